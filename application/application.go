@@ -4,16 +4,24 @@ import (
 	"github.com/fabric8-services/fabric8-wit/account"
 	"github.com/fabric8-services/fabric8-wit/area"
 	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/codebase/workspace"
 	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/deployment"
 	"github.com/fabric8-services/fabric8-wit/iteration"
 	"github.com/fabric8-services/fabric8-wit/label"
 	"github.com/fabric8-services/fabric8-wit/query"
 	"github.com/fabric8-services/fabric8-wit/remoteworkitem"
+	"github.com/fabric8-services/fabric8-wit/retention"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/fabric8-services/fabric8-wit/spacetemplate"
+	"github.com/fabric8-services/fabric8-wit/token/accesstoken"
+	"github.com/fabric8-services/fabric8-wit/trash"
+	"github.com/fabric8-services/fabric8-wit/watcher"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/event"
 	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/fabric8-services/fabric8-wit/workitem/template"
 )
 
 //An Application stands for a particular implementation of the business logic of our application
@@ -38,6 +46,18 @@ type Application interface {
 	Events() event.Repository
 	SpaceTemplates() spacetemplate.Repository
 	WorkItemTypeGroups() workitem.WorkItemTypeGroupRepository
+	Watchers() watcher.Repository
+	NotificationPreferences() watcher.PreferenceRepository
+	WorkItemTemplates() template.Repository
+	Trash() trash.Repository
+	SpaceRoles() role.Repository
+	PersonalAccessTokens() accesstoken.Repository
+	Deployments() deployment.Repository
+	CodeReferences() codebase.ReferenceRepository
+	CodebaseMetadata() codebase.MetadataRepository
+	CodebaseWorkspaces() workspace.Repository
+	WorkspacePolicies() workspace.PolicyRepository
+	RetentionPolicies() retention.PolicyRepository
 }
 
 // A Transaction abstracts a database transaction. The repositories created for the transaction object make changes inside the the transaction
@@ -1,14 +1,20 @@
 package application
 
 import (
+	"github.com/fabric8-services/fabric8-wit/search"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/link"
 
 	"context"
+
+	uuid "github.com/satori/go.uuid"
 )
 
 // SearchRepository encapsulates searching of woritems,users,etc
 type SearchRepository interface {
 	SearchFullText(ctx context.Context, searchStr string, start *int, length *int, spaceID *string) ([]workitem.WorkItem, int, error)
 	Filter(ctx context.Context, filterStr string, parentExists *bool, start *int, length *int) ([]workitem.WorkItem, int, link.AncestorList, link.WorkItemLinkList, error)
+	FilterAfter(ctx context.Context, filterStr string, after *workitem.WorkItem, limit int) ([]workitem.WorkItem, error)
+	Highlights(ctx context.Context, searchStr string, ids []uuid.UUID) (map[uuid.UUID]search.Highlight, error)
+	Suggest(ctx context.Context, spaceID uuid.UUID, prefix string, limit int) ([]search.Suggestion, error)
 }
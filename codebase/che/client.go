@@ -8,10 +8,10 @@ import (
 	"net/http"
 	"net/http/httputil"
 
+	witmiddleware "github.com/fabric8-services/fabric8-wit/goamiddleware"
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/goadesign/goa/middleware"
-	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 )
 
 // Client the interface for remote operations on Che
@@ -20,6 +20,7 @@ type Client interface {
 	ListWorkspaces(ctx context.Context, repository string) ([]*WorkspaceResponse, error)
 	DeleteWorkspace(ctx context.Context, workspaceName string) error
 	StartExistingWorkspace(ctx context.Context, workspaceName string) (*WorkspaceResponse, error)
+	StopWorkspace(ctx context.Context, workspaceName string) error
 	GetCheServerState(ctx context.Context) (*ServerStateResponse, error)
 	StartCheServer(ctx context.Context) (*ServerStateResponse, error)
 }
@@ -30,11 +31,19 @@ func NewStarterClient(cheStarterURL, openshiftMasterURL string, namespace string
 	return &StarterClient{cheStarterURL: cheStarterURL, openshiftMasterURL: openshiftMasterURL, namespace: namespace, client: client}
 }
 
+// NewStarterServiceClient creates a new CheStarter client authenticated with
+// the given token instead of the token of the user in the request context, for
+// the operations that are not run on behalf of a user
+func NewStarterServiceClient(cheStarterURL, openshiftMasterURL string, namespace string, token string, client *http.Client) Client {
+	return &StarterClient{cheStarterURL: cheStarterURL, openshiftMasterURL: openshiftMasterURL, namespace: namespace, token: token, client: client}
+}
+
 // StarterClient describes the REST interface between Platform and Che Starter
 type StarterClient struct {
 	cheStarterURL      string
 	openshiftMasterURL string
 	namespace          string
+	token              string
 	client             *http.Client
 }
 
@@ -45,7 +54,16 @@ func (cs *StarterClient) targetURL(resource string) string {
 func (cs *StarterClient) setHeaders(ctx context.Context, req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", "Bearer "+goajwt.ContextJWT(ctx).Raw)
+	token := cs.token
+	if token == "" {
+		// personal access tokens are never sent to che-starter
+		if jwttoken := witmiddleware.ForwardableJWT(ctx); jwttoken != nil {
+			token = jwttoken.Raw
+		}
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 	req.Header.Set(middleware.RequestIDHeader, middleware.ContextRequestID(ctx))
 }
 
@@ -289,6 +307,65 @@ func (cs *StarterClient) StartExistingWorkspace(ctx context.Context, workspaceNa
 	return &workspaceResp, nil
 }
 
+// StopWorkspace stops the runtime of a Che Workspace by its name, the
+// workspace can be started again afterwards
+func (cs *StarterClient) StopWorkspace(ctx context.Context, workspaceName string) error {
+	req, err := http.NewRequest("DELETE", cs.targetURL(fmt.Sprintf("workspace/%s/runtime", workspaceName)), nil)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"name":      workspaceName,
+			"masterURL": cs.cheStarterURL,
+			"namespace": cs.namespace,
+			"err":       err,
+		}, "failed to create request object")
+		return err
+	}
+	cs.setHeaders(ctx, req)
+
+	if log.IsDebug() {
+		b, _ := httputil.DumpRequest(req, true)
+		log.Debug(ctx, map[string]interface{}{
+			"request": string(b),
+		}, "request object")
+	}
+
+	resp, err := cs.client.Do(req)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"name":      workspaceName,
+			"masterURL": cs.cheStarterURL,
+			"namespace": cs.namespace,
+			"err":       err,
+		}, "failed to stop workspace")
+		return err
+	}
+
+	defer rest.CloseResponse(resp)
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		workspaceErr := StarterError{}
+		err = json.NewDecoder(resp.Body).Decode(&workspaceErr)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"name":      workspaceName,
+				"masterURL": cs.cheStarterURL,
+				"namespace": cs.namespace,
+				"err":       err,
+			}, "failed to decode error response from stop workspace")
+			return err
+		}
+		log.Error(ctx, map[string]interface{}{
+			"name":      workspaceName,
+			"masterURL": cs.cheStarterURL,
+			"namespace": cs.namespace,
+			"err":       workspaceErr.String(),
+		}, "failed to stop workspace")
+		return &workspaceErr
+	}
+
+	return nil
+}
+
 // GetCheServerState get che server state.
 func (cs *StarterClient) GetCheServerState(ctx context.Context) (*ServerStateResponse, error) {
 	req, err := http.NewRequest("GET", cs.targetURL("server"), nil)
@@ -0,0 +1,96 @@
+package che_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/codebase/che"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	chesupport "github.com/fabric8-services/fabric8-wit/test/che"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const repository = "https://github.com/fabric8-services/fabric8-wit"
+
+func TestStarterClient(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	ctx := goajwt.WithJWT(context.Background(), &jwt.Token{Raw: "user-token"})
+
+	t.Run("workspace lifecycle", func(t *testing.T) {
+		starter := chesupport.NewFakeStarter()
+		defer starter.Close()
+		client := che.NewStarterClient(starter.URL, "https://openshift.example.com", "user-che", http.DefaultClient)
+
+		created, err := client.CreateWorkspace(ctx, che.WorkspaceRequest{Repository: repository, Branch: "master", StackID: "java-centos"})
+		require.NoError(t, err)
+		name := created.Config.Name
+		assert.Equal(t, chesupport.StatusRunning, starter.Status(name))
+
+		require.NoError(t, client.StopWorkspace(ctx, name))
+		assert.Equal(t, chesupport.StatusStopped, starter.Status(name))
+
+		started, err := client.StartExistingWorkspace(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, chesupport.StatusRunning, started.Status)
+
+		workspaces, err := client.ListWorkspaces(ctx, repository)
+		require.NoError(t, err)
+		require.Len(t, workspaces, 1)
+		assert.Equal(t, name, workspaces[0].Config.Name)
+
+		require.NoError(t, client.DeleteWorkspace(ctx, name))
+		assert.Equal(t, "", starter.Status(name))
+		assert.Equal(t, []string{
+			"POST /workspace",
+			"DELETE /workspace/" + name + "/runtime",
+			"PATCH /workspace/" + name,
+			"GET /workspace",
+			"DELETE /workspace/" + name,
+		}, starter.Requests)
+		for _, token := range starter.Tokens {
+			assert.Equal(t, "user-token", token)
+		}
+	})
+
+	t.Run("stop unknown workspace", func(t *testing.T) {
+		starter := chesupport.NewFakeStarter()
+		defer starter.Close()
+		client := che.NewStarterClient(starter.URL, "https://openshift.example.com", "user-che", http.DefaultClient)
+
+		err := client.StopWorkspace(ctx, "unknown")
+		require.Error(t, err)
+		require.IsType(t, &che.StarterError{}, err)
+		assert.Equal(t, http.StatusNotFound, err.(*che.StarterError).Status)
+	})
+
+	t.Run("service account", func(t *testing.T) {
+		starter := chesupport.NewFakeStarter()
+		defer starter.Close()
+		starter.AddWorkspace("wksp-expired", repository, chesupport.StatusRunning)
+		// no user token in the context
+		client := che.NewStarterServiceClient(starter.URL, "https://openshift.example.com", "user-che", "service-token", http.DefaultClient)
+
+		require.NoError(t, client.StopWorkspace(context.Background(), "wksp-expired"))
+		assert.Equal(t, chesupport.StatusStopped, starter.Status("wksp-expired"))
+		assert.Equal(t, []string{"service-token"}, starter.Tokens)
+	})
+
+	t.Run("personal access token", func(t *testing.T) {
+		starter := chesupport.NewFakeStarter()
+		defer starter.Close()
+		client := che.NewStarterClient(starter.URL, "https://openshift.example.com", "user-che", http.DefaultClient)
+		patCtx := testsupport.WithResolvedToken(context.Background(), "f8pat_secret", testsupport.TestIdentity)
+
+		_, err := client.ListWorkspaces(patCtx, repository)
+		require.Error(t, err)
+		// the personal access token is never sent to che-starter
+		assert.Equal(t, []string{""}, starter.Tokens)
+	})
+}
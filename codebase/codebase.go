@@ -2,6 +2,8 @@ package codebase
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"regexp"
 	"time"
@@ -130,6 +132,9 @@ type Codebase struct {
 	URL               string
 	StackID           *string
 	LastUsedWorkspace string
+	// WebhookSecret is the secret the webhook events of the repository must
+	// be signed with, generated when the codebase is created
+	WebhookSecret string
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -166,6 +171,13 @@ func (m *GormCodebaseRepository) Create(ctx context.Context, codebase *Codebase)
 	if codebase.ID == uuid.Nil {
 		codebase.ID = uuid.NewV4()
 	}
+	if codebase.WebhookSecret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return errors.NewInternalError(ctx, err)
+		}
+		codebase.WebhookSecret = secret
+	}
 
 	if err := m.db.Create(codebase).Error; err != nil {
 		// if codebase already exists in the space
@@ -187,6 +199,15 @@ func (m *GormCodebaseRepository) Create(ctx context.Context, codebase *Codebase)
 	return nil
 }
 
+// generateWebhookSecret returns a new random webhook secret
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", errs.Wrap(err, "failed to generate the webhook secret")
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // Delete deletes the codebase with the given id
 // returns NotFoundError or InternalError
 func (m *GormCodebaseRepository) Delete(ctx context.Context, ID uuid.UUID) error {
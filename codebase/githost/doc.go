@@ -0,0 +1,4 @@
+// Package githost periodically fetches the metadata of the repositories of
+// the codebases (default branch, branches, latest commit, open pull requests
+// and language) from the hosts they are stored on, through a Client per host.
+package githost
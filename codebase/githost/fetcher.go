@@ -0,0 +1,183 @@
+package githost
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/models"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/robfig/cron"
+)
+
+// AdvisoryLockID is the ID of the advisory lock held by the instance of the
+// service fetching the metadata of the codebases
+const AdvisoryLockID = 4272
+
+// Fetcher periodically fetches the metadata of the codebases whose metadata
+// is older than the maximum age, with the client of the host of their
+// repository. The codebases stored on other hosts are skipped. The codebases
+// are listed in batches of bounded size and only one instance of the service
+// fetches the metadata at a time.
+type Fetcher struct {
+	db        *gorm.DB
+	clients   map[string]Client
+	maxAge    time.Duration
+	batchSize int
+	cr        *cron.Cron
+}
+
+// NewFetcher creates a new Fetcher using the given clients per host name
+// (e.g. "github.com") and listing at most batchSize codebases at a time
+func NewFetcher(db *gorm.DB, clients map[string]Client, maxAge time.Duration, batchSize int) *Fetcher {
+	return &Fetcher{db: db, clients: clients, maxAge: maxAge, batchSize: batchSize, cr: cron.New()}
+}
+
+// Start runs the fetch according to the given cron schedule (e.g. "@every
+// 10m"). Nothing is ever fetched if the schedule is empty.
+func (f *Fetcher) Start(schedule string) error {
+	if schedule == "" {
+		log.Info(nil, map[string]interface{}{}, "codebase metadata schedule is not set, the metadata is never fetched")
+		return nil
+	}
+	if f.batchSize <= 0 {
+		return errs.Errorf("invalid codebase metadata batch size %d", f.batchSize)
+	}
+	err := f.cr.AddFunc(schedule, func() {
+		if _, err := f.Fetch(context.Background()); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err": err,
+			}, "failed to fetch the codebase metadata")
+		}
+	})
+	if err != nil {
+		return errs.Wrapf(err, "invalid codebase metadata schedule '%s'", schedule)
+	}
+	f.cr.Start()
+	return nil
+}
+
+// Stop stops the fetcher.
+// This should be called only from main
+func (f *Fetcher) Stop() {
+	f.cr.Stop()
+}
+
+// Fetch fetches and stores the metadata of the codebases whose metadata is
+// stale, unless another instance of the service is already doing it, and
+// returns the number of codebases that were updated. A codebase whose
+// metadata can't be fetched is retried on the next run.
+func (f *Fetcher) Fetch(ctx context.Context) (int, error) {
+	// the session advisory lock is held by a connection of its own for the
+	// whole run, which spans many transactions
+	conn, err := f.db.DB().Conn(ctx)
+	if err != nil {
+		return 0, errs.Wrap(err, "failed to open a database connection")
+	}
+	defer conn.Close()
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", AdvisoryLockID).Scan(&acquired); err != nil {
+		return 0, errs.Wrap(err, "failed to acquire the codebase metadata lock")
+	}
+	if !acquired {
+		log.Info(ctx, map[string]interface{}{}, "the codebase metadata is already fetched by another instance")
+		return 0, nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", AdvisoryLockID); err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"err": err,
+			}, "failed to release the codebase metadata lock")
+		}
+	}()
+	return f.fetchAll(ctx)
+}
+
+// fetchAll fetches the metadata of the stale codebases, one batch after the
+// other
+func (f *Fetcher) fetchAll(ctx context.Context) (int, error) {
+	hosts := make([]string, 0, len(f.clients))
+	for host := range f.clients {
+		hosts = append(hosts, host)
+	}
+	fetchedBefore := time.Now().Add(-f.maxAge)
+	// the same repository may be registered in several spaces
+	fetched := map[Repository]*codebase.Metadata{}
+	var stale, updated int
+	var after *codebase.StaleCodebase
+	for {
+		var codebases []codebase.StaleCodebase
+		err := models.Transactional(f.db, func(tx *gorm.DB) error {
+			var err error
+			codebases, err = codebase.NewMetadataRepository(tx).ListStale(ctx, fetchedBefore, hosts, after, f.batchSize)
+			return err
+		})
+		if err != nil {
+			return updated, err
+		}
+		stale += len(codebases)
+		for _, cb := range codebases {
+			ok, err := f.fetch(ctx, cb.Codebase, fetched)
+			if err != nil {
+				return updated, err
+			}
+			if ok {
+				updated++
+			}
+		}
+		if len(codebases) < f.batchSize {
+			break
+		}
+		after = &codebases[len(codebases)-1]
+	}
+	log.Info(ctx, map[string]interface{}{
+		"stale":   stale,
+		"updated": updated,
+	}, "fetched the codebase metadata")
+	return updated, nil
+}
+
+// fetch fetches and stores the metadata of the codebase, unless it was
+// already fetched for the same repository, and tells whether the codebase was
+// updated
+func (f *Fetcher) fetch(ctx context.Context, cb codebase.Codebase, fetched map[Repository]*codebase.Metadata) (bool, error) {
+	repo, err := ParseRepositoryURL(cb.URL)
+	if err != nil {
+		log.Debug(ctx, map[string]interface{}{
+			"codebase_id": cb.ID,
+			"url":         cb.URL,
+		}, "skipping the codebase with an invalid URL")
+		return false, nil
+	}
+	client, ok := f.clients[repo.Host]
+	if !ok {
+		return false, nil
+	}
+	m, ok := fetched[*repo]
+	if !ok {
+		// the git host is called outside of any transaction
+		m, err = client.Fetch(ctx, *repo)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"codebase_id": cb.ID,
+				"repository":  repo.String(),
+				"err":         err,
+			}, "failed to fetch the codebase metadata")
+			return false, nil
+		}
+		fetched[*repo] = m
+	}
+	md := *m
+	md.CodebaseID = cb.ID
+	md.FetchedAt = time.Now()
+	err = models.Transactional(f.db, func(tx *gorm.DB) error {
+		return codebase.NewMetadataRepository(tx).Save(ctx, &md)
+	})
+	if err != nil {
+		return false, errs.Wrapf(err, "failed to store the metadata of codebase %s", cb.ID)
+	}
+	return true, nil
+}
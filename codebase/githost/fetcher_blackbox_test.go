@@ -0,0 +1,104 @@
+package githost_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/codebase/githost"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	githostsupport "github.com/fabric8-services/fabric8-wit/test/githost"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestFetcher struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunFetcher(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestFetcher{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+func (s *TestFetcher) TestFetch() {
+	// the same repository is registered in two spaces, along with a
+	// repository unknown to the git host and one stored on another host
+	name := uuid.NewV4().String()
+	urls := []string{
+		"git@github.com:fabric8-services/" + name + ".git",
+		"https://github.com/fabric8-services/" + name,
+		"https://github.com/fabric8-services/" + name + "-unknown",
+		"https://gitlab.com/fabric8-services/" + name,
+	}
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(4), tf.Codebases(4, func(fxt *tf.TestFixture, idx int) error {
+		fxt.Codebases[idx].URL = urls[idx]
+		fxt.Codebases[idx].SpaceID = fxt.Spaces[idx].ID
+		return nil
+	}))
+	client := &githostsupport.FakeClient{
+		Metadata: map[string]codebase.Metadata{
+			"fabric8-services/" + name: {
+				DefaultBranch:    "master",
+				Branches:         codebase.Branches{"master"},
+				OpenPullRequests: 2,
+				Language:         "Go",
+			},
+		},
+	}
+	// the codebases are listed one at a time
+	fetcher := githost.NewFetcher(s.DB, map[string]githost.Client{githost.GitHubHost: client}, time.Hour, 1)
+	repo := codebase.NewMetadataRepository(s.DB)
+
+	s.T().Run("stale metadata", func(t *testing.T) {
+		_, err := fetcher.Fetch(context.Background())
+		require.NoError(t, err)
+		metadata, err := repo.List(context.Background(), fxt.Codebases[0].ID, fxt.Codebases[1].ID, fxt.Codebases[2].ID, fxt.Codebases[3].ID)
+		require.NoError(t, err)
+		require.Len(t, metadata, 2)
+		for _, m := range metadata {
+			assert.Contains(t, []uuid.UUID{fxt.Codebases[0].ID, fxt.Codebases[1].ID}, m.CodebaseID)
+			assert.Equal(t, "master", m.DefaultBranch)
+			assert.Equal(t, 2, m.OpenPullRequests)
+		}
+		// the repository registered twice is fetched once
+		fetched := 0
+		for _, r := range client.Fetched {
+			if r.Name == name {
+				fetched++
+			}
+		}
+		assert.Equal(t, 1, fetched)
+	})
+
+	s.T().Run("fresh metadata", func(t *testing.T) {
+		client.Fetched = nil
+		_, err := fetcher.Fetch(context.Background())
+		require.NoError(t, err)
+		for _, r := range client.Fetched {
+			assert.NotEqual(t, name, r.Name)
+		}
+	})
+
+	s.T().Run("another instance fetching the metadata", func(t *testing.T) {
+		require.NoError(t, s.DB.Exec("DELETE FROM "+codebase.MetadataTableName+" WHERE codebase_id = ?", fxt.Codebases[0].ID).Error)
+		conn, err := s.DB.DB().Conn(context.Background())
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = conn.ExecContext(context.Background(), "SELECT pg_advisory_lock($1)", githost.AdvisoryLockID)
+		require.NoError(t, err)
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", githost.AdvisoryLockID)
+		client.Fetched = nil
+		// when
+		updated, err := fetcher.Fetch(context.Background())
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 0, updated)
+		assert.Empty(t, client.Fetched)
+	})
+}
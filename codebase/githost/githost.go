@@ -0,0 +1,58 @@
+package githost
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/errors"
+)
+
+// Client fetches the metadata of the repositories stored on a git host
+type Client interface {
+	// Fetch returns the metadata of the given repository. The codebase ID
+	// and freshness timestamp of the result are set by the caller.
+	Fetch(ctx context.Context, repo Repository) (*codebase.Metadata, error)
+}
+
+// Repository identifies a repository on a git host
+type Repository struct {
+	// Host is the host name of the git host, e.g. "github.com"
+	Host string
+	// Owner is the user or organization owning the repository
+	Owner string
+	Name  string
+}
+
+// String returns the repository in the "host/owner/name" form
+func (r Repository) String() string {
+	return r.Host + "/" + r.Owner + "/" + r.Name
+}
+
+// scpLikeURL matches the "git@github.com:owner/repo.git" form of the URLs
+var scpLikeURL = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+):([^/].*)$`)
+
+// ParseRepositoryURL returns the repository of the given codebase URL, which
+// may be an HTTP(S), SSH, git or scp-like URL
+func ParseRepositoryURL(codebaseURL string) (*Repository, error) {
+	var host, path string
+	if u, err := url.Parse(codebaseURL); err == nil && u.Scheme != "" && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if m := scpLikeURL.FindStringSubmatch(codebaseURL); m != nil {
+		host, path = m[1], m[2]
+	} else {
+		return nil, errors.NewBadParameterError("url", codebaseURL).Expected("git repository URL")
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if i <= 0 || i == len(path)-1 {
+		return nil, errors.NewBadParameterError("url", codebaseURL).Expected("git repository URL with an owner and a name")
+	}
+	return &Repository{
+		Host:  strings.ToLower(host),
+		Owner: path[:i],
+		Name:  path[i+1:],
+	}, nil
+}
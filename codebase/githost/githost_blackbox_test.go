@@ -0,0 +1,39 @@
+package githost_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/codebase/githost"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepositoryURL(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	testData := []struct {
+		name     string
+		url      string
+		expected *githost.Repository
+	}{
+		{"scp-like", "git@github.com:fabric8-services/fabric8-wit.git", &githost.Repository{Host: "github.com", Owner: "fabric8-services", Name: "fabric8-wit"}},
+		{"https", "https://github.com/fabric8-services/fabric8-wit", &githost.Repository{Host: "github.com", Owner: "fabric8-services", Name: "fabric8-wit"}},
+		{"https with suffix", "https://GitHub.com/fabric8-services/fabric8-wit.git/", &githost.Repository{Host: "github.com", Owner: "fabric8-services", Name: "fabric8-wit"}},
+		{"ssh", "ssh://git@gitlab.com:22/group/subgroup/project.git", &githost.Repository{Host: "gitlab.com", Owner: "group/subgroup", Name: "project"}},
+		{"no owner", "https://github.com/fabric8-wit", nil},
+		{"no path", "https://github.com/", nil},
+		{"not an URL", "fabric8-wit", nil},
+	}
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			repo, err := githost.ParseRepositoryURL(td.url)
+			if td.expected == nil {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, *td.expected, *repo)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package githost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	errs "github.com/pkg/errors"
+)
+
+// GitHubHost is the host name of the repositories fetched by the GitHubClient
+const GitHubHost = "github.com"
+
+// maxBranches is the maximum number of branches fetched per repository
+const maxBranches = 100
+
+// GitHubClient fetches the metadata of GitHub repositories through the GitHub
+// REST API v3
+type GitHubClient struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGitHubClient creates a client of the GitHub API at the given URL (e.g.
+// "https://api.github.com"), authenticated with the given token if it is not
+// empty. Unauthenticated clients are heavily rate limited by GitHub.
+func NewGitHubClient(apiURL, token string, timeout time.Duration) *GitHubClient {
+	return &GitHubClient{
+		url:        strings.TrimSuffix(apiURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Fetch returns the metadata of the GitHub repository
+func (c *GitHubClient) Fetch(ctx context.Context, repo Repository) (*codebase.Metadata, error) {
+	repoPath := "/repos/" + url.PathEscape(repo.Owner) + "/" + url.PathEscape(repo.Name)
+	var r struct {
+		DefaultBranch string `json:"default_branch"`
+		Language      string `json:"language"`
+	}
+	if err := c.get(ctx, repoPath, nil, &r); err != nil {
+		return nil, err
+	}
+	m := &codebase.Metadata{
+		DefaultBranch: r.DefaultBranch,
+		Language:      r.Language,
+		Branches:      codebase.Branches{},
+	}
+
+	var branches []struct {
+		Name string `json:"name"`
+	}
+	if err := c.get(ctx, repoPath+"/branches", url.Values{"per_page": {fmt.Sprint(maxBranches)}}, &branches); err != nil {
+		return nil, err
+	}
+	for _, b := range branches {
+		m.Branches = append(m.Branches, b.Name)
+	}
+
+	if r.DefaultBranch != "" {
+		var commit struct {
+			SHA     string `json:"sha"`
+			HTMLURL string `json:"html_url"`
+			Commit  struct {
+				Message string `json:"message"`
+				Author  struct {
+					Name string    `json:"name"`
+					Date time.Time `json:"date"`
+				} `json:"author"`
+			} `json:"commit"`
+		}
+		err := c.get(ctx, repoPath+"/commits/"+url.PathEscape(r.DefaultBranch), nil, &commit)
+		if err != nil {
+			// an empty repository has a default branch but no commit
+			if ok, _ := errors.IsNotFoundError(err); !ok {
+				return nil, err
+			}
+		} else {
+			m.LatestCommitID = commit.SHA
+			m.LatestCommitMessage = commit.Commit.Message
+			m.LatestCommitAuthor = commit.Commit.Author.Name
+			m.LatestCommitURL = commit.HTMLURL
+			if !commit.Commit.Author.Date.IsZero() {
+				date := commit.Commit.Author.Date
+				m.LatestCommitDate = &date
+			}
+		}
+	}
+
+	// the search API returns the number of open pull requests without
+	// listing them all
+	var prs struct {
+		TotalCount int `json:"total_count"`
+	}
+	query := url.Values{
+		"q":        {fmt.Sprintf("repo:%s/%s type:pr state:open", repo.Owner, repo.Name)},
+		"per_page": {"1"},
+	}
+	if err := c.get(ctx, "/search/issues", query, &prs); err != nil {
+		return nil, err
+	}
+	m.OpenPullRequests = prs.TotalCount
+	return m, nil
+}
+
+// get sends a GET request to the GitHub API and decodes the JSON response
+// into result
+func (c *GitHubClient) get(ctx context.Context, path string, params url.Values, result interface{}) error {
+	fullURL := c.url + path
+	if len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
+	if err != nil {
+		return errs.Wrapf(err, "failed to create the request to %s", fullURL)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errs.Wrapf(err, "failed to send the request to %s", fullURL)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errs.Wrapf(err, "failed to read the response of %s", fullURL)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errors.NewNotFoundError("GitHub resource", path)
+	case resp.StatusCode != http.StatusOK:
+		log.Error(ctx, map[string]interface{}{
+			"url":    fullURL,
+			"status": resp.StatusCode,
+			"body":   string(body),
+		}, "unexpected response of the GitHub API")
+		return errs.Errorf("unexpected status %d from %s", resp.StatusCode, fullURL)
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return errs.Wrapf(err, "failed to decode the response of %s", fullURL)
+	}
+	return nil
+}
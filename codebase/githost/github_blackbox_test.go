@@ -0,0 +1,85 @@
+package githost_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/codebase/githost"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGitHub returns a fake GitHub API replying with the given body to
+// each path, and with a 404 to the others
+func newTestGitHub(t *testing.T, responses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token myToken", r.Header.Get("Authorization"))
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+}
+
+func TestGitHubClient(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	repo := githost.Repository{Host: githost.GitHubHost, Owner: "fabric8-services", Name: "fabric8-wit"}
+
+	t.Run("ok", func(t *testing.T) {
+		gh := newTestGitHub(t, map[string]string{
+			"/repos/fabric8-services/fabric8-wit":          `{"default_branch": "master", "language": "Go"}`,
+			"/repos/fabric8-services/fabric8-wit/branches": `[{"name": "master"}, {"name": "feature"}]`,
+			"/repos/fabric8-services/fabric8-wit/commits/master": `{
+				"sha": "6f5a2b2c",
+				"html_url": "https://github.com/fabric8-services/fabric8-wit/commit/6f5a2b2c",
+				"commit": {"message": "Fix the login page", "author": {"name": "John Doe", "date": "2018-02-01T10:00:00Z"}}
+			}`,
+			"/search/issues": `{"total_count": 3, "items": []}`,
+		})
+		defer gh.Close()
+		m, err := githost.NewGitHubClient(gh.URL+"/", "myToken", time.Second).Fetch(context.Background(), repo)
+		require.NoError(t, err)
+		assert.Equal(t, "master", m.DefaultBranch)
+		assert.Equal(t, codebase.Branches{"master", "feature"}, m.Branches)
+		assert.Equal(t, "6f5a2b2c", m.LatestCommitID)
+		assert.Equal(t, "Fix the login page", m.LatestCommitMessage)
+		assert.Equal(t, "John Doe", m.LatestCommitAuthor)
+		assert.Equal(t, time.Date(2018, 2, 1, 10, 0, 0, 0, time.UTC), *m.LatestCommitDate)
+		assert.Equal(t, 3, m.OpenPullRequests)
+		assert.Equal(t, "Go", m.Language)
+	})
+
+	t.Run("empty repository", func(t *testing.T) {
+		gh := newTestGitHub(t, map[string]string{
+			"/repos/fabric8-services/fabric8-wit":          `{"default_branch": "master", "language": null}`,
+			"/repos/fabric8-services/fabric8-wit/branches": `[]`,
+			"/search/issues": `{"total_count": 0, "items": []}`,
+		})
+		defer gh.Close()
+		m, err := githost.NewGitHubClient(gh.URL, "myToken", time.Second).Fetch(context.Background(), repo)
+		require.NoError(t, err)
+		assert.Empty(t, m.Branches)
+		assert.Empty(t, m.LatestCommitID)
+		assert.Nil(t, m.LatestCommitDate)
+	})
+
+	t.Run("unknown repository", func(t *testing.T) {
+		gh := newTestGitHub(t, map[string]string{})
+		defer gh.Close()
+		_, err := githost.NewGitHubClient(gh.URL, "myToken", time.Second).Fetch(context.Background(), repo)
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+}
@@ -0,0 +1,174 @@
+package codebase
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Branches are the names of the branches of a repository, stored as a JSON
+// array
+type Branches []string
+
+// Value implements the driver.Valuer interface
+func (b Branches) Value() (driver.Value, error) {
+	if b == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(b)
+}
+
+// Scan implements the sql.Scanner interface
+func (b *Branches) Scan(src interface{}) error {
+	data, ok := src.([]byte)
+	if !ok {
+		return errs.Errorf("unexpected type of branches: %T", src)
+	}
+	return json.Unmarshal(data, b)
+}
+
+// Metadata is the information about the repository of a codebase that is
+// periodically fetched from its git host
+type Metadata struct {
+	CodebaseID          uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	DefaultBranch       string
+	Branches            Branches `sql:"type:jsonb"`
+	LatestCommitID      string
+	LatestCommitMessage string
+	LatestCommitAuthor  string
+	LatestCommitURL     string
+	LatestCommitDate    *time.Time
+	OpenPullRequests    int
+	Language            string
+	// FetchedAt tells how fresh the metadata is
+	FetchedAt time.Time
+}
+
+// MetadataTableName constant that holds table name of the codebase metadata
+const MetadataTableName = "codebase_metadata"
+
+// TableName implements gorm.tabler
+func (m Metadata) TableName() string {
+	return MetadataTableName
+}
+
+// StaleCodebase is a codebase whose metadata needs to be fetched, along with
+// the time its metadata was last fetched, if ever
+type StaleCodebase struct {
+	Codebase
+	FetchedAt *time.Time
+}
+
+// urlHostPattern matches the host of an HTTP(S), SSH, git or scp-like URL in
+// its first group, like githost.ParseRepositoryURL does
+const urlHostPattern = `^(?:[a-z][a-z0-9+.-]*://)?(?:[^@/]+@)?([^:/]+)`
+
+// MetadataRepository describes interactions with the metadata of codebases
+type MetadataRepository interface {
+	// Save stores the given metadata, replacing the previous metadata of the
+	// codebase
+	Save(ctx context.Context, m *Metadata) error
+	// List returns the metadata of the given codebases. Codebases whose
+	// metadata was never fetched are omitted.
+	List(ctx context.Context, codebaseIDs ...uuid.UUID) ([]Metadata, error)
+	// ListStale returns at most limit codebases stored on the given hosts
+	// whose metadata was never fetched or was fetched before the given time,
+	// the stalest first, starting after the given codebase if not nil
+	ListStale(ctx context.Context, fetchedBefore time.Time, hosts []string, after *StaleCodebase, limit int) ([]StaleCodebase, error)
+}
+
+// NewMetadataRepository creates a new storage type.
+func NewMetadataRepository(db *gorm.DB) MetadataRepository {
+	return &GormMetadataRepository{db: db}
+}
+
+// GormMetadataRepository is the implementation of the storage interface for
+// the metadata of codebases.
+type GormMetadataRepository struct {
+	db *gorm.DB
+}
+
+// Save stores the given metadata
+func (r *GormMetadataRepository) Save(ctx context.Context, m *Metadata) error {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_metadata", "save"}, time.Now())
+	if m.FetchedAt.IsZero() {
+		m.FetchedAt = time.Now()
+	}
+	upsertStmt := `INSERT INTO ` + MetadataTableName + ` (codebase_id, default_branch, branches, latest_commit_id,
+		latest_commit_message, latest_commit_author, latest_commit_url, latest_commit_date, open_pull_requests, language, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (codebase_id) DO UPDATE SET
+		default_branch = EXCLUDED.default_branch, branches = EXCLUDED.branches, latest_commit_id = EXCLUDED.latest_commit_id,
+		latest_commit_message = EXCLUDED.latest_commit_message, latest_commit_author = EXCLUDED.latest_commit_author,
+		latest_commit_url = EXCLUDED.latest_commit_url, latest_commit_date = EXCLUDED.latest_commit_date,
+		open_pull_requests = EXCLUDED.open_pull_requests, language = EXCLUDED.language, fetched_at = EXCLUDED.fetched_at`
+	err := r.db.Exec(upsertStmt, m.CodebaseID, m.DefaultBranch, m.Branches, m.LatestCommitID, m.LatestCommitMessage,
+		m.LatestCommitAuthor, m.LatestCommitURL, m.LatestCommitDate, m.OpenPullRequests, m.Language, m.FetchedAt).Error
+	if err != nil {
+		if gormsupport.IsForeignKeyViolation(err, "codebase_metadata_codebase_id_fkey") {
+			return errors.NewNotFoundError("codebase", m.CodebaseID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"codebase_id": m.CodebaseID,
+			"err":         err,
+		}, "unable to store the codebase metadata")
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// List returns the metadata of the codebases
+func (r *GormMetadataRepository) List(ctx context.Context, codebaseIDs ...uuid.UUID) ([]Metadata, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_metadata", "list"}, time.Now())
+	res := []Metadata{}
+	if len(codebaseIDs) == 0 {
+		return res, nil
+	}
+	if err := r.db.Where("codebase_id IN (?)", codebaseIDs).Find(&res).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"codebase_ids": codebaseIDs,
+			"err":          err,
+		}, "unable to list the codebase metadata")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, nil
+}
+
+// ListStale returns the codebases whose metadata needs to be fetched. The
+// codebases are paged by the time their metadata was fetched, the never
+// fetched ones first, then by ID.
+func (r *GormMetadataRepository) ListStale(ctx context.Context, fetchedBefore time.Time, hosts []string, after *StaleCodebase, limit int) ([]StaleCodebase, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_metadata", "list_stale"}, time.Now())
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+	db := r.db.Select("codebases.*, md.fetched_at").
+		Joins("LEFT JOIN "+MetadataTableName+" md ON md.codebase_id = codebases.id").
+		Where("md.fetched_at IS NULL OR md.fetched_at < ?", fetchedBefore).
+		Where("lower(substring(codebases.url from ?)) IN (?)", urlHostPattern, hosts)
+	if after != nil {
+		db = db.Where("(COALESCE(md.fetched_at, '-infinity'), codebases.id) > (COALESCE(CAST(? AS timestamp with time zone), '-infinity'), ?)", after.FetchedAt, after.ID)
+	}
+	var res []StaleCodebase
+	err := db.Order("md.fetched_at NULLS FIRST, codebases.id").
+		Limit(limit).
+		Find(&res).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"fetched_before": fetchedBefore,
+			"hosts":          hosts,
+			"err":            err,
+		}, "unable to list the codebases with stale metadata")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,115 @@
+package codebase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestMetadataRepository struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunMetadataRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestMetadataRepository{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestMetadataRepository) TestSave() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Codebases(1))
+	repo := codebase.NewMetadataRepository(s.DB)
+
+	s.T().Run("create and replace", func(t *testing.T) {
+		commitDate := time.Date(2018, 2, 1, 10, 0, 0, 0, time.UTC)
+		m := codebase.Metadata{
+			CodebaseID:       fxt.Codebases[0].ID,
+			DefaultBranch:    "master",
+			Branches:         codebase.Branches{"master", "feature"},
+			LatestCommitID:   "6f5a2b2c",
+			LatestCommitDate: &commitDate,
+			OpenPullRequests: 3,
+			Language:         "Go",
+		}
+		require.NoError(t, repo.Save(context.Background(), &m))
+		m.Branches = codebase.Branches{"master"}
+		m.OpenPullRequests = 0
+		require.NoError(t, repo.Save(context.Background(), &m))
+
+		metadata, err := repo.List(context.Background(), fxt.Codebases[0].ID)
+		require.NoError(t, err)
+		require.Len(t, metadata, 1)
+		assert.Equal(t, codebase.Branches{"master"}, metadata[0].Branches)
+		assert.Equal(t, 0, metadata[0].OpenPullRequests)
+		assert.Equal(t, "Go", metadata[0].Language)
+		assert.Equal(t, commitDate, metadata[0].LatestCommitDate.UTC())
+		assert.False(t, metadata[0].FetchedAt.IsZero())
+	})
+
+	s.T().Run("unknown codebase", func(t *testing.T) {
+		err := repo.Save(context.Background(), &codebase.Metadata{CodebaseID: uuid.NewV4()})
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *TestMetadataRepository) TestListStale() {
+	// the fourth codebase is stored on another host
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Codebases(4, func(fxt *tf.TestFixture, idx int) error {
+		fxt.Codebases[idx].URL = "https://github.com/fabric8-services/" + uuid.NewV4().String()
+		if idx == 3 {
+			fxt.Codebases[idx].URL = "git@gitlab.com:fabric8-services/" + uuid.NewV4().String()
+		}
+		return nil
+	}))
+	repo := codebase.NewMetadataRepository(s.DB)
+	now := time.Now()
+	// the first codebase is fresh, the second one is stale and the metadata
+	// of the third one was never fetched
+	require.NoError(s.T(), repo.Save(context.Background(), &codebase.Metadata{CodebaseID: fxt.Codebases[0].ID, FetchedAt: now}))
+	require.NoError(s.T(), repo.Save(context.Background(), &codebase.Metadata{CodebaseID: fxt.Codebases[1].ID, FetchedAt: now.Add(-2 * time.Hour)}))
+
+	// the codebases are listed one at a time
+	var stale []uuid.UUID
+	var after *codebase.StaleCodebase
+	for {
+		codebases, err := repo.ListStale(context.Background(), now.Add(-time.Hour), []string{"github.com"}, after, 1)
+		require.NoError(s.T(), err)
+		require.True(s.T(), len(codebases) <= 1)
+		if len(codebases) == 0 {
+			break
+		}
+		// ignore the codebases created by other tests
+		if codebases[0].SpaceID == fxt.Spaces[0].ID {
+			stale = append(stale, codebases[0].ID)
+		}
+		after = &codebases[0]
+	}
+	// never fetched first
+	assert.Equal(s.T(), []uuid.UUID{fxt.Codebases[2].ID, fxt.Codebases[1].ID}, stale)
+
+	s.T().Run("other host", func(t *testing.T) {
+		codebases, err := repo.ListStale(context.Background(), now.Add(-time.Hour), []string{"gitlab.com"}, nil, 100)
+		require.NoError(t, err)
+		var ids []uuid.UUID
+		for _, cb := range codebases {
+			if cb.SpaceID == fxt.Spaces[0].ID {
+				ids = append(ids, cb.ID)
+			}
+		}
+		assert.Equal(t, []uuid.UUID{fxt.Codebases[3].ID}, ids)
+	})
+
+	metadata, err := repo.List(context.Background())
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), metadata)
+}
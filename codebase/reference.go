@@ -0,0 +1,128 @@
+package codebase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeCodeReferences helps to avoid string literal
+const APIStringTypeCodeReferences = "code-references"
+
+// ReferenceKind tells what references a work item in a codebase
+type ReferenceKind string
+
+// The kinds of references
+const (
+	ReferenceKindCommit      ReferenceKind = "commit"
+	ReferenceKindPullRequest ReferenceKind = "pullrequest"
+)
+
+// Reference is a commit or a pull request of a codebase that references a
+// work item in its message
+type Reference struct {
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	WorkItemID uuid.UUID `sql:"type:uuid"`
+	CodebaseID uuid.UUID `sql:"type:uuid"`
+	Kind       ReferenceKind
+	// Ref is the ID of the commit or the number of the pull request
+	Ref    string
+	Title  string
+	URL    string
+	Author string
+	// State is the state of the pull request, empty for a commit
+	State     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ReferenceTableName constant that holds table name of the code references
+const ReferenceTableName = "work_item_code_references"
+
+// TableName implements gorm.tabler
+func (r Reference) TableName() string {
+	return ReferenceTableName
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (r Reference) GetETagData() []interface{} {
+	return []interface{}{r.ID, r.UpdatedAt.Unix()}
+}
+
+// GetLastModified returns the last modification time
+func (r Reference) GetLastModified() time.Time {
+	return r.UpdatedAt.Truncate(time.Second)
+}
+
+// ReferenceRepository describes interactions with the code references
+type ReferenceRepository interface {
+	// Upsert stores the given reference, or updates the title, URL, author
+	// and state of the existing reference of the same commit or pull request
+	// to the work item
+	Upsert(ctx context.Context, r *Reference) error
+	// ListByWorkItem returns the references to the given work item, most
+	// recently updated first
+	ListByWorkItem(ctx context.Context, workItemID uuid.UUID) ([]Reference, error)
+}
+
+// NewReferenceRepository creates a new storage type.
+func NewReferenceRepository(db *gorm.DB) ReferenceRepository {
+	return &GormReferenceRepository{db: db}
+}
+
+// GormReferenceRepository is the implementation of the storage interface for
+// the code references.
+type GormReferenceRepository struct {
+	db *gorm.DB
+}
+
+// Upsert stores or updates the given reference
+func (m *GormReferenceRepository) Upsert(ctx context.Context, r *Reference) error {
+	defer goa.MeasureSince([]string{"goa", "db", "code_reference", "upsert"}, time.Now())
+	upsertStmt := `INSERT INTO ` + ReferenceTableName + ` (work_item_id, codebase_id, kind, ref, title, url, author, state)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (work_item_id, codebase_id, kind, ref) DO UPDATE SET
+		title = EXCLUDED.title, url = EXCLUDED.url, author = EXCLUDED.author, state = EXCLUDED.state, updated_at = now()`
+	err := m.db.Exec(upsertStmt, r.WorkItemID, r.CodebaseID, r.Kind, r.Ref, r.Title, r.URL, r.Author, r.State).Error
+	if err != nil {
+		if gormsupport.IsForeignKeyViolation(err, "work_item_code_references_work_item_id_fkey") {
+			return errors.NewNotFoundError("work item", r.WorkItemID.String())
+		}
+		if gormsupport.IsForeignKeyViolation(err, "work_item_code_references_codebase_id_fkey") {
+			return errors.NewNotFoundError("codebase", r.CodebaseID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"wi_id":       r.WorkItemID,
+			"codebase_id": r.CodebaseID,
+			"kind":        r.Kind,
+			"ref":         r.Ref,
+			"err":         err,
+		}, "unable to store the code reference")
+		return errors.NewInternalError(ctx, err)
+	}
+	err = m.db.Where("work_item_id = ? AND codebase_id = ? AND kind = ? AND ref = ?", r.WorkItemID, r.CodebaseID, r.Kind, r.Ref).First(r).Error
+	if err != nil {
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// ListByWorkItem returns the references to the work item
+func (m *GormReferenceRepository) ListByWorkItem(ctx context.Context, workItemID uuid.UUID) ([]Reference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "code_reference", "list_by_workitem"}, time.Now())
+	var res []Reference
+	if err := m.db.Where("work_item_id = ?", workItemID).Order("updated_at DESC, id").Find(&res).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"wi_id": workItemID,
+			"err":   err,
+		}, "unable to list the code references of the work item")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,93 @@
+package codebase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestReferenceRepository struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunReferenceRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestReferenceRepository{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestReferenceRepository) TestUpsert() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(1), tf.Codebases(1))
+	repo := codebase.NewReferenceRepository(s.DB)
+	newReference := func() codebase.Reference {
+		return codebase.Reference{
+			WorkItemID: fxt.WorkItems[0].ID,
+			CodebaseID: fxt.Codebases[0].ID,
+			Kind:       codebase.ReferenceKindPullRequest,
+			Ref:        "7",
+			Title:      "Fix the login page",
+			State:      "open",
+		}
+	}
+
+	s.T().Run("create and update", func(t *testing.T) {
+		r := newReference()
+		require.NoError(t, repo.Upsert(context.Background(), &r))
+		require.NotEqual(t, uuid.Nil, r.ID)
+		merged := newReference()
+		merged.State = "merged"
+		require.NoError(t, repo.Upsert(context.Background(), &merged))
+		assert.Equal(t, r.ID, merged.ID)
+		assert.Equal(t, "merged", merged.State)
+
+		refs, err := repo.ListByWorkItem(context.Background(), fxt.WorkItems[0].ID)
+		require.NoError(t, err)
+		require.Len(t, refs, 1)
+		assert.Equal(t, "merged", refs[0].State)
+	})
+
+	s.T().Run("unknown work item", func(t *testing.T) {
+		r := newReference()
+		r.WorkItemID = uuid.NewV4()
+		err := repo.Upsert(context.Background(), &r)
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+
+	s.T().Run("unknown codebase", func(t *testing.T) {
+		r := newReference()
+		r.CodebaseID = uuid.NewV4()
+		err := repo.Upsert(context.Background(), &r)
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *TestReferenceRepository) TestListByWorkItem() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(2), tf.Codebases(1))
+	repo := codebase.NewReferenceRepository(s.DB)
+	for _, ref := range []string{"6f5a2b2c", "9e8f7a6b"} {
+		r := codebase.Reference{
+			WorkItemID: fxt.WorkItems[0].ID,
+			CodebaseID: fxt.Codebases[0].ID,
+			Kind:       codebase.ReferenceKindCommit,
+			Ref:        ref,
+		}
+		require.NoError(s.T(), repo.Upsert(context.Background(), &r))
+	}
+
+	refs, err := repo.ListByWorkItem(context.Background(), fxt.WorkItems[0].ID)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), refs, 2)
+	refs, err = repo.ListByWorkItem(context.Background(), fxt.WorkItems[1].ID)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), refs)
+}
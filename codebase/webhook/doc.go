@@ -0,0 +1,4 @@
+// Package webhook parses the push and pull request webhook payloads sent by
+// GitHub and GitLab for the repositories registered as codebases, and the
+// references to work items in their messages.
+package webhook
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// referenceRegexp matches "owner/space#number" and "space#number" when not
+// part of a word, an email address or a URL path
+var referenceRegexp = regexp.MustCompile(`(?:^|[^\w/.#@-])(?:([A-Za-z0-9][\w.-]*)/)?([A-Za-z0-9][\w.-]*)#([0-9]+)\b`)
+
+// Reference is a reference to a work item in a commit or pull request
+// message, with the "owner/space#number" scheme. The owner is optional.
+type Reference struct {
+	Owner  string
+	Space  string
+	Number int
+}
+
+// String implements fmt.Stringer
+func (r Reference) String() string {
+	if r.Owner == "" {
+		return r.Space + "#" + strconv.Itoa(r.Number)
+	}
+	return r.Owner + "/" + r.Space + "#" + strconv.Itoa(r.Number)
+}
+
+// ParseReferences returns the distinct work item references in the given text
+// in the order of their first appearance. Only the space names made of
+// letters, digits, ".", "_" and "-" can be referenced.
+func ParseReferences(text string) []Reference {
+	res := []Reference{}
+	seen := map[Reference]struct{}{}
+	for _, m := range referenceRegexp.FindAllStringSubmatch(text, -1) {
+		number, err := strconv.Atoi(m[3])
+		if err != nil {
+			continue
+		}
+		r := Reference{Owner: m[1], Space: m[2], Number: number}
+		if _, ok := seen[r]; ok {
+			continue
+		}
+		seen[r] = struct{}{}
+		res = append(res, r)
+	}
+	return res
+}
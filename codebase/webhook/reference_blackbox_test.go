@@ -0,0 +1,45 @@
+package webhook_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/codebase/webhook"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReferences(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	testData := []struct {
+		name     string
+		text     string
+		expected []webhook.Reference
+	}{
+		{"empty", "", []webhook.Reference{}},
+		{"no reference", "fix the build", []webhook.Reference{}},
+		{"space", "fixes myspace#123", []webhook.Reference{{Space: "myspace", Number: 123}}},
+		{"owner and space", "closes alice/my-space#4", []webhook.Reference{{Owner: "alice", Space: "my-space", Number: 4}}},
+		{"multiple references", "a#1,b#2\nbob/c.d#3", []webhook.Reference{
+			{Space: "a", Number: 1},
+			{Space: "b", Number: 2},
+			{Owner: "bob", Space: "c.d", Number: 3},
+		}},
+		{"duplicate references", "myspace#1 (myspace#1)", []webhook.Reference{{Space: "myspace", Number: 1}}},
+		{"issue of the repository", "fixes #12", []webhook.Reference{}},
+		{"URL", "see https://github.com/alice/repo#12", []webhook.Reference{}},
+		{"email address", "by alice@myspace#1", []webhook.Reference{}},
+	}
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			assert.Equal(t, td.expected, webhook.ParseReferences(td.text))
+		})
+	}
+}
+
+func TestReferenceString(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	assert.Equal(t, "myspace#1", webhook.Reference{Space: "myspace", Number: 1}.String())
+	assert.Equal(t, "alice/myspace#1", webhook.Reference{Owner: "alice", Space: "myspace", Number: 1}.String())
+}
@@ -0,0 +1,276 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+)
+
+// The states of a pull request
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+	StateMerged = "merged"
+)
+
+// Event is a push or pull request event of a repository, in the same form for
+// all the providers
+type Event struct {
+	// RepositoryURLs are the URLs the repository may have been registered
+	// with as a codebase
+	RepositoryURLs []string
+	// Commits are the commits pushed, if any
+	Commits []Commit
+	// PullRequest is the pull request that changed, if any
+	PullRequest *PullRequest
+}
+
+// Commit is a commit pushed to a repository
+type Commit struct {
+	ID      string
+	Message string
+	URL     string
+	Author  string
+}
+
+// PullRequest is a pull request (or merge request) of a repository
+type PullRequest struct {
+	Number      int
+	Title       string
+	Description string
+	URL         string
+	Author      string
+	// State is one of StateOpen, StateClosed and StateMerged
+	State string
+}
+
+// Verify returns an unauthorized error unless the payload was sent by a
+// webhook configured with the given secret: GitHub signs the payload with the
+// secret in the X-Hub-Signature-256 header, GitLab sends the secret in the
+// X-Gitlab-Token header. The legacy SHA-1 signatures of GitHub are rejected.
+func Verify(header http.Header, body []byte, secret string) error {
+	if secret == "" {
+		return errors.NewUnauthorizedError("missing webhook secret")
+	}
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return errors.NewUnauthorizedError("invalid webhook token")
+		}
+		return nil
+	}
+	if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+		return verifySignature(signature, body, secret)
+	}
+	return errors.NewUnauthorizedError("missing webhook signature")
+}
+
+func verifySignature(signature string, body []byte, secret string) error {
+	const prefix = "sha256="
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil || !strings.HasPrefix(signature, prefix) {
+		return errors.NewUnauthorizedError("invalid webhook signature")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return errors.NewUnauthorizedError("invalid webhook signature")
+	}
+	return nil
+}
+
+// Parse returns the event of the given payload, or nil if it is neither a push
+// nor a pull request event (e.g. the "ping" event GitHub sends when the webhook
+// is created)
+func Parse(header http.Header, body []byte) (*Event, error) {
+	var e *Event
+	var err error
+	if event := header.Get("X-Gitlab-Event"); event != "" {
+		switch event {
+		case "Push Hook":
+			e, err = parseGitLabPush(body)
+		case "Merge Request Hook":
+			e, err = parseGitLabMergeRequest(body)
+		default:
+			return nil, nil
+		}
+	} else {
+		switch header.Get("X-GitHub-Event") {
+		case "push":
+			e, err = parseGitHubPush(body)
+		case "pull_request":
+			e, err = parseGitHubPullRequest(body)
+		default:
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, errors.NewBadParameterError("payload", string(body)).Expected("webhook payload: " + err.Error())
+	}
+	return e, nil
+}
+
+type githubRepository struct {
+	HTMLURL  string `json:"html_url"`
+	CloneURL string `json:"clone_url"`
+	SSHURL   string `json:"ssh_url"`
+	GitURL   string `json:"git_url"`
+}
+
+func (r githubRepository) urls() []string {
+	return distinctURLs(r.CloneURL, r.HTMLURL, r.SSHURL, r.GitURL)
+}
+
+func parseGitHubPush(body []byte) (*Event, error) {
+	var payload struct {
+		Repository githubRepository `json:"repository"`
+		Commits    []struct {
+			ID      string `json:"id"`
+			Message string `json:"message"`
+			URL     string `json:"url"`
+			Author  struct {
+				Name     string `json:"name"`
+				Username string `json:"username"`
+			} `json:"author"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	e := &Event{RepositoryURLs: payload.Repository.urls()}
+	for _, c := range payload.Commits {
+		author := c.Author.Username
+		if author == "" {
+			author = c.Author.Name
+		}
+		e.Commits = append(e.Commits, Commit{ID: c.ID, Message: c.Message, URL: c.URL, Author: author})
+	}
+	return e, nil
+}
+
+func parseGitHubPullRequest(body []byte) (*Event, error) {
+	var payload struct {
+		Repository  githubRepository `json:"repository"`
+		PullRequest struct {
+			Number  int    `json:"number"`
+			Title   string `json:"title"`
+			Body    string `json:"body"`
+			HTMLURL string `json:"html_url"`
+			State   string `json:"state"`
+			Merged  bool   `json:"merged"`
+			User    struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	pr := payload.PullRequest
+	state := StateOpen
+	if pr.Merged {
+		state = StateMerged
+	} else if pr.State == "closed" {
+		state = StateClosed
+	}
+	return &Event{
+		RepositoryURLs: payload.Repository.urls(),
+		PullRequest: &PullRequest{
+			Number:      pr.Number,
+			Title:       pr.Title,
+			Description: pr.Body,
+			URL:         pr.HTMLURL,
+			Author:      pr.User.Login,
+			State:       state,
+		},
+	}, nil
+}
+
+type gitlabProject struct {
+	WebURL     string `json:"web_url"`
+	GitHTTPURL string `json:"git_http_url"`
+	GitSSHURL  string `json:"git_ssh_url"`
+}
+
+func (p gitlabProject) urls() []string {
+	return distinctURLs(p.GitHTTPURL, p.WebURL, p.GitSSHURL)
+}
+
+func parseGitLabPush(body []byte) (*Event, error) {
+	var payload struct {
+		Project gitlabProject `json:"project"`
+		Commits []struct {
+			ID      string `json:"id"`
+			Message string `json:"message"`
+			URL     string `json:"url"`
+			Author  struct {
+				Name string `json:"name"`
+			} `json:"author"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	e := &Event{RepositoryURLs: payload.Project.urls()}
+	for _, c := range payload.Commits {
+		e.Commits = append(e.Commits, Commit{ID: c.ID, Message: c.Message, URL: c.URL, Author: c.Author.Name})
+	}
+	return e, nil
+}
+
+func parseGitLabMergeRequest(body []byte) (*Event, error) {
+	var payload struct {
+		User struct {
+			Username string `json:"username"`
+		} `json:"user"`
+		Project          gitlabProject `json:"project"`
+		ObjectAttributes struct {
+			IID         int    `json:"iid"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			URL         string `json:"url"`
+			State       string `json:"state"`
+		} `json:"object_attributes"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	mr := payload.ObjectAttributes
+	state := StateOpen
+	switch mr.State {
+	case "merged":
+		state = StateMerged
+	case "closed":
+		state = StateClosed
+	}
+	return &Event{
+		RepositoryURLs: payload.Project.urls(),
+		PullRequest: &PullRequest{
+			Number:      mr.IID,
+			Title:       mr.Title,
+			Description: mr.Description,
+			URL:         mr.URL,
+			Author:      payload.User.Username,
+			State:       state,
+		},
+	}, nil
+}
+
+// distinctURLs returns the given URLs without the empty and duplicated ones
+func distinctURLs(urls ...string) []string {
+	res := []string{}
+	seen := map[string]struct{}{}
+	for _, u := range urls {
+		if _, ok := seen[u]; ok || u == "" {
+			continue
+		}
+		seen[u] = struct{}{}
+		res = append(res, u)
+	}
+	return res
+}
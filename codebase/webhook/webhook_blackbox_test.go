@@ -0,0 +1,164 @@
+package webhook_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/codebase/webhook"
+	errs "github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	body := []byte(`{"zen":"Keep it logically awesome."}`)
+	sign := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	sha1Mac := hmac.New(sha1.New, []byte("secret"))
+	sha1Mac.Write(body)
+
+	testData := []struct {
+		name   string
+		header http.Header
+		secret string
+		valid  bool
+	}{
+		{"GitHub signature", http.Header{"X-Hub-Signature-256": {sign("secret")}}, "secret", true},
+		{"GitHub legacy signature", http.Header{"X-Hub-Signature": {"sha1=" + hex.EncodeToString(sha1Mac.Sum(nil))}}, "secret", false},
+		{"GitHub wrong signature", http.Header{"X-Hub-Signature-256": {sign("other")}}, "secret", false},
+		{"GitHub malformed signature", http.Header{"X-Hub-Signature-256": {"sha256=zz"}}, "secret", false},
+		{"GitLab token", http.Header{"X-Gitlab-Token": {"secret"}}, "secret", true},
+		{"GitLab wrong token", http.Header{"X-Gitlab-Token": {"other"}}, "secret", false},
+		{"missing signature", http.Header{}, "secret", false},
+		{"missing secret", http.Header{"X-Gitlab-Token": {""}}, "", false},
+	}
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			err := webhook.Verify(td.header, body, td.secret)
+			if td.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			_, ok := errors.Cause(err).(errs.UnauthorizedError)
+			assert.True(t, ok, "expected an unauthorized error: %+v", err)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+
+	t.Run("GitHub push", func(t *testing.T) {
+		e, err := webhook.Parse(http.Header{"X-Github-Event": {"push"}}, []byte(`{
+			"repository": {
+				"html_url": "https://github.com/alice/repo",
+				"clone_url": "https://github.com/alice/repo.git",
+				"ssh_url": "git@github.com:alice/repo.git",
+				"git_url": "git://github.com/alice/repo.git"
+			},
+			"commits": [{
+				"id": "6f5a2b2c",
+				"message": "fixes myspace#1",
+				"url": "https://github.com/alice/repo/commit/6f5a2b2c",
+				"author": {"name": "Alice", "username": "alice"}
+			}]
+		}`))
+		require.NoError(t, err)
+		require.NotNil(t, e)
+		assert.Equal(t, []string{
+			"https://github.com/alice/repo.git",
+			"https://github.com/alice/repo",
+			"git@github.com:alice/repo.git",
+			"git://github.com/alice/repo.git",
+		}, e.RepositoryURLs)
+		assert.Equal(t, []webhook.Commit{{
+			ID:      "6f5a2b2c",
+			Message: "fixes myspace#1",
+			URL:     "https://github.com/alice/repo/commit/6f5a2b2c",
+			Author:  "alice",
+		}}, e.Commits)
+		assert.Nil(t, e.PullRequest)
+	})
+
+	t.Run("GitHub merged pull request", func(t *testing.T) {
+		e, err := webhook.Parse(http.Header{"X-Github-Event": {"pull_request"}}, []byte(`{
+			"action": "closed",
+			"repository": {"clone_url": "https://github.com/alice/repo.git"},
+			"pull_request": {
+				"number": 7,
+				"title": "Fix the login",
+				"body": "closes myspace#1",
+				"html_url": "https://github.com/alice/repo/pull/7",
+				"state": "closed",
+				"merged": true,
+				"user": {"login": "bob"}
+			}
+		}`))
+		require.NoError(t, err)
+		require.NotNil(t, e)
+		assert.Equal(t, []string{"https://github.com/alice/repo.git"}, e.RepositoryURLs)
+		assert.Equal(t, &webhook.PullRequest{
+			Number:      7,
+			Title:       "Fix the login",
+			Description: "closes myspace#1",
+			URL:         "https://github.com/alice/repo/pull/7",
+			Author:      "bob",
+			State:       webhook.StateMerged,
+		}, e.PullRequest)
+	})
+
+	t.Run("GitLab push", func(t *testing.T) {
+		e, err := webhook.Parse(http.Header{"X-Gitlab-Event": {"Push Hook"}}, []byte(`{
+			"project": {
+				"web_url": "https://gitlab.com/alice/repo",
+				"git_http_url": "https://gitlab.com/alice/repo.git",
+				"git_ssh_url": "git@gitlab.com:alice/repo.git"
+			},
+			"commits": [{"id": "b6568db1", "message": "myspace#2", "url": "https://gitlab.com/alice/repo/commit/b6568db1", "author": {"name": "Alice"}}]
+		}`))
+		require.NoError(t, err)
+		require.NotNil(t, e)
+		assert.Equal(t, []string{"https://gitlab.com/alice/repo.git", "https://gitlab.com/alice/repo", "git@gitlab.com:alice/repo.git"}, e.RepositoryURLs)
+		require.Len(t, e.Commits, 1)
+		assert.Equal(t, "Alice", e.Commits[0].Author)
+	})
+
+	t.Run("GitLab open merge request", func(t *testing.T) {
+		e, err := webhook.Parse(http.Header{"X-Gitlab-Event": {"Merge Request Hook"}}, []byte(`{
+			"user": {"username": "bob"},
+			"project": {"git_http_url": "https://gitlab.com/alice/repo.git"},
+			"object_attributes": {"iid": 3, "title": "myspace#2", "url": "https://gitlab.com/alice/repo/merge_requests/3", "state": "opened"}
+		}`))
+		require.NoError(t, err)
+		require.NotNil(t, e.PullRequest)
+		assert.Equal(t, 3, e.PullRequest.Number)
+		assert.Equal(t, webhook.StateOpen, e.PullRequest.State)
+		assert.Equal(t, "bob", e.PullRequest.Author)
+	})
+
+	t.Run("other event", func(t *testing.T) {
+		e, err := webhook.Parse(http.Header{"X-Github-Event": {"ping"}}, []byte(`{"zen": "Keep it logically awesome."}`))
+		require.NoError(t, err)
+		assert.Nil(t, e)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		_, err := webhook.Parse(http.Header{"X-Github-Event": {"push"}}, []byte(`{"commits": 1}`))
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.BadParameterError)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,7 @@
+// Package workspace keeps track of the Che workspaces created and opened for
+// the codebases, enforces the workspace policies of the spaces (maximum
+// number of running workspaces and maximum runtime) and periodically
+// reconciles the workspaces with che-starter: the workspaces running for
+// longer than the maximum runtime are stopped and the workspaces of deleted
+// codebases are removed.
+package workspace
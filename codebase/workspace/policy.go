@@ -0,0 +1,101 @@
+package workspace
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/space/policy"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Policy limits the workspaces of the codebases of a space. A zero value
+// means no limit.
+type Policy struct {
+	SpaceID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	// MaxRunningWorkspaces is the maximum number of workspaces running at the
+	// same time for the codebases of the space
+	MaxRunningWorkspaces int
+	// MaxRuntimeMinutes is the number of minutes after which a running
+	// workspace is stopped, counted from its start. Che doesn't report the
+	// activity in the workspaces, so it is stopped even if still in use.
+	MaxRuntimeMinutes int
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// PolicyTableName constant that holds table name of the workspace policies
+const PolicyTableName = "space_workspace_policies"
+
+// TableName implements gorm.tabler
+func (p Policy) TableName() string {
+	return PolicyTableName
+}
+
+// MaxRuntime returns the maximum runtime of the workspaces of the policy
+func (p Policy) MaxRuntime() time.Duration {
+	return time.Duration(p.MaxRuntimeMinutes) * time.Minute
+}
+
+// Allows returns true if the policy allows one more workspace to run when
+// the given number of workspaces are already running
+func (p Policy) Allows(running int) bool {
+	return p.MaxRunningWorkspaces == 0 || running < p.MaxRunningWorkspaces
+}
+
+// PolicyRepository describes interactions with the workspace policies
+type PolicyRepository interface {
+	// Load returns the policy of the space, which is unlimited if none was
+	// saved
+	Load(ctx context.Context, spaceID uuid.UUID) (*Policy, error)
+	// LoadForUpdate returns the policy of the space like Load and locks it
+	// until the end of the transaction, so that the workspaces of the space
+	// are started one at a time
+	LoadForUpdate(ctx context.Context, spaceID uuid.UUID) (*Policy, error)
+	// Save creates or replaces the policy of the space
+	Save(ctx context.Context, p *Policy) error
+}
+
+// NewPolicyRepository creates a new storage type.
+func NewPolicyRepository(db *gorm.DB) PolicyRepository {
+	return &GormPolicyRepository{db: db}
+}
+
+// GormPolicyRepository is the implementation of the storage interface for
+// the workspace policies.
+type GormPolicyRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the policy of the space
+func (r *GormPolicyRepository) Load(ctx context.Context, spaceID uuid.UUID) (*Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workspace_policy", "load"}, time.Now())
+	p := Policy{SpaceID: spaceID}
+	if err := policy.Load(ctx, r.db, PolicyTableName, spaceID, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// LoadForUpdate returns the policy of the space and locks it
+func (r *GormPolicyRepository) LoadForUpdate(ctx context.Context, spaceID uuid.UUID) (*Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workspace_policy", "load_for_update"}, time.Now())
+	p := Policy{SpaceID: spaceID}
+	// SELECT ... FOR UPDATE locks the row until the surrounding transaction
+	// ends. There is nothing to lock for the spaces without policy, which
+	// are unlimited.
+	if err := policy.Load(ctx, r.db.Set("gorm:query_option", "FOR UPDATE"), PolicyTableName, spaceID, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Save creates or replaces the policy of the space
+func (r *GormPolicyRepository) Save(ctx context.Context, p *Policy) error {
+	defer goa.MeasureSince([]string{"goa", "db", "workspace_policy", "save"}, time.Now())
+	return policy.Save(ctx, r.db, PolicyTableName, p.SpaceID, p,
+		policy.Setting{Column: "max_running_workspaces", Attribute: "max-running-workspaces", Value: p.MaxRunningWorkspaces},
+		policy.Setting{Column: "max_runtime_minutes", Attribute: "max-runtime-minutes", Value: p.MaxRuntimeMinutes},
+	)
+}
@@ -0,0 +1,143 @@
+package workspace
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/codebase/che"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/models"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/robfig/cron"
+)
+
+// ClientProvider returns the che-starter client for the given namespace
+type ClientProvider func(namespace string) che.Client
+
+// Result is the outcome of a reconciliation
+type Result struct {
+	// Stopped is the number of workspaces that were stopped after running for
+	// the maximum runtime
+	Stopped int
+	// Removed is the number of workspaces of deleted codebases that were
+	// removed
+	Removed int
+}
+
+// Reconciler periodically stops the expired workspaces and removes the
+// workspaces of the deleted codebases
+type Reconciler struct {
+	db      *gorm.DB
+	clients ClientProvider
+	cr      *cron.Cron
+}
+
+// NewReconciler creates a new Reconciler calling che-starter with the
+// clients returned by the given provider
+func NewReconciler(db *gorm.DB, clients ClientProvider) *Reconciler {
+	return &Reconciler{db: db, clients: clients, cr: cron.New()}
+}
+
+// Start runs the reconciliation according to the given cron schedule (e.g.
+// "@every 5m"). The workspaces are never reconciled if the schedule is empty.
+func (r *Reconciler) Start(schedule string) error {
+	if schedule == "" {
+		log.Info(nil, map[string]interface{}{}, "workspace reconcile schedule is not set, the workspaces are never reconciled")
+		return nil
+	}
+	err := r.cr.AddFunc(schedule, func() {
+		if _, err := r.Reconcile(context.Background()); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err": err,
+			}, "failed to reconcile the workspaces")
+		}
+	})
+	if err != nil {
+		return errs.Wrapf(err, "invalid workspace reconcile schedule '%s'", schedule)
+	}
+	r.cr.Start()
+	return nil
+}
+
+// Stop stops the reconciler.
+// This should be called only from main
+func (r *Reconciler) Stop() {
+	r.cr.Stop()
+}
+
+// Reconcile removes the workspaces of the deleted codebases and stops the
+// workspaces running for longer than the maximum runtime. A workspace that che-starter fails to remove or stop is
+// retried on the next run.
+func (r *Reconciler) Reconcile(ctx context.Context) (*Result, error) {
+	var orphans, expired []Workspace
+	err := models.Transactional(r.db, func(tx *gorm.DB) error {
+		var err error
+		orphans, err = NewRepository(tx).ListOrphans(ctx)
+		if err != nil {
+			return err
+		}
+		expired, err = NewRepository(tx).ListExpired(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := Result{}
+	for _, w := range orphans {
+		// che-starter is called outside of any transaction
+		err := r.clients(w.Namespace).DeleteWorkspace(ctx, w.Name)
+		if err != nil && !isNotFound(err) {
+			log.Error(ctx, map[string]interface{}{
+				"codebase_id": w.CodebaseID,
+				"namespace":   w.Namespace,
+				"name":        w.Name,
+				"err":         err,
+			}, "failed to remove the workspace of the deleted codebase")
+			continue
+		}
+		w := w
+		err = models.Transactional(r.db, func(tx *gorm.DB) error {
+			return NewRepository(tx).Delete(ctx, w.CodebaseID, w.Namespace, w.Name)
+		})
+		if err != nil {
+			return &res, errs.Wrapf(err, "failed to forget the workspace %s", w.Name)
+		}
+		res.Removed++
+	}
+	for _, w := range expired {
+		err := r.clients(w.Namespace).StopWorkspace(ctx, w.Name)
+		if err != nil && !isNotFound(err) {
+			log.Error(ctx, map[string]interface{}{
+				"codebase_id": w.CodebaseID,
+				"namespace":   w.Namespace,
+				"name":        w.Name,
+				"err":         err,
+			}, "failed to stop the expired workspace")
+			continue
+		}
+		w := w
+		w.Running = false
+		err = models.Transactional(r.db, func(tx *gorm.DB) error {
+			return NewRepository(tx).Save(ctx, &w)
+		})
+		if err != nil {
+			return &res, errs.Wrapf(err, "failed to record the stop of the workspace %s", w.Name)
+		}
+		res.Stopped++
+	}
+	log.Info(ctx, map[string]interface{}{
+		"removed": res.Removed,
+		"stopped": res.Stopped,
+	}, "reconciled the workspaces")
+	return &res, nil
+}
+
+// isNotFound returns true if che-starter doesn't know the workspace anymore
+func isNotFound(err error) bool {
+	if e, ok := err.(*che.StarterError); ok {
+		return e.Status == http.StatusNotFound
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package workspace_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/codebase/che"
+	"github.com/fabric8-services/fabric8-wit/codebase/workspace"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	chesupport "github.com/fabric8-services/fabric8-wit/test/che"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestReconciler struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunReconciler(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestReconciler{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+func (s *TestReconciler) TestReconcile() {
+	// the first codebase has an expired and an active workspace, the second one
+	// was deleted along with its workspaces, one of which is already unknown
+	// to che-starter
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Codebases(2))
+	starter := chesupport.NewFakeStarter()
+	defer starter.Close()
+	starter.AddWorkspace("wksp-expired", fxt.Codebases[0].URL, chesupport.StatusRunning)
+	starter.AddWorkspace("wksp-active", fxt.Codebases[0].URL, chesupport.StatusRunning)
+	starter.AddWorkspace("wksp-orphan", fxt.Codebases[1].URL, chesupport.StatusStopped)
+	repo := workspace.NewRepository(s.DB)
+	for _, w := range []workspace.Workspace{
+		{CodebaseID: fxt.Codebases[0].ID, Namespace: "user-che", Name: "wksp-expired", Running: true},
+		{CodebaseID: fxt.Codebases[0].ID, Namespace: "user-che", Name: "wksp-active", Running: true},
+		{CodebaseID: fxt.Codebases[1].ID, Namespace: "user-che", Name: "wksp-orphan", Running: false},
+		{CodebaseID: fxt.Codebases[1].ID, Namespace: "user-che", Name: "wksp-gone", Running: false},
+	} {
+		w := w
+		require.NoError(s.T(), repo.Save(context.Background(), &w))
+	}
+	require.NoError(s.T(), s.DB.Exec("UPDATE codebase_workspaces SET started_at = now() - interval '2 hours' WHERE codebase_id = ? AND name = ?", fxt.Codebases[0].ID, "wksp-expired").Error)
+	require.NoError(s.T(), workspace.NewPolicyRepository(s.DB).Save(context.Background(), &workspace.Policy{SpaceID: fxt.Spaces[0].ID, MaxRuntimeMinutes: 60}))
+	require.NoError(s.T(), codebase.NewCodebaseRepository(s.DB).Delete(context.Background(), fxt.Codebases[1].ID))
+	var namespaces []string
+	reconciler := workspace.NewReconciler(s.DB, func(namespace string) che.Client {
+		namespaces = append(namespaces, namespace)
+		return che.NewStarterServiceClient(starter.URL, "https://openshift.example.com", namespace, "service-token", http.DefaultClient)
+	})
+
+	res, err := reconciler.Reconcile(context.Background())
+	require.NoError(s.T(), err)
+	// other tests may have left expired or orphan workspaces
+	assert.True(s.T(), res.Stopped >= 1)
+	assert.True(s.T(), res.Removed >= 2)
+	assert.Equal(s.T(), chesupport.StatusStopped, starter.Status("wksp-expired"))
+	assert.Equal(s.T(), chesupport.StatusRunning, starter.Status("wksp-active"))
+	assert.Equal(s.T(), "", starter.Status("wksp-orphan"))
+	assert.Contains(s.T(), namespaces, "user-che")
+	for _, token := range starter.Tokens {
+		assert.Equal(s.T(), "service-token", token)
+	}
+
+	running, err := repo.ListRunning(context.Background(), fxt.Spaces[0].ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"wksp-active"}, names(running, fxt.Codebases...))
+	orphans, err := repo.ListOrphans(context.Background())
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), names(orphans, fxt.Codebases...))
+
+	s.T().Run("nothing left to reconcile", func(t *testing.T) {
+		requests := len(starter.Requests)
+		_, err := reconciler.Reconcile(context.Background())
+		require.NoError(t, err)
+		for _, r := range starter.Requests[requests:] {
+			assert.NotContains(t, r, "wksp-expired")
+			assert.NotContains(t, r, "wksp-orphan")
+		}
+	})
+}
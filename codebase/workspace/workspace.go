@@ -0,0 +1,259 @@
+package workspace
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Workspace is a Che workspace created or opened for a codebase
+type Workspace struct {
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	CodebaseID uuid.UUID `sql:"type:uuid"`
+	// Namespace is the Che namespace of the user who owns the workspace
+	Namespace string
+	Name      string
+	Running   bool
+	// StartedAt is when the running workspace was started, nil if it is
+	// stopped
+	StartedAt *time.Time
+	// LastActivityAt is when the workspace was last created, opened, started
+	// or stopped
+	LastActivityAt time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TableName constant that holds table name of the workspaces
+const TableName = "codebase_workspaces"
+
+// ReservationTableName constant that holds table name of the reservations of
+// running workspaces
+const ReservationTableName = "codebase_workspace_reservations"
+
+// ReservationTimeout is how long a running workspace stays reserved in a space
+// if the reservation is never released, e.g. because the service stopped while
+// che-starter was starting the workspace
+const ReservationTimeout = 10 * time.Minute
+
+// TableName implements gorm.tabler
+func (w Workspace) TableName() string {
+	return TableName
+}
+
+// Repository describes interactions with the workspaces
+type Repository interface {
+	// Save records an activity on the given workspace, along with whether it
+	// is running
+	Save(ctx context.Context, w *Workspace) error
+	// Delete forgets the workspace of the given codebase
+	Delete(ctx context.Context, codebaseID uuid.UUID, namespace, name string) error
+	// ListRunning returns the running workspaces of the codebases of the space
+	ListRunning(ctx context.Context, spaceID uuid.UUID) ([]Workspace, error)
+	// ListExpired returns the workspaces that are running for longer than the
+	// maximum runtime of the policy of their space
+	ListExpired(ctx context.Context) ([]Workspace, error)
+	// ListOrphans returns the workspaces of the deleted codebases
+	ListOrphans(ctx context.Context) ([]Workspace, error)
+	// Reserve reserves a running workspace in the space for a workspace about
+	// to be started, until the reservation is released or ReservationTimeout
+	// elapsed
+	Reserve(ctx context.Context, spaceID uuid.UUID) (uuid.UUID, error)
+	// Release releases the given reservation
+	Release(ctx context.Context, reservationID uuid.UUID) error
+	// CountReservations returns the number of running workspaces currently
+	// reserved in the space
+	CountReservations(ctx context.Context, spaceID uuid.UUID) (int, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for the
+// workspaces.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Save records an activity on the workspace
+func (r *GormRepository) Save(ctx context.Context, w *Workspace) error {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_workspace", "save"}, time.Now())
+	// the start time is kept while the workspace keeps running
+	upsertStmt := `INSERT INTO ` + TableName + ` AS w (codebase_id, namespace, name, running, started_at)
+		VALUES (?, ?, ?, ?, CASE WHEN ? THEN now() END)
+		ON CONFLICT (codebase_id, namespace, name) DO UPDATE SET
+		running = EXCLUDED.running,
+		started_at = CASE WHEN NOT EXCLUDED.running THEN NULL WHEN w.running THEN w.started_at ELSE now() END,
+		last_activity_at = now(), updated_at = now()`
+	err := r.db.Exec(upsertStmt, w.CodebaseID, w.Namespace, w.Name, w.Running, w.Running).Error
+	if err != nil {
+		if gormsupport.IsForeignKeyViolation(err, "codebase_workspaces_codebase_id_fkey") {
+			return errors.NewNotFoundError("codebase", w.CodebaseID.String())
+		}
+		if gormsupport.IsCheckViolation(err, "codebase_workspaces_namespace_check") {
+			return errors.NewBadParameterError("namespace", w.Namespace)
+		}
+		if gormsupport.IsCheckViolation(err, "codebase_workspaces_name_check") {
+			return errors.NewBadParameterError("name", w.Name)
+		}
+		log.Error(ctx, map[string]interface{}{
+			"codebase_id": w.CodebaseID,
+			"namespace":   w.Namespace,
+			"name":        w.Name,
+			"err":         err,
+		}, "unable to store the workspace")
+		return errors.NewInternalError(ctx, err)
+	}
+	err = r.db.Where("codebase_id = ? AND namespace = ? AND name = ?", w.CodebaseID, w.Namespace, w.Name).First(w).Error
+	if err != nil {
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// Delete forgets the workspace
+func (r *GormRepository) Delete(ctx context.Context, codebaseID uuid.UUID, namespace, name string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_workspace", "delete"}, time.Now())
+	db := r.db.Where("codebase_id = ? AND namespace = ? AND name = ?", codebaseID, namespace, name).Delete(&Workspace{})
+	if db.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"codebase_id": codebaseID,
+			"namespace":   namespace,
+			"name":        name,
+			"err":         db.Error,
+		}, "unable to delete the workspace")
+		return errors.NewInternalError(ctx, db.Error)
+	}
+	if db.RowsAffected == 0 {
+		return errors.NewNotFoundError("workspace", name)
+	}
+	return nil
+}
+
+// ListRunning returns the running workspaces of the space
+func (r *GormRepository) ListRunning(ctx context.Context, spaceID uuid.UUID) ([]Workspace, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_workspace", "list_running"}, time.Now())
+	var res []Workspace
+	err := r.db.Select("w.*").Table(TableName+" w").
+		Joins("JOIN "+codebase.Codebase{}.TableName()+" c ON c.id = w.codebase_id AND c.deleted_at IS NULL").
+		Where("c.space_id = ? AND w.running", spaceID).
+		Order("w.last_activity_at").
+		Find(&res).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      err,
+		}, "unable to list the running workspaces of the space")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, nil
+}
+
+// ListExpired returns the workspaces running for longer than the maximum
+// runtime
+func (r *GormRepository) ListExpired(ctx context.Context) ([]Workspace, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_workspace", "list_expired"}, time.Now())
+	var res []Workspace
+	err := r.db.Select("w.*").Table(TableName + " w").
+		Joins("JOIN " + codebase.Codebase{}.TableName() + " c ON c.id = w.codebase_id AND c.deleted_at IS NULL").
+		Joins("JOIN " + PolicyTableName + " p ON p.space_id = c.space_id").
+		Where("w.running AND p.max_runtime_minutes > 0").
+		Where("w.started_at < now() - p.max_runtime_minutes * interval '1 minute'").
+		Order("w.started_at").
+		Find(&res).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to list the expired workspaces")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, nil
+}
+
+// ListOrphans returns the workspaces of the deleted codebases
+func (r *GormRepository) ListOrphans(ctx context.Context) ([]Workspace, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_workspace", "list_orphans"}, time.Now())
+	var res []Workspace
+	err := r.db.Select("w.*").Table(TableName + " w").
+		Joins("JOIN " + codebase.Codebase{}.TableName() + " c ON c.id = w.codebase_id").
+		Where("c.deleted_at IS NOT NULL").
+		Order("w.codebase_id, w.namespace, w.name").
+		Find(&res).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to list the workspaces of the deleted codebases")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, nil
+}
+
+// Reserve reserves a running workspace in the space and forgets the expired
+// reservations of the space
+func (r *GormRepository) Reserve(ctx context.Context, spaceID uuid.UUID) (uuid.UUID, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_workspace", "reserve"}, time.Now())
+	err := r.db.Exec("DELETE FROM "+ReservationTableName+" WHERE space_id = ? AND created_at < ?", spaceID, time.Now().Add(-ReservationTimeout)).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      err,
+		}, "unable to delete the expired workspace reservations of the space")
+		return uuid.Nil, errors.NewInternalError(ctx, err)
+	}
+	var res struct {
+		ID uuid.UUID
+	}
+	err = r.db.Raw("INSERT INTO "+ReservationTableName+" (space_id) VALUES (?) RETURNING id", spaceID).Scan(&res).Error
+	if err != nil {
+		if gormsupport.IsForeignKeyViolation(err, "codebase_workspace_reservations_space_id_fkey") {
+			return uuid.Nil, errors.NewNotFoundError("space", spaceID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      err,
+		}, "unable to reserve a workspace in the space")
+		return uuid.Nil, errors.NewInternalError(ctx, err)
+	}
+	return res.ID, nil
+}
+
+// Release releases the reservation
+func (r *GormRepository) Release(ctx context.Context, reservationID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_workspace", "release"}, time.Now())
+	err := r.db.Exec("DELETE FROM "+ReservationTableName+" WHERE id = ?", reservationID).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"reservation_id": reservationID,
+			"err":            err,
+		}, "unable to release the workspace reservation")
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// CountReservations returns the number of reservations of the space that did
+// not expire
+func (r *GormRepository) CountReservations(ctx context.Context, spaceID uuid.UUID) (int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase_workspace", "count_reservations"}, time.Now())
+	var count int
+	err := r.db.Table(ReservationTableName).
+		Where("space_id = ? AND created_at >= ?", spaceID, time.Now().Add(-ReservationTimeout)).
+		Count(&count).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      err,
+		}, "unable to count the workspace reservations of the space")
+		return 0, errors.NewInternalError(ctx, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,249 @@
+package workspace_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/codebase/workspace"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkspaceRepository struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunWorkspaceRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWorkspaceRepository{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+// names returns the names of the given workspaces of the codebases
+func names(workspaces []workspace.Workspace, codebases ...*codebase.Codebase) []string {
+	var res []string
+	for _, w := range workspaces {
+		for _, cb := range codebases {
+			if w.CodebaseID == cb.ID {
+				res = append(res, w.Name)
+			}
+		}
+	}
+	return res
+}
+
+func (s *TestWorkspaceRepository) TestSave() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Codebases(1))
+	repo := workspace.NewRepository(s.DB)
+
+	s.T().Run("create and update", func(t *testing.T) {
+		w := workspace.Workspace{CodebaseID: fxt.Codebases[0].ID, Namespace: "user-che", Name: "wksp-1", Running: true}
+		require.NoError(t, repo.Save(context.Background(), &w))
+		assert.NotEqual(t, uuid.Nil, w.ID)
+		assert.True(t, w.Running)
+		created := w
+
+		w = workspace.Workspace{CodebaseID: fxt.Codebases[0].ID, Namespace: "user-che", Name: "wksp-1", Running: false}
+		require.NoError(t, repo.Save(context.Background(), &w))
+		assert.Equal(t, created.ID, w.ID)
+		assert.False(t, w.Running)
+		assert.False(t, w.LastActivityAt.Before(created.LastActivityAt))
+	})
+
+	s.T().Run("start time", func(t *testing.T) {
+		w := workspace.Workspace{CodebaseID: fxt.Codebases[0].ID, Namespace: "user-che", Name: "wksp-2", Running: true}
+		require.NoError(t, repo.Save(context.Background(), &w))
+		require.NotNil(t, w.StartedAt)
+		started := *w.StartedAt
+
+		// opening the running workspace again keeps its start time
+		require.NoError(t, repo.Save(context.Background(), &w))
+		require.NotNil(t, w.StartedAt)
+		assert.True(t, started.Equal(*w.StartedAt))
+
+		w.Running = false
+		require.NoError(t, repo.Save(context.Background(), &w))
+		assert.Nil(t, w.StartedAt)
+	})
+
+	s.T().Run("unknown codebase", func(t *testing.T) {
+		err := repo.Save(context.Background(), &workspace.Workspace{CodebaseID: uuid.NewV4(), Namespace: "user-che", Name: "wksp-1"})
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+
+	s.T().Run("empty name", func(t *testing.T) {
+		err := repo.Save(context.Background(), &workspace.Workspace{CodebaseID: fxt.Codebases[0].ID, Namespace: "user-che", Name: " "})
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, err)
+	})
+}
+
+func (s *TestWorkspaceRepository) TestDelete() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Codebases(1))
+	repo := workspace.NewRepository(s.DB)
+	require.NoError(s.T(), repo.Save(context.Background(), &workspace.Workspace{CodebaseID: fxt.Codebases[0].ID, Namespace: "user-che", Name: "wksp-1", Running: true}))
+
+	s.T().Run("ok", func(t *testing.T) {
+		require.NoError(t, repo.Delete(context.Background(), fxt.Codebases[0].ID, "user-che", "wksp-1"))
+		running, err := repo.ListRunning(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, running)
+	})
+
+	s.T().Run("not found", func(t *testing.T) {
+		err := repo.Delete(context.Background(), fxt.Codebases[0].ID, "user-che", "wksp-1")
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *TestWorkspaceRepository) TestList() {
+	// the space has two codebases, the second one being deleted, and the
+	// codebase of another space has a running workspace too
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(2), tf.Codebases(3, func(fxt *tf.TestFixture, idx int) error {
+		if idx == 2 {
+			fxt.Codebases[idx].SpaceID = fxt.Spaces[1].ID
+		} else {
+			fxt.Codebases[idx].SpaceID = fxt.Spaces[0].ID
+		}
+		return nil
+	}))
+	repo := workspace.NewRepository(s.DB)
+	for _, w := range []workspace.Workspace{
+		{CodebaseID: fxt.Codebases[0].ID, Namespace: "user-che", Name: "wksp-expired", Running: true},
+		{CodebaseID: fxt.Codebases[0].ID, Namespace: "user-che", Name: "wksp-active", Running: true},
+		{CodebaseID: fxt.Codebases[0].ID, Namespace: "user-che", Name: "wksp-stopped", Running: false},
+		{CodebaseID: fxt.Codebases[1].ID, Namespace: "user-che", Name: "wksp-orphan", Running: true},
+		{CodebaseID: fxt.Codebases[2].ID, Namespace: "user-che", Name: "wksp-other", Running: true},
+	} {
+		w := w
+		require.NoError(s.T(), repo.Save(context.Background(), &w))
+	}
+	require.NoError(s.T(), s.DB.Exec("UPDATE codebase_workspaces SET started_at = now() - interval '2 hours' WHERE codebase_id = ? AND name = ?", fxt.Codebases[0].ID, "wksp-expired").Error)
+	require.NoError(s.T(), codebase.NewCodebaseRepository(s.DB).Delete(context.Background(), fxt.Codebases[1].ID))
+	require.NoError(s.T(), workspace.NewPolicyRepository(s.DB).Save(context.Background(), &workspace.Policy{SpaceID: fxt.Spaces[0].ID, MaxRuntimeMinutes: 60}))
+
+	s.T().Run("running", func(t *testing.T) {
+		running, err := repo.ListRunning(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"wksp-expired", "wksp-active"}, names(running, fxt.Codebases...))
+	})
+
+	s.T().Run("expired", func(t *testing.T) {
+		expired, err := repo.ListExpired(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"wksp-expired"}, names(expired, fxt.Codebases...))
+	})
+
+	s.T().Run("orphans", func(t *testing.T) {
+		orphans, err := repo.ListOrphans(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"wksp-orphan"}, names(orphans, fxt.Codebases...))
+	})
+}
+
+func (s *TestWorkspaceRepository) TestReservations() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(2))
+	repo := workspace.NewRepository(s.DB)
+
+	s.T().Run("reserve and release", func(t *testing.T) {
+		first, err := repo.Reserve(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		second, err := repo.Reserve(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+		count, err := repo.CountReservations(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		count, err = repo.CountReservations(context.Background(), fxt.Spaces[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		require.NoError(t, repo.Release(context.Background(), first))
+		require.NoError(t, repo.Release(context.Background(), second))
+		count, err = repo.CountReservations(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	s.T().Run("expired", func(t *testing.T) {
+		_, err := repo.Reserve(context.Background(), fxt.Spaces[1].ID)
+		require.NoError(t, err)
+		require.NoError(t, s.DB.Exec("UPDATE codebase_workspace_reservations SET created_at = now() - interval '1 hour' WHERE space_id = ?", fxt.Spaces[1].ID).Error)
+		count, err := repo.CountReservations(context.Background(), fxt.Spaces[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		_, err := repo.Reserve(context.Background(), uuid.NewV4())
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *TestWorkspaceRepository) TestPolicy() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1))
+	repo := workspace.NewPolicyRepository(s.DB)
+
+	s.T().Run("unlimited by default", func(t *testing.T) {
+		p, err := repo.Load(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, fxt.Spaces[0].ID, p.SpaceID)
+		assert.Equal(t, 0, p.MaxRunningWorkspaces)
+		assert.Equal(t, 0, p.MaxRuntimeMinutes)
+		assert.True(t, p.Allows(100))
+	})
+
+	s.T().Run("save and replace", func(t *testing.T) {
+		p := workspace.Policy{SpaceID: fxt.Spaces[0].ID, MaxRunningWorkspaces: 2, MaxRuntimeMinutes: 30}
+		require.NoError(t, repo.Save(context.Background(), &p))
+		p = workspace.Policy{SpaceID: fxt.Spaces[0].ID, MaxRunningWorkspaces: 1, MaxRuntimeMinutes: 0}
+		require.NoError(t, repo.Save(context.Background(), &p))
+		assert.False(t, p.UpdatedAt.IsZero())
+
+		loaded, err := repo.Load(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, loaded.MaxRunningWorkspaces)
+		assert.Equal(t, 0, loaded.MaxRuntimeMinutes)
+		assert.True(t, loaded.Allows(0))
+		assert.False(t, loaded.Allows(1))
+	})
+
+	s.T().Run("locked for update", func(t *testing.T) {
+		tx := s.DB.Begin()
+		defer tx.Rollback()
+		p, err := workspace.NewPolicyRepository(tx).LoadForUpdate(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, p.MaxRunningWorkspaces)
+		// when
+		other := s.DB.Begin()
+		defer other.Rollback()
+		require.NoError(t, other.Exec("SET LOCAL lock_timeout = 100").Error)
+		_, err = workspace.NewPolicyRepository(other).LoadForUpdate(context.Background(), fxt.Spaces[0].ID)
+		// then the policy is locked until the end of the first transaction
+		require.Error(t, err)
+		require.NoError(t, tx.Rollback().Error)
+		_, err = repo.LoadForUpdate(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		err := repo.Save(context.Background(), &workspace.Policy{SpaceID: uuid.NewV4()})
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+
+	s.T().Run("negative runtime", func(t *testing.T) {
+		err := repo.Save(context.Background(), &workspace.Policy{SpaceID: fxt.Spaces[0].ID, MaxRuntimeMinutes: -1})
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, err)
+	})
+}
@@ -85,6 +85,10 @@ const (
 	varCacheControlUsers             = "cachecontrol.users"
 	varCacheControlCollaborators     = "cachecontrol.collaborators"
 	varCacheControlSpaceTemplates    = "cachecontrol.spacetemplates"
+	varCacheControlWatchers          = "cachecontrol.watchers"
+	varCacheControlWorkItemTemplates = "cachecontrol.workitemtemplates"
+	varCacheControlDeployments       = "cachecontrol.deployments"
+	varCacheControlCodeReferences    = "cachecontrol.codereferences"
 
 	// cache control settings for a single resource
 	varCacheControlUser             = "cachecontrol.user"
@@ -111,6 +115,32 @@ const (
 	varDeploymentsServiceURL    = "deployments.serviceurl"
 	varCodebaseServiceURL       = "codebase.serviceurl"
 	varDeploymentsHTTPTimeout   = "deployments.http.timeout"
+	varTrashRetention           = "trash.retention"
+	varTrashPurgeSchedule       = "trash.purge.schedule"
+	varRateLimitEnabled         = "ratelimit.enabled"
+	varEventsRetention          = "events.retention"
+	varEventsPollInterval       = "events.poll.interval"
+	varGraphQLMaxDepth          = "graphql.max.depth"
+	varGraphQLMaxCost           = "graphql.max.cost"
+	varHealthWatchInterval      = "deployments.health.interval"
+	varHealthWatchExpiry        = "deployments.health.expiry"
+	varHealthWatchToken         = "deployments.health.serviceaccount.token"
+	varMetricsBackend           = "deployments.metrics.backend"
+	varPrometheusURL            = "deployments.metrics.prometheus.url"
+	varWebhookMergedState       = "codebase.webhook.mergedstate"
+	varMetadataSchedule         = "codebase.metadata.schedule"
+	varMetadataMaxAge           = "codebase.metadata.maxage"
+	varMetadataTimeout          = "codebase.metadata.http.timeout"
+	varMetadataGitHubURL        = "codebase.metadata.github.url"
+	varMetadataBatchSize        = "codebase.metadata.batchsize"
+	varWorkspaceReconcile       = "codebase.workspaces.reconcile.schedule"
+	varCheServiceAccountToken   = "che.serviceaccount.token"
+	varRetentionSchedule        = "retention.schedule"
+	varRetentionBatchSize       = "retention.batchsize"
+	// the rate (requests per second) and burst of the route groups are set
+	// with e.g. "ratelimit.search.rate" and "ratelimit.search.burst"
+	varRateLimitRate  = "ratelimit.%s.rate"
+	varRateLimitBurst = "ratelimit.%s.burst"
 )
 
 // Registry encapsulates the Viper configuration registry which stores the
@@ -231,6 +261,10 @@ func (c *Registry) setConfigDefaults() {
 	c.v.SetDefault(varCacheControlFilters, "max-age=86400")
 	c.v.SetDefault(varCacheControlUsers, "max-age=2")
 	c.v.SetDefault(varCacheControlCollaborators, "max-age=2")
+	c.v.SetDefault(varCacheControlWatchers, "max-age=2")
+	c.v.SetDefault(varCacheControlWorkItemTemplates, "max-age=2")
+	c.v.SetDefault(varCacheControlDeployments, "max-age=2")
+	c.v.SetDefault(varCacheControlCodeReferences, "max-age=2")
 
 	// Cache control values for a single resource
 	c.v.SetDefault(varCacheControlWorkItem, "private,max-age=2")
@@ -248,6 +282,58 @@ func (c *Registry) setConfigDefaults() {
 	// Features
 	c.v.SetDefault(varFeatureWorkitemRemote, true)
 
+	// Deleted work items and comments are purged after 30 days
+	c.v.SetDefault(varTrashRetention, time.Duration(30*24*time.Hour))
+	c.v.SetDefault(varTrashPurgeSchedule, "@hourly")
+
+	// Rate limits per identity (or client IP) and route group: searches are
+	// the most expensive requests, writes come next
+	c.v.SetDefault(varRateLimitEnabled, true)
+	c.v.SetDefault(fmt.Sprintf(varRateLimitRate, "default"), 20.0)
+	c.v.SetDefault(fmt.Sprintf(varRateLimitBurst, "default"), 100)
+	c.v.SetDefault(fmt.Sprintf(varRateLimitRate, "search"), 2.0)
+	c.v.SetDefault(fmt.Sprintf(varRateLimitBurst, "search"), 20)
+	c.v.SetDefault(fmt.Sprintf(varRateLimitRate, "write"), 5.0)
+	c.v.SetDefault(fmt.Sprintf(varRateLimitBurst, "write"), 50)
+
+	// The events of the spaces are kept long enough for the clients to resume
+	// their stream after a reconnection
+	c.v.SetDefault(varEventsRetention, time.Duration(time.Hour))
+	c.v.SetDefault(varEventsPollInterval, time.Duration(time.Second))
+
+	// Limits of the GraphQL queries: the cost is the number of entities loaded
+	// or listed to resolve a query
+	c.v.SetDefault(varGraphQLMaxDepth, 10)
+	c.v.SetDefault(varGraphQLMaxCost, 5000)
+
+	// The health of the deployments is not watched by default. When enabled, with
+	// a service account token to send the notifications, an environment is
+	// watched for an hour after its health was last requested.
+	c.v.SetDefault(varHealthWatchInterval, time.Duration(0))
+	c.v.SetDefault(varHealthWatchExpiry, time.Hour)
+
+	// The deployment metrics are read from Hawkular by default. The metrics URL
+	// of the cluster is used when no Prometheus URL is set.
+	c.v.SetDefault(varMetricsBackend, MetricsBackendHawkular)
+	c.v.SetDefault(varPrometheusURL, "")
+
+	// The codebase webhooks are disabled until a secret is set, and merged
+	// pull requests only change the state of work items when a state is set.
+	c.v.SetDefault(varWebhookMergedState, "")
+
+	// The metadata of the codebases is refreshed when older than an hour
+	c.v.SetDefault(varMetadataSchedule, "@every 10m")
+	c.v.SetDefault(varMetadataMaxAge, time.Hour)
+	c.v.SetDefault(varMetadataTimeout, 30*time.Second)
+	c.v.SetDefault(varMetadataGitHubURL, "https://api.github.com")
+	c.v.SetDefault(varMetadataBatchSize, 100)
+	c.v.SetDefault(varWorkspaceReconcile, "@every 5m")
+
+	// The retention policies of the spaces are enforced at night, in batches
+	// small enough not to block the users
+	c.v.SetDefault(varRetentionSchedule, "0 0 3 * * *")
+	c.v.SetDefault(varRetentionBatchSize, 500)
+
 	c.v.SetDefault(varKeycloakTesUser2Name, defaultKeycloakTesUser2Name)
 	c.v.SetDefault(varOpenshiftTenantMasterURL, defaultOpenshiftTenantMasterURL)
 	c.v.SetDefault(varCheStarterURL, defaultCheStarterURL)
@@ -406,6 +492,30 @@ func (c *Registry) GetCacheControlEvents() string {
 	return c.v.GetString(varCacheControlWorkItemEvents)
 }
 
+// GetCacheControlWatchers returns the value to set in the "Cache-Control" HTTP response header
+// when returning the list of watchers of a work item.
+func (c *Registry) GetCacheControlWatchers() string {
+	return c.v.GetString(varCacheControlWatchers)
+}
+
+// GetCacheControlDeployments returns the value to set in the "Cache-Control" HTTP response header
+// when returning the deployments recorded in a space.
+func (c *Registry) GetCacheControlDeployments() string {
+	return c.v.GetString(varCacheControlDeployments)
+}
+
+// GetCacheControlCodeReferences returns the value to set in the "Cache-Control" HTTP response header
+// when returning the commits and pull requests referencing a work item.
+func (c *Registry) GetCacheControlCodeReferences() string {
+	return c.v.GetString(varCacheControlCodeReferences)
+}
+
+// GetCacheControlWorkItemTemplates returns the value to set in the "Cache-Control" HTTP response header
+// when returning work item templates.
+func (c *Registry) GetCacheControlWorkItemTemplates() string {
+	return c.v.GetString(varCacheControlWorkItemTemplates)
+}
+
 // GetCacheControlWorkItemTypes returns the value to set in the "Cache-Control" HTTP response header
 // when returning a list of work item types.
 func (c *Registry) GetCacheControlWorkItemTypes() string {
@@ -873,6 +983,71 @@ func (c *Registry) GetCodebaseServiceURL() string {
 	return c.v.GetString(varCodebaseServiceURL)
 }
 
+// GetCodebaseWebhookMergedState returns the state work items are moved to
+// when a pull request referencing them is merged, or an empty string to leave
+// their state unchanged
+func (c *Registry) GetCodebaseWebhookMergedState() string {
+	return c.v.GetString(varWebhookMergedState)
+}
+
+// GetCodebaseMetadataSchedule returns the cron schedule of the job that
+// fetches the metadata of the codebases from their git hosts, or an empty
+// string if the metadata is never fetched
+func (c *Registry) GetCodebaseMetadataSchedule() string {
+	return c.v.GetString(varMetadataSchedule)
+}
+
+// GetCodebaseMetadataMaxAge returns the age after which the metadata of a
+// codebase is fetched again
+func (c *Registry) GetCodebaseMetadataMaxAge() time.Duration {
+	return c.v.GetDuration(varMetadataMaxAge)
+}
+
+// GetCodebaseMetadataTimeout returns the timeout of the requests sent to the
+// git hosts
+func (c *Registry) GetCodebaseMetadataTimeout() time.Duration {
+	return c.v.GetDuration(varMetadataTimeout)
+}
+
+// GetCodebaseMetadataBatchSize returns the maximum number of codebases whose
+// stale metadata is listed at a time
+func (c *Registry) GetCodebaseMetadataBatchSize() int {
+	return c.v.GetInt(varMetadataBatchSize)
+}
+
+// GetCodebaseMetadataGitHubURL returns the URL of the GitHub API the metadata
+// of the codebases stored on GitHub is fetched from
+func (c *Registry) GetCodebaseMetadataGitHubURL() string {
+	return c.v.GetString(varMetadataGitHubURL)
+}
+
+// GetCodebaseWorkspacesReconcileSchedule returns the cron schedule on which
+// the workspaces running for longer than the maximum runtime of their space
+// are stopped and the workspaces of the deleted codebases are removed
+func (c *Registry) GetCodebaseWorkspacesReconcileSchedule() string {
+	return c.v.GetString(varWorkspaceReconcile)
+}
+
+// GetCheServiceAccountToken returns the token used to call che-starter
+// outside of any user request, e.g. to stop the expired workspaces. The
+// workspaces are never reconciled if it is empty.
+func (c *Registry) GetCheServiceAccountToken() string {
+	return c.v.GetString(varCheServiceAccountToken)
+}
+
+// GetRetentionSchedule returns the cron schedule on which the closed work
+// items are archived and the old revisions are compacted according to the
+// retention policies of the spaces. An empty schedule disables the job.
+func (c *Registry) GetRetentionSchedule() string {
+	return c.v.GetString(varRetentionSchedule)
+}
+
+// GetRetentionBatchSize returns the maximum number of rows archived or deleted
+// in a single transaction when enforcing the retention policies
+func (c *Registry) GetRetentionBatchSize() int {
+	return c.v.GetInt(varRetentionBatchSize)
+}
+
 // GetDeploymentsTimeout returns the amount of seconds until it should timeout.
 func (c *Registry) GetDeploymentsHTTPTimeoutSeconds() time.Duration {
 	timeout := c.v.GetInt(varDeploymentsHTTPTimeout)
@@ -882,6 +1057,90 @@ func (c *Registry) GetDeploymentsHTTPTimeoutSeconds() time.Duration {
 	return time.Duration(timeout) * time.Second
 }
 
+// GetTrashRetention returns how long deleted work items and comments are kept
+// in the trash before they are purged permanently. Zero disables the purge.
+func (c *Registry) GetTrashRetention() time.Duration {
+	return c.v.GetDuration(varTrashRetention)
+}
+
+// GetTrashPurgeSchedule returns the cron schedule of the job that purges the
+// trash
+func (c *Registry) GetTrashPurgeSchedule() string {
+	return c.v.GetString(varTrashPurgeSchedule)
+}
+
+// GetEventsRetention returns how long the events of the spaces are kept for
+// the clients resuming their event stream
+func (c *Registry) GetEventsRetention() time.Duration {
+	return c.v.GetDuration(varEventsRetention)
+}
+
+// GetEventsPollInterval returns how often the events committed by the other
+// instances of the service are fetched for the local subscribers
+func (c *Registry) GetEventsPollInterval() time.Duration {
+	return c.v.GetDuration(varEventsPollInterval)
+}
+
+// GetGraphQLMaxDepth returns how deeply the selections of a GraphQL query can
+// be nested
+func (c *Registry) GetGraphQLMaxDepth() int {
+	return c.v.GetInt(varGraphQLMaxDepth)
+}
+
+// GetGraphQLMaxCost returns how many entities a GraphQL query can load or list
+// before it is aborted
+func (c *Registry) GetGraphQLMaxCost() int {
+	return c.v.GetInt(varGraphQLMaxCost)
+}
+
+// GetDeploymentsHealthInterval returns how often the health of the watched
+// deployments is checked. Zero disables the watch.
+func (c *Registry) GetDeploymentsHealthInterval() time.Duration {
+	return c.v.GetDuration(varHealthWatchInterval)
+}
+
+// GetDeploymentsHealthExpiry returns how long the deployments of an
+// environment are watched after their health was last requested
+func (c *Registry) GetDeploymentsHealthExpiry() time.Duration {
+	return c.v.GetDuration(varHealthWatchExpiry)
+}
+
+// GetDeploymentsHealthServiceAccountToken returns the token used to send the
+// notifications of the unhealthy deployments to the notification service. The
+// health of the deployments is never watched if it is empty.
+func (c *Registry) GetDeploymentsHealthServiceAccountToken() string {
+	return c.v.GetString(varHealthWatchToken)
+}
+
+// GetDeploymentsMetricsBackend returns the backend the deployment metrics
+// are read from, i.e. MetricsBackendHawkular or MetricsBackendPrometheus
+func (c *Registry) GetDeploymentsMetricsBackend() string {
+	return c.v.GetString(varMetricsBackend)
+}
+
+// GetDeploymentsPrometheusURL returns the URL of the Prometheus HTTP API the
+// deployment metrics are read from, or an empty string to use the metrics URL
+// of the cluster of each environment
+func (c *Registry) GetDeploymentsPrometheusURL() string {
+	return c.v.GetString(varPrometheusURL)
+}
+
+// IsRateLimitEnabled returns true if the requests are rate limited
+func (c *Registry) IsRateLimitEnabled() bool {
+	return c.v.GetBool(varRateLimitEnabled)
+}
+
+// GetRateLimit returns the rate (in requests per second) at which the requests
+// of the given route group are allowed for each identity or client IP, and how
+// many requests can be sent at once. The limits of the "default" group apply to
+// the groups that are not configured.
+func (c *Registry) GetRateLimit(group string) (rate float64, burst int) {
+	if !c.v.IsSet(fmt.Sprintf(varRateLimitRate, group)) {
+		group = "default"
+	}
+	return c.v.GetFloat64(fmt.Sprintf(varRateLimitRate, group)), c.v.GetInt(fmt.Sprintf(varRateLimitBurst, group))
+}
+
 const (
 	defaultHeaderMaxLength = 5000 // bytes
 
@@ -922,6 +1181,11 @@ const (
 	defaultDeploymentsServiceURL = "http://core"
 	defaultCodebaseServiceURL    = "http://core"
 
+	// MetricsBackendHawkular and MetricsBackendPrometheus are the backends the
+	// deployment metrics can be read from
+	MetricsBackendHawkular   = "hawkular"
+	MetricsBackendPrometheus = "prometheus"
+
 	// DefaultValidRedirectURLs is a regex to be used to whitelist redirect URL for auth
 	// If the F8_REDIRECT_VALID env var is not set then in Dev Mode all redirects allowed - *
 	// In prod mode the following regex will be used by default:
@@ -215,3 +215,75 @@ func TestGetDeploymentsTimeoutDefault(t *testing.T) {
 	expectedTimeSeconds := time.Duration(30) * time.Second
 	assert.Equal(t, expectedTimeSeconds, viperValue)
 }
+
+func TestGetRateLimit(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.True(t, config.IsRateLimitEnabled())
+	rate, burst := config.GetRateLimit("search")
+	assert.Equal(t, 2.0, rate)
+	assert.Equal(t, 20, burst)
+	// the groups that are not configured get the default limits
+	rate, burst = config.GetRateLimit("unknown")
+	assert.Equal(t, 20.0, rate)
+	assert.Equal(t, 100, burst)
+
+	env := os.Getenv("F8_RATELIMIT_SEARCH_RATE")
+	defer func() {
+		os.Setenv("F8_RATELIMIT_SEARCH_RATE", env)
+		resetConfiguration(defaultValuesConfigFilePath)
+	}()
+	os.Setenv("F8_RATELIMIT_SEARCH_RATE", "0.5")
+	resetConfiguration(defaultValuesConfigFilePath)
+	rate, _ = config.GetRateLimit("search")
+	assert.Equal(t, 0.5, rate)
+}
+
+func TestGetEventsDefault(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, time.Hour, config.GetEventsRetention())
+	assert.Equal(t, time.Second, config.GetEventsPollInterval())
+}
+
+func TestGetDeploymentsHealthDefault(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, time.Duration(0), config.GetDeploymentsHealthInterval())
+	assert.Equal(t, time.Hour, config.GetDeploymentsHealthExpiry())
+}
+
+func TestGetDeploymentsMetricsDefault(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, configuration.MetricsBackendHawkular, config.GetDeploymentsMetricsBackend())
+	assert.Equal(t, "", config.GetDeploymentsPrometheusURL())
+}
+
+func TestGetCodebaseWebhookDefault(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, "", config.GetCodebaseWebhookMergedState())
+	assert.Equal(t, "max-age=2", config.GetCacheControlCodeReferences())
+}
+
+func TestGetCodebaseMetadataDefault(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, "@every 10m", config.GetCodebaseMetadataSchedule())
+	assert.Equal(t, time.Hour, config.GetCodebaseMetadataMaxAge())
+	assert.Equal(t, 30*time.Second, config.GetCodebaseMetadataTimeout())
+	assert.Equal(t, "https://api.github.com", config.GetCodebaseMetadataGitHubURL())
+}
+
+func TestGetCodebaseWorkspacesDefault(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, "@every 5m", config.GetCodebaseWorkspacesReconcileSchedule())
+	assert.Equal(t, "", config.GetCheServiceAccountToken())
+}
+
+func TestGetRetentionDefault(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, "0 0 3 * * *", config.GetRetentionSchedule())
+	assert.Equal(t, 500, config.GetRetentionBatchSize())
+}
+
+func TestGetGraphQLLimitsDefault(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, 10, config.GetGraphQLMaxDepth())
+	assert.Equal(t, 5000, config.GetGraphQLMaxCost())
+}
@@ -13,11 +13,11 @@ import (
 	"github.com/fabric8-services/fabric8-wit/area"
 	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/jsonapi"
-	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/path"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 
 	"github.com/goadesign/goa"
 	uuid "github.com/satori/go.uuid"
@@ -72,7 +72,7 @@ func (c *AreaController) ShowChildren(ctx *app.ShowChildrenAreaContext) error {
 
 // CreateChild runs the create-child action.
 func (c *AreaController) CreateChild(ctx *app.CreateChildAreaContext) error {
-	currentUser, err := login.ContextIdentity(ctx)
+	_, err := login.ContextIdentity(ctx)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
@@ -86,18 +86,9 @@ func (c *AreaController) CreateChild(ctx *app.CreateChildAreaContext) error {
 		if err != nil {
 			return err
 		}
-		s, err := appl.Spaces().Load(ctx, parent.SpaceID)
-		if err != nil {
+		if err := authorizeSpace(ctx, appl, parent.SpaceID, role.ManageAreas); err != nil {
 			return err
 		}
-		if !uuid.Equal(*currentUser, s.OwnerID) {
-			log.Warn(ctx, map[string]interface{}{
-				"space_id":     s.ID,
-				"space_owner":  s.OwnerID,
-				"current_user": *currentUser,
-			}, "user is not the space owner")
-			return errors.NewForbiddenError("user is not the space owner")
-		}
 
 		reqArea := ctx.Payload.Data
 		if reqArea.Attributes.Name == nil {
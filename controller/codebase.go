@@ -11,16 +11,17 @@ import (
 	"github.com/fabric8-services/fabric8-wit/application"
 	"github.com/fabric8-services/fabric8-wit/codebase"
 	"github.com/fabric8-services/fabric8-wit/codebase/che"
+	"github.com/fabric8-services/fabric8-wit/codebase/workspace"
+	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/jsonapi"
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/ptr"
 	"github.com/fabric8-services/fabric8-wit/rest"
-	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 
-	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/goadesign/goa"
-	"github.com/satori/go.uuid"
+	uuid "github.com/satori/go.uuid"
 )
 
 const (
@@ -56,16 +57,21 @@ func NewCodebaseController(service *goa.Service, db application.DB, config codeb
 // Show runs the show action.
 func (c *CodebaseController) Show(ctx *app.ShowCodebaseContext) error {
 	var cdb *codebase.Codebase
+	var metadata []codebase.Metadata
 	err := application.Transactional(c.db, func(appl application.Application) error {
 		var err error
 		cdb, err = appl.Codebases().Load(ctx, ctx.CodebaseID)
+		if err != nil {
+			return err
+		}
+		metadata, err = appl.CodebaseMetadata().List(ctx, cdb.ID)
 		return err
 	})
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 	return ctx.OK(&app.CodebaseSingle{
-		Data: ConvertCodebase(ctx.Request, *cdb),
+		Data: ConvertCodebase(ctx.Request, *cdb, codebaseIncludeMetadata(metadata)),
 	})
 
 }
@@ -152,32 +158,21 @@ func (c *CodebaseController) ListWorkspaces(ctx *app.ListWorkspacesCodebaseConte
 
 // Delete deletes the given codebase if the user is authenticated and authorized
 func (c *CodebaseController) Delete(ctx *app.DeleteCodebaseContext) error {
-	currentUser, err := login.ContextIdentity(ctx)
+	_, err := login.ContextIdentity(ctx)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
 	var cb *codebase.Codebase
-	var cbSpace *space.Space
 	err = application.Transactional(c.db, func(appl application.Application) error {
 		cb, err = appl.Codebases().Load(ctx.Context, ctx.CodebaseID)
 		if err != nil {
 			return err
 		}
-		cbSpace, err = appl.Spaces().Load(ctx.Context, cb.SpaceID)
-		return err
+		return authorizeSpace(ctx, appl, cb.SpaceID, role.ManageCodebases)
 	})
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	if !uuid.Equal(*currentUser, cbSpace.OwnerID) {
-		log.Warn(ctx, map[string]interface{}{
-			"codebase_id":  ctx.CodebaseID,
-			"space_id":     cbSpace.ID,
-			"space_owner":  cbSpace.OwnerID,
-			"current_user": *currentUser,
-		}, "user is not the space owner")
-		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not the space owner"))
-	}
 	// attempt to remotely delete the Che workspaces
 	ns, err := c.getCheNamespace(ctx)
 	if err != nil {
@@ -208,7 +203,10 @@ func (c *CodebaseController) Delete(ctx *app.DeleteCodebaseContext) error {
 							"che_namespace": ns,
 							"workspace":     workspace.Config.Name},
 						"failed to delete Che workspace: %s", err.Error())
+					// the workspace is removed later on by the workspace reconciler
+					continue
 				}
+				c.forgetWorkspace(ctx, cb.ID, ns, workspace.Config.Name)
 			}
 		}
 	}
@@ -251,32 +249,30 @@ func (c *CodebaseController) Create(ctx *app.CreateCodebaseContext) error {
 		stackID = *cb.StackID
 	}
 
-	workspace := che.WorkspaceRequest{
+	workspaceReq := che.WorkspaceRequest{
 		Branch:     getWorkspaceBranch(ctx),
 		StackID:    stackID,
 		Repository: cb.URL,
 	}
-	workspaceResp, err := cheClient.CreateWorkspace(ctx, workspace)
-	if err != nil {
-		log.Error(ctx, map[string]interface{}{
-			"codebase_id": cb.ID,
-			"stack_id":    stackID,
-			"err":         err,
-		}, "unable to create workspaces")
-		if werr, ok := err.(*che.StarterError); ok {
+	var workspaceResp *che.WorkspaceResponse
+	err = c.startWorkspace(ctx, cb, ns, "", func() (string, error) {
+		workspaceResp, err = cheClient.CreateWorkspace(ctx, workspaceReq)
+		if err != nil {
 			log.Error(ctx, map[string]interface{}{
 				"codebase_id": cb.ID,
 				"stack_id":    stackID,
 				"err":         err,
-			}, "unable to create workspaces: %s", werr.String())
+			}, "unable to create workspaces")
+			if werr, ok := err.(*che.StarterError); ok {
+				log.Error(ctx, map[string]interface{}{
+					"codebase_id": cb.ID,
+					"stack_id":    stackID,
+					"err":         err,
+				}, "unable to create workspaces: %s", werr.String())
+			}
+			return "", goa.ErrInternal(err.Error())
 		}
-		return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
-	}
-
-	err = application.Transactional(c.db, func(appl application.Application) error {
-		cb.LastUsedWorkspace = workspaceResp.Config.Name
-		_, err := appl.Codebases().Save(ctx, cb)
-		return err
+		return workspaceResp.Config.Name, nil
 	})
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
@@ -313,32 +309,126 @@ func (c *CodebaseController) Open(ctx *app.OpenCodebaseContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
 	}
-	workspaceResp, err := cheClient.StartExistingWorkspace(ctx, ctx.WorkspaceID)
-	if err != nil {
-		log.Error(ctx, map[string]interface{}{
-			"codebase_id": cb.ID,
-			"stack_id":    cb.StackID,
-			"err":         err,
-		}, "unable to open workspaces")
-		if werr, ok := err.(*che.StarterError); ok {
+	if err := checkWorkspace(ctx, cheClient, *cb, ctx.WorkspaceID); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var workspaceResp *che.WorkspaceResponse
+	err = c.startWorkspace(ctx, cb, ns, ctx.WorkspaceID, func() (string, error) {
+		workspaceResp, err = cheClient.StartExistingWorkspace(ctx, ctx.WorkspaceID)
+		if err != nil {
 			log.Error(ctx, map[string]interface{}{
 				"codebase_id": cb.ID,
 				"stack_id":    cb.StackID,
 				"err":         err,
-			}, "unable to open workspaces: %s", werr.String())
+			}, "unable to open workspaces")
+			if werr, ok := err.(*che.StarterError); ok {
+				log.Error(ctx, map[string]interface{}{
+					"codebase_id": cb.ID,
+					"stack_id":    cb.StackID,
+					"err":         err,
+				}, "unable to open workspaces: %s", werr.String())
+			}
+			return "", goa.ErrInternal(err.Error())
 		}
-		return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
+		return ctx.WorkspaceID, nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	ideURL := workspaceResp.GetHrefByRelOfWorkspaceLink(che.IdeUrlRel)
+	resp := &app.WorkspaceOpen{
+		Links: &app.WorkspaceOpenLinks{
+			Open: &ideURL,
+		},
 	}
+	return ctx.OK(resp)
+}
 
+// Stop runs the stop action.
+func (c *CodebaseController) Stop(ctx *app.StopCodebaseContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var cb *codebase.Codebase
 	err = application.Transactional(c.db, func(appl application.Application) error {
-		cb.LastUsedWorkspace = ctx.WorkspaceID
-		_, err := appl.Codebases().Save(ctx, cb)
-		return err
+		cb, err = appl.Codebases().Load(ctx, ctx.CodebaseID)
+		if err != nil {
+			return err
+		}
+		return authorizeSpace(ctx, appl, cb.SpaceID, role.ManageCodebases)
 	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	ns, err := c.getCheNamespace(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
+	}
+	cheClient, err := c.NewCheClient(ctx, ns)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
 	}
+	if err := checkWorkspace(ctx, cheClient, *cb, ctx.WorkspaceID); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	err = cheClient.StopWorkspace(ctx, ctx.WorkspaceID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, workspaceError(ctx, *cb, ctx.WorkspaceID, err))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		return appl.CodebaseWorkspaces().Save(ctx, &workspace.Workspace{CodebaseID: cb.ID, Namespace: ns, Name: ctx.WorkspaceID, Running: false})
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
 
+// Restart runs the restart action.
+func (c *CodebaseController) Restart(ctx *app.RestartCodebaseContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var cb *codebase.Codebase
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		cb, err = appl.Codebases().Load(ctx, ctx.CodebaseID)
+		if err != nil {
+			return err
+		}
+		return authorizeSpace(ctx, appl, cb.SpaceID, role.ManageCodebases)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	ns, err := c.getCheNamespace(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
+	}
+	cheClient, err := c.NewCheClient(ctx, ns)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
+	}
+	if err := checkWorkspace(ctx, cheClient, *cb, ctx.WorkspaceID); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var workspaceResp *che.WorkspaceResponse
+	err = c.startWorkspace(ctx, cb, ns, ctx.WorkspaceID, func() (string, error) {
+		err := cheClient.StopWorkspace(ctx, ctx.WorkspaceID)
+		if err != nil {
+			return "", workspaceError(ctx, *cb, ctx.WorkspaceID, err)
+		}
+		workspaceResp, err = cheClient.StartExistingWorkspace(ctx, ctx.WorkspaceID)
+		if err != nil {
+			return "", workspaceError(ctx, *cb, ctx.WorkspaceID, err)
+		}
+		return ctx.WorkspaceID, nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
 	ideURL := workspaceResp.GetHrefByRelOfWorkspaceLink(che.IdeUrlRel)
 	resp := &app.WorkspaceOpen{
 		Links: &app.WorkspaceOpenLinks{
@@ -348,10 +438,206 @@ func (c *CodebaseController) Open(ctx *app.OpenCodebaseContext) error {
 	return ctx.OK(resp)
 }
 
+// startWorkspace starts a workspace of the codebase with the given function,
+// which returns the name of the started workspace, and records it as the
+// running last used workspace of the codebase. The name is empty for a new
+// workspace. A running workspace is first reserved in the space in a short
+// transaction, so that the workspaces of the space never exceed the maximum
+// number of running workspaces, then che-starter is called outside of any
+// transaction. The reservation is released once the start is recorded or
+// failed.
+func (c *CodebaseController) startWorkspace(ctx context.Context, cb *codebase.Codebase, namespace, name string, start func() (string, error)) error {
+	var reservation *uuid.UUID
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		reservation, err = reserveWorkspace(ctx, appl, *cb, namespace, name)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	started, err := start()
+	if err != nil {
+		c.releaseWorkspace(ctx, reservation)
+		return err
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if reservation != nil {
+			if err := appl.CodebaseWorkspaces().Release(ctx, *reservation); err != nil {
+				return err
+			}
+		}
+		cb.LastUsedWorkspace = started
+		if _, err := appl.Codebases().Save(ctx, cb); err != nil {
+			return err
+		}
+		return appl.CodebaseWorkspaces().Save(ctx, &workspace.Workspace{CodebaseID: cb.ID, Namespace: namespace, Name: started, Running: true})
+	})
+}
+
+// reserveWorkspace returns a conflict error if running the given workspace
+// of the codebase would exceed the maximum number of running workspaces set by
+// the policy of its space, counting the workspaces being started. Otherwise it
+// reserves a running workspace in the space and returns the reservation, or
+// nil if the space is unlimited or the workspace is already running. The name
+// is empty for a new workspace. The policy is locked until the end of the
+// transaction, so that the workspaces of a space are reserved one at a time.
+func reserveWorkspace(ctx context.Context, appl application.Application, cb codebase.Codebase, namespace, name string) (*uuid.UUID, error) {
+	policy, err := appl.WorkspacePolicies().LoadForUpdate(ctx, cb.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.MaxRunningWorkspaces == 0 {
+		return nil, nil
+	}
+	running, err := appl.CodebaseWorkspaces().ListRunning(ctx, cb.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	others := 0
+	for _, w := range running {
+		if w.CodebaseID == cb.ID && w.Namespace == namespace && w.Name == name {
+			// the workspace is already running
+			return nil, nil
+		}
+		others++
+	}
+	reserved, err := appl.CodebaseWorkspaces().CountReservations(ctx, cb.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.Allows(others + reserved) {
+		return nil, errors.NewDataConflictError(fmt.Sprintf("the space already has %d running or starting workspaces, the maximum allowed by its workspace policy", others+reserved))
+	}
+	reservation, err := appl.CodebaseWorkspaces().Reserve(ctx, cb.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// releaseWorkspace releases the reservation of a workspace that failed to
+// start, if any. A failure is only logged since the reservation expires
+// anyway.
+func (c *CodebaseController) releaseWorkspace(ctx context.Context, reservation *uuid.UUID) {
+	if reservation == nil {
+		return
+	}
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		return appl.CodebaseWorkspaces().Release(ctx, *reservation)
+	})
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"reservation_id": *reservation,
+			"err":            err,
+		}, "unable to release the reservation of the workspace")
+	}
+}
+
+// workspaceError logs the error returned by che-starter for the given
+// workspace and converts it into a not found error if che-starter doesn't
+// know the workspace
+func workspaceError(ctx context.Context, cb codebase.Codebase, name string, err error) error {
+	log.Error(ctx, map[string]interface{}{
+		"codebase_id": cb.ID,
+		"workspace":   name,
+		"err":         err,
+	}, "unable to manage the workspace")
+	if werr, ok := err.(*che.StarterError); ok {
+		if werr.Status == http.StatusNotFound {
+			return errors.NewNotFoundError("workspace", name)
+		}
+		log.Error(ctx, map[string]interface{}{
+			"codebase_id": cb.ID,
+			"workspace":   name,
+			"err":         err,
+		}, "unable to manage the workspace: %s", werr.String())
+	}
+	return goa.ErrInternal(err.Error())
+}
+
+// checkWorkspace returns a not found error if che-starter lists no workspace
+// with the given name for the repository of the codebase, so that no record is
+// ever saved for a workspace of another codebase or for an unknown one.
+func checkWorkspace(ctx context.Context, cheClient che.Client, cb codebase.Codebase, name string) error {
+	workspaces, err := cheClient.ListWorkspaces(ctx, cb.URL)
+	if err != nil {
+		return workspaceError(ctx, cb, name, err)
+	}
+	for _, w := range workspaces {
+		if w.Config.Name == name {
+			return nil
+		}
+	}
+	return errors.NewNotFoundError("workspace", name)
+}
+
+// forgetWorkspace removes the record of a workspace deleted from che-starter.
+// A failure is only logged since the workspace reconciler removes the records
+// of the workspaces of the deleted codebases anyway.
+func (c *CodebaseController) forgetWorkspace(ctx context.Context, codebaseID uuid.UUID, namespace, name string) {
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		err := appl.CodebaseWorkspaces().Delete(ctx, codebaseID, namespace, name)
+		if _, ok := err.(errors.NotFoundError); ok {
+			// the workspace was never created or opened through a codebase
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"codebase_id": codebaseID,
+			"workspace":   name,
+			"err":         err,
+		}, "unable to forget the deleted workspace")
+	}
+}
+
 // CodebaseConvertFunc is a open ended function to add additional links/data/relations to a Codebase during
 // convertion from internal to API
 type CodebaseConvertFunc func(*http.Request, codebase.Codebase, *app.Codebase)
 
+// codebaseIncludeMetadata adds the metadata fetched from the git host of the
+// codebase, if any
+func codebaseIncludeMetadata(metadata []codebase.Metadata) CodebaseConvertFunc {
+	byCodebase := make(map[uuid.UUID]codebase.Metadata, len(metadata))
+	for _, m := range metadata {
+		byCodebase[m.CodebaseID] = m
+	}
+	return func(request *http.Request, cb codebase.Codebase, result *app.Codebase) {
+		m, ok := byCodebase[cb.ID]
+		if !ok {
+			return
+		}
+		res := &app.CodebaseMetadata{
+			DefaultBranch:    ptr.String(m.DefaultBranch),
+			Branches:         []string(m.Branches),
+			OpenPullRequests: ptr.Int(m.OpenPullRequests),
+			Language:         ptr.String(m.Language),
+			FetchedAt:        ptr.Time(m.FetchedAt.UTC()),
+		}
+		if m.LatestCommitID != "" {
+			res.LatestCommit = &app.CodebaseCommit{
+				ID:      ptr.String(m.LatestCommitID),
+				Message: ptr.String(m.LatestCommitMessage),
+				Author:  ptr.String(m.LatestCommitAuthor),
+				URL:     ptr.String(m.LatestCommitURL),
+				Date:    m.LatestCommitDate,
+			}
+		}
+		result.Attributes.Metadata = res
+	}
+}
+
+// codebaseIDs returns the IDs of the given codebases
+func codebaseIDs(codebases []codebase.Codebase) []uuid.UUID {
+	ids := make([]uuid.UUID, len(codebases))
+	for i, cb := range codebases {
+		ids[i] = cb.ID
+	}
+	return ids
+}
+
 // ConvertCodebases converts between internal and external REST representation
 func ConvertCodebases(request *http.Request, codebases []codebase.Codebase, options ...CodebaseConvertFunc) []*app.Codebase {
 	result := make([]*app.Codebase, len(codebases))
@@ -418,6 +704,27 @@ func ConvertCodebase(request *http.Request, codebase codebase.Codebase, options
 	return result
 }
 
+// Webhook returns the URL and the secret to configure the webhook of the
+// repository of the codebase with
+func (c *CodebaseController) Webhook(ctx *app.WebhookCodebaseContext) error {
+	var cb *codebase.Codebase
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		cb, err = appl.Codebases().Load(ctx, ctx.CodebaseID)
+		if err != nil {
+			return err
+		}
+		return authorizeSpace(ctx, appl, cb.SpaceID, role.ManageCodebases)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.CodebaseWebhook{
+		URL:    rest.AbsoluteURL(ctx.Request, "/api/codebases/webhooks"),
+		Secret: cb.WebhookSecret,
+	})
+}
+
 // CheState gets che server state.
 func (c *CodebaseController) CheState(ctx *app.CheStateCodebaseContext) error {
 	ns, err := c.getCheNamespace(ctx)
@@ -11,7 +11,9 @@ import (
 	"github.com/fabric8-services/fabric8-wit/account"
 	"github.com/fabric8-services/fabric8-wit/account/tenant"
 	"github.com/fabric8-services/fabric8-wit/app/test"
+	"github.com/fabric8-services/fabric8-wit/codebase"
 	"github.com/fabric8-services/fabric8-wit/codebase/che"
+	"github.com/fabric8-services/fabric8-wit/codebase/workspace"
 	"github.com/fabric8-services/fabric8-wit/configuration"
 	"github.com/fabric8-services/fabric8-wit/ptr"
 
@@ -20,10 +22,12 @@ import (
 	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
 	"github.com/fabric8-services/fabric8-wit/resource"
 	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	chesupport "github.com/fabric8-services/fabric8-wit/test/che"
 	"github.com/fabric8-services/fabric8-wit/test/http_monitor"
 	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
 	"github.com/goadesign/goa"
 	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -90,6 +94,13 @@ func NewMockCheClient(r http.RoundTripper, config *configuration.Registry) Codeb
 	}
 }
 
+// withFakeStarter makes the controller call the given fake che-starter
+func withFakeStarter(starter *chesupport.FakeStarter) ConfigureCodebaseController {
+	return withCheClient(func(ctx context.Context, ns string) (che.Client, error) {
+		return che.NewStarterClient(starter.URL, "https://openshift.example.com", ns, http.DefaultClient), nil
+	})
+}
+
 func MockShowTenant() func(context.Context) (*tenant.TenantSingle, error) {
 	return func(context.Context) (*tenant.TenantSingle, error) {
 		// return a predefined response for the Tenant
@@ -135,6 +146,35 @@ func (s *CodebaseControllerTestSuite) TestShowCodebase() {
 		require.NotNil(t, result)
 		compareWithGoldenAgnostic(t, filepath.Join(s.testDir, "show", "ok_with_stackId.golden.json"), result)
 	})
+
+	s.T().Run("success with metadata", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.Codebases(1))
+		commitDate := time.Date(2018, 2, 1, 10, 0, 0, 0, time.UTC)
+		err := codebase.NewMetadataRepository(s.DB).Save(context.Background(), &codebase.Metadata{
+			CodebaseID:       fxt.Codebases[0].ID,
+			DefaultBranch:    "master",
+			Branches:         codebase.Branches{"master", "feature"},
+			LatestCommitID:   "6f5a2b2c",
+			LatestCommitDate: &commitDate,
+			OpenPullRequests: 3,
+			Language:         "Go",
+		})
+		require.NoError(t, err)
+		svc, ctrl := s.UnsecuredController()
+		// when
+		_, result := test.ShowCodebaseOK(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID)
+		// then
+		metadata := result.Data.Attributes.Metadata
+		require.NotNil(t, metadata)
+		assert.Equal(t, "master", *metadata.DefaultBranch)
+		assert.Equal(t, []string{"master", "feature"}, metadata.Branches)
+		assert.Equal(t, "6f5a2b2c", *metadata.LatestCommit.ID)
+		assert.Equal(t, commitDate, metadata.LatestCommit.Date.UTC())
+		assert.Equal(t, 3, *metadata.OpenPullRequests)
+		assert.Equal(t, "Go", *metadata.Language)
+		assert.NotNil(t, metadata.FetchedAt)
+	})
 }
 
 func (s *CodebaseControllerTestSuite) TestDeleteCodebase() {
@@ -280,6 +320,34 @@ func (s *CodebaseControllerTestSuite) TestDeleteCodebase() {
 
 }
 
+func (s *CodebaseControllerTestSuite) TestWebhook() {
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.Spaces(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.Spaces[idx].OwnerID = testsupport.TestIdentity.ID
+			return nil
+		}),
+		tf.Codebases(1))
+
+	s.T().Run("ok", func(t *testing.T) {
+		svc, ctrl := s.SecuredControllers(testsupport.TestIdentity)
+		_, res := test.WebhookCodebaseOK(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID)
+		assert.Equal(t, fxt.Codebases[0].WebhookSecret, res.Secret)
+		assert.NotEmpty(t, res.Secret)
+		assert.Equal(t, "http:///api/codebases/webhooks", res.URL)
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		// the secret would allow to forge the events of the repository
+		svc, ctrl := s.SecuredControllers(testsupport.TestIdentity2)
+		test.WebhookCodebaseForbidden(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID)
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		svc, ctrl := s.UnsecuredController()
+		test.WebhookCodebaseUnauthorized(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID)
+	})
+}
+
 func (s *CodebaseControllerTestSuite) TestListWorkspaces() {
 
 	s.T().Run("OK", func(t *testing.T) {
@@ -315,3 +383,150 @@ func (s *CodebaseControllerTestSuite) TestListWorkspaces() {
 		require.Equal(t, "foo", codebaseBranch)
 	})
 }
+
+func (s *CodebaseControllerTestSuite) TestStopWorkspace() {
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.Spaces(1, func(fxt *tf.TestFixture, idx int) error {
+			// the workspaces are managed by the maintainers of the space
+			fxt.Spaces[idx].OwnerID = testsupport.TestIdentity.ID
+			return nil
+		}),
+		tf.Codebases(1))
+	starter := chesupport.NewFakeStarter()
+	defer starter.Close()
+	starter.AddWorkspace("wksp-1", fxt.Codebases[0].URL, chesupport.StatusRunning)
+	repo := workspace.NewRepository(s.DB)
+	require.NoError(s.T(), repo.Save(context.Background(), &workspace.Workspace{CodebaseID: fxt.Codebases[0].ID, Namespace: "foo", Name: "wksp-1", Running: true}))
+	svc, ctrl := s.SecuredControllers(testsupport.TestIdentity, withFakeStarter(starter), withShowTenant(MockShowTenant()))
+
+	s.T().Run("ok", func(t *testing.T) {
+		test.StopCodebaseNoContent(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-1")
+		assert.Equal(t, chesupport.StatusStopped, starter.Status("wksp-1"))
+		running, err := repo.ListRunning(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, running)
+	})
+
+	s.T().Run("unknown workspace", func(t *testing.T) {
+		test.StopCodebaseNotFound(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "unknown")
+	})
+
+	s.T().Run("workspace of another repository", func(t *testing.T) {
+		starter.AddWorkspace("wksp-other", "https://github.com/fabric8-services/other", chesupport.StatusRunning)
+		test.StopCodebaseNotFound(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-other")
+		assert.Equal(t, chesupport.StatusRunning, starter.Status("wksp-other"))
+		// no record is saved for the workspace
+		var count int
+		require.NoError(t, s.DB.Table(workspace.TableName).Where("name = ?", "wksp-other").Count(&count).Error)
+		assert.Equal(t, 0, count)
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		starter.AddWorkspace("wksp-2", fxt.Codebases[0].URL, chesupport.StatusRunning)
+		svc, ctrl := s.SecuredControllers(testsupport.TestIdentity2, withFakeStarter(starter), withShowTenant(MockShowTenant()))
+		test.StopCodebaseForbidden(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-2")
+		assert.Equal(t, chesupport.StatusRunning, starter.Status("wksp-2"))
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		svc, ctrl := s.UnsecuredController(withFakeStarter(starter), withShowTenant(MockShowTenant()))
+		test.StopCodebaseUnauthorized(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-1")
+	})
+}
+
+func (s *CodebaseControllerTestSuite) TestRestartWorkspace() {
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.Spaces(1, func(fxt *tf.TestFixture, idx int) error {
+			// the workspaces are managed by the maintainers of the space
+			fxt.Spaces[idx].OwnerID = testsupport.TestIdentity.ID
+			return nil
+		}),
+		tf.Codebases(1))
+	starter := chesupport.NewFakeStarter()
+	defer starter.Close()
+	starter.AddWorkspace("wksp-1", fxt.Codebases[0].URL, chesupport.StatusRunning)
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		svc, ctrl := s.SecuredControllers(testsupport.TestIdentity2, withFakeStarter(starter), withShowTenant(MockShowTenant()))
+		test.RestartCodebaseForbidden(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-1")
+		assert.Empty(t, starter.Requests)
+	})
+
+	s.T().Run("ok", func(t *testing.T) {
+		svc, ctrl := s.SecuredControllers(testsupport.TestIdentity, withFakeStarter(starter), withShowTenant(MockShowTenant()))
+		_, res := test.RestartCodebaseOK(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-1")
+		require.NotNil(t, res.Links)
+		assert.Equal(t, "https://che.example.com/user/wksp-1", *res.Links.Open)
+		assert.Equal(t, []string{"GET /workspace", "DELETE /workspace/wksp-1/runtime", "PATCH /workspace/wksp-1"}, starter.Requests)
+		assert.Equal(t, chesupport.StatusRunning, starter.Status("wksp-1"))
+		running, err := workspace.NewRepository(s.DB).ListRunning(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		require.Len(t, running, 1)
+		assert.Equal(t, "wksp-1", running[0].Name)
+		assert.Equal(t, "foo", running[0].Namespace)
+	})
+
+	s.T().Run("workspace of another repository", func(t *testing.T) {
+		starter.AddWorkspace("wksp-other", "https://github.com/fabric8-services/other", chesupport.StatusStopped)
+		svc, ctrl := s.SecuredControllers(testsupport.TestIdentity, withFakeStarter(starter), withShowTenant(MockShowTenant()))
+		test.RestartCodebaseNotFound(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-other")
+		assert.Equal(t, chesupport.StatusStopped, starter.Status("wksp-other"))
+	})
+}
+
+func (s *CodebaseControllerTestSuite) TestWorkspacePolicy() {
+	// the space allows a single running workspace, which is already running
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.Spaces(1, func(fxt *tf.TestFixture, idx int) error {
+			// the workspaces are managed by the maintainers of the space
+			fxt.Spaces[idx].OwnerID = testsupport.TestIdentity.ID
+			return nil
+		}),
+		tf.Codebases(2))
+	starter := chesupport.NewFakeStarter()
+	defer starter.Close()
+	starter.AddWorkspace("wksp-1", fxt.Codebases[0].URL, chesupport.StatusRunning)
+	starter.AddWorkspace("wksp-2", fxt.Codebases[1].URL, chesupport.StatusStopped)
+	require.NoError(s.T(), workspace.NewPolicyRepository(s.DB).Save(context.Background(), &workspace.Policy{SpaceID: fxt.Spaces[0].ID, MaxRunningWorkspaces: 1}))
+	require.NoError(s.T(), workspace.NewRepository(s.DB).Save(context.Background(), &workspace.Workspace{CodebaseID: fxt.Codebases[0].ID, Namespace: "foo", Name: "wksp-1", Running: true}))
+	svc, ctrl := s.SecuredControllers(testsupport.TestIdentity, withFakeStarter(starter), withShowTenant(MockShowTenant()))
+
+	s.T().Run("open another workspace", func(t *testing.T) {
+		test.OpenCodebaseConflict(t, svc.Context, svc, ctrl, fxt.Codebases[1].ID, "wksp-2")
+		assert.Equal(t, chesupport.StatusStopped, starter.Status("wksp-2"))
+	})
+
+	s.T().Run("create a workspace", func(t *testing.T) {
+		test.CreateCodebaseConflict(t, svc.Context, svc, ctrl, fxt.Codebases[1].ID, nil)
+	})
+
+	s.T().Run("open the running workspace", func(t *testing.T) {
+		test.OpenCodebaseOK(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-1")
+	})
+
+	s.T().Run("open another workspace once stopped", func(t *testing.T) {
+		test.StopCodebaseNoContent(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-1")
+		test.OpenCodebaseOK(t, svc.Context, svc, ctrl, fxt.Codebases[1].ID, "wksp-2")
+		assert.Equal(t, chesupport.StatusRunning, starter.Status("wksp-2"))
+	})
+
+	s.T().Run("restart the stopped workspace", func(t *testing.T) {
+		test.RestartCodebaseConflict(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-1")
+	})
+
+	s.T().Run("the started workspace is no longer reserved", func(t *testing.T) {
+		count, err := workspace.NewRepository(s.DB).CountReservations(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	s.T().Run("restart while another workspace is starting", func(t *testing.T) {
+		test.StopCodebaseNoContent(t, svc.Context, svc, ctrl, fxt.Codebases[1].ID, "wksp-2")
+		repo := workspace.NewRepository(s.DB)
+		reservation, err := repo.Reserve(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		test.RestartCodebaseConflict(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-1")
+		require.NoError(t, repo.Release(context.Background(), reservation))
+		test.RestartCodebaseOK(t, svc.Context, svc, ctrl, fxt.Codebases[0].ID, "wksp-1")
+	})
+}
@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/codebase/webhook"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// CodebaseWebhooksController implements the codebase_webhooks resource.
+type CodebaseWebhooksController struct {
+	*goa.Controller
+	db     application.DB
+	config CodebaseWebhooksControllerConfig
+}
+
+// CodebaseWebhooksControllerConfig the config interface for the CodebaseWebhooksController
+type CodebaseWebhooksControllerConfig interface {
+	GetCodebaseWebhookMergedState() string
+}
+
+// NewCodebaseWebhooksController creates a codebase_webhooks controller.
+func NewCodebaseWebhooksController(service *goa.Service, db application.DB, config CodebaseWebhooksControllerConfig) *CodebaseWebhooksController {
+	return &CodebaseWebhooksController{
+		Controller: service.NewController("CodebaseWebhooksController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// Ingest runs the ingest action.
+func (c *CodebaseWebhooksController) Ingest(ctx *app.IngestCodebaseWebhooksContext) error {
+	// the payload is read as is since its signature is computed on the raw bytes
+	body, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("payload", err.Error()))
+	}
+	event, err := webhook.Parse(ctx.Request.Header, body)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if event == nil {
+		// not an event we are interested in
+		return ctx.NoContent()
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		codebases, err := codebasesByURL(ctx, appl, event.RepositoryURLs)
+		if err != nil {
+			return err
+		}
+		codebases = verifiedCodebases(ctx.Request.Header, body, codebases)
+		if len(codebases) == 0 {
+			log.Info(ctx, map[string]interface{}{
+				"urls": event.RepositoryURLs,
+			}, "no codebase of the repository of the webhook event has the secret of the event")
+			return errors.NewUnauthorizedError("invalid webhook signature")
+		}
+		for _, commit := range event.Commits {
+			title := strings.SplitN(commit.Message, "\n", 2)[0]
+			err := c.storeReferences(ctx, appl, codebases, commit.Message, codebase.Reference{
+				Kind:   codebase.ReferenceKindCommit,
+				Ref:    commit.ID,
+				Title:  title,
+				URL:    commit.URL,
+				Author: commit.Author,
+			}, false)
+			if err != nil {
+				return err
+			}
+		}
+		if pr := event.PullRequest; pr != nil {
+			return c.storeReferences(ctx, appl, codebases, pr.Title+"\n"+pr.Description, codebase.Reference{
+				Kind:   codebase.ReferenceKindPullRequest,
+				Ref:    strconv.Itoa(pr.Number),
+				Title:  pr.Title,
+				URL:    pr.URL,
+				Author: pr.Author,
+				State:  pr.State,
+			}, pr.State == webhook.StateMerged)
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// storeReferences links the given commit or pull request to the work items
+// referenced in the text, and moves them to the configured state if merged is
+// true. References to work items outside of the spaces of the codebases are
+// ignored, so that a repository can only affect the spaces it belongs to.
+func (c *CodebaseWebhooksController) storeReferences(ctx context.Context, appl application.Application, codebases []codebase.Codebase, text string, r codebase.Reference, merged bool) error {
+	for _, ref := range webhook.ParseReferences(text) {
+		wiID, cb, err := lookupReferencedWorkItem(ctx, appl, codebases, ref)
+		if err != nil {
+			return err
+		}
+		if wiID == nil {
+			log.Info(ctx, map[string]interface{}{
+				"reference": ref.String(),
+				"ref":       r.Ref,
+			}, "ignoring the reference to an unknown work item")
+			continue
+		}
+		cr := r
+		cr.WorkItemID = *wiID
+		cr.CodebaseID = cb.ID
+		if err := appl.CodeReferences().Upsert(ctx, &cr); err != nil {
+			return errs.Wrapf(err, "failed to store the reference of %s %s", r.Kind, r.Ref)
+		}
+		if merged {
+			if err := c.moveToMergedState(ctx, appl, *wiID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// moveToMergedState sets the state of the work item to the configured merged
+// state, unless no state is configured or the work item type doesn't have it
+func (c *CodebaseWebhooksController) moveToMergedState(ctx context.Context, appl application.Application, wiID uuid.UUID) error {
+	state := c.config.GetCodebaseWebhookMergedState()
+	if state == "" {
+		return nil
+	}
+	wi, err := appl.WorkItems().LoadByID(ctx, wiID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to load work item %s", wiID)
+	}
+	if wi.Fields[workitem.SystemState] == state {
+		return nil
+	}
+	wit, err := appl.WorkItemTypes().Load(ctx, wi.Type)
+	if err != nil {
+		return errs.Wrapf(err, "failed to load the type of work item %s", wiID)
+	}
+	def, ok := wit.Fields[workitem.SystemState]
+	if !ok {
+		return nil
+	}
+	if _, err := def.ConvertToModel(workitem.SystemState, state); err != nil {
+		log.Info(ctx, map[string]interface{}{
+			"wi_id": wiID,
+			"state": state,
+			"err":   err,
+		}, "not changing the state of the work item since its type doesn't allow the merged state")
+		return nil
+	}
+	s, err := appl.Spaces().Load(ctx, wi.SpaceID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to load the space of work item %s", wiID)
+	}
+	wi.Fields[workitem.SystemState] = state
+	// the event isn't sent on behalf of a user, so the change is attributed to
+	// the owner of the space
+	if _, err := appl.WorkItems().Save(ctx, wi.SpaceID, *wi, s.OwnerID); err != nil {
+		return errs.Wrapf(err, "failed to change the state of work item %s", wiID)
+	}
+	return nil
+}
+
+// codebasesByURL returns the codebases registered with any of the given
+// repository URLs
+func codebasesByURL(ctx context.Context, appl application.Application, urls []string) ([]codebase.Codebase, error) {
+	var res []codebase.Codebase
+	seen := map[uuid.UUID]struct{}{}
+	for _, url := range urls {
+		codebases, _, err := appl.Codebases().SearchByURL(ctx, url, nil, nil)
+		if err != nil {
+			return nil, errs.Wrapf(err, "failed to search the codebases of %s", url)
+		}
+		for _, cb := range codebases {
+			if _, ok := seen[cb.ID]; ok {
+				continue
+			}
+			seen[cb.ID] = struct{}{}
+			res = append(res, cb)
+		}
+	}
+	return res, nil
+}
+
+// verifiedCodebases returns the codebases whose webhook secret the payload was
+// signed with. Since each codebase has its own secret, an event can only
+// affect the spaces whose codebases configured the webhook.
+func verifiedCodebases(header http.Header, body []byte, codebases []codebase.Codebase) []codebase.Codebase {
+	var res []codebase.Codebase
+	for _, cb := range codebases {
+		if webhook.Verify(header, body, cb.WebhookSecret) == nil {
+			res = append(res, cb)
+		}
+	}
+	return res
+}
+
+// lookupReferencedWorkItem returns the ID of the referenced work item along
+// with the codebase of its space, or nil if there is no such work item in the
+// spaces of the codebases. A reference without owner is looked up in the
+// spaces of the owners of the codebases.
+func lookupReferencedWorkItem(ctx context.Context, appl application.Application, codebases []codebase.Codebase, ref webhook.Reference) (*uuid.UUID, *codebase.Codebase, error) {
+	for i, cb := range codebases {
+		owner := ref.Owner
+		if owner == "" {
+			s, err := appl.Spaces().Load(ctx, cb.SpaceID)
+			if err != nil {
+				return nil, nil, errs.Wrapf(err, "failed to load the space of codebase %s", cb.ID)
+			}
+			identity, err := appl.Identities().Load(ctx, s.OwnerID)
+			if err != nil {
+				return nil, nil, errs.Wrapf(err, "failed to load the owner of space %s", s.ID)
+			}
+			owner = identity.Username
+		}
+		wiID, spaceID, err := appl.WorkItems().LookupIDByNamedSpaceAndNumber(ctx, owner, ref.Space, ref.Number)
+		if err != nil {
+			if ok, _ := errors.IsNotFoundError(err); ok {
+				continue
+			}
+			return nil, nil, errs.Wrapf(err, "failed to look up work item %s", ref)
+		}
+		if *spaceID == cb.SpaceID {
+			return wiID, &codebases[i], nil
+		}
+	}
+	return nil, nil, nil
+}
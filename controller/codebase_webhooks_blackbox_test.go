@@ -0,0 +1,165 @@
+package controller_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestCodebaseWebhooksREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunCodebaseWebhooksREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestCodebaseWebhooksREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestCodebaseWebhooksREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+type webhooksTestConfig struct {
+	mergedState string
+}
+
+func (c webhooksTestConfig) GetCodebaseWebhookMergedState() string {
+	return c.mergedState
+}
+
+// ingest sends the given GitLab event to the webhook and returns the status
+// of the response. The generated test helpers can't be used since they don't
+// send a body for actions without payload.
+func (s *TestCodebaseWebhooksREST) ingest(t *testing.T, config webhooksTestConfig, token, event, body string) int {
+	svc := goa.New("Webhooks-Service")
+	ctrl := NewCodebaseWebhooksController(svc, s.db, config)
+	req := httptest.NewRequest(http.MethodPost, "/api/codebases/webhooks", bytes.NewBufferString(body))
+	req.Header.Set("X-Gitlab-Token", token)
+	req.Header.Set("X-Gitlab-Event", event)
+	rw := httptest.NewRecorder()
+	goaCtx := goa.NewContext(goa.WithAction(svc.Context, "CodebaseWebhooksTest"), rw, req, url.Values{})
+	ctx, err := app.NewIngestCodebaseWebhooksContext(goaCtx, req, svc)
+	require.NoError(t, err)
+	require.NoError(t, ctrl.Ingest(ctx))
+	return rw.Code
+}
+
+func gitlabPushPayload(repoURL, message string) string {
+	return fmt.Sprintf(`{
+		"project": {"git_http_url": %q},
+		"commits": [{"id": "6f5a2b2c", "message": %q, "url": "%s/commit/6f5a2b2c", "author": {"name": "jdoe"}}]
+	}`, repoURL, message, repoURL)
+}
+
+func gitlabMergeRequestPayload(repoURL, title, state string) string {
+	return fmt.Sprintf(`{
+		"user": {"username": "jdoe"},
+		"project": {"git_http_url": %q},
+		"object_attributes": {"iid": 7, "title": %q, "description": "", "url": "%s/merge_requests/7", "state": %q}
+	}`, repoURL, title, repoURL, state)
+}
+
+func (s *TestCodebaseWebhooksREST) TestIngest() {
+	repoURL := "https://gitlab.com/jdoe/" + uuid.NewV4().String() + ".git"
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(2), tf.Codebases(1, func(fxt *tf.TestFixture, idx int) error {
+		fxt.Codebases[idx].URL = repoURL
+		return nil
+	}))
+	config := webhooksTestConfig{mergedState: workitem.SystemStateResolved}
+	secret := fxt.Codebases[0].WebhookSecret
+	svc := testsupport.ServiceAsUser("CodeReferences-Service", *fxt.Identities[0])
+	listCtrl := NewWorkItemCodeReferencesController(svc, s.db, s.Configuration)
+	ref := func(wi *workitem.WorkItem) string {
+		return fmt.Sprintf("%s#%d", fxt.Spaces[0].Name, wi.Number)
+	}
+
+	s.T().Run("invalid token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, s.ingest(t, config, "other", "Push Hook", gitlabPushPayload(repoURL, ref(fxt.WorkItems[0]))))
+	})
+
+	s.T().Run("invalid payload", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, s.ingest(t, config, secret, "Push Hook", "{"))
+	})
+
+	s.T().Run("unknown repository", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, s.ingest(t, config, secret, "Push Hook", gitlabPushPayload("https://gitlab.com/jdoe/unknown.git", ref(fxt.WorkItems[0]))))
+		_, list := test.ListWorkItemCodeReferencesOK(t, svc.Context, svc, listCtrl, fxt.WorkItems[0].ID, nil, nil)
+		assert.Empty(t, list.Data)
+	})
+
+	s.T().Run("secret of the codebase of another space", func(t *testing.T) {
+		// anyone can register the repository as a codebase of their own space
+		other := tf.NewTestFixture(t, s.DB, tf.Codebases(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.Codebases[idx].URL = repoURL
+			return nil
+		}))
+		require.NotEqual(t, secret, other.Codebases[0].WebhookSecret)
+		message := fmt.Sprintf("Fix %s/%s", fxt.Identities[0].Username, ref(fxt.WorkItems[0]))
+		require.Equal(t, http.StatusNoContent, s.ingest(t, config, other.Codebases[0].WebhookSecret, "Push Hook", gitlabPushPayload(repoURL, message)))
+		_, list := test.ListWorkItemCodeReferencesOK(t, svc.Context, svc, listCtrl, fxt.WorkItems[0].ID, nil, nil)
+		assert.Empty(t, list.Data)
+	})
+
+	s.T().Run("commit", func(t *testing.T) {
+		message := "Fix the login page\n\nsee " + ref(fxt.WorkItems[0])
+		require.Equal(t, http.StatusNoContent, s.ingest(t, config, secret, "Push Hook", gitlabPushPayload(repoURL, message)))
+		// the same commit pushed to another branch is only linked once
+		require.Equal(t, http.StatusNoContent, s.ingest(t, config, secret, "Push Hook", gitlabPushPayload(repoURL, message)))
+		_, list := test.ListWorkItemCodeReferencesOK(t, svc.Context, svc, listCtrl, fxt.WorkItems[0].ID, nil, nil)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, "commit", list.Data[0].Attributes.Kind)
+		assert.Equal(t, "6f5a2b2c", list.Data[0].Attributes.Ref)
+		assert.Equal(t, "Fix the login page", *list.Data[0].Attributes.Title)
+		assert.Equal(t, fxt.Codebases[0].ID.String(), *list.Data[0].Relationships.Codebase.Data.ID)
+	})
+
+	s.T().Run("merged merge request", func(t *testing.T) {
+		title := "Fix " + fxt.Identities[0].Username + "/" + ref(fxt.WorkItems[1])
+		require.Equal(t, http.StatusNoContent, s.ingest(t, config, secret, "Merge Request Hook", gitlabMergeRequestPayload(repoURL, title, "opened")))
+		wi, err := s.db.WorkItems().LoadByID(svc.Context, fxt.WorkItems[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, workitem.SystemStateNew, wi.Fields[workitem.SystemState])
+
+		require.Equal(t, http.StatusNoContent, s.ingest(t, config, secret, "Merge Request Hook", gitlabMergeRequestPayload(repoURL, title, "merged")))
+		_, list := test.ListWorkItemCodeReferencesOK(t, svc.Context, svc, listCtrl, fxt.WorkItems[1].ID, nil, nil)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, "pullrequest", list.Data[0].Attributes.Kind)
+		assert.Equal(t, "7", list.Data[0].Attributes.Ref)
+		assert.Equal(t, "merged", *list.Data[0].Attributes.State)
+		wi, err = s.db.WorkItems().LoadByID(svc.Context, fxt.WorkItems[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, workitem.SystemStateResolved, wi.Fields[workitem.SystemState])
+	})
+
+	s.T().Run("work item of another space", func(t *testing.T) {
+		other := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		message := fmt.Sprintf("Fix %s/%s#%d", other.Identities[0].Username, other.Spaces[0].Name, other.WorkItems[0].Number)
+		require.Equal(t, http.StatusNoContent, s.ingest(t, config, secret, "Push Hook", gitlabPushPayload(repoURL, message)))
+		_, list := test.ListWorkItemCodeReferencesOK(t, svc.Context, svc, listCtrl, other.WorkItems[0].ID, nil, nil)
+		assert.Empty(t, list.Data)
+	})
+
+	s.T().Run("unknown work item", func(t *testing.T) {
+		test.ListWorkItemCodeReferencesNotFound(t, svc.Context, svc, listCtrl, uuid.NewV4(), nil, nil)
+	})
+}
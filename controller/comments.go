@@ -9,14 +9,13 @@ import (
 	"github.com/fabric8-services/fabric8-wit/app"
 	"github.com/fabric8-services/fabric8-wit/application"
 	"github.com/fabric8-services/fabric8-wit/comment"
-	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/jsonapi"
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/notification"
 	"github.com/fabric8-services/fabric8-wit/ptr"
 	"github.com/fabric8-services/fabric8-wit/rendering"
 	"github.com/fabric8-services/fabric8-wit/rest"
-	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/goadesign/goa"
 	uuid "github.com/satori/go.uuid"
@@ -93,14 +92,13 @@ func (c *CommentsController) Update(ctx *app.UpdateCommentsContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	// User is allowed to update if user is creator of the comment OR user is a space collaborator
+	// User is allowed to update if user is creator of the comment OR user can edit the work items of the space
 	if !userIsCreator {
-		authorized, err := authz.Authorize(ctx, wi.SpaceID.String())
+		err = application.Transactional(c.db, func(appl application.Application) error {
+			return authorizeSpace(ctx, appl, wi.SpaceID, role.EditWorkItems)
+		})
 		if err != nil {
-			return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
-		}
-		if !authorized {
-			return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not a space collaborator"))
+			return jsonapi.JSONErrorResponse(ctx, err)
 		}
 	}
 	err = c.performUpdate(ctx, cm, identityID)
@@ -111,7 +109,7 @@ func (c *CommentsController) Update(ctx *app.UpdateCommentsContext) error {
 	res := &app.CommentSingle{
 		Data: ConvertComment(ctx.Request, *cm, CommentIncludeParentWorkItem(ctx, cm)),
 	}
-	c.notification.Send(ctx, notification.NewCommentUpdated(cm.ID.String()))
+	c.notification.Send(ctx, notification.NewCommentUpdated(wi.SpaceID, cm.ID.String()))
 	return ctx.OK(res)
 }
 
@@ -122,15 +120,9 @@ func (c *CommentsController) loadComment(ctx context.Context, commentID, identit
 		if err != nil {
 			return err
 		}
-		if identityID == cm.Creator {
-			userIsCreator = true
-			return err
-		}
+		userIsCreator = identityID == cm.Creator
 		wi, err = appl.WorkItems().LoadByID(ctx, cm.ParentID)
-		if err != nil {
-			return err
-		}
-		return nil
+		return err
 	})
 	return // using names returned value
 }
@@ -154,14 +146,13 @@ func (c *CommentsController) Delete(ctx *app.DeleteCommentsContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	// User is allowed to delete if user is creator of the comment OR user is a space collaborator
+	// User is allowed to delete if user is creator of the comment OR user can edit the work items of the space
 	if !userIsCreator {
-		authorized, err := authz.Authorize(ctx, wi.SpaceID.String())
+		err = application.Transactional(c.db, func(appl application.Application) error {
+			return authorizeSpace(ctx, appl, wi.SpaceID, role.EditWorkItems)
+		})
 		if err != nil {
-			return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
-		}
-		if !authorized {
-			return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not a space collaborator"))
+			return jsonapi.JSONErrorResponse(ctx, err)
 		}
 	}
 	err = application.Transactional(c.db, func(appl application.Application) error {
@@ -170,6 +161,7 @@ func (c *CommentsController) Delete(ctx *app.DeleteCommentsContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
+	c.notification.Send(ctx, notification.NewCommentDeleted(wi.SpaceID, cm.ID.String()))
 	return ctx.OK([]byte{})
 }
 
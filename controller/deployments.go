@@ -3,6 +3,8 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"time"
@@ -14,16 +16,24 @@ import (
 	"github.com/fabric8-services/fabric8-wit/kubernetes"
 	"github.com/fabric8-services/fabric8-wit/log"
 
+	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 )
 
+// defaultDeploymentLogTailLines is the number of lines returned from the end of
+// the logs of each pod when neither the number of lines nor a start time is given
+const defaultDeploymentLogTailLines = 100
+
 // DeploymentsController implements the deployments resource.
 type DeploymentsController struct {
 	*goa.Controller
 	Config *configuration.Registry
 	ClientGetter
+	// HealthWatcher, when set, watches the environments whose health is requested
+	HealthWatcher *kubernetes.HealthWatcher
 }
 
 // ClientGetter creates an instances of clients used by this controller
@@ -156,15 +166,20 @@ func (g *defaultClientGetter) GetKubeClient(ctx context.Context) (kubernetes.Kub
 		return nil, errs.Wrap(err, "could not retrieve tenant data")
 	}
 
-	/* Timeout used per HTTP request to Kubernetes/OpenShift API servers.
-	 * Communication with Hawkular currently uses a hard-coded 30 second
-	 * timeout per request, and does not use this parameter. */
+	/* Timeout used per HTTP request to Kubernetes/OpenShift API servers
+	 * and to Prometheus. Communication with Hawkular currently uses a
+	 * hard-coded 30 second timeout per request, and does not use this parameter. */
 	// create the cluster API client
 	kubeConfig := &kubernetes.KubeClientConfig{
 		BaseURLProvider: baseURLProvider,
 		UserNamespace:   *kubeNamespaceName,
 		Timeout:         g.config.GetDeploymentsHTTPTimeoutSeconds(),
 	}
+	if g.config.GetDeploymentsMetricsBackend() == configuration.MetricsBackendPrometheus {
+		kubeConfig.MetricsGetter = &kubernetes.PrometheusMetricsGetter{
+			URL: g.config.GetDeploymentsPrometheusURL(),
+		}
+	}
 	kc, err := kubernetes.NewKubeClient(kubeConfig)
 	if err != nil {
 		url, _ := baseURLProvider.GetAPIURL()
@@ -231,6 +246,163 @@ func (c *DeploymentsController) DeleteDeployment(ctx *app.DeleteDeploymentDeploy
 	return ctx.OK([]byte{})
 }
 
+// ShowDeploymentHistory runs the showDeploymentHistory action.
+func (c *DeploymentsController) ShowDeploymentHistory(ctx *app.ShowDeploymentHistoryDeploymentsContext) error {
+	kc, err := c.GetKubeClient(ctx)
+	defer cleanup(kc)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	kubeSpaceName, err := c.getSpaceNameFromSpaceID(ctx, ctx.SpaceID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("osio space", ctx.SpaceID.String()))
+	}
+
+	revisions, err := kc.GetDeploymentHistory(*kubeSpaceName, ctx.AppName, ctx.DeployName)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "error retrieving deployment history for %s", ctx.DeployName))
+	}
+	if revisions == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("deployment", ctx.DeployName))
+	}
+
+	res := &app.SimpleDeploymentRevisionList{
+		Data: revisions,
+	}
+
+	return ctx.OK(res)
+}
+
+// ShowDeploymentLogs runs the showDeploymentLogs action.
+func (c *DeploymentsController) ShowDeploymentLogs(ctx *app.ShowDeploymentLogsDeploymentsContext) error {
+	flusher, ok := ctx.ResponseData.ResponseWriter.(http.Flusher)
+	if !ok {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewInternalErrorFromString("streaming is not supported"))
+	}
+
+	kc, err := c.GetKubeClient(ctx)
+	defer cleanup(kc)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	kubeSpaceName, err := c.getSpaceNameFromSpaceID(ctx, ctx.SpaceID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("osio space", ctx.SpaceID.String()))
+	}
+
+	opts := &kubernetes.DeploymentLogOptions{
+		SinceTime: ctx.Since,
+	}
+	if ctx.Container != nil {
+		opts.Container = *ctx.Container
+	}
+	if ctx.Tail != nil {
+		tail := int64(*ctx.Tail)
+		opts.TailLines = &tail
+	} else if ctx.Since == nil {
+		tail := int64(defaultDeploymentLogTailLines)
+		opts.TailLines = &tail
+	}
+	if ctx.Follow != nil {
+		opts.Follow = *ctx.Follow
+	}
+	logs, err := kc.GetDeploymentLogs(*kubeSpaceName, ctx.AppName, ctx.DeployName, opts)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "error retrieving logs of deployment %s", ctx.DeployName))
+	}
+	defer logs.Close()
+	// stop reading the logs as soon as the client goes away
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			logs.Close()
+		case <-done:
+		}
+	}()
+
+	ctx.ResponseData.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	ctx.ResponseData.Header().Set("Cache-Control", "no-cache")
+	ctx.ResponseData.Header().Set("X-Accel-Buffering", "no")
+	ctx.ResponseData.WriteHeader(http.StatusOK)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := logs.Read(buf)
+		if n > 0 {
+			if _, err := ctx.ResponseData.Write(buf[:n]); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				log.Error(ctx, map[string]interface{}{
+					"err":        err,
+					"space_name": *kubeSpaceName,
+				}, "error streaming logs of deployment %s", ctx.DeployName)
+			}
+			return nil
+		}
+	}
+}
+
+// ShowDeploymentEvents runs the showDeploymentEvents action.
+func (c *DeploymentsController) ShowDeploymentEvents(ctx *app.ShowDeploymentEventsDeploymentsContext) error {
+	kc, err := c.GetKubeClient(ctx)
+	defer cleanup(kc)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	kubeSpaceName, err := c.getSpaceNameFromSpaceID(ctx, ctx.SpaceID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("osio space", ctx.SpaceID.String()))
+	}
+
+	events, err := kc.GetDeploymentEvents(*kubeSpaceName, ctx.AppName, ctx.DeployName)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "error retrieving deployment events for %s", ctx.DeployName))
+	}
+	if events == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("deployment", ctx.DeployName))
+	}
+
+	res := &app.SimpleDeploymentEventList{
+		Data: events,
+	}
+
+	return ctx.OK(res)
+}
+
+// RollbackDeployment runs the rollbackDeployment action.
+func (c *DeploymentsController) RollbackDeployment(ctx *app.RollbackDeploymentDeploymentsContext) error {
+	kc, err := c.GetKubeClient(ctx)
+	defer cleanup(kc)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	kubeSpaceName, err := c.getSpaceNameFromSpaceID(ctx, ctx.SpaceID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("osio space", ctx.SpaceID.String()))
+	}
+
+	err = kc.RollbackDeployment(*kubeSpaceName, ctx.AppName, ctx.DeployName, ctx.Version)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":        err,
+			"space_name": *kubeSpaceName,
+			"version":    ctx.Version,
+		}, "error rolling back deployment")
+		return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "error rolling back deployment %s", ctx.DeployName))
+	}
+
+	return ctx.OK([]byte{})
+}
+
 // ShowDeploymentStatSeries runs the showDeploymentStatSeries action.
 func (c *DeploymentsController) ShowDeploymentStatSeries(ctx *app.ShowDeploymentStatSeriesDeploymentsContext) error {
 
@@ -381,8 +553,69 @@ func (c *DeploymentsController) ShowSpaceEnvironments(ctx *app.ShowSpaceEnvironm
 	return ctx.OK(res)
 }
 
+// ShowEnvironmentHealth runs the showEnvironmentHealth action.
+func (c *DeploymentsController) ShowEnvironmentHealth(ctx *app.ShowEnvironmentHealthDeploymentsContext) error {
+	kc, err := c.GetKubeClient(ctx)
+	defer cleanup(kc)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	kubeSpaceName, err := c.getSpaceNameFromSpaceID(ctx, ctx.SpaceID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("osio space", ctx.SpaceID.String()))
+	}
+
+	health, err := kc.GetEnvironmentHealth(*kubeSpaceName, ctx.EnvName)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "error retrieving health of environment %s", ctx.EnvName))
+	}
+
+	if c.HealthWatcher != nil {
+		// the watcher needs its own client, as this one is closed with the request
+		err = c.HealthWatcher.Watch(ctx.SpaceID, *kubeSpaceName, health, tokenExpiry(ctx), func() (kubernetes.KubeClientInterface, error) {
+			return c.GetKubeClient(ctx)
+		})
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"err":         err,
+				"space_id":    ctx.SpaceID,
+				"environment": ctx.EnvName,
+			}, "failed to watch the health of the environment")
+		}
+	}
+
+	res := &app.SimpleEnvironmentHealthSingle{
+		Data: health,
+	}
+
+	return ctx.OK(res)
+}
+
 func cleanup(kc kubernetes.KubeClientInterface) {
 	if kc != nil {
 		kc.Close()
 	}
 }
+
+// tokenExpiry returns the expiry of the token of the request, or the zero time
+// if it has none
+func tokenExpiry(ctx context.Context) time.Time {
+	token := goajwt.ContextJWT(ctx)
+	if token == nil {
+		return time.Time{}
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return time.Time{}
+	}
+	switch exp := claims["exp"].(type) {
+	case float64:
+		return time.Unix(int64(exp), 0)
+	case json.Number:
+		if v, err := exp.Int64(); err == nil {
+			return time.Unix(v, 0)
+		}
+	}
+	return time.Time{}
+}
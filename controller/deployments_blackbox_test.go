@@ -4,14 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/gojuno/minimock"
 
 	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/goatest"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,9 +28,11 @@ import (
 	"github.com/fabric8-services/fabric8-wit/controller"
 	witerrors "github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/kubernetes"
+	"github.com/fabric8-services/fabric8-wit/ptr"
 	"github.com/fabric8-services/fabric8-wit/space"
 	testcontroller "github.com/fabric8-services/fabric8-wit/test/controller"
 	testk8s "github.com/fabric8-services/fabric8-wit/test/kubernetes"
+	notificationsupport "github.com/fabric8-services/fabric8-wit/test/notification"
 )
 
 type testKubeClient struct {
@@ -343,6 +351,312 @@ func TestSetDeployment(t *testing.T) {
 	})
 }
 
+func TestShowDeploymentHistory(t *testing.T) {
+	// given
+	spaceName := "mySpace"
+	appName := "myApp"
+	envName := "run"
+
+	clientGetterMock := testcontroller.NewClientGetterMock(t)
+	svc, ctrl, err := createDeploymentsController()
+	require.NoError(t, err)
+	ctrl.ClientGetter = clientGetterMock
+	clientGetterMock.GetAndCheckOSIOClientFunc = func(ctx context.Context) (controller.OpenshiftIOClient, error) {
+		return createOSIOClientMock(t, spaceName), nil
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		// given
+		kubeClientMock := testk8s.NewKubeClientMock(t)
+		defer kubeClientMock.Finish()
+		kubeClientMock.GetDeploymentHistoryMock.Expect(spaceName, appName, envName).Return([]*app.SimpleDeploymentRevision{
+			{
+				ID:   "myDeploy-2",
+				Type: "deploymentrevision",
+				Attributes: &app.SimpleDeploymentRevisionAttributes{
+					Version: 2,
+				},
+			},
+			{
+				ID:   "myDeploy-1",
+				Type: "deploymentrevision",
+				Attributes: &app.SimpleDeploymentRevisionAttributes{
+					Version: 1,
+				},
+			},
+		}, nil)
+		kubeClientMock.CloseFunc = func() {}
+		clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+			return kubeClientMock, nil
+		}
+		// when
+		_, res := test.ShowDeploymentHistoryDeploymentsOK(t, context.Background(), svc, ctrl, space.SystemSpace,
+			appName, envName)
+		// then
+		require.Len(t, res.Data, 2)
+		assert.Equal(t, 2, res.Data[0].Attributes.Version)
+		assert.Equal(t, 1, res.Data[1].Attributes.Version)
+		// verify that the Close method was called
+		assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+
+		t.Run("deployment not found", func(t *testing.T) {
+			// given
+			kubeClientMock := testk8s.NewKubeClientMock(t)
+			defer kubeClientMock.Finish()
+			kubeClientMock.GetDeploymentHistoryMock.Expect(spaceName, appName, envName).Return(nil, nil)
+			kubeClientMock.CloseFunc = func() {}
+			clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+				return kubeClientMock, nil
+			}
+			// when
+			test.ShowDeploymentHistoryDeploymentsNotFound(t, context.Background(), svc, ctrl, space.SystemSpace,
+				appName, envName)
+			// then verify that the Close method was called
+			assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+		})
+
+		t.Run("get deployment history bad request", func(t *testing.T) {
+			// given
+			kubeClientMock := testk8s.NewKubeClientMock(t)
+			defer kubeClientMock.Finish()
+			kubeClientMock.GetDeploymentHistoryMock.Expect(spaceName, appName, envName).Return(nil,
+				witerrors.NewBadParameterErrorFromString("TEST"))
+			kubeClientMock.CloseFunc = func() {}
+			clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+				return kubeClientMock, nil
+			}
+			// when
+			test.ShowDeploymentHistoryDeploymentsBadRequest(t, context.Background(), svc, ctrl, space.SystemSpace,
+				appName, envName)
+			// then verify that the Close method was called
+			assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+		})
+	})
+}
+
+func TestShowDeploymentLogs(t *testing.T) {
+	// given
+	spaceName := "mySpace"
+	appName := "myApp"
+	envName := "run"
+
+	clientGetterMock := testcontroller.NewClientGetterMock(t)
+	svc, ctrl, err := createDeploymentsController()
+	require.NoError(t, err)
+	ctrl.ClientGetter = clientGetterMock
+	clientGetterMock.GetAndCheckOSIOClientFunc = func(ctx context.Context) (controller.OpenshiftIOClient, error) {
+		return createOSIOClientMock(t, spaceName), nil
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		// given
+		kubeClientMock := testk8s.NewKubeClientMock(t)
+		defer kubeClientMock.Finish()
+		kubeClientMock.GetDeploymentLogsMock.Expect(spaceName, appName, envName, &kubernetes.DeploymentLogOptions{
+			Container: "myDeploy",
+			TailLines: ptr.Int64(10),
+		}).Return(ioutil.NopCloser(strings.NewReader("[myDeploy-1-nfs9w] Started\n")), nil)
+		kubeClientMock.CloseFunc = func() {}
+		clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+			return kubeClientMock, nil
+		}
+		// when
+		rw := test.ShowDeploymentLogsDeploymentsOK(t, context.Background(), svc, ctrl, space.SystemSpace,
+			appName, envName, ptr.String("myDeploy"), nil, nil, ptr.Int(10))
+		// then
+		assert.Equal(t, "text/plain; charset=utf-8", rw.Header().Get("Content-Type"))
+		assert.Equal(t, "[myDeploy-1-nfs9w] Started\n", rw.(*httptest.ResponseRecorder).Body.String())
+		// verify that the Close method was called
+		assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+	})
+
+	t.Run("default tail", func(t *testing.T) {
+		// given
+		kubeClientMock := testk8s.NewKubeClientMock(t)
+		defer kubeClientMock.Finish()
+		kubeClientMock.GetDeploymentLogsMock.Expect(spaceName, appName, envName, &kubernetes.DeploymentLogOptions{
+			TailLines: ptr.Int64(100),
+		}).Return(ioutil.NopCloser(strings.NewReader("")), nil)
+		kubeClientMock.CloseFunc = func() {}
+		clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+			return kubeClientMock, nil
+		}
+		// when/then
+		test.ShowDeploymentLogsDeploymentsOK(t, context.Background(), svc, ctrl, space.SystemSpace,
+			appName, envName, nil, nil, nil, nil)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+
+		t.Run("kube client init failure", func(t *testing.T) {
+			// given
+			clientGetterMock.GetKubeClientFunc = func(p context.Context) (r kubernetes.KubeClientInterface, r1 error) {
+				return nil, fmt.Errorf("failure")
+			}
+			// when/then
+			test.ShowDeploymentLogsDeploymentsInternalServerError(t, context.Background(), svc, ctrl, space.SystemSpace,
+				appName, envName, nil, nil, nil, nil)
+		})
+
+		t.Run("deployment not found", func(t *testing.T) {
+			// given
+			kubeClientMock := testk8s.NewKubeClientMock(t)
+			defer kubeClientMock.Finish()
+			kubeClientMock.GetDeploymentLogsFunc = func(spaceName string, appName string, envName string,
+				opts *kubernetes.DeploymentLogOptions) (io.ReadCloser, error) {
+				return nil, witerrors.NewNotFoundError("deployment", envName)
+			}
+			kubeClientMock.CloseFunc = func() {}
+			clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+				return kubeClientMock, nil
+			}
+			// when
+			test.ShowDeploymentLogsDeploymentsNotFound(t, context.Background(), svc, ctrl, space.SystemSpace,
+				appName, envName, nil, nil, nil, nil)
+			// then verify that the Close method was called
+			assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+		})
+	})
+}
+
+func TestShowDeploymentEvents(t *testing.T) {
+	// given
+	spaceName := "mySpace"
+	appName := "myApp"
+	envName := "run"
+
+	clientGetterMock := testcontroller.NewClientGetterMock(t)
+	svc, ctrl, err := createDeploymentsController()
+	require.NoError(t, err)
+	ctrl.ClientGetter = clientGetterMock
+	clientGetterMock.GetAndCheckOSIOClientFunc = func(ctx context.Context) (controller.OpenshiftIOClient, error) {
+		return createOSIOClientMock(t, spaceName), nil
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		// given
+		kubeClientMock := testk8s.NewKubeClientMock(t)
+		defer kubeClientMock.Finish()
+		kubeClientMock.GetDeploymentEventsMock.Expect(spaceName, appName, envName).Return([]*app.SimpleDeploymentEvent{
+			{
+				ID:   "5a0c3e1c-4d44-4b0e-9a53-5f1c1e3b0a03",
+				Type: "deploymentevent",
+				Attributes: &app.SimpleDeploymentEventAttributes{
+					EventType:  "Warning",
+					Reason:     "BackOff",
+					Count:      3,
+					ObjectKind: "Pod",
+					ObjectName: "myDeploy-1-sdmzq",
+				},
+			},
+		}, nil)
+		kubeClientMock.CloseFunc = func() {}
+		clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+			return kubeClientMock, nil
+		}
+		// when
+		_, res := test.ShowDeploymentEventsDeploymentsOK(t, context.Background(), svc, ctrl, space.SystemSpace,
+			appName, envName)
+		// then
+		require.Len(t, res.Data, 1)
+		assert.Equal(t, "BackOff", res.Data[0].Attributes.Reason)
+		assert.Equal(t, 3, res.Data[0].Attributes.Count)
+		// verify that the Close method was called
+		assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+
+		t.Run("deployment not found", func(t *testing.T) {
+			// given
+			kubeClientMock := testk8s.NewKubeClientMock(t)
+			defer kubeClientMock.Finish()
+			kubeClientMock.GetDeploymentEventsMock.Expect(spaceName, appName, envName).Return(nil, nil)
+			kubeClientMock.CloseFunc = func() {}
+			clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+				return kubeClientMock, nil
+			}
+			// when
+			test.ShowDeploymentEventsDeploymentsNotFound(t, context.Background(), svc, ctrl, space.SystemSpace,
+				appName, envName)
+			// then verify that the Close method was called
+			assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+		})
+
+		t.Run("get deployment events internal error", func(t *testing.T) {
+			// given
+			kubeClientMock := testk8s.NewKubeClientMock(t)
+			defer kubeClientMock.Finish()
+			kubeClientMock.GetDeploymentEventsMock.Expect(spaceName, appName, envName).Return(nil,
+				witerrors.NewInternalErrorFromString("TEST"))
+			kubeClientMock.CloseFunc = func() {}
+			clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+				return kubeClientMock, nil
+			}
+			// when
+			test.ShowDeploymentEventsDeploymentsInternalServerError(t, context.Background(), svc, ctrl, space.SystemSpace,
+				appName, envName)
+			// then verify that the Close method was called
+			assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+		})
+	})
+}
+
+func TestRollbackDeployment(t *testing.T) {
+	// given
+	spaceName := "mySpace"
+	appName := "myApp"
+	envName := "run"
+	version := 1
+
+	clientGetterMock := testcontroller.NewClientGetterMock(t)
+	svc, ctrl, err := createDeploymentsController()
+	require.NoError(t, err)
+	ctrl.ClientGetter = clientGetterMock
+	clientGetterMock.GetAndCheckOSIOClientFunc = func(ctx context.Context) (controller.OpenshiftIOClient, error) {
+		return createOSIOClientMock(t, spaceName), nil
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		// given
+		kubeClientMock := testk8s.NewKubeClientMock(t)
+		defer kubeClientMock.Finish()
+		kubeClientMock.RollbackDeploymentMock.Expect(spaceName, appName, envName, version).Return(nil)
+		kubeClientMock.CloseFunc = func() {}
+		clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+			return kubeClientMock, nil
+		}
+		// when
+		test.RollbackDeploymentDeploymentsOK(t, context.Background(), svc, ctrl, space.SystemSpace,
+			appName, envName, version)
+		// then verify that the Close method was called
+		assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+
+		t.Run("unknown version", func(t *testing.T) {
+			// given
+			kubeClientMock := testk8s.NewKubeClientMock(t)
+			defer kubeClientMock.Finish()
+			kubeClientMock.RollbackDeploymentMock.Expect(spaceName, appName, envName, version).Return(
+				witerrors.NewNotFoundError("deployment version", "1"))
+			kubeClientMock.CloseFunc = func() {}
+			clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+				return kubeClientMock, nil
+			}
+			// when
+			test.RollbackDeploymentDeploymentsNotFound(t, context.Background(), svc, ctrl, space.SystemSpace,
+				appName, envName, version)
+			// then verify that the Close method was called
+			assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+		})
+	})
+}
+
 func TestShowDeploymentStats(t *testing.T) {
 	// given
 	spaceName := "mySpace"
@@ -575,6 +889,116 @@ func TestShowSpaceEnvironments(t *testing.T) {
 
 }
 
+func TestShowEnvironmentHealth(t *testing.T) {
+	// given
+	spaceName := "mySpace"
+	envName := "run"
+	health := &app.SimpleEnvironmentHealth{
+		ID:   envName,
+		Type: "environmenthealth",
+		Attributes: &app.SimpleEnvironmentHealthAttributes{
+			Name:   envName,
+			Status: kubernetes.HealthWarning,
+			Applications: []*app.SimpleApplicationHealth{
+				{
+					Name:        "myApp",
+					Status:      kubernetes.HealthWarning,
+					PodTotal:    2,
+					PodsWarning: 1,
+				},
+			},
+		},
+	}
+
+	clientGetterMock := testcontroller.NewClientGetterMock(t)
+	svc, ctrl, err := createDeploymentsController()
+	require.NoError(t, err)
+	ctrl.ClientGetter = clientGetterMock
+	clientGetterMock.GetAndCheckOSIOClientFunc = func(ctx context.Context) (controller.OpenshiftIOClient, error) {
+		return createOSIOClientMock(t, spaceName), nil
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		// given
+		kubeClientMock := testk8s.NewKubeClientMock(t)
+		defer kubeClientMock.Finish()
+		kubeClientMock.GetEnvironmentHealthMock.Expect(spaceName, envName).Return(health, nil)
+		kubeClientMock.CloseFunc = func() {}
+		clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+			return kubeClientMock, nil
+		}
+		// when
+		_, res := test.ShowEnvironmentHealthDeploymentsOK(t, context.Background(), svc, ctrl, space.SystemSpace, envName)
+		// then
+		require.NotNil(t, res.Data)
+		assert.Equal(t, kubernetes.HealthWarning, res.Data.Attributes.Status)
+		require.Len(t, res.Data.Attributes.Applications, 1)
+		assert.Equal(t, 1, res.Data.Attributes.Applications[0].PodsWarning)
+		// verify that the Close method was called
+		assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+	})
+
+	t.Run("ok with health watcher", func(t *testing.T) {
+		// given
+		kubeClientMock := testk8s.NewKubeClientMock(t)
+		defer kubeClientMock.Finish()
+		kubeClientMock.GetEnvironmentHealthMock.Expect(spaceName, envName).Return(health, nil)
+		kubeClientMock.CloseFunc = func() {}
+		clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+			return kubeClientMock, nil
+		}
+		ctrl.HealthWatcher = kubernetes.NewHealthWatcher(&notificationsupport.FakeNotificationChannel{}, "sa-token", time.Hour, time.Hour)
+		defer func() {
+			ctrl.HealthWatcher = nil
+		}()
+		// the watcher uses the token of the request until it expires
+		ctx := goajwt.WithJWT(context.Background(), &jwt.Token{Claims: jwt.MapClaims{"exp": float64(time.Now().Add(time.Hour).Unix())}})
+		// when
+		test.ShowEnvironmentHealthDeploymentsOK(t, ctx, svc, ctrl, space.SystemSpace, envName)
+		// then the client of the request is closed, the one of the watcher
+		// is closed when the watcher stops
+		assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+		ctrl.HealthWatcher.Stop()
+		assert.Equal(t, uint64(2), kubeClientMock.CloseCounter)
+	})
+
+	t.Run("ok with health watcher and a token without expiry", func(t *testing.T) {
+		// given
+		kubeClientMock := testk8s.NewKubeClientMock(t)
+		defer kubeClientMock.Finish()
+		kubeClientMock.GetEnvironmentHealthMock.Expect(spaceName, envName).Return(health, nil)
+		kubeClientMock.CloseFunc = func() {}
+		clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+			return kubeClientMock, nil
+		}
+		ctrl.HealthWatcher = kubernetes.NewHealthWatcher(&notificationsupport.FakeNotificationChannel{}, "sa-token", time.Hour, time.Hour)
+		defer func() {
+			ctrl.HealthWatcher = nil
+		}()
+		// when
+		test.ShowEnvironmentHealthDeploymentsOK(t, context.Background(), svc, ctrl, space.SystemSpace, envName)
+		// then the environment isn't watched
+		ctrl.HealthWatcher.Stop()
+		assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		// given
+		kubeClientMock := testk8s.NewKubeClientMock(t)
+		defer kubeClientMock.Finish()
+		kubeClientMock.GetEnvironmentHealthMock.Expect(spaceName, envName).Return(nil,
+			witerrors.NewInternalErrorFromString("TEST"))
+		kubeClientMock.CloseFunc = func() {}
+		clientGetterMock.GetKubeClientFunc = func(ctx context.Context) (kubernetes.KubeClientInterface, error) {
+			return kubeClientMock, nil
+		}
+		// when
+		test.ShowEnvironmentHealthDeploymentsInternalServerError(t, context.Background(), svc, ctrl, space.SystemSpace, envName)
+		// then verify that the Close method was called
+		assert.Equal(t, uint64(1), kubeClientMock.CloseCounter)
+	})
+}
+
 func createOSIOClientMock(t minimock.Tester, spaceName string) *testcontroller.OSIOClientMock {
 	osioClientMock := testcontroller.NewOSIOClientMock(t)
 	osioClientMock.GetSpaceByIDFunc = func(ctx context.Context, spaceID uuid.UUID) (*app.Space, error) {
@@ -13,11 +13,12 @@ import (
 	"github.com/fabric8-services/fabric8-wit/auth"
 	"github.com/fabric8-services/fabric8-wit/auth/authservice"
 	"github.com/fabric8-services/fabric8-wit/configuration"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	witmiddleware "github.com/fabric8-services/fabric8-wit/goamiddleware"
 	"github.com/fabric8-services/fabric8-wit/goasupport"
 	"github.com/fabric8-services/fabric8-wit/kubernetes"
 	"github.com/fabric8-services/fabric8-wit/log"
 
-	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 	errs "github.com/pkg/errors"
 )
 
@@ -90,6 +91,12 @@ var _ kubernetes.BaseURLProvider = (*tenantURLProvider)(nil)
 
 // NewURLProvider looks at what servers are available and create a BaseURLProvder that fits
 func NewURLProvider(ctx context.Context, config *configuration.Registry, osioclient OpenshiftIOClient) (kubernetes.BaseURLProvider, error) {
+	// the token is sent to the tenant, auth and OpenShift services, which
+	// must never receive a personal access token
+	jwttoken := witmiddleware.ForwardableJWT(ctx)
+	if jwttoken == nil {
+		return nil, errors.NewUnauthorizedError("missing token")
+	}
 
 	userServices, err := osioclient.GetUserServices(ctx)
 	if err != nil {
@@ -99,7 +106,7 @@ func NewURLProvider(ctx context.Context, config *configuration.Registry, osiocli
 		return nil, err
 	}
 
-	token := goajwt.ContextJWT(ctx).Raw
+	token := jwttoken.Raw
 	proxyURL := config.GetOpenshiftProxyURL()
 
 	up, err := newTenantURLProviderFromTenant(userServices, token, proxyURL)
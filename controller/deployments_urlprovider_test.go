@@ -1,6 +1,7 @@
 package controller_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -8,10 +9,14 @@ import (
 	"testing"
 
 	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/configuration"
 	"github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/kubernetes"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
 
 	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -224,3 +229,37 @@ func tostring(item interface{}) string {
 	}
 	return string(bytes)
 }
+
+// recordingOSIOClient records the calls to the OSIO API
+type recordingOSIOClient struct {
+	calls int
+}
+
+func (c *recordingOSIOClient) GetNamespaceByType(ctx context.Context, userService *app.UserService, namespaceType string) (*app.NamespaceAttributes, error) {
+	c.calls++
+	return nil, nil
+}
+
+func (c *recordingOSIOClient) GetUserServices(ctx context.Context) (*app.UserService, error) {
+	c.calls++
+	return getDefaultTenant()
+}
+
+func (c *recordingOSIOClient) GetSpaceByID(ctx context.Context, spaceID uuid.UUID) (*app.Space, error) {
+	c.calls++
+	return nil, nil
+}
+
+func TestURLProviderWithPersonalAccessToken(t *testing.T) {
+	config, err := configuration.Get()
+	require.NoError(t, err)
+	osioclient := &recordingOSIOClient{}
+	ctx := testsupport.WithResolvedToken(context.Background(), "f8pat_secret", testsupport.TestIdentity)
+
+	up, err := controller.NewURLProvider(ctx, config, osioclient)
+	require.Error(t, err)
+	require.IsType(t, errors.UnauthorizedError{}, err)
+	require.Nil(t, up)
+	// the personal access token is never sent to the tenant nor OpenShift
+	require.Equal(t, 0, osioclient.calls)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/fabric8-services/fabric8-wit/app"
 	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/goadesign/goa"
 )
 
@@ -40,91 +41,47 @@ const (
 	filterKeyTitle        = "title"
 )
 
+// filterDefinition describes one of the filters that are always available
+type filterDefinition struct {
+	title       string
+	description string
+	typ         string
+	key         string
+	// field is the work item field the filter applies to, if any
+	field string
+}
+
+var builtinFilters = []filterDefinition{
+	{title: "Assignee", description: "Filter by assignee", typ: "users", key: filterKeyAssignee, field: workitem.SystemAssignees},
+	{title: "Creator", description: "Filter by creator", typ: "users", key: filterKeyCreator, field: workitem.SystemCreator},
+	{title: "Area", description: "Filter by area", typ: "areas", key: filterKeyArea, field: workitem.SystemArea},
+	{title: "Iteration", description: "Filter by iteration", typ: "iterations", key: filterKeyIteration, field: workitem.SystemIteration},
+	{title: "Workitem type", description: "Filter by workitemtype", typ: "workitemtypes", key: filterKeyWorkItemType},
+	{title: "State", description: "Filter by state", typ: "state", key: filterKeyState, field: workitem.SystemState},
+	{title: "Label", description: "Filter by label", typ: "labels", key: filterKeyLabel, field: workitem.SystemLabels},
+	{title: "Title", description: "Filter by title", typ: "title", key: filterKeyTitle, field: workitem.SystemTitle}, // type not really used anywhere
+}
+
+// convert converts the filter definition to its REST representation
+func (f filterDefinition) convert() *app.Filters {
+	return &app.Filters{
+		Attributes: &app.FilterAttributes{
+			Title:       f.title,
+			Description: f.description,
+			Type:        f.typ,
+			Query:       fmt.Sprintf("filter[%s]={id}", f.key),
+			Key:         f.key,
+		},
+		Type: "filters",
+	}
+}
+
 // List runs the list action.
 func (c *FilterController) List(ctx *app.ListFilterContext) error {
-	var arr []*app.Filters
-	arr = append(arr,
-		&app.Filters{
-			Attributes: &app.FilterAttributes{
-				Title:       "Assignee",
-				Description: "Filter by assignee",
-				Type:        "users",
-				Query:       fmt.Sprintf("filter[%s]={id}", filterKeyAssignee),
-				Key:         filterKeyAssignee,
-			},
-			Type: "filters",
-		},
-		&app.Filters{
-			Attributes: &app.FilterAttributes{
-				Title:       "Creator",
-				Description: "Filter by creator",
-				Type:        "users",
-				Query:       fmt.Sprintf("filter[%s]={id}", filterKeyCreator),
-				Key:         filterKeyCreator,
-			},
-			Type: "filters",
-		},
-		&app.Filters{
-			Attributes: &app.FilterAttributes{
-				Title:       "Area",
-				Query:       fmt.Sprintf("filter[%s]={id}", filterKeyArea),
-				Key:         filterKeyArea,
-				Description: "Filter by area",
-				Type:        "areas",
-			},
-			Type: "filters",
-		},
-		&app.Filters{
-			Attributes: &app.FilterAttributes{
-				Title:       "Iteration",
-				Query:       fmt.Sprintf("filter[%s]={id}", filterKeyIteration),
-				Key:         filterKeyIteration,
-				Description: "Filter by iteration",
-				Type:        "iterations",
-			},
-			Type: "filters",
-		},
-		&app.Filters{
-			Attributes: &app.FilterAttributes{
-				Title:       "Workitem type",
-				Query:       fmt.Sprintf("filter[%s]={id}", filterKeyWorkItemType),
-				Key:         filterKeyWorkItemType,
-				Description: "Filter by workitemtype",
-				Type:        "workitemtypes",
-			},
-			Type: "filters",
-		},
-		&app.Filters{
-			Attributes: &app.FilterAttributes{
-				Title:       "State",
-				Query:       fmt.Sprintf("filter[%s]={id}", filterKeyState),
-				Key:         filterKeyState,
-				Description: "Filter by state",
-				Type:        "state",
-			},
-			Type: "filters",
-		},
-		&app.Filters{
-			Attributes: &app.FilterAttributes{
-				Title:       "Label",
-				Query:       fmt.Sprintf("filter[%s]={id}", filterKeyLabel),
-				Key:         filterKeyLabel,
-				Description: "Filter by label",
-				Type:        "labels",
-			},
-			Type: "filters",
-		},
-		&app.Filters{
-			Attributes: &app.FilterAttributes{
-				Title:       "Title",
-				Query:       fmt.Sprintf("filter[%s]={id}", filterKeyTitle),
-				Key:         filterKeyTitle,
-				Description: "Filter by title",
-				Type:        "title", // not really used anywhere
-			},
-			Type: "filters",
-		},
-	)
+	arr := make([]*app.Filters, len(builtinFilters))
+	for i, f := range builtinFilters {
+		arr[i] = f.convert()
+	}
 	result := &app.FilterList{
 		Data: arr,
 	}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/graphql"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// GraphQLController implements the graphql resource.
+type GraphQLController struct {
+	*goa.Controller
+	executor *graphql.Executor
+}
+
+// NewGraphQLExecutor returns the executor of the GraphQL queries, which only
+// reads the spaces the current user is allowed to read.
+func NewGraphQLExecutor(db application.DB, config graphql.Config) (*graphql.Executor, error) {
+	return graphql.NewExecutor(db, config, func(ctx context.Context, appl application.Application, spaceID uuid.UUID) error {
+		return authorizeSpace(ctx, appl, spaceID, role.ReadSpace)
+	})
+}
+
+// NewGraphQLController creates a graphql controller.
+func NewGraphQLController(service *goa.Service, executor *graphql.Executor) *GraphQLController {
+	return &GraphQLController{
+		Controller: service.NewController("GraphQLController"),
+		executor:   executor,
+	}
+}
+
+// Query runs the query action.
+func (c *GraphQLController) Query(ctx *app.QueryGraphqlContext) error {
+	if _, err := login.ContextIdentity(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	req := graphql.Request{
+		Query:     ctx.Payload.Query,
+		Variables: ctx.Payload.Variables,
+	}
+	if ctx.Payload.OperationName != nil {
+		req.OperationName = *ctx.Payload.OperationName
+	}
+	res, err := c.executor.Exec(ctx, req)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(ctx, err))
+	}
+	// the errors of the query are part of the response, as expected by the
+	// GraphQL clients
+	return ctx.OK(body)
+}
@@ -0,0 +1,100 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestGraphQLREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunGraphQLREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestGraphQLREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestGraphQLREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func (s *TestGraphQLREST) newController(t *testing.T, svc *goa.Service) *GraphQLController {
+	executor, err := NewGraphQLExecutor(s.db, s.Configuration)
+	require.NoError(t, err)
+	return NewGraphQLController(svc, executor)
+}
+
+func (s *TestGraphQLREST) TestQuery() {
+	s.T().Run("ok", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(2, tf.SetWorkItemTitles("A", "B")))
+		svc := testsupport.ServiceAsUser("GraphQL-Service", *fxt.Identities[0])
+		ctrl := s.newController(t, svc)
+		payload := &app.GraphQLRequest{
+			Query: `query($id: ID!) { space(id: $id) { name workItems { nodes { title } } } }`,
+			Variables: map[string]interface{}{
+				"id": fxt.Spaces[0].ID.String(),
+			},
+		}
+		// when
+		rw := test.QueryGraphqlOK(t, svc.Context, svc, ctrl, payload)
+		// then
+		var res struct {
+			Data struct {
+				Space struct {
+					Name      string
+					WorkItems struct {
+						Nodes []struct{ Title string }
+					}
+				}
+			}
+			Errors []interface{}
+		}
+		require.NoError(t, json.NewDecoder(rw.(*httptest.ResponseRecorder).Body).Decode(&res))
+		assert.Empty(t, res.Errors)
+		assert.Equal(t, fxt.Spaces[0].Name, res.Data.Space.Name)
+		assert.Len(t, res.Data.Space.WorkItems.Nodes, 2)
+	})
+
+	s.T().Run("query errors", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		svc := testsupport.ServiceAsUser("GraphQL-Service", *fxt.Identities[0])
+		ctrl := s.newController(t, svc)
+		payload := &app.GraphQLRequest{
+			Query: `{ space(id: "not a UUID") { unknown } }`,
+		}
+		// when
+		rw := test.QueryGraphqlOK(t, svc.Context, svc, ctrl, payload)
+		// then
+		var res struct {
+			Errors []struct{ Message string }
+		}
+		require.NoError(t, json.NewDecoder(rw.(*httptest.ResponseRecorder).Body).Decode(&res))
+		assert.NotEmpty(t, res.Errors)
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		// given
+		svc := goa.New("GraphQL-Service")
+		ctrl := s.newController(t, svc)
+		// when/then
+		test.QueryGraphqlUnauthorized(t, svc.Context, svc, ctrl, &app.GraphQLRequest{Query: `{ __typename }`})
+	})
+}
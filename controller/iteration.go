@@ -1,7 +1,6 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 
@@ -14,7 +13,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space"
-	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 
 	"github.com/goadesign/goa"
@@ -45,22 +44,9 @@ func NewIterationController(service *goa.Service, db application.DB, config Iter
 	return &IterationController{Controller: service.NewController("IterationController"), db: db, config: config}
 }
 
-// verifyUser checks if user is a space owner or a collaborator
-func verifyUser(ctx context.Context, currentUser uuid.UUID, sp *space.Space) (bool, bool, error) {
-	authorized, err := authz.Authorize(ctx, sp.ID.String())
-	if err != nil {
-		return false, false, err
-	}
-	var spaceOwner bool
-	if uuid.Equal(currentUser, sp.OwnerID) {
-		spaceOwner = true
-	}
-	return authorized, spaceOwner, nil
-}
-
 // CreateChild runs the create-child action.
 func (c *IterationController) CreateChild(ctx *app.CreateChildIterationContext) error {
-	currentUser, err := login.ContextIdentity(ctx)
+	_, err := login.ContextIdentity(ctx)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
@@ -69,37 +55,16 @@ func (c *IterationController) CreateChild(ctx *app.CreateChildIterationContext)
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
 	}
 	var parentItr *iteration.Iteration
-	var itrSpace *space.Space
 	err = application.Transactional(c.db, func(appl application.Application) error {
 		parentItr, err = appl.Iterations().Load(ctx, parentID)
 		if err != nil {
 			return err
 		}
-		itrSpace, err = appl.Spaces().Load(ctx, parentItr.SpaceID)
-		return err
+		return authorizeSpace(ctx, appl, parentItr.SpaceID, role.PlanIterations)
 	})
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	authorized, spaceOwner, err := verifyUser(ctx, *currentUser, itrSpace)
-	if err != nil {
-		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
-	}
-	if !authorized && !spaceOwner {
-		log.Error(ctx, map[string]interface{}{
-			"space_id":     itrSpace.ID,
-			"space_owner":  itrSpace.OwnerID,
-			"current_user": *currentUser,
-		}, "user is not the space owner")
-		if !authorized {
-			// unauthorized
-			return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError("user is not allowed to create an iteration in this space"))
-		}
-		// forbidden
-		// Ideally we never hit following error
-		// But written following line to make it verbose 401 vs 403
-		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not allowed to create an iteration in this space"))
-	}
 	reqItr := ctx.Payload.Data
 	if reqItr.Attributes.Name == nil {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
@@ -193,7 +158,7 @@ func (c *IterationController) Show(ctx *app.ShowIterationContext) error {
 
 // Update runs the update action.
 func (c *IterationController) Update(ctx *app.UpdateIterationContext) error {
-	currentUser, err := login.ContextIdentity(ctx)
+	_, err := login.ContextIdentity(ctx)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
@@ -203,37 +168,16 @@ func (c *IterationController) Update(ctx *app.UpdateIterationContext) error {
 	}
 
 	var itr *iteration.Iteration
-	var sp *space.Space
 	err = application.Transactional(c.db, func(appl application.Application) error {
 		itr, err = appl.Iterations().Load(ctx.Context, id)
 		if err != nil {
 			return err
 		}
-		sp, err = appl.Spaces().Load(ctx, itr.SpaceID)
-		return err
+		return authorizeSpace(ctx, appl, itr.SpaceID, role.PlanIterations)
 	})
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	authorized, spaceOwner, err := verifyUser(ctx, *currentUser, sp)
-	if err != nil {
-		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
-	}
-	if !authorized && !spaceOwner {
-		log.Error(ctx, map[string]interface{}{
-			"space_id":     sp.ID,
-			"space_owner":  sp.OwnerID,
-			"current_user": *currentUser,
-		}, "user is not the space owner")
-		if !authorized {
-			// unauthorized
-			return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError("user is not allowed to create an iteration in this space"))
-		}
-		// forbidden:
-		// Ideally we never hit following error
-		// But written following line to make it verbose 401 vs 403
-		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not allowed to create an iteration in this space"))
-	}
 	var iterations []iteration.Iteration
 	var wiCounts map[string]workitem.WICountsPerIteration
 	err = application.Transactional(c.db, func(appl application.Application) error {
@@ -351,23 +295,12 @@ func (c *IterationController) Delete(ctx *app.DeleteIterationContext) error {
 		if err != nil {
 			return err
 		}
-		s, err := appl.Spaces().Load(ctx, itr.SpaceID)
-		if err != nil {
-			return goa.ErrNotFound(err.Error())
-		}
-		if !uuid.Equal(*currentUser, s.OwnerID) {
-			errorMsg := fmt.Sprintf("only the space owner can delete an iteration and %s is not the space owner of %s",
-				*currentUser, s.ID)
-			log.Warn(ctx, map[string]interface{}{
-				"space_id":     s.ID,
-				"space_owner":  s.OwnerID,
-				"current_user": *currentUser,
-			}, errorMsg)
-			return errors.NewForbiddenError(errorMsg)
+		if err := authorizeSpace(ctx, appl, itr.SpaceID, role.ManageIterations); err != nil {
+			return err
 		}
-		if itr.IsRoot(s.ID) {
+		if itr.IsRoot(itr.SpaceID) {
 			log.Warn(ctx, map[string]interface{}{
-				"space_id":     s.ID,
+				"space_id":     itr.SpaceID,
 				"iteration_id": itr.ID,
 			}, "cannot delete root iteration")
 			return errors.NewForbiddenError("can not delete root iteration")
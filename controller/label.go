@@ -12,6 +12,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/goadesign/goa"
 )
 
@@ -75,6 +76,9 @@ func (c *LabelController) Create(ctx *app.CreateLabelContext) error {
 		lbl.BorderColor = *ctx.Payload.Data.Attributes.BorderColor
 	}
 	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.ManageLabels); err != nil {
+			return err
+		}
 		return appl.Labels().Create(ctx, lbl)
 	})
 	if err != nil {
@@ -187,6 +191,9 @@ func (c *LabelController) Update(ctx *app.UpdateLabelContext) error {
 		if err != nil {
 			return err
 		}
+		if err := authorizeSpace(ctx, appl, lbl.SpaceID, role.ManageLabels); err != nil {
+			return err
+		}
 		if lbl.Version != *ctx.Payload.Data.Attributes.Version {
 			return errors.NewVersionConflictError("version conflict")
 		}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/watcher"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// NotificationPreferencesController implements the notification_preferences resource.
+type NotificationPreferencesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewNotificationPreferencesController creates a notification_preferences controller.
+func NewNotificationPreferencesController(service *goa.Service, db application.DB) *NotificationPreferencesController {
+	return &NotificationPreferencesController{
+		Controller: service.NewController("NotificationPreferencesController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *NotificationPreferencesController) List(ctx *app.ListNotificationPreferencesContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	var prefs []watcher.Preference
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		prefs, err = appl.NotificationPreferences().List(ctx, *currentUserIdentityID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.NotificationPreferenceList{
+		Data: ConvertNotificationPreferences(*currentUserIdentityID, prefs),
+	})
+}
+
+// Update runs the update action.
+func (c *NotificationPreferencesController) Update(ctx *app.UpdateNotificationPreferencesContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data", nil).Expected("not nil"))
+	}
+	for _, p := range ctx.Payload.Data {
+		if p == nil || p.Attributes == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+		}
+		if !notification.IsKnownMessageType(p.ID) {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.id", p.ID).Expected("one of the known message types"))
+		}
+	}
+	var prefs []watcher.Preference
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		for _, p := range ctx.Payload.Data {
+			_, err := appl.NotificationPreferences().Save(ctx, watcher.Preference{
+				IdentityID:  *currentUserIdentityID,
+				MessageType: p.ID,
+				Enabled:     p.Attributes.Enabled,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		var err error
+		prefs, err = appl.NotificationPreferences().List(ctx, *currentUserIdentityID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.NotificationPreferenceList{
+		Data: ConvertNotificationPreferences(*currentUserIdentityID, prefs),
+	})
+}
+
+// ConvertNotificationPreferences converts the stored preferences of an
+// identity into the external REST representation. All known message types
+// are listed, the ones without a stored preference are reported as enabled.
+func ConvertNotificationPreferences(identityID uuid.UUID, prefs []watcher.Preference) []*app.NotificationPreference {
+	stored := make(map[string]watcher.Preference, len(prefs))
+	for _, p := range prefs {
+		stored[p.MessageType] = p
+	}
+	res := make([]*app.NotificationPreference, 0, len(notification.MessageTypes))
+	for _, messageType := range notification.MessageTypes {
+		p, ok := stored[messageType]
+		if !ok {
+			p = watcher.Preference{IdentityID: identityID, MessageType: messageType, Enabled: true}
+		}
+		np := &app.NotificationPreference{
+			Type: watcher.APIStringTypeNotificationPreferences,
+			ID:   messageType,
+			Attributes: &app.NotificationPreferenceAttributes{
+				Enabled: p.Enabled,
+			},
+		}
+		if ok {
+			np.Attributes.UpdatedAt = ptr.Time(p.UpdatedAt.UTC())
+		}
+		res = append(res, np)
+	}
+	return res
+}
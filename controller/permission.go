@@ -1,5 +1,19 @@
 package controller
 
+import (
+	"context"
+	"fmt"
+
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/fabric8-services/fabric8-wit/token/accesstoken"
+	uuid "github.com/satori/go.uuid"
+)
+
 // PermissionDefinition defines the Permissions available
 type PermissionDefinition struct {
 	CreateWorkItem string
@@ -22,3 +36,54 @@ var (
 		DeleteWorkItem: "delete.workitem",
 	}
 )
+
+// spaceRoleSources returns the sources of the roles in a space: spaces are
+// public so every identity is a viewer, the owner is an admin, then come the
+// roles stored locally and the collaborators given by the Keycloak
+// entitlement (contributors).
+func spaceRoleSources(appl application.Application) []role.Source {
+	return []role.Source{
+		role.Everyone(role.Viewer),
+		role.Owner(appl.Spaces()),
+		appl.SpaceRoles(),
+		authz.RoleSource(),
+	}
+}
+
+// currentSpaceRole returns the role of the current user in the given space,
+// restricted by the scope of the personal access token the request is
+// authenticated with, if any
+func currentSpaceRole(ctx context.Context, appl application.Application, spaceID uuid.UUID) (role.Role, error) {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return role.None, errors.NewUnauthorizedError(err.Error())
+	}
+	r, err := role.Resolve(ctx, spaceID, *currentUser, spaceRoleSources(appl)...)
+	if err != nil {
+		return role.None, err
+	}
+	if scope, ok := accesstoken.ContextScope(ctx); ok {
+		return scope.Restrict(r), nil
+	}
+	return r, nil
+}
+
+// authorizeSpace returns an unauthorized error if there is no current user and
+// a forbidden error if the role of the current user in the given space doesn't
+// grant the permission. All the permission checks in a space go through this
+// function.
+func authorizeSpace(ctx context.Context, appl application.Application, spaceID uuid.UUID, p role.Permission) error {
+	r, err := currentSpaceRole(ctx, appl, spaceID)
+	if err != nil {
+		return err
+	}
+	if !r.Can(p) {
+		log.Warn(ctx, map[string]interface{}{
+			"space_id":   spaceID,
+			"role":       r,
+			"permission": p,
+		}, "user is not allowed to %s", p)
+		return errors.NewForbiddenError(fmt.Sprintf("user is not allowed to %s in this space", p))
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/token/accesstoken"
+	"github.com/goadesign/goa"
+)
+
+const personalAccessTokensEndpoint = "/api/user/tokens"
+
+// PersonalAccessTokensController implements the personal_access_tokens resource.
+type PersonalAccessTokensController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewPersonalAccessTokensController creates a personal_access_tokens controller.
+func NewPersonalAccessTokensController(service *goa.Service, db application.DB) *PersonalAccessTokensController {
+	return &PersonalAccessTokensController{
+		Controller: service.NewController("PersonalAccessTokensController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *PersonalAccessTokensController) List(ctx *app.ListPersonalAccessTokensContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	var tokens []accesstoken.PersonalAccessToken
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		tokens, err = appl.PersonalAccessTokens().List(ctx, *currentUserIdentityID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.PersonalAccessTokenList{
+		Data: ConvertPersonalAccessTokens(ctx.Request, tokens),
+	})
+}
+
+// Create runs the create action.
+func (c *PersonalAccessTokensController) Create(ctx *app.CreatePersonalAccessTokensContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if _, ok := accesstoken.ContextScope(ctx); ok {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("personal access tokens can't be used to create other tokens"))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	t := accesstoken.PersonalAccessToken{
+		IdentityID: *currentUserIdentityID,
+		Name:       attrs.Name,
+		Scope:      accesstoken.Scope(attrs.Scope),
+		ExpiresAt:  attrs.ExpiresAt,
+	}
+	var token string
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		token, err = appl.PersonalAccessTokens().Create(ctx, &t)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.PersonalAccessTokenSingle{
+		Data: ConvertPersonalAccessToken(ctx.Request, t),
+	}
+	res.Data.Attributes.Token = &token
+	ctx.ResponseData.Header().Set("Location", *res.Data.Links.Self)
+	return ctx.Created(res)
+}
+
+// Revoke runs the revoke action.
+func (c *PersonalAccessTokensController) Revoke(ctx *app.RevokePersonalAccessTokensContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if _, ok := accesstoken.ContextScope(ctx); ok {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("personal access tokens can't be used to revoke tokens"))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		return appl.PersonalAccessTokens().Revoke(ctx, *currentUserIdentityID, ctx.TokenID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// ConvertPersonalAccessTokens converts from internal to external REST representation
+func ConvertPersonalAccessTokens(request *http.Request, tokens []accesstoken.PersonalAccessToken) []*app.PersonalAccessToken {
+	res := make([]*app.PersonalAccessToken, 0, len(tokens))
+	for _, t := range tokens {
+		res = append(res, ConvertPersonalAccessToken(request, t))
+	}
+	return res
+}
+
+// ConvertPersonalAccessToken converts from internal to external REST
+// representation. The token itself is never part of it.
+func ConvertPersonalAccessToken(request *http.Request, t accesstoken.PersonalAccessToken) *app.PersonalAccessToken {
+	selfURL := rest.AbsoluteURL(request, fmt.Sprintf("%s/%s", personalAccessTokensEndpoint, t.ID))
+	var lastUsedAt *time.Time
+	if t.LastUsedAt != nil {
+		lastUsedAt = ptr.Time(t.LastUsedAt.UTC())
+	}
+	id := t.ID
+	return &app.PersonalAccessToken{
+		Type: accesstoken.APIStringTypePersonalAccessTokens,
+		ID:   &id,
+		Attributes: &app.PersonalAccessTokenAttributes{
+			Name:       t.Name,
+			Scope:      string(t.Scope),
+			ExpiresAt:  t.ExpiresAt.UTC(),
+			CreatedAt:  ptr.Time(t.CreatedAt.UTC()),
+			LastUsedAt: lastUsedAt,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
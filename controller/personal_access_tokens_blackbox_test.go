@@ -0,0 +1,214 @@
+package controller_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	witmiddleware "github.com/fabric8-services/fabric8-wit/goamiddleware"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/login/tokencontext"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	testtoken "github.com/fabric8-services/fabric8-wit/test/token"
+	"github.com/fabric8-services/fabric8-wit/token/accesstoken"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestPersonalAccessTokensREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunPersonalAccessTokensREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestPersonalAccessTokensREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestPersonalAccessTokensREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func newPersonalAccessTokenPayload(name string, scope accesstoken.Scope, expiresAt time.Time) *app.CreatePersonalAccessTokensPayload {
+	return &app.CreatePersonalAccessTokensPayload{
+		Data: &app.PersonalAccessToken{
+			Type: accesstoken.APIStringTypePersonalAccessTokens,
+			Attributes: &app.PersonalAccessTokenAttributes{
+				Name:      name,
+				Scope:     string(scope),
+				ExpiresAt: expiresAt,
+			},
+		},
+	}
+}
+
+func (s *TestPersonalAccessTokensREST) TestCreateListRevoke() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2))
+	svc := testsupport.ServiceAsUser("PersonalAccessTokens-Service", *fxt.Identities[0])
+	ctrl := NewPersonalAccessTokensController(svc, s.db)
+	var tokenID uuid.UUID
+
+	s.T().Run("create", func(t *testing.T) {
+		res, created := test.CreatePersonalAccessTokensCreated(t, svc.Context, svc, ctrl, newPersonalAccessTokenPayload("bot", accesstoken.ScopeWriteWorkItems, time.Now().Add(24*time.Hour)))
+		require.NotNil(t, created.Data.ID)
+		tokenID = *created.Data.ID
+		require.NotNil(t, created.Data.Attributes.Token)
+		assert.True(t, accesstoken.IsPersonalAccessToken(*created.Data.Attributes.Token))
+		assert.Equal(t, "bot", created.Data.Attributes.Name)
+		assert.Equal(t, string(accesstoken.ScopeWriteWorkItems), created.Data.Attributes.Scope)
+		assert.NotEmpty(t, res.Header().Get("Location"))
+	})
+
+	s.T().Run("create too long lived", func(t *testing.T) {
+		test.CreatePersonalAccessTokensBadRequest(t, svc.Context, svc, ctrl, newPersonalAccessTokenPayload("bot", accesstoken.ScopeRead, time.Now().Add(2*accesstoken.MaxLifetime)))
+	})
+
+	s.T().Run("list", func(t *testing.T) {
+		_, list := test.ListPersonalAccessTokensOK(t, svc.Context, svc, ctrl)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, tokenID, *list.Data[0].ID)
+		// the token itself is only returned once
+		assert.Nil(t, list.Data[0].Attributes.Token)
+		// the tokens of the other users are not listed
+		otherSvc := testsupport.ServiceAsUser("PersonalAccessTokens-Service", *fxt.Identities[1])
+		_, list = test.ListPersonalAccessTokensOK(t, otherSvc.Context, otherSvc, NewPersonalAccessTokensController(otherSvc, s.db))
+		assert.Empty(t, list.Data)
+	})
+
+	s.T().Run("revoke", func(t *testing.T) {
+		otherSvc := testsupport.ServiceAsUser("PersonalAccessTokens-Service", *fxt.Identities[1])
+		test.RevokePersonalAccessTokensNotFound(t, otherSvc.Context, otherSvc, NewPersonalAccessTokensController(otherSvc, s.db), tokenID)
+		test.RevokePersonalAccessTokensNoContent(t, svc.Context, svc, ctrl, tokenID)
+		_, list := test.ListPersonalAccessTokensOK(t, svc.Context, svc, ctrl)
+		assert.Empty(t, list.Data)
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		svc := goa.New("PersonalAccessTokens-Service")
+		test.ListPersonalAccessTokensUnauthorized(t, svc.Context, svc, NewPersonalAccessTokensController(svc, s.db))
+	})
+}
+
+// keycloakAuthzService behaves like the Keycloak entitlement endpoint, which
+// rejects the tokens it didn't issue
+type keycloakAuthzService struct {
+	called bool
+}
+
+func (s *keycloakAuthzService) Authorize(ctx context.Context, endpoint string, spaceID string) (bool, error) {
+	s.called = true
+	if accesstoken.IsPersonalAccessToken(goajwt.ContextJWT(ctx).Raw) {
+		return false, errors.NewUnauthorizedError("invalid token")
+	}
+	return true, nil
+}
+
+func (s *keycloakAuthzService) Configuration() authz.AuthzConfiguration {
+	return nil
+}
+
+// serviceWithPersonalAccessToken creates a new service whose context is
+// authenticated with the given personal access token like the requests are
+func serviceWithPersonalAccessToken(t *testing.T, s *TestPersonalAccessTokensREST, serviceName, pat string, authzSrv authz.AuthzService) *goa.Service {
+	var ctx context.Context
+	h := func(handledCtx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		ctx = handledCtx
+		return nil
+	}
+	middleware := witmiddleware.TokenContext([]byte("secret"), nil, &goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"}, accesstoken.TokenResolver(s.DB))
+	req, err := http.NewRequest(http.MethodPatch, "/api/workitems", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+pat)
+	require.NoError(t, middleware(h)(context.Background(), httptest.NewRecorder(), req))
+	svc := goa.New(serviceName)
+	svc.Context = tokencontext.ContextWithTokenManager(ctx, testtoken.TokenManager)
+	svc.Context = tokencontext.ContextWithSpaceAuthzService(svc.Context, &authz.KeycloakAuthzServiceManager{Service: authzSrv})
+	return svc
+}
+
+func (s *TestPersonalAccessTokensREST) TestUseInSpace() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2), tf.WorkItems(1))
+	bot := fxt.Identities[1]
+	_, err := s.db.SpaceRoles().Assign(context.Background(), fxt.Spaces[0].ID, bot.ID, role.Contributor)
+	require.NoError(s.T(), err)
+	pat, err := accesstoken.NewRepository(s.DB).Create(context.Background(), &accesstoken.PersonalAccessToken{
+		IdentityID: bot.ID,
+		Name:       "bot",
+		Scope:      accesstoken.ScopeWriteWorkItems,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	require.NoError(s.T(), err)
+
+	s.T().Run("edit a work item with a stored role", func(t *testing.T) {
+		authzSrv := &keycloakAuthzService{}
+		svc := serviceWithPersonalAccessToken(t, s, "PersonalAccessTokens-Service", pat, authzSrv)
+		ctrl := NewWorkitemController(svc, s.db, s.Configuration)
+		spaceSelfURL := rest.AbsoluteURL(&http.Request{Host: "api.service.domain.org"}, app.SpaceHref(fxt.Spaces[0].ID.String()))
+		payload := app.UpdateWorkitemPayload{
+			Data: &app.WorkItem{
+				Type: APIStringTypeWorkItem,
+				ID:   &fxt.WorkItems[0].ID,
+				Attributes: map[string]interface{}{
+					workitem.SystemTitle:   "updated by a bot",
+					workitem.SystemVersion: fxt.WorkItems[0].Version,
+				},
+				Relationships: &app.WorkItemRelationships{
+					Space: app.NewSpaceRelation(fxt.Spaces[0].ID, spaceSelfURL),
+				},
+			},
+		}
+		_, updated := test.UpdateWorkitemOK(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &payload)
+		assert.Equal(t, "updated by a bot", updated.Data.Attributes[workitem.SystemTitle])
+		// the personal access token is never sent to Keycloak
+		assert.False(t, authzSrv.called)
+	})
+
+	s.T().Run("create a work item with a stored role", func(t *testing.T) {
+		authzSrv := &keycloakAuthzService{}
+		svc := serviceWithPersonalAccessToken(t, s, "PersonalAccessTokens-Service", pat, authzSrv)
+		ctrl := NewWorkitemsController(svc, s.db, s.Configuration)
+		payload := minimumRequiredCreateWithTypeAndSpace(fxt.WorkItemTypes[0].ID, fxt.Spaces[0].ID)
+		test.CreateWorkitemsCreated(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, &payload)
+		assert.False(t, authzSrv.called)
+	})
+
+	s.T().Run("read-only token", func(t *testing.T) {
+		readOnlyPAT, err := accesstoken.NewRepository(s.DB).Create(context.Background(), &accesstoken.PersonalAccessToken{
+			IdentityID: bot.ID,
+			Name:       "read-only bot",
+			Scope:      accesstoken.ScopeRead,
+			ExpiresAt:  time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		authzSrv := &keycloakAuthzService{}
+		svc := serviceWithPersonalAccessToken(t, s, "PersonalAccessTokens-Service", readOnlyPAT, authzSrv)
+		ctrl := NewWorkitemsController(svc, s.db, s.Configuration)
+		t.Run("create a work item", func(t *testing.T) {
+			payload := minimumRequiredCreateWithTypeAndSpace(fxt.WorkItemTypes[0].ID, fxt.Spaces[0].ID)
+			test.CreateWorkitemsForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, &payload)
+		})
+		t.Run("reorder work items", func(t *testing.T) {
+			payload := minimumRequiredReorderPayload()
+			test.ReorderWorkitemsForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, &payload)
+		})
+		assert.False(t, authzSrv.called)
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/query"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
 )
@@ -50,7 +51,7 @@ func (c *QueryController) Create(ctx *app.CreateQueryContext) error {
 	}
 	var q query.Query
 	err = application.Transactional(c.db, func(appl application.Application) error {
-		err = appl.Spaces().CheckExists(ctx, ctx.SpaceID)
+		err = authorizeSpace(ctx, appl, ctx.SpaceID, role.SaveQueries)
 		if err != nil {
 			return err
 		}
@@ -251,12 +251,20 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 	}
 	var result []workitem.WorkItem
 	var count int
+	var highlights map[uuid.UUID]search.Highlight
 	err := application.Transactional(c.db, func(appl application.Application) error {
 		if ctx.Q == nil || *ctx.Q == "" {
 			return goa.ErrBadRequest("empty search query not allowed")
 		}
 		var err error
 		result, count, err = appl.SearchItems().SearchFullText(ctx.Context, *ctx.Q, &offset, &limit, ctx.SpaceID)
+		if err == nil {
+			ids := make([]uuid.UUID, len(result))
+			for i, wi := range result {
+				ids[i] = wi.ID
+			}
+			highlights, err = appl.SearchItems().Highlights(ctx.Context, *ctx.Q, ids)
+		}
 		if err != nil {
 			cause := errs.Cause(err)
 			switch cause.(type) {
@@ -289,8 +297,11 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 	}
 	response := app.SearchWorkItemList{
 		Links: &app.PagingLinks{},
-		Meta:  &app.WorkItemListResponseMeta{TotalCount: count},
-		Data:  wis,
+		Meta: &app.WorkItemListResponseMeta{
+			TotalCount: count,
+			Highlights: ConvertSearchHighlights(highlights),
+		},
+		Data: wis,
 	}
 	setPagingLinks(response.Links, buildAbsoluteURL(ctx.Request), len(result), offset, limit, count, "q="+*ctx.Q)
 	return ctx.OK(&response)
@@ -392,6 +403,20 @@ func (c *SearchController) enrichWorkItemList(ctx *app.ShowSearchContext, ancest
 	return nil
 }
 
+// ConvertSearchHighlights converts the highlighted fragments of the work items
+// found by a full text search to their REST representation, by work item ID
+func ConvertSearchHighlights(highlights map[uuid.UUID]search.Highlight) map[string]*app.SearchHighlight {
+	res := make(map[string]*app.SearchHighlight, len(highlights))
+	for wiID, h := range highlights {
+		res[wiID.String()] = &app.SearchHighlight{
+			Title:       h.Title,
+			Description: h.Description,
+			Comment:     h.Comment,
+		}
+	}
+	return res
+}
+
 // Codebases runs the codebases search action.
 func (c *SearchController) Codebases(ctx *app.CodebasesSearchContext) error {
 	if ctx.URL == "" {
@@ -400,6 +425,7 @@ func (c *SearchController) Codebases(ctx *app.CodebasesSearchContext) error {
 	offset, limit := computePagingLimits(ctx.PageOffset, ctx.PageLimit)
 	var matchingCodebases []codebase.Codebase
 	var relatedSpaces []space.Space
+	var metadata []codebase.Metadata
 	var totalCount int
 	err := application.Transactional(c.db, func(appl application.Application) error {
 		var err error
@@ -419,6 +445,10 @@ func (c *SearchController) Codebases(ctx *app.CodebasesSearchContext) error {
 			spaceIDs[i] = c.SpaceID
 		}
 		relatedSpaces, err = appl.Spaces().LoadMany(ctx, spaceIDs)
+		if err != nil {
+			return err
+		}
+		metadata, err = appl.CodebaseMetadata().List(ctx, codebaseIDs(matchingCodebases)...)
 		return err
 	})
 	if err != nil {
@@ -433,7 +463,7 @@ func (c *SearchController) Codebases(ctx *app.CodebasesSearchContext) error {
 		}
 		includedData[i] = *appSpace
 	}
-	codebasesData := ConvertCodebases(ctx.Request, matchingCodebases)
+	codebasesData := ConvertCodebases(ctx.Request, matchingCodebases, codebaseIncludeMetadata(metadata))
 	response := app.CodebaseList{
 		Links:    &app.PagingLinks{},
 		Meta:     &app.CodebaseListMeta{TotalCount: totalCount},
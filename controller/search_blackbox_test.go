@@ -88,6 +88,8 @@ func (s *searchControllerTestSuite) TestSearchWorkItems() {
 	r := sr.Data[0]
 	assert.Equal(s.T(), q, r.Attributes[workitem.SystemTitle])
 	assert.Equal(s.T(), fxt.WorkItems[0].Number, r.Attributes[workitem.SystemNumber])
+	require.NotNil(s.T(), sr.Meta.Highlights[fxt.WorkItems[0].ID.String()])
+	assert.Equal(s.T(), "<b>"+q+"</b>", *sr.Meta.Highlights[fxt.WorkItems[0].ID.String()].Title)
 }
 
 func (s *searchControllerTestSuite) TestSearchPagination() {
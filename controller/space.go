@@ -20,6 +20,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/fabric8-services/fabric8-wit/spacetemplate"
 
 	"github.com/goadesign/goa"
@@ -98,6 +99,9 @@ func (c *SpaceController) Create(ctx *app.CreateSpaceContext) error {
 		if reqSpace.Attributes.Description != nil {
 			newSpace.Description = *reqSpace.Attributes.Description
 		}
+		if reqSpace.Attributes.SearchConfig != nil {
+			newSpace.SearchConfig = *reqSpace.Attributes.SearchConfig
+		}
 		// if given, use space template from relationship
 		if reqSpace.Relationships != nil && reqSpace.Relationships.SpaceTemplate != nil && reqSpace.Relationships.SpaceTemplate.Data != nil {
 			stID := reqSpace.Relationships.SpaceTemplate.Data.ID
@@ -180,10 +184,17 @@ func (c *SpaceController) rollBackSpaceCreation(ctx context.Context, spaceID uui
 
 // Delete runs the delete action.
 func (c *SpaceController) Delete(ctx *app.DeleteSpaceContext) error {
-	currentUser, err := login.ContextIdentity(ctx)
+	_, err := login.ContextIdentity(ctx)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
+	// check the permission before deleting the external resources of the space
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		return authorizeSpace(ctx, appl, ctx.SpaceID, role.ManageSpace)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
 
 	spaceDeletionErrorExternal := fmt.Errorf("could not delete space")
 	spaceID, err := goauuid.FromString(ctx.SpaceID.String())
@@ -230,18 +241,6 @@ func (c *SpaceController) Delete(ctx *app.DeleteSpaceContext) error {
 	}
 
 	err = application.Transactional(c.db, func(appl application.Application) error {
-		s, err := appl.Spaces().Load(ctx.Context, ctx.SpaceID)
-		if err != nil {
-			return err
-		}
-		if !uuid.Equal(*currentUser, s.OwnerID) {
-			log.Warn(ctx, map[string]interface{}{
-				"space_id":     ctx.SpaceID,
-				"space_owner":  s.OwnerID,
-				"current_user": *currentUser,
-			}, "user is not the space owner")
-			return errors.NewForbiddenError("user is not the space owner")
-		}
 		return appl.Spaces().Delete(ctx.Context, ctx.SpaceID)
 	})
 	if err != nil {
@@ -500,7 +499,7 @@ func (c *SpaceController) Show(ctx *app.ShowSpaceContext) error {
 
 // Update runs the update action.
 func (c *SpaceController) Update(ctx *app.UpdateSpaceContext) error {
-	currentUser, err := login.ContextIdentity(ctx)
+	_, err := login.ContextIdentity(ctx)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
@@ -515,10 +514,8 @@ func (c *SpaceController) Update(ctx *app.UpdateSpaceContext) error {
 		if err != nil {
 			return err
 		}
-
-		if !uuid.Equal(*currentUser, s.OwnerID) {
-			log.Error(ctx, map[string]interface{}{"currentUser": *currentUser, "owner": s.OwnerID}, "Current user is not owner")
-			return goa.NewErrorClass("forbidden", 403)("User is not the space owner")
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.ManageSpace); err != nil {
+			return err
 		}
 
 		s.Version = *ctx.Payload.Data.Attributes.Version
@@ -528,6 +525,9 @@ func (c *SpaceController) Update(ctx *app.UpdateSpaceContext) error {
 		if ctx.Payload.Data.Attributes.Description != nil {
 			s.Description = *ctx.Payload.Data.Attributes.Description
 		}
+		if ctx.Payload.Data.Attributes.SearchConfig != nil {
+			s.SearchConfig = *ctx.Payload.Data.Attributes.SearchConfig
+		}
 
 		s, err = appl.Spaces().Save(ctx.Context, s)
 		return err
@@ -608,6 +608,9 @@ func ConvertSpaceToModel(appSpace app.Space) space.Space {
 		if appSpace.Attributes.Description != nil {
 			modelSpace.Description = *appSpace.Attributes.Description
 		}
+		if appSpace.Attributes.SearchConfig != nil {
+			modelSpace.SearchConfig = *appSpace.Attributes.SearchConfig
+		}
 	}
 	if appSpace.Relationships != nil && appSpace.Relationships.OwnedBy != nil &&
 		appSpace.Relationships.OwnedBy.Data != nil && appSpace.Relationships.OwnedBy.Data.ID != nil {
@@ -673,11 +676,12 @@ func ConvertSpaceFromModel(request *http.Request, sp space.Space, options ...Spa
 		ID:   &sp.ID,
 		Type: APIStringTypeSpace,
 		Attributes: &app.SpaceAttributes{
-			Name:        &sp.Name,
-			Description: &sp.Description,
-			CreatedAt:   &sp.CreatedAt,
-			UpdatedAt:   &sp.UpdatedAt,
-			Version:     &sp.Version,
+			Name:         &sp.Name,
+			Description:  &sp.Description,
+			SearchConfig: &sp.SearchConfig,
+			CreatedAt:    &sp.CreatedAt,
+			UpdatedAt:    &sp.UpdatedAt,
+			Version:      &sp.Version,
 		},
 		Links: &app.GenericLinksForSpace{
 			Self:    &selfURL,
@@ -661,7 +661,7 @@ func (s *SpaceControllerTestSuite) TestUpdateSpace() {
 		_, errors := test.UpdateSpaceForbidden(t, svc2.Context, svc2, ctrl2, *created.Data.ID, u)
 		// then
 		assert.NotEmpty(t, errors.Errors)
-		assert.Contains(t, errors.Errors[0].Detail, "User is not the space owner")
+		assert.Contains(t, errors.Errors[0].Detail, "user is not allowed to manage the space")
 	})
 
 	s.T().Run("fail - unsecured", func(t *testing.T) {
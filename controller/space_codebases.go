@@ -8,6 +8,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/jsonapi"
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 
 	"github.com/goadesign/goa"
 )
@@ -25,7 +26,7 @@ func NewSpaceCodebasesController(service *goa.Service, db application.DB) *Space
 
 // Create runs the create action.
 func (c *SpaceCodebasesController) Create(ctx *app.CreateSpaceCodebasesContext) error {
-	identityID, err := login.ContextIdentity(ctx)
+	_, err := login.ContextIdentity(ctx)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
@@ -42,13 +43,9 @@ func (c *SpaceCodebasesController) Create(ctx *app.CreateSpaceCodebasesContext)
 	}
 	var cdb *codebase.Codebase
 	err = application.Transactional(c.db, func(appl application.Application) error {
-		sp, err := appl.Spaces().Load(ctx, ctx.SpaceID)
-		if err != nil {
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.ManageCodebases); err != nil {
 			return err
 		}
-		if *identityID != sp.OwnerID {
-			return errors.NewForbiddenError("user is not the space owner")
-		}
 		cdb = &codebase.Codebase{
 			SpaceID: ctx.SpaceID,
 			Type:    *reqIter.Attributes.Type,
@@ -71,6 +68,7 @@ func (c *SpaceCodebasesController) Create(ctx *app.CreateSpaceCodebasesContext)
 func (c *SpaceCodebasesController) List(ctx *app.ListSpaceCodebasesContext) error {
 	offset, limit := computePagingLimits(ctx.PageOffset, ctx.PageLimit)
 	var codebases []codebase.Codebase
+	var metadata []codebase.Metadata
 	var count int
 	err := application.Transactional(c.db, func(appl application.Application) error {
 		err := appl.Spaces().CheckExists(ctx, ctx.SpaceID)
@@ -79,13 +77,17 @@ func (c *SpaceCodebasesController) List(ctx *app.ListSpaceCodebasesContext) erro
 		}
 
 		codebases, count, err = appl.Codebases().List(ctx, ctx.SpaceID, &offset, &limit)
+		if err != nil {
+			return err
+		}
+		metadata, err = appl.CodebaseMetadata().List(ctx, codebaseIDs(codebases)...)
 		return err
 	})
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 	res := &app.CodebaseList{
-		Data:  ConvertCodebases(ctx.Request, codebases),
+		Data:  ConvertCodebases(ctx.Request, codebases, codebaseIncludeMetadata(metadata)),
 		Meta:  &app.CodebaseListMeta{TotalCount: count},
 		Links: &app.PagingLinks{},
 	}
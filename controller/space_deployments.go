@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/deployment"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SpaceDeploymentsController implements the space_deployments resource.
+type SpaceDeploymentsController struct {
+	*goa.Controller
+	db     application.DB
+	config SpaceDeploymentsControllerConfig
+}
+
+// SpaceDeploymentsControllerConfig the config interface for the SpaceDeploymentsController
+type SpaceDeploymentsControllerConfig interface {
+	GetCacheControlDeployments() string
+}
+
+// NewSpaceDeploymentsController creates a space_deployments controller.
+func NewSpaceDeploymentsController(service *goa.Service, db application.DB, config SpaceDeploymentsControllerConfig) *SpaceDeploymentsController {
+	return &SpaceDeploymentsController{
+		Controller: service.NewController("SpaceDeploymentsController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// List runs the list action.
+func (c *SpaceDeploymentsController) List(ctx *app.ListSpaceDeploymentsContext) error {
+	var environment string
+	if ctx.Environment != nil {
+		environment = *ctx.Environment
+	}
+	var deployments []deployment.Deployment
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.Spaces().CheckExists(ctx, ctx.SpaceID); err != nil {
+			return err
+		}
+		var err error
+		deployments, err = appl.Deployments().List(ctx, ctx.SpaceID, environment)
+		return errs.Wrap(err, "failed to list the deployments of the space")
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.ConditionalEntities(deployments, c.config.GetCacheControlDeployments, func() error {
+		return ctx.OK(&app.DeploymentList{
+			Data: ConvertDeployments(ctx.Request, deployments),
+		})
+	})
+}
+
+// Create runs the create action.
+func (c *SpaceDeploymentsController) Create(ctx *app.CreateSpaceDeploymentsContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	d := deployment.Deployment{
+		SpaceID:     ctx.SpaceID,
+		AppName:     attrs.AppName,
+		Environment: attrs.Environment,
+		Version:     attrs.Version,
+		BuildID:     attrs.BuildID,
+		CommitID:    attrs.CommitID,
+		IdentityID:  currentUserIdentityID,
+	}
+	if attrs.DeployedAt != nil {
+		d.DeployedAt = *attrs.DeployedAt
+	}
+	if rel := ctx.Payload.Data.Relationships; rel != nil && rel.Workitems != nil {
+		for _, wi := range rel.Workitems.Data {
+			if wi == nil || wi.ID == nil {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.workitems.data.id", nil).Expected("not nil"))
+			}
+			id, err := uuid.FromString(*wi.ID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.workitems.data.id", *wi.ID).Expected("UUID"))
+			}
+			d.WorkItemIDs = append(d.WorkItemIDs, id)
+		}
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.Spaces().CheckExists(ctx, ctx.SpaceID); err != nil {
+			return err
+		}
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.RecordDeployments); err != nil {
+			return err
+		}
+		return appl.Deployments().Create(ctx, &d)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.Created(&app.DeploymentSingle{
+		Data: ConvertDeployment(ctx.Request, d),
+	})
+}
+
+// ConvertDeployments converts from internal to external REST representation
+func ConvertDeployments(request *http.Request, deployments []deployment.Deployment) []*app.Deployment {
+	res := make([]*app.Deployment, 0, len(deployments))
+	for _, d := range deployments {
+		res = append(res, ConvertDeployment(request, d))
+	}
+	return res
+}
+
+// ConvertDeployment converts from internal to external REST representation
+func ConvertDeployment(request *http.Request, d deployment.Deployment) *app.Deployment {
+	spaceRelatedURL := rest.AbsoluteURL(request, app.SpaceHref(d.SpaceID))
+	selfURL := spaceRelatedURL + "/deployments"
+	workItems := make([]*app.GenericData, 0, len(d.WorkItemIDs))
+	for _, id := range d.WorkItemIDs {
+		workItemRelatedURL := rest.AbsoluteURL(request, app.WorkitemHref(id))
+		workItems = append(workItems, &app.GenericData{
+			Type: ptr.String(APIStringTypeWorkItem),
+			ID:   ptr.String(id.String()),
+			Links: &app.GenericLinks{
+				Related: &workItemRelatedURL,
+			},
+		})
+	}
+	res := &app.Deployment{
+		Type: deployment.APIStringTypeDeployments,
+		ID:   &d.ID,
+		Attributes: &app.DeploymentAttributes{
+			AppName:     d.AppName,
+			Environment: d.Environment,
+			Version:     d.Version,
+			BuildID:     d.BuildID,
+			CommitID:    d.CommitID,
+			DeployedAt:  ptr.Time(d.DeployedAt.UTC()),
+			CreatedAt:   ptr.Time(d.CreatedAt.UTC()),
+		},
+		Relationships: &app.DeploymentRelations{
+			Workitems: &app.RelationGenericList{
+				Data: workItems,
+			},
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(space.SpaceType),
+					ID:   ptr.String(d.SpaceID.String()),
+					Links: &app.GenericLinks{
+						Related: &spaceRelatedURL,
+					},
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+	if d.IdentityID != nil {
+		creatorRelatedURL := rest.AbsoluteURL(request, fmt.Sprintf("%s/%s", usersEndpoint, *d.IdentityID))
+		res.Relationships.Creator = &app.RelationGeneric{
+			Data: &app.GenericData{
+				Type: ptr.String(APIStringTypeUser),
+				ID:   ptr.String(d.IdentityID.String()),
+				Links: &app.GenericLinks{
+					Related: &creatorRelatedURL,
+				},
+			},
+		}
+	}
+	return res
+}
@@ -0,0 +1,143 @@
+package controller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceDeploymentsREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunSpaceDeploymentsREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestSpaceDeploymentsREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestSpaceDeploymentsREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func newDeploymentPayload(environment string, workItemIDs ...uuid.UUID) *app.CreateSpaceDeploymentsPayload {
+	workItems := make([]*app.GenericData, len(workItemIDs))
+	for i, id := range workItemIDs {
+		workItems[i] = &app.GenericData{
+			Type: ptr.String(APIStringTypeWorkItem),
+			ID:   ptr.String(id.String()),
+		}
+	}
+	return &app.CreateSpaceDeploymentsPayload{
+		Data: &app.Deployment{
+			Type: "deployments",
+			Attributes: &app.DeploymentAttributes{
+				AppName:     "myapp",
+				Environment: environment,
+				Version:     "1.0.2",
+				BuildID:     ptr.String("myapp-pipeline-42"),
+				CommitID:    ptr.String("6f5a2b2c1d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a"),
+			},
+			Relationships: &app.DeploymentRelations{
+				Workitems: &app.RelationGenericList{Data: workItems},
+			},
+		},
+	}
+}
+
+func (s *TestSpaceDeploymentsREST) TestCreate() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(2))
+		svc := testsupport.ServiceAsUser("Deployments-Service", *fxt.Identities[0])
+		ctrl := NewSpaceDeploymentsController(svc, s.db, s.Configuration)
+		deployedAt := time.Date(2018, 2, 1, 10, 0, 0, 0, time.UTC)
+		payload := newDeploymentPayload("production", fxt.WorkItems[0].ID, fxt.WorkItems[1].ID)
+		payload.Data.Attributes.DeployedAt = &deployedAt
+		_, d := test.CreateSpaceDeploymentsCreated(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, payload)
+		require.NotNil(t, d.Data.ID)
+		assert.Equal(t, "production", d.Data.Attributes.Environment)
+		assert.Equal(t, "myapp-pipeline-42", *d.Data.Attributes.BuildID)
+		assert.Equal(t, deployedAt, *d.Data.Attributes.DeployedAt)
+		require.Len(t, d.Data.Relationships.Workitems.Data, 2)
+		assert.Equal(t, fxt.Identities[0].ID.String(), *d.Data.Relationships.Creator.Data.ID)
+	})
+
+	s.T().Run("work item of another space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(2), tf.WorkItems(1))
+		svc := testsupport.ServiceAsUser("Deployments-Service", *fxt.Identities[0])
+		ctrl := NewSpaceDeploymentsController(svc, s.db, s.Configuration)
+		test.CreateSpaceDeploymentsBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[1].ID, newDeploymentPayload("production", fxt.WorkItems[0].ID))
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.WorkItems(1))
+		svc := testsupport.ServiceAsUser("Deployments-Service", *fxt.Identities[1])
+		ctrl := NewSpaceDeploymentsController(svc, s.db, s.Configuration)
+		test.CreateSpaceDeploymentsForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newDeploymentPayload("production", fxt.WorkItems[0].ID))
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		svc := goa.New("Deployments-Service")
+		ctrl := NewSpaceDeploymentsController(svc, s.db, s.Configuration)
+		test.CreateSpaceDeploymentsUnauthorized(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newDeploymentPayload("production"))
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		svc := testsupport.ServiceAsUser("Deployments-Service", *fxt.Identities[0])
+		ctrl := NewSpaceDeploymentsController(svc, s.db, s.Configuration)
+		test.CreateSpaceDeploymentsNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), newDeploymentPayload("production"))
+	})
+}
+
+func (s *TestSpaceDeploymentsREST) TestList() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(3))
+	svc := testsupport.ServiceAsUser("Deployments-Service", *fxt.Identities[0])
+	ctrl := NewSpaceDeploymentsController(svc, s.db, s.Configuration)
+	test.CreateSpaceDeploymentsCreated(s.T(), svc.Context, svc, ctrl, fxt.Spaces[0].ID, newDeploymentPayload("stage", fxt.WorkItems[0].ID, fxt.WorkItems[1].ID))
+	_, production := test.CreateSpaceDeploymentsCreated(s.T(), svc.Context, svc, ctrl, fxt.Spaces[0].ID, newDeploymentPayload("production", fxt.WorkItems[0].ID))
+
+	s.T().Run("all environments", func(t *testing.T) {
+		_, list := test.ListSpaceDeploymentsOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, nil, nil, nil)
+		require.Len(t, list.Data, 2)
+		// newest first
+		assert.Equal(t, *production.Data.ID, *list.Data[0].ID)
+	})
+
+	s.T().Run("live in production", func(t *testing.T) {
+		_, list := test.ListSpaceDeploymentsOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, ptr.String("production"), nil, nil)
+		require.Len(t, list.Data, 1)
+		require.Len(t, list.Data[0].Relationships.Workitems.Data, 1)
+		assert.Equal(t, fxt.WorkItems[0].ID.String(), *list.Data[0].Relationships.Workitems.Data[0].ID)
+	})
+
+	s.T().Run("work item", func(t *testing.T) {
+		wiCtrl := NewWorkItemDeploymentsController(svc, s.db, s.Configuration)
+		_, list := test.ListWorkItemDeploymentsOK(t, svc.Context, svc, wiCtrl, fxt.WorkItems[1].ID, nil, nil)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, "stage", list.Data[0].Attributes.Environment)
+		_, list = test.ListWorkItemDeploymentsOK(t, svc.Context, svc, wiCtrl, fxt.WorkItems[2].ID, nil, nil)
+		assert.Empty(t, list.Data)
+		test.ListWorkItemDeploymentsNotFound(t, svc.Context, svc, wiCtrl, uuid.NewV4(), nil, nil)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		test.ListSpaceDeploymentsNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), nil, nil, nil)
+	})
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/space/event"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+)
+
+// spaceEventsHeartbeat is how often a comment is sent on an idle stream to
+// keep the connection open, and how often the permission of the subscriber
+// is checked again
+const spaceEventsHeartbeat = 15 * time.Second
+
+// SpaceEventsController implements the space_events resource.
+type SpaceEventsController struct {
+	*goa.Controller
+	db     application.DB
+	broker *event.Broker
+}
+
+// NewSpaceEventsController creates a space_events controller.
+func NewSpaceEventsController(service *goa.Service, db application.DB, broker *event.Broker) *SpaceEventsController {
+	return &SpaceEventsController{
+		Controller: service.NewController("SpaceEventsController"),
+		db:         db,
+		broker:     broker,
+	}
+}
+
+// Stream runs the stream action.
+func (c *SpaceEventsController) Stream(ctx *app.StreamSpaceEventsContext) error {
+	var lastEventID int64
+	resume := ctx.LastEventID != nil && *ctx.LastEventID != ""
+	if resume {
+		var err error
+		lastEventID, err = strconv.ParseInt(*ctx.LastEventID, 10, 64)
+		if err != nil || lastEventID < 0 {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("Last-Event-ID", *ctx.LastEventID).Expected("event ID"))
+		}
+	}
+	if err := c.authorize(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	flusher, ok := ctx.ResponseData.ResponseWriter.(http.Flusher)
+	if !ok {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewInternalErrorFromString("streaming is not supported"))
+	}
+	// subscribe before replaying so that no event is missed in between
+	sub := c.broker.Subscribe(ctx.SpaceID)
+	defer sub.Close()
+	var replayed []event.Event
+	complete := true
+	if resume {
+		var err error
+		replayed, complete, err = c.broker.Replay(ctx, ctx.SpaceID, lastEventID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "failed to replay the events of space %s", ctx.SpaceID))
+		}
+	}
+
+	ctx.ResponseData.Header().Set("Content-Type", "text/event-stream")
+	ctx.ResponseData.Header().Set("Cache-Control", "no-cache")
+	ctx.ResponseData.Header().Set("X-Accel-Buffering", "no")
+	ctx.ResponseData.WriteHeader(http.StatusOK)
+	if !complete {
+		if _, err := fmt.Fprint(ctx.ResponseData, "event: reset\ndata: {}\n\n"); err != nil {
+			return nil
+		}
+	}
+	sent := event.NewSeen()
+	for _, e := range replayed {
+		if err := writeSpaceEvent(ctx.ResponseData, e); err != nil {
+			return nil
+		}
+		sent.Add(e.ID)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(spaceEventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-sub.Events():
+			if !ok {
+				// dropped for lagging behind, the client will resume its
+				// stream from the last event it received
+				return nil
+			}
+			if !sent.Add(e.ID) {
+				continue
+			}
+			if err := writeSpaceEvent(ctx.ResponseData, e); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if err := c.authorize(ctx); err != nil {
+				log.Info(ctx, map[string]interface{}{
+					"space_id": ctx.SpaceID,
+					"err":      err,
+				}, "closing the event stream of the space")
+				return nil
+			}
+			if _, err := fmt.Fprint(ctx.ResponseData, ": ping\n\n"); err != nil {
+				return nil
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// authorize checks that the current user is allowed to read the space
+func (c *SpaceEventsController) authorize(ctx *app.StreamSpaceEventsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.Spaces().CheckExists(ctx, ctx.SpaceID); err != nil {
+			return err
+		}
+		return authorizeSpace(ctx, appl, ctx.SpaceID, role.ReadSpace)
+	})
+}
+
+// spaceEventData is the data of the events sent to the clients
+type spaceEventData struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	SpaceID   string    `json:"space-id"`
+	Identity  *string   `json:"identity-id,omitempty"`
+	CreatedAt time.Time `json:"created-at"`
+}
+
+// writeSpaceEvent writes the given event in the Server-Sent Events format
+func writeSpaceEvent(w http.ResponseWriter, e event.Event) error {
+	data := spaceEventData{
+		ID:        e.TargetID,
+		Type:      e.Type,
+		SpaceID:   e.SpaceID.String(),
+		CreatedAt: e.CreatedAt.UTC(),
+	}
+	if e.IdentityID != nil {
+		data.Identity = ptr.String(e.IdentityID.String())
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, b)
+	return err
+}
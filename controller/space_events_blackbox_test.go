@@ -0,0 +1,107 @@
+package controller_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/event"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceEventsREST struct {
+	gormtestsupport.DBTestSuite
+	db     *gormapplication.GormDB
+	broker *event.Broker
+}
+
+func TestRunSpaceEventsREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestSpaceEventsREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestSpaceEventsREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+	s.broker = event.NewBroker(s.DB, 10*time.Millisecond, time.Hour)
+	require.NoError(s.T(), s.broker.Start())
+}
+
+func (s *TestSpaceEventsREST) TearDownTest() {
+	s.broker.Stop()
+	s.DBTestSuite.TearDownTest()
+}
+
+func (s *TestSpaceEventsREST) TestStream() {
+	s.T().Run("replay after the last event", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(2))
+		svc := testsupport.ServiceAsUser("SpaceEvents-Service", *fxt.Identities[0])
+		ctrl := NewSpaceEventsController(svc, s.db, s.broker)
+		s.broker.Send(svc.Context, notification.NewWorkItemCreated(fxt.Spaces[0].ID, fxt.WorkItems[0].ID.String()))
+		events, _, err := s.broker.Replay(context.Background(), fxt.Spaces[0].ID, 0)
+		require.NoError(t, err)
+		require.NotEmpty(t, events)
+		lastEventID := events[len(events)-1].ID
+		s.broker.Send(svc.Context, notification.NewWorkItemReordered(fxt.Spaces[0].ID, fxt.WorkItems[1].ID.String()))
+		ctx, cancel := context.WithTimeout(svc.Context, 500*time.Millisecond)
+		defer cancel()
+		// when
+		rw := test.StreamSpaceEventsOK(t, ctx, svc, ctrl, fxt.Spaces[0].ID, ptr.String(fmt.Sprintf("%d", lastEventID)))
+		// then
+		body := rw.(*httptest.ResponseRecorder).Body.String()
+		assert.Equal(t, "text/event-stream", rw.Header().Get("Content-Type"))
+		assert.Contains(t, body, "event: workitem.reorder\n")
+		assert.Contains(t, body, fmt.Sprintf(`"id":"%s"`, fxt.WorkItems[1].ID))
+		// the events within the in-flight window are replayed, but only once
+		assert.Equal(t, 1, strings.Count(body, fmt.Sprintf(`"id":"%s"`, fxt.WorkItems[0].ID)))
+	})
+
+	s.T().Run("live events", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		svc := testsupport.ServiceAsUser("SpaceEvents-Service", *fxt.Identities[0])
+		ctrl := NewSpaceEventsController(svc, s.db, s.broker)
+		ctx, cancel := context.WithTimeout(svc.Context, 500*time.Millisecond)
+		defer cancel()
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			s.broker.Send(svc.Context, notification.NewCommentDeleted(fxt.Spaces[0].ID, "c1"))
+		}()
+		// when
+		rw := test.StreamSpaceEventsOK(t, ctx, svc, ctrl, fxt.Spaces[0].ID, nil)
+		// then
+		body := rw.(*httptest.ResponseRecorder).Body.String()
+		assert.Contains(t, body, "event: comment.delete\n")
+		assert.Contains(t, body, `"id":"c1"`)
+	})
+
+	s.T().Run("invalid last event ID", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		svc := testsupport.ServiceAsUser("SpaceEvents-Service", *fxt.Identities[0])
+		ctrl := NewSpaceEventsController(svc, s.db, s.broker)
+		// when/then
+		test.StreamSpaceEventsBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, ptr.String("foo"))
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		svc := testsupport.ServiceAsUser("SpaceEvents-Service", *fxt.Identities[0])
+		ctrl := NewSpaceEventsController(svc, s.db, s.broker)
+		// when/then
+		test.StreamSpaceEventsNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), nil)
+	})
+}
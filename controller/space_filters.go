@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/search"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+)
+
+// SpaceFiltersController implements the space_filters resource.
+type SpaceFiltersController struct {
+	*goa.Controller
+	db     application.DB
+	config FilterControllerConfiguration
+}
+
+// NewSpaceFiltersController creates a space_filters controller.
+func NewSpaceFiltersController(service *goa.Service, db application.DB, config FilterControllerConfiguration) *SpaceFiltersController {
+	return &SpaceFiltersController{
+		Controller: service.NewController("SpaceFiltersController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// List runs the list action.
+func (c *SpaceFiltersController) List(ctx *app.ListSpaceFiltersContext) error {
+	var wits []workitem.WorkItemType
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		s, err := appl.Spaces().Load(ctx, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		wits, err = appl.WorkItemTypes().List(ctx, s.SpaceTemplateID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	result := &app.FilterList{
+		Data: ConvertSpaceFilters(wits),
+	}
+	// the filters only change with the work item types of the space template
+	witEtagData := make([]app.ConditionalRequestEntity, len(wits))
+	var lastModified time.Time
+	for i, wit := range wits {
+		witEtagData[i] = wit
+		if wit.GetLastModified().After(lastModified) {
+			lastModified = wit.GetLastModified()
+		}
+	}
+	ctx.ResponseData.Header().Set(app.ETag, app.GenerateEntitiesTag(witEtagData))
+	ctx.ResponseData.Header().Set(app.LastModified, app.ToHTTPTime(lastModified))
+	ctx.ResponseData.Header().Set(app.CacheControl, c.config.GetCacheControlFilters())
+	return ctx.OK(result)
+}
+
+// spaceFilterField is a work item field as defined across the work item types
+// of a space template
+type spaceFilterField struct {
+	name       string
+	definition workitem.FieldDefinition
+	// values are the allowed values of an enum field across all work item
+	// types
+	values []interface{}
+}
+
+// ConvertSpaceFilters returns the filters that apply to the work items of the
+// given types: the built-in filters whose field is defined by at least one of
+// the types followed by a filter for each custom field of the types (sorted by
+// name). Custom fields are searched for with the workitem.JSONFieldPrefix.
+func ConvertSpaceFilters(wits []workitem.WorkItemType) []*app.Filters {
+	fields := map[string]*spaceFilterField{}
+	names := []string{}
+	for _, wit := range wits {
+		for name, def := range wit.Fields {
+			f, ok := fields[name]
+			if !ok {
+				f = &spaceFilterField{name: name, definition: def}
+				fields[name] = f
+				names = append(names, name)
+			}
+			if enum, ok := def.Type.(workitem.EnumType); ok {
+				for _, v := range enum.Values {
+					if !containsValue(f.values, v) {
+						f.values = append(f.values, v)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+
+	res := []*app.Filters{}
+	for _, b := range builtinFilters {
+		filter := b.convert()
+		if b.field == "" {
+			// filter on a work item property, not on a field
+			filter.Attributes.Operators = []string{search.EQ, search.NE, search.IN}
+			res = append(res, filter)
+			continue
+		}
+		f, ok := fields[b.field]
+		if !ok {
+			continue
+		}
+		setFilterField(filter, *f)
+		res = append(res, filter)
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, "system.") {
+			continue
+		}
+		f := fields[name]
+		key := workitem.JSONFieldPrefix + name
+		operators := search.OperatorsForKind(f.definition.Type.GetKind())
+		label := f.definition.Label
+		if label == "" {
+			label = name
+		}
+		filter := &app.Filters{
+			Attributes: &app.FilterAttributes{
+				Title:       label,
+				Description: "Filter by " + strings.ToLower(label),
+				Type:        f.definition.Type.GetKind().String(),
+				Query:       fmt.Sprintf(`filter[expression]={"%s":{"%s":"{value}"}}`, key, operators[0]),
+				Key:         key,
+			},
+			Type: "filters",
+		}
+		setFilterField(filter, *f)
+		res = append(res, filter)
+	}
+	return res
+}
+
+// setFilterField sets the kind, allowed values and operators of the given
+// field on the filter
+func setFilterField(filter *app.Filters, f spaceFilterField) {
+	kind := f.definition.Type.GetKind()
+	filter.Attributes.Kind = ptr.String(kind.String())
+	filter.Attributes.Values = f.values
+	filter.Attributes.Operators = search.OperatorsForKind(kind)
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
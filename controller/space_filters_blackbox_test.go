@@ -0,0 +1,89 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/search"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceFiltersREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunSpaceFiltersREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestSpaceFiltersREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestSpaceFiltersREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func (s *TestSpaceFiltersREST) TestList() {
+	s.T().Run("ok", func(t *testing.T) {
+		enum := func(values ...interface{}) workitem.FieldDefinition {
+			return workitem.FieldDefinition{
+				Label: "State",
+				Type: workitem.EnumType{
+					SimpleType: workitem.SimpleType{Kind: workitem.KindEnum},
+					BaseType:   workitem.SimpleType{Kind: workitem.KindString},
+					Values:     values,
+				},
+			}
+		}
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(2, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItemTypes[idx].Fields = workitem.FieldDefinitions{
+				workitem.SystemTitle: {Label: "Title", Type: workitem.SimpleType{Kind: workitem.KindString}},
+			}
+			switch idx {
+			case 0:
+				fxt.WorkItemTypes[idx].Fields[workitem.SystemState] = enum("new", "closed")
+				fxt.WorkItemTypes[idx].Fields["effort"] = workitem.FieldDefinition{Label: "Effort", Type: workitem.SimpleType{Kind: workitem.KindFloat}}
+			case 1:
+				fxt.WorkItemTypes[idx].Fields[workitem.SystemState] = enum("new", "done")
+			}
+			return nil
+		}))
+		svc := goa.New("SpaceFilters-Service")
+		ctrl := NewSpaceFiltersController(svc, s.db, s.Configuration)
+		res, filters := test.ListSpaceFiltersOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID)
+		assertResponseHeaders(t, res)
+		keys := make([]string, len(filters.Data))
+		for i, f := range filters.Data {
+			keys[i] = f.Attributes.Key
+		}
+		require.Equal(t, []string{"workitemtype", "state", "title", "fields.effort"}, keys)
+		// state
+		assert.Equal(t, string(workitem.KindEnum), *filters.Data[1].Attributes.Kind)
+		assert.Equal(t, []interface{}{"new", "closed", "done"}, filters.Data[1].Attributes.Values)
+		assert.Equal(t, []string{search.EQ, search.NE, search.IN}, filters.Data[1].Attributes.Operators)
+		// title
+		assert.Equal(t, []string{search.EQ, search.NE, search.IN, search.SUBSTR}, filters.Data[2].Attributes.Operators)
+		// custom field
+		assert.Equal(t, "Effort", filters.Data[3].Attributes.Title)
+		assert.Equal(t, string(workitem.KindFloat), filters.Data[3].Attributes.Type)
+		assert.Equal(t, `filter[expression]={"fields.effort":{"$EQ":"{value}"}}`, filters.Data[3].Attributes.Query)
+		assert.Empty(t, filters.Data[3].Attributes.Values)
+		assert.Equal(t, []string{search.EQ, search.NE}, filters.Data[3].Attributes.Operators)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		svc := goa.New("SpaceFilters-Service")
+		ctrl := NewSpaceFiltersController(svc, s.db, s.Configuration)
+		test.ListSpaceFiltersNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+	})
+}
@@ -9,8 +9,8 @@ import (
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/fabric8-services/fabric8-wit/workitem"
-	uuid "github.com/satori/go.uuid"
 
 	"github.com/goadesign/goa"
 )
@@ -34,7 +34,7 @@ func NewSpaceIterationsController(service *goa.Service, db application.DB, confi
 
 // Create runs the create action.
 func (c *SpaceIterationsController) Create(ctx *app.CreateSpaceIterationsContext) error {
-	currentUser, err := login.ContextIdentity(ctx)
+	_, err := login.ContextIdentity(ctx)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
@@ -49,17 +49,8 @@ func (c *SpaceIterationsController) Create(ctx *app.CreateSpaceIterationsContext
 
 	var responseData *app.Iteration
 	err = application.Transactional(c.db, func(appl application.Application) error {
-		s, err := appl.Spaces().Load(ctx, ctx.SpaceID)
-		if err != nil {
-			return goa.ErrNotFound(err.Error())
-		}
-		if !uuid.Equal(*currentUser, s.OwnerID) {
-			log.Warn(ctx, map[string]interface{}{
-				"space_id":     ctx.SpaceID,
-				"space_owner":  s.OwnerID,
-				"current_user": *currentUser,
-			}, "user is not the space owner")
-			return errors.NewForbiddenError("user is not the space owner")
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.ManageIterations); err != nil {
+			return err
 		}
 		// Put iteration under root iteration
 		rootIteration, err := appl.Iterations().Root(ctx, ctx.SpaceID)
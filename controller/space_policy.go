@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	uuid "github.com/satori/go.uuid"
+)
+
+// showSpacePolicy loads a policy of the given space with the load function,
+// once the space is known to exist. The policies of a space are public.
+func showSpacePolicy(ctx context.Context, db application.DB, spaceID uuid.UUID, load func(appl application.Application) error) error {
+	return application.Transactional(db, func(appl application.Application) error {
+		if err := appl.Spaces().CheckExists(ctx, spaceID); err != nil {
+			return err
+		}
+		return load(appl)
+	})
+}
+
+// updateSpacePolicy saves a policy of the given space with the save
+// function, once the current user is known to have the given permission in
+// the space
+func updateSpacePolicy(ctx context.Context, db application.DB, spaceID uuid.UUID, p role.Permission, save func(appl application.Application) error) error {
+	return application.Transactional(db, func(appl application.Application) error {
+		if err := authorizeSpace(ctx, appl, spaceID, p); err != nil {
+			return err
+		}
+		return save(appl)
+	})
+}
+
+// convertSpacePolicyLinks returns the relationship to the space and the links
+// of the policy of the space served at the given path below the space
+func convertSpacePolicyLinks(request *http.Request, spaceID uuid.UUID, path string) (*app.RelationGeneric, *app.GenericLinks) {
+	spaceRelatedURL := rest.AbsoluteURL(request, app.SpaceHref(spaceID))
+	selfURL := spaceRelatedURL + "/" + path
+	space := &app.RelationGeneric{
+		Data: &app.GenericData{
+			Type: ptr.String(APIStringTypeSpace),
+			ID:   ptr.String(spaceID.String()),
+			Links: &app.GenericLinks{
+				Related: &spaceRelatedURL,
+			},
+		},
+	}
+	return space, &app.GenericLinks{Self: &selfURL}
+}
+
+// convertSpacePolicyUpdatedAt returns when the policy was last changed, nil
+// if it was never saved and the defaults of the space apply
+func convertSpacePolicyUpdatedAt(updatedAt time.Time) *time.Time {
+	if updatedAt.IsZero() {
+		return nil
+	}
+	return ptr.Time(updatedAt.UTC())
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/retention"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/goadesign/goa"
+)
+
+// APIStringTypeRetentionPolicy contains the JSON API type for retention
+// policies
+const APIStringTypeRetentionPolicy = "retentionpolicies"
+
+// SpaceRetentionPolicyController implements the space_retention_policy resource.
+type SpaceRetentionPolicyController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceRetentionPolicyController creates a space_retention_policy controller.
+func NewSpaceRetentionPolicyController(service *goa.Service, db application.DB) *SpaceRetentionPolicyController {
+	return &SpaceRetentionPolicyController{
+		Controller: service.NewController("SpaceRetentionPolicyController"),
+		db:         db,
+	}
+}
+
+// Show runs the show action.
+func (c *SpaceRetentionPolicyController) Show(ctx *app.ShowSpaceRetentionPolicyContext) error {
+	var p *retention.Policy
+	err := showSpacePolicy(ctx, c.db, ctx.SpaceID, func(appl application.Application) error {
+		var err error
+		p, err = appl.RetentionPolicies().Load(ctx, ctx.SpaceID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.RetentionPolicySingle{
+		Data: ConvertRetentionPolicy(ctx.Request, *p),
+	})
+}
+
+// Update runs the update action.
+func (c *SpaceRetentionPolicyController) Update(ctx *app.UpdateSpaceRetentionPolicyContext) error {
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	p := retention.Policy{
+		SpaceID:                     ctx.SpaceID,
+		ArchiveClosedAfterMonths:    ctx.Payload.Data.Attributes.ArchiveClosedAfterMonths,
+		CompactRevisionsAfterMonths: ctx.Payload.Data.Attributes.CompactRevisionsAfterMonths,
+	}
+	err := updateSpacePolicy(ctx, c.db, ctx.SpaceID, role.ManageSpace, func(appl application.Application) error {
+		return appl.RetentionPolicies().Save(ctx, &p)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.RetentionPolicySingle{
+		Data: ConvertRetentionPolicy(ctx.Request, p),
+	})
+}
+
+// ConvertRetentionPolicy converts from internal to external REST representation
+func ConvertRetentionPolicy(request *http.Request, p retention.Policy) *app.RetentionPolicy {
+	space, links := convertSpacePolicyLinks(request, p.SpaceID, "retention-policy")
+	return &app.RetentionPolicy{
+		Type: APIStringTypeRetentionPolicy,
+		ID:   &p.SpaceID,
+		Attributes: &app.RetentionPolicyAttributes{
+			ArchiveClosedAfterMonths:    p.ArchiveClosedAfterMonths,
+			CompactRevisionsAfterMonths: p.CompactRevisionsAfterMonths,
+			UpdatedAt:                   convertSpacePolicyUpdatedAt(p.UpdatedAt),
+		},
+		Relationships: &app.RetentionPolicyRelations{
+			Space: space,
+		},
+		Links: links,
+	}
+}
@@ -0,0 +1,118 @@
+package controller_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceRetentionPolicyREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunSpaceRetentionPolicyREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestSpaceRetentionPolicyREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestSpaceRetentionPolicyREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func newRetentionPolicyPayload(archiveAfter, compactAfter int) *app.UpdateSpaceRetentionPolicyPayload {
+	return &app.UpdateSpaceRetentionPolicyPayload{
+		Data: &app.RetentionPolicy{
+			Type: APIStringTypeRetentionPolicy,
+			Attributes: &app.RetentionPolicyAttributes{
+				ArchiveClosedAfterMonths:    archiveAfter,
+				CompactRevisionsAfterMonths: compactAfter,
+			},
+		},
+	}
+}
+
+func (s *TestSpaceRetentionPolicyREST) TestShow() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1))
+	svc := goa.New("SpaceRetentionPolicy-Service")
+	ctrl := NewSpaceRetentionPolicyController(svc, s.db)
+
+	s.T().Run("keeps everything by default", func(t *testing.T) {
+		_, res := test.ShowSpaceRetentionPolicyOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID)
+		require.NotNil(t, res.Data)
+		assert.Equal(t, 0, res.Data.Attributes.ArchiveClosedAfterMonths)
+		assert.Equal(t, 0, res.Data.Attributes.CompactRevisionsAfterMonths)
+		assert.Nil(t, res.Data.Attributes.UpdatedAt)
+		assert.Equal(t, fmt.Sprintf("http:///api/spaces/%s/retention-policy", fxt.Spaces[0].ID), *res.Data.Links.Self)
+	})
+
+	s.T().Run("saved policy shown to everyone", func(t *testing.T) {
+		owner := testsupport.ServiceAsUser("SpaceRetentionPolicy-Service", *fxt.Identities[0])
+		test.UpdateSpaceRetentionPolicyOK(t, owner.Context, owner, NewSpaceRetentionPolicyController(owner, s.db), fxt.Spaces[0].ID, newRetentionPolicyPayload(24, 0))
+		// when
+		_, res := test.ShowSpaceRetentionPolicyOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID)
+		// then archiving without compacting is allowed
+		assert.Equal(t, 24, res.Data.Attributes.ArchiveClosedAfterMonths)
+		assert.Equal(t, 0, res.Data.Attributes.CompactRevisionsAfterMonths)
+		assert.NotNil(t, res.Data.Attributes.UpdatedAt)
+	})
+}
+
+func (s *TestSpaceRetentionPolicyREST) TestUpdate() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(3), tf.Spaces(1))
+	_, err := s.db.SpaceRoles().Assign(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID, role.Maintainer)
+	require.NoError(s.T(), err)
+	_, err = s.db.SpaceRoles().Assign(context.Background(), fxt.Spaces[0].ID, fxt.Identities[2].ID, role.Admin)
+	require.NoError(s.T(), err)
+
+	s.T().Run("admin of the space", func(t *testing.T) {
+		svc := testsupport.ServiceAsUser("SpaceRetentionPolicy-Service", *fxt.Identities[2])
+		ctrl := NewSpaceRetentionPolicyController(svc, s.db)
+		// when
+		_, res := test.UpdateSpaceRetentionPolicyOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newRetentionPolicyPayload(12, 6))
+		// then the enforcer uses the saved policy
+		require.NotNil(t, res.Data)
+		assert.Equal(t, 12, res.Data.Attributes.ArchiveClosedAfterMonths)
+		assert.Equal(t, 6, res.Data.Attributes.CompactRevisionsAfterMonths)
+		policies, err := s.db.RetentionPolicies().List(context.Background())
+		require.NoError(t, err)
+		var saved bool
+		for _, p := range policies {
+			if p.SpaceID == fxt.Spaces[0].ID {
+				saved = true
+				assert.Equal(t, 12, p.ArchiveClosedAfterMonths)
+				assert.Equal(t, 6, p.CompactRevisionsAfterMonths)
+			}
+		}
+		assert.True(t, saved)
+	})
+
+	s.T().Run("maintainer of the space", func(t *testing.T) {
+		// the maintainers manage the workspace policy but only the admins
+		// decide what is archived and compacted
+		svc := testsupport.ServiceAsUser("SpaceRetentionPolicy-Service", *fxt.Identities[1])
+		ctrl := NewSpaceRetentionPolicyController(svc, s.db)
+		test.UpdateSpaceRetentionPolicyForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newRetentionPolicyPayload(1, 1))
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		svc := goa.New("SpaceRetentionPolicy-Service")
+		ctrl := NewSpaceRetentionPolicyController(svc, s.db)
+		test.UpdateSpaceRetentionPolicyUnauthorized(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newRetentionPolicyPayload(0, 0))
+	})
+}
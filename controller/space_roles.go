@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+)
+
+// SpaceRolesController implements the space_roles resource.
+type SpaceRolesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceRolesController creates a space_roles controller.
+func NewSpaceRolesController(service *goa.Service, db application.DB) *SpaceRolesController {
+	return &SpaceRolesController{
+		Controller: service.NewController("SpaceRolesController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *SpaceRolesController) List(ctx *app.ListSpaceRolesContext) error {
+	var assignments []role.Assignment
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.Spaces().CheckExists(ctx, ctx.SpaceID); err != nil {
+			return err
+		}
+		var err error
+		assignments, err = appl.SpaceRoles().List(ctx, ctx.SpaceID)
+		return errs.Wrap(err, "failed to list the space roles")
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.SpaceRoleList{
+		Data: ConvertSpaceRoles(ctx.Request, assignments),
+	})
+}
+
+// Assign runs the assign action.
+func (c *SpaceRolesController) Assign(ctx *app.AssignSpaceRolesContext) error {
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.role", nil).Expected("not nil"))
+	}
+	var a *role.Assignment
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.ManageRoles); err != nil {
+			return err
+		}
+		var err error
+		a, err = appl.SpaceRoles().Assign(ctx, ctx.SpaceID, ctx.IdentityID, role.Role(ctx.Payload.Data.Attributes.Role))
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.SpaceRoleSingle{
+		Data: ConvertSpaceRole(ctx.Request, *a),
+	})
+}
+
+// Revoke runs the revoke action.
+func (c *SpaceRolesController) Revoke(ctx *app.RevokeSpaceRolesContext) error {
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.ManageRoles); err != nil {
+			return err
+		}
+		return appl.SpaceRoles().Revoke(ctx, ctx.SpaceID, ctx.IdentityID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// ConvertSpaceRoles converts from internal to external REST representation
+func ConvertSpaceRoles(request *http.Request, assignments []role.Assignment) []*app.SpaceRole {
+	res := make([]*app.SpaceRole, 0, len(assignments))
+	for _, a := range assignments {
+		res = append(res, ConvertSpaceRole(request, a))
+	}
+	return res
+}
+
+// ConvertSpaceRole converts from internal to external REST representation
+func ConvertSpaceRole(request *http.Request, a role.Assignment) *app.SpaceRole {
+	identityRelatedURL := rest.AbsoluteURL(request, fmt.Sprintf("%s/%s", usersEndpoint, a.IdentityID))
+	spaceRelatedURL := rest.AbsoluteURL(request, app.SpaceHref(a.SpaceID))
+	selfURL := fmt.Sprintf("%s/roles/%s", spaceRelatedURL, a.IdentityID)
+	return &app.SpaceRole{
+		Type: role.APIStringTypeSpaceRoles,
+		ID:   &a.IdentityID,
+		Attributes: &app.SpaceRoleAttributes{
+			Role:      string(a.Role),
+			CreatedAt: ptr.Time(a.CreatedAt.UTC()),
+			UpdatedAt: ptr.Time(a.UpdatedAt.UTC()),
+		},
+		Relationships: &app.SpaceRoleRelations{
+			Identity: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeUser),
+					ID:   ptr.String(a.IdentityID.String()),
+					Links: &app.GenericLinks{
+						Related: &identityRelatedURL,
+					},
+				},
+			},
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeSpace),
+					ID:   ptr.String(a.SpaceID.String()),
+					Links: &app.GenericLinks{
+						Related: &spaceRelatedURL,
+					},
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceRolesREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunSpaceRolesREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestSpaceRolesREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestSpaceRolesREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func newSpaceRolePayload(r role.Role) *app.AssignSpaceRolesPayload {
+	return &app.AssignSpaceRolesPayload{
+		Data: &app.SpaceRole{
+			Type: role.APIStringTypeSpaceRoles,
+			Attributes: &app.SpaceRoleAttributes{
+				Role: string(r),
+			},
+		},
+	}
+}
+
+func (s *TestSpaceRolesREST) TestAssign() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.Areas(1))
+		svc := testsupport.ServiceAsUser("SpaceRoles-Service", *fxt.Identities[0])
+		ctrl := NewSpaceRolesController(svc, s.db)
+		// when
+		_, res := test.AssignSpaceRolesOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, fxt.Identities[1].ID, newSpaceRolePayload(role.Maintainer))
+		// then
+		require.NotNil(t, res.Data)
+		assert.Equal(t, fxt.Identities[1].ID, *res.Data.ID)
+		assert.Equal(t, string(role.Maintainer), res.Data.Attributes.Role)
+		_, list := test.ListSpaceRolesOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, fxt.Identities[1].ID, *list.Data[0].ID)
+
+		t.Run("maintainer can create an area", func(t *testing.T) {
+			areaSvc := testsupport.ServiceAsUser("Area-Service", *fxt.Identities[1])
+			areaCtrl := NewAreaController(areaSvc, s.db, s.Configuration)
+			test.CreateChildAreaCreated(t, areaSvc.Context, areaSvc, areaCtrl, fxt.Areas[0].ID.String(), newCreateChildAreaPayload(uuid.NewV4().String()))
+		})
+
+		t.Run("maintainer cannot manage the roles", func(t *testing.T) {
+			svc := testsupport.ServiceAsUser("SpaceRoles-Service", *fxt.Identities[1])
+			ctrl := NewSpaceRolesController(svc, s.db)
+			test.AssignSpaceRolesForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, fxt.Identities[1].ID, newSpaceRolePayload(role.Admin))
+			test.RevokeSpaceRolesForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, fxt.Identities[1].ID)
+		})
+
+		t.Run("revoke", func(t *testing.T) {
+			test.RevokeSpaceRolesNoContent(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, fxt.Identities[1].ID)
+			_, list := test.ListSpaceRolesOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID)
+			assert.Empty(t, list.Data)
+			areaSvc := testsupport.ServiceAsUser("Area-Service", *fxt.Identities[1])
+			areaCtrl := NewAreaController(areaSvc, s.db, s.Configuration)
+			test.CreateChildAreaForbidden(t, areaSvc.Context, areaSvc, areaCtrl, fxt.Areas[0].ID.String(), newCreateChildAreaPayload(uuid.NewV4().String()))
+		})
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.Spaces(1))
+		svc := goa.New("SpaceRoles-Service")
+		ctrl := NewSpaceRolesController(svc, s.db)
+		test.AssignSpaceRolesUnauthorized(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, fxt.Identities[1].ID, newSpaceRolePayload(role.Viewer))
+	})
+
+	s.T().Run("unknown identity", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		svc := testsupport.ServiceAsUser("SpaceRoles-Service", *fxt.Identities[0])
+		ctrl := NewSpaceRolesController(svc, s.db)
+		test.AssignSpaceRolesNotFound(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, uuid.NewV4(), newSpaceRolePayload(role.Viewer))
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		svc := testsupport.ServiceAsUser("SpaceRoles-Service", *fxt.Identities[0])
+		ctrl := NewSpaceRolesController(svc, s.db)
+		test.AssignSpaceRolesNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), fxt.Identities[0].ID, newSpaceRolePayload(role.Viewer))
+	})
+}
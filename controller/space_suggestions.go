@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/search"
+	"github.com/goadesign/goa"
+)
+
+// SpaceSuggestionsController implements the space_suggestions resource.
+type SpaceSuggestionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceSuggestionsController creates a space_suggestions controller.
+func NewSpaceSuggestionsController(service *goa.Service, db application.DB) *SpaceSuggestionsController {
+	return &SpaceSuggestionsController{
+		Controller: service.NewController("SpaceSuggestionsController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *SpaceSuggestionsController) List(ctx *app.ListSpaceSuggestionsContext) error {
+	var suggestions []search.Suggestion
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		err := appl.Spaces().CheckExists(ctx, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		suggestions, err = appl.SearchItems().Suggest(ctx, ctx.SpaceID, ctx.Q, ctx.Limit)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.SuggestionList{
+		Data: ConvertSuggestions(suggestions),
+	})
+}
+
+// ConvertSuggestions converts the given suggestions into their JSON API
+// representation.
+func ConvertSuggestions(suggestions []search.Suggestion) []*app.Suggestion {
+	res := make([]*app.Suggestion, len(suggestions))
+	for i, s := range suggestions {
+		res[i] = &app.Suggestion{
+			Type: s.Type,
+			ID:   s.ID,
+			Attributes: &app.SuggestionAttributes{
+				Name:     s.Name,
+				Number:   s.Number,
+				FullName: s.FullName,
+				ImageURL: s.ImageURL,
+			},
+		}
+	}
+	return res
+}
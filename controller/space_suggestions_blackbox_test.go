@@ -0,0 +1,65 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/search"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceSuggestionsREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunSpaceSuggestionsREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestSpaceSuggestionsREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestSpaceSuggestionsREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func (s *TestSpaceSuggestionsREST) TestList() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.WorkItems(2, tf.SetWorkItemTitles("giraffe neck", "elephant trunk")),
+			tf.Labels(1, tf.SetLabelNames("giraffes")),
+		)
+		svc := goa.New("SpaceSuggestions-Service")
+		ctrl := NewSpaceSuggestionsController(svc, s.db)
+		_, res := test.ListSpaceSuggestionsOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, 5, "gira")
+		types := map[string]int{}
+		for _, suggestion := range res.Data {
+			types[suggestion.Type]++
+		}
+		assert.Equal(t, 1, types[search.SuggestionTypeWorkItem])
+		assert.Equal(t, 1, types[search.SuggestionTypeLabel])
+		for _, suggestion := range res.Data {
+			if suggestion.Type == search.SuggestionTypeWorkItem {
+				assert.Equal(t, fxt.WorkItemByTitle("giraffe neck").ID, suggestion.ID)
+				assert.Equal(t, "giraffe neck", suggestion.Attributes.Name)
+				require.NotNil(t, suggestion.Attributes.Number)
+				assert.Equal(t, fxt.WorkItemByTitle("giraffe neck").Number, *suggestion.Attributes.Number)
+			}
+		}
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		svc := goa.New("SpaceSuggestions-Service")
+		ctrl := NewSpaceSuggestionsController(svc, s.db)
+		test.ListSpaceSuggestionsNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), 5, "gira")
+	})
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/codebase/workspace"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/goadesign/goa"
+)
+
+// APIStringTypeWorkspacePolicy contains the JSON API type for workspace
+// policies
+const APIStringTypeWorkspacePolicy = "workspacepolicies"
+
+// SpaceWorkspacePolicyController implements the space_workspace_policy resource.
+type SpaceWorkspacePolicyController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceWorkspacePolicyController creates a space_workspace_policy controller.
+func NewSpaceWorkspacePolicyController(service *goa.Service, db application.DB) *SpaceWorkspacePolicyController {
+	return &SpaceWorkspacePolicyController{
+		Controller: service.NewController("SpaceWorkspacePolicyController"),
+		db:         db,
+	}
+}
+
+// Show runs the show action.
+func (c *SpaceWorkspacePolicyController) Show(ctx *app.ShowSpaceWorkspacePolicyContext) error {
+	var p *workspace.Policy
+	err := showSpacePolicy(ctx, c.db, ctx.SpaceID, func(appl application.Application) error {
+		var err error
+		p, err = appl.WorkspacePolicies().Load(ctx, ctx.SpaceID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.WorkspacePolicySingle{
+		Data: ConvertWorkspacePolicy(ctx.Request, *p),
+	})
+}
+
+// Update runs the update action.
+func (c *SpaceWorkspacePolicyController) Update(ctx *app.UpdateSpaceWorkspacePolicyContext) error {
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	p := workspace.Policy{
+		SpaceID:              ctx.SpaceID,
+		MaxRunningWorkspaces: ctx.Payload.Data.Attributes.MaxRunningWorkspaces,
+		MaxRuntimeMinutes:    ctx.Payload.Data.Attributes.MaxRuntimeMinutes,
+	}
+	err := updateSpacePolicy(ctx, c.db, ctx.SpaceID, role.ManageCodebases, func(appl application.Application) error {
+		return appl.WorkspacePolicies().Save(ctx, &p)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.WorkspacePolicySingle{
+		Data: ConvertWorkspacePolicy(ctx.Request, p),
+	})
+}
+
+// ConvertWorkspacePolicy converts from internal to external REST representation
+func ConvertWorkspacePolicy(request *http.Request, p workspace.Policy) *app.WorkspacePolicy {
+	space, links := convertSpacePolicyLinks(request, p.SpaceID, "workspace-policy")
+	return &app.WorkspacePolicy{
+		Type: APIStringTypeWorkspacePolicy,
+		ID:   &p.SpaceID,
+		Attributes: &app.WorkspacePolicyAttributes{
+			MaxRunningWorkspaces: p.MaxRunningWorkspaces,
+			MaxRuntimeMinutes:    p.MaxRuntimeMinutes,
+			UpdatedAt:            convertSpacePolicyUpdatedAt(p.UpdatedAt),
+		},
+		Relationships: &app.WorkspacePolicyRelations{
+			Space: space,
+		},
+		Links: links,
+	}
+}
@@ -0,0 +1,95 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceWorkspacePolicyREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunSpaceWorkspacePolicyREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestSpaceWorkspacePolicyREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestSpaceWorkspacePolicyREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func newWorkspacePolicyPayload(maxRunning, maxRuntime int) *app.UpdateSpaceWorkspacePolicyPayload {
+	return &app.UpdateSpaceWorkspacePolicyPayload{
+		Data: &app.WorkspacePolicy{
+			Type: APIStringTypeWorkspacePolicy,
+			Attributes: &app.WorkspacePolicyAttributes{
+				MaxRunningWorkspaces: maxRunning,
+				MaxRuntimeMinutes:    maxRuntime,
+			},
+		},
+	}
+}
+
+func (s *TestSpaceWorkspacePolicyREST) TestShow() {
+	s.T().Run("unlimited by default", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		svc := goa.New("SpaceWorkspacePolicy-Service")
+		ctrl := NewSpaceWorkspacePolicyController(svc, s.db)
+		_, res := test.ShowSpaceWorkspacePolicyOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID)
+		require.NotNil(t, res.Data)
+		assert.Equal(t, fxt.Spaces[0].ID, *res.Data.ID)
+		assert.Equal(t, 0, res.Data.Attributes.MaxRunningWorkspaces)
+		assert.Equal(t, 0, res.Data.Attributes.MaxRuntimeMinutes)
+		assert.Nil(t, res.Data.Attributes.UpdatedAt)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		svc := goa.New("SpaceWorkspacePolicy-Service")
+		ctrl := NewSpaceWorkspacePolicyController(svc, s.db)
+		test.ShowSpaceWorkspacePolicyNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+	})
+}
+
+func (s *TestSpaceWorkspacePolicyREST) TestUpdate() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2), tf.Spaces(1))
+
+	s.T().Run("ok", func(t *testing.T) {
+		svc := testsupport.ServiceAsUser("SpaceWorkspacePolicy-Service", *fxt.Identities[0])
+		ctrl := NewSpaceWorkspacePolicyController(svc, s.db)
+		_, res := test.UpdateSpaceWorkspacePolicyOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newWorkspacePolicyPayload(2, 60))
+		require.NotNil(t, res.Data)
+		assert.Equal(t, 2, res.Data.Attributes.MaxRunningWorkspaces)
+		assert.Equal(t, 60, res.Data.Attributes.MaxRuntimeMinutes)
+		assert.NotNil(t, res.Data.Attributes.UpdatedAt)
+		_, res = test.ShowSpaceWorkspacePolicyOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID)
+		assert.Equal(t, 2, res.Data.Attributes.MaxRunningWorkspaces)
+		assert.Equal(t, 60, res.Data.Attributes.MaxRuntimeMinutes)
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		svc := testsupport.ServiceAsUser("SpaceWorkspacePolicy-Service", *fxt.Identities[1])
+		ctrl := NewSpaceWorkspacePolicyController(svc, s.db)
+		test.UpdateSpaceWorkspacePolicyForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newWorkspacePolicyPayload(0, 0))
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		svc := goa.New("SpaceWorkspacePolicy-Service")
+		ctrl := NewSpaceWorkspacePolicyController(svc, s.db)
+		test.UpdateSpaceWorkspacePolicyUnauthorized(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newWorkspacePolicyPayload(0, 0))
+	})
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/fabric8-services/fabric8-wit/trash"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// TrashController implements the trash resource.
+type TrashController struct {
+	*goa.Controller
+	db     application.DB
+	config TrashControllerConfig
+}
+
+// TrashControllerConfig the config interface for the TrashController
+type TrashControllerConfig interface {
+	GetTrashRetention() time.Duration
+}
+
+// NewTrashController creates a trash controller.
+func NewTrashController(service *goa.Service, db application.DB, config TrashControllerConfig) *TrashController {
+	return &TrashController{
+		Controller: service.NewController("TrashController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// List runs the list action.
+func (c *TrashController) List(ctx *app.ListTrashContext) error {
+	if _, err := login.ContextIdentity(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if err := c.checkSpace(ctx, ctx.SpaceID); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var items []trash.Item
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		items, err = appl.Trash().List(ctx, ctx.SpaceID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.TrashItemList{
+		Data: make([]*app.TrashItem, 0, len(items)),
+	}
+	for _, item := range items {
+		res.Data = append(res.Data, ConvertTrashItem(ctx.Request, item, c.config.GetTrashRetention()))
+	}
+	return ctx.OK(res)
+}
+
+// Show runs the show action.
+func (c *TrashController) Show(ctx *app.ShowTrashContext) error {
+	if _, err := login.ContextIdentity(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if err := c.checkSpace(ctx, ctx.SpaceID); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var item *trash.Item
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		item, err = appl.Trash().Load(ctx, ctx.SpaceID, ctx.ItemID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.TrashItemSingle{
+		Data: ConvertTrashItem(ctx.Request, *item, c.config.GetTrashRetention()),
+	})
+}
+
+// Restore runs the restore action.
+func (c *TrashController) Restore(ctx *app.RestoreTrashContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if err := c.checkSpace(ctx, ctx.SpaceID); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var item *trash.Item
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		item, err = appl.Trash().Restore(ctx, ctx.SpaceID, ctx.ItemID, *currentUserIdentityID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	// the item is not in the trash anymore so it won't be purged
+	return ctx.OK(&app.TrashItemSingle{
+		Data: ConvertTrashItem(ctx.Request, *item, 0),
+	})
+}
+
+// checkSpace returns a NotFoundError if the given space doesn't exist or a
+// ForbiddenError if the current user is not allowed to modify it.
+func (c *TrashController) checkSpace(ctx context.Context, spaceID uuid.UUID) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.Spaces().CheckExists(ctx, spaceID); err != nil {
+			return err
+		}
+		return authorizeSpace(ctx, appl, spaceID, role.EditWorkItems)
+	})
+}
+
+// ConvertTrashItem converts from internal to external REST representation. The
+// purge date is only set if the retention period is positive.
+func ConvertTrashItem(request *http.Request, item trash.Item, retention time.Duration) *app.TrashItem {
+	spaceID := item.SpaceID.String()
+	selfURL := rest.AbsoluteURL(request, app.TrashHref(spaceID, item.ID))
+	spaceRelatedURL := rest.AbsoluteURL(request, app.SpaceHref(spaceID))
+	workItemRelatedURL := rest.AbsoluteURL(request, app.WorkitemHref(item.WorkItemID))
+	res := &app.TrashItem{
+		Type: trash.APIStringTypeTrashItems,
+		ID:   &item.ID,
+		Attributes: &app.TrashItemAttributes{
+			Kind:      ptr.String(string(item.Kind)),
+			Title:     &item.Title,
+			Number:    &item.Number,
+			DeletedAt: ptr.Time(item.DeletedAt.UTC()),
+		},
+		Relationships: &app.TrashItemRelations{
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(space.SpaceType),
+					ID:   &spaceID,
+					Links: &app.GenericLinks{
+						Related: &spaceRelatedURL,
+					},
+				},
+			},
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeWorkItem),
+					ID:   ptr.String(item.WorkItemID.String()),
+					Links: &app.GenericLinks{
+						Related: &workItemRelatedURL,
+					},
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+	if retention > 0 {
+		res.Attributes.PurgeAt = ptr.Time(item.DeletedAt.Add(retention).UTC())
+	}
+	if item.DeletedBy != nil {
+		deletedByRelatedURL := rest.AbsoluteURL(request, fmt.Sprintf("%s/%s", usersEndpoint, *item.DeletedBy))
+		res.Relationships.DeletedBy = &app.RelationGeneric{
+			Data: &app.GenericData{
+				Type: ptr.String(APIStringTypeUser),
+				ID:   ptr.String(item.DeletedBy.String()),
+				Links: &app.GenericLinks{
+					Related: &deletedByRelatedURL,
+				},
+			},
+		}
+	}
+	return res
+}
@@ -0,0 +1,91 @@
+package controller_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/trash"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestTrashREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunTrashREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestTrashREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestTrashREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func (s *TestTrashREST) TestList() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(2, tf.SetWorkItemTitles("kept", "deleted")))
+		require.NoError(t, s.db.WorkItems().Delete(context.Background(), fxt.WorkItems[1].ID, fxt.Identities[0].ID))
+		svc := testsupport.ServiceAsSpaceUser("Trash-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewTrashController(svc, s.db, s.Configuration)
+		_, items := test.ListTrashOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID)
+		require.Len(t, items.Data, 1)
+		assert.Equal(t, fxt.WorkItems[1].ID, *items.Data[0].ID)
+		assert.Equal(t, string(trash.KindWorkItem), *items.Data[0].Attributes.Kind)
+		assert.Equal(t, "deleted", *items.Data[0].Attributes.Title)
+		require.NotNil(t, items.Data[0].Attributes.PurgeAt)
+		assert.Equal(t, items.Data[0].Attributes.DeletedAt.Add(s.Configuration.GetTrashRetention()), *items.Data[0].Attributes.PurgeAt)
+		require.NotNil(t, items.Data[0].Relationships.DeletedBy)
+		assert.Equal(t, fxt.Identities[0].ID.String(), *items.Data[0].Relationships.DeletedBy.Data.ID)
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		svc := goa.New("Trash-Service")
+		ctrl := NewTrashController(svc, s.db, s.Configuration)
+		test.ListTrashUnauthorized(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		svc := testsupport.ServiceAsSpaceUser("Trash-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewTrashController(svc, s.db, s.Configuration)
+		test.ListTrashNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+	})
+}
+
+func (s *TestTrashREST) TestRestore() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		require.NoError(t, s.db.WorkItems().Delete(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[0].ID))
+		svc := testsupport.ServiceAsSpaceUser("Trash-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewTrashController(svc, s.db, s.Configuration)
+		_, item := test.RestoreTrashOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, fxt.WorkItems[0].ID)
+		assert.Equal(t, fxt.WorkItems[0].ID, *item.Data.ID)
+		assert.Nil(t, item.Data.Attributes.PurgeAt)
+		_, err := s.db.WorkItems().LoadByID(context.Background(), fxt.WorkItems[0].ID)
+		require.NoError(t, err)
+		// the work item is not in the trash anymore
+		test.RestoreTrashNotFound(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, fxt.WorkItems[0].ID)
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.WorkItems(1))
+		require.NoError(t, s.db.WorkItems().Delete(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[0].ID))
+		svc := testsupport.ServiceAsSpaceUser("Trash-Service", *fxt.Identities[1], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewTrashController(svc, s.db, s.Configuration)
+		test.RestoreTrashForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, fxt.WorkItems[0].ID)
+	})
+}
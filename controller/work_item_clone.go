@@ -0,0 +1,406 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/path"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/fabric8-services/fabric8-wit/workitem/template"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WorkItemCloneController implements the work_item_clone resource.
+type WorkItemCloneController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemCloneController creates a work_item_clone controller.
+func NewWorkItemCloneController(service *goa.Service, db application.DB) *WorkItemCloneController {
+	return &WorkItemCloneController{
+		Controller: service.NewController("WorkItemCloneController"),
+		db:         db,
+	}
+}
+
+// Clone runs the clone action.
+func (c *WorkItemCloneController) Clone(ctx *app.CloneWorkItemCloneContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	var deep bool
+	var targetSpaceID *uuid.UUID
+	if ctx.Payload != nil && ctx.Payload.Data != nil {
+		if ctx.Payload.Data.Attributes != nil && ctx.Payload.Data.Attributes.Deep != nil {
+			deep = *ctx.Payload.Data.Attributes.Deep
+		}
+		if rel := ctx.Payload.Data.Relationships; rel != nil && rel.Space != nil && rel.Space.Data != nil && rel.Space.Data.ID != nil {
+			id, err := uuid.FromString(*rel.Space.Data.ID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.space.data.id", *rel.Space.Data.ID).Expected("valid UUID"))
+			}
+			targetSpaceID = &id
+		}
+	}
+	var source *workitem.WorkItem
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		source, err = appl.WorkItems().LoadByID(ctx, ctx.WiID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if targetSpaceID == nil {
+		targetSpaceID = &source.SpaceID
+	}
+	var wi *workitem.WorkItem
+	var wit *workitem.WorkItemType
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeSpace(ctx, appl, *targetSpaceID, role.EditWorkItems); err != nil {
+			return err
+		}
+		if err := checkSpaceTemplateCompatible(ctx, appl, source.SpaceID, *targetSpaceID); err != nil {
+			return err
+		}
+		tree, err := loadWorkItemTree(ctx, appl, *source, deep)
+		if err != nil {
+			return errs.Wrapf(err, "failed to load the work item %s to clone", source.ID)
+		}
+		mapper, err := newSpaceFieldMapper(ctx, appl, source.SpaceID, *targetSpaceID)
+		if err != nil {
+			return err
+		}
+		wi, err = createWorkItemTree(ctx, appl, *tree, *targetSpaceID, *currentUserIdentityID, mapper)
+		if err != nil {
+			return errs.Wrapf(err, "failed to clone the work item %s", source.ID)
+		}
+		wit, err = appl.WorkItemTypes().Load(ctx, wi.Type)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	hasChildren := workItemIncludeHasChildren(ctx, c.db)
+	wi2, err := ConvertWorkItem(ctx.Request, *wit, *wi, hasChildren)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	ctx.ResponseData.Header().Set("Last-Modified", lastModified(*wi))
+	ctx.ResponseData.Header().Set("Location", app.WorkitemHref(wi2.ID))
+	return ctx.Created(&app.WorkItemSingle{Data: wi2})
+}
+
+// workItemTreeExcludedFields are never copied when cloning a work item or
+// saving it as a template since they identify the original work item or only
+// make sense in its space.
+var workItemTreeExcludedFields = map[string]struct{}{
+	workitem.SystemNumber:       {},
+	workitem.SystemCreator:      {},
+	workitem.SystemCreatedAt:    {},
+	workitem.SystemUpdatedAt:    {},
+	workitem.SystemOrder:        {},
+	workitem.SystemRemoteItemID: {},
+	workitem.SystemCodebase:     {},
+}
+
+// loadWorkItemTree returns the given work item as a template node. If deep is
+// true, the children of the work item (following the parent/child links) are
+// included recursively in their original order.
+func loadWorkItemTree(ctx context.Context, appl application.Application, wi workitem.WorkItem, deep bool) (*template.Node, error) {
+	wit, err := appl.WorkItemTypes().Load(ctx, wi.Type)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to load the type of work item %s", wi.ID)
+	}
+	node := template.Node{
+		TypeID: wi.Type,
+		Fields: map[string]interface{}{},
+	}
+	for name, def := range wit.Fields {
+		if _, excluded := workItemTreeExcludedFields[name]; excluded || def.ReadOnly {
+			continue
+		}
+		if v := wi.Fields[name]; v != nil {
+			node.Fields[name] = v
+		}
+	}
+	if !deep {
+		return &node, nil
+	}
+	childLinks, err := appl.WorkItemLinks().ListChildLinks(ctx, link.SystemWorkItemLinkTypeParentChildID, wi.ID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to list the children of work item %s", wi.ID)
+	}
+	if len(childLinks) == 0 {
+		return &node, nil
+	}
+	childIDs := make([]uuid.UUID, 0, len(childLinks))
+	for _, l := range childLinks {
+		childIDs = append(childIDs, l.TargetID)
+	}
+	children, err := appl.WorkItems().LoadBatchByID(ctx, childIDs)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to load the children of work item %s", wi.ID)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		oi, _ := children[i].Fields[workitem.SystemOrder].(float64)
+		oj, _ := children[j].Fields[workitem.SystemOrder].(float64)
+		return oi < oj
+	})
+	for _, child := range children {
+		childNode, err := loadWorkItemTree(ctx, appl, *child, true)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, *childNode)
+	}
+	return &node, nil
+}
+
+// createWorkItemTree creates the work items of the given template node in the
+// given space and links them with the parent/child link type. The root work
+// item is returned.
+func createWorkItemTree(ctx context.Context, appl application.Application, node template.Node, spaceID uuid.UUID, creatorID uuid.UUID, mapper *spaceFieldMapper) (*workitem.WorkItem, error) {
+	wit, err := appl.WorkItemTypes().Load(ctx, node.TypeID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to load work item type %s", node.TypeID)
+	}
+	fields, err := normalizeTemplateFields(*wit, node.Fields)
+	if err != nil {
+		return nil, err
+	}
+	wi, err := appl.WorkItems().Create(ctx, spaceID, node.TypeID, mapper.mapFields(fields), creatorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := autoWatchWorkItem(ctx, appl, *wi, &creatorID); err != nil {
+		return nil, errs.Wrap(err, "failed to subscribe watchers to the new work item")
+	}
+	for _, childNode := range node.Children {
+		child, err := createWorkItemTree(ctx, appl, childNode, spaceID, creatorID, mapper)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := appl.WorkItemLinks().Create(ctx, wi.ID, child.ID, link.SystemWorkItemLinkTypeParentChildID, creatorID); err != nil {
+			return nil, errs.Wrapf(err, "failed to link work item %s to its parent %s", child.ID, wi.ID)
+		}
+	}
+	return wi, nil
+}
+
+// normalizeTemplateFields converts the field values that don't survive the
+// JSON round trip of a stored template back into the types expected by the
+// work item model. Fields that are unknown to the work item type are dropped.
+func normalizeTemplateFields(wit workitem.WorkItemType, fields map[string]interface{}) (map[string]interface{}, error) {
+	res := make(map[string]interface{}, len(fields))
+	for name, v := range fields {
+		def, ok := wit.Fields[name]
+		if !ok || def.ReadOnly {
+			continue
+		}
+		switch def.Type.GetKind() {
+		case workitem.KindMarkup:
+			if mc := rendering.NewMarkupContentFromValue(v); mc != nil {
+				v = *mc
+			}
+		case workitem.KindInteger, workitem.KindDuration:
+			if f, ok := v.(float64); ok {
+				v = int(f)
+			}
+		case workitem.KindInstant:
+			if s, ok := v.(string); ok {
+				t, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					return nil, errors.NewBadParameterError(name, s).Expected("RFC3339 timestamp")
+				}
+				v = t
+			}
+		}
+		res[name] = v
+	}
+	return res, nil
+}
+
+// checkSpaceTemplateCompatible returns a BadParameterError if the target
+// space doesn't use the same space template as the source space, in which
+// case the work item types of the source can't be used in the target.
+func checkSpaceTemplateCompatible(ctx context.Context, appl application.Application, sourceSpaceID, targetSpaceID uuid.UUID) error {
+	if sourceSpaceID == targetSpaceID {
+		return nil
+	}
+	source, err := appl.Spaces().Load(ctx, sourceSpaceID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to load space %s", sourceSpaceID)
+	}
+	target, err := appl.Spaces().Load(ctx, targetSpaceID)
+	if err != nil {
+		return err
+	}
+	if source.SpaceTemplateID != target.SpaceTemplateID {
+		return errors.NewBadParameterError("space", targetSpaceID).Expected(fmt.Sprintf("space using the space template %s", source.SpaceTemplateID))
+	}
+	return nil
+}
+
+// spaceFieldMapper translates the area, iteration and labels of work items
+// from one space to another. Areas and iterations are matched by their path
+// of names below the root and fall back to the root of the target space,
+// labels are matched by name and dropped if the target space has no such
+// label.
+type spaceFieldMapper struct {
+	areas      map[string]string
+	iterations map[string]string
+	labels     map[string]string
+	rootArea   string
+	rootIter   string
+}
+
+// newSpaceFieldMapper returns a mapper for the given spaces or nil if both
+// spaces are the same and no mapping is needed.
+func newSpaceFieldMapper(ctx context.Context, appl application.Application, sourceSpaceID, targetSpaceID uuid.UUID) (*spaceFieldMapper, error) {
+	if sourceSpaceID == targetSpaceID {
+		return nil, nil
+	}
+	m := spaceFieldMapper{
+		areas:      map[string]string{},
+		iterations: map[string]string{},
+		labels:     map[string]string{},
+	}
+	sourceAreas, err := appl.Areas().List(ctx, sourceSpaceID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to list the areas of space %s", sourceSpaceID)
+	}
+	targetAreas, err := appl.Areas().List(ctx, targetSpaceID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to list the areas of space %s", targetSpaceID)
+	}
+	targetAreaIDs := map[string]string{}
+	targetAreaNames := map[uuid.UUID]string{}
+	for _, a := range targetAreas {
+		targetAreaNames[a.ID] = a.Name
+	}
+	for _, a := range targetAreas {
+		if len(a.Path) == 0 {
+			m.rootArea = a.ID.String()
+		}
+		targetAreaIDs[namePath(a.Path, a.Name, targetAreaNames)] = a.ID.String()
+	}
+	sourceAreaNames := map[uuid.UUID]string{}
+	for _, a := range sourceAreas {
+		sourceAreaNames[a.ID] = a.Name
+	}
+	for _, a := range sourceAreas {
+		if id, ok := targetAreaIDs[namePath(a.Path, a.Name, sourceAreaNames)]; ok {
+			m.areas[a.ID.String()] = id
+		}
+	}
+	sourceIterations, err := appl.Iterations().List(ctx, sourceSpaceID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to list the iterations of space %s", sourceSpaceID)
+	}
+	targetIterations, err := appl.Iterations().List(ctx, targetSpaceID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to list the iterations of space %s", targetSpaceID)
+	}
+	targetIterationIDs := map[string]string{}
+	targetIterationNames := map[uuid.UUID]string{}
+	for _, i := range targetIterations {
+		targetIterationNames[i.ID] = i.Name
+	}
+	for _, i := range targetIterations {
+		if len(i.Path) == 0 {
+			m.rootIter = i.ID.String()
+		}
+		targetIterationIDs[namePath(i.Path, i.Name, targetIterationNames)] = i.ID.String()
+	}
+	sourceIterationNames := map[uuid.UUID]string{}
+	for _, i := range sourceIterations {
+		sourceIterationNames[i.ID] = i.Name
+	}
+	for _, i := range sourceIterations {
+		if id, ok := targetIterationIDs[namePath(i.Path, i.Name, sourceIterationNames)]; ok {
+			m.iterations[i.ID.String()] = id
+		}
+	}
+	sourceLabels, err := appl.Labels().List(ctx, sourceSpaceID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to list the labels of space %s", sourceSpaceID)
+	}
+	targetLabels, err := appl.Labels().List(ctx, targetSpaceID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to list the labels of space %s", targetSpaceID)
+	}
+	targetLabelIDs := map[string]string{}
+	for _, l := range targetLabels {
+		targetLabelIDs[l.Name] = l.ID.String()
+	}
+	for _, l := range sourceLabels {
+		if id, ok := targetLabelIDs[l.Name]; ok {
+			m.labels[l.ID.String()] = id
+		}
+	}
+	return &m, nil
+}
+
+// namePath returns the names of the given path (without the root) and the
+// given name joined with slashes. The root itself has an empty name path so
+// that the roots of two spaces match regardless of the space names.
+func namePath(p path.Path, name string, names map[uuid.UUID]string) string {
+	if len(p) == 0 {
+		return ""
+	}
+	elems := make([]string, 0, len(p))
+	for _, id := range p[1:] {
+		elems = append(elems, names[id])
+	}
+	return strings.Join(append(elems, name), "/")
+}
+
+// mapFields returns the given fields with the area, iteration and labels
+// translated to the target space. A nil mapper returns the fields unchanged.
+func (m *spaceFieldMapper) mapFields(fields map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return fields
+	}
+	if v, ok := fields[workitem.SystemArea].(string); ok {
+		if id, ok := m.areas[v]; ok {
+			fields[workitem.SystemArea] = id
+		} else {
+			fields[workitem.SystemArea] = m.rootArea
+		}
+	}
+	if v, ok := fields[workitem.SystemIteration].(string); ok {
+		if id, ok := m.iterations[v]; ok {
+			fields[workitem.SystemIteration] = id
+		} else {
+			fields[workitem.SystemIteration] = m.rootIter
+		}
+	}
+	if v, ok := fields[workitem.SystemLabels].([]interface{}); ok {
+		labels := make([]interface{}, 0, len(v))
+		for _, l := range v {
+			if s, ok := l.(string); ok {
+				if id, ok := m.labels[s]; ok {
+					labels = append(labels, id)
+				}
+			}
+		}
+		fields[workitem.SystemLabels] = labels
+	}
+	return fields
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+)
+
+// WorkItemCodeReferencesController implements the work_item_code_references resource.
+type WorkItemCodeReferencesController struct {
+	*goa.Controller
+	db     application.DB
+	config WorkItemCodeReferencesControllerConfig
+}
+
+// WorkItemCodeReferencesControllerConfig the config interface for the WorkItemCodeReferencesController
+type WorkItemCodeReferencesControllerConfig interface {
+	GetCacheControlCodeReferences() string
+}
+
+// NewWorkItemCodeReferencesController creates a work_item_code_references controller.
+func NewWorkItemCodeReferencesController(service *goa.Service, db application.DB, config WorkItemCodeReferencesControllerConfig) *WorkItemCodeReferencesController {
+	return &WorkItemCodeReferencesController{
+		Controller: service.NewController("WorkItemCodeReferencesController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// List runs the list action.
+func (c *WorkItemCodeReferencesController) List(ctx *app.ListWorkItemCodeReferencesContext) error {
+	var references []codebase.Reference
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.WorkItems().CheckExists(ctx, ctx.WiID); err != nil {
+			return err
+		}
+		var err error
+		references, err = appl.CodeReferences().ListByWorkItem(ctx, ctx.WiID)
+		return errs.Wrap(err, "failed to list the code references of the work item")
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.ConditionalEntities(references, c.config.GetCacheControlCodeReferences, func() error {
+		return ctx.OK(&app.CodeReferenceList{
+			Data: ConvertCodeReferences(ctx.Request, references),
+		})
+	})
+}
+
+// ConvertCodeReferences converts between internal and external REST representation
+func ConvertCodeReferences(request *http.Request, references []codebase.Reference) []*app.CodeReference {
+	res := make([]*app.CodeReference, len(references))
+	for i, r := range references {
+		res[i] = ConvertCodeReference(request, r)
+	}
+	return res
+}
+
+// ConvertCodeReference converts between internal and external REST representation
+func ConvertCodeReference(request *http.Request, r codebase.Reference) *app.CodeReference {
+	workItemRelatedURL := rest.AbsoluteURL(request, app.WorkitemHref(r.WorkItemID))
+	codebaseRelatedURL := rest.AbsoluteURL(request, app.CodebaseHref(r.CodebaseID))
+	selfURL := workItemRelatedURL + "/codereferences"
+	return &app.CodeReference{
+		Type: codebase.APIStringTypeCodeReferences,
+		ID:   &r.ID,
+		Attributes: &app.CodeReferenceAttributes{
+			Kind:      string(r.Kind),
+			Ref:       r.Ref,
+			Title:     ptr.String(r.Title),
+			URL:       ptr.String(r.URL),
+			Author:    ptr.String(r.Author),
+			State:     ptr.String(r.State),
+			CreatedAt: ptr.Time(r.CreatedAt.UTC()),
+			UpdatedAt: ptr.Time(r.UpdatedAt.UTC()),
+		},
+		Relationships: &app.CodeReferenceRelations{
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeWorkItem),
+					ID:   ptr.String(r.WorkItemID.String()),
+					Links: &app.GenericLinks{
+						Related: &workItemRelatedURL,
+					},
+				},
+			},
+			Codebase: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeCodebase),
+					ID:   ptr.String(r.CodebaseID.String()),
+					Links: &app.GenericLinks{
+						Related: &codebaseRelatedURL,
+					},
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
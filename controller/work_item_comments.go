@@ -13,6 +13,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/notification"
 	"github.com/fabric8-services/fabric8-wit/rendering"
 	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/watcher"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
@@ -54,11 +55,13 @@ func NewNotifyingWorkItemCommentsController(service *goa.Service, db application
 // Create runs the create action.
 func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsContext) error {
 	var newComment comment.Comment
+	var spaceID uuid.UUID
 	err := application.Transactional(c.db, func(appl application.Application) error {
-		_, err := appl.WorkItems().LoadByID(ctx, ctx.WiID)
+		wi, err := appl.WorkItems().LoadByID(ctx, ctx.WiID)
 		if err != nil {
 			return goa.ErrNotFound(err.Error())
 		}
+		spaceID = wi.SpaceID
 		currentUserIdentityID, err := login.ContextIdentity(ctx)
 		if err != nil {
 			return goa.ErrUnauthorized(err.Error())
@@ -90,6 +93,12 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 		if err != nil {
 			return goa.ErrInternal(err.Error())
 		}
+		if err := appl.Watchers().AutoWatch(ctx, ctx.WiID, watcher.ReasonCommenter, *currentUserIdentityID); err != nil {
+			return goa.ErrInternal(err.Error())
+		}
+		if err := autoWatchMentions(ctx, appl, ctx.WiID, newComment.Body); err != nil {
+			return goa.ErrInternal(err.Error())
+		}
 
 		res := &app.CommentSingle{
 			Data: ConvertComment(ctx.Request, newComment),
@@ -100,7 +109,7 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 	if ctx.ResponseData.Status == 200 {
-		c.notification.Send(ctx, notification.NewCommentCreated(newComment.ID.String()))
+		c.notification.Send(ctx, notification.NewCommentCreated(spaceID, newComment.ID.String()))
 	}
 	return nil
 }
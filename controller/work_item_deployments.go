@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/deployment"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+)
+
+// WorkItemDeploymentsController implements the work_item_deployments resource.
+type WorkItemDeploymentsController struct {
+	*goa.Controller
+	db     application.DB
+	config WorkItemDeploymentsControllerConfig
+}
+
+// WorkItemDeploymentsControllerConfig the config interface for the WorkItemDeploymentsController
+type WorkItemDeploymentsControllerConfig interface {
+	GetCacheControlDeployments() string
+}
+
+// NewWorkItemDeploymentsController creates a work_item_deployments controller.
+func NewWorkItemDeploymentsController(service *goa.Service, db application.DB, config WorkItemDeploymentsControllerConfig) *WorkItemDeploymentsController {
+	return &WorkItemDeploymentsController{
+		Controller: service.NewController("WorkItemDeploymentsController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// List runs the list action.
+func (c *WorkItemDeploymentsController) List(ctx *app.ListWorkItemDeploymentsContext) error {
+	var deployments []deployment.Deployment
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.WorkItems().CheckExists(ctx, ctx.WiID); err != nil {
+			return err
+		}
+		var err error
+		deployments, err = appl.Deployments().ListByWorkItem(ctx, ctx.WiID)
+		return errs.Wrap(err, "failed to list the deployments of the work item")
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.ConditionalEntities(deployments, c.config.GetCacheControlDeployments, func() error {
+		return ctx.OK(&app.DeploymentList{
+			Data: ConvertDeployments(ctx.Request, deployments),
+		})
+	})
+}
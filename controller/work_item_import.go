@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/fabric8-services/fabric8-wit/workitem/importer"
+	"github.com/goadesign/goa"
+)
+
+// APIStringTypeWorkItemImport contains the JSON API type for work item imports
+const APIStringTypeWorkItemImport = "workitemimports"
+
+// WorkItemImportController implements the work_item_import resource.
+type WorkItemImportController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemImportController creates a work_item_import controller.
+func NewWorkItemImportController(service *goa.Service, db application.DB) *WorkItemImportController {
+	return &WorkItemImportController{
+		Controller: service.NewController("WorkItemImportController"),
+		db:         db,
+	}
+}
+
+// Import runs the import action.
+func (c *WorkItemImportController) Import(ctx *app.ImportWorkItemImportContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	options := importer.Options{
+		Columns: attrs.Columns,
+	}
+	if attrs.ReferenceColumn != nil {
+		options.ReferenceColumn = *attrs.ReferenceColumn
+	}
+	if attrs.ParentColumn != nil {
+		options.ParentColumn = *attrs.ParentColumn
+	}
+	if attrs.CreateMissing != nil {
+		options.CreateMissing = *attrs.CreateMissing
+	}
+	dryRun := attrs.DryRun == nil || *attrs.DryRun
+	rel := ctx.Payload.Data.Relationships
+	if rel != nil && rel.BaseType != nil && rel.BaseType.Data != nil {
+		options.TypeID = &rel.BaseType.Data.ID
+	}
+	var report *importer.Report
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		sp, err := appl.Spaces().Load(ctx, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.EditWorkItems); err != nil {
+			return err
+		}
+		wits, err := appl.WorkItemTypes().List(ctx, sp.SpaceTemplateID)
+		if err != nil {
+			return err
+		}
+		imp, err := importer.NewImporter(ctx.SpaceID, *currentUserIdentityID, wits, options)
+		if err != nil {
+			return err
+		}
+		report, err = imp.Validate(ctx, appl, strings.NewReader(attrs.Csv))
+		if err != nil {
+			return err
+		}
+		if dryRun || !report.Valid() {
+			return nil
+		}
+		for _, p := range imp.Permissions() {
+			if err := authorizeSpace(ctx, appl, ctx.SpaceID, p); err != nil {
+				return err
+			}
+		}
+		return imp.Commit(ctx, appl)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.WorkItemImportSingle{
+		Data: ConvertWorkItemImport(ctx.Payload.Data, *report),
+	})
+}
+
+// ConvertWorkItemImport returns the given import request completed with the
+// outcome of the import. The content of the file is not sent back.
+func ConvertWorkItemImport(request *app.WorkItemImport, report importer.Report) *app.WorkItemImport {
+	attrs := *request.Attributes
+	attrs.Csv = ""
+	attrs.Committed = ptr.Bool(report.Committed)
+	attrs.Creates = report.Creates
+	attrs.Rows = make([]*app.WorkItemImportRow, len(report.Rows))
+	for i, r := range report.Rows {
+		row := &app.WorkItemImportRow{
+			Row:        r.Row,
+			Errors:     r.Errors,
+			WorkitemID: r.WorkItemID,
+			Number:     r.Number,
+		}
+		if r.Reference != "" {
+			row.Reference = ptr.String(r.Reference)
+		}
+		attrs.Rows[i] = row
+	}
+	return &app.WorkItemImport{
+		Type:          APIStringTypeWorkItemImport,
+		Attributes:    &attrs,
+		Relationships: request.Relationships,
+	}
+}
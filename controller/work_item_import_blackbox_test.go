@@ -0,0 +1,153 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemImportREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunWorkItemImportREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWorkItemImportREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestWorkItemImportREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func newImportPayload(fxt *tf.TestFixture, content string, dryRun bool) *app.WorkItemImportSingle {
+	return &app.WorkItemImportSingle{
+		Data: &app.WorkItemImport{
+			Type: APIStringTypeWorkItemImport,
+			Attributes: &app.WorkItemImportAttributes{
+				Csv: content,
+				Columns: map[string]string{
+					"Title": workitem.SystemTitle,
+				},
+				ReferenceColumn: ptr.String("ID"),
+				ParentColumn:    ptr.String("Parent"),
+				DryRun:          ptr.Bool(dryRun),
+			},
+			Relationships: &app.WorkItemImportRelationships{
+				BaseType: &app.RelationBaseType{
+					Data: &app.BaseTypeData{
+						ID:   fxt.WorkItemTypes[0].ID,
+						Type: APIStringTypeWorkItemType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *TestWorkItemImportREST) TestImport() {
+	const content = "ID,Title,Parent\nA,Epic,\nB,Story,A\n"
+
+	s.T().Run("ok", func(t *testing.T) {
+		t.Run("dry run", func(t *testing.T) {
+			// given
+			fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+			svc := testsupport.ServiceAsUser("WorkItemImport-Service", *fxt.Identities[0])
+			ctrl := NewWorkItemImportController(svc, s.db)
+			// when
+			_, res := test.ImportWorkItemImportOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newImportPayload(fxt, content, true))
+			// then
+			require.NotNil(t, res.Data.Attributes.Committed)
+			assert.False(t, *res.Data.Attributes.Committed)
+			assert.Empty(t, res.Data.Attributes.Csv)
+			require.Len(t, res.Data.Attributes.Rows, 2)
+			assert.Nil(t, res.Data.Attributes.Rows[0].WorkitemID)
+			assert.Empty(t, res.Data.Attributes.Rows[0].Errors)
+		})
+
+		t.Run("commit", func(t *testing.T) {
+			// given
+			fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+			svc := testsupport.ServiceAsUser("WorkItemImport-Service", *fxt.Identities[0])
+			ctrl := NewWorkItemImportController(svc, s.db)
+			// when
+			_, res := test.ImportWorkItemImportOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newImportPayload(fxt, content, false))
+			// then
+			require.NotNil(t, res.Data.Attributes.Committed)
+			assert.True(t, *res.Data.Attributes.Committed)
+			require.Len(t, res.Data.Attributes.Rows, 2)
+			require.NotNil(t, res.Data.Attributes.Rows[1].WorkitemID)
+			require.NotNil(t, res.Data.Attributes.Rows[1].Number)
+			wi, err := s.db.WorkItems().LoadByID(svc.Context, *res.Data.Attributes.Rows[1].WorkitemID)
+			require.NoError(t, err)
+			assert.Equal(t, "Story", wi.Fields[workitem.SystemTitle])
+		})
+
+		t.Run("commit with row errors", func(t *testing.T) {
+			// given
+			fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+			svc := testsupport.ServiceAsUser("WorkItemImport-Service", *fxt.Identities[0])
+			ctrl := NewWorkItemImportController(svc, s.db)
+			// when
+			_, res := test.ImportWorkItemImportOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newImportPayload(fxt, "ID,Title,Parent\nA,Epic,\nB,,A\n", false))
+			// then
+			require.NotNil(t, res.Data.Attributes.Committed)
+			assert.False(t, *res.Data.Attributes.Committed)
+			assert.Empty(t, res.Data.Attributes.Rows[0].Errors)
+			assert.NotEmpty(t, res.Data.Attributes.Rows[1].Errors)
+			assert.Nil(t, res.Data.Attributes.Rows[0].WorkitemID)
+		})
+	})
+
+	s.T().Run("bad request", func(t *testing.T) {
+		t.Run("missing column", func(t *testing.T) {
+			// given
+			fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+			svc := testsupport.ServiceAsUser("WorkItemImport-Service", *fxt.Identities[0])
+			ctrl := NewWorkItemImportController(svc, s.db)
+			// when/then
+			test.ImportWorkItemImportBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newImportPayload(fxt, "ID,Name\nA,Epic\n", true))
+		})
+
+		t.Run("invalid CSV", func(t *testing.T) {
+			// given
+			fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+			svc := testsupport.ServiceAsUser("WorkItemImport-Service", *fxt.Identities[0])
+			ctrl := NewWorkItemImportController(svc, s.db)
+			// when/then
+			test.ImportWorkItemImportBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newImportPayload(fxt, "ID,Title,Parent\nA,\"Epic,\n", true))
+		})
+	})
+
+	s.T().Run("forbidden for a viewer", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.WorkItemTypes(1))
+		svc := testsupport.ServiceAsUser("WorkItemImport-Service", *fxt.Identities[1])
+		ctrl := NewWorkItemImportController(svc, s.db)
+		// when/then
+		test.ImportWorkItemImportForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newImportPayload(fxt, content, true))
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+		svc := goa.New("WorkItemImport-Service")
+		ctrl := NewWorkItemImportController(svc, s.db)
+		// when/then
+		test.ImportWorkItemImportUnauthorized(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newImportPayload(fxt, content, true))
+	})
+}
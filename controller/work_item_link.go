@@ -9,8 +9,9 @@ import (
 	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/jsonapi"
 	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/notification"
 	"github.com/fabric8-services/fabric8-wit/rest"
-	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/link"
 	"github.com/goadesign/goa"
@@ -21,8 +22,9 @@ import (
 // WorkItemLinkController implements the work-item-link resource.
 type WorkItemLinkController struct {
 	*goa.Controller
-	db     application.DB
-	config WorkItemLinkControllerConfig
+	db           application.DB
+	notification notification.Channel
+	config       WorkItemLinkControllerConfig
 }
 
 // WorkItemLinkControllerConfig the config interface for the WorkitemLinkController
@@ -33,10 +35,21 @@ type WorkItemLinkControllerConfig interface {
 
 // NewWorkItemLinkController creates a work-item-link controller.
 func NewWorkItemLinkController(service *goa.Service, db application.DB, config WorkItemLinkControllerConfig) *WorkItemLinkController {
+	return NewNotifyingWorkItemLinkController(service, db, &notification.DevNullChannel{}, config)
+}
+
+// NewNotifyingWorkItemLinkController creates a work-item-link controller with
+// notification broadcast.
+func NewNotifyingWorkItemLinkController(service *goa.Service, db application.DB, notificationChannel notification.Channel, config WorkItemLinkControllerConfig) *WorkItemLinkController {
+	n := notificationChannel
+	if n == nil {
+		n = &notification.DevNullChannel{}
+	}
 	return &WorkItemLinkController{
-		Controller: service.NewController("WorkItemLinkController"),
-		db:         db,
-		config:     config,
+		Controller:   service.NewController("WorkItemLinkController"),
+		db:           db,
+		notification: n,
+		config:       config,
 	}
 }
 
@@ -234,9 +247,14 @@ func (c *WorkItemLinkController) Create(ctx *app.CreateWorkItemLinkContext) erro
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 	var createdModelLink *link.WorkItemLink
+	var spaceIDs []uuid.UUID
 	err = application.Transactional(c.db, func(appl application.Application) error {
 		var err error
 		createdModelLink, err = appl.WorkItemLinks().Create(ctx.Context, modelLink.SourceID, modelLink.TargetID, modelLink.LinkTypeID, *currentUserIdentityID)
+		if err != nil {
+			return err
+		}
+		spaceIDs, err = linkSpaceIDs(ctx, appl, *createdModelLink)
 		return err
 	})
 	if err != nil {
@@ -248,9 +266,28 @@ func (c *WorkItemLinkController) Create(ctx *app.CreateWorkItemLinkContext) erro
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 	ctx.ResponseWriter.Header().Set("Location", app.WorkItemLinkHref(createdAppLink.Data.ID))
+	for _, spaceID := range spaceIDs {
+		c.notification.Send(ctx, notification.NewLinkCreated(spaceID, createdModelLink.ID.String()))
+	}
 	return ctx.Created(&createdAppLink)
 }
 
+// linkSpaceIDs returns the IDs of the spaces of the source and target work
+// items of the given link, only once if both are in the same space.
+func linkSpaceIDs(ctx context.Context, appl application.Application, l link.WorkItemLink) ([]uuid.UUID, error) {
+	var spaceIDs []uuid.UUID
+	for _, workItemID := range []uuid.UUID{l.SourceID, l.TargetID} {
+		wi, err := appl.WorkItems().LoadByID(ctx, workItemID)
+		if err != nil {
+			return nil, errs.Wrapf(err, "failed to load the work item %s of link %s", workItemID, l.ID)
+		}
+		if len(spaceIDs) == 0 || spaceIDs[0] != wi.SpaceID {
+			spaceIDs = append(spaceIDs, wi.SpaceID)
+		}
+	}
+	return spaceIDs, nil
+}
+
 func (c *WorkItemLinkController) checkIfUserIsSpaceCollaboratorOrWorkItemCreator(ctx context.Context, linkID uuid.UUID, currentIdentityID uuid.UUID) (bool, error) {
 	var authorized bool
 	var sourceSpaceID *uuid.UUID
@@ -272,15 +309,23 @@ func (c *WorkItemLinkController) checkIfUserIsSpaceCollaboratorOrWorkItemCreator
 	if err != nil {
 		return false, err
 	}
-	// Check if the user is a space collaborator
+	// Check if the role of the user allows to link work items in both spaces
 	if !authorized {
-		authorized, err = authz.Authorize(ctx, sourceSpaceID.String())
-		if err != nil {
-			return false, err
-		}
-		return authz.Authorize(ctx, targetSpaceID.String())
-	}
-	return authorized, nil
+		err = application.Transactional(c.db, func(appl application.Application) error {
+			for _, spaceID := range []uuid.UUID{*sourceSpaceID, *targetSpaceID} {
+				r, err := currentSpaceRole(ctx, appl, spaceID)
+				if err != nil {
+					return err
+				}
+				if !r.Can(role.LinkWorkItems) {
+					return nil
+				}
+			}
+			authorized = true
+			return nil
+		})
+	}
+	return authorized, err
 }
 
 func (c *WorkItemLinkController) checkWorkItemCreatorOrSpaceOwner(ctx context.Context, appl application.Application, workItemID uuid.UUID, currentIdentityID uuid.UUID) (bool, *uuid.UUID, error) {
@@ -319,12 +364,24 @@ func (c *WorkItemLinkController) Delete(ctx *app.DeleteWorkItemLinkContext) erro
 	if !authorized {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not authorized to delete the link"))
 	}
+	var spaceIDs []uuid.UUID
 	err = application.Transactional(c.db, func(appl application.Application) error {
+		l, err := appl.WorkItemLinks().Load(ctx.Context, ctx.LinkID)
+		if err != nil {
+			return err
+		}
+		spaceIDs, err = linkSpaceIDs(ctx, appl, *l)
+		if err != nil {
+			return err
+		}
 		return appl.WorkItemLinks().Delete(ctx.Context, ctx.LinkID, *currentUserIdentityID)
 	})
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
+	for _, spaceID := range spaceIDs {
+		c.notification.Send(ctx, notification.NewLinkDeleted(spaceID, ctx.LinkID.String()))
+	}
 	return ctx.OK([]byte{})
 }
 
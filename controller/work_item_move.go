@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WorkItemMoveController implements the work_item_move resource.
+type WorkItemMoveController struct {
+	*goa.Controller
+	db           application.DB
+	notification notification.Channel
+}
+
+// NewWorkItemMoveController creates a work_item_move controller.
+func NewWorkItemMoveController(service *goa.Service, db application.DB) *WorkItemMoveController {
+	return NewNotifyingWorkItemMoveController(service, db, &notification.DevNullChannel{})
+}
+
+// NewNotifyingWorkItemMoveController creates a work_item_move controller with notification broadcast.
+func NewNotifyingWorkItemMoveController(service *goa.Service, db application.DB, notificationChannel notification.Channel) *WorkItemMoveController {
+	n := notificationChannel
+	if n == nil {
+		n = &notification.DevNullChannel{}
+	}
+	return &WorkItemMoveController{
+		Controller:   service.NewController("WorkItemMoveController"),
+		db:           db,
+		notification: n,
+	}
+}
+
+// Move runs the move action.
+func (c *WorkItemMoveController) Move(ctx *app.MoveWorkItemMoveContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	rel := ctx.Payload.Data.Relationships
+	if rel.Space == nil || rel.Space.Data == nil || rel.Space.Data.ID == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.space", nil).Expected("not nil"))
+	}
+	targetSpaceID, err := uuid.FromString(*rel.Space.Data.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.space.data.id", *rel.Space.Data.ID).Expected("valid UUID"))
+	}
+	var targetTypeID *uuid.UUID
+	if rel.BaseType != nil && rel.BaseType.Data != nil {
+		targetTypeID = &rel.BaseType.Data.ID
+	}
+	var wi *workitem.WorkItem
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		wi, err = appl.WorkItems().LoadByID(ctx, ctx.WiID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	creator := wi.Fields[workitem.SystemCreator]
+	if creator == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(ctx, errs.New("work item doesn't have creator")))
+	}
+	authorized, err := authorizeWorkitemEditor(ctx, c.db, wi.SpaceID, creator.(string), currentUserIdentityID.String())
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if !authorized {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not authorized to access the space"))
+	}
+	sourceSpaceID := wi.SpaceID
+	var wit *workitem.WorkItemType
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		targetSpace, err := appl.Spaces().Load(ctx, targetSpaceID)
+		if err != nil {
+			return err
+		}
+		if err := authorizeSpace(ctx, appl, targetSpaceID, role.EditWorkItems); err != nil {
+			return err
+		}
+		oldType, err := appl.WorkItemTypes().Load(ctx, wi.Type)
+		if err != nil {
+			return errs.Wrapf(err, "failed to load the type of work item %s", wi.ID)
+		}
+		if targetTypeID == nil {
+			targetTypeID, err = mapWorkItemTypeToSpace(ctx, appl, *oldType, *targetSpace)
+			if err != nil {
+				return err
+			}
+		}
+		wit, err = appl.WorkItemTypes().Load(ctx, *targetTypeID)
+		if err != nil {
+			return errors.NewBadParameterError("data.relationships.baseType", *targetTypeID)
+		}
+		if wit.SpaceTemplateID != targetSpace.SpaceTemplateID {
+			return errors.NewBadParameterError("data.relationships.baseType", *targetTypeID).Expected("work item type of the target space template")
+		}
+		mapper, err := newSpaceFieldMapper(ctx, appl, wi.SpaceID, targetSpaceID)
+		if err != nil {
+			return err
+		}
+		fields := mapper.mapFields(convertFieldsToType(*oldType, *wit, wi.Fields))
+		wi, err = appl.WorkItems().Move(ctx, wi.ID, targetSpaceID, *targetTypeID, fields, *currentUserIdentityID)
+		if err != nil {
+			return errs.Wrapf(err, "failed to move work item %s", ctx.WiID)
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	hasChildren := workItemIncludeHasChildren(ctx, c.db)
+	wi2, err := ConvertWorkItem(ctx.Request, *wit, *wi, hasChildren)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	ctx.ResponseData.Header().Set("Last-Modified", lastModified(*wi))
+	// the subscribers of the source space won't see the work item anymore
+	c.notification.Send(ctx, notification.NewWorkItemDeleted(sourceSpaceID, wi.ID.String()))
+	c.notification.Send(ctx, notification.NewWorkItemUpdated(wi.SpaceID, wi.ID.String()))
+	return ctx.OK(&app.WorkItemSingle{Data: wi2})
+}
+
+// mapWorkItemTypeToSpace returns the ID of the work item type to use in the
+// given space for work items of the given type: the type itself if the space
+// uses its space template, otherwise the constructable type with the same name
+// or, if there is none, the first constructable type of the space template.
+func mapWorkItemTypeToSpace(ctx context.Context, appl application.Application, wit workitem.WorkItemType, target space.Space) (*uuid.UUID, error) {
+	if wit.SpaceTemplateID == target.SpaceTemplateID {
+		return &wit.ID, nil
+	}
+	types, err := appl.WorkItemTypes().List(ctx, target.SpaceTemplateID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to list the work item types of space template %s", target.SpaceTemplateID)
+	}
+	var fallback *uuid.UUID
+	for i := range types {
+		if !types[i].CanConstruct {
+			continue
+		}
+		if types[i].Name == wit.Name {
+			return &types[i].ID, nil
+		}
+		if fallback == nil {
+			fallback = &types[i].ID
+		}
+	}
+	if fallback == nil {
+		return nil, errors.NewBadParameterError("space", target.ID).Expected("space with a constructable work item type")
+	}
+	return fallback, nil
+}
+
+// convertFieldsToType returns the field values of a work item of type "from"
+// that can be kept when it becomes a work item of type "to". Values are kept
+// for fields of the same kind that are still valid for the new type, all other
+// fields get their default value.
+func convertFieldsToType(from, to workitem.WorkItemType, fields map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(to.Fields))
+	for name, def := range to.Fields {
+		v, ok := fields[name]
+		if !ok || v == nil || def.ReadOnly {
+			continue
+		}
+		oldDef, ok := from.Fields[name]
+		if !ok || oldDef.Type.GetKind() != def.Type.GetKind() {
+			continue
+		}
+		if from.ID != to.ID {
+			if _, err := def.ConvertToModel(name, v); err != nil {
+				continue
+			}
+		}
+		res[name] = v
+	}
+	return res
+}
@@ -0,0 +1,97 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemMoveREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunWorkItemMoveREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWorkItemMoveREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestWorkItemMoveREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func movePayload(spaceID uuid.UUID) *app.WorkItemMoveSingle {
+	return &app.WorkItemMoveSingle{
+		Data: &app.WorkItemMove{
+			Type: "workitemmoves",
+			Relationships: &app.WorkItemMoveRelations{
+				Space: &app.RelationGeneric{Data: &app.GenericData{ID: ptr.String(spaceID.String())}},
+			},
+		},
+	}
+}
+
+func (s *TestWorkItemMoveREST) TestMove() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.Spaces(2),
+			tf.WorkItems(2, tf.SetWorkItemTitles("parent", "child")),
+			tf.WorkItemLinks(1, func(fxt *tf.TestFixture, idx int) error {
+				l := fxt.WorkItemLinks[idx]
+				l.LinkTypeID = link.SystemWorkItemLinkTypeParentChildID
+				l.SourceID = fxt.WorkItems[0].ID
+				l.TargetID = fxt.WorkItems[1].ID
+				return nil
+			}),
+		)
+		svc := testsupport.ServiceAsSpaceUser("Move-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewWorkItemMoveController(svc, s.db)
+		_, wi := test.MoveWorkItemMoveOK(t, svc.Context, svc, ctrl, fxt.WorkItems[1].ID, movePayload(fxt.Spaces[1].ID))
+		require.NotNil(t, wi.Data)
+		assert.Equal(t, fxt.WorkItems[1].ID, *wi.Data.ID)
+		assert.Equal(t, fxt.Spaces[1].ID, *wi.Data.Relationships.Space.Data.ID)
+		assert.Equal(t, "child", wi.Data.Attributes[workitem.SystemTitle])
+		// the link to the parent is kept
+		links, err := s.db.WorkItemLinks().ListByWorkItem(svc.Context, fxt.WorkItems[1].ID)
+		require.NoError(t, err)
+		require.Len(t, links, 1)
+		assert.Equal(t, fxt.WorkItems[0].ID, links[0].SourceID)
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(2), tf.WorkItems(1))
+		svc := goa.New("Move-Service")
+		ctrl := NewWorkItemMoveController(svc, s.db)
+		test.MoveWorkItemMoveUnauthorized(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, movePayload(fxt.Spaces[1].ID))
+	})
+
+	s.T().Run("unknown target space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		svc := testsupport.ServiceAsSpaceUser("Move-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewWorkItemMoveController(svc, s.db)
+		test.MoveWorkItemMoveNotFound(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, movePayload(uuid.NewV4()))
+	})
+
+	s.T().Run("same space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		svc := testsupport.ServiceAsSpaceUser("Move-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewWorkItemMoveController(svc, s.db)
+		test.MoveWorkItemMoveBadRequest(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, movePayload(fxt.Spaces[0].ID))
+	})
+}
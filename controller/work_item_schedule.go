@@ -0,0 +1,269 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/fabric8-services/fabric8-wit/workitem/schedule"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeWorkItemSchedule helps to avoid string literal
+const APIStringTypeWorkItemSchedule = "workitemschedules"
+
+// WorkItemScheduleController implements the work_item_schedule resource.
+type WorkItemScheduleController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemScheduleController creates a work_item_schedule controller.
+func NewWorkItemScheduleController(service *goa.Service, db application.DB) *WorkItemScheduleController {
+	return &WorkItemScheduleController{
+		Controller: service.NewController("WorkItemScheduleController"),
+		db:         db,
+	}
+}
+
+// Show runs the show action.
+func (c *WorkItemScheduleController) Show(ctx *app.ShowWorkItemScheduleContext) error {
+	var plan *schedule.Plan
+	var wis []workitem.WorkItem
+	var wits []workitem.WorkItemType
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.Spaces().CheckExists(ctx, ctx.SpaceID); err != nil {
+			return err
+		}
+		if ctx.FilterIteration != nil {
+			itr, err := appl.Iterations().Load(ctx, *ctx.FilterIteration)
+			if err != nil {
+				return err
+			}
+			if itr.SpaceID != ctx.SpaceID {
+				return errors.NewBadParameterError("filter[iteration]", *ctx.FilterIteration).Expected("iteration of the space")
+			}
+		}
+		var err error
+		plan, wis, err = loadWorkItemSchedule(ctx, appl, ctx.SpaceID, ctx.FilterIteration)
+		if err != nil {
+			return err
+		}
+		wits, err = loadWorkItemTypesFromArr(ctx, appl, wis)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	included, err := ConvertWorkItems(ctx.Request, wits, wis)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.WorkItemScheduleSingle{
+		Data:     ConvertWorkItemSchedule(ctx.Request, ctx.SpaceID, ctx.FilterIteration, *plan),
+		Included: make([]interface{}, 0, len(included)),
+	}
+	for _, wi := range included {
+		res.Included = append(res.Included, wi)
+	}
+	return ctx.OK(res)
+}
+
+// loadWorkItemSchedule computes the schedule of the work items of the given
+// space (or iteration and its child iterations, if not nil) that have
+// dependencies and returns it along with these work items in the order of the
+// schedule.
+func loadWorkItemSchedule(ctx context.Context, appl application.Application, spaceID uuid.UUID, iterationID *uuid.UUID) (*schedule.Plan, []workitem.WorkItem, error) {
+	links, err := appl.WorkItemLinks().ListByTopology(ctx, spaceID, link.TopologyDependency)
+	if err != nil {
+		return nil, nil, errs.Wrapf(err, "failed to list the dependencies of space %s", spaceID)
+	}
+	dependencies := make([]schedule.Dependency, len(links))
+	ids := []uuid.UUID{}
+	seen := map[uuid.UUID]struct{}{}
+	for i, l := range links {
+		dependencies[i] = schedule.Dependency{PredecessorID: l.SourceID, SuccessorID: l.TargetID}
+		for _, id := range []uuid.UUID{l.SourceID, l.TargetID} {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+	loaded, err := appl.WorkItems().LoadBatchByID(ctx, ids)
+	if err != nil {
+		return nil, nil, errs.Wrapf(err, "failed to load the work items with dependencies in space %s", spaceID)
+	}
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].Number < loaded[j].Number })
+
+	// the end of the iterations the work items are scheduled in
+	iterationIDs := []uuid.UUID{}
+	for _, wi := range loaded {
+		if id := workItemIterationID(*wi); id != nil {
+			iterationIDs = append(iterationIDs, *id)
+		}
+	}
+	iterations := map[uuid.UUID]iteration.Iteration{}
+	if len(iterationIDs) > 0 {
+		itrs, err := appl.Iterations().LoadMultiple(ctx, iterationIDs)
+		if err != nil {
+			return nil, nil, errs.Wrapf(err, "failed to load the iterations of the work items with dependencies in space %s", spaceID)
+		}
+		for _, itr := range itrs {
+			iterations[itr.ID] = itr
+		}
+	}
+
+	// the iterations whose work items are scheduled, when filtered
+	var scheduled map[uuid.UUID]struct{}
+	if iterationID != nil {
+		children, err := appl.Iterations().LoadChildren(ctx, *iterationID)
+		if err != nil {
+			return nil, nil, errs.Wrapf(err, "failed to load the child iterations of iteration %s", *iterationID)
+		}
+		scheduled = map[uuid.UUID]struct{}{*iterationID: {}}
+		for _, child := range children {
+			scheduled[child.ID] = struct{}{}
+		}
+	}
+
+	items := []schedule.Item{}
+	known := make(map[uuid.UUID]schedule.Item, len(loaded))
+	wis := make(map[uuid.UUID]workitem.WorkItem, len(loaded))
+	for _, wi := range loaded {
+		item := schedule.Item{
+			ID:     wi.ID,
+			Effort: 1,
+		}
+		if state, ok := wi.Fields[workitem.SystemState].(string); ok {
+			item.Finished = schedule.IsFinishedState(state)
+		}
+		switch effort := wi.Fields[schedule.EffortField].(type) {
+		case float64:
+			item.Effort = effort
+		case int:
+			item.Effort = float64(effort)
+		}
+		item.IterationID = workItemIterationID(*wi)
+		if item.IterationID != nil {
+			if itr, ok := iterations[*item.IterationID]; ok {
+				item.IterationEnd = itr.EndAt
+			}
+		}
+		known[wi.ID] = item
+		wis[wi.ID] = *wi
+		if scheduled == nil {
+			items = append(items, item)
+		} else if item.IterationID != nil {
+			if _, ok := scheduled[*item.IterationID]; ok {
+				items = append(items, item)
+			}
+		}
+	}
+	plan, err := schedule.NewPlan(items, known, dependencies)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := make([]workitem.WorkItem, len(plan.Order))
+	for i, id := range plan.Order {
+		result[i] = wis[id]
+	}
+	return plan, result, nil
+}
+
+// workItemIterationID returns the ID of the iteration of the given work item,
+// if any.
+func workItemIterationID(wi workitem.WorkItem) *uuid.UUID {
+	s, ok := wi.Fields[workitem.SystemIteration].(string)
+	if !ok {
+		return nil
+	}
+	id, err := uuid.FromString(s)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// ConvertWorkItemSchedule converts from internal to external REST representation
+func ConvertWorkItemSchedule(request *http.Request, spaceID uuid.UUID, iterationID *uuid.UUID, plan schedule.Plan) *app.WorkItemSchedule {
+	id := spaceID
+	spaceIDStr := spaceID.String()
+	selfURL := rest.AbsoluteURL(request, app.WorkItemScheduleHref(spaceID))
+	spaceRelatedURL := rest.AbsoluteURL(request, app.SpaceHref(spaceIDStr))
+	res := &app.WorkItemSchedule{
+		Type: APIStringTypeWorkItemSchedule,
+		Attributes: &app.WorkItemScheduleAttributes{
+			Order:              plan.Order,
+			Dependencies:       make([]*app.WorkItemDependency, len(plan.Dependencies)),
+			CriticalPath:       plan.CriticalPath,
+			CriticalPathEffort: ptr.Float64(plan.CriticalPathEffort),
+			Blocked:            make([]*app.WorkItemBlocked, len(plan.Blocked)),
+			Conflicts:          make([]*app.WorkItemScheduleConflict, len(plan.Conflicts)),
+		},
+		Relationships: &app.WorkItemScheduleRelations{
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(space.SpaceType),
+					ID:   &spaceIDStr,
+					Links: &app.GenericLinks{
+						Related: &spaceRelatedURL,
+					},
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+	for i, dep := range plan.Dependencies {
+		res.Attributes.Dependencies[i] = &app.WorkItemDependency{
+			Predecessor: dep.PredecessorID,
+			Successor:   dep.SuccessorID,
+		}
+	}
+	for i, b := range plan.Blocked {
+		res.Attributes.Blocked[i] = &app.WorkItemBlocked{
+			Workitem:  b.ID,
+			BlockedBy: b.BlockedBy,
+		}
+	}
+	for i, conflict := range plan.Conflicts {
+		res.Attributes.Conflicts[i] = &app.WorkItemScheduleConflict{
+			Workitem:            conflict.ID,
+			Iteration:           conflict.IterationID,
+			Dependency:          conflict.DependencyID,
+			DependencyIteration: conflict.DependencyIterationID,
+		}
+	}
+	if iterationID != nil {
+		id = *iterationID
+		iterationIDStr := iterationID.String()
+		iterationRelatedURL := rest.AbsoluteURL(request, app.IterationHref(iterationIDStr))
+		selfURL += "?filter[iteration]=" + iterationIDStr
+		res.Links.Self = &selfURL
+		res.Relationships.Iteration = &app.RelationGeneric{
+			Data: &app.GenericData{
+				Type: ptr.String(iteration.APIStringTypeIteration),
+				ID:   &iterationIDStr,
+				Links: &app.GenericLinks{
+					Related: &iterationRelatedURL,
+				},
+			},
+		}
+	}
+	res.ID = &id
+	return res
+}
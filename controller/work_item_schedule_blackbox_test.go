@@ -0,0 +1,116 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemScheduleREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunWorkItemScheduleREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWorkItemScheduleREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestWorkItemScheduleREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func (s *TestWorkItemScheduleREST) TestShow() {
+	s.T().Run("ok", func(t *testing.T) {
+		// C depends on B which depends on A, A is closed
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.WorkItemLinkTypes(1, tf.SetTopologies(link.TopologyDependency)),
+			tf.WorkItems(4, tf.SetWorkItemTitles("C", "B", "A", "unrelated"), tf.SetWorkItemField(workitem.SystemState, workitem.SystemStateOpen, workitem.SystemStateOpen, workitem.SystemStateClosed, workitem.SystemStateOpen)),
+			tf.WorkItemLinksCustom(2, tf.BuildLinks(tf.LinkChain("A", "B", "C")...)),
+		)
+		svc := testsupport.ServiceAsUser("Schedule-Service", *fxt.Identities[0])
+		ctrl := NewWorkItemScheduleController(svc, s.db)
+		_, res := test.ShowWorkItemScheduleOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, nil)
+		a, b, c := fxt.WorkItemByTitle("A").ID, fxt.WorkItemByTitle("B").ID, fxt.WorkItemByTitle("C").ID
+		assert.Equal(t, []uuid.UUID{a, b, c}, res.Data.Attributes.Order)
+		assert.Len(t, res.Data.Attributes.Dependencies, 2)
+		assert.Equal(t, []uuid.UUID{a, b, c}, res.Data.Attributes.CriticalPath)
+		assert.Equal(t, float64(2), *res.Data.Attributes.CriticalPathEffort)
+		require.Len(t, res.Data.Attributes.Blocked, 1)
+		assert.Equal(t, c, res.Data.Attributes.Blocked[0].Workitem)
+		assert.Equal(t, []uuid.UUID{b}, res.Data.Attributes.Blocked[0].BlockedBy)
+		assert.Empty(t, res.Data.Attributes.Conflicts)
+		assert.Len(t, res.Included, 3)
+	})
+
+	s.T().Run("cycle", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.WorkItemLinkTypes(1, tf.SetTopologies(link.TopologyDependency)),
+			tf.WorkItems(2, tf.SetWorkItemTitles("A", "B")),
+			tf.WorkItemLinksCustom(2, tf.BuildLinks(tf.L("A", "B"), tf.L("B", "A"))),
+		)
+		svc := testsupport.ServiceAsUser("Schedule-Service", *fxt.Identities[0])
+		ctrl := NewWorkItemScheduleController(svc, s.db)
+		test.ShowWorkItemScheduleConflict(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, nil)
+	})
+
+	s.T().Run("iteration with child iterations", func(t *testing.T) {
+		// A is in a grandchild of the filtered iteration, B in a child and C
+		// in another iteration
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.Iterations(4, tf.SetIterationNames("parent", "child", "grandchild", "other"), func(fxt *tf.TestFixture, idx int) error {
+				switch idx {
+				case 1:
+					fxt.Iterations[idx].MakeChildOf(*fxt.Iterations[0])
+				case 2:
+					fxt.Iterations[idx].MakeChildOf(*fxt.Iterations[1])
+				}
+				return nil
+			}),
+			tf.WorkItemLinkTypes(1, tf.SetTopologies(link.TopologyDependency)),
+			tf.WorkItems(3, tf.SetWorkItemTitles("A", "B", "C"), func(fxt *tf.TestFixture, idx int) error {
+				itr := []string{"grandchild", "child", "other"}[idx]
+				fxt.WorkItems[idx].Fields[workitem.SystemIteration] = fxt.IterationByName(itr).ID.String()
+				return nil
+			}),
+			tf.WorkItemLinksCustom(2, tf.BuildLinks(tf.LinkChain("A", "B", "C")...)),
+		)
+		svc := testsupport.ServiceAsUser("Schedule-Service", *fxt.Identities[0])
+		ctrl := NewWorkItemScheduleController(svc, s.db)
+		a, b := fxt.WorkItemByTitle("A").ID, fxt.WorkItemByTitle("B").ID
+		_, res := test.ShowWorkItemScheduleOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, &fxt.IterationByName("parent").ID)
+		assert.Equal(t, []uuid.UUID{a, b}, res.Data.Attributes.Order)
+		_, res = test.ShowWorkItemScheduleOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, &fxt.IterationByName("grandchild").ID)
+		assert.Equal(t, []uuid.UUID{a}, res.Data.Attributes.Order)
+	})
+
+	s.T().Run("iteration of another space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(2), tf.Iterations(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.Iterations[idx].SpaceID = fxt.Spaces[1].ID
+			return nil
+		}))
+		svc := testsupport.ServiceAsUser("Schedule-Service", *fxt.Identities[0])
+		ctrl := NewWorkItemScheduleController(svc, s.db)
+		test.ShowWorkItemScheduleBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, &fxt.Iterations[0].ID)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		svc := testsupport.ServiceAsUser("Schedule-Service", *fxt.Identities[0])
+		ctrl := NewWorkItemScheduleController(svc, s.db)
+		test.ShowWorkItemScheduleNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), nil)
+	})
+}
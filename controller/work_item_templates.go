@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/template"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WorkItemTemplatesController implements the work_item_templates resource.
+type WorkItemTemplatesController struct {
+	*goa.Controller
+	db     application.DB
+	config WorkItemTemplatesControllerConfig
+}
+
+// WorkItemTemplatesControllerConfig the config interface for the WorkItemTemplatesController
+type WorkItemTemplatesControllerConfig interface {
+	GetCacheControlWorkItemTemplates() string
+}
+
+// NewWorkItemTemplatesController creates a work_item_templates controller.
+func NewWorkItemTemplatesController(service *goa.Service, db application.DB, config WorkItemTemplatesControllerConfig) *WorkItemTemplatesController {
+	return &WorkItemTemplatesController{
+		Controller: service.NewController("WorkItemTemplatesController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// List runs the list action.
+func (c *WorkItemTemplatesController) List(ctx *app.ListWorkItemTemplatesContext) error {
+	var templates []template.Template
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.Spaces().CheckExists(ctx, ctx.SpaceID); err != nil {
+			return err
+		}
+		var err error
+		templates, err = appl.WorkItemTemplates().List(ctx, ctx.SpaceID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.ConditionalEntities(templates, c.config.GetCacheControlWorkItemTemplates, func() error {
+		res := &app.WorkItemTemplateList{
+			Data: make([]*app.WorkItemTemplate, 0, len(templates)),
+		}
+		for _, t := range templates {
+			res.Data = append(res.Data, ConvertWorkItemTemplate(ctx.Request, t))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Show runs the show action.
+func (c *WorkItemTemplatesController) Show(ctx *app.ShowWorkItemTemplatesContext) error {
+	var t *template.Template
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		t, err = loadSpaceWorkItemTemplate(ctx, appl, ctx.SpaceID, ctx.TemplateID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.ConditionalRequest(*t, c.config.GetCacheControlWorkItemTemplates, func() error {
+		return ctx.OK(&app.WorkItemTemplateSingle{
+			Data: ConvertWorkItemTemplate(ctx.Request, *t),
+		})
+	})
+}
+
+// Create runs the create action.
+func (c *WorkItemTemplatesController) Create(ctx *app.CreateWorkItemTemplatesContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil || ctx.Payload.Data.Attributes.Name == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
+	}
+	rel := ctx.Payload.Data.Relationships
+	if rel == nil || rel.Workitem == nil || rel.Workitem.Data == nil || rel.Workitem.Data.ID == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.workitem", nil).Expected("not nil"))
+	}
+	rootID, err := uuid.FromString(*rel.Workitem.Data.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.workitem.data.id", *rel.Workitem.Data.ID).Expected("valid UUID"))
+	}
+	t := template.Template{
+		SpaceID:     ctx.SpaceID,
+		Name:        strings.TrimSpace(*ctx.Payload.Data.Attributes.Name),
+		Description: ctx.Payload.Data.Attributes.Description,
+		CreatorID:   *currentUserIdentityID,
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.EditWorkItems); err != nil {
+			return err
+		}
+		root, err := appl.WorkItems().LoadByID(ctx, rootID)
+		if err != nil {
+			return err
+		}
+		if root.SpaceID != ctx.SpaceID {
+			return errors.NewBadParameterError("data.relationships.workitem", rootID).Expected(fmt.Sprintf("work item in space %s", ctx.SpaceID))
+		}
+		tree, err := loadWorkItemTree(ctx, appl, *root, true)
+		if err != nil {
+			return errs.Wrapf(err, "failed to load the work item %s to save as a template", rootID)
+		}
+		t.Root = *tree
+		return appl.WorkItemTemplates().Create(ctx, &t)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.WorkItemTemplateSingle{
+		Data: ConvertWorkItemTemplate(ctx.Request, t),
+	}
+	ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.Request, app.WorkItemTemplatesHref(ctx.SpaceID, t.ID)))
+	return ctx.Created(res)
+}
+
+// Delete runs the delete action.
+func (c *WorkItemTemplatesController) Delete(ctx *app.DeleteWorkItemTemplatesContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.EditWorkItems); err != nil {
+			return err
+		}
+		if _, err := loadSpaceWorkItemTemplate(ctx, appl, ctx.SpaceID, ctx.TemplateID); err != nil {
+			return err
+		}
+		return appl.WorkItemTemplates().Delete(ctx, ctx.TemplateID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// Instantiate runs the instantiate action. The template may belong to any
+// space that uses the same space template as the space in the URL.
+func (c *WorkItemTemplatesController) Instantiate(ctx *app.InstantiateWorkItemTemplatesContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	values := map[string]string{}
+	if ctx.Payload != nil && ctx.Payload.Data != nil && ctx.Payload.Data.Attributes != nil && ctx.Payload.Data.Attributes.Placeholders != nil {
+		values = ctx.Payload.Data.Attributes.Placeholders
+	}
+	var wi *workitem.WorkItem
+	var wit *workitem.WorkItemType
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.EditWorkItems); err != nil {
+			return err
+		}
+		t, err := appl.WorkItemTemplates().Load(ctx, ctx.TemplateID)
+		if err != nil {
+			return err
+		}
+		if err := checkSpaceTemplateCompatible(ctx, appl, t.SpaceID, ctx.SpaceID); err != nil {
+			return err
+		}
+		root, err := t.Root.Substitute(values)
+		if err != nil {
+			return err
+		}
+		mapper, err := newSpaceFieldMapper(ctx, appl, t.SpaceID, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		wi, err = createWorkItemTree(ctx, appl, *root, ctx.SpaceID, *currentUserIdentityID, mapper)
+		if err != nil {
+			return errs.Wrapf(err, "failed to instantiate the work item template %s", t.ID)
+		}
+		wit, err = appl.WorkItemTypes().Load(ctx, wi.Type)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	hasChildren := workItemIncludeHasChildren(ctx, c.db)
+	wi2, err := ConvertWorkItem(ctx.Request, *wit, *wi, hasChildren)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	ctx.ResponseData.Header().Set("Last-Modified", lastModified(*wi))
+	ctx.ResponseData.Header().Set("Location", app.WorkitemHref(wi2.ID))
+	return ctx.Created(&app.WorkItemSingle{Data: wi2})
+}
+
+// loadSpaceWorkItemTemplate loads the given work item template and returns a
+// NotFoundError if it doesn't belong to the given space.
+func loadSpaceWorkItemTemplate(ctx context.Context, appl application.Application, spaceID, templateID uuid.UUID) (*template.Template, error) {
+	t, err := appl.WorkItemTemplates().Load(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if t.SpaceID != spaceID {
+		return nil, errors.NewNotFoundError("work item template", templateID.String())
+	}
+	return t, nil
+}
+
+// ConvertWorkItemTemplate converts from internal to external REST representation
+func ConvertWorkItemTemplate(request *http.Request, t template.Template) *app.WorkItemTemplate {
+	spaceID := t.SpaceID.String()
+	selfURL := rest.AbsoluteURL(request, app.WorkItemTemplatesHref(spaceID, t.ID))
+	spaceRelatedURL := rest.AbsoluteURL(request, app.SpaceHref(spaceID))
+	creatorRelatedURL := rest.AbsoluteURL(request, fmt.Sprintf("%s/%s", usersEndpoint, t.CreatorID))
+	return &app.WorkItemTemplate{
+		Type: template.APIStringTypeWorkItemTemplates,
+		ID:   &t.ID,
+		Attributes: &app.WorkItemTemplateAttributes{
+			Name:         &t.Name,
+			Description:  t.Description,
+			Placeholders: t.Root.Placeholders(),
+			Size:         ptr.Int(t.Root.Size()),
+			CreatedAt:    ptr.Time(t.CreatedAt.UTC()),
+			UpdatedAt:    ptr.Time(t.UpdatedAt.UTC()),
+			Version:      &t.Version,
+		},
+		Relationships: &app.WorkItemTemplateRelations{
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(space.SpaceType),
+					ID:   &spaceID,
+					Links: &app.GenericLinks{
+						Related: &spaceRelatedURL,
+					},
+				},
+			},
+			Creator: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeUser),
+					ID:   ptr.String(t.CreatorID.String()),
+					Links: &app.GenericLinks{
+						Related: &creatorRelatedURL,
+					},
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
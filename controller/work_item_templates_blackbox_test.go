@@ -0,0 +1,195 @@
+package controller_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/fabric8-services/fabric8-wit/workitem/template"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemTemplatesREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunWorkItemTemplatesREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWorkItemTemplatesREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestWorkItemTemplatesREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+// epicFixture creates two spaces with the same space template and a parent
+// work item with two children in the first space.
+func (s *TestWorkItemTemplatesREST) epicFixture(t *testing.T) *tf.TestFixture {
+	return tf.NewTestFixture(t, s.DB,
+		tf.Spaces(2),
+		tf.WorkItems(3, tf.SetWorkItemTitles("epic", "task 1", "task 2")),
+		tf.WorkItemLinks(2, func(fxt *tf.TestFixture, idx int) error {
+			l := fxt.WorkItemLinks[idx]
+			l.LinkTypeID = link.SystemWorkItemLinkTypeParentChildID
+			l.SourceID = fxt.WorkItems[0].ID
+			l.TargetID = fxt.WorkItems[idx+1].ID
+			return nil
+		}),
+	)
+}
+
+func (s *TestWorkItemTemplatesREST) childTitles(t *testing.T, parentID uuid.UUID) []string {
+	children, _, err := s.db.WorkItemLinks().ListWorkItemChildren(context.Background(), parentID, nil, nil)
+	require.NoError(t, err)
+	titles := make([]string, 0, len(children))
+	for _, c := range children {
+		titles = append(titles, c.Fields[workitem.SystemTitle].(string))
+	}
+	return titles
+}
+
+func (s *TestWorkItemTemplatesREST) TestClone() {
+	s.T().Run("shallow into the same space", func(t *testing.T) {
+		fxt := s.epicFixture(t)
+		svc := testsupport.ServiceAsSpaceUser("Clone-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewWorkItemCloneController(svc, s.db)
+		payload := app.WorkItemCloneSingle{Data: &app.WorkItemClone{Type: "workitemclones"}}
+		_, wi := test.CloneWorkItemCloneCreated(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &payload)
+		require.NotNil(t, wi.Data)
+		assert.NotEqual(t, fxt.WorkItems[0].ID, *wi.Data.ID)
+		assert.Equal(t, "epic", wi.Data.Attributes[workitem.SystemTitle])
+		assert.Equal(t, fxt.Spaces[0].ID, *wi.Data.Relationships.Space.Data.ID)
+		assert.Empty(t, s.childTitles(t, *wi.Data.ID))
+	})
+
+	s.T().Run("deep into another space", func(t *testing.T) {
+		fxt := s.epicFixture(t)
+		svc := testsupport.ServiceAsSpaceUser("Clone-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewWorkItemCloneController(svc, s.db)
+		payload := app.WorkItemCloneSingle{
+			Data: &app.WorkItemClone{
+				Type:       "workitemclones",
+				Attributes: &app.WorkItemCloneAttributes{Deep: ptr.Bool(true)},
+				Relationships: &app.WorkItemCloneRelations{
+					Space: &app.RelationGeneric{Data: &app.GenericData{ID: ptr.String(fxt.Spaces[1].ID.String())}},
+				},
+			},
+		}
+		_, wi := test.CloneWorkItemCloneCreated(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &payload)
+		assert.Equal(t, fxt.Spaces[1].ID, *wi.Data.Relationships.Space.Data.ID)
+		assert.ElementsMatch(t, []string{"task 1", "task 2"}, s.childTitles(t, *wi.Data.ID))
+	})
+
+	s.T().Run("incompatible space template", func(t *testing.T) {
+		fxt := s.epicFixture(t)
+		other := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		svc := testsupport.ServiceAsSpaceUser("Clone-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewWorkItemCloneController(svc, s.db)
+		payload := app.WorkItemCloneSingle{
+			Data: &app.WorkItemClone{
+				Type: "workitemclones",
+				Relationships: &app.WorkItemCloneRelations{
+					Space: &app.RelationGeneric{Data: &app.GenericData{ID: ptr.String(other.Spaces[0].ID.String())}},
+				},
+			},
+		}
+		test.CloneWorkItemCloneBadRequest(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &payload)
+	})
+
+	s.T().Run("roles of the target space", func(t *testing.T) {
+		fxt := s.epicFixture(t)
+		user := tf.NewTestFixture(t, s.DB, tf.Identities(1)).Identities[0]
+		// the user is not a Keycloak collaborator of any space
+		svc := testsupport.ServiceAsSpaceUser("Clone-Service", *user, &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewWorkItemCloneController(svc, s.db)
+		payload := app.WorkItemCloneSingle{
+			Data: &app.WorkItemClone{
+				Type: "workitemclones",
+				Relationships: &app.WorkItemCloneRelations{
+					Space: &app.RelationGeneric{Data: &app.GenericData{ID: ptr.String(fxt.Spaces[1].ID.String())}},
+				},
+			},
+		}
+		// a viewer can't clone into the space
+		test.CloneWorkItemCloneForbidden(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &payload)
+		// a contributor given by a local role can
+		_, err := s.db.SpaceRoles().Assign(context.Background(), fxt.Spaces[1].ID, user.ID, role.Contributor)
+		require.NoError(t, err)
+		_, wi := test.CloneWorkItemCloneCreated(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &payload)
+		assert.Equal(t, fxt.Spaces[1].ID, *wi.Data.Relationships.Space.Data.ID)
+	})
+}
+
+func (s *TestWorkItemTemplatesREST) TestCreateAndInstantiate() {
+	fxt := s.epicFixture(s.T())
+	_, err := s.db.WorkItems().Save(context.Background(), fxt.Spaces[0].ID, func() workitem.WorkItem {
+		wi := *fxt.WorkItems[1]
+		wi.Fields[workitem.SystemTitle] = "{{component}}: write docs"
+		return wi
+	}(), fxt.Identities[0].ID)
+	require.NoError(s.T(), err)
+	svc := testsupport.ServiceAsSpaceUser("Templates-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+	ctrl := NewWorkItemTemplatesController(svc, s.db, s.Configuration)
+	payload := app.WorkItemTemplateSingle{
+		Data: &app.WorkItemTemplate{
+			Type:       template.APIStringTypeWorkItemTemplates,
+			Attributes: &app.WorkItemTemplateAttributes{Name: ptr.String("release")},
+			Relationships: &app.WorkItemTemplateRelations{
+				Workitem: &app.RelationGeneric{Data: &app.GenericData{ID: ptr.String(fxt.WorkItems[0].ID.String())}},
+			},
+		},
+	}
+	_, created := test.CreateWorkItemTemplatesCreated(s.T(), svc.Context, svc, ctrl, fxt.Spaces[0].ID, &payload)
+	require.NotNil(s.T(), created.Data)
+	assert.Equal(s.T(), 3, *created.Data.Attributes.Size)
+	assert.Equal(s.T(), []string{"component"}, created.Data.Attributes.Placeholders)
+
+	s.T().Run("list", func(t *testing.T) {
+		_, list := test.ListWorkItemTemplatesOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, nil, nil)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, *created.Data.ID, *list.Data[0].ID)
+	})
+
+	s.T().Run("instantiate in another space", func(t *testing.T) {
+		instantiation := app.WorkItemTemplateInstantiationSingle{
+			Data: &app.WorkItemTemplateInstantiation{
+				Type: "workitemtemplateinstantiations",
+				Attributes: &app.WorkItemTemplateInstantiationAttributes{
+					Placeholders: map[string]string{"component": "planner"},
+				},
+			},
+		}
+		_, wi := test.InstantiateWorkItemTemplatesCreated(t, svc.Context, svc, ctrl, fxt.Spaces[1].ID, *created.Data.ID, &instantiation)
+		assert.Equal(t, "epic", wi.Data.Attributes[workitem.SystemTitle])
+		assert.Equal(t, fxt.Spaces[1].ID, *wi.Data.Relationships.Space.Data.ID)
+		assert.ElementsMatch(t, []string{"planner: write docs", "task 2"}, s.childTitles(t, *wi.Data.ID))
+	})
+
+	s.T().Run("missing placeholder value", func(t *testing.T) {
+		instantiation := app.WorkItemTemplateInstantiationSingle{
+			Data: &app.WorkItemTemplateInstantiation{Type: "workitemtemplateinstantiations"},
+		}
+		test.InstantiateWorkItemTemplatesBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, *created.Data.ID, &instantiation)
+	})
+
+	s.T().Run("delete", func(t *testing.T) {
+		test.DeleteWorkItemTemplatesNoContent(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, *created.Data.ID)
+		test.ShowWorkItemTemplatesNotFound(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, *created.Data.ID, nil, nil)
+	})
+}
@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/watcher"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WorkItemWatchersController implements the work_item_watchers resource.
+type WorkItemWatchersController struct {
+	*goa.Controller
+	db     application.DB
+	config WorkItemWatchersControllerConfig
+}
+
+// WorkItemWatchersControllerConfig the config interface for the WorkItemWatchersController
+type WorkItemWatchersControllerConfig interface {
+	GetCacheControlWatchers() string
+}
+
+// NewWorkItemWatchersController creates a work_item_watchers controller.
+func NewWorkItemWatchersController(service *goa.Service, db application.DB, config WorkItemWatchersControllerConfig) *WorkItemWatchersController {
+	return &WorkItemWatchersController{
+		Controller: service.NewController("WorkItemWatchersController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// List runs the list action.
+func (c *WorkItemWatchersController) List(ctx *app.ListWorkItemWatchersContext) error {
+	var watchers []watcher.Watcher
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.WorkItems().CheckExists(ctx, ctx.WiID); err != nil {
+			return err
+		}
+		var err error
+		watchers, err = appl.Watchers().List(ctx, ctx.WiID)
+		return errs.Wrap(err, "failed to list the work item watchers")
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.ConditionalEntities(watchers, c.config.GetCacheControlWatchers, func() error {
+		res := &app.WatcherList{
+			Data: ConvertWatchers(ctx.Request, watchers),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Watch runs the watch action.
+func (c *WorkItemWatchersController) Watch(ctx *app.WatchWorkItemWatchersContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	var w *watcher.Watcher
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.WorkItems().CheckExists(ctx, ctx.WiID); err != nil {
+			return err
+		}
+		var err error
+		w, err = appl.Watchers().Watch(ctx, ctx.WiID, *currentUserIdentityID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.WatcherSingle{
+		Data: ConvertWatcher(ctx.Request, *w),
+	})
+}
+
+// Unwatch runs the unwatch action.
+func (c *WorkItemWatchersController) Unwatch(ctx *app.UnwatchWorkItemWatchersContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.WorkItems().CheckExists(ctx, ctx.WiID); err != nil {
+			return err
+		}
+		return appl.Watchers().Unwatch(ctx, ctx.WiID, *currentUserIdentityID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// ConvertWatchers converts from internal to external REST representation
+func ConvertWatchers(request *http.Request, watchers []watcher.Watcher) []*app.Watcher {
+	res := make([]*app.Watcher, 0, len(watchers))
+	for _, w := range watchers {
+		res = append(res, ConvertWatcher(request, w))
+	}
+	return res
+}
+
+// ConvertWatcher converts from internal to external REST representation
+func ConvertWatcher(request *http.Request, w watcher.Watcher) *app.Watcher {
+	identityRelatedURL := rest.AbsoluteURL(request, fmt.Sprintf("%s/%s", usersEndpoint, w.IdentityID))
+	workItemRelatedURL := rest.AbsoluteURL(request, app.WorkitemHref(w.WorkItemID))
+	selfURL := workItemRelatedURL + "/watchers"
+	reason := string(w.Reason)
+	return &app.Watcher{
+		Type: watcher.APIStringTypeWatchers,
+		ID:   &w.IdentityID,
+		Attributes: &app.WatcherAttributes{
+			Reason:    &reason,
+			CreatedAt: ptr.Time(w.CreatedAt.UTC()),
+		},
+		Relationships: &app.WatcherRelations{
+			Identity: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeUser),
+					ID:   ptr.String(w.IdentityID.String()),
+					Links: &app.GenericLinks{
+						Related: &identityRelatedURL,
+					},
+				},
+			},
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeWorkItem),
+					ID:   ptr.String(w.WorkItemID.String()),
+					Links: &app.GenericLinks{
+						Related: &workItemRelatedURL,
+					},
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// autoWatchWorkItem subscribes the creator (if given), the assignees and the
+// identities mentioned in the description of the given work item
+func autoWatchWorkItem(ctx context.Context, appl application.Application, wi workitem.WorkItem, creatorID *uuid.UUID) error {
+	watchers := appl.Watchers()
+	if creatorID != nil {
+		if err := watchers.AutoWatch(ctx, wi.ID, watcher.ReasonCreator, *creatorID); err != nil {
+			return err
+		}
+	}
+	if assignees, ok := wi.Fields[workitem.SystemAssignees].([]interface{}); ok {
+		assigneeIDs := make([]uuid.UUID, 0, len(assignees))
+		for _, a := range assignees {
+			if s, ok := a.(string); ok {
+				if id, err := uuid.FromString(s); err == nil {
+					assigneeIDs = append(assigneeIDs, id)
+				}
+			}
+		}
+		if err := watchers.AutoWatch(ctx, wi.ID, watcher.ReasonAssignee, assigneeIDs...); err != nil {
+			return err
+		}
+	}
+	if description := rendering.NewMarkupContentFromValue(wi.Fields[workitem.SystemDescription]); description != nil {
+		if err := autoWatchMentions(ctx, appl, wi.ID, description.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// autoWatchMentions subscribes the identities mentioned with "@username" in
+// the given text. Unknown usernames are ignored.
+func autoWatchMentions(ctx context.Context, appl application.Application, wiID uuid.UUID, text string) error {
+	var mentionedIDs []uuid.UUID
+	for _, username := range watcher.ParseMentions(text) {
+		identities, err := appl.Identities().Query(account.IdentityFilterByUsername(username))
+		if err != nil {
+			return errs.Wrapf(err, "failed to look up mentioned user %s", username)
+		}
+		if len(identities) == 0 {
+			log.Debug(ctx, map[string]interface{}{
+				"wi_id":    wiID,
+				"username": username,
+			}, "ignoring mention of unknown user")
+			continue
+		}
+		mentionedIDs = append(mentionedIDs, identities[0].ID)
+	}
+	return appl.Watchers().AutoWatch(ctx, wiID, watcher.ReasonMention, mentionedIDs...)
+}
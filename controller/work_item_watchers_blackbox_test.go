@@ -0,0 +1,99 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemWatchersREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunWorkItemWatchersREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWorkItemWatchersREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestWorkItemWatchersREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func (s *TestWorkItemWatchersREST) TestWatchAndUnwatch() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.WorkItems(1))
+		svc := testsupport.ServiceAsUser("Watchers-Service", *fxt.Identities[1])
+		ctrl := NewWorkItemWatchersController(svc, s.db, s.Configuration)
+		_, w := test.WatchWorkItemWatchersOK(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID)
+		require.NotNil(t, w.Data)
+		assert.Equal(t, fxt.Identities[1].ID, *w.Data.ID)
+		assert.Equal(t, "manual", *w.Data.Attributes.Reason)
+
+		_, list := test.ListWorkItemWatchersOK(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, nil, nil)
+		require.Len(t, list.Data, 1)
+
+		test.UnwatchWorkItemWatchersNoContent(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID)
+		_, list = test.ListWorkItemWatchersOK(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, nil, nil)
+		assert.Empty(t, list.Data)
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		svc := goa.New("Watchers-Service")
+		ctrl := NewWorkItemWatchersController(svc, s.db, s.Configuration)
+		test.WatchWorkItemWatchersUnauthorized(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID)
+	})
+
+	s.T().Run("unknown work item", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		svc := testsupport.ServiceAsUser("Watchers-Service", *fxt.Identities[0])
+		ctrl := NewWorkItemWatchersController(svc, s.db, s.Configuration)
+		test.WatchWorkItemWatchersNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+	})
+}
+
+func (s *TestWorkItemWatchersREST) TestAutoWatch() {
+	s.T().Run("creator and assignees", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.CreateWorkItemEnvironment(), tf.Identities(2))
+		svc := testsupport.ServiceAsSpaceUser("Watchers-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		workitemsCtrl := NewWorkitemsController(svc, s.db, s.Configuration)
+		ctrl := NewWorkItemWatchersController(svc, s.db, s.Configuration)
+		spaceSelfURL := rest.AbsoluteURL(&http.Request{Host: "api.service.domain.org"}, app.SpaceHref(fxt.Spaces[0].ID.String()))
+		payload := minimumRequiredCreateWithType(fxt.WorkItemTypes[0].ID)
+		payload.Data.Attributes[workitem.SystemTitle] = "watched"
+		payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+		payload.Data.Relationships.Space = app.NewSpaceRelation(fxt.Spaces[0].ID, spaceSelfURL)
+		assigneeID := fxt.Identities[1].ID.String()
+		payload.Data.Relationships.Assignees = &app.RelationGenericList{
+			Data: []*app.GenericData{{Type: ptr.String(APIStringTypeUser), ID: &assigneeID}},
+		}
+		_, wi := test.CreateWorkitemsCreated(t, svc.Context, svc, workitemsCtrl, fxt.Spaces[0].ID, &payload)
+
+		_, list := test.ListWorkItemWatchersOK(t, svc.Context, svc, ctrl, *wi.Data.ID, nil, nil)
+		require.Len(t, list.Data, 2)
+		reasons := map[uuid.UUID]string{}
+		for _, w := range list.Data {
+			reasons[*w.ID] = *w.Attributes.Reason
+		}
+		assert.Equal(t, "creator", reasons[fxt.Identities[0].ID])
+		assert.Equal(t, "assignee", reasons[fxt.Identities[1].ID])
+	})
+}
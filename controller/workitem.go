@@ -23,7 +23,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/notification"
 	"github.com/fabric8-services/fabric8-wit/rendering"
 	"github.com/fabric8-services/fabric8-wit/rest"
-	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 
 	"github.com/goadesign/goa"
@@ -71,16 +71,22 @@ func NewNotifyingWorkitemController(service *goa.Service, db application.DB, not
 		config:       config}
 }
 
-// Returns true if the user is the work item creator or space collaborator
+// Returns true if the user is the work item creator or if its role in the
+// space allows to edit work items
 func authorizeWorkitemEditor(ctx context.Context, db application.DB, spaceID uuid.UUID, creatorID string, editorID string) (bool, error) {
 	if editorID == creatorID {
 		return true, nil
 	}
-	authorized, err := authz.Authorize(ctx, spaceID.String())
+	var r role.Role
+	err := application.Transactional(db, func(appl application.Application) error {
+		var err error
+		r, err = currentSpaceRole(ctx, appl, spaceID)
+		return err
+	})
 	if err != nil {
-		return false, errors.NewUnauthorizedError(err.Error())
+		return false, err
 	}
-	return authorized, nil
+	return r.Can(role.EditWorkItems), nil
 }
 
 // Update does PATCH workitem
@@ -127,6 +133,9 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 		if err != nil {
 			return errs.Wrap(err, "Error updating work item")
 		}
+		if err := autoWatchWorkItem(ctx, appl, *wi, nil); err != nil {
+			return errs.Wrap(err, "failed to subscribe watchers to the work item")
+		}
 		return nil
 	})
 	if err != nil {
@@ -136,7 +145,7 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "failed to load work item type: %s", wi.Type))
 	}
-	c.notification.Send(ctx, notification.NewWorkItemUpdated(ctx.Payload.Data.ID.String()))
+	c.notification.Send(ctx, notification.NewWorkItemUpdated(wi.SpaceID, ctx.Payload.Data.ID.String()))
 	converted, err := ConvertWorkItem(ctx.Request, *wit, *wi, workItemIncludeHasChildren(ctx, c.db))
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
@@ -200,18 +209,11 @@ func (c *WorkitemController) Delete(ctx *app.DeleteWorkitemContext) error {
 		if err != nil {
 			return errs.Wrap(err, fmt.Sprintf("Failed to load work item with id %v", ctx.WiID))
 		}
-		return nil
+		return authorizeSpace(ctx, appl, wi.SpaceID, role.EditWorkItems)
 	})
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	authorized, err := authz.Authorize(ctx, wi.SpaceID.String())
-	if err != nil {
-		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
-	}
-	if !authorized {
-		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not authorized to access the space"))
-	}
 	err = application.Transactional(c.db, func(appl application.Application) error {
 		if err := appl.WorkItems().Delete(ctx, ctx.WiID, *currentUserIdentityID); err != nil {
 			return errs.Wrapf(err, "error deleting work item %s", ctx.WiID)
@@ -224,6 +226,7 @@ func (c *WorkitemController) Delete(ctx *app.DeleteWorkitemContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
+	c.notification.Send(ctx, notification.NewWorkItemDeleted(wi.SpaceID, ctx.WiID.String()))
 	return ctx.OK([]byte{})
 }
 
@@ -608,6 +611,8 @@ func ConvertWorkItem(request *http.Request, wit workitem.WorkItemType, wi workit
 	workItemIncludeComments(request, &wi, op)
 	workItemIncludeChildren(request, &wi, op)
 	workItemIncludeEvents(request, &wi, op)
+	workItemIncludeDeployments(request, &wi, op)
+	workItemIncludeCodeReferences(request, &wi, op)
 	for _, add := range additional {
 		if err := add(request, &wi, op); err != nil {
 			return nil, errs.Wrap(err, "failed to run additional conversion function")
@@ -753,6 +758,30 @@ func workItemIncludeEvents(request *http.Request, wi *workitem.WorkItem, wi2 *ap
 	}
 }
 
+// workItemIncludeDeployments adds relationship about the deployments that
+// delivered the workitem
+func workItemIncludeDeployments(request *http.Request, wi *workitem.WorkItem, wi2 *app.WorkItem) {
+	deploymentsRelated := rest.AbsoluteURL(request, app.WorkitemHref(wi.ID.String())) + "/deployments"
+	if wi2.Relationships.Deployments == nil {
+		wi2.Relationships.Deployments = &app.RelationGeneric{}
+	}
+	wi2.Relationships.Deployments.Links = &app.GenericLinks{
+		Related: &deploymentsRelated,
+	}
+}
+
+// workItemIncludeCodeReferences adds relationship about the commits and pull
+// requests referencing the workitem
+func workItemIncludeCodeReferences(request *http.Request, wi *workitem.WorkItem, wi2 *app.WorkItem) {
+	codeReferencesRelated := rest.AbsoluteURL(request, app.WorkitemHref(wi.ID.String())) + "/codereferences"
+	if wi2.Relationships.CodeReferences == nil {
+		wi2.Relationships.CodeReferences = &app.RelationGeneric{}
+	}
+	wi2.Relationships.CodeReferences.Links = &app.GenericLinks{
+		Related: &codeReferencesRelated,
+	}
+}
+
 func loadWorkItemTypesFromArr(ctx context.Context, appl application.Application, wis []workitem.WorkItem) ([]workitem.WorkItemType, error) {
 	wits := make([]workitem.WorkItemType, len(wis))
 	for idx, wi := range wis {
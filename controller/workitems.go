@@ -2,7 +2,9 @@ package controller
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/fabric8-services/fabric8-wit/app"
 	"github.com/fabric8-services/fabric8-wit/application"
@@ -16,8 +18,9 @@ import (
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/search"
 	"github.com/fabric8-services/fabric8-wit/space"
-	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/export"
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
@@ -81,12 +84,11 @@ func (c *WorkitemsController) Create(ctx *app.CreateWorkitemsContext) error {
 	spaceOwnerID := space.OwnerID.String()
 	// check both the "openshiftio" user and the "test" user from the test realm.
 	if "7b50ddb4-5e12-4031-bca7-3b88f92e2339" != spaceOwnerID && "ae68a343-c866-430c-b6ce-a36f0b38d8e5" != spaceOwnerID {
-		authorized, err := authz.Authorize(ctx, ctx.SpaceID.String())
+		err = application.Transactional(c.db, func(appl application.Application) error {
+			return authorizeSpace(ctx, appl, ctx.SpaceID, role.EditWorkItems)
+		})
 		if err != nil {
-			return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(ctx, err))
-		}
-		if !authorized {
-			return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not authorized to access the space"))
+			return jsonapi.JSONErrorResponse(ctx, err)
 		}
 	}
 	// ----
@@ -127,6 +129,9 @@ func (c *WorkitemsController) Create(ctx *app.CreateWorkitemsContext) error {
 		if err != nil {
 			return errs.Wrap(err, fmt.Sprintf("Error creating work item"))
 		}
+		if err := autoWatchWorkItem(ctx, appl, *wi, currentUserIdentityID); err != nil {
+			return errs.Wrap(err, "failed to subscribe watchers to the new work item")
+		}
 		return nil
 	})
 	if err != nil {
@@ -149,7 +154,7 @@ func (c *WorkitemsController) Create(ctx *app.CreateWorkitemsContext) error {
 	}
 	ctx.ResponseData.Header().Set("Last-Modified", lastModified(*wi))
 	ctx.ResponseData.Header().Set("Location", app.WorkitemHref(wi2.ID))
-	c.notification.Send(ctx, notification.NewWorkItemCreated(wi.ID.String()))
+	c.notification.Send(ctx, notification.NewWorkItemCreated(wi.SpaceID, wi.ID.String()))
 	return ctx.Created(resp)
 }
 
@@ -263,12 +268,11 @@ func (c *WorkitemsController) Reorder(ctx *app.ReorderWorkitemsContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
 	}
-	authorized, err := authz.Authorize(ctx, ctx.SpaceID.String())
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		return authorizeSpace(ctx, appl, ctx.SpaceID, role.EditWorkItems)
+	})
 	if err != nil {
-		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
-	}
-	if !authorized {
-		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not authorized to access the space"))
+		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Position == nil {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("missing payload element in request", nil))
@@ -317,8 +321,80 @@ func (c *WorkitemsController) Reorder(ctx *app.ReorderWorkitemsContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
+	for _, wi := range dataArray {
+		c.notification.Send(ctx, notification.NewWorkItemReordered(ctx.SpaceID, wi.ID.String()))
+	}
 	resp := &app.WorkItemReorder{
 		Data: dataArray,
 	}
 	return ctx.OK(resp)
 }
+
+// Export runs the export action. The rows are written as they are loaded, so
+// an error happening once the export has started can only be logged.
+func (c *WorkitemsController) Export(ctx *app.ExportWorkitemsContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if ctx.FilterExpression != nil && ctx.Query != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("query", *ctx.Query).Expected("no filter expression"))
+	}
+	filter := fmt.Sprintf(`{"space": "%s"}`, ctx.SpaceID)
+	var wits []workitem.WorkItemType
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		sp, err := appl.Spaces().Load(ctx, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		if err := authorizeSpace(ctx, appl, ctx.SpaceID, role.ReadSpace); err != nil {
+			return err
+		}
+		expression := ctx.FilterExpression
+		if ctx.Query != nil {
+			q, err := appl.Queries().Load(ctx, *ctx.Query, ctx.SpaceID)
+			if err != nil {
+				return err
+			}
+			if q.Creator != *currentUserIdentityID {
+				return errors.NewForbiddenError("user is not the query creator")
+			}
+			expression = &q.Fields
+		}
+		if expression != nil {
+			filter = fmt.Sprintf(`{"%s":[{"space": "%s" }, %s]}`, search.AND, ctx.SpaceID, *expression)
+		}
+		wits, err = appl.WorkItemTypes().List(ctx, sp.SpaceTemplateID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if _, _, err := search.ParseFilterString(ctx, filter); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("filter[expression]", filter))
+	}
+	var columns []string
+	if ctx.Columns != nil {
+		for _, column := range strings.Split(*ctx.Columns, ",") {
+			if column = strings.TrimSpace(column); column != "" {
+				columns = append(columns, column)
+			}
+		}
+	}
+	exporter, err := export.NewExporter(c.db, wits, columns)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	format := export.Format(ctx.Format)
+	ctx.ResponseData.Header().Set("Content-Type", format.ContentType())
+	ctx.ResponseData.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="workitems.%s"`, format))
+	ctx.ResponseData.WriteHeader(http.StatusOK)
+	if err := exporter.Export(ctx, ctx.ResponseData, format, filter); err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": ctx.SpaceID,
+			"filter":   filter,
+			"err":      err,
+		}, "failed to export the work items")
+	}
+	return nil
+}
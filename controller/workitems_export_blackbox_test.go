@@ -0,0 +1,141 @@
+package controller_test
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemsExportREST struct {
+	gormtestsupport.DBTestSuite
+	db *gormapplication.GormDB
+}
+
+func TestRunWorkItemsExportREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWorkItemsExportREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestWorkItemsExportREST) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func (s *TestWorkItemsExportREST) fixture(t *testing.T) *tf.TestFixture {
+	return tf.NewTestFixture(t, s.DB,
+		tf.Identities(2, tf.SetIdentityUsernames("alice", "bob")),
+		tf.WorkItems(3, func(fxt *tf.TestFixture, idx int) error {
+			wi := fxt.WorkItems[idx]
+			wi.Fields[workitem.SystemTitle] = fmt.Sprintf("item %d", idx)
+			if idx == 0 {
+				wi.Fields[workitem.SystemAssignees] = []string{fxt.IdentityByUsername("bob").ID.String()}
+			} else {
+				wi.Fields[workitem.SystemState] = workitem.SystemStateClosed
+			}
+			return nil
+		}),
+		tf.Queries(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.Queries[idx].Fields = fmt.Sprintf(`{"%s": "%s"}`, workitem.SystemState, workitem.SystemStateClosed)
+			return nil
+		}),
+	)
+}
+
+func (s *TestWorkItemsExportREST) TestExport() {
+	s.T().Run("ok", func(t *testing.T) {
+		t.Run("csv", func(t *testing.T) {
+			// given
+			fxt := s.fixture(t)
+			svc := testsupport.ServiceAsUser("WorkItemsExport-Service", *fxt.Identities[0])
+			ctrl := NewWorkitemsController(svc, s.db, s.Configuration)
+			filter := fmt.Sprintf(`{"%s": "%s"}`, workitem.SystemAssignees, fxt.IdentityByUsername("bob").ID)
+			// when
+			rw := test.ExportWorkitemsOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, ptr.String("system.title, system.assignees"), &filter, "csv", nil)
+			// then
+			assert.Equal(t, "text/csv; charset=utf-8", rw.Header().Get("Content-Type"))
+			assert.Equal(t, `attachment; filename="workitems.csv"`, rw.Header().Get("Content-Disposition"))
+			records, err := csv.NewReader(rw.(*httptest.ResponseRecorder).Body).ReadAll()
+			require.NoError(t, err)
+			assert.Equal(t, [][]string{
+				{workitem.SystemTitle, workitem.SystemAssignees},
+				{"item 0", "bob"},
+			}, records)
+		})
+
+		t.Run("ndjson of a saved query", func(t *testing.T) {
+			// given
+			fxt := s.fixture(t)
+			svc := testsupport.ServiceAsUser("WorkItemsExport-Service", *fxt.Identities[0])
+			ctrl := NewWorkitemsController(svc, s.db, s.Configuration)
+			// when
+			rw := test.ExportWorkitemsOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, ptr.String("system.title"), nil, "ndjson", &fxt.Queries[0].ID)
+			// then
+			assert.Equal(t, "application/x-ndjson", rw.Header().Get("Content-Type"))
+			lines := strings.Split(strings.TrimSpace(rw.(*httptest.ResponseRecorder).Body.String()), "\n")
+			assert.Equal(t, []string{`{"system.title":"item 2"}`, `{"system.title":"item 1"}`}, lines)
+		})
+	})
+
+	s.T().Run("bad request", func(t *testing.T) {
+		t.Run("unknown column", func(t *testing.T) {
+			// given
+			fxt := s.fixture(t)
+			svc := testsupport.ServiceAsUser("WorkItemsExport-Service", *fxt.Identities[0])
+			ctrl := NewWorkitemsController(svc, s.db, s.Configuration)
+			// when/then
+			test.ExportWorkitemsBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, ptr.String("system.title,foo"), nil, "csv", nil)
+		})
+
+		t.Run("invalid filter", func(t *testing.T) {
+			// given
+			fxt := s.fixture(t)
+			svc := testsupport.ServiceAsUser("WorkItemsExport-Service", *fxt.Identities[0])
+			ctrl := NewWorkitemsController(svc, s.db, s.Configuration)
+			// when/then
+			test.ExportWorkitemsBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, nil, ptr.String(`{"system.state": `), "csv", nil)
+		})
+
+		t.Run("filter and query", func(t *testing.T) {
+			// given
+			fxt := s.fixture(t)
+			svc := testsupport.ServiceAsUser("WorkItemsExport-Service", *fxt.Identities[0])
+			ctrl := NewWorkitemsController(svc, s.db, s.Configuration)
+			// when/then
+			test.ExportWorkitemsBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, nil, ptr.String(`{"system.state": "open"}`), "csv", &fxt.Queries[0].ID)
+		})
+	})
+
+	s.T().Run("forbidden query of another user", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		svc := testsupport.ServiceAsUser("WorkItemsExport-Service", *fxt.Identities[1])
+		ctrl := NewWorkitemsController(svc, s.db, s.Configuration)
+		// when/then
+		test.ExportWorkitemsForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, nil, nil, "csv", &fxt.Queries[0].ID)
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		svc := goa.New("WorkItemsExport-Service")
+		ctrl := NewWorkitemsController(svc, s.db, s.Configuration)
+		// when/then
+		test.ExportWorkitemsUnauthorized(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, nil, nil, "csv", nil)
+	})
+}
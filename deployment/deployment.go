@@ -0,0 +1,223 @@
+package deployment
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeDeployments helps to avoid string literal
+const APIStringTypeDeployments = "deployments"
+
+// Deployment is a version of an application built and deployed to an
+// environment, as reported by the CI of a space
+type Deployment struct {
+	ID          uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	SpaceID     uuid.UUID `sql:"type:uuid"`
+	AppName     string
+	Environment string
+	Version     string
+	BuildID     *string
+	CommitID    *string
+	DeployedAt  time.Time
+	// IdentityID is the identity that recorded the deployment
+	IdentityID *uuid.UUID `sql:"type:uuid"`
+	CreatedAt  time.Time
+	// WorkItemIDs are the work items delivered by the deployment
+	WorkItemIDs []uuid.UUID `gorm:"-"`
+}
+
+// TableName implements gorm.tabler
+func (d Deployment) TableName() string {
+	return "deployments"
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (d Deployment) GetETagData() []interface{} {
+	return []interface{}{d.ID, d.CreatedAt.Unix(), len(d.WorkItemIDs)}
+}
+
+// GetLastModified returns the last modification time
+func (d Deployment) GetLastModified() time.Time {
+	return d.CreatedAt.Truncate(time.Second)
+}
+
+// workItemDeployment links a deployment to a work item it delivered
+type workItemDeployment struct {
+	WorkItemID   uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	DeploymentID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+}
+
+// TableName implements gorm.tabler
+func (w workItemDeployment) TableName() string {
+	return "work_item_deployments"
+}
+
+// Repository describes interactions with the deployments
+type Repository interface {
+	// Create stores the given deployment along with the links to the work
+	// items it delivered, which must belong to the space of the deployment
+	Create(ctx context.Context, d *Deployment) error
+	// List returns the deployments of the given space, to the given
+	// environment if not empty, newest first
+	List(ctx context.Context, spaceID uuid.UUID, environment string) ([]Deployment, error)
+	// ListByWorkItem returns the deployments that delivered the given work
+	// item, newest first
+	ListByWorkItem(ctx context.Context, workItemID uuid.UUID) ([]Deployment, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for the
+// deployments.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create stores the given deployment and its work items
+func (r *GormRepository) Create(ctx context.Context, d *Deployment) error {
+	defer goa.MeasureSince([]string{"goa", "db", "deployment", "create"}, time.Now())
+	if strings.TrimSpace(d.AppName) == "" {
+		return errors.NewBadParameterError("app-name", d.AppName).Expected("non empty string")
+	}
+	if strings.TrimSpace(d.Environment) == "" {
+		return errors.NewBadParameterError("environment", d.Environment).Expected("non empty string")
+	}
+	if strings.TrimSpace(d.Version) == "" {
+		return errors.NewBadParameterError("version", d.Version).Expected("non empty string")
+	}
+	workItemIDs := uniqueIDs(d.WorkItemIDs)
+	if len(workItemIDs) > 0 {
+		var count int
+		err := r.db.Table("work_items").Where("id IN (?) AND space_id = ? AND deleted_at IS NULL", workItemIDs, d.SpaceID).Count(&count).Error
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"space_id": d.SpaceID,
+				"err":      err,
+			}, "unable to check the work items of the deployment")
+			return errors.NewInternalError(ctx, err)
+		}
+		if count != len(workItemIDs) {
+			return errors.NewBadParameterError("workitems", workItemIDs).Expected("work items of space " + d.SpaceID.String())
+		}
+	}
+	if d.ID == uuid.Nil {
+		d.ID = uuid.NewV4()
+	}
+	if d.DeployedAt.IsZero() {
+		d.DeployedAt = time.Now()
+	}
+	if err := r.db.Create(d).Error; err != nil {
+		if gormsupport.IsForeignKeyViolation(err, "deployments_space_id_fkey") {
+			return errors.NewNotFoundError("space", d.SpaceID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"space_id":    d.SpaceID,
+			"app_name":    d.AppName,
+			"environment": d.Environment,
+			"err":         err,
+		}, "unable to store the deployment")
+		return errors.NewInternalError(ctx, err)
+	}
+	for _, workItemID := range workItemIDs {
+		if err := r.db.Create(&workItemDeployment{WorkItemID: workItemID, DeploymentID: d.ID}).Error; err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"deployment_id": d.ID,
+				"wi_id":         workItemID,
+				"err":           err,
+			}, "unable to link the deployment to the work item")
+			return errors.NewInternalError(ctx, err)
+		}
+	}
+	d.WorkItemIDs = workItemIDs
+	log.Debug(ctx, map[string]interface{}{
+		"deployment_id": d.ID,
+		"space_id":      d.SpaceID,
+	}, "deployment created")
+	return nil
+}
+
+// List returns the deployments of the space
+func (r *GormRepository) List(ctx context.Context, spaceID uuid.UUID, environment string) ([]Deployment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "deployment", "list"}, time.Now())
+	db := r.db.Where("space_id = ?", spaceID)
+	if environment != "" {
+		db = db.Where("environment = ?", environment)
+	}
+	var res []Deployment
+	if err := db.Order("deployed_at DESC, created_at DESC").Find(&res).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id":    spaceID,
+			"environment": environment,
+			"err":         err,
+		}, "unable to list the deployments")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, r.loadWorkItemIDs(ctx, res)
+}
+
+// ListByWorkItem returns the deployments of the work item
+func (r *GormRepository) ListByWorkItem(ctx context.Context, workItemID uuid.UUID) ([]Deployment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "deployment", "list_by_workitem"}, time.Now())
+	var res []Deployment
+	err := r.db.Joins("JOIN work_item_deployments wid ON wid.deployment_id = deployments.id").
+		Where("wid.work_item_id = ?", workItemID).
+		Order("deployed_at DESC, created_at DESC").Find(&res).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"wi_id": workItemID,
+			"err":   err,
+		}, "unable to list the deployments of the work item")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, r.loadWorkItemIDs(ctx, res)
+}
+
+// loadWorkItemIDs sets the work items delivered by each of the given
+// deployments
+func (r *GormRepository) loadWorkItemIDs(ctx context.Context, deployments []Deployment) error {
+	if len(deployments) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(deployments))
+	index := make(map[uuid.UUID]int, len(deployments))
+	for i, d := range deployments {
+		ids[i] = d.ID
+		index[d.ID] = i
+	}
+	var links []workItemDeployment
+	if err := r.db.Where("deployment_id IN (?)", ids).Order("work_item_id").Find(&links).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to load the work items of the deployments")
+		return errors.NewInternalError(ctx, err)
+	}
+	for _, l := range links {
+		d := &deployments[index[l.DeploymentID]]
+		d.WorkItemIDs = append(d.WorkItemIDs, l.WorkItemID)
+	}
+	return nil
+}
+
+// uniqueIDs returns the given IDs without the duplicates, in order
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	res := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			res = append(res, id)
+		}
+	}
+	return res
+}
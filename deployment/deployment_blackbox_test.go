@@ -0,0 +1,111 @@
+package deployment_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/deployment"
+	errs "github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestDeploymentRepository struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunDeploymentRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestDeploymentRepository{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func newDeployment(spaceID uuid.UUID, environment string, workItemIDs ...uuid.UUID) *deployment.Deployment {
+	return &deployment.Deployment{
+		SpaceID:     spaceID,
+		AppName:     "myapp",
+		Environment: environment,
+		Version:     "1.0.2",
+		WorkItemIDs: workItemIDs,
+	}
+}
+
+func (s *TestDeploymentRepository) TestCreate() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(2))
+		repo := deployment.NewRepository(s.DB)
+		d := newDeployment(fxt.Spaces[0].ID, "production", fxt.WorkItems[0].ID, fxt.WorkItems[1].ID, fxt.WorkItems[0].ID)
+		require.NoError(t, repo.Create(context.Background(), d))
+		assert.NotEqual(t, uuid.Nil, d.ID)
+		assert.False(t, d.DeployedAt.IsZero(), "the deployment time must default to now")
+		// the duplicated work item is only linked once
+		assert.Equal(t, []uuid.UUID{fxt.WorkItems[0].ID, fxt.WorkItems[1].ID}, d.WorkItemIDs)
+	})
+
+	s.T().Run("missing version", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		d := newDeployment(fxt.Spaces[0].ID, "production")
+		d.Version = " "
+		err := deployment.NewRepository(s.DB).Create(context.Background(), d)
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.BadParameterError)
+		assert.True(t, ok)
+	})
+
+	s.T().Run("work item of another space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(2), tf.WorkItems(1))
+		err := deployment.NewRepository(s.DB).Create(context.Background(), newDeployment(fxt.Spaces[1].ID, "production", fxt.WorkItems[0].ID))
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.BadParameterError)
+		assert.True(t, ok)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		err := deployment.NewRepository(s.DB).Create(context.Background(), newDeployment(uuid.NewV4(), "production"))
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.NotFoundError)
+		assert.True(t, ok)
+	})
+}
+
+func (s *TestDeploymentRepository) TestList() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(3))
+	repo := deployment.NewRepository(s.DB)
+	stage := newDeployment(fxt.Spaces[0].ID, "stage", fxt.WorkItems[0].ID, fxt.WorkItems[1].ID)
+	stage.DeployedAt = time.Now().Add(-time.Hour)
+	require.NoError(s.T(), repo.Create(context.Background(), stage))
+	production := newDeployment(fxt.Spaces[0].ID, "production", fxt.WorkItems[0].ID)
+	require.NoError(s.T(), repo.Create(context.Background(), production))
+
+	s.T().Run("all environments", func(t *testing.T) {
+		res, err := repo.List(context.Background(), fxt.Spaces[0].ID, "")
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		assert.Equal(t, production.ID, res[0].ID)
+		assert.Equal(t, stage.ID, res[1].ID)
+		assert.Len(t, res[1].WorkItemIDs, 2)
+	})
+
+	s.T().Run("one environment", func(t *testing.T) {
+		res, err := repo.List(context.Background(), fxt.Spaces[0].ID, "production")
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, []uuid.UUID{fxt.WorkItems[0].ID}, res[0].WorkItemIDs)
+	})
+
+	s.T().Run("by work item", func(t *testing.T) {
+		res, err := repo.ListByWorkItem(context.Background(), fxt.WorkItems[0].ID)
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		assert.Equal(t, production.ID, res[0].ID)
+		res, err = repo.ListByWorkItem(context.Background(), fxt.WorkItems[2].ID)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+}
@@ -0,0 +1,3 @@
+// Package deployment contains the records of the deployments reported by the
+// CI of a space and the work items they delivered.
+package deployment
@@ -0,0 +1,94 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var codeReference = a.Type("CodeReference", func() {
+	a.Description(`JSONAPI store for the data of a commit or a pull request referencing a work item. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("code-references")
+	})
+	a.Attribute("id", d.UUID, "ID of the code reference", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", codeReferenceAttributes)
+	a.Attribute("relationships", codeReferenceRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var codeReferenceAttributes = a.Type("CodeReferenceAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a code reference. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("kind", d.String, "Whether the work item is referenced by a commit or a pull request", func() {
+		a.Enum("commit", "pullrequest")
+	})
+	a.Attribute("ref", d.String, "ID of the commit or number of the pull request", func() {
+		a.Example("6f5a2b2c1d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a")
+	})
+	a.Attribute("title", d.String, "First line of the commit message or title of the pull request", func() {
+		a.Example("Fix the login page (#42)")
+	})
+	a.Attribute("url", d.String, "URL of the commit or of the pull request", func() {
+		a.Example("https://github.com/fabric8-services/fabric8-wit/pull/1337")
+	})
+	a.Attribute("author", d.String, "Author of the commit or of the pull request", func() {
+		a.Example("jdoe")
+	})
+	a.Attribute("state", d.String, "State of the pull request (open, closed or merged), empty for a commit", func() {
+		a.Example("merged")
+	})
+	a.Attribute("created-at", d.DateTime, "When the reference was first received", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the reference was last received", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("kind", "ref")
+})
+
+var codeReferenceRelationships = a.Type("CodeReferenceRelations", func() {
+	a.Attribute("workitem", relationGeneric, "This defines the referenced work item")
+	a.Attribute("codebase", relationGeneric, "This defines the codebase of the commit or pull request")
+})
+
+var codeReferenceList = JSONList(
+	"CodeReference", "Holds the list of commits and pull requests referencing a work item",
+	codeReference,
+	nil,
+	nil)
+
+var _ = a.Resource("codebase_webhooks", func() {
+	a.BasePath("/codebases/webhooks")
+
+	a.Action("ingest", func() {
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description(`Receive the push and pull request events of the GitHub and GitLab repositories of the codebases.
+Work items mentioned as "space#42" or "owner/space#42" in the commit messages and pull requests are linked to them.
+The payload must be signed with the webhook secret of a codebase of the repository, see the "webhook" action of the codebases.`)
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("work_item_code_references", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("codereferences"),
+		)
+		a.Description("List the commits and pull requests referencing the given work item, most recently updated first")
+		a.UseTrait("conditional")
+		a.Response(d.OK, codeReferenceList)
+		a.Response(d.NotModified)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
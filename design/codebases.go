@@ -36,6 +36,37 @@ var codebaseAttributes = a.Type("CodebaseAttributes", func() {
 	a.Attribute("last_used_workspace", d.String, "The last used workspace name of the codebase ", func() {
 		a.Example("java-centos")
 	})
+	a.Attribute("metadata", codebaseMetadata, "The metadata of the repository, periodically fetched from its git host")
+})
+
+var codebaseMetadata = a.Type("CodebaseMetadata", func() {
+	a.Description("The metadata of the repository of a codebase")
+	a.Attribute("defaultBranch", d.String, "The default branch of the repository", func() {
+		a.Example("master")
+	})
+	a.Attribute("branches", a.ArrayOf(d.String), "The branches of the repository")
+	a.Attribute("latestCommit", codebaseCommit, "The latest commit of the default branch")
+	a.Attribute("openPullRequests", d.Integer, "The number of open pull requests", func() {
+		a.Example(3)
+	})
+	a.Attribute("language", d.String, "The main language of the repository", func() {
+		a.Example("Go")
+	})
+	a.Attribute("fetchedAt", d.DateTime, "When the metadata was fetched", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var codebaseCommit = a.Type("CodebaseCommit", func() {
+	a.Attribute("id", d.String, "The ID of the commit", func() {
+		a.Example("6f5a2b2c1d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a")
+	})
+	a.Attribute("message", d.String, "The message of the commit")
+	a.Attribute("author", d.String, "The author of the commit")
+	a.Attribute("url", d.String, "The URL of the commit")
+	a.Attribute("date", d.DateTime, "When the commit was authored", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
 })
 
 var codebaseLinks = a.Type("CodebaseLinks", func() {
@@ -145,6 +176,24 @@ var cheServerState = a.MediaType("CheServerState", func() {
 	})
 })
 
+var codebaseWebhook = a.MediaType("CodebaseWebhook", func() {
+	a.TypeName("CodebaseWebhook")
+	a.Description("The settings of the webhook of the repository of a codebase")
+	a.Attributes(func() {
+		a.Attribute("url", d.String, "The URL the webhook must send the push and pull request events to", func() {
+			a.Example("https://api.openshift.io/api/codebases/webhooks")
+		})
+		a.Attribute("secret", d.String, "The secret the webhook must sign the events with", func() {
+			a.Example("6f5a2b2c1d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a")
+		})
+		a.Required("url", "secret")
+	})
+	a.View("default", func() {
+		a.Attribute("url")
+		a.Attribute("secret")
+	})
+})
+
 var createWorkspace = a.MediaType("application/vnd.createworkspace+json", func() {
 	a.UseTrait("jsonapi-media-type")
 	a.TypeName("CreateWorkspace")
@@ -253,6 +302,7 @@ var _ = a.Resource("codebase", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
 	})
 	a.Action("open", func() {
 		a.Security("jwt")
@@ -271,6 +321,62 @@ var _ = a.Resource("codebase", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+	})
+	a.Action("stop", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:codebaseID/stop/:workspaceID"),
+		)
+		a.Description("Stop a given workspace of a codebase. Only the maintainers of the space can stop its workspaces.")
+		a.Params(func() {
+			a.Param("codebaseID", d.UUID, "Codebase Identifier")
+			a.Param("workspaceID", d.String, "Workspace Identifier")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+	a.Action("restart", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:codebaseID/restart/:workspaceID"),
+		)
+		a.Description("Stop then start again a given workspace of a codebase. Only the maintainers of the space can restart its workspaces.")
+		a.Params(func() {
+			a.Param("codebaseID", d.UUID, "Codebase Identifier")
+			a.Param("workspaceID", d.String, "Workspace Identifier")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workspaceOpen)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+	})
+	a.Action("webhook", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:codebaseID/webhook"),
+		)
+		a.Description("Retrieve the URL and the secret to configure the webhook of the repository of a codebase with.")
+		a.Params(func() {
+			a.Param("codebaseID", d.UUID, "Codebase Identifier")
+		})
+		a.Response(d.OK, func() {
+			a.Media(codebaseWebhook)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
 	})
 	a.Action("cheState", func() {
 		a.Security("jwt")
@@ -0,0 +1,117 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var deployment = a.Type("Deployment", func() {
+	a.Description(`JSONAPI store for the data of a deployment recorded by the CI of a space. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("deployments")
+	})
+	a.Attribute("id", d.UUID, "ID of the deployment", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", deploymentAttributes)
+	a.Attribute("relationships", deploymentRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var deploymentAttributes = a.Type("DeploymentAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a deployment. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("app-name", d.String, "Name of the deployed application", func() {
+		a.Example("myapp")
+	})
+	a.Attribute("environment", d.String, "Environment the application was deployed to", func() {
+		a.Example("production")
+	})
+	a.Attribute("version", d.String, "Deployed version of the application", func() {
+		a.Example("1.0.2")
+	})
+	a.Attribute("build-id", d.String, "ID of the build that produced the deployed version", func() {
+		a.Example("myapp-pipeline-42")
+	})
+	a.Attribute("commit-id", d.String, "ID of the commit the deployed version was built from", func() {
+		a.Example("6f5a2b2c1d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a")
+	})
+	a.Attribute("deployed-at", d.DateTime, "When the application was deployed, defaults to when the deployment is recorded", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("created-at", d.DateTime, "When the deployment was recorded", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("app-name", "environment", "version")
+})
+
+var deploymentRelationships = a.Type("DeploymentRelations", func() {
+	a.Attribute("workitems", relationGenericList, "This defines the work items delivered by the deployment")
+	a.Attribute("space", relationGeneric, "This defines the space of the deployment")
+	a.Attribute("creator", relationGeneric, "This defines the identity that recorded the deployment")
+})
+
+var deploymentList = JSONList(
+	"Deployment", "Holds the list of deployments",
+	deployment,
+	nil,
+	nil)
+
+var deploymentSingle = JSONSingle(
+	"Deployment", "Holds a single deployment",
+	deployment,
+	nil)
+
+var _ = a.Resource("space_deployments", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("deployments"),
+		)
+		a.Description(`List the deployments of the given space, newest first, along with the work items they
+delivered. Filter on the "production" environment to know which work items are live in production.`)
+		a.Params(func() {
+			a.Param("environment", d.String, "Only list the deployments to this environment")
+		})
+		a.UseTrait("conditional")
+		a.Response(d.OK, deploymentList)
+		a.Response(d.NotModified)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("deployments"),
+		)
+		a.Description(`Record a deployment of an application of the given space, and link it to the work items
+it delivered. Meant to be called by the CI with a personal access token allowed to edit the work items.`)
+		a.Payload(deploymentSingle)
+		a.Response(d.Created, deploymentSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("work_item_deployments", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("deployments"),
+		)
+		a.Description("List the deployments that delivered the given work item, newest first")
+		a.UseTrait("conditional")
+		a.Response(d.OK, deploymentList)
+		a.Response(d.NotModified)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
@@ -150,6 +150,83 @@ var simpleDeploymentStatSeries = a.Type("SimpleDeploymentStatSeries", func() {
 	a.Attribute("net_rx", a.ArrayOf(timedNumberTuple))
 })
 
+var simpleDeploymentRevision = a.Type("SimpleDeploymentRevision", func() {
+	a.Description(`a version of a deployment`)
+	a.Attribute("type", d.String, "The type of the related resource", func() {
+		a.Enum("deploymentrevision")
+	})
+	a.Attribute("id", d.String, "ID of the version (same as the name of its replication controller)")
+	a.Attribute("attributes", simpleDeploymentRevisionAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var simpleDeploymentRevisionAttributes = a.Type("SimpleDeploymentRevisionAttributes", func() {
+	a.Description(`a version of a deployment`)
+	a.Attribute("version", d.Integer, "version of the deployment config")
+	a.Attribute("app_version", d.String, "version of the application")
+	a.Attribute("images", a.ArrayOf(d.String), "images of the containers")
+	a.Attribute("created", d.DateTime)
+	a.Attribute("status", d.String, "phase of the deployment, e.g. 'Complete' or 'Failed'")
+	a.Attribute("replicas", d.Integer, "number of running pods")
+	a.Attribute("current", d.Boolean, "whether this is the current deployment")
+	a.Required("version")
+})
+
+var simpleDeploymentEvent = a.Type("SimpleDeploymentEvent", func() {
+	a.Description(`a Kubernetes event of a deployment`)
+	a.Attribute("type", d.String, "The type of the related resource", func() {
+		a.Enum("deploymentevent")
+	})
+	a.Attribute("id", d.String, "ID of the event")
+	a.Attribute("attributes", simpleDeploymentEventAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var simpleDeploymentEventAttributes = a.Type("SimpleDeploymentEventAttributes", func() {
+	a.Description(`a Kubernetes event of a deployment`)
+	a.Attribute("event_type", d.String, "type of the event, e.g. 'Normal' or 'Warning'")
+	a.Attribute("reason", d.String, "short reason of the event, e.g. 'BackOff'")
+	a.Attribute("message", d.String, "description of the event")
+	a.Attribute("count", d.Integer, "number of times the event occurred")
+	a.Attribute("first_timestamp", d.DateTime)
+	a.Attribute("last_timestamp", d.DateTime)
+	a.Attribute("object_kind", d.String, "kind of the object of the event, e.g. 'Pod'")
+	a.Attribute("object_name", d.String, "name of the object of the event")
+	a.Required("event_type", "reason", "message", "count", "object_kind", "object_name")
+})
+
+var simpleEnvironmentHealth = a.Type("SimpleEnvironmentHealth", func() {
+	a.Description(`the health of the deployments of a space within an environment`)
+	a.Attribute("type", d.String, "The type of the related resource", func() {
+		a.Enum("environmenthealth")
+	})
+	a.Attribute("id", d.String, "ID of the environment (same as 'name')")
+	a.Attribute("attributes", simpleEnvironmentHealthAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var simpleEnvironmentHealthAttributes = a.Type("SimpleEnvironmentHealthAttributes", func() {
+	a.Description(`the health of the deployments of a space within an environment`)
+	a.Attribute("name", d.String, "name of the environment")
+	a.Attribute("status", d.String, "health of the worst deployment", func() {
+		a.Enum("ok", "warning", "error")
+	})
+	a.Attribute("applications", a.ArrayOf(simpleApplicationHealth))
+	a.Required("name", "status", "applications")
+})
+
+var simpleApplicationHealth = a.Type("SimpleApplicationHealth", func() {
+	a.Description(`the health of the deployment of an application`)
+	a.Attribute("name", d.String, "name of the application")
+	a.Attribute("status", d.String, "health of the deployment", func() {
+		a.Enum("ok", "warning", "error")
+	})
+	a.Attribute("pod_total", d.Integer, "number of pods")
+	a.Attribute("pods_warning", d.Integer, "number of pods in a warning state")
+	a.Attribute("pods_error", d.Integer, "number of pods in a severe warning state, e.g. crash-looping")
+	a.Required("name", "status", "pod_total", "pods_warning", "pods_error")
+})
+
 var simpleSpaceSingle = JSONSingle(
 	"SimpleSpace", "Holds a single response to a space request",
 	simpleSpace,
@@ -161,6 +238,23 @@ var simpleEnvironmentMultiple = JSONList(
 	nil,
 	nil)
 
+var simpleDeploymentRevisionMultiple = JSONList(
+	"SimpleDeploymentRevision", "Holds a response to a space/application/deployment/history request",
+	simpleDeploymentRevision,
+	nil,
+	nil)
+
+var simpleDeploymentEventMultiple = JSONList(
+	"SimpleDeploymentEvent", "Holds a response to a space/application/deployment/events request",
+	simpleDeploymentEvent,
+	nil,
+	nil)
+
+var simpleEnvironmentHealthSingle = JSONSingle(
+	"SimpleEnvironmentHealth", "Holds a single response to a space/environment/health request",
+	simpleEnvironmentHealth,
+	nil)
+
 var simpleDeploymentStatsSingle = JSONSingle(
 	"SimpleDeploymentStats", "Holds a single response to a space/application/deployment/stats request",
 	simpleDeploymentStats,
@@ -248,6 +342,85 @@ var _ = a.Resource("deployments", func() {
 		a.Response(d.BadRequest, JSONAPIErrors)
 	})
 
+	a.Action("showDeploymentLogs", func() {
+		a.Routing(
+			a.GET("/spaces/:spaceID/applications/:appName/deployments/:deployName/logs"),
+		)
+		a.Description(`Stream the logs of the pods of a deployment as plain text. Each line is prefixed with the name
+of its pod between square brackets. Only the last 100 lines of each pod are returned unless "tail" or "since" is given.`)
+		a.Params(func() {
+			a.Param("spaceID", d.UUID, "ID of the space")
+			a.Param("appName", d.String, "Name of the application")
+			a.Param("deployName", d.String, "Name of the deployment")
+			a.Param("container", d.String, "Name of the container, when the pods have several ones")
+			a.Param("tail", d.Integer, "number of lines to return from the end of the logs of each pod", func() {
+				a.Minimum(1)
+			})
+			a.Param("since", d.DateTime, "only return the lines logged after this time")
+			a.Param("follow", d.Boolean, "keep streaming the new lines")
+		})
+		a.Response(d.OK, "text/plain")
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
+
+	a.Action("showDeploymentHistory", func() {
+		a.Routing(
+			a.GET("/spaces/:spaceID/applications/:appName/deployments/:deployName/history"),
+		)
+		a.Description("list the versions of a deployment, from the most recent one")
+		a.Params(func() {
+			a.Param("spaceID", d.UUID, "ID of the space")
+			a.Param("appName", d.String, "Name of the application")
+			a.Param("deployName", d.String, "Name of the deployment")
+		})
+		a.Response(d.OK, simpleDeploymentRevisionMultiple)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
+
+	a.Action("rollbackDeployment", func() {
+		a.Routing(
+			a.POST("/spaces/:spaceID/applications/:appName/deployments/:deployName/rollback"),
+		)
+		a.Description("roll back a deployment to an earlier version")
+		a.Params(func() {
+			a.Param("spaceID", d.UUID, "ID of the space")
+			a.Param("appName", d.String, "Name of the application")
+			a.Param("deployName", d.String, "Name of the deployment")
+			a.Param("version", d.Integer, "version to roll back to", func() {
+				a.Minimum(1)
+			})
+			a.Required("version")
+		})
+		a.Response(d.OK)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
+
+	a.Action("showDeploymentEvents", func() {
+		a.Routing(
+			a.GET("/spaces/:spaceID/applications/:appName/deployments/:deployName/events"),
+		)
+		a.Description("list the Kubernetes events of a deployment and of its pods, from the most recent one")
+		a.Params(func() {
+			a.Param("spaceID", d.UUID, "ID of the space")
+			a.Param("appName", d.String, "Name of the application")
+			a.Param("deployName", d.String, "Name of the deployment")
+		})
+		a.Response(d.OK, simpleDeploymentEventMultiple)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
+
 	a.Action("deleteDeployment", func() {
 		a.Routing(
 			a.DELETE("/spaces/:spaceID/applications/:appName/deployments/:deployName"),
@@ -279,4 +452,20 @@ var _ = a.Resource("deployments", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.BadRequest, JSONAPIErrors)
 	})
+
+	a.Action("showEnvironmentHealth", func() {
+		a.Routing(
+			a.GET("/spaces/:spaceID/environments/:envName/health"),
+		)
+		a.Description("get the health of the deployments of a space within an environment")
+		a.Params(func() {
+			a.Param("spaceID", d.UUID, "ID of the space")
+			a.Param("envName", d.String, "Name of the environment")
+		})
+		a.Response(d.OK, simpleEnvironmentHealthSingle)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
 })
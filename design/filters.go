@@ -31,6 +31,13 @@ var filterAttributes = a.Type("filterAttributes", func() {
 	a.Attribute("key", d.String, "Filter key to be used in the search query language", func() {
 		a.Example("label")
 	})
+	a.Attribute("kind", d.String, "The kind of the work item field the filter applies to (only for the filters of a space)", func() {
+		a.Example("enum")
+	})
+	a.Attribute("values", a.ArrayOf(d.Any), "The allowed values of the work item field, if restricted (only for the filters of a space)")
+	a.Attribute("operators", a.ArrayOf(d.String), "The operators of the search query language that apply to the filter (only for the filters of a space)", func() {
+		a.Example([]string{"$EQ", "$NE", "$IN"})
+	})
 	a.Required("type", "title", "description", "query", "key")
 })
 
@@ -60,3 +67,22 @@ var _ = a.Resource("filter", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 	})
 })
+
+var _ = a.Resource("space_filters", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("filters"),
+		)
+		a.Description(`List the filters of the space, derived from the fields of the work item types of its space
+template, with the kind of the fields, their allowed values and the operators of the search query language that
+apply to them.`)
+		a.Response(d.OK, func() {
+			a.Media(filterList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,36 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var graphQLRequest = a.Type("GraphQLRequest", func() {
+	a.Description(`A GraphQL request, see http://graphql.org/learn/serving-over-http/#post-request`)
+	a.Attribute("query", d.String, "The GraphQL query", func() {
+		a.Example(`{ space(id: "a8b5d5dc-fe2e-4b1b-9ef7-0c1a5bba1b0b") { name workItems(first: 10) { nodes { number title } } } }`)
+	})
+	a.Attribute("operationName", d.String, "The name of the operation to run when the query holds several ones")
+	a.Attribute("variables", a.HashOf(d.String, d.Any), "The values of the variables of the query")
+	a.Required("query")
+})
+
+var _ = a.Resource("graphql", func() {
+	a.BasePath("/graphql")
+
+	a.Action("query", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description(`Run a read-only GraphQL query over the spaces, their work items, work item types, iterations,
+areas and labels, and the links and comments of the work items. The response holds the "data" and "errors" of the
+query as defined by the GraphQL specification. The query is rejected if it is nested too deeply, and aborted if it
+loads or lists more entities than allowed.`)
+		a.Payload(graphQLRequest)
+		a.Response(d.OK, "application/json")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -34,9 +34,19 @@ var pagingLinks = a.Type("pagingLinks", func() {
 var meta = a.Type("workItemListResponseMeta", func() {
 	a.Attribute("totalCount", d.Integer)
 	a.Attribute("ancestorIDs", a.ArrayOf(d.UUID), "array of work item IDs in the \"included\" array that are ancestors")
+	a.Attribute("highlights", a.HashOf(d.String, searchHighlight), "the fragments of the work items matching a full text search, by work item ID")
 	a.Required("totalCount")
 })
 
+var searchHighlight = a.Type("SearchHighlight", func() {
+	a.Description("The fragments of a work item that match a full text search, with the matching words highlighted")
+	a.Attribute("title", d.String, "The highlighted title, if it matches", func() {
+		a.Example("Fix the <b>login</b> page")
+	})
+	a.Attribute("description", d.String, "The highlighted fragment of the description, if it matches")
+	a.Attribute("comment", d.String, "The highlighted fragment of the best matching comment, if any")
+})
+
 // position represents the ID of the workitem above which the to-be-reordered workitem(s) should be placed
 var position = a.Type("workItemReorderPosition", func() {
 	a.Description("Position represents the ID of the workitem above which the to-be-reordered workitem(s) should be placed")
@@ -0,0 +1,106 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var personalAccessToken = a.Type("PersonalAccessToken", func() {
+	a.Description(`JSONAPI store for the data of a personal access token. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("personal-access-tokens")
+	})
+	a.Attribute("id", d.UUID, "ID of the personal access token", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", personalAccessTokenAttributes)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var personalAccessTokenAttributes = a.Type("PersonalAccessTokenAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a personal access token. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "The name given to the token by the user", func() {
+		a.MinLength(1)
+		a.Example("release bot")
+	})
+	a.Attribute("scope", d.String, `What the token can be used for: "read" only allows to read, "write:workitems"
+allows to do what a contributor of a space can do and "admin:space" allows to do what the user can do`, func() {
+		a.Enum("read", "write:workitems", "admin:space")
+		a.Example("write:workitems")
+	})
+	a.Attribute("expires-at", d.DateTime, "When the token expires, at most one year after its creation", func() {
+		a.Example("2017-11-29T23:18:14Z")
+	})
+	a.Attribute("token", d.String, "The token to send in the Authorization header, only returned when the token is created", func() {
+		a.Example("f8pat_6UV9r2r1HDbQZ0oMUnqkTdzWfrmnO6V6Fs0YXJtDpq8")
+	})
+	a.Attribute("created-at", d.DateTime, "When the token was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("last-used-at", d.DateTime, "When the token was last used, with a precision of one minute", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("name", "scope", "expires-at")
+})
+
+var personalAccessTokenList = JSONList(
+	"PersonalAccessToken", "Holds the list of the personal access tokens of a user",
+	personalAccessToken,
+	nil,
+	nil)
+
+var personalAccessTokenSingle = JSONSingle(
+	"PersonalAccessToken", "Holds a single personal access token",
+	personalAccessToken,
+	nil)
+
+var _ = a.Resource("personal_access_tokens", func() {
+	a.BasePath("/user/tokens")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the personal access tokens of the authenticated user that are not revoked, including the expired ones")
+		a.Response(d.OK, personalAccessTokenList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description(`Create a personal access token for the authenticated user. The token is only returned in the
+response and can't be retrieved later. Personal access tokens can't be used to create other tokens.`)
+		a.Payload(personalAccessTokenSingle)
+		a.Response(d.Created, "/user/tokens/.*", func() {
+			a.Media(personalAccessTokenSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("revoke", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:tokenID"),
+		)
+		a.Params(func() {
+			a.Param("tokenID", d.UUID, "ID of the personal access token")
+		})
+		a.Description("Revoke a personal access token of the authenticated user")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
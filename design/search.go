@@ -31,7 +31,10 @@ var _ = a.Resource("search", func() {
 				1) "id:100" :- Look for work item hainvg id 100
 				2) "url:http://demo.openshift.io/details/500" :- Search on WI having id 500 and check 
 					if this URL is mentioned in searchable columns of work item
-				3) "simple keywords separated by space" :- Search in Work Items based on these keywords.`)
+				3) "simple keywords separated by space" :- Search in Work Items based on these keywords.
+				The number, title, description and comments of the work items are searched (in this order of
+				relevance) using the search configuration of their space and the matching fragments are returned
+				in the "highlights" of the response meta.`)
 			a.Param("page[offset]", d.String, "Paging start position") // #428
 			a.Param("page[limit]", d.Integer, "Paging size")
 			a.Param("filter[parentexists]", d.Boolean, "if false list work items without any parent")
@@ -0,0 +1,32 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("space_events", func() {
+	a.Parent("space")
+
+	a.Action("stream", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("events"),
+		)
+		a.Headers(func() {
+			a.Header("Last-Event-ID", d.String, "ID of the last event received, to resume the stream after it")
+		})
+		a.Description(`Stream the changes made in the given space as Server-Sent Events (text/event-stream).
+The name of each event is the type of the change (e.g. "workitem.create", "workitem.reorder", "link.delete",
+"comment.update") and its data holds the ID of the changed item. A "reset" event is sent when the stream
+can't be resumed after the given Last-Event-ID because the events that followed are not retained anymore:
+the client should then reload the whole state of the space. A resumed stream also replays some of the
+events that came shortly before the given Last-Event-ID, since they may have been committed after it.`)
+		a.Response(d.OK, "text/event-stream")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,69 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+// spacePolicySingle defines the JSONAPI type of a policy of a space, whose
+// ID is the ID of the space, and returns the media type holding it. The
+// attributes function defines the settings of the policy.
+func spacePolicySingle(name, apiType, kind, description string, attributes func()) *d.MediaTypeDefinition {
+	policyAttributes := a.Type(name+"Attributes", func() {
+		a.Description(`JSONAPI store for all the "attributes" of a ` + kind + `. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+		attributes()
+		a.Attribute("updated-at", d.DateTime, "When the policy was last changed", func() {
+			a.Example("2016-11-29T23:18:14Z")
+		})
+	})
+	policyRelationships := a.Type(name+"Relations", func() {
+		a.Attribute("space", relationGeneric, "This defines the space the policy applies to")
+	})
+	policy := a.Type(name, func() {
+		a.Description(description + ` See also http://jsonapi.org/format/#document-resource-object`)
+		a.Attribute("type", d.String, func() {
+			a.Enum(apiType)
+		})
+		a.Attribute("id", d.UUID, "ID of the space the policy applies to", func() {
+			a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+		})
+		a.Attribute("attributes", policyAttributes)
+		a.Attribute("relationships", policyRelationships)
+		a.Attribute("links", genericLinks)
+		a.Required("type", "attributes")
+	})
+	return JSONSingle(name, "Holds the "+kind+" of a space", policy, nil)
+}
+
+// spacePolicyActions defines the actions of the resource of a policy of a
+// space served at the given path below the space: everyone can show the
+// policy and the authorized users can replace it
+func spacePolicyActions(path, showDescription, updateDescription string, single *d.MediaTypeDefinition) {
+	a.Parent("space")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET(path),
+		)
+		a.Description(showDescription)
+		a.Response(d.OK, single)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT(path),
+		)
+		a.Description(updateDescription)
+		a.Payload(single)
+		a.Response(d.OK, single)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+}
@@ -0,0 +1,27 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var retentionPolicySingle = spacePolicySingle("RetentionPolicy", "retentionpolicies", "retention policy",
+	`JSONAPI store for the retention policy of the closed work items and of the revisions of a space.`,
+	func() {
+		a.Attribute("archive-closed-after-months", d.Integer, "The number of months after which a closed work item (or one resolved, done or removed) that did not change is archived, 0 meaning never. The archived work items are left out of the searches unless requested.", func() {
+			a.Minimum(0)
+			a.Example(12)
+		})
+		a.Attribute("compact-revisions-after-months", d.Integer, "The number of months after which the revisions of the work items, comments and links are compacted into a single snapshot revision, 0 meaning never", func() {
+			a.Minimum(0)
+			a.Example(6)
+		})
+		a.Required("archive-closed-after-months", "compact-revisions-after-months")
+	})
+
+var _ = a.Resource("space_retention_policy", func() {
+	spacePolicyActions("retention-policy",
+		"Retrieve the retention policy of the given space",
+		"Replace the retention policy of the given space",
+		retentionPolicySingle)
+})
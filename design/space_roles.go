@@ -0,0 +1,102 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var spaceRole = a.Type("SpaceRole", func() {
+	a.Description(`JSONAPI store for the role of an identity in a space. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("spaceroles")
+	})
+	a.Attribute("id", d.UUID, "ID of the identity having the role", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", spaceRoleAttributes)
+	a.Attribute("relationships", spaceRoleRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var spaceRoleAttributes = a.Type("SpaceRoleAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a space role. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("role", d.String, "The role of the identity in the space, each role granting the permissions of the ones before it", func() {
+		a.Enum("viewer", "contributor", "maintainer", "admin")
+		a.Example("maintainer")
+	})
+	a.Attribute("created-at", d.DateTime, "When the role was first given to the identity", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the role was last changed", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("role")
+})
+
+var spaceRoleRelationships = a.Type("SpaceRoleRelations", func() {
+	a.Attribute("identity", relationGeneric, "This defines the identity having the role")
+	a.Attribute("space", relationGeneric, "This defines the space the role applies to")
+})
+
+var spaceRoleList = JSONList(
+	"SpaceRole", "Holds the list of the roles stored for a space",
+	spaceRole,
+	nil,
+	nil)
+
+var spaceRoleSingle = JSONSingle(
+	"SpaceRole", "Holds a single space role",
+	spaceRole,
+	nil)
+
+var _ = a.Resource("space_roles", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("roles"),
+		)
+		a.Description(`List the roles stored for the given space. The owner of the space and the collaborators
+known by the authorization service have roles that are not listed here.`)
+		a.Response(d.OK, spaceRoleList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("assign", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("roles/:identityID"),
+		)
+		a.Params(func() {
+			a.Param("identityID", d.UUID, "ID of the identity")
+		})
+		a.Description("Give a role to an identity in the given space, replacing its previous role if any")
+		a.Payload(spaceRoleSingle)
+		a.Response(d.OK, spaceRoleSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("revoke", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("roles/:identityID"),
+		)
+		a.Params(func() {
+			a.Param("identityID", d.UUID, "ID of the identity")
+		})
+		a.Description("Remove the role stored for an identity in the given space")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
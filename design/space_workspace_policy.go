@@ -0,0 +1,27 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workspacePolicySingle = spacePolicySingle("WorkspacePolicy", "workspacepolicies", "workspace policy",
+	`JSONAPI store for the policy of the Che workspaces of the codebases of a space.`,
+	func() {
+		a.Attribute("max-running-workspaces", d.Integer, "The maximum number of workspaces running at the same time for the codebases of the space, 0 meaning no limit", func() {
+			a.Minimum(0)
+			a.Example(3)
+		})
+		a.Attribute("max-runtime-minutes", d.Integer, "The number of minutes after which a workspace started through a codebase is stopped, 0 meaning never. Che doesn't report the activity in the workspaces, so a workspace is stopped even if it is still in use.", func() {
+			a.Minimum(0)
+			a.Example(480)
+		})
+		a.Required("max-running-workspaces", "max-runtime-minutes")
+	})
+
+var _ = a.Resource("space_workspace_policy", func() {
+	spacePolicyActions("workspace-policy",
+		"Retrieve the policy of the Che workspaces of the codebases of the given space",
+		"Replace the policy of the Che workspaces of the codebases of the given space",
+		workspacePolicySingle)
+})
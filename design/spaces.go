@@ -67,6 +67,9 @@ var spaceAttributes = a.Type("SpaceAttributes", func() {
 	a.Attribute("description", d.String, "Description for the space", func() {
 		a.Example("This is the foobar collaboration space")
 	})
+	a.Attribute("search-config", d.String, "The text search configuration (i.e. the language) used to index and search the work items of the space (defaults to \"english\")", func() {
+		a.Example("german")
+	})
 	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
 		a.Example(23)
 	})
@@ -0,0 +1,74 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var suggestion = a.Type("Suggestion", func() {
+	a.Description(`JSONAPI store for an entity suggested for a prefix. The "type" tells which kind of entity is
+suggested. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitems", "identities", "labels", "iterations", "areas")
+	})
+	a.Attribute("id", d.UUID, "ID of the suggested entity", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", suggestionAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var suggestionAttributes = a.Type("SuggestionAttributes", func() {
+	a.Description(`JSONAPI store for the minimal "attributes" of a suggested entity. See also
+http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "The title of a work item, the username of a user or the name of a label, an iteration or an area", func() {
+		a.Example("Login page")
+	})
+	a.Attribute("number", d.Integer, "The number of a work item", func() {
+		a.Example(42)
+	})
+	a.Attribute("full-name", d.String, "The full name of a user", func() {
+		a.Example("John Doe")
+	})
+	a.Attribute("image-url", d.String, "The image URL of a user", func() {
+		a.Example("https://www.gravatar.com/avatar/0")
+	})
+	a.Required("name")
+})
+
+var suggestionList = JSONList(
+	"Suggestion", "Holds the list of the entities suggested for a prefix",
+	suggestion,
+	nil,
+	nil)
+
+var _ = a.Resource("space_suggestions", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("suggestions"),
+		)
+		a.Description(`List the work items, users, labels, iterations and areas matching a prefix, e.g. to fill an
+autocomplete box: work items of the space whose number is the prefix or whose title has words starting with the
+words of the prefix, users whose username or full name starts with the prefix and labels, iterations and areas of
+the space whose name starts with the prefix. Only a few attributes of the entities are returned.`)
+		a.Params(func() {
+			a.Param("q", d.String, "The prefix to match", func() {
+				a.MinLength(1)
+			})
+			a.Param("limit", d.Integer, "The maximum number of suggestions per type of entity", func() {
+				a.Minimum(1)
+				a.Maximum(20)
+				a.Default(5)
+			})
+			a.Required("q")
+		})
+		a.Response(d.OK, func() {
+			a.Media(suggestionList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,111 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var trashItem = a.Type("TrashItem", func() {
+	a.Description(`JSONAPI store for the data of a deleted work item or comment. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("trashitems")
+	})
+	a.Attribute("id", d.UUID, "ID of the deleted work item or comment", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", trashItemAttributes)
+	a.Attribute("relationships", trashItemRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var trashItemAttributes = a.Type("TrashItemAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a deleted work item or comment. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("kind", d.String, "What has been deleted", func() {
+		a.Enum("workitems", "comments")
+	})
+	a.Attribute("title", d.String, "The title of the work item or the body of the comment", func() {
+		a.Example("Release the new component")
+	})
+	a.Attribute("number", d.Integer, "The number of the work item or of the work item the comment belongs to", func() {
+		a.Example(42)
+	})
+	a.Attribute("deleted-at", d.DateTime, "When the work item or comment was deleted", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("purge-at", d.DateTime, "When the work item or comment will be deleted permanently (not set if it is never purged)", func() {
+		a.Example("2016-12-29T23:18:14Z")
+	})
+})
+
+var trashItemRelationships = a.Type("TrashItemRelations", func() {
+	a.Attribute("space", relationGeneric, "This defines the owning space")
+	a.Attribute("workitem", relationGeneric, "The deleted work item or the work item the deleted comment belongs to")
+	a.Attribute("deleted-by", relationGeneric, "The identity that deleted the work item or comment")
+})
+
+var trashItemList = JSONList(
+	"TrashItem", "Holds the list of deleted work items and comments",
+	trashItem,
+	nil,
+	nil)
+
+var trashItemSingle = JSONSingle(
+	"TrashItem", "Holds a single deleted work item or comment",
+	trashItem,
+	nil)
+
+var _ = a.Resource("trash", func() {
+	a.Parent("space")
+	a.BasePath("/trash")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the work items and comments recently deleted in the given space, most recent first")
+		a.Response(d.OK, trashItemList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:itemID"),
+		)
+		a.Description("Retrieve the deleted work item or comment with the given id")
+		a.Params(func() {
+			a.Param("itemID", d.UUID, "ID of the deleted work item or comment")
+		})
+		a.Response(d.OK, trashItemSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("restore", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:itemID/restore"),
+		)
+		a.Description(`Restore the deleted work item or comment with the given id. The links that were removed when the
+work item was deleted are restored too, unless they were created again or would now give a work item two parents.
+A comment can only be restored if its work item exists.`)
+		a.Params(func() {
+			a.Param("itemID", d.UUID, "ID of the deleted work item or comment")
+		})
+		a.Response(d.OK, trashItemSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
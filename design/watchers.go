@@ -0,0 +1,148 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var watcher = a.Type("Watcher", func() {
+	a.Description(`JSONAPI store for the data of a work item watcher. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("watchers")
+	})
+	a.Attribute("id", d.UUID, "ID of the watching identity", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", watcherAttributes)
+	a.Attribute("relationships", watcherRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var watcherAttributes = a.Type("WatcherAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item watcher. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("reason", d.String, "Why the identity is watching the work item", func() {
+		a.Enum("manual", "creator", "assignee", "commenter", "mention")
+		a.Example("assignee")
+	})
+	a.Attribute("created-at", d.DateTime, "When the identity started watching the work item", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var watcherRelationships = a.Type("WatcherRelations", func() {
+	a.Attribute("identity", relationGeneric, "This defines the watching identity")
+	a.Attribute("workitem", relationGeneric, "This defines the watched work item")
+})
+
+var watcherList = JSONList(
+	"Watcher", "Holds the list of watchers of a work item",
+	watcher,
+	nil,
+	nil)
+
+var watcherSingle = JSONSingle(
+	"Watcher", "Holds a single work item watcher",
+	watcher,
+	nil)
+
+var notificationPreference = a.Type("NotificationPreference", func() {
+	a.Description(`JSONAPI store for the data of a notification preference. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("notification-preferences")
+	})
+	a.Attribute("id", d.String, "The message type the preference applies to", func() {
+		a.Example("workitem.update")
+	})
+	a.Attribute("attributes", notificationPreferenceAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var notificationPreferenceAttributes = a.Type("NotificationPreferenceAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a notification preference. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("enabled", d.Boolean, "Whether the user wants to receive messages of this type", func() {
+		a.Example(false)
+	})
+	a.Attribute("updated-at", d.DateTime, "When the preference was last changed", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("enabled")
+})
+
+var notificationPreferenceList = JSONList(
+	"NotificationPreference", "Holds the notification preferences of a user",
+	notificationPreference,
+	nil,
+	nil)
+
+var _ = a.Resource("work_item_watchers", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("watchers"),
+		)
+		a.Description("List the identities watching the given work item")
+		a.UseTrait("conditional")
+		a.Response(d.OK, watcherList)
+		a.Response(d.NotModified)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("watch", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("watchers"),
+		)
+		a.Description("Make the authenticated user watch the given work item")
+		a.Response(d.OK, watcherSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("unwatch", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("watchers"),
+		)
+		a.Description("Make the authenticated user stop watching the given work item")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("notification_preferences", func() {
+	a.BasePath("/user/notificationpreferences")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the notification preferences of the authenticated user for all known message types")
+		a.Response(d.OK, notificationPreferenceList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH(""),
+		)
+		a.Description("Update the notification preferences of the authenticated user")
+		a.Payload(notificationPreferenceList)
+		a.Response(d.OK, notificationPreferenceList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,83 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemImport = a.Type("WorkItemImport", func() {
+	a.Description(`JSONAPI store for the data of a work item import. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemimports")
+	})
+	a.Attribute("attributes", workItemImportAttributes)
+	a.Attribute("relationships", workItemImportRelationships)
+	a.Required("type", "attributes")
+})
+
+var workItemImportAttributes = a.Type("WorkItemImportAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item import. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("csv", d.String, "The content of the CSV file, whose first line is the header", func() {
+		a.Example("ID,Title,Assignee,Parent\nA1,Epic,alice,\nA2,Story,bob@example.com,A1")
+	})
+	a.Attribute("columns", a.HashOf(d.String, d.String), `Maps the CSV columns to the work item fields or to "type" for the
+column holding the name of the work item type. The columns that are not mapped are ignored.`)
+	a.Attribute("reference-column", d.String, "The column holding a reference to the row that is unique in the file", func() {
+		a.Example("ID")
+	})
+	a.Attribute("parent-column", d.String, "The column holding the reference of the row of the parent work item", func() {
+		a.Example("Parent")
+	})
+	a.Attribute("create-missing", d.Boolean, "Whether to create the iterations, areas and labels that don't exist", func() {
+		a.Default(false)
+	})
+	a.Attribute("dry-run", d.Boolean, "Whether to only validate the rows without creating anything", func() {
+		a.Default(true)
+	})
+	a.Attribute("committed", d.Boolean, "Whether the work items were created, only set in the response")
+	a.Attribute("creates", a.ArrayOf(d.String), "The iterations, areas and labels created by the import, only set in the response")
+	a.Attribute("rows", a.ArrayOf(workItemImportRow), "The outcome of the import of each row, only set in the response")
+	a.Required("csv", "columns")
+})
+
+var workItemImportRow = a.Type("WorkItemImportRow", func() {
+	a.Attribute("row", d.Integer, "Position of the row in the file, starting at 1 after the header")
+	a.Attribute("reference", d.String, "Reference of the row")
+	a.Attribute("errors", a.ArrayOf(d.String), "Why the row can't be imported")
+	a.Attribute("workitem-id", d.UUID, "ID of the work item created for the row")
+	a.Attribute("number", d.Integer, "Number of the work item created for the row")
+	a.Required("row")
+})
+
+var workItemImportRelationships = a.Type("WorkItemImportRelationships", func() {
+	a.Attribute("baseType", relationBaseType, `The type of the work items whose type is not given by a column mapped to "type"`)
+})
+
+var workItemImportSingle = JSONSingle(
+	"WorkItemImport", "Holds a work item import",
+	workItemImport,
+	nil)
+
+var _ = a.Resource("work_item_import", func() {
+	a.Parent("space")
+
+	a.Action("import", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("workitems/import"),
+		)
+		a.Description(`Import work items from a CSV file. The users are resolved by username or email, the iterations
+and areas by path and the labels by name. Every row is validated against its work item type and the errors are
+reported per row. Unless it is a dry run, the work items and the links to their parents are created in a single
+transaction if no row has errors, otherwise nothing is created.`)
+		a.Payload(workItemImportSingle)
+		a.Response(d.OK, func() {
+			a.Media(workItemImportSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
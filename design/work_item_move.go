@@ -0,0 +1,50 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemMove = a.Type("WorkItemMove", func() {
+	a.Description(`JSONAPI store for the data of a work item move request. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemmoves")
+	})
+	a.Attribute("relationships", workItemMoveRelationships)
+	a.Required("type", "relationships")
+})
+
+var workItemMoveRelationships = a.Type("WorkItemMoveRelations", func() {
+	a.Attribute("space", relationGeneric, "The space to move the work item into")
+	a.Attribute("baseType", relationBaseType, "The type of the work item in the target space (defaults to the equivalent of the current type)")
+	a.Required("space")
+})
+
+var workItemMoveSingle = JSONSingle(
+	"WorkItemMove", "Holds a work item move request",
+	workItemMove,
+	nil)
+
+var _ = a.Resource("work_item_move", func() {
+	a.Parent("workitem")
+
+	a.Action("move", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("move"),
+		)
+		a.Description(`Move the given work item into another space. The work item gets a new number in the target space,
+its type, iteration, area and labels are mapped to their equivalents in the target space. Links and comments are kept
+and the old number keeps resolving to the work item.`)
+		a.Payload(workItemMoveSingle)
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,85 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemSchedule = a.Type("WorkItemSchedule", func() {
+	a.Description(`JSONAPI store for the schedule of the work items of a space or iteration given the links of a "dependency" topology between them. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemschedules")
+	})
+	a.Attribute("id", d.UUID, "ID of the space or iteration", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemScheduleAttributes)
+	a.Attribute("relationships", workItemScheduleRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var workItemScheduleAttributes = a.Type("WorkItemScheduleAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item schedule. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("order", a.ArrayOf(d.UUID), "The IDs of the work items with dependencies, every work item comes after the ones it depends on")
+	a.Attribute("dependencies", a.ArrayOf(workItemDependency), "The dependencies between the work items")
+	a.Attribute("critical-path", a.ArrayOf(d.UUID), "The IDs of the chain of dependent work items with the highest remaining effort")
+	a.Attribute("critical-path-effort", d.Number, "The remaining effort on the critical path (work items without an effort count as 1)", func() {
+		a.Example(13)
+	})
+	a.Attribute("blocked", a.ArrayOf(workItemBlocked), "The unfinished work items that depend on unfinished work items")
+	a.Attribute("conflicts", a.ArrayOf(workItemScheduleConflict), "The work items scheduled in an iteration that ends before the iteration of an unfinished work item they depend on")
+})
+
+var workItemDependency = a.Type("WorkItemDependency", func() {
+	a.Attribute("predecessor", d.UUID, "The work item that must be finished first")
+	a.Attribute("successor", d.UUID, "The work item that depends on the predecessor")
+	a.Required("predecessor", "successor")
+})
+
+var workItemBlocked = a.Type("WorkItemBlocked", func() {
+	a.Attribute("workitem", d.UUID, "The blocked work item")
+	a.Attribute("blocked-by", a.ArrayOf(d.UUID), "The unfinished work items it depends on")
+	a.Required("workitem", "blocked-by")
+})
+
+var workItemScheduleConflict = a.Type("WorkItemScheduleConflict", func() {
+	a.Attribute("workitem", d.UUID, "The work item scheduled too early")
+	a.Attribute("iteration", d.UUID, "The iteration of the work item")
+	a.Attribute("dependency", d.UUID, "The unfinished work item it depends on")
+	a.Attribute("dependency-iteration", d.UUID, "The iteration of the work item it depends on")
+	a.Required("workitem", "iteration", "dependency", "dependency-iteration")
+})
+
+var workItemScheduleRelationships = a.Type("WorkItemScheduleRelations", func() {
+	a.Attribute("space", relationGeneric, "This defines the owning space")
+	a.Attribute("iteration", relationGeneric, "The iteration the schedule is restricted to, if any")
+})
+
+var workItemScheduleSingle = JSONSingle(
+	"WorkItemSchedule", "Holds the schedule of the work items of a space or iteration",
+	workItemSchedule,
+	nil)
+
+var _ = a.Resource("work_item_schedule", func() {
+	a.Parent("space")
+	a.BasePath("/schedule")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`Show the work items of the space, or of the given iteration, that have dependencies in the order
+in which they can be worked on, with their critical path, the work items blocked by unfinished ones and the work items
+scheduled in an iteration that ends before the iteration of one of their dependencies. The work items are included in
+the response.`)
+		a.Params(func() {
+			a.Param("filter[iteration]", d.UUID, "Restrict the schedule to the work items of the given iteration")
+		})
+		a.Response(d.OK, workItemScheduleSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
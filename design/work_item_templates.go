@@ -0,0 +1,218 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemClone = a.Type("WorkItemClone", func() {
+	a.Description(`JSONAPI store for the data of a work item clone request. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemclones")
+	})
+	a.Attribute("attributes", workItemCloneAttributes)
+	a.Attribute("relationships", workItemCloneRelationships)
+	a.Required("type")
+})
+
+var workItemCloneAttributes = a.Type("WorkItemCloneAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item clone request. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("deep", d.Boolean, "Whether the children of the work item are cloned as well", func() {
+		a.Default(false)
+		a.Example(true)
+	})
+})
+
+var workItemCloneRelationships = a.Type("WorkItemCloneRelations", func() {
+	a.Attribute("space", relationGeneric, "The space to clone the work item into (defaults to the space of the work item)")
+})
+
+var workItemCloneSingle = JSONSingle(
+	"WorkItemClone", "Holds a work item clone request",
+	workItemClone,
+	nil)
+
+var workItemTemplate = a.Type("WorkItemTemplate", func() {
+	a.Description(`JSONAPI store for the data of a work item template. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemtemplates")
+	})
+	a.Attribute("id", d.UUID, "ID of the work item template", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemTemplateAttributes)
+	a.Attribute("relationships", workItemTemplateRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var workItemTemplateAttributes = a.Type("WorkItemTemplateAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item template. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "The work item template name", nameValidationFunction)
+	a.Attribute("description", d.String, "Description of the work item template", func() {
+		a.Example("An epic with the tasks required to release a new component")
+	})
+	a.Attribute("placeholders", a.ArrayOf(d.String), "The placeholders used in the titles of the template (read-only)", func() {
+		a.Example([]string{"component"})
+	})
+	a.Attribute("size", d.Integer, "The number of work items created when instantiating the template (read-only)", func() {
+		a.Example(11)
+	})
+	a.Attribute("created-at", d.DateTime, "When the work item template was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the work item template was updated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
+		a.Example(23)
+	})
+})
+
+var workItemTemplateRelationships = a.Type("WorkItemTemplateRelations", func() {
+	a.Attribute("space", relationGeneric, "This defines the owning space")
+	a.Attribute("creator", relationGeneric, "This defines the creator of the work item template")
+	a.Attribute("workitem", relationGeneric, "The root work item of the subtree to save as a template (only used when creating a template)")
+})
+
+var workItemTemplateList = JSONList(
+	"WorkItemTemplate", "Holds the list of work item templates",
+	workItemTemplate,
+	nil,
+	nil)
+
+var workItemTemplateSingle = JSONSingle(
+	"WorkItemTemplate", "Holds a single work item template",
+	workItemTemplate,
+	nil)
+
+var workItemTemplateInstantiation = a.Type("WorkItemTemplateInstantiation", func() {
+	a.Description(`JSONAPI store for the data of a work item template instantiation. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemtemplateinstantiations")
+	})
+	a.Attribute("attributes", workItemTemplateInstantiationAttributes)
+	a.Required("type")
+})
+
+var workItemTemplateInstantiationAttributes = a.Type("WorkItemTemplateInstantiationAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item template instantiation. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("placeholders", a.HashOf(d.String, d.String), "The values to substitute for the placeholders in the titles", func() {
+		a.Example(map[string]string{"component": "planner"})
+	})
+})
+
+var workItemTemplateInstantiationSingle = JSONSingle(
+	"WorkItemTemplateInstantiation", "Holds a work item template instantiation request",
+	workItemTemplateInstantiation,
+	nil)
+
+var _ = a.Resource("work_item_clone", func() {
+	a.Parent("workitem")
+
+	a.Action("clone", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("clone"),
+		)
+		a.Description("Clone the given work item, and optionally its children, into the same or another space with the same space template. Comments are not cloned.")
+		a.Payload(workItemCloneSingle)
+		a.Response(d.Created, "/workitems/.*", func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("work_item_templates", func() {
+	a.Parent("space")
+	a.BasePath("/workitemtemplates")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the work item templates of the given space")
+		a.UseTrait("conditional")
+		a.Response(d.OK, workItemTemplateList)
+		a.Response(d.NotModified)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:templateID"),
+		)
+		a.Description("Retrieve the work item template with the given id")
+		a.Params(func() {
+			a.Param("templateID", d.UUID, "ID of the work item template")
+		})
+		a.UseTrait("conditional")
+		a.Response(d.OK, workItemTemplateSingle)
+		a.Response(d.NotModified)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Save the given work item and its children as a work item template")
+		a.Payload(workItemTemplateSingle)
+		a.Response(d.Created, "/workitemtemplates/.*", func() {
+			a.Media(workItemTemplateSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:templateID"),
+		)
+		a.Description("Delete the work item template with the given id")
+		a.Params(func() {
+			a.Param("templateID", d.UUID, "ID of the work item template")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("instantiate", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:templateID/instantiate"),
+		)
+		a.Description("Create the work items of the given template in the space, substituting the placeholders in their titles. The template must belong to a space with the same space template.")
+		a.Params(func() {
+			a.Param("templateID", d.UUID, "ID of the work item template")
+		})
+		a.Payload(workItemTemplateInstantiationSingle)
+		a.Response(d.Created, "/workitems/.*", func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
@@ -45,6 +45,8 @@ var workItemRelationships = a.Type("WorkItemRelationships", func() {
 	a.Attribute("parent", relationKindUUID, "This defines the parent of this work item.")
 	a.Attribute("workItemLinks", relationGeneric, "List of links in which this work item is involved")
 	a.Attribute("events", relationGeneric, "List of events in which this work item is involved")
+	a.Attribute("deployments", relationGeneric, "List of the deployments that delivered this work item")
+	a.Attribute("codeReferences", relationGeneric, "List of the commits and pull requests referencing this work item")
 })
 
 // relationBaseType is top level block for WorkItemType relationship
@@ -247,6 +249,35 @@ var _ = a.Resource("workitems", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 		a.Response(d.Forbidden, JSONAPIErrors)
 	})
+
+	a.Action("export", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/export"),
+		)
+		a.Description(`Export the work items of the space matching the filter expression or the saved query, if any,
+as CSV or as newline delimited JSON objects. The users, iterations, areas and labels are exported with their
+display values instead of their IDs.`)
+		a.Params(func() {
+			a.Param("filter[expression]", d.String, "Filter expression in JSON format", func() {
+				a.Example(`{"$AND": [{"state": "open"}, {"assignee": "abcd1234-1234-5678-cafe-0123456789ab"}]}`)
+			})
+			a.Param("query", d.UUID, "ID of a saved query of the current user to use as filter expression")
+			a.Param("columns", d.String, "Comma separated list of the columns to export: id, type or the name of a field of the work item types", func() {
+				a.Example("system.number,type,system.title,system.assignees,effort")
+			})
+			a.Param("format", d.String, "Format of the export", func() {
+				a.Enum("csv", "ndjson")
+				a.Default("csv")
+			})
+		})
+		a.Response(d.OK, "text/csv")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
 })
 
 var _ = a.Resource("planner_backlog", func() {
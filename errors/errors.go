@@ -117,6 +117,27 @@ type ForbiddenError struct {
 	simpleError
 }
 
+// TooManyRequestsError means that the client sent too many requests in a given
+// amount of time
+type TooManyRequestsError struct {
+	simpleError
+}
+
+// NewTooManyRequestsError returns the custom defined error of type TooManyRequestsError.
+func NewTooManyRequestsError(msg string) TooManyRequestsError {
+	return TooManyRequestsError{simpleError{msg}}
+}
+
+// IsTooManyRequestsError returns true if the cause of the given error can be
+// converted to a TooManyRequestsError, which is returned as the second result.
+func IsTooManyRequestsError(err error) (bool, error) {
+	e, ok := errs.Cause(err).(TooManyRequestsError)
+	if !ok {
+		return false, nil
+	}
+	return true, e
+}
+
 // VersionConflictError means that the version was not as expected in an update operation
 type VersionConflictError struct {
 	simpleError
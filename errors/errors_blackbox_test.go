@@ -104,6 +104,9 @@ func TestIsXYError(t *testing.T) {
 		{"IsVersionConflictError - is a VersionConflictError", errors.NewVersionConflictError("some message"), errors.IsVersionConflictError, true},
 		{"IsVersionConflictError - is a wrapped VersionConflictError", errs.Wrap(errs.Wrap(errors.NewVersionConflictError("some message"), "msg1"), "msg2"), errors.IsVersionConflictError, true},
 		{"IsVersionConflictError - is not a VersionConflictError", errors.NewInternalError(ctx, errs.New("some message")), errors.IsVersionConflictError, false},
+		{"IsTooManyRequestsError - is a TooManyRequestsError", errors.NewTooManyRequestsError("some message"), errors.IsTooManyRequestsError, true},
+		{"IsTooManyRequestsError - is a wrapped TooManyRequestsError", errs.Wrap(errs.Wrap(errors.NewTooManyRequestsError("some message"), "msg1"), "msg2"), errors.IsTooManyRequestsError, true},
+		{"IsTooManyRequestsError - is not a TooManyRequestsError", errors.NewForbiddenError("some message"), errors.IsTooManyRequestsError, false},
 	}
 	for _, tc := range testCases {
 		// Note that we need to capture the range variable to ensure that tc
@@ -15,11 +15,38 @@ import (
 	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 )
 
+// TokenResolver resolves a token that is not a JWT, e.g. a personal access
+// token, into the claims of the JWT standing for it in the context. The claims
+// must contain the identity ID in the "sub" claim. It returns nil claims and no
+// error if the token is not one it knows about.
+type TokenResolver func(ctx context.Context, incomingToken string) (jwt.MapClaims, error)
+
+type contextResolvedTokenKey struct{}
+
+// IsResolvedToken returns true if the token in the context was given by one of
+// the TokenResolvers rather than parsed from a JWT
+func IsResolvedToken(ctx context.Context) bool {
+	resolved, _ := ctx.Value(contextResolvedTokenKey{}).(bool)
+	return resolved
+}
+
+// ForwardableJWT returns the token in the context if it may be sent to the
+// other services on behalf of the user, i.e. a JWT parsed from the request. It
+// returns nil for the tokens given by the TokenResolvers (e.g. a personal
+// access token), which must never leave this service.
+func ForwardableJWT(ctx context.Context) *jwt.Token {
+	if IsResolvedToken(ctx) {
+		return nil
+	}
+	return goajwt.ContextJWT(ctx)
+}
+
 // TokenContext is a new goa middleware that aims to extract the token from the
 // Authorization header when possible. If the Authorization header is missing in the request,
 // no error is returned. However, if the Authorization header contains a
-// token, it will be stored it in the context.
-func TokenContext(validationKeys interface{}, validationFunc goa.Middleware, scheme *goa.JWTSecurity) goa.Middleware {
+// token, it will be stored it in the context. The tokens that are not JWTs are
+// given to the resolvers, in order, until one of them knows about it.
+func TokenContext(validationKeys interface{}, validationFunc goa.Middleware, scheme *goa.JWTSecurity, resolvers ...TokenResolver) goa.Middleware {
 	var rsaKeys []*rsa.PublicKey
 	var hmacKeys [][]byte
 
@@ -38,6 +65,10 @@ func TokenContext(validationKeys interface{}, validationFunc goa.Middleware, sch
 				incomingToken := strings.Split(val, " ")[1]
 				log.Debug(ctx, nil, "extracted the incoming token %v ", incomingToken)
 
+				if resolvedCtx, resolved := resolveToken(ctx, resolvers, incomingToken); resolved {
+					return nextHandler(resolvedCtx, rw, req)
+				}
+
 				var (
 					token  *jwt.Token
 					err    error
@@ -77,6 +108,32 @@ func TokenContext(validationKeys interface{}, validationFunc goa.Middleware, sch
 	}
 }
 
+// resolveToken gives the incoming token to the resolvers and returns true if
+// one of them knows about it, along with the context holding the resolved
+// token if it's valid.
+func resolveToken(ctx context.Context, resolvers []TokenResolver, incomingToken string) (context.Context, bool) {
+	for _, resolve := range resolvers {
+		claims, err := resolve(ctx, incomingToken)
+		if err != nil {
+			log.Warn(ctx, nil, "unable to resolve token: %v", err)
+			return ctx, true
+		}
+		if claims == nil {
+			continue
+		}
+		token := &jwt.Token{
+			Raw:    incomingToken,
+			Method: jwt.SigningMethodNone,
+			Header: map[string]interface{}{},
+			Claims: claims,
+			Valid:  true,
+		}
+		ctx = goajwt.WithJWT(ctx, token)
+		return context.WithValue(ctx, contextResolvedTokenKey{}, true), true
+	}
+	return ctx, false
+}
+
 // partitionKeys sorts keys by their type.
 func partitionKeys(k interface{}) ([]*rsa.PublicKey, []*ecdsa.PublicKey, [][]byte) {
 	var (
@@ -4,8 +4,8 @@ import (
 	"context"
 	"net/http"
 
+	witmiddleware "github.com/fabric8-services/fabric8-wit/goamiddleware"
 	goaclient "github.com/goadesign/goa/client"
-	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 )
 
 // ForwardSigner reuse Token from caller and forward to target Request
@@ -19,9 +19,11 @@ func (f forwardSigner) Sign(request *http.Request) error {
 	return nil
 }
 
-// NewForwardSigner return a new signer based on current context
+// NewForwardSigner return a new signer based on current context. It returns
+// nil if the request has no token that may be forwarded (e.g. a personal
+// access token), so that the request is sent without one.
 func NewForwardSigner(ctx context.Context) goaclient.Signer {
-	token := goajwt.ContextJWT(ctx)
+	token := witmiddleware.ForwardableJWT(ctx)
 	if token == nil {
 		return nil
 	}
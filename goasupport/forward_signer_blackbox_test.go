@@ -0,0 +1,46 @@
+package goasupport_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/fabric8-services/fabric8-wit/goasupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardSigner(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+
+	t.Run("jwt", func(t *testing.T) {
+		// given
+		ctx := goajwt.WithJWT(context.Background(), &jwt.Token{Raw: "user-token"})
+		req, err := http.NewRequest(http.MethodGet, "http://tenant.example.com/api/tenant", nil)
+		require.NoError(t, err)
+		// when
+		signer := goasupport.NewForwardSigner(ctx)
+		// then
+		require.NotNil(t, signer)
+		require.NoError(t, signer.Sign(req))
+		assert.Equal(t, "Bearer user-token", req.Header.Get("Authorization"))
+	})
+
+	t.Run("personal access token", func(t *testing.T) {
+		// given
+		ctx := testsupport.WithResolvedToken(context.Background(), "f8pat_secret", testsupport.TestIdentity)
+		// when
+		signer := goasupport.NewForwardSigner(ctx)
+		// then the request is sent without the token
+		assert.Nil(t, signer)
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		assert.Nil(t, goasupport.NewForwardSigner(context.Background()))
+	})
+}
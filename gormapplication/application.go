@@ -8,17 +8,25 @@ import (
 	"github.com/fabric8-services/fabric8-wit/application"
 	"github.com/fabric8-services/fabric8-wit/area"
 	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/codebase/workspace"
 	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/deployment"
 	"github.com/fabric8-services/fabric8-wit/iteration"
 	"github.com/fabric8-services/fabric8-wit/label"
 	"github.com/fabric8-services/fabric8-wit/query"
 	"github.com/fabric8-services/fabric8-wit/remoteworkitem"
+	"github.com/fabric8-services/fabric8-wit/retention"
 	"github.com/fabric8-services/fabric8-wit/search"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 	"github.com/fabric8-services/fabric8-wit/spacetemplate"
+	"github.com/fabric8-services/fabric8-wit/token/accesstoken"
+	"github.com/fabric8-services/fabric8-wit/trash"
+	"github.com/fabric8-services/fabric8-wit/watcher"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/event"
 	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/fabric8-services/fabric8-wit/workitem/template"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 )
@@ -162,6 +170,66 @@ func (g *GormBase) WorkItemTypeGroups() workitem.WorkItemTypeGroupRepository {
 	return workitem.NewWorkItemTypeGroupRepository(g.db)
 }
 
+// Watchers returns a work item watchers repository
+func (g *GormBase) Watchers() watcher.Repository {
+	return watcher.NewRepository(g.db)
+}
+
+// NotificationPreferences returns a notification preferences repository
+func (g *GormBase) NotificationPreferences() watcher.PreferenceRepository {
+	return watcher.NewPreferenceRepository(g.db)
+}
+
+// WorkItemTemplates returns a work item templates repository
+func (g *GormBase) WorkItemTemplates() template.Repository {
+	return template.NewRepository(g.db)
+}
+
+// Trash returns a trash repository
+func (g *GormBase) Trash() trash.Repository {
+	return trash.NewRepository(g.db)
+}
+
+// SpaceRoles returns a space roles repository
+func (g *GormBase) SpaceRoles() role.Repository {
+	return role.NewRepository(g.db)
+}
+
+// PersonalAccessTokens returns a personal access tokens repository
+func (g *GormBase) PersonalAccessTokens() accesstoken.Repository {
+	return accesstoken.NewRepository(g.db)
+}
+
+// Deployments returns a deployments repository
+func (g *GormBase) Deployments() deployment.Repository {
+	return deployment.NewRepository(g.db)
+}
+
+// CodeReferences returns a code references repository
+func (g *GormBase) CodeReferences() codebase.ReferenceRepository {
+	return codebase.NewReferenceRepository(g.db)
+}
+
+// CodebaseMetadata returns a codebase metadata repository
+func (g *GormBase) CodebaseMetadata() codebase.MetadataRepository {
+	return codebase.NewMetadataRepository(g.db)
+}
+
+// CodebaseWorkspaces returns a codebase workspace repository
+func (g *GormBase) CodebaseWorkspaces() workspace.Repository {
+	return workspace.NewRepository(g.db)
+}
+
+// WorkspacePolicies returns a workspace policy repository
+func (g *GormBase) WorkspacePolicies() workspace.PolicyRepository {
+	return workspace.NewPolicyRepository(g.db)
+}
+
+// RetentionPolicies returns a retention policy repository
+func (g *GormBase) RetentionPolicies() retention.PolicyRepository {
+	return retention.NewPolicyRepository(g.db)
+}
+
 func (g *GormBase) DB() *gorm.DB {
 	return g.db
 }
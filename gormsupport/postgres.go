@@ -7,6 +7,7 @@ const (
 	errUniqueViolation     = "23505"
 	errForeignKeyViolation = "23503"
 	errInvalidCatalogName  = "3D000"
+	errLockNotAvailable    = "55P03"
 )
 
 // IsCheckViolation returns true if the error is a violation of the given check
@@ -57,3 +58,16 @@ func IsForeignKeyViolation(err error, indexName string) bool {
 	}
 	return pqError.Code == errForeignKeyViolation && pqError.Constraint == indexName
 }
+
+// IsLockNotAvailable returns true if the error says that a lock could not be
+// obtained, e.g. because the lock timeout expired while waiting for it
+func IsLockNotAvailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	pqError, ok := err.(*pq.Error)
+	if !ok {
+		return false
+	}
+	return pqError.Code == errLockNotAvailable
+}
@@ -0,0 +1,5 @@
+// Package graphql contains the read-only GraphQL schema of the spaces and
+// their work items, resolved with the repositories of the application. The
+// entities referred to by lists of work items are loaded in batches and every
+// query has a cost budget, the number of entities it can load or list.
+package graphql
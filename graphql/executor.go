@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/fabric8-services/fabric8-wit/application"
+	gql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Config holds the limits of the queries
+type Config interface {
+	GetGraphQLMaxDepth() int
+	GetGraphQLMaxCost() int
+}
+
+// AuthorizeFunc returns an error if the current user is not allowed to read
+// the given space
+type AuthorizeFunc func(ctx context.Context, appl application.Application, spaceID uuid.UUID) error
+
+// Request is a GraphQL request, as sent by the clients
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Executor runs the GraphQL queries
+type Executor struct {
+	db        application.DB
+	schema    *gql.Schema
+	maxCost   int
+	authorize AuthorizeFunc
+}
+
+// NewExecutor returns an executor of the queries against the given database.
+// The spaces are only read if the given function authorizes it.
+func NewExecutor(db application.DB, config Config, authorize AuthorizeFunc) (*Executor, error) {
+	// the resolvers share the transaction of the request and its loaders, so
+	// they must run one at a time
+	s, err := gql.ParseSchema(schema, &queryResolver{}, gql.MaxDepth(config.GetGraphQLMaxDepth()), gql.MaxParallelism(1))
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to parse the GraphQL schema")
+	}
+	return &Executor{
+		db:        db,
+		schema:    s,
+		maxCost:   config.GetGraphQLMaxCost(),
+		authorize: authorize,
+	}, nil
+}
+
+// Exec runs the query of the given request in a single transaction. The
+// errors of the query are returned in the response, along with the data that
+// could be resolved, unless the query exceeds its cost limit in which case it
+// is aborted and only this error is returned.
+func (e *Executor) Exec(ctx context.Context, req Request) (*gql.Response, error) {
+	var res *gql.Response
+	err := application.Transactional(e.db, func(appl application.Application) error {
+		r := newRequest(appl, e.authorize, e.maxCost)
+		res = e.schema.Exec(context.WithValue(ctx, requestKey, r), req.Query, req.OperationName, req.Variables)
+		if r.exceeded() {
+			res = &gql.Response{
+				Errors: []*gqlerrors.QueryError{gqlerrors.Errorf("the query exceeds the maximum cost of %d", e.maxCost)},
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,345 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/graphql"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunExecutor(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &executorBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+type executorBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	db application.DB
+}
+
+func (s *executorBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+type testConfig struct {
+	maxDepth int
+	maxCost  int
+}
+
+func (c testConfig) GetGraphQLMaxDepth() int {
+	return c.maxDepth
+}
+
+func (c testConfig) GetGraphQLMaxCost() int {
+	return c.maxCost
+}
+
+func allowAll(ctx context.Context, appl application.Application, spaceID uuid.UUID) error {
+	return nil
+}
+
+// fixture creates the work items "A", "B" and "C" in the iteration "sprint1",
+// assigned to the first identity and labeled "important", "B" and "C" being
+// the children of "A", and a comment on "A"
+func (s *executorBlackBoxTest) fixture(t *testing.T) *tf.TestFixture {
+	return tf.NewTestFixture(t, s.DB,
+		tf.Identities(1),
+		tf.Iterations(2, tf.SetIterationNames("root", "sprint1"), tf.PlaceIterationUnderRootIteration()),
+		tf.Labels(1, tf.SetLabelNames("important")),
+		tf.WorkItems(3, tf.SetWorkItemTitles("A", "B", "C"), func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItems[idx].Fields[workitem.SystemIteration] = fxt.IterationByName("sprint1").ID.String()
+			fxt.WorkItems[idx].Fields[workitem.SystemAssignees] = []interface{}{fxt.Identities[0].ID.String()}
+			fxt.WorkItems[idx].Fields[workitem.SystemLabels] = []interface{}{fxt.Labels[0].ID.String()}
+			return nil
+		}),
+		tf.WorkItemLinksCustom(2,
+			tf.BuildLinks(tf.L("A", "B"), tf.L("A", "C")),
+			func(fxt *tf.TestFixture, idx int) error {
+				fxt.WorkItemLinks[idx].LinkTypeID = link.SystemWorkItemLinkTypeParentChildID
+				return nil
+			},
+		),
+		tf.Comments(1),
+	)
+}
+
+// exec runs the given query and returns its data, decoded in the given value,
+// and its errors
+func (s *executorBlackBoxTest) exec(t *testing.T, config testConfig, authorize graphql.AuthorizeFunc, query string, data interface{}) []string {
+	executor, err := graphql.NewExecutor(s.db, config, authorize)
+	require.NoError(t, err)
+	res, err := executor.Exec(context.Background(), graphql.Request{Query: query})
+	require.NoError(t, err)
+	var errs []string
+	for _, e := range res.Errors {
+		errs = append(errs, e.Message)
+	}
+	if res.Data != nil {
+		require.NoError(t, json.Unmarshal(res.Data, data))
+	}
+	return errs
+}
+
+var defaultConfig = testConfig{maxDepth: 10, maxCost: 1000}
+
+func (s *executorBlackBoxTest) TestSpace() {
+	s.T().Run("work items with their references", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		query := fmt.Sprintf(`{
+			space(id: "%s") {
+				name
+				workItems(first: 10) {
+					nodes {
+						title
+						iteration { path }
+						assignees { username }
+						labels { name }
+						parent { title }
+						children { title }
+					}
+					hasNextPage
+				}
+			}
+		}`, fxt.Spaces[0].ID)
+		var data struct {
+			Space struct {
+				Name      string
+				WorkItems struct {
+					Nodes []struct {
+						Title     string
+						Iteration struct{ Path string }
+						Assignees []struct{ Username string }
+						Labels    []struct{ Name string }
+						Parent    *struct{ Title string }
+						Children  []struct{ Title string }
+					}
+					HasNextPage bool
+				}
+			}
+		}
+		// when
+		errs := s.exec(t, defaultConfig, allowAll, query, &data)
+		// then
+		require.Empty(t, errs)
+		assert.Equal(t, fxt.Spaces[0].Name, data.Space.Name)
+		assert.False(t, data.Space.WorkItems.HasNextPage)
+		require.Len(t, data.Space.WorkItems.Nodes, 3)
+		for _, node := range data.Space.WorkItems.Nodes {
+			assert.Equal(t, "/root/sprint1", node.Iteration.Path)
+			require.Len(t, node.Assignees, 1)
+			assert.Equal(t, fxt.Identities[0].Username, node.Assignees[0].Username)
+			require.Len(t, node.Labels, 1)
+			assert.Equal(t, "important", node.Labels[0].Name)
+			if node.Title == "A" {
+				assert.Nil(t, node.Parent)
+				assert.Len(t, node.Children, 2)
+			} else {
+				require.NotNil(t, node.Parent)
+				assert.Equal(t, "A", node.Parent.Title)
+				assert.Empty(t, node.Children)
+			}
+		}
+	})
+
+	s.T().Run("pages", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		var seen []string
+		after := "null"
+		for i := 0; i < 3; i++ {
+			query := fmt.Sprintf(`{ space(id: "%s") { workItems(first: 1, after: %s) { nodes { title } endCursor hasNextPage } } }`, fxt.Spaces[0].ID, after)
+			var data struct {
+				Space struct {
+					WorkItems struct {
+						Nodes       []struct{ Title string }
+						EndCursor   string
+						HasNextPage bool
+					}
+				}
+			}
+			// when
+			errs := s.exec(t, defaultConfig, allowAll, query, &data)
+			// then
+			require.Empty(t, errs)
+			require.Len(t, data.Space.WorkItems.Nodes, 1)
+			assert.Equal(t, i < 2, data.Space.WorkItems.HasNextPage)
+			seen = append(seen, data.Space.WorkItems.Nodes[0].Title)
+			after = fmt.Sprintf("%q", data.Space.WorkItems.EndCursor)
+		}
+		assert.ElementsMatch(t, []string{"A", "B", "C"}, seen)
+	})
+
+	s.T().Run("not found", func(t *testing.T) {
+		// given
+		var data struct {
+			Space *struct{ Name string }
+		}
+		// when
+		errs := s.exec(t, defaultConfig, allowAll, fmt.Sprintf(`{ space(id: "%s") { name } }`, uuid.NewV4()), &data)
+		// then
+		assert.Empty(t, errs)
+		assert.Nil(t, data.Space)
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		forbid := func(ctx context.Context, appl application.Application, spaceID uuid.UUID) error {
+			return errors.NewForbiddenError("user is not allowed to read this space")
+		}
+		var data struct {
+			Space *struct{ Name string }
+		}
+		// when
+		errs := s.exec(t, defaultConfig, forbid, fmt.Sprintf(`{ space(id: "%s") { name } }`, fxt.Spaces[0].ID), &data)
+		// then
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0], "not allowed")
+		assert.Nil(t, data.Space)
+	})
+}
+
+func (s *executorBlackBoxTest) TestWorkItem() {
+	s.T().Run("typed fields", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		a := fxt.WorkItemByTitle("A")
+		query := fmt.Sprintf(`{
+			workItem(id: "%s") {
+				number
+				type { name }
+				fields {
+					name
+					kind
+					... on TextField { text }
+					... on IterationField { iteration { name } }
+					... on ListField { items { ... on UserField { user { username } } ... on LabelField { label { name } } } }
+				}
+				title: field(name: "%s") { ... on TextField { text } }
+				missing: field(name: "unknown") { name }
+				comments { body creator { username } }
+			}
+		}`, a.ID, workitem.SystemTitle)
+		type field struct {
+			Name      string
+			Kind      string
+			Text      *string
+			Iteration *struct{ Name string }
+			Items     []struct {
+				User  *struct{ Username string }
+				Label *struct{ Name string }
+			}
+		}
+		var data struct {
+			WorkItem struct {
+				Number   int
+				Type     struct{ Name string }
+				Fields   []field
+				Title    field
+				Missing  *field
+				Comments []struct {
+					Body    string
+					Creator struct{ Username string }
+				}
+			}
+		}
+		// when
+		errs := s.exec(t, defaultConfig, allowAll, query, &data)
+		// then
+		require.Empty(t, errs)
+		assert.Equal(t, a.Number, data.WorkItem.Number)
+		assert.Equal(t, fxt.WorkItemTypes[0].Name, data.WorkItem.Type.Name)
+		require.NotNil(t, data.WorkItem.Title.Text)
+		assert.Equal(t, "A", *data.WorkItem.Title.Text)
+		assert.Nil(t, data.WorkItem.Missing)
+		fields := map[string]field{}
+		for _, f := range data.WorkItem.Fields {
+			fields[f.Name] = f
+		}
+		require.NotNil(t, fields[workitem.SystemIteration].Iteration)
+		assert.Equal(t, "sprint1", fields[workitem.SystemIteration].Iteration.Name)
+		assert.Equal(t, string(workitem.KindList), fields[workitem.SystemAssignees].Kind)
+		require.Len(t, fields[workitem.SystemAssignees].Items, 1)
+		require.NotNil(t, fields[workitem.SystemAssignees].Items[0].User)
+		assert.Equal(t, fxt.Identities[0].Username, fields[workitem.SystemAssignees].Items[0].User.Username)
+		require.Len(t, fields[workitem.SystemLabels].Items, 1)
+		require.NotNil(t, fields[workitem.SystemLabels].Items[0].Label)
+		assert.Equal(t, "important", fields[workitem.SystemLabels].Items[0].Label.Name)
+		require.Len(t, data.WorkItem.Comments, 1)
+		assert.Equal(t, fxt.Comments[0].Body, data.WorkItem.Comments[0].Body)
+	})
+
+	s.T().Run("links", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		query := fmt.Sprintf(`{ workItem(id: "%s") { links { linkType { forwardName } source { title } target { title } } } }`, fxt.WorkItemByTitle("B").ID)
+		var data struct {
+			WorkItem struct {
+				Links []struct {
+					LinkType struct{ ForwardName string }
+					Source   struct{ Title string }
+					Target   struct{ Title string }
+				}
+			}
+		}
+		// when
+		errs := s.exec(t, defaultConfig, allowAll, query, &data)
+		// then
+		require.Empty(t, errs)
+		require.Len(t, data.WorkItem.Links, 1)
+		assert.Equal(t, "A", data.WorkItem.Links[0].Source.Title)
+		assert.Equal(t, "B", data.WorkItem.Links[0].Target.Title)
+	})
+}
+
+func (s *executorBlackBoxTest) TestLimits() {
+	s.T().Run("cost", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		query := fmt.Sprintf(`{ space(id: "%s") { workItems { nodes { title assignees { username } } } } }`, fxt.Spaces[0].ID)
+		var data struct{}
+		// when
+		errs := s.exec(t, testConfig{maxDepth: 10, maxCost: 3}, allowAll, query, &data)
+		// then
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0], "maximum cost of 3")
+	})
+
+	s.T().Run("depth", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		query := fmt.Sprintf(`{ space(id: "%s") { workItems { nodes { parent { children { title } } } } } }`, fxt.Spaces[0].ID)
+		var data struct{}
+		// when
+		errs := s.exec(t, testConfig{maxDepth: 3, maxCost: 1000}, allowAll, query, &data)
+		// then
+		assert.NotEmpty(t, errs)
+	})
+
+	s.T().Run("page size", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		query := fmt.Sprintf(`{ space(id: "%s") { workItems(first: 1000) { nodes { title } } } }`, fxt.Spaces[0].ID)
+		var data struct{}
+		// when
+		errs := s.exec(t, defaultConfig, allowAll, query, &data)
+		// then
+		assert.NotEmpty(t, errs)
+	})
+}
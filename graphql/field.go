@@ -0,0 +1,251 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	gql "github.com/graph-gophers/graphql-go"
+	uuid "github.com/satori/go.uuid"
+)
+
+// fieldResolver resolves the Field interface for the value of a field of a
+// work item. Its concrete type depends on the kind of the field, or of the
+// values of an enum, and the elements of a list are fields of the kind of its
+// component.
+type fieldResolver struct {
+	r       *request
+	spaceID uuid.UUID
+	name    string
+	t       workitem.FieldType
+	value   interface{}
+}
+
+func (f *fieldResolver) Name() string {
+	return f.name
+}
+
+func (f *fieldResolver) Kind() string {
+	return string(f.t.GetKind())
+}
+
+// valueKind returns the kind of the value of the field
+func (f *fieldResolver) valueKind() workitem.Kind {
+	if t, ok := f.t.(workitem.EnumType); ok {
+		return t.BaseType.GetKind()
+	}
+	return f.t.GetKind()
+}
+
+func (f *fieldResolver) ToTextField() (*textFieldResolver, bool) {
+	switch f.valueKind() {
+	case workitem.KindString, workitem.KindURL, workitem.KindMarkup, workitem.KindCodebase:
+		return &textFieldResolver{f}, true
+	}
+	return nil, false
+}
+
+func (f *fieldResolver) ToNumberField() (*numberFieldResolver, bool) {
+	switch f.valueKind() {
+	case workitem.KindInteger, workitem.KindFloat, workitem.KindDuration:
+		return &numberFieldResolver{f}, true
+	}
+	return nil, false
+}
+
+func (f *fieldResolver) ToBooleanField() (*booleanFieldResolver, bool) {
+	if f.valueKind() == workitem.KindBoolean {
+		return &booleanFieldResolver{f}, true
+	}
+	return nil, false
+}
+
+func (f *fieldResolver) ToInstantField() (*instantFieldResolver, bool) {
+	if f.valueKind() == workitem.KindInstant {
+		return &instantFieldResolver{f}, true
+	}
+	return nil, false
+}
+
+func (f *fieldResolver) ToUserField() (*userFieldResolver, bool) {
+	if f.valueKind() == workitem.KindUser {
+		return &userFieldResolver{f}, true
+	}
+	return nil, false
+}
+
+func (f *fieldResolver) ToIterationField() (*iterationFieldResolver, bool) {
+	if f.valueKind() == workitem.KindIteration {
+		return &iterationFieldResolver{f}, true
+	}
+	return nil, false
+}
+
+func (f *fieldResolver) ToAreaField() (*areaFieldResolver, bool) {
+	if f.valueKind() == workitem.KindArea {
+		return &areaFieldResolver{f}, true
+	}
+	return nil, false
+}
+
+func (f *fieldResolver) ToLabelField() (*labelFieldResolver, bool) {
+	if f.valueKind() == workitem.KindLabel {
+		return &labelFieldResolver{f}, true
+	}
+	return nil, false
+}
+
+func (f *fieldResolver) ToListField() (*listFieldResolver, bool) {
+	if t, ok := f.t.(workitem.ListType); ok {
+		return &listFieldResolver{fieldResolver: f, t: t}, true
+	}
+	return nil, false
+}
+
+// id returns the ID held by the value of the field, if any
+func (f *fieldResolver) id() *uuid.UUID {
+	ids := referencedIDs(f.value)
+	if len(ids) == 0 {
+		return nil
+	}
+	return &ids[0]
+}
+
+type textFieldResolver struct {
+	*fieldResolver
+}
+
+func (f *textFieldResolver) Text() *string {
+	if s, ok := displayText(f.value); ok {
+		return &s
+	}
+	return nil
+}
+
+type numberFieldResolver struct {
+	*fieldResolver
+}
+
+func (f *numberFieldResolver) Number() *float64 {
+	var n float64
+	switch v := f.value.(type) {
+	case float64:
+		n = v
+	case float32:
+		n = float64(v)
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case int32:
+		n = float64(v)
+	default:
+		return nil
+	}
+	return &n
+}
+
+type booleanFieldResolver struct {
+	*fieldResolver
+}
+
+func (f *booleanFieldResolver) Boolean() *bool {
+	if b, ok := f.value.(bool); ok {
+		return &b
+	}
+	return nil
+}
+
+type instantFieldResolver struct {
+	*fieldResolver
+}
+
+func (f *instantFieldResolver) Instant() *gql.Time {
+	return timeField(f.value)
+}
+
+type userFieldResolver struct {
+	*fieldResolver
+}
+
+func (f *userFieldResolver) User(ctx context.Context) (*userResolver, error) {
+	id := f.id()
+	if id == nil {
+		return nil, nil
+	}
+	return f.r.userResolver(ctx, *id)
+}
+
+type iterationFieldResolver struct {
+	*fieldResolver
+}
+
+func (f *iterationFieldResolver) Iteration(ctx context.Context) (*iterationResolver, error) {
+	id := f.id()
+	if id == nil {
+		return nil, nil
+	}
+	return f.r.iterationResolver(ctx, *id)
+}
+
+type areaFieldResolver struct {
+	*fieldResolver
+}
+
+func (f *areaFieldResolver) Area(ctx context.Context) (*areaResolver, error) {
+	id := f.id()
+	if id == nil {
+		return nil, nil
+	}
+	return f.r.areaResolver(ctx, *id)
+}
+
+type labelFieldResolver struct {
+	*fieldResolver
+}
+
+func (f *labelFieldResolver) Label(ctx context.Context) (*labelResolver, error) {
+	id := f.id()
+	if id == nil {
+		return nil, nil
+	}
+	return f.r.labelResolver(ctx, f.spaceID, *id)
+}
+
+type listFieldResolver struct {
+	*fieldResolver
+	t workitem.ListType
+}
+
+func (f *listFieldResolver) Items() []*fieldResolver {
+	values, _ := f.value.([]interface{})
+	res := make([]*fieldResolver, len(values))
+	for i, v := range values {
+		res[i] = &fieldResolver{
+			r:       f.r,
+			spaceID: f.spaceID,
+			name:    f.name,
+			t:       f.t.ComponentType,
+			value:   v,
+		}
+	}
+	return res
+}
+
+// displayText returns the text of a value of a text field: the content of a
+// markup or the repository of a codebase
+func displayText(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case rendering.MarkupContent:
+		return v.Content, true
+	case codebase.Content:
+		return v.Repository, true
+	}
+	return fmt.Sprint(value), true
+}
@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// batchFunc loads the entities with the given IDs, the missing ones being
+// left out of the result
+type batchFunc func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]interface{}, error)
+
+// loader loads entities by ID in batches: the IDs of the entities that will
+// likely be needed, like the iterations of a list of work items, are primed
+// and loaded at once along with the first of them that is actually needed.
+// Each entity is loaded once per request and adds to its cost.
+type loader struct {
+	name    string
+	request *request
+	batch   batchFunc
+	primed  map[uuid.UUID]struct{}
+	loaded  map[uuid.UUID]interface{}
+	missing map[uuid.UUID]struct{}
+}
+
+func newLoader(name string, r *request, batch batchFunc) *loader {
+	return &loader{
+		name:    name,
+		request: r,
+		batch:   batch,
+		primed:  map[uuid.UUID]struct{}{},
+		loaded:  map[uuid.UUID]interface{}{},
+		missing: map[uuid.UUID]struct{}{},
+	}
+}
+
+// prime adds the given IDs to the next batch
+func (l *loader) prime(ids ...uuid.UUID) {
+	for _, id := range ids {
+		if !l.known(id) {
+			l.primed[id] = struct{}{}
+		}
+	}
+}
+
+// add adds an entity that was loaded otherwise
+func (l *loader) add(id uuid.UUID, v interface{}) {
+	delete(l.primed, id)
+	l.loaded[id] = v
+}
+
+// load returns the entity with the given ID, loading it along with the primed
+// ones if it was not loaded yet
+func (l *loader) load(ctx context.Context, id uuid.UUID) (interface{}, error) {
+	if !l.known(id) {
+		l.primed[id] = struct{}{}
+		ids := make([]uuid.UUID, 0, len(l.primed))
+		for primedID := range l.primed {
+			ids = append(ids, primedID)
+		}
+		l.primed = map[uuid.UUID]struct{}{}
+		if err := l.request.charge(len(ids)); err != nil {
+			return nil, err
+		}
+		res, err := l.batch(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, batchID := range ids {
+			if v, ok := res[batchID]; ok {
+				l.loaded[batchID] = v
+			} else {
+				l.missing[batchID] = struct{}{}
+			}
+		}
+	}
+	if v, ok := l.loaded[id]; ok {
+		return v, nil
+	}
+	return nil, errors.NewNotFoundError(l.name, id.String())
+}
+
+func (l *loader) known(id uuid.UUID) bool {
+	if _, ok := l.loaded[id]; ok {
+		return true
+	}
+	_, ok := l.missing[id]
+	return ok
+}
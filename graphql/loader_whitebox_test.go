@@ -0,0 +1,78 @@
+package graphql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	existing := []uuid.UUID{uuid.NewV4(), uuid.NewV4(), uuid.NewV4()}
+	missing := uuid.NewV4()
+
+	newTestLoader := func(maxCost int) (*loader, *[][]uuid.UUID) {
+		var batches [][]uuid.UUID
+		l := newLoader("thing", &request{maxCost: maxCost}, func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]interface{}, error) {
+			batches = append(batches, ids)
+			res := map[uuid.UUID]interface{}{}
+			for _, id := range ids {
+				if id != missing {
+					res[id] = id.String()
+				}
+			}
+			return res, nil
+		})
+		return l, &batches
+	}
+
+	t.Run("primed entities are loaded in one batch", func(t *testing.T) {
+		// given
+		l, batches := newTestLoader(10)
+		l.prime(existing...)
+		l.prime(missing)
+		// when
+		for _, id := range existing {
+			v, err := l.load(context.Background(), id)
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, id.String(), v)
+		}
+		_, err := l.load(context.Background(), missing)
+		notFound, _ := errors.IsNotFoundError(err)
+		assert.True(t, notFound)
+		require.Len(t, *batches, 1)
+		assert.Len(t, (*batches)[0], 4)
+		assert.Equal(t, 4, l.request.cost)
+	})
+
+	t.Run("added entities are not loaded", func(t *testing.T) {
+		// given
+		l, batches := newTestLoader(10)
+		l.prime(existing[0])
+		l.add(existing[0], "added")
+		// when
+		v, err := l.load(context.Background(), existing[0])
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "added", v)
+		assert.Empty(t, *batches)
+	})
+
+	t.Run("cost exceeded", func(t *testing.T) {
+		// given
+		l, batches := newTestLoader(2)
+		l.prime(existing...)
+		// when
+		_, err := l.load(context.Background(), existing[0])
+		// then
+		require.Error(t, err)
+		assert.True(t, l.request.exceeded())
+		assert.Empty(t, *batches)
+	})
+}
@@ -0,0 +1,386 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/area"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/label"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+type contextKey int
+
+const requestKey contextKey = iota
+
+// request holds the state of the execution of a query: its transaction, its
+// cost so far and the entities it loaded. The resolvers run one at a time, so
+// it is not locked.
+type request struct {
+	appl      application.Application
+	authorize AuthorizeFunc
+	cost      int
+	maxCost   int
+
+	spaces     map[uuid.UUID]*space.Space
+	authorized map[uuid.UUID]error
+	types      map[uuid.UUID]*workitem.WorkItemType
+	linkTypes  map[uuid.UUID]*link.WorkItemLinkType
+	labels     map[uuid.UUID]map[uuid.UUID]label.Label
+
+	workItems  *loader
+	iterations *loader
+	areas      *loader
+	users      *loader
+	// parents maps the work items to the ID of their parent
+	parents *loader
+	// children maps the work items to the IDs of their children
+	children *loader
+}
+
+func newRequest(appl application.Application, authorize AuthorizeFunc, maxCost int) *request {
+	r := &request{
+		appl:       appl,
+		authorize:  authorize,
+		maxCost:    maxCost,
+		spaces:     map[uuid.UUID]*space.Space{},
+		authorized: map[uuid.UUID]error{},
+		types:      map[uuid.UUID]*workitem.WorkItemType{},
+		linkTypes:  map[uuid.UUID]*link.WorkItemLinkType{},
+		labels:     map[uuid.UUID]map[uuid.UUID]label.Label{},
+	}
+	r.workItems = newLoader("work item", r, r.batchWorkItems)
+	r.iterations = newLoader("iteration", r, r.batchIterations)
+	r.areas = newLoader("area", r, r.batchAreas)
+	r.users = newLoader("identity", r, r.batchUsers)
+	r.parents = newLoader("parent", r, r.batchParents)
+	r.children = newLoader("children", r, r.batchChildren)
+	return r
+}
+
+// requestFrom returns the state of the request executed with the given context
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey).(*request)
+}
+
+// charge adds the given cost to the cost of the query, and returns an error if
+// the query exceeds its maximum cost
+func (r *request) charge(cost int) error {
+	r.cost += cost
+	if r.exceeded() {
+		return errs.Errorf("the query exceeds the maximum cost of %d", r.maxCost)
+	}
+	return nil
+}
+
+func (r *request) exceeded() bool {
+	return r.cost > r.maxCost
+}
+
+// space returns the space with the given ID if the current user is allowed to
+// read it
+func (r *request) space(ctx context.Context, id uuid.UUID) (*space.Space, error) {
+	if err := r.authorizeSpace(ctx, id); err != nil {
+		return nil, err
+	}
+	if s, ok := r.spaces[id]; ok {
+		return s, nil
+	}
+	if err := r.charge(1); err != nil {
+		return nil, err
+	}
+	s, err := r.appl.Spaces().Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.spaces[id] = s
+	return s, nil
+}
+
+// authorizeSpace returns an error if the current user is not allowed to read
+// the given space, checking it only once per space
+func (r *request) authorizeSpace(ctx context.Context, spaceID uuid.UUID) error {
+	err, ok := r.authorized[spaceID]
+	if !ok {
+		err = r.authorize(ctx, r.appl, spaceID)
+		r.authorized[spaceID] = err
+	}
+	return err
+}
+
+// workItem returns the work item with the given ID if the current user is
+// allowed to read its space
+func (r *request) workItem(ctx context.Context, id uuid.UUID) (*workitem.WorkItem, error) {
+	v, err := r.workItems.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	wi := v.(*workitem.WorkItem)
+	if err := r.authorizeSpace(ctx, wi.SpaceID); err != nil {
+		return nil, err
+	}
+	return wi, nil
+}
+
+// iteration returns the iteration with the given ID
+func (r *request) iteration(ctx context.Context, id uuid.UUID) (*iteration.Iteration, error) {
+	v, err := r.iterations.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*iteration.Iteration), nil
+}
+
+// area returns the area with the given ID
+func (r *request) area(ctx context.Context, id uuid.UUID) (*area.Area, error) {
+	v, err := r.areas.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*area.Area), nil
+}
+
+// user returns the identity with the given ID, along with its user
+func (r *request) user(ctx context.Context, id uuid.UUID) (*account.Identity, error) {
+	v, err := r.users.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*account.Identity), nil
+}
+
+// parentID returns the ID of the parent of the given work item, nil if it has
+// no parent
+func (r *request) parentID(ctx context.Context, id uuid.UUID) (*uuid.UUID, error) {
+	v, err := r.parents.load(ctx, id)
+	if err != nil {
+		if notFound, _ := errors.IsNotFoundError(err); notFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	parentID := v.(uuid.UUID)
+	return &parentID, nil
+}
+
+// childIDs returns the IDs of the children of the given work item
+func (r *request) childIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	v, err := r.children.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]uuid.UUID), nil
+}
+
+// addWorkItems adds the given work items, which were listed, to the loaded
+// ones and primes the loaders with the entities they refer to
+func (r *request) addWorkItems(ctx context.Context, wis []*workitem.WorkItem) error {
+	for _, wi := range wis {
+		r.workItems.add(wi.ID, wi)
+	}
+	return r.primeReferences(ctx, wis)
+}
+
+// primeReferences primes the loaders with the users, iterations and areas the
+// fields of the given work items refer to, and with their parents and
+// children, so that they are loaded in batches
+func (r *request) primeReferences(ctx context.Context, wis []*workitem.WorkItem) error {
+	for _, wi := range wis {
+		wit, err := r.workItemType(ctx, wi.Type)
+		if err != nil {
+			return err
+		}
+		for name, def := range wit.Fields {
+			var l *loader
+			switch componentKind(def.Type) {
+			case workitem.KindUser:
+				l = r.users
+			case workitem.KindIteration:
+				l = r.iterations
+			case workitem.KindArea:
+				l = r.areas
+			default:
+				continue
+			}
+			l.prime(referencedIDs(wi.Fields[name])...)
+		}
+		r.parents.prime(wi.ID)
+		r.children.prime(wi.ID)
+	}
+	return nil
+}
+
+// workItemType returns the work item type with the given ID
+func (r *request) workItemType(ctx context.Context, id uuid.UUID) (*workitem.WorkItemType, error) {
+	if wit, ok := r.types[id]; ok {
+		return wit, nil
+	}
+	if err := r.charge(1); err != nil {
+		return nil, err
+	}
+	wit, err := r.appl.WorkItemTypes().Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.types[id] = wit
+	return wit, nil
+}
+
+// linkType returns the work item link type with the given ID
+func (r *request) linkType(ctx context.Context, id uuid.UUID) (*link.WorkItemLinkType, error) {
+	if lt, ok := r.linkTypes[id]; ok {
+		return lt, nil
+	}
+	if err := r.charge(1); err != nil {
+		return nil, err
+	}
+	lt, err := r.appl.WorkItemLinkTypes().Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.linkTypes[id] = lt
+	return lt, nil
+}
+
+// label returns the label with the given ID of the given space, all the labels
+// of the space being loaded at once
+func (r *request) label(ctx context.Context, spaceID, id uuid.UUID) (*label.Label, error) {
+	labels, ok := r.labels[spaceID]
+	if !ok {
+		list, err := r.appl.Labels().List(ctx, spaceID)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.charge(len(list)); err != nil {
+			return nil, err
+		}
+		labels = make(map[uuid.UUID]label.Label, len(list))
+		for _, l := range list {
+			labels[l.ID] = l
+		}
+		r.labels[spaceID] = labels
+	}
+	l, ok := labels[id]
+	if !ok {
+		return nil, errors.NewNotFoundError("label", id.String())
+	}
+	return &l, nil
+}
+
+func (r *request) batchWorkItems(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]interface{}, error) {
+	wis, err := r.appl.WorkItems().LoadBatchByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uuid.UUID]interface{}, len(wis))
+	for _, wi := range wis {
+		res[wi.ID] = wi
+	}
+	return res, r.primeReferences(ctx, wis)
+}
+
+func (r *request) batchIterations(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]interface{}, error) {
+	itrs, err := r.appl.Iterations().LoadMultiple(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uuid.UUID]interface{}, len(itrs))
+	for i := range itrs {
+		res[itrs[i].ID] = &itrs[i]
+	}
+	return res, nil
+}
+
+func (r *request) batchAreas(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]interface{}, error) {
+	areas, err := r.appl.Areas().LoadMultiple(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uuid.UUID]interface{}, len(areas))
+	for i := range areas {
+		res[areas[i].ID] = &areas[i]
+	}
+	return res, nil
+}
+
+func (r *request) batchUsers(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]interface{}, error) {
+	identities, err := r.appl.Identities().Query(account.IdentityFilterByIDs(ids), account.IdentityWithUser())
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uuid.UUID]interface{}, len(identities))
+	for i := range identities {
+		res[identities[i].ID] = &identities[i]
+	}
+	return res, nil
+}
+
+func (r *request) batchParents(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]interface{}, error) {
+	ancestors, err := r.appl.WorkItemLinks().GetAncestors(ctx, link.SystemWorkItemLinkTypeParentChildID, link.AncestorLevelParent, ids...)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uuid.UUID]interface{}, len(ancestors))
+	for _, a := range ancestors {
+		if a.Level == int64(link.AncestorLevelParent) {
+			res[a.OriginalChildID] = a.ID
+		}
+	}
+	return res, nil
+}
+
+func (r *request) batchChildren(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]interface{}, error) {
+	links, err := r.appl.WorkItemLinks().ListChildLinks(ctx, link.SystemWorkItemLinkTypeParentChildID, ids...)
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[uuid.UUID][]uuid.UUID, len(ids))
+	for _, l := range links {
+		children[l.SourceID] = append(children[l.SourceID], l.TargetID)
+	}
+	res := make(map[uuid.UUID]interface{}, len(ids))
+	for _, id := range ids {
+		res[id] = children[id]
+	}
+	return res, nil
+}
+
+// componentKind returns the kind of the values of a field of the given type:
+// the kind of the elements of a list or of the values of an enum
+func componentKind(t workitem.FieldType) workitem.Kind {
+	switch t := t.(type) {
+	case workitem.ListType:
+		return t.ComponentType.GetKind()
+	case workitem.EnumType:
+		return t.BaseType.GetKind()
+	}
+	return t.GetKind()
+}
+
+// referencedIDs returns the IDs held by the given value of a field, either a
+// single ID or a list of them
+func referencedIDs(value interface{}) []uuid.UUID {
+	var res []uuid.UUID
+	add := func(v interface{}) {
+		if s, ok := v.(string); ok {
+			if id, err := uuid.FromString(s); err == nil {
+				res = append(res, id)
+			}
+		}
+	}
+	if values, ok := value.([]interface{}); ok {
+		for _, v := range values {
+			add(v)
+		}
+	} else {
+		add(value)
+	}
+	return res
+}
@@ -0,0 +1,261 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/search"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	gql "github.com/graph-gophers/graphql-go"
+	uuid "github.com/satori/go.uuid"
+)
+
+// The number of work items of a page, by default and at most
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// queryResolver resolves the fields of the Query type
+type queryResolver struct{}
+
+// Space resolves Query.space
+func (q *queryResolver) Space(ctx context.Context, args struct{ ID gql.ID }) (*spaceResolver, error) {
+	id, err := parseID("id", args.ID)
+	if err != nil {
+		return nil, err
+	}
+	r := requestFrom(ctx)
+	s, err := r.space(ctx, id)
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+	return &spaceResolver{r: r, s: s}, nil
+}
+
+// WorkItem resolves Query.workItem
+func (q *queryResolver) WorkItem(ctx context.Context, args struct{ ID gql.ID }) (*workItemResolver, error) {
+	id, err := parseID("id", args.ID)
+	if err != nil {
+		return nil, err
+	}
+	r := requestFrom(ctx)
+	wi, err := r.workItem(ctx, id)
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+	return &workItemResolver{r: r, wi: wi}, nil
+}
+
+type spaceResolver struct {
+	r *request
+	s *space.Space
+}
+
+func (s *spaceResolver) ID() gql.ID {
+	return gql.ID(s.s.ID.String())
+}
+
+func (s *spaceResolver) Name() string {
+	return s.s.Name
+}
+
+func (s *spaceResolver) Description() *string {
+	if s.s.Description == "" {
+		return nil
+	}
+	return &s.s.Description
+}
+
+func (s *spaceResolver) Owner(ctx context.Context) (*userResolver, error) {
+	return s.r.userResolver(ctx, s.s.OwnerID)
+}
+
+func (s *spaceResolver) CreatedAt() gql.Time {
+	return gql.Time{Time: s.s.CreatedAt}
+}
+
+func (s *spaceResolver) UpdatedAt() gql.Time {
+	return gql.Time{Time: s.s.UpdatedAt}
+}
+
+func (s *spaceResolver) WorkItem(ctx context.Context, args struct{ Number int32 }) (*workItemResolver, error) {
+	if err := s.r.charge(1); err != nil {
+		return nil, err
+	}
+	wi, err := s.r.appl.WorkItems().Load(ctx, s.s.ID, int(args.Number))
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+	if err := s.r.addWorkItems(ctx, []*workitem.WorkItem{wi}); err != nil {
+		return nil, err
+	}
+	return &workItemResolver{r: s.r, wi: wi}, nil
+}
+
+func (s *spaceResolver) WorkItems(ctx context.Context, args struct {
+	First  *int32
+	After  *gql.ID
+	Filter *string
+}) (*workItemConnectionResolver, error) {
+	limit, err := pageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	filter := fmt.Sprintf(`{"space": "%s"}`, s.s.ID)
+	if args.Filter != nil {
+		filter = fmt.Sprintf(`{"%s":[{"space": "%s" }, %s]}`, search.AND, s.s.ID, *args.Filter)
+	}
+	var after *workitem.WorkItem
+	if args.After != nil {
+		afterID, err := parseID("after", *args.After)
+		if err != nil {
+			return nil, err
+		}
+		if after, err = s.r.workItem(ctx, afterID); err != nil {
+			return nil, err
+		}
+	}
+	// one more work item is loaded to know if there is a next page
+	wis, err := s.r.appl.SearchItems().FilterAfter(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	res := &workItemConnectionResolver{}
+	if len(wis) > limit {
+		wis = wis[:limit]
+		res.hasNextPage = true
+	}
+	if err := s.r.charge(len(wis)); err != nil {
+		return nil, err
+	}
+	list := make([]*workitem.WorkItem, len(wis))
+	res.nodes = make([]*workItemResolver, len(wis))
+	for i := range wis {
+		list[i] = &wis[i]
+		res.nodes[i] = &workItemResolver{r: s.r, wi: &wis[i]}
+	}
+	if err := s.r.addWorkItems(ctx, list); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *spaceResolver) WorkItemTypes(ctx context.Context) ([]*workItemTypeResolver, error) {
+	wits, err := s.r.appl.WorkItemTypes().List(ctx, s.s.SpaceTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.r.charge(len(wits)); err != nil {
+		return nil, err
+	}
+	res := make([]*workItemTypeResolver, len(wits))
+	for i := range wits {
+		s.r.types[wits[i].ID] = &wits[i]
+		res[i] = &workItemTypeResolver{wit: &wits[i]}
+	}
+	return res, nil
+}
+
+func (s *spaceResolver) Iterations(ctx context.Context) ([]*iterationResolver, error) {
+	itrs, err := s.r.appl.Iterations().List(ctx, s.s.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.r.charge(len(itrs)); err != nil {
+		return nil, err
+	}
+	res := make([]*iterationResolver, len(itrs))
+	for i := range itrs {
+		s.r.iterations.add(itrs[i].ID, &itrs[i])
+		res[i] = &iterationResolver{r: s.r, itr: &itrs[i]}
+	}
+	return res, nil
+}
+
+func (s *spaceResolver) Areas(ctx context.Context) ([]*areaResolver, error) {
+	areas, err := s.r.appl.Areas().List(ctx, s.s.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.r.charge(len(areas)); err != nil {
+		return nil, err
+	}
+	res := make([]*areaResolver, len(areas))
+	for i := range areas {
+		s.r.areas.add(areas[i].ID, &areas[i])
+		res[i] = &areaResolver{r: s.r, a: &areas[i]}
+	}
+	return res, nil
+}
+
+func (s *spaceResolver) Labels(ctx context.Context) ([]*labelResolver, error) {
+	labels, err := s.r.appl.Labels().List(ctx, s.s.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.r.charge(len(labels)); err != nil {
+		return nil, err
+	}
+	res := make([]*labelResolver, len(labels))
+	for i := range labels {
+		res[i] = &labelResolver{l: &labels[i]}
+	}
+	return res, nil
+}
+
+type workItemConnectionResolver struct {
+	nodes       []*workItemResolver
+	hasNextPage bool
+}
+
+func (c *workItemConnectionResolver) Nodes() []*workItemResolver {
+	return c.nodes
+}
+
+func (c *workItemConnectionResolver) EndCursor() *gql.ID {
+	if len(c.nodes) == 0 {
+		return nil
+	}
+	return ptrID(c.nodes[len(c.nodes)-1].wi.ID)
+}
+
+func (c *workItemConnectionResolver) HasNextPage() bool {
+	return c.hasNextPage
+}
+
+// pageSize returns the size of a page given by the "first" argument
+func pageSize(first *int32) (int, error) {
+	if first == nil {
+		return defaultPageSize, nil
+	}
+	if *first < 1 || *first > maxPageSize {
+		return 0, errors.NewBadParameterError("first", *first).Expected(fmt.Sprintf("between 1 and %d", maxPageSize))
+	}
+	return int(*first), nil
+}
+
+// parseID returns the UUID held by the given ID argument
+func parseID(name string, id gql.ID) (uuid.UUID, error) {
+	res, err := uuid.FromString(string(id))
+	if err != nil {
+		return uuid.Nil, errors.NewBadParameterError(name, id).Expected("UUID")
+	}
+	return res, nil
+}
+
+func ptrID(id uuid.UUID) *gql.ID {
+	res := gql.ID(id.String())
+	return &res
+}
+
+// nullIfNotFound returns nil for a not found error, so that the missing
+// entity is resolved as null, or the given error otherwise
+func nullIfNotFound(err error) error {
+	if notFound, _ := errors.IsNotFoundError(err); notFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,212 @@
+package graphql
+
+// schema is the GraphQL schema, whose fields are resolved by the methods of
+// the same name of the resolvers
+const schema = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	# The space with the given ID, null if it doesn't exist
+	space(id: ID!): Space
+	# The work item with the given ID, null if it doesn't exist
+	workItem(id: ID!): WorkItem
+}
+
+type Space {
+	id: ID!
+	name: String!
+	description: String
+	owner: User
+	createdAt: Time!
+	updatedAt: Time!
+	# The work item of the space with the given number, null if it doesn't exist
+	workItem(number: Int!): WorkItem
+	# The work items of the space in the order of the work item lists, matching
+	# the filter expression (in the JSON format of the search API) if any. The
+	# next page starts after the endCursor of the previous one.
+	workItems(first: Int = 20, after: ID, filter: String): WorkItemConnection!
+	workItemTypes: [WorkItemType!]!
+	iterations: [Iteration!]!
+	areas: [Area!]!
+	labels: [Label!]!
+}
+
+type WorkItemConnection {
+	nodes: [WorkItem!]!
+	endCursor: ID
+	hasNextPage: Boolean!
+}
+
+type WorkItem {
+	id: ID!
+	number: Int!
+	title: String!
+	description: String
+	state: String
+	type: WorkItemType!
+	space: Space!
+	createdAt: Time
+	updatedAt: Time
+	creator: User
+	assignees: [User!]!
+	iteration: Iteration
+	area: Area
+	labels: [Label!]!
+	# The values of all the fields of the work item type
+	fields: [Field!]!
+	# The value of the given field, null if the type has no such field
+	field(name: String!): Field
+	parent: WorkItem
+	children: [WorkItem!]!
+	links: [Link!]!
+	comments(first: Int = 20, offset: Int = 0): [Comment!]!
+}
+
+type WorkItemType {
+	id: ID!
+	name: String!
+	description: String
+	icon: String!
+	fields: [FieldDefinition!]!
+}
+
+type FieldDefinition {
+	name: String!
+	label: String!
+	description: String!
+	required: Boolean!
+	readOnly: Boolean!
+	# The kind of the field: string, integer, float, bool, instant, duration,
+	# url, iteration, user, label, enum, list, markup, area or codebase
+	kind: String!
+	# The kind of the elements of a list or of the values of an enum
+	componentKind: String
+	# The allowed values of an enum
+	values: [String!]
+}
+
+# The value of a field of a work item, typed after the kind of the field
+interface Field {
+	name: String!
+	kind: String!
+}
+
+type TextField implements Field {
+	name: String!
+	kind: String!
+	text: String
+}
+
+type NumberField implements Field {
+	name: String!
+	kind: String!
+	number: Float
+}
+
+type BooleanField implements Field {
+	name: String!
+	kind: String!
+	boolean: Boolean
+}
+
+type InstantField implements Field {
+	name: String!
+	kind: String!
+	instant: Time
+}
+
+type UserField implements Field {
+	name: String!
+	kind: String!
+	user: User
+}
+
+type IterationField implements Field {
+	name: String!
+	kind: String!
+	iteration: Iteration
+}
+
+type AreaField implements Field {
+	name: String!
+	kind: String!
+	area: Area
+}
+
+type LabelField implements Field {
+	name: String!
+	kind: String!
+	label: Label
+}
+
+type ListField implements Field {
+	name: String!
+	kind: String!
+	items: [Field!]!
+}
+
+type Iteration {
+	id: ID!
+	name: String!
+	description: String
+	# The names of the ancestors of the iteration and its own name, separated
+	# with slashes
+	path: String!
+	parent: Iteration
+	state: String!
+	startAt: Time
+	endAt: Time
+}
+
+type Area {
+	id: ID!
+	name: String!
+	# The names of the ancestors of the area and its own name, separated with
+	# slashes
+	path: String!
+	parent: Area
+}
+
+type Label {
+	id: ID!
+	name: String!
+	textColor: String!
+	backgroundColor: String!
+	borderColor: String!
+}
+
+type User {
+	id: ID!
+	username: String!
+	fullName: String!
+	imageURL: String!
+}
+
+type Link {
+	id: ID!
+	linkType: LinkType!
+	source: WorkItem!
+	target: WorkItem!
+}
+
+type LinkType {
+	id: ID!
+	name: String!
+	forwardName: String!
+	reverseName: String!
+	topology: String!
+}
+
+type Comment {
+	id: ID!
+	body: String!
+	markup: String!
+	creator: User
+	createdAt: Time!
+	updatedAt: Time!
+}
+`
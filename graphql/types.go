@@ -0,0 +1,361 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/area"
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/label"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	gql "github.com/graph-gophers/graphql-go"
+	uuid "github.com/satori/go.uuid"
+)
+
+type workItemTypeResolver struct {
+	wit *workitem.WorkItemType
+}
+
+func (t *workItemTypeResolver) ID() gql.ID {
+	return gql.ID(t.wit.ID.String())
+}
+
+func (t *workItemTypeResolver) Name() string {
+	return t.wit.Name
+}
+
+func (t *workItemTypeResolver) Description() *string {
+	return t.wit.Description
+}
+
+func (t *workItemTypeResolver) Icon() string {
+	return t.wit.Icon
+}
+
+func (t *workItemTypeResolver) Fields() []*fieldDefinitionResolver {
+	names := make([]string, 0, len(t.wit.Fields))
+	for name := range t.wit.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]*fieldDefinitionResolver, len(names))
+	for i, name := range names {
+		res[i] = &fieldDefinitionResolver{name: name, def: t.wit.Fields[name]}
+	}
+	return res
+}
+
+type fieldDefinitionResolver struct {
+	name string
+	def  workitem.FieldDefinition
+}
+
+func (f *fieldDefinitionResolver) Name() string {
+	return f.name
+}
+
+func (f *fieldDefinitionResolver) Label() string {
+	return f.def.Label
+}
+
+func (f *fieldDefinitionResolver) Description() string {
+	return f.def.Description
+}
+
+func (f *fieldDefinitionResolver) Required() bool {
+	return f.def.Required
+}
+
+func (f *fieldDefinitionResolver) ReadOnly() bool {
+	return f.def.ReadOnly
+}
+
+func (f *fieldDefinitionResolver) Kind() string {
+	return string(f.def.Type.GetKind())
+}
+
+func (f *fieldDefinitionResolver) ComponentKind() *string {
+	switch f.def.Type.(type) {
+	case workitem.ListType, workitem.EnumType:
+		kind := string(componentKind(f.def.Type))
+		return &kind
+	}
+	return nil
+}
+
+func (f *fieldDefinitionResolver) Values() *[]string {
+	t, ok := f.def.Type.(workitem.EnumType)
+	if !ok {
+		return nil
+	}
+	res := make([]string, 0, len(t.Values))
+	for _, v := range t.Values {
+		if s, ok := displayText(v); ok {
+			res = append(res, s)
+		}
+	}
+	return &res
+}
+
+type iterationResolver struct {
+	r   *request
+	itr *iteration.Iteration
+}
+
+func (i *iterationResolver) ID() gql.ID {
+	return gql.ID(i.itr.ID.String())
+}
+
+func (i *iterationResolver) Name() string {
+	return i.itr.Name
+}
+
+func (i *iterationResolver) Description() *string {
+	return i.itr.Description
+}
+
+func (i *iterationResolver) Path(ctx context.Context) (string, error) {
+	i.r.iterations.prime(i.itr.Path...)
+	res := ""
+	for _, ancestorID := range i.itr.Path {
+		ancestor, err := i.r.iteration(ctx, ancestorID)
+		if err != nil {
+			return "", err
+		}
+		res += iteration.PathSepInService + ancestor.Name
+	}
+	return res + iteration.PathSepInService + i.itr.Name, nil
+}
+
+func (i *iterationResolver) Parent(ctx context.Context) (*iterationResolver, error) {
+	if i.itr.Path.IsEmpty() {
+		return nil, nil
+	}
+	return i.r.iterationResolver(ctx, i.itr.Path.This())
+}
+
+func (i *iterationResolver) State() string {
+	return i.itr.State.String()
+}
+
+func (i *iterationResolver) StartAt() *gql.Time {
+	return optionalTime(i.itr.StartAt)
+}
+
+func (i *iterationResolver) EndAt() *gql.Time {
+	return optionalTime(i.itr.EndAt)
+}
+
+type areaResolver struct {
+	r *request
+	a *area.Area
+}
+
+func (a *areaResolver) ID() gql.ID {
+	return gql.ID(a.a.ID.String())
+}
+
+func (a *areaResolver) Name() string {
+	return a.a.Name
+}
+
+func (a *areaResolver) Path(ctx context.Context) (string, error) {
+	a.r.areas.prime(a.a.Path...)
+	res := ""
+	for _, ancestorID := range a.a.Path {
+		ancestor, err := a.r.area(ctx, ancestorID)
+		if err != nil {
+			return "", err
+		}
+		res += iteration.PathSepInService + ancestor.Name
+	}
+	return res + iteration.PathSepInService + a.a.Name, nil
+}
+
+func (a *areaResolver) Parent(ctx context.Context) (*areaResolver, error) {
+	if a.a.Path.IsEmpty() {
+		return nil, nil
+	}
+	return a.r.areaResolver(ctx, a.a.Path.This())
+}
+
+type labelResolver struct {
+	l *label.Label
+}
+
+func (l *labelResolver) ID() gql.ID {
+	return gql.ID(l.l.ID.String())
+}
+
+func (l *labelResolver) Name() string {
+	return l.l.Name
+}
+
+func (l *labelResolver) TextColor() string {
+	return l.l.TextColor
+}
+
+func (l *labelResolver) BackgroundColor() string {
+	return l.l.BackgroundColor
+}
+
+func (l *labelResolver) BorderColor() string {
+	return l.l.BorderColor
+}
+
+type userResolver struct {
+	identity *account.Identity
+}
+
+func (u *userResolver) ID() gql.ID {
+	return gql.ID(u.identity.ID.String())
+}
+
+func (u *userResolver) Username() string {
+	return u.identity.Username
+}
+
+func (u *userResolver) FullName() string {
+	return u.identity.User.FullName
+}
+
+func (u *userResolver) ImageURL() string {
+	return u.identity.User.ImageURL
+}
+
+type linkResolver struct {
+	r *request
+	l *link.WorkItemLink
+}
+
+func (l *linkResolver) ID() gql.ID {
+	return gql.ID(l.l.ID.String())
+}
+
+func (l *linkResolver) LinkType(ctx context.Context) (*linkTypeResolver, error) {
+	lt, err := l.r.linkType(ctx, l.l.LinkTypeID)
+	if err != nil {
+		return nil, err
+	}
+	return &linkTypeResolver{lt: lt}, nil
+}
+
+func (l *linkResolver) Source(ctx context.Context) (*workItemResolver, error) {
+	return l.r.workItemResolver(ctx, l.l.SourceID)
+}
+
+func (l *linkResolver) Target(ctx context.Context) (*workItemResolver, error) {
+	return l.r.workItemResolver(ctx, l.l.TargetID)
+}
+
+type linkTypeResolver struct {
+	lt *link.WorkItemLinkType
+}
+
+func (t *linkTypeResolver) ID() gql.ID {
+	return gql.ID(t.lt.ID.String())
+}
+
+func (t *linkTypeResolver) Name() string {
+	return t.lt.Name
+}
+
+func (t *linkTypeResolver) ForwardName() string {
+	return t.lt.ForwardName
+}
+
+func (t *linkTypeResolver) ReverseName() string {
+	return t.lt.ReverseName
+}
+
+func (t *linkTypeResolver) Topology() string {
+	return t.lt.Topology.String()
+}
+
+type commentResolver struct {
+	r *request
+	c *comment.Comment
+}
+
+func (c *commentResolver) ID() gql.ID {
+	return gql.ID(c.c.ID.String())
+}
+
+func (c *commentResolver) Body() string {
+	return c.c.Body
+}
+
+func (c *commentResolver) Markup() string {
+	return c.c.Markup
+}
+
+func (c *commentResolver) Creator(ctx context.Context) (*userResolver, error) {
+	return c.r.userResolver(ctx, c.c.Creator)
+}
+
+func (c *commentResolver) CreatedAt() gql.Time {
+	return gql.Time{Time: c.c.CreatedAt}
+}
+
+func (c *commentResolver) UpdatedAt() gql.Time {
+	return gql.Time{Time: c.c.UpdatedAt}
+}
+
+// userResolver returns the resolver of the identity with the given ID, nil if
+// it doesn't exist
+func (r *request) userResolver(ctx context.Context, id uuid.UUID) (*userResolver, error) {
+	identity, err := r.user(ctx, id)
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+	return &userResolver{identity: identity}, nil
+}
+
+// iterationResolver returns the resolver of the iteration with the given ID,
+// nil if it doesn't exist
+func (r *request) iterationResolver(ctx context.Context, id uuid.UUID) (*iterationResolver, error) {
+	itr, err := r.iteration(ctx, id)
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+	return &iterationResolver{r: r, itr: itr}, nil
+}
+
+// areaResolver returns the resolver of the area with the given ID, nil if it
+// doesn't exist
+func (r *request) areaResolver(ctx context.Context, id uuid.UUID) (*areaResolver, error) {
+	a, err := r.area(ctx, id)
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+	return &areaResolver{r: r, a: a}, nil
+}
+
+// labelResolver returns the resolver of the label with the given ID of the
+// given space, nil if it doesn't exist
+func (r *request) labelResolver(ctx context.Context, spaceID, id uuid.UUID) (*labelResolver, error) {
+	l, err := r.label(ctx, spaceID, id)
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+	return &labelResolver{l: l}, nil
+}
+
+// timeField returns the given value of an instant field, nil if it is not set
+func timeField(value interface{}) *gql.Time {
+	if t, ok := value.(time.Time); ok {
+		return &gql.Time{Time: t}
+	}
+	return nil
+}
+
+func optionalTime(t *time.Time) *gql.Time {
+	if t == nil {
+		return nil
+	}
+	return &gql.Time{Time: *t}
+}
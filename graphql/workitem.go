@@ -0,0 +1,244 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	gql "github.com/graph-gophers/graphql-go"
+	uuid "github.com/satori/go.uuid"
+)
+
+type workItemResolver struct {
+	r  *request
+	wi *workitem.WorkItem
+}
+
+func (w *workItemResolver) ID() gql.ID {
+	return gql.ID(w.wi.ID.String())
+}
+
+func (w *workItemResolver) Number() int32 {
+	return int32(w.wi.Number)
+}
+
+func (w *workItemResolver) Title() string {
+	title, _ := w.wi.Fields[workitem.SystemTitle].(string)
+	return title
+}
+
+func (w *workItemResolver) Description() *string {
+	if m := rendering.NewMarkupContentFromValue(w.wi.Fields[workitem.SystemDescription]); m != nil {
+		return &m.Content
+	}
+	return nil
+}
+
+func (w *workItemResolver) State() *string {
+	if state, ok := w.wi.Fields[workitem.SystemState].(string); ok {
+		return &state
+	}
+	return nil
+}
+
+func (w *workItemResolver) Type(ctx context.Context) (*workItemTypeResolver, error) {
+	wit, err := w.r.workItemType(ctx, w.wi.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &workItemTypeResolver{wit: wit}, nil
+}
+
+func (w *workItemResolver) Space(ctx context.Context) (*spaceResolver, error) {
+	s, err := w.r.space(ctx, w.wi.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	return &spaceResolver{r: w.r, s: s}, nil
+}
+
+func (w *workItemResolver) CreatedAt() *gql.Time {
+	return timeField(w.wi.Fields[workitem.SystemCreatedAt])
+}
+
+func (w *workItemResolver) UpdatedAt() *gql.Time {
+	return timeField(w.wi.Fields[workitem.SystemUpdatedAt])
+}
+
+func (w *workItemResolver) Creator(ctx context.Context) (*userResolver, error) {
+	ids := referencedIDs(w.wi.Fields[workitem.SystemCreator])
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return w.r.userResolver(ctx, ids[0])
+}
+
+func (w *workItemResolver) Assignees(ctx context.Context) ([]*userResolver, error) {
+	res := []*userResolver{}
+	for _, id := range referencedIDs(w.wi.Fields[workitem.SystemAssignees]) {
+		u, err := w.r.userResolver(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if u != nil {
+			res = append(res, u)
+		}
+	}
+	return res, nil
+}
+
+func (w *workItemResolver) Iteration(ctx context.Context) (*iterationResolver, error) {
+	ids := referencedIDs(w.wi.Fields[workitem.SystemIteration])
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return w.r.iterationResolver(ctx, ids[0])
+}
+
+func (w *workItemResolver) Area(ctx context.Context) (*areaResolver, error) {
+	ids := referencedIDs(w.wi.Fields[workitem.SystemArea])
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return w.r.areaResolver(ctx, ids[0])
+}
+
+func (w *workItemResolver) Labels(ctx context.Context) ([]*labelResolver, error) {
+	res := []*labelResolver{}
+	for _, id := range referencedIDs(w.wi.Fields[workitem.SystemLabels]) {
+		l, err := w.r.labelResolver(ctx, w.wi.SpaceID, id)
+		if err != nil {
+			return nil, err
+		}
+		if l != nil {
+			res = append(res, l)
+		}
+	}
+	return res, nil
+}
+
+func (w *workItemResolver) Fields(ctx context.Context) ([]*fieldResolver, error) {
+	wit, err := w.r.workItemType(ctx, w.wi.Type)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(wit.Fields))
+	for name := range wit.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]*fieldResolver, len(names))
+	for i, name := range names {
+		res[i] = w.field(name, wit.Fields[name].Type)
+	}
+	return res, nil
+}
+
+func (w *workItemResolver) Field(ctx context.Context, args struct{ Name string }) (*fieldResolver, error) {
+	wit, err := w.r.workItemType(ctx, w.wi.Type)
+	if err != nil {
+		return nil, err
+	}
+	def, ok := wit.Fields[args.Name]
+	if !ok {
+		return nil, nil
+	}
+	return w.field(args.Name, def.Type), nil
+}
+
+func (w *workItemResolver) field(name string, t workitem.FieldType) *fieldResolver {
+	return &fieldResolver{
+		r:       w.r,
+		spaceID: w.wi.SpaceID,
+		name:    name,
+		t:       t,
+		value:   w.wi.Fields[name],
+	}
+}
+
+func (w *workItemResolver) Parent(ctx context.Context) (*workItemResolver, error) {
+	parentID, err := w.r.parentID(ctx, w.wi.ID)
+	if err != nil || parentID == nil {
+		return nil, err
+	}
+	return w.r.workItemResolver(ctx, *parentID)
+}
+
+func (w *workItemResolver) Children(ctx context.Context) ([]*workItemResolver, error) {
+	ids, err := w.r.childIDs(ctx, w.wi.ID)
+	if err != nil {
+		return nil, err
+	}
+	return w.r.workItemResolvers(ctx, ids)
+}
+
+func (w *workItemResolver) Links(ctx context.Context) ([]*linkResolver, error) {
+	links, err := w.r.appl.WorkItemLinks().ListByWorkItem(ctx, w.wi.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.r.charge(len(links)); err != nil {
+		return nil, err
+	}
+	res := make([]*linkResolver, len(links))
+	for i := range links {
+		w.r.workItems.prime(links[i].SourceID, links[i].TargetID)
+		res[i] = &linkResolver{r: w.r, l: &links[i]}
+	}
+	return res, nil
+}
+
+func (w *workItemResolver) Comments(ctx context.Context, args struct {
+	First  *int32
+	Offset *int32
+}) ([]*commentResolver, error) {
+	limit, err := pageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	start := 0
+	if args.Offset != nil && *args.Offset > 0 {
+		start = int(*args.Offset)
+	}
+	comments, _, err := w.r.appl.Comments().List(ctx, w.wi.ID, &start, &limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.r.charge(len(comments)); err != nil {
+		return nil, err
+	}
+	res := make([]*commentResolver, len(comments))
+	for i := range comments {
+		w.r.users.prime(comments[i].Creator)
+		res[i] = &commentResolver{r: w.r, c: &comments[i]}
+	}
+	return res, nil
+}
+
+// workItemResolver returns the resolver of the work item with the given ID,
+// nil if it doesn't exist
+func (r *request) workItemResolver(ctx context.Context, id uuid.UUID) (*workItemResolver, error) {
+	wi, err := r.workItem(ctx, id)
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+	return &workItemResolver{r: r, wi: wi}, nil
+}
+
+// workItemResolvers returns the resolvers of the existing work items with the
+// given IDs, loaded at once
+func (r *request) workItemResolvers(ctx context.Context, ids []uuid.UUID) ([]*workItemResolver, error) {
+	r.workItems.prime(ids...)
+	res := make([]*workItemResolver, 0, len(ids))
+	for _, id := range ids {
+		w, err := r.workItemResolver(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if w != nil {
+			res = append(res, w)
+		}
+	}
+	return res, nil
+}
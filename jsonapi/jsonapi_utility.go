@@ -26,6 +26,7 @@ const (
 	ErrorCodeForbiddenError    = "forbidden_error"
 	ErrorCodeJWTSecurityError  = "jwt_security_error"
 	ErrorCodeDataConflict      = "data_conflict_error"
+	ErrorCodeTooManyRequests   = "too_many_requests_error"
 )
 
 // ErrorToJSONAPIError returns the JSONAPI representation
@@ -72,6 +73,10 @@ func ErrorToJSONAPIError(ctx context.Context, err error) (app.JSONAPIError, int)
 		code = ErrorCodeForbiddenError
 		title = "Forbidden error"
 		statusCode = http.StatusForbidden
+	case errors.TooManyRequestsError:
+		code = ErrorCodeTooManyRequests
+		title = "Too many requests error"
+		statusCode = http.StatusTooManyRequests
 	default:
 		code = ErrorCodeUnknownError
 		title = "Unknown error"
@@ -0,0 +1,196 @@
+package kubernetes
+
+import (
+	"sort"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	v1 "k8s.io/client-go/pkg/api/v1"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/log"
+	errs "github.com/pkg/errors"
+)
+
+// The health statuses of a deployment, from the best to the worst
+const (
+	HealthOK      = "ok"
+	HealthWarning = "warning"
+	HealthError   = "error"
+)
+
+// healthSeverity orders the health statuses
+var healthSeverity = map[string]int{
+	HealthOK:      0,
+	HealthWarning: 1,
+	HealthError:   2,
+}
+
+// IsWorseHealth returns true if the first health status is worse than the second one
+func IsWorseHealth(health string, than string) bool {
+	return healthSeverity[health] > healthSeverity[than]
+}
+
+// GetDeploymentEvents returns the Kubernetes events of the deployment config of an application
+// within a particular environment, of its replication controllers and of their pods, from the
+// most recent to the oldest one. The application must exist within the provided space.
+func (kc *kubeClient) GetDeploymentEvents(spaceName string, appName string, envName string) ([]*app.SimpleDeploymentEvent, error) {
+	envNS, err := kc.getEnvironmentNamespace(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deployment Config name does not always match the application name, look up
+	// DC name using available metadata
+	dcName, err := kc.getDeploymentConfigNameForApp(envNS, appName, spaceName)
+	if err != nil {
+		return nil, err
+	}
+	deploy, err := kc.getAndParseDeploymentConfig(envNS, dcName, spaceName)
+	if err != nil {
+		return nil, err
+	} else if deploy == nil {
+		return nil, nil
+	}
+	rcs, err := kc.getReplicationControllers(envNS, deploy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the objects whose events are returned
+	involved := map[string]map[string]struct{}{
+		"DeploymentConfig":      {dcName: {}},
+		"ReplicationController": {},
+		"Pod":                   {},
+	}
+	rcUIDs := make(map[types.UID]struct{}, len(rcs))
+	for _, rc := range rcs {
+		involved["ReplicationController"][rc.Name] = struct{}{}
+		rcUIDs[rc.UID] = struct{}{}
+	}
+	pods, err := kc.Pods(envNS).List(metaV1.ListOptions{})
+	if err != nil {
+		log.Error(nil, map[string]interface{}{
+			"err":       err,
+			"namespace": envNS,
+		}, "failed to list pods")
+		return nil, convertError(errs.WithStack(err), "failed to list pods in %s", envNS)
+	}
+	for _, pod := range pods.Items {
+		for _, ref := range pod.OwnerReferences {
+			if _, pres := rcUIDs[ref.UID]; pres && ref.Controller != nil && *ref.Controller {
+				involved["Pod"][pod.Name] = struct{}{}
+				break
+			}
+		}
+	}
+
+	events, err := kc.Events(envNS).List(metaV1.ListOptions{})
+	if err != nil {
+		log.Error(nil, map[string]interface{}{
+			"err":       err,
+			"namespace": envNS,
+		}, "failed to list events")
+		return nil, convertError(errs.WithStack(err), "failed to list events in %s", envNS)
+	}
+	result := []*app.SimpleDeploymentEvent{}
+	for idx := range events.Items {
+		event := &events.Items[idx]
+		if _, pres := involved[event.InvolvedObject.Kind][event.InvolvedObject.Name]; pres {
+			result = append(result, newDeploymentEvent(event))
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Attributes.LastTimestamp.After(*result[j].Attributes.LastTimestamp)
+	})
+	return result, nil
+}
+
+func newDeploymentEvent(event *v1.Event) *app.SimpleDeploymentEvent {
+	first := event.FirstTimestamp.UTC()
+	last := event.LastTimestamp.UTC()
+	return &app.SimpleDeploymentEvent{
+		Type: "deploymentevent",
+		ID:   string(event.UID),
+		Attributes: &app.SimpleDeploymentEventAttributes{
+			EventType:      event.Type,
+			Reason:         event.Reason,
+			Message:        event.Message,
+			Count:          int(event.Count),
+			FirstTimestamp: &first,
+			LastTimestamp:  &last,
+			ObjectKind:     event.InvolvedObject.Kind,
+			ObjectName:     event.InvolvedObject.Name,
+		},
+	}
+}
+
+// GetEnvironmentHealth returns the health of the current deployment of each application of a
+// space within a particular environment. A deployment is in error when one of its pods is in a
+// severe warning state, e.g. crash-looping, and in warning when one of its pods is in any other
+// warning state. The environment has the health of its worst deployment.
+func (kc *kubeClient) GetEnvironmentHealth(spaceName string, envName string) (*app.SimpleEnvironmentHealth, error) {
+	envNS, err := kc.getEnvironmentNamespace(envName)
+	if err != nil {
+		return nil, err
+	}
+	appNames, err := kc.getBuildConfigsForSpace(spaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	envHealth := HealthOK
+	apps := []*app.SimpleApplicationHealth{}
+	for _, appName := range appNames {
+		deploy, err := kc.getCurrentDeployment(spaceName, appName, envNS)
+		if err != nil {
+			return nil, err
+		} else if deploy == nil || deploy.current == nil {
+			continue
+		}
+		pods, err := kc.getPods(envNS, deploy.current)
+		if err != nil {
+			return nil, err
+		}
+		appHealth := getApplicationHealth(appName, pods)
+		if IsWorseHealth(appHealth.Status, envHealth) {
+			envHealth = appHealth.Status
+		}
+		apps = append(apps, appHealth)
+	}
+
+	result := &app.SimpleEnvironmentHealth{
+		Type: "environmenthealth",
+		ID:   envName,
+		Attributes: &app.SimpleEnvironmentHealthAttributes{
+			Name:         envName,
+			Status:       envHealth,
+			Applications: apps,
+		},
+	}
+	return result, nil
+}
+
+func getApplicationHealth(appName string, pods []*v1.Pod) *app.SimpleApplicationHealth {
+	result := &app.SimpleApplicationHealth{
+		Name:   appName,
+		Status: HealthOK,
+	}
+	for _, pod := range pods {
+		// Failed and terminating pods are left out, as done by getPodStatus
+		if pod.Status.Phase == v1.PodFailed || pod.DeletionTimestamp != nil {
+			continue
+		}
+		result.PodTotal++
+		if warn, severe := isPodWarning(pod); severe {
+			result.PodsError++
+			result.Status = HealthError
+		} else if warn {
+			result.PodsWarning++
+			if IsWorseHealth(HealthWarning, result.Status) {
+				result.Status = HealthWarning
+			}
+		}
+	}
+	return result
+}
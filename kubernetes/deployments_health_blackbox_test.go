@@ -0,0 +1,296 @@
+package kubernetes_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/kubernetes"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	notificationsupport "github.com/fabric8-services/fabric8-wit/test/notification"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDeploymentEvents(t *testing.T) {
+	testCases := []struct {
+		testName     string
+		spaceName    string
+		appName      string
+		envName      string
+		cassetteName string
+		expected     []string
+		shouldFail   bool
+		errorChecker func(error) (bool, error)
+	}{
+		{
+			testName:     "Basic",
+			spaceName:    "mySpace",
+			appName:      "myApp",
+			envName:      "run",
+			cassetteName: "deploymentevents",
+			// the event of another application's pod is left out
+			expected: []string{
+				"5a0c3e1c-4d44-4b0e-9a53-5f1c1e3b0a03",
+				"5a0c3e1c-4d44-4b0e-9a53-5f1c1e3b0a05",
+				"5a0c3e1c-4d44-4b0e-9a53-5f1c1e3b0a02",
+				"5a0c3e1c-4d44-4b0e-9a53-5f1c1e3b0a01",
+			},
+		},
+		{
+			testName:     "Bad Environment",
+			spaceName:    "mySpace",
+			appName:      "myApp",
+			envName:      "doesNotExist",
+			cassetteName: "deploymentevents",
+			shouldFail:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			r, err := recorder.New(pathToTestJSON + testCase.cassetteName)
+			require.NoError(t, err, "Failed to open cassette")
+			defer r.Stop()
+
+			fixture := &testFixture{}
+			kc := getDefaultKubeClient(fixture, r.Transport, t)
+
+			events, err := kc.GetDeploymentEvents(testCase.spaceName, testCase.appName, testCase.envName)
+			if testCase.shouldFail {
+				require.Error(t, err, "Expected an error")
+				if testCase.errorChecker != nil {
+					matches, _ := testCase.errorChecker(err)
+					require.True(t, matches, "Error or cause must be the expected type")
+				}
+			} else {
+				require.NoError(t, err, "Unexpected error occurred")
+				ids := make([]string, 0, len(events))
+				for _, event := range events {
+					ids = append(ids, event.ID)
+				}
+				require.Equal(t, testCase.expected, ids, "Wrong events")
+
+				attrs := events[0].Attributes
+				require.NotNil(t, attrs, "Event attributes are nil")
+				assert.Equal(t, "Warning", attrs.EventType)
+				assert.Equal(t, "Unhealthy", attrs.Reason)
+				assert.Equal(t, 3, attrs.Count)
+				assert.Equal(t, "Pod", attrs.ObjectKind)
+				assert.Equal(t, "myDeploy-1-sdmzq", attrs.ObjectName)
+				require.NotNil(t, attrs.LastTimestamp, "Last timestamp is nil")
+				assert.Equal(t, time.Date(2018, 1, 25, 20, 41, 0, 0, time.UTC), *attrs.LastTimestamp)
+			}
+		})
+	}
+}
+
+func TestGetEnvironmentHealth(t *testing.T) {
+	testCases := []struct {
+		testName     string
+		spaceName    string
+		envName      string
+		cassetteName string
+		expected     *app.SimpleApplicationHealth
+		shouldFail   bool
+		errorChecker func(error) (bool, error)
+	}{
+		{
+			testName:     "Basic",
+			spaceName:    "mySpace",
+			envName:      "run",
+			cassetteName: "environmenthealth",
+			expected: &app.SimpleApplicationHealth{
+				Name:     "myApp",
+				Status:   kubernetes.HealthOK,
+				PodTotal: 2,
+			},
+		},
+		{
+			testName:     "Crash Loop",
+			spaceName:    "mySpace",
+			envName:      "run",
+			cassetteName: "environmenthealth-crashloop",
+			expected: &app.SimpleApplicationHealth{
+				Name:      "myApp",
+				Status:    kubernetes.HealthError,
+				PodTotal:  2,
+				PodsError: 1,
+			},
+		},
+		{
+			testName:     "Bad Environment",
+			spaceName:    "mySpace",
+			envName:      "doesNotExist",
+			cassetteName: "environmenthealth",
+			shouldFail:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			r, err := recorder.New(pathToTestJSON + testCase.cassetteName)
+			require.NoError(t, err, "Failed to open cassette")
+			defer r.Stop()
+
+			fixture := &testFixture{}
+			kc := getDefaultKubeClient(fixture, r.Transport, t)
+
+			health, err := kc.GetEnvironmentHealth(testCase.spaceName, testCase.envName)
+			if testCase.shouldFail {
+				require.Error(t, err, "Expected an error")
+				if testCase.errorChecker != nil {
+					matches, _ := testCase.errorChecker(err)
+					require.True(t, matches, "Error or cause must be the expected type")
+				}
+			} else {
+				require.NoError(t, err, "Unexpected error occurred")
+				require.NotNil(t, health, "Health is nil")
+				require.NotNil(t, health.Attributes, "Health attributes are nil")
+				assert.Equal(t, testCase.envName, health.Attributes.Name)
+				assert.Equal(t, testCase.expected.Status, health.Attributes.Status, "Wrong environment health")
+				require.Equal(t, []*app.SimpleApplicationHealth{testCase.expected}, health.Attributes.Applications)
+			}
+		})
+	}
+}
+
+// fakeHealthKubeClient returns the health statuses of an application, one
+// after the other
+type fakeHealthKubeClient struct {
+	kubernetes.KubeClientInterface
+	statuses chan string
+	closed   bool
+}
+
+func (kc *fakeHealthKubeClient) GetEnvironmentHealth(spaceName string, envName string) (*app.SimpleEnvironmentHealth, error) {
+	status := kubernetes.HealthOK
+	select {
+	case status = <-kc.statuses:
+	default:
+	}
+	return newEnvironmentHealth(envName, status), nil
+}
+
+func (kc *fakeHealthKubeClient) Close() {
+	kc.closed = true
+}
+
+func newEnvironmentHealth(envName string, status string) *app.SimpleEnvironmentHealth {
+	return &app.SimpleEnvironmentHealth{
+		Type: "environmenthealth",
+		ID:   envName,
+		Attributes: &app.SimpleEnvironmentHealthAttributes{
+			Name:   envName,
+			Status: status,
+			Applications: []*app.SimpleApplicationHealth{
+				{Name: "myApp", Status: status},
+			},
+		},
+	}
+}
+
+func TestHealthWatcher(t *testing.T) {
+	spaceID := uuid.NewV4()
+
+	t.Run("notifies when a deployment becomes unhealthy", func(t *testing.T) {
+		channel := &notificationsupport.FakeNotificationChannel{}
+		watcher := kubernetes.NewHealthWatcher(channel, "sa-token", 10*time.Millisecond, time.Hour)
+		kc := &fakeHealthKubeClient{statuses: make(chan string, 3)}
+		kc.statuses <- kubernetes.HealthWarning
+		kc.statuses <- kubernetes.HealthWarning
+		kc.statuses <- kubernetes.HealthOK
+		err := watcher.Watch(spaceID, "mySpace", newEnvironmentHealth("run", kubernetes.HealthOK), time.Now().Add(time.Hour),
+			func() (kubernetes.KubeClientInterface, error) {
+				return kc, nil
+			})
+		require.NoError(t, err)
+
+		watcher.Start()
+		time.Sleep(100 * time.Millisecond)
+		watcher.Stop()
+
+		// only the transition from ok to warning is notified
+		require.Len(t, channel.Messages, 1)
+		assert.Equal(t, notification.MessageTypeDeploymentUnhealthy, channel.Messages[0].MessageType)
+		assert.Equal(t, spaceID, channel.Messages[0].SpaceID)
+		assert.Equal(t, "run/myApp", channel.Messages[0].TargetID)
+		assert.Equal(t, []string{"sa-token"}, channel.Tokens, "The message must be sent with the service account token")
+		assert.True(t, kc.closed, "The client must be closed")
+	})
+
+	t.Run("extends the watch of a watched environment", func(t *testing.T) {
+		watcher := kubernetes.NewHealthWatcher(&notificationsupport.FakeNotificationChannel{}, "sa-token", time.Hour, time.Hour)
+		created := 0
+		var clients []*fakeHealthKubeClient
+		newClient := func() (kubernetes.KubeClientInterface, error) {
+			created++
+			kc := &fakeHealthKubeClient{}
+			clients = append(clients, kc)
+			return kc, nil
+		}
+		health := newEnvironmentHealth("run", kubernetes.HealthOK)
+		tokenExpiry := time.Now().Add(time.Hour)
+		require.NoError(t, watcher.Watch(spaceID, "mySpace", health, tokenExpiry, newClient))
+		require.NoError(t, watcher.Watch(spaceID, "mySpace", health, tokenExpiry, newClient))
+		assert.Equal(t, 1, created, "Only one client must be created")
+		// a token expiring later replaces the client
+		require.NoError(t, watcher.Watch(spaceID, "mySpace", health, tokenExpiry.Add(time.Minute), newClient))
+		assert.Equal(t, 2, created, "The client must be replaced")
+		assert.False(t, clients[0].closed, "The replaced client must be closed by the check")
+		watcher.Stop()
+		assert.True(t, clients[0].closed, "The replaced client must be closed")
+		assert.True(t, clients[1].closed, "The client must be closed")
+	})
+
+	t.Run("stops watching when the token expires", func(t *testing.T) {
+		channel := &notificationsupport.FakeNotificationChannel{}
+		watcher := kubernetes.NewHealthWatcher(channel, "sa-token", 10*time.Millisecond, time.Hour)
+		kc := &fakeHealthKubeClient{statuses: make(chan string, 1)}
+		err := watcher.Watch(spaceID, "mySpace", newEnvironmentHealth("run", kubernetes.HealthOK), time.Now().Add(20*time.Millisecond),
+			func() (kubernetes.KubeClientInterface, error) {
+				return kc, nil
+			})
+		require.NoError(t, err)
+
+		watcher.Start()
+		time.Sleep(50 * time.Millisecond)
+		// the client would be used with an expired token
+		kc.statuses <- kubernetes.HealthError
+		time.Sleep(50 * time.Millisecond)
+		watcher.Stop()
+
+		assert.Empty(t, channel.Messages)
+		assert.True(t, kc.closed, "The client must be closed")
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		watcher := kubernetes.NewHealthWatcher(&notificationsupport.FakeNotificationChannel{}, "sa-token", time.Hour, time.Hour)
+		err := watcher.Watch(spaceID, "mySpace", newEnvironmentHealth("run", kubernetes.HealthOK), time.Time{},
+			func() (kubernetes.KubeClientInterface, error) {
+				return &fakeHealthKubeClient{}, nil
+			})
+		assert.Error(t, err)
+	})
+
+	t.Run("drops the expired environments", func(t *testing.T) {
+		channel := &notificationsupport.FakeNotificationChannel{}
+		watcher := kubernetes.NewHealthWatcher(channel, "sa-token", 10*time.Millisecond, time.Nanosecond)
+		kc := &fakeHealthKubeClient{statuses: make(chan string, 1)}
+		kc.statuses <- kubernetes.HealthError
+		err := watcher.Watch(spaceID, "mySpace", newEnvironmentHealth("run", kubernetes.HealthOK), time.Now().Add(time.Hour),
+			func() (kubernetes.KubeClientInterface, error) {
+				return kc, nil
+			})
+		require.NoError(t, err)
+
+		watcher.Start()
+		time.Sleep(50 * time.Millisecond)
+		watcher.Stop()
+
+		assert.Empty(t, channel.Messages)
+		assert.True(t, kc.closed, "The client must be closed")
+	})
+}
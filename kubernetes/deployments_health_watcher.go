@@ -0,0 +1,208 @@
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// HealthWatcher periodically checks the health of the deployments of the
+// watched environments and sends a notification.MessageTypeDeploymentUnhealthy
+// message when the health of a deployment gets worse. An environment is
+// watched with the credentials of a user who requested its health, for a while
+// after the last request but never after these credentials expire. The
+// messages are sent on behalf of a service account, since no user request is
+// involved.
+type HealthWatcher struct {
+	channel  notification.Channel
+	token    string
+	interval time.Duration
+	expiry   time.Duration
+	mu       sync.Mutex
+	watched  map[watchedEnvironmentKey]*watchedEnvironment
+	// replaced holds the replaced clients, which may still be in use by a
+	// running check, until the next check or Stop closes them
+	replaced []KubeClientInterface
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type watchedEnvironmentKey struct {
+	spaceID uuid.UUID
+	envName string
+}
+
+type watchedEnvironment struct {
+	spaceName string
+	kc        KubeClientInterface
+	// tokenExpiry is the expiry of the token the client was created with
+	tokenExpiry time.Time
+	expiresAt   time.Time
+	// health holds the last known health of each application
+	health map[string]string
+}
+
+// NewHealthWatcher creates a watcher checking the health of the watched
+// environments at the given interval, until they expire, and sending the
+// messages with the given service account token
+func NewHealthWatcher(channel notification.Channel, serviceAccountToken string, interval time.Duration, expiry time.Duration) *HealthWatcher {
+	return &HealthWatcher{
+		channel:  channel,
+		token:    serviceAccountToken,
+		interval: interval,
+		expiry:   expiry,
+		watched:  map[watchedEnvironmentKey]*watchedEnvironment{},
+	}
+}
+
+// Watch starts watching the deployments of a space in an environment, from
+// their given current health, or extends the watch if the environment is
+// already watched. The given function creates a client with the token of the
+// current user, which expires at the given time: the client is created in the
+// first case, or to replace a client whose token expires earlier, and closed
+// when the watch expires. A replaced client is closed by the next check since
+// a running check may still use it. The watch never outlives the token of its
+// client.
+func (w *HealthWatcher) Watch(spaceID uuid.UUID, spaceName string, health *app.SimpleEnvironmentHealth,
+	tokenExpiry time.Time, newClient func() (KubeClientInterface, error)) error {
+	now := time.Now()
+	if !tokenExpiry.After(now) {
+		return errs.Errorf("the token expired at %v", tokenExpiry)
+	}
+	key := watchedEnvironmentKey{spaceID: spaceID, envName: health.Attributes.Name}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	env, ok := w.watched[key]
+	if !ok || env.tokenExpiry.Before(tokenExpiry) {
+		kc, err := newClient()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			env = &watchedEnvironment{spaceName: spaceName, health: applicationHealth(health)}
+			w.watched[key] = env
+		} else {
+			w.replaced = append(w.replaced, env.kc)
+		}
+		env.kc = kc
+		env.tokenExpiry = tokenExpiry
+	}
+	env.expiresAt = now.Add(w.expiry)
+	if env.tokenExpiry.Before(env.expiresAt) {
+		env.expiresAt = env.tokenExpiry
+	}
+	return nil
+}
+
+// Start starts checking the health of the watched environments in the
+// background
+func (w *HealthWatcher) Start() {
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.check()
+			}
+		}
+	}()
+}
+
+// Stop stops the watcher and closes the clients of the watched environments
+func (w *HealthWatcher) Stop() {
+	if w.stop != nil {
+		close(w.stop)
+		<-w.done
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, env := range w.watched {
+		env.kc.Close()
+		delete(w.watched, key)
+	}
+	for _, kc := range w.replaced {
+		kc.Close()
+	}
+	w.replaced = nil
+}
+
+// check closes the clients replaced since the previous check, which is over,
+// drops the expired environments and checks the health of the others. Only
+// this goroutine closes the clients while the watcher runs.
+func (w *HealthWatcher) check() {
+	now := time.Now()
+	w.mu.Lock()
+	for _, kc := range w.replaced {
+		kc.Close()
+	}
+	w.replaced = nil
+	watched := make(map[watchedEnvironmentKey]*watchedEnvironment, len(w.watched))
+	clients := make(map[watchedEnvironmentKey]KubeClientInterface, len(w.watched))
+	for key, env := range w.watched {
+		if now.After(env.expiresAt) {
+			env.kc.Close()
+			delete(w.watched, key)
+			continue
+		}
+		watched[key] = env
+		clients[key] = env.kc
+	}
+	w.mu.Unlock()
+
+	// the messages are signed with the service account token
+	ctx := goajwt.WithJWT(context.Background(), &jwt.Token{Raw: w.token})
+	for key, env := range watched {
+		// the environments are only checked by this goroutine, the lock
+		// guards the watched map, the clients and the expiry
+		health, err := clients[key].GetEnvironmentHealth(env.spaceName, key.envName)
+		if err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err":         err,
+				"space_id":    key.spaceID,
+				"environment": key.envName,
+			}, "failed to check the health of the deployments")
+			continue
+		}
+		current := applicationHealth(health)
+		for appName, status := range current {
+			// the applications deployed since the last check were healthy
+			previous, known := env.health[appName]
+			if !known {
+				previous = HealthOK
+			}
+			if IsWorseHealth(status, previous) {
+				log.Info(nil, map[string]interface{}{
+					"space_id":         key.spaceID,
+					"environment":      key.envName,
+					"application_name": appName,
+					"health":           status,
+				}, "deployment became unhealthy")
+				w.channel.Send(ctx, notification.NewDeploymentUnhealthy(key.spaceID, key.envName, appName))
+			}
+		}
+		env.health = current
+	}
+}
+
+// applicationHealth returns the health of each application of the environment
+func applicationHealth(health *app.SimpleEnvironmentHealth) map[string]string {
+	result := make(map[string]string, len(health.Attributes.Applications))
+	for _, a := range health.Attributes.Applications {
+		result[a.Name] = a.Status
+	}
+	return result
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -34,10 +36,10 @@ type KubeClientConfig struct {
 	// Kubernetes namespace in the cluster of type 'user'
 	UserNamespace string
 	// Timeout used for communicating with Kubernetes and OpenShift API servers,
-	// a value of zero indicates no timeout
+	// and with Prometheus, a value of zero indicates no timeout
 	Timeout time.Duration
 	// Specifies a non-default HTTP transport to use when sending requests to
-	// Kubernetes and OpenShift API servers
+	// Kubernetes and OpenShift API servers, and to Prometheus
 	Transport http.RoundTripper
 	// Provides access to the Kubernetes REST API, uses default implementation if not set
 	KubeRESTAPIGetter
@@ -73,6 +75,11 @@ type KubeClientInterface interface {
 	GetDeploymentStatSeries(spaceName string, appName string, envName string, startTime time.Time,
 		endTime time.Time, limit int) (*app.SimpleDeploymentStatSeries, error)
 	DeleteDeployment(spaceName string, appName string, envName string) error
+	GetDeploymentHistory(spaceName string, appName string, envName string) ([]*app.SimpleDeploymentRevision, error)
+	RollbackDeployment(spaceName string, appName string, envName string, version int) error
+	GetDeploymentLogs(spaceName string, appName string, envName string, opts *DeploymentLogOptions) (io.ReadCloser, error)
+	GetDeploymentEvents(spaceName string, appName string, envName string) ([]*app.SimpleDeploymentEvent, error)
+	GetEnvironmentHealth(spaceName string, envName string) (*app.SimpleEnvironmentHealth, error)
 	GetEnvironments() ([]*app.SimpleEnvironment, error)
 	GetEnvironment(envName string) (*app.SimpleEnvironment, error)
 	GetMetricsClient(envNS string) (Metrics, error)
@@ -107,6 +114,8 @@ type OpenShiftRESTAPI interface {
 	DeleteDeploymentConfig(namespace string, name string, opts *metaV1.DeleteOptions) error
 	GetDeploymentConfigScale(namespace string, name string) (map[string]interface{}, error)
 	SetDeploymentConfigScale(namespace string, name string, scale map[string]interface{}) error
+	RollbackDeploymentConfig(namespace string, name string, rollback map[string]interface{}) (map[string]interface{}, error)
+	UpdateDeploymentConfig(namespace string, name string, dc map[string]interface{}) error
 	GetRoutes(namespace string, labelSelector string) (map[string]interface{}, error)
 	DeleteRoute(namespace string, name string, opts *metaV1.DeleteOptions) error
 }
@@ -257,6 +266,8 @@ func (kc *kubeClient) GetMetricsClient(envNS string) (Metrics, error) {
 	metricsConfig := &MetricsClientConfig{
 		MetricsURL:  *url,
 		BearerToken: *token,
+		Timeout:     kc.config.Timeout,
+		Transport:   kc.config.Transport,
 	}
 
 	metrics, err := kc.GetMetrics(metricsConfig)
@@ -400,6 +411,195 @@ func (oc *openShiftAPIClient) SetDeploymentConfigScale(namespace string, name st
 	return oc.sendResource(dcScalePath, "PUT", scale)
 }
 
+// GetDeploymentHistory returns the deployments of an application within a particular environment,
+// from the most recent to the oldest one. The application must exist within the provided space.
+func (kc *kubeClient) GetDeploymentHistory(spaceName string, appName string, envName string) ([]*app.SimpleDeploymentRevision, error) {
+	envNS, err := kc.getEnvironmentNamespace(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deployment Config name does not always match the application name, look up
+	// DC name using available metadata
+	dcName, err := kc.getDeploymentConfigNameForApp(envNS, appName, spaceName)
+	if err != nil {
+		return nil, err
+	}
+	deploy, err := kc.getAndParseDeploymentConfig(envNS, dcName, spaceName)
+	if err != nil {
+		return nil, err
+	} else if deploy == nil {
+		return nil, nil
+	}
+
+	// Each deployment of the DC is kept as a replication controller, up to the
+	// revision history limit of the DC
+	rcs, err := kc.getReplicationControllers(envNS, deploy)
+	if err != nil {
+		return nil, err
+	}
+	current, err := getCurrentReplicationController(rcs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*app.SimpleDeploymentRevision, 0, len(rcs))
+	for idx := range rcs {
+		rc := &rcs[idx]
+		versionStr, pres := rc.Annotations[deploymentVersionAnnotation]
+		if !pres {
+			// Not created by the deployment config, e.g. when adopted
+			continue
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, errs.Wrapf(err, "deployment version for %s is not a valid integer", rc.Name)
+		}
+		result = append(result, newDeploymentRevision(rc, version, current != nil && rc.UID == current.UID))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Attributes.Version > result[j].Attributes.Version
+	})
+	return result, nil
+}
+
+func newDeploymentRevision(rc *v1.ReplicationController, version int, current bool) *app.SimpleDeploymentRevision {
+	created := rc.CreationTimestamp.UTC()
+	replicas := int(rc.Status.Replicas)
+	attrs := &app.SimpleDeploymentRevisionAttributes{
+		Version:  version,
+		Created:  &created,
+		Replicas: &replicas,
+		Current:  &current,
+	}
+	if phase, pres := rc.Annotations[deploymentPhaseAnnotation]; pres {
+		attrs.Status = &phase
+	}
+	if rc.Spec.Template != nil {
+		if appVersion, pres := rc.Spec.Template.Labels["version"]; pres {
+			attrs.AppVersion = &appVersion
+		}
+		for _, container := range rc.Spec.Template.Spec.Containers {
+			attrs.Images = append(attrs.Images, container.Image)
+		}
+	}
+	return &app.SimpleDeploymentRevision{
+		Type:       "deploymentrevision",
+		ID:         rc.Name,
+		Attributes: attrs,
+	}
+}
+
+// RollbackDeployment rolls back the deployment of an application within a particular environment
+// to the given earlier version. The pod template of that version is deployed again.
+func (kc *kubeClient) RollbackDeployment(spaceName string, appName string, envName string, version int) error {
+	envNS, err := kc.getEnvironmentNamespace(envName)
+	if err != nil {
+		return err
+	}
+
+	// Deployment Config name does not always match the application name, look up
+	// DC name using available metadata
+	dcName, err := kc.getDeploymentConfigNameForApp(envNS, appName, spaceName)
+	if err != nil {
+		return err
+	}
+	deploy, err := kc.getAndParseDeploymentConfig(envNS, dcName, spaceName)
+	if err != nil {
+		return err
+	} else if deploy == nil {
+		return errors.NewNotFoundError("deployment", envName)
+	}
+
+	// Find the replication controller of the requested version
+	rcs, err := kc.getReplicationControllers(envNS, deploy)
+	if err != nil {
+		return err
+	}
+	var target *v1.ReplicationController
+	for idx := range rcs {
+		if rcs[idx].Annotations[deploymentVersionAnnotation] == strconv.Itoa(version) {
+			target = &rcs[idx]
+			break
+		}
+	}
+	if target == nil {
+		return errors.NewNotFoundError("deployment version", strconv.Itoa(version))
+	}
+
+	// Let OpenShift generate the rolled back deployment config, with the same
+	// options as 'oc rollback'
+	rollback := map[string]interface{}{
+		"kind":       "DeploymentConfigRollback",
+		"apiVersion": "v1",
+		"name":       dcName,
+		"spec": map[string]interface{}{
+			"from": map[string]interface{}{
+				"kind": "ReplicationController",
+				"name": target.Name,
+			},
+			"includeTemplate":        true,
+			"includeTriggers":        false,
+			"includeReplicationMeta": false,
+			"includeStrategy":        false,
+		},
+	}
+	dc, err := kc.RollbackDeploymentConfig(envNS, dcName, rollback)
+	if err != nil {
+		return err
+	}
+
+	// As done by 'oc rollback', disable the image change triggers so that the
+	// rolled back deployment is not replaced by the next image
+	spec, ok := dc["spec"].(map[string]interface{})
+	if !ok {
+		return errs.New("invalid deployment config returned from endpoint: missing 'spec'")
+	}
+	if triggers, ok := spec["triggers"].([]interface{}); ok {
+		for _, trigger := range triggers {
+			trigger, ok := trigger.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if params, ok := trigger["imageChangeParams"].(map[string]interface{}); ok {
+				params["automatic"] = false
+			}
+		}
+	}
+
+	err = kc.UpdateDeploymentConfig(envNS, dcName, dc)
+	if err != nil {
+		return err
+	}
+
+	log.Info(nil, map[string]interface{}{
+		"space_name":       spaceName,
+		"application_name": appName,
+		"environment_name": envName,
+		"version":          version,
+	}, "rolled back deployment to version %d", version)
+	return nil
+}
+
+func (oc *openShiftAPIClient) RollbackDeploymentConfig(namespace string, name string, rollback map[string]interface{}) (map[string]interface{}, error) {
+	dcRollbackPath := fmt.Sprintf("/oapi/v1/namespaces/%s/deploymentconfigs/%s/rollback", namespace, name)
+	body, err := oc.sendResourceWithResponse(dcRollbackPath, "POST", rollback)
+	if err != nil {
+		return nil, err
+	}
+	var dc map[string]interface{}
+	err = json.Unmarshal(body, &dc)
+	if err != nil {
+		return nil, errs.Wrapf(err, "invalid deployment config returned from %s", dcRollbackPath)
+	}
+	return dc, nil
+}
+
+func (oc *openShiftAPIClient) UpdateDeploymentConfig(namespace string, name string, dc map[string]interface{}) error {
+	dcPath := fmt.Sprintf("/oapi/v1/namespaces/%s/deploymentconfigs/%s", namespace, name)
+	return oc.sendResource(dcPath, "PUT", dc)
+}
+
 func (kc *kubeClient) getApplicationURL(envNS string, deploy *deployment) (*string, error) {
 	// Get the best route to the application to show to the user
 	routeURL, err := kc.getBestRoute(envNS, deploy)
@@ -807,9 +1007,16 @@ func (kc *kubeClient) getEnvironmentNamespace(envName string) (string, error) {
 
 // Derived from: https://github.com/fabric8-services/fabric8-tenant/blob/master/openshift/kube_token.go
 func (oc *openShiftAPIClient) sendResource(path string, method string, reqBody interface{}) error {
+	_, err := oc.sendResourceWithResponse(path, method, reqBody)
+	return err
+}
+
+// sendResourceWithResponse behaves like sendResource, but also returns the
+// body of the response
+func (oc *openShiftAPIClient) sendResourceWithResponse(path string, method string, reqBody interface{}) ([]byte, error) {
 	url, err := oc.config.GetAPIURL()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	fullURL := strings.TrimSuffix(*url, "/") + path
 
@@ -820,7 +1027,7 @@ func (oc *openShiftAPIClient) sendResource(path string, method string, reqBody i
 			"url":          fullURL,
 			"request_body": reqBody,
 		}, "could not marshall %s request", method)
-		return errs.WithStack(err)
+		return nil, errs.WithStack(err)
 	}
 
 	req, err := http.NewRequest(method, fullURL, bytes.NewBuffer(marshalled))
@@ -830,12 +1037,12 @@ func (oc *openShiftAPIClient) sendResource(path string, method string, reqBody i
 			"url":          fullURL,
 			"request_body": reqBody,
 		}, "could not create %s request", method)
-		return errs.WithStack(err)
+		return nil, errs.WithStack(err)
 	}
 
 	token, err := oc.config.GetAPIToken()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
@@ -848,7 +1055,7 @@ func (oc *openShiftAPIClient) sendResource(path string, method string, reqBody i
 			"url":          fullURL,
 			"request_body": reqBody,
 		}, "could not perform %s request", method)
-		return errs.WithStack(err)
+		return nil, errs.WithStack(err)
 	}
 	defer resp.Body.Close()
 
@@ -860,12 +1067,12 @@ func (oc *openShiftAPIClient) sendResource(path string, method string, reqBody i
 			"url":          fullURL,
 			"request_body": reqBody,
 		}, "could not read response from %s request", method)
-		return errs.WithStack(err)
+		return nil, errs.WithStack(err)
 	}
 	respBody := buf.Bytes()
 
 	status := resp.StatusCode
-	if status != http.StatusOK {
+	if status != http.StatusOK && status != http.StatusCreated {
 		log.Error(nil, map[string]interface{}{
 			"url":           fullURL,
 			"request_body":  reqBody,
@@ -876,11 +1083,11 @@ func (oc *openShiftAPIClient) sendResource(path string, method string, reqBody i
 		// If response contains a Kubernetes Status object, create a StatusError
 		err = parseErrorFromStatus(respBody)
 		if err != nil {
-			return convertError(errs.WithStack(err), "failed to %s url %s due to status code %d", method, fullURL, status)
+			return nil, convertError(errs.WithStack(err), "failed to %s url %s due to status code %d", method, fullURL, status)
 		}
-		return errs.Errorf("failed to %s url %s: status code %d", method, fullURL, status)
+		return nil, errs.Errorf("failed to %s url %s: status code %d", method, fullURL, status)
 	}
-	return nil
+	return respBody, nil
 }
 
 func (kc *kubeClient) getAndParseDeploymentConfig(namespace string, dcName string, space string) (*deployment, error) {
@@ -1132,6 +1339,17 @@ func (kc *kubeClient) getCurrentDeployment(space string, appName string, namespa
 		return result, nil
 	}
 
+	current, err := getCurrentReplicationController(rcs)
+	if err != nil {
+		return nil, err
+	}
+	result.current = current
+	return result, nil
+}
+
+// getCurrentReplicationController returns the current deployment among the
+// replication controllers of a deployment config
+func getCurrentReplicationController(rcs []v1.ReplicationController) (*v1.ReplicationController, error) {
 	// Find newest RC created by this DC, which is also considered visible according to the
 	// OpenShift web console's criteria:
 	// https://github.com/openshift/origin-web-console/blob/v3.7.0/app/scripts/controllers/overview.js#L679
@@ -1153,12 +1371,7 @@ func (kc *kubeClient) getCurrentDeployment(space string, appName string, namespa
 		candidates[active.Name] = active
 	}
 	// For final comparison use deployment version annotation instead of creation timestamp
-	current, err := getMostRecentByDeploymentVersion(candidates)
-	if err != nil {
-		return nil, err
-	}
-	result.current = current
-	return result, nil
+	return getMostRecentByDeploymentVersion(candidates)
 }
 
 func isReplicationControllerVisible(rc *v1.ReplicationController) bool {
@@ -19,6 +19,7 @@ import (
 
 	"github.com/fabric8-services/fabric8-wit/app"
 	"github.com/fabric8-services/fabric8-wit/kubernetes"
+	"github.com/fabric8-services/fabric8-wit/ptr"
 
 	errs "github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -1086,6 +1087,220 @@ func TestScaleDeployment(t *testing.T) {
 	}
 }
 
+func TestGetDeploymentHistory(t *testing.T) {
+	testCases := []struct {
+		testName     string
+		spaceName    string
+		appName      string
+		envName      string
+		cassetteName string
+		expected     []*app.SimpleDeploymentRevisionAttributes
+		shouldFail   bool
+		errorChecker func(error) (bool, error)
+	}{
+		{
+			testName:     "Basic",
+			spaceName:    "mySpace",
+			appName:      "myApp",
+			envName:      "run",
+			cassetteName: "deploymenthistory",
+			expected: []*app.SimpleDeploymentRevisionAttributes{
+				{
+					Version:    3,
+					AppVersion: ptr.String("1.0.3"),
+					Images:     []string{"127.0.0.1:5000/my-run/myDeploy@sha256:0bad6e4f216f2fb4b69fff9b3a44842c38686ca685f3f55dc48c5d3fb1107be4"},
+					Created:    ptr.Time(time.Date(2018, 1, 26, 9, 1, 17, 0, time.UTC)),
+					Status:     ptr.String("Failed"),
+					Replicas:   ptr.Int(0),
+					Current:    ptr.Bool(false),
+				},
+				{
+					Version:    2,
+					AppVersion: ptr.String("1.0.2"),
+					Images:     []string{"127.0.0.1:5000/my-run/myDeploy@sha256:98ea6e4f216f2fb4b69fff9b3a44842c38686ca685f3f55dc48c5d3fb1107be4"},
+					Created:    ptr.Time(time.Date(2018, 1, 25, 16, 33, 3, 0, time.UTC)),
+					Status:     ptr.String("Complete"),
+					Replicas:   ptr.Int(2),
+					Current:    ptr.Bool(true),
+				},
+				{
+					Version:    1,
+					AppVersion: ptr.String("1.0.1"),
+					Images:     []string{"127.0.0.1:5000/my-run/myDeploy@sha256:3e1f6d8a5cbb6f4a1b3c9e3f5d9a2c7b8e0f1a2b3c4d5e6f708192a3b4c5d6e7"},
+					Created:    ptr.Time(time.Date(2018, 1, 24, 10, 12, 40, 0, time.UTC)),
+					Status:     ptr.String("Complete"),
+					Replicas:   ptr.Int(0),
+					Current:    ptr.Bool(false),
+				},
+			},
+		},
+		{
+			testName:     "Bad Environment",
+			spaceName:    "mySpace",
+			appName:      "myApp",
+			envName:      "doesNotExist",
+			cassetteName: "deploymenthistory",
+			shouldFail:   true,
+		},
+		{
+			testName:     "RC Error",
+			spaceName:    "mySpace",
+			appName:      "myApp",
+			envName:      "run",
+			cassetteName: "deploymenthistory-rc-error",
+			shouldFail:   true,
+			errorChecker: errors.IsInternalError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			r, err := recorder.New(pathToTestJSON + testCase.cassetteName)
+			require.NoError(t, err, "Failed to open cassette")
+			defer r.Stop()
+
+			fixture := &testFixture{}
+			kc := getDefaultKubeClient(fixture, r.Transport, t)
+
+			revisions, err := kc.GetDeploymentHistory(testCase.spaceName, testCase.appName, testCase.envName)
+			if testCase.shouldFail {
+				require.Error(t, err, "Expected an error")
+				if testCase.errorChecker != nil {
+					matches, _ := testCase.errorChecker(err)
+					require.True(t, matches, "Error or cause must be the expected type")
+				}
+			} else {
+				require.NoError(t, err, "Unexpected error occurred")
+				require.Len(t, revisions, len(testCase.expected), "Wrong number of versions")
+				for idx, revision := range revisions {
+					require.Equal(t, "deploymentrevision", revision.Type, "Wrong type")
+					require.Equal(t, fmt.Sprintf("myDeploy-%d", revision.Attributes.Version), revision.ID, "Wrong ID")
+					require.Equal(t, testCase.expected[idx], revision.Attributes, "Wrong attributes")
+				}
+			}
+		})
+	}
+}
+
+func TestRollbackDeployment(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		spaceName     string
+		appName       string
+		envName       string
+		version       int
+		cassetteName  string
+		expectPutURLs map[string]struct{}
+		shouldFail    bool
+		errorChecker  func(error) (bool, error)
+	}{
+		{
+			testName:     "Basic",
+			spaceName:    "mySpace",
+			appName:      "myApp",
+			envName:      "run",
+			version:      1,
+			cassetteName: "rollbackdeployment",
+			expectPutURLs: map[string]struct{}{
+				"http://api.myCluster/oapi/v1/namespaces/my-run/deploymentconfigs/myDeploy": {},
+			},
+		},
+		{
+			testName:      "Unknown Version",
+			spaceName:     "mySpace",
+			appName:       "myApp",
+			envName:       "run",
+			version:       5,
+			cassetteName:  "rollbackdeployment-noversion",
+			expectPutURLs: map[string]struct{}{},
+			shouldFail:    true,
+			errorChecker:  errors.IsNotFoundError,
+		},
+		{
+			testName:      "Rollback Error",
+			spaceName:     "mySpace",
+			appName:       "myApp",
+			envName:       "run",
+			version:       1,
+			cassetteName:  "rollbackdeployment-rollback-error",
+			expectPutURLs: map[string]struct{}{},
+			shouldFail:    true,
+			errorChecker:  errors.IsBadParameterError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			r, err := recorder.New(pathToTestJSON + testCase.cassetteName)
+			r.SetMatcher(func(actual *http.Request, expected cassette.Request) bool {
+				if cassette.DefaultMatcher(actual, expected) {
+					if actual.Method == "POST" || actual.Method == "PUT" {
+						var buf bytes.Buffer
+						reqBody := actual.Body
+						_, err := buf.ReadFrom(reqBody)
+						require.NoError(t, err, "Error reading request body")
+						defer reqBody.Close()
+
+						var output map[string]interface{}
+						err = json.Unmarshal(buf.Bytes(), &output)
+						require.NoError(t, err, "Request body must be JSON object")
+						spec, ok := output["spec"].(map[string]interface{})
+						require.True(t, ok, "Spec property is missing or invalid")
+
+						if actual.Method == "POST" {
+							// Check the rollback targets the RC of the requested version
+							from, ok := spec["from"].(map[string]interface{})
+							require.True(t, ok, "From property is missing or invalid")
+							require.Equal(t, fmt.Sprintf("myDeploy-%d", testCase.version), from["name"], "Wrong rollback target")
+						} else {
+							// Mark interaction as seen
+							reqURL := actual.URL.String()
+							_, pres := testCase.expectPutURLs[reqURL]
+							require.True(t, pres, "Unexpected PUT request %s", reqURL)
+							delete(testCase.expectPutURLs, reqURL)
+
+							// Check image change triggers were disabled
+							triggers, ok := spec["triggers"].([]interface{})
+							require.True(t, ok, "Triggers property is missing or invalid")
+							for _, trigger := range triggers {
+								trigger, ok := trigger.(map[string]interface{})
+								require.True(t, ok, "Trigger is not an object")
+								if params, ok := trigger["imageChangeParams"].(map[string]interface{}); ok {
+									require.Equal(t, false, params["automatic"], "Image change trigger not disabled")
+								}
+							}
+						}
+
+						// Replace body
+						actual.Body = ioutil.NopCloser(&buf)
+					}
+					return true
+				}
+				return false
+			})
+			require.NoError(t, err, "Failed to open cassette")
+			defer r.Stop()
+
+			fixture := &testFixture{}
+			kc := getDefaultKubeClient(fixture, r.Transport, t)
+
+			err = kc.RollbackDeployment(testCase.spaceName, testCase.appName, testCase.envName, testCase.version)
+			if testCase.shouldFail {
+				require.Error(t, err, "Expected an error")
+				if testCase.errorChecker != nil {
+					matches, _ := testCase.errorChecker(err)
+					require.True(t, matches, "Error or cause must be the expected type")
+				}
+			} else {
+				require.NoError(t, err, "Unexpected error occurred")
+			}
+
+			// Check we saw all expected PUT requests
+			require.Empty(t, testCase.expectPutURLs, "Not all PUT requests sent: %v", testCase.expectPutURLs)
+		})
+	}
+}
+
 func TestDeleteDeployment(t *testing.T) {
 	// DeleteOptions do not change
 	policy := metav1.DeletePropagationForeground
@@ -0,0 +1,163 @@
+package kubernetes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "k8s.io/client-go/pkg/api/v1"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	errs "github.com/pkg/errors"
+)
+
+// maxLogLineSize is the size of the longest log line that can be read from a pod
+const maxLogLineSize = 1024 * 1024
+
+// DeploymentLogOptions selects the logs returned by GetDeploymentLogs
+type DeploymentLogOptions struct {
+	// Container is the name of the container to read the logs from, which may
+	// be left empty when the pods have a single container
+	Container string
+	// TailLines is the number of lines to read from the end of the logs of each
+	// pod, all the lines are read when nil
+	TailLines *int64
+	// SinceTime only reads the lines logged after the given time when set
+	SinceTime *time.Time
+	// Follow keeps streaming the new lines until the logs are closed
+	Follow bool
+}
+
+// GetDeploymentLogs returns the logs of the pods of the current deployment of an application
+// within a particular environment. Each line is prefixed with the name of its pod. The logs of
+// the pods follow each other, unless they are followed: the lines are then written as they come.
+// The returned logs must be closed by the caller.
+func (kc *kubeClient) GetDeploymentLogs(spaceName string, appName string, envName string,
+	opts *DeploymentLogOptions) (io.ReadCloser, error) {
+	envNS, err := kc.getEnvironmentNamespace(envName)
+	if err != nil {
+		return nil, err
+	}
+	deploy, err := kc.getCurrentDeployment(spaceName, appName, envNS)
+	if err != nil {
+		return nil, err
+	} else if deploy == nil || deploy.current == nil {
+		return nil, errors.NewNotFoundError("deployment", envName)
+	}
+	pods, err := kc.getPods(envNS, deploy.current)
+	if err != nil {
+		return nil, err
+	}
+
+	logOpts := &v1.PodLogOptions{
+		Container: opts.Container,
+		TailLines: opts.TailLines,
+		Follow:    opts.Follow,
+	}
+	if opts.SinceTime != nil {
+		since := metaV1.NewTime(*opts.SinceTime)
+		logOpts.SinceTime = &since
+	}
+	// Open all the streams first, so that an error is returned before any
+	// line is read
+	streams := make([]podLogStream, 0, len(pods))
+	for _, pod := range pods {
+		stream, err := kc.Pods(envNS).GetLogs(pod.Name, logOpts).Stream()
+		if err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err":       err,
+				"namespace": envNS,
+				"pod_name":  pod.Name,
+				"container": opts.Container,
+			}, "failed to get the logs of the pod")
+			for _, s := range streams {
+				s.stream.Close()
+			}
+			return nil, convertError(errs.WithStack(err), "failed to get the logs of pod %s in %s", pod.Name, envNS)
+		}
+		streams = append(streams, podLogStream{pod: pod.Name, stream: stream})
+	}
+	return newPodLogReader(streams, opts.Follow), nil
+}
+
+// podLogStream is the stream of the logs of a pod
+type podLogStream struct {
+	pod    string
+	stream io.ReadCloser
+}
+
+// podLogReader merges the logs of several pods, prefixing each line with the
+// name of its pod
+type podLogReader struct {
+	*io.PipeReader
+	streams   []podLogStream
+	closeOnce sync.Once
+}
+
+// newPodLogReader starts copying the lines of the given streams, one after the
+// other or concurrently
+func newPodLogReader(streams []podLogStream, concurrent bool) *podLogReader {
+	pr, pw := io.Pipe()
+	var mu sync.Mutex
+	copyLines := func(s podLogStream) error {
+		scanner := bufio.NewScanner(s.stream)
+		scanner.Buffer(nil, maxLogLineSize)
+		for scanner.Scan() {
+			mu.Lock()
+			_, err := fmt.Fprintf(pw, "[%s] %s\n", s.pod, scanner.Bytes())
+			mu.Unlock()
+			if err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}
+	go func() {
+		var err error
+		if concurrent {
+			var wg sync.WaitGroup
+			results := make(chan error, len(streams))
+			for _, s := range streams {
+				wg.Add(1)
+				go func(s podLogStream) {
+					defer wg.Done()
+					results <- copyLines(s)
+				}(s)
+			}
+			wg.Wait()
+			close(results)
+			for result := range results {
+				if err == nil {
+					err = result
+				}
+			}
+		} else {
+			for _, s := range streams {
+				if err = copyLines(s); err != nil {
+					break
+				}
+			}
+		}
+		// a nil error ends the logs with io.EOF
+		pw.CloseWithError(err)
+	}()
+	return &podLogReader{
+		PipeReader: pr,
+		streams:    streams,
+	}
+}
+
+// Close stops reading the logs of the pods. It may be called several times.
+func (r *podLogReader) Close() error {
+	r.closeOnce.Do(func() {
+		r.PipeReader.Close()
+		for _, s := range r.streams {
+			s.stream.Close()
+		}
+	})
+	return nil
+}
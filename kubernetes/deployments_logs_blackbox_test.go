@@ -0,0 +1,88 @@
+package kubernetes_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/kubernetes"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDeploymentLogs(t *testing.T) {
+	testCases := []struct {
+		testName     string
+		spaceName    string
+		appName      string
+		envName      string
+		container    string
+		cassetteName string
+		expected     string
+		shouldFail   bool
+		errorChecker func(error) (bool, error)
+	}{
+		{
+			testName:     "Basic",
+			spaceName:    "mySpace",
+			appName:      "myApp",
+			envName:      "run",
+			container:    "myDeploy",
+			cassetteName: "deploymentlogs",
+			expected: "[myDeploy-1-nfs9w] Starting the Java application\n" +
+				"[myDeploy-1-nfs9w] Started in 3.2 seconds\n" +
+				"[myDeploy-1-sdmzq] Starting the Java application\n" +
+				"[myDeploy-1-sdmzq] GET / 200\n",
+		},
+		{
+			testName:     "Bad Environment",
+			spaceName:    "mySpace",
+			appName:      "myApp",
+			envName:      "doesNotExist",
+			container:    "myDeploy",
+			cassetteName: "deploymentlogs",
+			shouldFail:   true,
+		},
+		{
+			testName:     "Unknown Container",
+			spaceName:    "mySpace",
+			appName:      "myApp",
+			envName:      "run",
+			container:    "notMyDeploy",
+			cassetteName: "deploymentlogs-error",
+			shouldFail:   true,
+			errorChecker: errors.IsBadParameterError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			r, err := recorder.New(pathToTestJSON + testCase.cassetteName)
+			require.NoError(t, err, "Failed to open cassette")
+			defer r.Stop()
+
+			fixture := &testFixture{}
+			kc := getDefaultKubeClient(fixture, r.Transport, t)
+
+			opts := &kubernetes.DeploymentLogOptions{
+				Container: testCase.container,
+				TailLines: ptr.Int64(2),
+			}
+			logs, err := kc.GetDeploymentLogs(testCase.spaceName, testCase.appName, testCase.envName, opts)
+			if testCase.shouldFail {
+				require.Error(t, err, "Expected an error")
+				if testCase.errorChecker != nil {
+					matches, _ := testCase.errorChecker(err)
+					require.True(t, matches, "Error or cause must be the expected type")
+				}
+			} else {
+				require.NoError(t, err, "Unexpected error occurred")
+				defer logs.Close()
+				output, err := ioutil.ReadAll(logs)
+				require.NoError(t, err, "Error reading logs")
+				require.Equal(t, testCase.expected, string(output), "Wrong logs")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"net/http"
 	"strings"
 	"time"
 
@@ -17,6 +18,10 @@ type MetricsClientConfig struct {
 	MetricsURL string
 	// An authorized token to access the cluster
 	BearerToken string
+	// Timeout used per HTTP request to the Prometheus HTTP API, Hawkular uses its own
+	Timeout time.Duration
+	// Transport used by the Prometheus client, http.DefaultTransport is used if not set
+	Transport http.RoundTripper
 	// Provides access to the underlying Hawkular API, uses default implementation if not set
 	HawkularGetter
 }
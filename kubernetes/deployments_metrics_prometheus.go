@@ -0,0 +1,296 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/log"
+	errs "github.com/pkg/errors"
+	v1 "k8s.io/client-go/pkg/api/v1"
+)
+
+// PrometheusMetricsGetter is a MetricsGetter reading the metrics of the
+// deployments from a Prometheus HTTP API instead of Hawkular
+type PrometheusMetricsGetter struct {
+	// URL of the Prometheus HTTP API, the metrics URL of the cluster is used if not set
+	URL string
+}
+
+// Queries of the cAdvisor metrics of the pods of a deployment, each point is
+// the average over the bucket duration before it. The network metrics are only
+// reported for the "POD" container holding the network of the pod.
+const (
+	promCPUQuery  = `sum(rate(container_cpu_usage_seconds_total{%s,container_name!="POD",container_name!=""}[1m]))`
+	promMemQuery  = `sum(avg_over_time(container_memory_usage_bytes{%s,container_name!="POD",container_name!=""}[1m]))`
+	promSentQuery = `sum(rate(container_network_transmit_bytes_total{%s,container_name="POD"}[1m]))`
+	promRecvQuery = `sum(rate(container_network_receive_bytes_total{%s,container_name="POD"}[1m]))`
+)
+
+type prometheusClient struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// ensure prometheusClient implements Metrics
+var _ Metrics = &prometheusClient{}
+var _ Metrics = (*prometheusClient)(nil)
+
+// GetMetrics creates a Prometheus client, see NewPrometheusMetricsClient
+func (g *PrometheusMetricsGetter) GetMetrics(config *MetricsClientConfig) (Metrics, error) {
+	if g.URL != "" {
+		prometheusConfig := *config
+		prometheusConfig.MetricsURL = g.URL
+		config = &prometheusConfig
+	}
+	return NewPrometheusMetricsClient(config)
+}
+
+// NewPrometheusMetricsClient creates a Metrics object reading the metrics from
+// the Prometheus HTTP API at the URL of the given configuration
+func NewPrometheusMetricsClient(config *MetricsClientConfig) (Metrics, error) {
+	if len(config.MetricsURL) == 0 {
+		return nil, errs.New("the URL of the Prometheus HTTP API is missing")
+	}
+	// Equivalent to http.DefaultClient with added timeout and transport
+	httpClient := &http.Client{
+		Timeout:   config.Timeout,
+		Transport: config.Transport,
+	}
+	pc := &prometheusClient{
+		url:        strings.TrimSuffix(config.MetricsURL, "/"),
+		token:      config.BearerToken,
+		httpClient: httpClient,
+	}
+	return pc, nil
+}
+
+// Close does nothing, the Prometheus client does not hold any resource
+func (pc *prometheusClient) Close() {
+}
+
+func (pc *prometheusClient) GetCPUMetrics(pods []*v1.Pod, namespace string, startTime time.Time) (*app.TimedNumberTuple, error) {
+	return pc.getBucketAverage(pods, namespace, promCPUQuery, startTime)
+}
+
+func (pc *prometheusClient) GetCPUMetricsRange(pods []*v1.Pod, namespace string,
+	startTime time.Time, endTime time.Time, limit int) ([]*app.TimedNumberTuple, error) {
+	return pc.getBucketsInRange(pods, namespace, promCPUQuery, startTime, endTime, limit)
+}
+
+func (pc *prometheusClient) GetMemoryMetrics(pods []*v1.Pod, namespace string, startTime time.Time) (*app.TimedNumberTuple, error) {
+	return pc.getBucketAverage(pods, namespace, promMemQuery, startTime)
+}
+
+func (pc *prometheusClient) GetMemoryMetricsRange(pods []*v1.Pod, namespace string,
+	startTime time.Time, endTime time.Time, limit int) ([]*app.TimedNumberTuple, error) {
+	return pc.getBucketsInRange(pods, namespace, promMemQuery, startTime, endTime, limit)
+}
+
+func (pc *prometheusClient) GetNetworkSentMetrics(pods []*v1.Pod, namespace string, startTime time.Time) (*app.TimedNumberTuple, error) {
+	return pc.getBucketAverage(pods, namespace, promSentQuery, startTime)
+}
+
+func (pc *prometheusClient) GetNetworkSentMetricsRange(pods []*v1.Pod, namespace string,
+	startTime time.Time, endTime time.Time, limit int) ([]*app.TimedNumberTuple, error) {
+	return pc.getBucketsInRange(pods, namespace, promSentQuery, startTime, endTime, limit)
+}
+
+func (pc *prometheusClient) GetNetworkRecvMetrics(pods []*v1.Pod, namespace string, startTime time.Time) (*app.TimedNumberTuple, error) {
+	return pc.getBucketAverage(pods, namespace, promRecvQuery, startTime)
+}
+
+func (pc *prometheusClient) GetNetworkRecvMetricsRange(pods []*v1.Pod, namespace string,
+	startTime time.Time, endTime time.Time, limit int) ([]*app.TimedNumberTuple, error) {
+	return pc.getBucketsInRange(pods, namespace, promRecvQuery, startTime, endTime, limit)
+}
+
+// getBucketAverage returns the average of the metric over the bucket starting
+// at the given time, or nil if there is no data
+func (pc *prometheusClient) getBucketAverage(pods []*v1.Pod, namespace string, query string,
+	startTime time.Time) (*app.TimedNumberTuple, error) {
+	if len(pods) == 0 {
+		return nil, nil
+	}
+	params := url.Values{}
+	params.Set("query", podsQuery(query, pods, namespace))
+	params.Set("time", formatPrometheusTime(startTime.Add(bucketDuration)))
+	series, err := pc.query("/api/v1/query", params)
+	if err != nil {
+		return nil, err
+	} else if len(series) == 0 || series[0].Value == nil || !series[0].Value.valid() {
+		return nil, nil
+	}
+	return series[0].Value.toTuple(), nil
+}
+
+// getBucketsInRange returns the averages of the metric over the buckets between
+// the start and end times, at most the newest limit ones if limit is not negative
+func (pc *prometheusClient) getBucketsInRange(pods []*v1.Pod, namespace string, query string,
+	startTime time.Time, endTime time.Time, limit int) ([]*app.TimedNumberTuple, error) {
+	results := []*app.TimedNumberTuple{}
+	// The first point is at the end of the first bucket, like with Hawkular the
+	// buckets ending after the end time are left out
+	firstPoint := startTime.Add(bucketDuration)
+	if len(pods) == 0 || firstPoint.After(endTime) {
+		return results, nil
+	}
+	params := url.Values{}
+	params.Set("query", podsQuery(query, pods, namespace))
+	params.Set("start", formatPrometheusTime(firstPoint))
+	params.Set("end", formatPrometheusTime(endTime))
+	params.Set("step", strconv.FormatFloat(bucketDuration.Seconds(), 'f', -1, 64))
+	series, err := pc.query("/api/v1/query_range", params)
+	if err != nil {
+		return nil, err
+	} else if len(series) == 0 {
+		return results, nil
+	}
+
+	// Points are ordered by time
+	for _, sample := range series[0].Values {
+		if sample.valid() {
+			results = append(results, sample.toTuple())
+		}
+	}
+	// If number of buckets is greater than requested limit n, take newest n buckets
+	if limit >= 0 && len(results) > limit {
+		results = results[len(results)-limit:]
+	}
+	return results, nil
+}
+
+// podsQuery selects the series of the given pods in the query
+func podsQuery(query string, pods []*v1.Pod, namespace string) string {
+	podNames := make([]string, len(pods))
+	for idx, pod := range pods {
+		podNames[idx] = regexp.QuoteMeta(pod.Name)
+	}
+	selector := fmt.Sprintf("namespace=%s,pod_name=~%s", strconv.Quote(namespace),
+		strconv.Quote(strings.Join(podNames, "|")))
+	return fmt.Sprintf(query, selector)
+}
+
+func formatPrometheusTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)
+}
+
+// prometheusResponse is the response of a query to the Prometheus HTTP API
+// See: https://prometheus.io/docs/prometheus/latest/querying/api/
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string             `json:"resultType"`
+		Result     []prometheusSeries `json:"result"`
+	} `json:"data"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+}
+
+// prometheusSeries holds the value of an instant query or the values of a
+// range query
+type prometheusSeries struct {
+	Metric map[string]string   `json:"metric"`
+	Value  *prometheusSample   `json:"value"`
+	Values []*prometheusSample `json:"values"`
+}
+
+// prometheusSample is a [<unix time in seconds>, "<value>"] pair
+type prometheusSample struct {
+	time  float64
+	value float64
+}
+
+func (s *prometheusSample) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	} else if len(raw) != 2 {
+		return errs.Errorf("invalid sample: %s", b)
+	}
+	if err := json.Unmarshal(raw[0], &s.time); err != nil {
+		return errs.Wrapf(err, "invalid sample time: %s", raw[0])
+	}
+	var value string
+	if err := json.Unmarshal(raw[1], &value); err != nil {
+		return errs.Wrapf(err, "invalid sample value: %s", raw[1])
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return errs.Wrapf(err, "invalid sample value: %s", value)
+	}
+	s.value = v
+	return nil
+}
+
+// valid returns false for the values that cannot be returned as JSON
+func (s *prometheusSample) valid() bool {
+	return !math.IsNaN(s.value) && !math.IsInf(s.value, 0)
+}
+
+func (s *prometheusSample) toTuple() *app.TimedNumberTuple {
+	// Use the start of the bucket as timestamp, as done for Hawkular
+	bucketStart := time.Unix(0, int64(s.time*float64(time.Second))).Add(-bucketDuration)
+	bucketTimeUnix := float64(convertToUnixMillis(bucketStart))
+	value := s.value
+	return &app.TimedNumberTuple{
+		Value: &value,
+		Time:  &bucketTimeUnix,
+	}
+}
+
+// query runs a query against the Prometheus HTTP API and returns the resulting series
+func (pc *prometheusClient) query(path string, params url.Values) ([]prometheusSeries, error) {
+	fullURL := pc.url + path + "?" + params.Encode()
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		log.Error(nil, map[string]interface{}{
+			"err": err,
+			"url": fullURL,
+		}, "error creating HTTP GET request")
+		return nil, errs.WithStack(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(pc.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+pc.token)
+	}
+
+	resp, err := pc.httpClient.Do(req)
+	if err != nil {
+		log.Error(nil, map[string]interface{}{
+			"err": err,
+			"url": fullURL,
+		}, "error during HTTP request")
+		return nil, errs.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	// Prometheus describes its errors in the same format as its results
+	var result prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		log.Error(nil, map[string]interface{}{
+			"err":         err,
+			"url":         fullURL,
+			"http_status": resp.StatusCode,
+		}, "could not decode the response of Prometheus")
+		return nil, errs.Wrapf(err, "could not decode the response of Prometheus with status %s", resp.Status)
+	}
+	if result.Status != "success" {
+		log.Error(nil, map[string]interface{}{
+			"url":         fullURL,
+			"http_status": resp.StatusCode,
+			"error_type":  result.ErrorType,
+			"error":       result.Error,
+		}, "error returned from Prometheus")
+		return nil, errs.Errorf("Prometheus query failed with status %s: %s: %s", resp.Status, result.ErrorType, result.Error)
+	}
+	return result.Data.Result, nil
+}
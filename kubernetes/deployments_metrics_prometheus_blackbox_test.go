@@ -0,0 +1,218 @@
+package kubernetes_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "k8s.io/client-go/pkg/api/v1"
+)
+
+// testPrometheus is a fake Prometheus server replying to every query with the
+// given status and body, and recording the last request
+type testPrometheus struct {
+	*httptest.Server
+	status  int
+	body    string
+	path    string
+	params  url.Values
+	headers http.Header
+}
+
+func newTestPrometheus(status int, body string) *testPrometheus {
+	p := &testPrometheus{
+		status: status,
+		body:   body,
+	}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.path = r.URL.Path
+		p.params = r.URL.Query()
+		p.headers = r.Header
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(p.status)
+		fmt.Fprint(w, p.body)
+	}))
+	return p
+}
+
+func newTestPrometheusClient(t *testing.T, p *testPrometheus) kubernetes.Metrics {
+	getter := &kubernetes.PrometheusMetricsGetter{
+		URL: p.URL + "/",
+	}
+	mc, err := getter.GetMetrics(&kubernetes.MetricsClientConfig{
+		MetricsURL:  "http://metrics.myCluster",
+		BearerToken: "myToken",
+	})
+	require.NoError(t, err)
+	return mc
+}
+
+var prometheusTestPods = []*v1.Pod{
+	{ObjectMeta: metav1.ObjectMeta{Name: "myDeploy-1-nfs9w"}},
+	{ObjectMeta: metav1.ObjectMeta{Name: "myDeploy-1-sdmzq"}},
+}
+
+const prometheusTestSelector = `namespace="my-run",pod_name=~"myDeploy-1-nfs9w|myDeploy-1-sdmzq"`
+
+func TestPrometheusMetrics(t *testing.T) {
+	startTime := time.Unix(1516301818, 0)
+
+	testCases := []struct {
+		testName string
+		getter   func(mc kubernetes.Metrics) (*app.TimedNumberTuple, error)
+		query    string
+	}{
+		{
+			testName: "CPU",
+			getter: func(mc kubernetes.Metrics) (*app.TimedNumberTuple, error) {
+				return mc.GetCPUMetrics(prometheusTestPods, "my-run", startTime)
+			},
+			query: `sum(rate(container_cpu_usage_seconds_total{` + prometheusTestSelector +
+				`,container_name!="POD",container_name!=""}[1m]))`,
+		},
+		{
+			testName: "Memory",
+			getter: func(mc kubernetes.Metrics) (*app.TimedNumberTuple, error) {
+				return mc.GetMemoryMetrics(prometheusTestPods, "my-run", startTime)
+			},
+			query: `sum(avg_over_time(container_memory_usage_bytes{` + prometheusTestSelector +
+				`,container_name!="POD",container_name!=""}[1m]))`,
+		},
+		{
+			testName: "Network Sent",
+			getter: func(mc kubernetes.Metrics) (*app.TimedNumberTuple, error) {
+				return mc.GetNetworkSentMetrics(prometheusTestPods, "my-run", startTime)
+			},
+			query: `sum(rate(container_network_transmit_bytes_total{` + prometheusTestSelector +
+				`,container_name="POD"}[1m]))`,
+		},
+		{
+			testName: "Network Received",
+			getter: func(mc kubernetes.Metrics) (*app.TimedNumberTuple, error) {
+				return mc.GetNetworkRecvMetrics(prometheusTestPods, "my-run", startTime)
+			},
+			query: `sum(rate(container_network_receive_bytes_total{` + prometheusTestSelector +
+				`,container_name="POD"}[1m]))`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			p := newTestPrometheus(http.StatusOK, `{"status":"success","data":{"resultType":"vector",
+				"result":[{"metric":{},"value":[1516301878,"0.25"]}]}}`)
+			defer p.Close()
+			mc := newTestPrometheusClient(t, p)
+			defer mc.Close()
+
+			tuple, err := testCase.getter(mc)
+			require.NoError(t, err)
+			require.NotNil(t, tuple)
+			assert.Equal(t, 0.25, *tuple.Value)
+			// the time of the point is the start of the bucket
+			assert.Equal(t, float64(1516301818000), *tuple.Time)
+
+			assert.Equal(t, "/api/v1/query", p.path)
+			assert.Equal(t, testCase.query, p.params.Get("query"))
+			assert.Equal(t, "1516301878", p.params.Get("time"))
+			assert.Equal(t, "Bearer myToken", p.headers.Get("Authorization"))
+		})
+	}
+
+	t.Run("No Data", func(t *testing.T) {
+		p := newTestPrometheus(http.StatusOK, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+		defer p.Close()
+		mc := newTestPrometheusClient(t, p)
+
+		tuple, err := mc.GetCPUMetrics(prometheusTestPods, "my-run", startTime)
+		require.NoError(t, err)
+		assert.Nil(t, tuple)
+	})
+
+	t.Run("No Pods", func(t *testing.T) {
+		p := newTestPrometheus(http.StatusOK, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+		defer p.Close()
+		mc := newTestPrometheusClient(t, p)
+
+		tuple, err := mc.GetCPUMetrics([]*v1.Pod{}, "my-run", startTime)
+		require.NoError(t, err)
+		assert.Nil(t, tuple)
+		assert.Empty(t, p.path, "Prometheus must not be queried")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		p := newTestPrometheus(http.StatusBadRequest, `{"status":"error","errorType":"bad_data",
+			"error":"parse error at char 12: unexpected character"}`)
+		defer p.Close()
+		mc := newTestPrometheusClient(t, p)
+
+		_, err := mc.GetCPUMetrics(prometheusTestPods, "my-run", startTime)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parse error at char 12")
+	})
+}
+
+func TestPrometheusMetricsRange(t *testing.T) {
+	startTime := time.Unix(1516301818, 0)
+	endTime := startTime.Add(4 * time.Minute)
+	matrix := `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[
+		[1516301878,"1024"],[1516301938,"2048"],[1516301998,"NaN"],[1516302058,"4096"]]}]}}`
+
+	testCases := []struct {
+		testName string
+		limit    int
+		expected []float64
+	}{
+		{
+			testName: "No Limit",
+			limit:    -1,
+			// the points without a value are left out
+			expected: []float64{1024, 2048, 4096},
+		},
+		{
+			testName: "Limit",
+			limit:    2,
+			expected: []float64{2048, 4096},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			p := newTestPrometheus(http.StatusOK, matrix)
+			defer p.Close()
+			mc := newTestPrometheusClient(t, p)
+
+			tuples, err := mc.GetMemoryMetricsRange(prometheusTestPods, "my-run", startTime, endTime, testCase.limit)
+			require.NoError(t, err)
+			values := make([]float64, len(tuples))
+			for idx, tuple := range tuples {
+				values[idx] = *tuple.Value
+			}
+			assert.Equal(t, testCase.expected, values)
+			assert.Equal(t, float64(1516302058000-60000), *tuples[len(tuples)-1].Time)
+
+			assert.Equal(t, "/api/v1/query_range", p.path)
+			assert.Equal(t, "1516301878", p.params.Get("start"))
+			assert.Equal(t, "1516302058", p.params.Get("end"))
+			assert.Equal(t, "60", p.params.Get("step"))
+		})
+	}
+
+	t.Run("Shorter Than A Bucket", func(t *testing.T) {
+		p := newTestPrometheus(http.StatusOK, matrix)
+		defer p.Close()
+		mc := newTestPrometheusClient(t, p)
+
+		tuples, err := mc.GetCPUMetricsRange(prometheusTestPods, "my-run", startTime, startTime.Add(time.Second), -1)
+		require.NoError(t, err)
+		assert.Empty(t, tuples)
+		assert.Empty(t, p.path, "Prometheus must not be queried")
+	})
+}
@@ -10,8 +10,8 @@ import (
 	"github.com/pkg/errors"
 )
 
-// extractIdentityID obtains the identity ID out of the authentication context
-func extractIdentityID(ctx context.Context) (string, error) {
+// ExtractIdentityID obtains the identity ID out of the authentication context
+func ExtractIdentityID(ctx context.Context) (string, error) {
 	token := goajwt.ContextJWT(ctx)
 	if token == nil {
 		return "", errors.New("Missing token")
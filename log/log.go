@@ -121,7 +121,7 @@ func Error(ctx context.Context, fields map[string]interface{}, format string, ar
 
 		if ctx != nil {
 			entry = entry.WithField("req_id", ExtractRequestID(ctx))
-			identityID, err := extractIdentityID(ctx)
+			identityID, err := ExtractIdentityID(ctx)
 			if err == nil {
 				entry = entry.WithField("identity_id", identityID)
 			}
@@ -182,7 +182,7 @@ func Warn(ctx context.Context, fields map[string]interface{}, format string, arg
 
 		if ctx != nil {
 			entry = entry.WithField("req_id", ExtractRequestID(ctx))
-			identityID, err := extractIdentityID(ctx)
+			identityID, err := ExtractIdentityID(ctx)
 			if err == nil { // Otherwise we don't use the identityID
 				entry = entry.WithField("identity_id", identityID)
 			}
@@ -211,7 +211,7 @@ func Info(ctx context.Context, fields map[string]interface{}, format string, arg
 
 		if ctx != nil {
 			entry = entry.WithField("req_id", ExtractRequestID(ctx))
-			identityID, err := extractIdentityID(ctx)
+			identityID, err := ExtractIdentityID(ctx)
 			if err == nil { // Otherwise we don't use the identityID
 				entry = entry.WithField("identity_id", identityID)
 			}
@@ -236,7 +236,7 @@ func Panic(ctx context.Context, fields map[string]interface{}, format string, ar
 
 		if ctx != nil {
 			entry = entry.WithField("req_id", ExtractRequestID(ctx))
-			identityID, err := extractIdentityID(ctx)
+			identityID, err := ExtractIdentityID(ctx)
 			if err == nil { // Otherwise we don't use the identityID
 				entry = entry.WithField("identity_id", identityID)
 			}
@@ -265,7 +265,7 @@ func Debug(ctx context.Context, fields map[string]interface{}, format string, ar
 
 		if ctx != nil {
 			entry = entry.WithField("req_id", ExtractRequestID(ctx))
-			identityID, err := extractIdentityID(ctx)
+			identityID, err := ExtractIdentityID(ctx)
 			if err == nil {
 				entry = entry.WithField("identity_id", identityID)
 			}
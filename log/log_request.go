@@ -44,7 +44,7 @@ func LogRequest(verbose bool) goa.Middleware {
 				"action": goa.ContextAction(ctx),
 			}
 
-			identityID, err := extractIdentityID(ctx)
+			identityID, err := ExtractIdentityID(ctx)
 			if err == nil {
 				reqStartedProperties["identity_id"] = identityID
 			}
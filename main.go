@@ -15,21 +15,30 @@ import (
 	"github.com/fabric8-services/fabric8-wit/app"
 	"github.com/fabric8-services/fabric8-wit/application"
 	"github.com/fabric8-services/fabric8-wit/auth"
+	"github.com/fabric8-services/fabric8-wit/codebase/che"
+	"github.com/fabric8-services/fabric8-wit/codebase/githost"
+	"github.com/fabric8-services/fabric8-wit/codebase/workspace"
 	"github.com/fabric8-services/fabric8-wit/configuration"
 	"github.com/fabric8-services/fabric8-wit/controller"
 	witmiddleware "github.com/fabric8-services/fabric8-wit/goamiddleware"
 	"github.com/fabric8-services/fabric8-wit/gormapplication"
 	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/kubernetes"
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/metric"
 	"github.com/fabric8-services/fabric8-wit/migration"
 	"github.com/fabric8-services/fabric8-wit/models"
 	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/ratelimit"
 	"github.com/fabric8-services/fabric8-wit/remoteworkitem"
+	"github.com/fabric8-services/fabric8-wit/retention"
 	"github.com/fabric8-services/fabric8-wit/sentry"
 	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/space/event"
 	"github.com/fabric8-services/fabric8-wit/token"
+	"github.com/fabric8-services/fabric8-wit/token/accesstoken"
+	"github.com/fabric8-services/fabric8-wit/trash"
 	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/logging/logrus"
 	"github.com/goadesign/goa/middleware"
@@ -127,6 +136,11 @@ func main() {
 	// Set the database transaction timeout
 	application.SetDatabaseTransactionTimeout(config.GetPostgresTransactionTimeout())
 
+	// Report the pending migrations instead of applying them
+	if flag.Arg(0) == "migrate" {
+		os.Exit(migrateCommand(db.DB(), config.GetPostgresDatabase(), flag.Args()[1:], os.Stdout))
+	}
+
 	// Migrate the schema
 	err = migration.Migrate(db.DB(), config.GetPostgresDatabase())
 	if err != nil {
@@ -179,8 +193,21 @@ func main() {
 				"url": config.GetNotificationServiceURL(),
 			}, "failed to parse notification service url")
 		}
-		notificationChannel = channel
+		notificationChannel = notification.NewResolvingChannel(notification.NewWatcherResolver(db), channel)
+	}
+	// The space events broker streams all the messages to the subscribers of
+	// their space while the notification service only gets the known ones
+	spaceEventsBroker := event.NewBroker(db, config.GetEventsPollInterval(), config.GetEventsRetention())
+	if err := spaceEventsBroker.Start(); err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"err": err,
+		}, "failed to start the space events broker")
 	}
+	defer spaceEventsBroker.Stop()
+	notificationChannel = notification.NewMultiChannel(
+		spaceEventsBroker,
+		notification.NewFilteringChannel(notificationChannel, notification.MessageTypes...),
+	)
 
 	appDB := gormapplication.NewGormDB(db)
 
@@ -190,13 +217,16 @@ func main() {
 			"err": err,
 		}, "failed to create token manager")
 	}
-	// Middleware that extracts and stores the token in the context
-	jwtMiddlewareTokenContext := witmiddleware.TokenContext(tokenManager.PublicKeys(), nil, app.NewJWTSecurity())
+	// Middleware that extracts and stores the token in the context, be it a JWT
+	// or a personal access token
+	jwtMiddlewareTokenContext := witmiddleware.TokenContext(tokenManager.PublicKeys(), nil, app.NewJWTSecurity(), accesstoken.TokenResolver(db))
 	service.Use(jwtMiddlewareTokenContext)
+	// Middleware that limits the rate of the requests of each identity or client IP
+	service.Use(ratelimit.Middleware(config))
 
 	service.Use(login.InjectTokenManager(tokenManager))
 	service.Use(log.LogRequest(config.IsPostgresDeveloperModeEnabled()))
-	app.UseJWTMiddleware(service, goajwt.New(tokenManager.PublicKeys(), nil, app.NewJWTSecurity()))
+	app.UseJWTMiddleware(service, accesstoken.JWTMiddleware(goajwt.New(tokenManager.PublicKeys(), nil, app.NewJWTSecurity())))
 
 	spaceAuthzService := authz.NewAuthzService(config)
 	service.Use(authz.InjectAuthzService(spaceAuthzService))
@@ -249,7 +279,7 @@ func main() {
 	app.MountWorkItemLinkTypesController(service, workItemLinkTypesCtrl)
 
 	// Mount "work item link" controller
-	workItemLinkCtrl := controller.NewWorkItemLinkController(service, appDB, config)
+	workItemLinkCtrl := controller.NewNotifyingWorkItemLinkController(service, appDB, notificationChannel, config)
 	app.MountWorkItemLinkController(service, workItemLinkCtrl)
 
 	// Mount "work item comments" controller
@@ -270,6 +300,112 @@ func main() {
 	workItemLabelCtrl := controller.NewWorkItemLabelsController(service, appDB, config)
 	app.MountWorkItemLabelsController(service, workItemLabelCtrl)
 
+	// Mount "work item watchers" controller
+	workItemWatchersCtrl := controller.NewWorkItemWatchersController(service, appDB, config)
+	app.MountWorkItemWatchersController(service, workItemWatchersCtrl)
+
+	// Mount "space deployments" and "work item deployments" controllers
+	spaceDeploymentsCtrl := controller.NewSpaceDeploymentsController(service, appDB, config)
+	app.MountSpaceDeploymentsController(service, spaceDeploymentsCtrl)
+	workItemDeploymentsCtrl := controller.NewWorkItemDeploymentsController(service, appDB, config)
+	app.MountWorkItemDeploymentsController(service, workItemDeploymentsCtrl)
+
+	// Mount "codebase webhooks" and "work item code references" controllers
+	codebaseWebhooksCtrl := controller.NewCodebaseWebhooksController(service, appDB, config)
+	app.MountCodebaseWebhooksController(service, codebaseWebhooksCtrl)
+	workItemCodeReferencesCtrl := controller.NewWorkItemCodeReferencesController(service, appDB, config)
+	app.MountWorkItemCodeReferencesController(service, workItemCodeReferencesCtrl)
+
+	// Mount "notification preferences" controller
+	notificationPreferencesCtrl := controller.NewNotificationPreferencesController(service, appDB)
+	app.MountNotificationPreferencesController(service, notificationPreferencesCtrl)
+
+	// Mount "personal access tokens" controller
+	personalAccessTokensCtrl := controller.NewPersonalAccessTokensController(service, appDB)
+	app.MountPersonalAccessTokensController(service, personalAccessTokensCtrl)
+
+	// Mount "work item clone" controller
+	workItemCloneCtrl := controller.NewWorkItemCloneController(service, appDB)
+	app.MountWorkItemCloneController(service, workItemCloneCtrl)
+
+	// Mount "work item move" controller
+	workItemMoveCtrl := controller.NewNotifyingWorkItemMoveController(service, appDB, notificationChannel)
+	app.MountWorkItemMoveController(service, workItemMoveCtrl)
+
+	// Mount "work item import" controller
+	workItemImportCtrl := controller.NewWorkItemImportController(service, appDB)
+	app.MountWorkItemImportController(service, workItemImportCtrl)
+
+	// Mount "graphql" controller
+	graphQLExecutor, err := controller.NewGraphQLExecutor(appDB, config)
+	if err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"err": err,
+		}, "failed to create the GraphQL executor")
+	}
+	graphQLCtrl := controller.NewGraphQLController(service, graphQLExecutor)
+	app.MountGraphqlController(service, graphQLCtrl)
+
+	// Mount "work item templates" controller
+	workItemTemplatesCtrl := controller.NewWorkItemTemplatesController(service, appDB, config)
+	app.MountWorkItemTemplatesController(service, workItemTemplatesCtrl)
+
+	// Mount "trash" controller
+	trashCtrl := controller.NewTrashController(service, appDB, config)
+	app.MountTrashController(service, trashCtrl)
+
+	// Mount "space events" controller
+	spaceEventsCtrl := controller.NewSpaceEventsController(service, appDB, spaceEventsBroker)
+	app.MountSpaceEventsController(service, spaceEventsCtrl)
+
+	// Mount "work item schedule" controller
+	workItemScheduleCtrl := controller.NewWorkItemScheduleController(service, appDB)
+	app.MountWorkItemScheduleController(service, workItemScheduleCtrl)
+
+	// Purge the work items and comments that have been in the trash for too long
+	trashPurger := trash.NewPurger(db, config.GetTrashRetention())
+	if err := trashPurger.Start(config.GetTrashPurgeSchedule()); err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"err": err,
+		}, "failed to start the trash purger")
+	}
+	defer trashPurger.Stop()
+
+	// Fetch the metadata of the codebases from their git hosts
+	metadataFetcher := githost.NewFetcher(db, map[string]githost.Client{
+		githost.GitHubHost: githost.NewGitHubClient(config.GetCodebaseMetadataGitHubURL(), config.GetGithubAuthToken(), config.GetCodebaseMetadataTimeout()),
+	}, config.GetCodebaseMetadataMaxAge(), config.GetCodebaseMetadataBatchSize())
+	if err := metadataFetcher.Start(config.GetCodebaseMetadataSchedule()); err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"err": err,
+		}, "failed to start the codebase metadata fetcher")
+	}
+	defer metadataFetcher.Stop()
+
+	// Stop the expired Che workspaces and remove the workspaces of the deleted
+	// codebases, on behalf of the che service account
+	if token := config.GetCheServiceAccountToken(); token != "" {
+		workspaceReconciler := workspace.NewReconciler(db, func(namespace string) che.Client {
+			return che.NewStarterServiceClient(config.GetCheStarterURL(), config.GetOpenshiftTenantMasterURL(), namespace, token, http.DefaultClient)
+		})
+		if err := workspaceReconciler.Start(config.GetCodebaseWorkspacesReconcileSchedule()); err != nil {
+			log.Panic(nil, map[string]interface{}{
+				"err": err,
+			}, "failed to start the workspace reconciler")
+		}
+		defer workspaceReconciler.Stop()
+	}
+
+	// Archive the old closed work items and compact the old revisions
+	// according to the retention policies of the spaces
+	retentionEnforcer := retention.NewEnforcer(db, config.GetRetentionBatchSize())
+	if err := retentionEnforcer.Start(config.GetRetentionSchedule()); err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"err": err,
+		}, "failed to start the retention enforcer")
+	}
+	defer retentionEnforcer.Stop()
+
 	// Mount "work item events relationships" controller
 	workItemEventsCtrl := controller.NewEventsController(service, appDB, config)
 	app.MountWorkItemEventsController(service, workItemEventsCtrl)
@@ -311,6 +447,14 @@ func main() {
 
 	// Mount "deployments" controller
 	deploymentsCtrl := controller.NewDeploymentsController(service, config)
+	if interval, token := config.GetDeploymentsHealthInterval(), config.GetDeploymentsHealthServiceAccountToken(); interval > 0 && token != "" {
+		// Notify when the deployments of the environments whose health is
+		// requested become unhealthy, on behalf of the service account
+		healthWatcher := kubernetes.NewHealthWatcher(notificationChannel, token, interval, config.GetDeploymentsHealthExpiry())
+		healthWatcher.Start()
+		defer healthWatcher.Stop()
+		deploymentsCtrl.HealthWatcher = healthWatcher
+	}
 	app.MountDeploymentsController(service, deploymentsCtrl)
 
 	// Mount "search" controller
@@ -351,6 +495,26 @@ func main() {
 	filterCtrl := controller.NewFilterController(service, config)
 	app.MountFilterController(service, filterCtrl)
 
+	// Mount "space filters" controller
+	spaceFiltersCtrl := controller.NewSpaceFiltersController(service, appDB, config)
+	app.MountSpaceFiltersController(service, spaceFiltersCtrl)
+
+	// Mount "space suggestions" controller
+	spaceSuggestionsCtrl := controller.NewSpaceSuggestionsController(service, appDB)
+	app.MountSpaceSuggestionsController(service, spaceSuggestionsCtrl)
+
+	// Mount "space_roles" controller
+	spaceRolesCtrl := controller.NewSpaceRolesController(service, appDB)
+	app.MountSpaceRolesController(service, spaceRolesCtrl)
+
+	// Mount "space_workspace_policy" controller
+	spaceWorkspacePolicyCtrl := controller.NewSpaceWorkspacePolicyController(service, appDB)
+	app.MountSpaceWorkspacePolicyController(service, spaceWorkspacePolicyCtrl)
+
+	// Mount "space retention policy" controller
+	spaceRetentionPolicyCtrl := controller.NewSpaceRetentionPolicyController(service, appDB)
+	app.MountSpaceRetentionPolicyController(service, spaceRetentionPolicyCtrl)
+
 	// Mount "namedspaces" controller
 	namedSpacesCtrl := controller.NewNamedspacesController(service, appDB)
 	app.MountNamedspacesController(service, namedSpacesCtrl)
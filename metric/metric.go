@@ -45,6 +45,13 @@ var (
 		Help:      "Bucketed histogram of the HTTP request sizes in bytes.",
 		Buckets:   []float64{1000, 5000, 10000, 20000, 30000, 40000, 50000},
 	}, reqLabels)
+
+	rateLimitCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "rate_limited_requests_total",
+		Help:      "Counter of requests going through the rate limiter, per route group and outcome (allowed or limited).",
+	}, []string{"group", "outcome"})
 )
 
 func registerMetrics() {
@@ -52,6 +59,7 @@ func registerMetrics() {
 	reqDuration = register(reqDuration, "request_duration_seconds").(*prometheus.HistogramVec)
 	resSize = register(resSize, "response_size_bytes").(*prometheus.HistogramVec)
 	reqSize = register(reqSize, "request_size_bytes").(*prometheus.HistogramVec)
+	rateLimitCnt = register(rateLimitCnt, "rate_limited_requests_total").(*prometheus.CounterVec)
 	log.Info(nil, nil, "metrics registered successfully")
 }
 
@@ -95,3 +103,16 @@ func reportRequestSize(method, entity, code string, size int64) {
 		reqSize.WithLabelValues(method, entity, code).Observe(float64(size))
 	}
 }
+
+// ReportRateLimit records that a request of the given route group went through
+// the rate limiter, and whether it was limited
+func ReportRateLimit(group string, limited bool) {
+	if group == "" {
+		return
+	}
+	outcome := "allowed"
+	if limited {
+		outcome = "limited"
+	}
+	rateLimitCnt.WithLabelValues(group, outcome).Inc()
+}
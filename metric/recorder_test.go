@@ -212,6 +212,21 @@ func createCtx(ctrl *goa.Controller, reqMethod string, resCode int) context.Cont
 	return ctx
 }
 
+func TestRateLimitMetric(t *testing.T) {
+	ReportRateLimit("search", false)
+	ReportRateLimit("search", true)
+	ReportRateLimit("search", false)
+	ReportRateLimit("", true)
+
+	for outcome, expected := range map[string]float64{"allowed": 2, "limited": 1} {
+		c, err := rateLimitCnt.GetMetricWithLabelValues("search", outcome)
+		assert.NoError(t, err)
+		m := &dto.Metric{}
+		c.Write(m)
+		assert.Equal(t, expected, m.Counter.GetValue(), outcome)
+	}
+}
+
 func checkCounter(t *testing.T, method, entity, code string, expected int64) {
 	reqMetric, _ := reqCnt.GetMetricWithLabelValues(method, entity, code)
 	m := &dto.Metric{}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/migration"
+)
+
+const migrateUsage = `Usage: wit [-config file] migrate [-dry-run] [-lock-timeout duration]
+
+Prints the current version of the database and the pending migrations along
+with their SQL files, without applying them (see -migrateDatabase).
+
+With -dry-run, the pending migrations are executed in a single transaction
+that is rolled back, and the duration and the tables locked by each step are
+reported. The locks are held until the end of the dry run, so it should run
+against a copy of the production database or during a maintenance window.
+`
+
+// migrateCommand runs the "migrate" subcommand with the given arguments and
+// returns the exit code of the binary
+func migrateCommand(db *sql.DB, catalog string, args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() {
+		fmt.Fprint(out, migrateUsage)
+		fs.PrintDefaults()
+	}
+	dryRun := fs.Bool("dry-run", false, "Executes the pending migrations in a transaction that is rolled back")
+	lockTimeout := fs.Duration("lock-timeout", 5*time.Second, "Maximum time a step of the dry run waits for a lock held by another session, 0 meaning forever")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	status, err := migration.GetStatus(db, catalog)
+	if err != nil {
+		fmt.Fprintf(out, "failed to get the status of the database: %v\n", err)
+		return 1
+	}
+	printStatus(out, *status)
+	if !*dryRun || len(status.Pending) == 0 {
+		return 0
+	}
+
+	report, err := migration.DryRun(db, catalog, *lockTimeout)
+	if report != nil {
+		printDryRun(out, *report)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "dry run failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printStatus prints the current version and the pending migrations
+func printStatus(out io.Writer, status migration.Status) {
+	fmt.Fprintf(out, "Current version: %d\n", status.CurrentVersion)
+	if len(status.Pending) == 0 {
+		fmt.Fprintln(out, "The database is up to date.")
+		return
+	}
+	fmt.Fprintf(out, "Pending migrations: %d\n", len(status.Pending))
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTEP\tFILE")
+	for _, p := range status.Pending {
+		for j, file := range p.Files {
+			fmt.Fprintf(w, "%d\t%d\t%s\n", p.Version, j, stepName(file))
+		}
+	}
+	w.Flush()
+}
+
+// printDryRun prints the duration and locks of each step of the dry run
+func printDryRun(out io.Writer, report migration.DryRunReport) {
+	fmt.Fprintln(out, "Dry run:")
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTEP\tFILE\tDURATION\tLOCKED TABLES\tRESULT")
+	for _, s := range report.Steps {
+		result := "ok"
+		switch {
+		case s.LockContention:
+			result = "lock contention: " + s.Err.Error()
+		case s.Err != nil:
+			result = "failed: " + s.Err.Error()
+		}
+		// errors may span several lines
+		result = strings.Replace(result, "\n", " ", -1)
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\n", s.Version, s.Step, stepName(s.File), s.Duration, strings.Join(s.Locks, ","), result)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "The dry run of %d steps took %s, all the changes were rolled back.\n", len(report.Steps), report.Duration)
+}
+
+// stepName returns the name of a step executing the given SQL file
+func stepName(file string) string {
+	if file == "" {
+		return "(Go code)"
+	}
+	return file
+}
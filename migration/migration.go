@@ -32,8 +32,16 @@ const AdvisoryLockID = 42
 // fn defines the type of function that can be part of a migration steps
 type fn func(tx *sql.Tx) error
 
+// step is a function that is part of a migration, along with the name of the
+// SQL file it executes, if any, so that the pending migrations can be
+// reported before they are applied
+type step struct {
+	fn
+	file string
+}
+
 // steps defines a collection of all the functions that make up a version
-type steps []fn
+type steps []step
 
 // Migrations defines all a collection of all the steps
 type Migrations []steps
@@ -416,6 +424,57 @@ func GetMigrations() Migrations {
 	// Version 92
 	m = append(m, steps{ExecuteSQLFile("092-comment-revisions-child-comments.sql")})
 
+	// Version 93
+	m = append(m, steps{ExecuteSQLFile("093-work-item-watchers.sql")})
+
+	// Version 94
+	m = append(m, steps{ExecuteSQLFile("094-work-item-templates.sql")})
+
+	// Version 95
+	m = append(m, steps{ExecuteSQLFile("095-work-item-number-redirects.sql")})
+
+	// Version 96
+	m = append(m, steps{ExecuteSQLFile("096-trash.sql")})
+
+	// Version 97
+	m = append(m, steps{ExecuteSQLFile("097-search-configuration.sql")})
+
+	// Version 98
+	m = append(m, steps{ExecuteSQLFile("098-suggestions.sql")})
+
+	// Version 99
+	m = append(m, steps{ExecuteSQLFile("099-space-roles.sql")})
+
+	// Version 100
+	m = append(m, steps{ExecuteSQLFile("100-personal-access-tokens.sql")})
+
+	// Version 101
+	m = append(m, steps{ExecuteSQLFile("101-space-events.sql")})
+
+	// Version 102
+	m = append(m, steps{ExecuteSQLFile("102-deployments.sql")})
+
+	// Version 103
+	m = append(m, steps{ExecuteSQLFile("103-work-item-code-references.sql")})
+
+	// Version 104
+	m = append(m, steps{ExecuteSQLFile("104-codebase-metadata.sql")})
+
+	// Version 105
+	m = append(m, steps{ExecuteSQLFile("105-codebase-workspaces.sql")})
+
+	// Version 106
+	m = append(m, steps{ExecuteSQLFile("106-retention.sql")})
+
+	// Version 107
+	m = append(m, steps{ExecuteSQLFile("107-codebase-webhook-secret.sql")})
+
+	// Version 108
+	m = append(m, steps{ExecuteSQLFile("108-codebase-workspace-max-runtime.sql")})
+
+	// Version 109
+	m = append(m, steps{ExecuteSQLFile("109-codebase-workspace-reservations.sql")})
+
 	// Version N
 	//
 	// In order to add an upgrade, simply append an array of MigrationFunc to the
@@ -428,15 +487,15 @@ func GetMigrations() Migrations {
 
 	/*
 		m = append(m, steps{
-			func(db *sql.Tx) error {
+			{fn: func(db *sql.Tx) error {
 				// Execute random go code
 				return nil
-			},
+			}},
 			ExecuteSQLFile("YOUR_OWN_FILE.sql"),
-			func(db *sql.Tx) error {
+			{fn: func(db *sql.Tx) error {
 				// Execute random go code
 				return nil
-			},
+			}},
 		})
 	*/
 
@@ -446,8 +505,8 @@ func GetMigrations() Migrations {
 // ExecuteSQLFile loads the given filename from the packaged SQL files and
 // executes it on the given database. Golang text/template module is used
 // to handle all the optional arguments passed to the sql files
-func ExecuteSQLFile(filename string, args ...string) fn {
-	return func(db *sql.Tx) error {
+func ExecuteSQLFile(filename string, args ...string) step {
+	return step{file: filename, fn: func(db *sql.Tx) error {
 		data, err := Asset(filename)
 		if err != nil {
 			return errs.Wrapf(err, "failed to find filename: %s", filename)
@@ -478,7 +537,7 @@ func ExecuteSQLFile(filename string, args ...string) fn {
 		}
 
 		return errs.WithStack(err)
-	}
+	}}
 }
 
 // MigrateToNextVersion migrates the database to the nextVersion.
@@ -515,7 +574,7 @@ func MigrateToNextVersion(tx *sql.Tx, nextVersion *int64, m Migrations, catalog
 
 	// Apply all the updates of the next version
 	for j := range m[*nextVersion] {
-		if err := m[*nextVersion][j](tx); err != nil {
+		if err := m[*nextVersion][j].fn(tx); err != nil {
 			return errs.Errorf("Failed to execute migration of step %d of version %d: %s\n", j, *nextVersion, err)
 		}
 	}
@@ -97,6 +97,9 @@ func TestMigrations(t *testing.T) {
 	dialect = gormDB.Dialect()
 	dialect.SetDB(sqlDB)
 
+	// The pending migrations of the new database are only reported
+	t.Run("TestDryRun", testDryRun)
+
 	// We migrate the new database until initialMigratedVersion
 	t.Run("TestMigration44", testMigration44)
 
@@ -141,10 +144,74 @@ func TestMigrations(t *testing.T) {
 	t.Run("TestMigration90", testMigration90QueriesVersion)
 	t.Run("TestMigration91", testMigration91CommentsChildComments)
 	t.Run("TestMigration92", testMigration92CommentRevisionsChildComments)
+	t.Run("TestMigration93", testMigration93WorkItemWatchers)
+	t.Run("TestMigration94", testMigration94WorkItemTemplates)
+	t.Run("TestMigration95", testMigration95WorkItemNumberRedirects)
+	t.Run("TestMigration96", testMigration96Trash)
+	t.Run("TestMigration97", testMigration97SearchConfiguration)
+	t.Run("TestMigration98", testMigration98Suggestions)
+	t.Run("TestMigration99", testMigration99SpaceRoles)
+	t.Run("TestMigration100", testMigration100PersonalAccessTokens)
+	t.Run("TestMigration101", testMigration101SpaceEvents)
+	t.Run("TestMigration102", testMigration102Deployments)
+	t.Run("TestMigration103", testMigration103WorkItemCodeReferences)
+	t.Run("TestMigration104", testMigration104CodebaseMetadata)
+	t.Run("TestMigration105", testMigration105CodebaseWorkspaces)
+	t.Run("TestMigration106", testMigration106Retention)
+	t.Run("TestMigration107", testMigration107CodebaseWebhookSecret)
+	t.Run("TestMigration108", testMigration108CodebaseWorkspaceMaxRuntime)
+	t.Run("TestMigration109", testMigration109CodebaseWorkspaceReservations)
 
 	// Perform the migration
 	err = migration.Migrate(sqlDB, databaseName)
 	require.NoError(t, err, "failed to execute database migration")
+
+	t.Run("TestStatusUpToDate", testStatusUpToDate)
+}
+
+func testDryRun(t *testing.T) {
+	status, err := migration.GetStatus(sqlDB, databaseName)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), status.CurrentVersion)
+	require.Len(t, status.Pending, len(migrations))
+	assert.Equal(t, int64(0), status.Pending[0].Version)
+	assert.Equal(t, []string{"000-bootstrap.sql"}, status.Pending[0].Files)
+
+	report, err := migration.DryRun(sqlDB, databaseName, 5*time.Second)
+	require.NoError(t, err)
+	require.NotEmpty(t, report.Steps)
+	locks := map[int64][]string{}
+	for _, s := range report.Steps {
+		assert.NoError(t, s.Err)
+		assert.False(t, s.LockContention)
+		locks[s.Version] = append(locks[s.Version], s.Locks...)
+	}
+	assert.Equal(t, int64(len(migrations)-1), report.Steps[len(report.Steps)-1].Version)
+	assert.Contains(t, locks[105], "codebase_workspaces")
+	// everything was rolled back
+	assert.False(t, gormDB.HasTable("version"))
+	assert.False(t, gormDB.HasTable("codebase_workspaces"))
+
+	t.Run("migration in progress", func(t *testing.T) {
+		tx, err := sqlDB.Begin()
+		require.NoError(t, err)
+		defer tx.Rollback()
+		_, err = tx.Exec("SELECT pg_advisory_xact_lock($1)", migration.AdvisoryLockID)
+		require.NoError(t, err)
+		_, err = migration.DryRun(sqlDB, databaseName, time.Second)
+		require.Error(t, err)
+	})
+}
+
+func testStatusUpToDate(t *testing.T) {
+	status, err := migration.GetStatus(sqlDB, databaseName)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(migrations)-1), status.CurrentVersion)
+	assert.Empty(t, status.Pending)
+
+	report, err := migration.DryRun(sqlDB, databaseName, time.Second)
+	require.NoError(t, err)
+	assert.Empty(t, report.Steps)
 }
 
 func testMigration44(t *testing.T) {
@@ -816,6 +883,124 @@ func testMigration92CommentRevisionsChildComments(t *testing.T) {
 	assert.True(t, dialect.HasColumn("comment_revisions", "comment_parent_comment_id"))
 }
 
+func testMigration93WorkItemWatchers(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:94], 94)
+	assert.True(t, gormDB.HasTable("work_item_watchers"))
+	assert.True(t, gormDB.HasTable("notification_preferences"))
+	assert.True(t, dialect.HasIndex("work_item_watchers", "work_item_watchers_identity_id_idx"))
+}
+
+func testMigration94WorkItemTemplates(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:95], 95)
+	assert.True(t, gormDB.HasTable("work_item_templates"))
+	assert.True(t, dialect.HasColumn("work_item_templates", "root"))
+	assert.True(t, dialect.HasIndex("work_item_templates", "work_item_templates_name_space_id_unique_idx"))
+}
+
+func testMigration95WorkItemNumberRedirects(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:96], 96)
+	assert.True(t, gormDB.HasTable("work_item_number_redirects"))
+	assert.True(t, dialect.HasIndex("work_item_number_redirects", "work_item_number_redirects_work_item_id_idx"))
+}
+
+func testMigration96Trash(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:97], 97)
+	assert.True(t, dialect.HasColumn("work_item_links", "deleted_with_work_item_id"))
+	assert.True(t, dialect.HasIndex("work_item_links", "work_item_links_deleted_with_work_item_id_idx"))
+	assert.True(t, dialect.HasIndex("work_items", "work_items_deleted_at_idx"))
+	assert.True(t, dialect.HasIndex("comments", "comments_deleted_at_idx"))
+}
+
+func testMigration97SearchConfiguration(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:98], 98)
+	assert.True(t, dialect.HasColumn("spaces", "search_config"))
+	assert.True(t, dialect.HasIndex("comments", "comments_parent_id_idx"))
+}
+
+func testMigration98Suggestions(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:99], 99)
+	assert.True(t, dialect.HasIndex("labels", "labels_space_id_lower_name_idx"))
+	assert.True(t, dialect.HasIndex("iterations", "iterations_space_id_lower_name_idx"))
+	assert.True(t, dialect.HasIndex("areas", "areas_space_id_lower_name_idx"))
+	assert.True(t, dialect.HasIndex("identities", "identities_lower_username_pattern_idx"))
+	assert.True(t, dialect.HasIndex("users", "users_lower_full_name_pattern_idx"))
+}
+
+func testMigration99SpaceRoles(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:100], 100)
+	assert.True(t, gormDB.HasTable("space_roles"))
+	assert.True(t, dialect.HasIndex("space_roles", "space_roles_identity_id_idx"))
+}
+
+func testMigration100PersonalAccessTokens(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:101], 101)
+	assert.True(t, gormDB.HasTable("personal_access_tokens"))
+	assert.True(t, dialect.HasIndex("personal_access_tokens", "personal_access_tokens_token_hash_idx"))
+	assert.True(t, dialect.HasIndex("personal_access_tokens", "personal_access_tokens_identity_id_idx"))
+}
+
+func testMigration101SpaceEvents(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:102], 102)
+	assert.True(t, gormDB.HasTable("space_events"))
+	assert.True(t, dialect.HasIndex("space_events", "space_events_space_id_id_idx"))
+	assert.True(t, dialect.HasIndex("space_events", "space_events_created_at_idx"))
+}
+
+func testMigration102Deployments(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:103], 103)
+	assert.True(t, gormDB.HasTable("deployments"))
+	assert.True(t, gormDB.HasTable("work_item_deployments"))
+	assert.True(t, dialect.HasIndex("deployments", "deployments_space_id_environment_idx"))
+	assert.True(t, dialect.HasIndex("work_item_deployments", "work_item_deployments_deployment_id_idx"))
+}
+
+func testMigration103WorkItemCodeReferences(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:104], 104)
+	assert.True(t, gormDB.HasTable("work_item_code_references"))
+	assert.True(t, dialect.HasIndex("work_item_code_references", "work_item_code_references_unique_idx"))
+}
+
+func testMigration104CodebaseMetadata(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:105], 105)
+	assert.True(t, gormDB.HasTable("codebase_metadata"))
+	assert.True(t, dialect.HasIndex("codebase_metadata", "codebase_metadata_fetched_at_idx"))
+}
+
+func testMigration105CodebaseWorkspaces(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:106], 106)
+	assert.True(t, gormDB.HasTable("codebase_workspaces"))
+	assert.True(t, gormDB.HasTable("space_workspace_policies"))
+	assert.True(t, dialect.HasIndex("codebase_workspaces", "codebase_workspaces_name_idx"))
+}
+
+func testMigration106Retention(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:107], 107)
+	assert.True(t, gormDB.HasTable("space_retention_policies"))
+	assert.True(t, gormDB.HasTable("work_item_archive"))
+	assert.True(t, dialect.HasIndex("work_item_archive", "work_item_archive_space_id_idx"))
+	assert.True(t, dialect.HasIndex("work_item_revisions", "work_item_revisions_work_item_id_time_idx"))
+	assert.True(t, dialect.HasIndex("comment_revisions", "comment_revisions_comment_id_time_idx"))
+	assert.True(t, dialect.HasIndex("work_item_link_revisions", "work_item_link_revisions_work_item_link_id_time_idx"))
+}
+
+func testMigration107CodebaseWebhookSecret(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:108], 108)
+	assert.True(t, dialect.HasColumn("codebases", "webhook_secret"))
+}
+
+func testMigration108CodebaseWorkspaceMaxRuntime(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:109], 109)
+	assert.True(t, dialect.HasColumn("space_workspace_policies", "max_runtime_minutes"))
+	assert.False(t, dialect.HasColumn("space_workspace_policies", "idle_timeout_minutes"))
+	assert.True(t, dialect.HasColumn("codebase_workspaces", "started_at"))
+}
+
+func testMigration109CodebaseWorkspaceReservations(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:110], 110)
+	assert.True(t, gormDB.HasTable("codebase_workspace_reservations"))
+	assert.True(t, dialect.HasIndex("codebase_workspace_reservations", "codebase_workspace_reservations_space_id_idx"))
+}
+
 // runSQLscript loads the given filename from the packaged SQL test files and
 // executes it on the given database. Golang text/template module is used
 // to handle all the optional arguments passed to the sql test files
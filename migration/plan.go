@@ -0,0 +1,180 @@
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+
+	errs "github.com/pkg/errors"
+)
+
+// Status is the version of a database along with the migrations that are not
+// applied to it yet
+type Status struct {
+	// CurrentVersion is the highest version of the "version" table, or -1 if
+	// the database was never migrated
+	CurrentVersion int64
+	// Pending are the migrations to apply, in order
+	Pending []Pending
+}
+
+// Pending is a migration that is not applied yet
+type Pending struct {
+	Version int64
+	// Files are the SQL files executed by the steps of the migration, the
+	// steps running Go code having an empty file name
+	Files []string
+}
+
+// StepReport is the outcome of a migration step executed in a dry run
+type StepReport struct {
+	Version  int64
+	Step     int
+	File     string
+	Duration time.Duration
+	// Locks are the tables the step locked in ACCESS EXCLUSIVE mode, which
+	// blocks every read and write of the tables until the end of the
+	// migration, including the tables created by the step
+	Locks []string
+	// LockContention is true if the step failed because a lock held by
+	// another session was not released before the lock timeout
+	LockContention bool
+	Err            error
+}
+
+// DryRunReport is the outcome of the dry run of the pending migrations
+type DryRunReport struct {
+	Status
+	Steps    []StepReport
+	Duration time.Duration
+}
+
+// GetStatus returns the current version of the database and its pending
+// migrations
+func GetStatus(db *sql.DB, catalog string) (*Status, error) {
+	if db == nil {
+		return nil, errs.New("database handle is nil")
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to start transaction")
+	}
+	// nothing is ever written
+	defer tx.Rollback()
+	currentVersion, err := getCurrentVersion(tx, catalog)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return newStatus(currentVersion, GetMigrations()), nil
+}
+
+// newStatus returns the status of a database at the given version
+func newStatus(currentVersion int64, m Migrations) *Status {
+	s := &Status{CurrentVersion: currentVersion}
+	for v := currentVersion + 1; v < int64(len(m)); v++ {
+		p := Pending{Version: v, Files: make([]string, len(m[v]))}
+		for j, st := range m[v] {
+			p.Files[j] = st.file
+		}
+		s.Pending = append(s.Pending, p)
+	}
+	return s
+}
+
+// DryRun executes all the pending migrations in a single transaction that is
+// rolled back, and reports the duration and the locks of each step. A step
+// waiting longer than the given lock timeout for a lock held by another
+// session fails and ends the dry run, as does any other failing step; the
+// report then ends with the failed step and the error is returned as well. A
+// zero lock timeout waits forever.
+func DryRun(db *sql.DB, catalog string, lockTimeout time.Duration) (*DryRunReport, error) {
+	if db == nil {
+		return nil, errs.New("database handle is nil")
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to start transaction")
+	}
+	// nothing is ever committed
+	defer tx.Rollback()
+	var acquired bool
+	if err := tx.QueryRow("SELECT pg_try_advisory_xact_lock($1)", AdvisoryLockID).Scan(&acquired); err != nil {
+		return nil, errs.Wrap(err, "failed to acquire lock")
+	}
+	if !acquired {
+		return nil, errs.New("another migration of the database is in progress")
+	}
+	if lockTimeout > 0 {
+		if _, err := tx.Exec(fmt.Sprintf("SET LOCAL lock_timeout = %d", lockTimeout/time.Millisecond)); err != nil {
+			return nil, errs.Wrap(err, "failed to set the lock timeout")
+		}
+	}
+	currentVersion, err := getCurrentVersion(tx, catalog)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	m := GetMigrations()
+	report := &DryRunReport{Status: *newStatus(currentVersion, m)}
+	start := time.Now()
+	defer func() {
+		report.Duration = time.Since(start)
+	}()
+	locked := map[string]bool{}
+	for _, p := range report.Pending {
+		for j, st := range m[p.Version] {
+			r := StepReport{Version: p.Version, Step: j, File: st.file}
+			stepStart := time.Now()
+			r.Err = st.fn(tx)
+			r.Duration = time.Since(stepStart)
+			if r.Err != nil {
+				r.LockContention = gormsupport.IsLockNotAvailable(errs.Cause(r.Err))
+				report.Steps = append(report.Steps, r)
+				log.Info(nil, map[string]interface{}{
+					"version": p.Version,
+					"step":    j,
+					"file":    st.file,
+					"err":     r.Err,
+				}, "dry run of the migration failed")
+				return report, errs.Wrapf(r.Err, "failed to execute step %d of version %d", j, p.Version)
+			}
+			r.Locks, err = exclusiveLocks(tx, locked)
+			if err != nil {
+				return report, err
+			}
+			report.Steps = append(report.Steps, r)
+		}
+		if _, err := tx.Exec("INSERT INTO version(version) VALUES($1)", p.Version); err != nil {
+			return report, errs.Wrapf(err, "failed to update DB to version %d", p.Version)
+		}
+	}
+	return report, nil
+}
+
+// exclusiveLocks returns the tables locked in ACCESS EXCLUSIVE mode by the
+// transaction that are not in the given set, and adds them to it
+func exclusiveLocks(tx *sql.Tx, locked map[string]bool) ([]string, error) {
+	rows, err := tx.Query(`SELECT c.relname FROM pg_locks l
+		JOIN pg_class c ON c.oid = l.relation
+		WHERE l.pid = pg_backend_pid() AND l.mode = 'AccessExclusiveLock' AND l.granted
+		AND c.relkind IN ('r', 'p')
+		ORDER BY c.relname`)
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to list the locks of the migration")
+	}
+	defer rows.Close()
+	var res []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errs.Wrap(err, "failed to scan the locks of the migration")
+		}
+		if !locked[name] {
+			locked[name] = true
+			res = append(res, name)
+		}
+	}
+	return res, errs.WithStack(rows.Err())
+}
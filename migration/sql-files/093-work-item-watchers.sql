@@ -0,0 +1,20 @@
+CREATE TABLE work_item_watchers (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    work_item_id uuid NOT NULL REFERENCES work_items (id) ON DELETE CASCADE,
+    identity_id uuid NOT NULL REFERENCES identities (id) ON DELETE CASCADE,
+    reason text NOT NULL CHECK (reason IN ('manual', 'creator', 'assignee', 'commenter', 'mention')),
+    PRIMARY KEY (work_item_id, identity_id)
+);
+CREATE INDEX work_item_watchers_identity_id_idx ON work_item_watchers USING btree (identity_id);
+
+CREATE TABLE notification_preferences (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    identity_id uuid NOT NULL REFERENCES identities (id) ON DELETE CASCADE,
+    message_type text NOT NULL CHECK (message_type <> ''),
+    enabled boolean NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (identity_id, message_type)
+);
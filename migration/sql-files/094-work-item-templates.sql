@@ -0,0 +1,13 @@
+CREATE TABLE work_item_templates (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4() NOT NULL,
+    space_id uuid NOT NULL REFERENCES spaces (id) ON DELETE CASCADE,
+    name text NOT NULL CHECK (name <> ''),
+    description text,
+    creator_id uuid NOT NULL REFERENCES identities (id),
+    root jsonb NOT NULL,
+    version integer DEFAULT 0 NOT NULL
+);
+CREATE UNIQUE INDEX work_item_templates_name_space_id_unique_idx ON work_item_templates (space_id, name) WHERE deleted_at IS NULL;
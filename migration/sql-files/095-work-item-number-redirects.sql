@@ -0,0 +1,9 @@
+-- keeps the numbers that work items had in the spaces they were moved out of
+CREATE TABLE work_item_number_redirects (
+    created_at timestamp with time zone,
+    space_id uuid NOT NULL REFERENCES spaces (id) ON DELETE CASCADE,
+    number integer NOT NULL,
+    work_item_id uuid NOT NULL REFERENCES work_items (id) ON DELETE CASCADE,
+    PRIMARY KEY (space_id, number)
+);
+CREATE INDEX work_item_number_redirects_work_item_id_idx ON work_item_number_redirects USING btree (work_item_id);
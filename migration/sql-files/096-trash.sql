@@ -0,0 +1,8 @@
+-- remembers the work item whose deletion removed a link so that the link can
+-- be restored together with the work item
+ALTER TABLE work_item_links ADD COLUMN deleted_with_work_item_id uuid REFERENCES work_items (id) ON DELETE CASCADE;
+CREATE INDEX work_item_links_deleted_with_work_item_id_idx ON work_item_links USING btree (deleted_with_work_item_id) WHERE deleted_with_work_item_id IS NOT NULL;
+
+-- speed up the listing and purging of deleted work items and comments
+CREATE INDEX work_items_deleted_at_idx ON work_items USING btree (space_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX comments_deleted_at_idx ON comments USING btree (deleted_at) WHERE deleted_at IS NOT NULL;
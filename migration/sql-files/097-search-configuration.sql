@@ -0,0 +1,71 @@
+-- the text search configuration (i.e. the language) used to index and search
+-- the work items of a space
+ALTER TABLE spaces ADD COLUMN search_config regconfig NOT NULL DEFAULT 'english';
+
+DROP TRIGGER IF EXISTS upd_tsvector ON work_items;
+DROP FUNCTION IF EXISTS workitem_tsv_trigger() CASCADE;
+
+-- computes the search vector of a work item using the text search
+-- configuration of its space: matches on the number weigh more than on the
+-- title, which weighs more than the description, which weighs more than the
+-- (non-deleted) comments.
+CREATE FUNCTION workitem_tsv(wi work_items) RETURNS tsvector AS $$
+DECLARE
+    cfg regconfig;
+BEGIN
+    SELECT search_config INTO cfg FROM spaces WHERE id = wi.space_id;
+    IF cfg IS NULL THEN
+        cfg := 'english';
+    END IF;
+    RETURN
+        setweight(to_tsvector(cfg, wi.number::text), 'A') ||
+        setweight(to_tsvector(cfg, coalesce(wi.fields->>'system.title', '')), 'B') ||
+        setweight(to_tsvector(cfg, coalesce(wi.fields#>>'{system.description, content}', '')), 'C') ||
+        setweight(to_tsvector(cfg, coalesce((
+            SELECT string_agg(c.body, ' ') FROM comments c
+            WHERE c.parent_id = wi.id AND c.deleted_at IS NULL), '')), 'D');
+END
+$$ LANGUAGE plpgsql STABLE;
+
+CREATE FUNCTION workitem_tsv_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.tsv := workitem_tsv(NEW);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER upd_tsvector BEFORE INSERT OR UPDATE OF number, fields, space_id ON work_items
+    FOR EACH ROW EXECUTE PROCEDURE workitem_tsv_trigger();
+
+-- re-index a work item when one of its comments is added, changed or deleted
+CREATE FUNCTION comment_workitem_tsv_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        UPDATE work_items w SET tsv = workitem_tsv(w) WHERE w.id = OLD.parent_id;
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.parent_id IS DISTINCT FROM OLD.parent_id) THEN
+        UPDATE work_items w SET tsv = workitem_tsv(w) WHERE w.id = NEW.parent_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER comment_upd_workitem_tsvector AFTER INSERT OR UPDATE OF body, parent_id, deleted_at OR DELETE ON comments
+    FOR EACH ROW EXECUTE PROCEDURE comment_workitem_tsv_trigger();
+
+-- re-index the work items of a space when its search configuration changes
+CREATE FUNCTION space_workitem_tsv_trigger() RETURNS trigger AS $$
+BEGIN
+    UPDATE work_items w SET tsv = workitem_tsv(w) WHERE w.space_id = NEW.id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER space_upd_workitem_tsvector AFTER UPDATE OF search_config ON spaces
+    FOR EACH ROW WHEN (NEW.search_config IS DISTINCT FROM OLD.search_config)
+    EXECUTE PROCEDURE space_workitem_tsv_trigger();
+
+-- speed up the lookup of the comments of a work item when (re-)indexing it
+CREATE INDEX comments_parent_id_idx ON comments USING btree (parent_id) WHERE deleted_at IS NULL;
+
+UPDATE work_items w SET tsv = workitem_tsv(w);
@@ -0,0 +1,8 @@
+-- prefix lookups of the suggestions: 'text_pattern_ops' lets the "lower(name)
+-- LIKE 'prefix%'" conditions use the indexes whatever the collation of the
+-- database
+CREATE INDEX labels_space_id_lower_name_idx ON labels USING btree (space_id, lower(name) text_pattern_ops) WHERE deleted_at IS NULL;
+CREATE INDEX iterations_space_id_lower_name_idx ON iterations USING btree (space_id, lower(name) text_pattern_ops) WHERE deleted_at IS NULL;
+CREATE INDEX areas_space_id_lower_name_idx ON areas USING btree (space_id, lower(name) text_pattern_ops) WHERE deleted_at IS NULL;
+CREATE INDEX identities_lower_username_pattern_idx ON identities USING btree (lower(username) text_pattern_ops) WHERE deleted_at IS NULL;
+CREATE INDEX users_lower_full_name_pattern_idx ON users USING btree (lower(full_name) text_pattern_ops) WHERE deleted_at IS NULL;
@@ -0,0 +1,10 @@
+CREATE TABLE space_roles (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    space_id uuid NOT NULL REFERENCES spaces (id) ON DELETE CASCADE,
+    identity_id uuid NOT NULL REFERENCES identities (id) ON DELETE CASCADE,
+    role text NOT NULL CHECK (role IN ('viewer', 'contributor', 'maintainer', 'admin')),
+    PRIMARY KEY (space_id, identity_id)
+);
+CREATE INDEX space_roles_identity_id_idx ON space_roles USING btree (identity_id);
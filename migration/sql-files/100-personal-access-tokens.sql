@@ -0,0 +1,14 @@
+CREATE TABLE personal_access_tokens (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4() NOT NULL,
+    identity_id uuid NOT NULL REFERENCES identities (id) ON DELETE CASCADE,
+    name text NOT NULL CHECK (name <> ''),
+    scope text NOT NULL CHECK (scope IN ('read', 'write:workitems', 'admin:space')),
+    token_hash text NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    last_used_at timestamp with time zone
+);
+CREATE UNIQUE INDEX personal_access_tokens_token_hash_idx ON personal_access_tokens USING btree (token_hash);
+CREATE INDEX personal_access_tokens_identity_id_idx ON personal_access_tokens USING btree (identity_id) WHERE deleted_at IS NULL;
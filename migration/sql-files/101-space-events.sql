@@ -0,0 +1,10 @@
+CREATE TABLE space_events (
+    id bigserial PRIMARY KEY,
+    space_id uuid NOT NULL REFERENCES spaces (id) ON DELETE CASCADE,
+    type text NOT NULL,
+    target_id text NOT NULL,
+    identity_id uuid,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+CREATE INDEX space_events_space_id_id_idx ON space_events USING btree (space_id, id);
+CREATE INDEX space_events_created_at_idx ON space_events USING btree (created_at);
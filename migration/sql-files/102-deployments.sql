@@ -0,0 +1,20 @@
+CREATE TABLE deployments (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    space_id uuid NOT NULL REFERENCES spaces (id) ON DELETE CASCADE,
+    app_name text NOT NULL CHECK (trim(app_name) <> ''),
+    environment text NOT NULL CHECK (trim(environment) <> ''),
+    version text NOT NULL CHECK (trim(version) <> ''),
+    build_id text,
+    commit_id text,
+    deployed_at timestamp with time zone NOT NULL DEFAULT now(),
+    identity_id uuid,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+CREATE INDEX deployments_space_id_environment_idx ON deployments USING btree (space_id, environment);
+
+CREATE TABLE work_item_deployments (
+    work_item_id uuid NOT NULL REFERENCES work_items (id) ON DELETE CASCADE,
+    deployment_id uuid NOT NULL REFERENCES deployments (id) ON DELETE CASCADE,
+    PRIMARY KEY (work_item_id, deployment_id)
+);
+CREATE INDEX work_item_deployments_deployment_id_idx ON work_item_deployments USING btree (deployment_id);
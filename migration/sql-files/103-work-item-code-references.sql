@@ -0,0 +1,15 @@
+CREATE TABLE work_item_code_references (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    work_item_id uuid NOT NULL REFERENCES work_items (id) ON DELETE CASCADE,
+    codebase_id uuid NOT NULL REFERENCES codebases (id) ON DELETE CASCADE,
+    kind text NOT NULL CHECK (kind IN ('commit', 'pullrequest')),
+    ref text NOT NULL CHECK (trim(ref) <> ''),
+    title text,
+    url text,
+    author text,
+    state text,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+-- redelivered webhooks update the existing references
+CREATE UNIQUE INDEX work_item_code_references_unique_idx ON work_item_code_references USING btree (work_item_id, codebase_id, kind, ref);
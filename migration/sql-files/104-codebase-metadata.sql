@@ -0,0 +1,15 @@
+CREATE TABLE codebase_metadata (
+    codebase_id uuid PRIMARY KEY REFERENCES codebases (id) ON DELETE CASCADE,
+    default_branch text,
+    branches jsonb NOT NULL DEFAULT '[]',
+    latest_commit_id text,
+    latest_commit_message text,
+    latest_commit_author text,
+    latest_commit_url text,
+    latest_commit_date timestamp with time zone,
+    open_pull_requests integer NOT NULL DEFAULT 0,
+    language text,
+    fetched_at timestamp with time zone NOT NULL DEFAULT now()
+);
+-- the codebases with the oldest metadata are refreshed first
+CREATE INDEX codebase_metadata_fetched_at_idx ON codebase_metadata USING btree (fetched_at);
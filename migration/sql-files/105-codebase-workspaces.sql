@@ -0,0 +1,23 @@
+-- the Che workspaces created or opened through the codebases, so that idle
+-- workspaces can be stopped and the workspaces of deleted codebases removed
+CREATE TABLE codebase_workspaces (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    codebase_id uuid NOT NULL REFERENCES codebases (id) ON DELETE CASCADE,
+    namespace text NOT NULL CHECK (trim(namespace) <> ''),
+    name text NOT NULL CHECK (trim(name) <> ''),
+    running boolean NOT NULL DEFAULT false,
+    last_activity_at timestamp with time zone NOT NULL DEFAULT now(),
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX codebase_workspaces_name_idx ON codebase_workspaces USING btree (codebase_id, namespace, name);
+
+-- a space without policy has no limit of running workspaces and no idle
+-- timeout
+CREATE TABLE space_workspace_policies (
+    space_id uuid PRIMARY KEY REFERENCES spaces (id) ON DELETE CASCADE,
+    max_running_workspaces integer NOT NULL DEFAULT 0 CHECK (max_running_workspaces >= 0),
+    idle_timeout_minutes integer NOT NULL DEFAULT 0 CHECK (idle_timeout_minutes >= 0),
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
@@ -0,0 +1,23 @@
+-- a space without policy keeps its closed work items in the searches and all
+-- the revisions forever
+CREATE TABLE space_retention_policies (
+    space_id uuid PRIMARY KEY REFERENCES spaces (id) ON DELETE CASCADE,
+    archive_closed_after_months integer NOT NULL DEFAULT 0 CHECK (archive_closed_after_months >= 0),
+    compact_revisions_after_months integer NOT NULL DEFAULT 0 CHECK (compact_revisions_after_months >= 0),
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- the archived work items stay in the work_items table so that their links,
+-- comments and URLs remain valid, but the searches leave them out by default
+CREATE TABLE work_item_archive (
+    work_item_id uuid PRIMARY KEY REFERENCES work_items (id) ON DELETE CASCADE,
+    space_id uuid NOT NULL REFERENCES spaces (id) ON DELETE CASCADE,
+    archived_at timestamp with time zone NOT NULL DEFAULT now()
+);
+CREATE INDEX work_item_archive_space_id_idx ON work_item_archive USING btree (space_id);
+
+-- the compaction looks for the older revisions of each item
+CREATE INDEX work_item_revisions_work_item_id_time_idx ON work_item_revisions USING btree (work_item_id, revision_time);
+CREATE INDEX comment_revisions_comment_id_time_idx ON comment_revisions USING btree (comment_id, revision_time);
+CREATE INDEX work_item_link_revisions_work_item_link_id_time_idx ON work_item_link_revisions USING btree (work_item_link_id, revision_time);
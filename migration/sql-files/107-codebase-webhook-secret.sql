@@ -0,0 +1,5 @@
+-- the secret the webhook events of the repository of each codebase are signed
+-- with, random for the existing codebases
+ALTER TABLE codebases ADD COLUMN webhook_secret TEXT;
+UPDATE codebases SET webhook_secret = replace(uuid_generate_v4()::text || uuid_generate_v4()::text, '-', '');
+ALTER TABLE codebases ALTER COLUMN webhook_secret SET NOT NULL;
@@ -0,0 +1,9 @@
+-- Che doesn't report the activity in the workspaces, so the workspaces are
+-- stopped once they ran for the maximum runtime of the policy of their space
+-- rather than after an idle timeout
+ALTER TABLE space_workspace_policies RENAME COLUMN idle_timeout_minutes TO max_runtime_minutes;
+ALTER TABLE space_workspace_policies RENAME CONSTRAINT space_workspace_policies_idle_timeout_minutes_check TO space_workspace_policies_max_runtime_minutes_check;
+
+-- when the running workspaces were started, NULL for the stopped ones
+ALTER TABLE codebase_workspaces ADD COLUMN started_at timestamp with time zone;
+UPDATE codebase_workspaces SET started_at = last_activity_at WHERE running;
@@ -0,0 +1,9 @@
+-- the running workspaces reserved in a space while che-starter starts them,
+-- outside of any transaction, so that they count against the maximum number of
+-- running workspaces of the space until their start is recorded or failed
+CREATE TABLE codebase_workspace_reservations (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    space_id uuid NOT NULL REFERENCES spaces (id) ON DELETE CASCADE,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+CREATE INDEX codebase_workspace_reservations_space_id_idx ON codebase_workspace_reservations USING btree (space_id, created_at);
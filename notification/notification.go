@@ -29,46 +29,207 @@ type Message struct {
 	UserID      *string
 	TargetID    string
 	MessageType string
+	// SpaceID is the space the target belongs to
+	SpaceID uuid.UUID
+	// Recipients holds the identities that should receive this message. It is
+	// filled by a RecipientResolver, see NewResolvingChannel.
+	Recipients []uuid.UUID
 }
 
 func (m Message) String() string {
-	return fmt.Sprintf("id:%v type:%v by:%v for:%v", m.MessageID, m.MessageType, m.UserID, m.TargetID)
+	return fmt.Sprintf("id:%v type:%v by:%v for:%v to:%v", m.MessageID, m.MessageType, m.UserID, m.TargetID, m.Recipients)
+}
+
+// The known message types
+const (
+	MessageTypeWorkItemCreate = "workitem.create"
+	MessageTypeWorkItemUpdate = "workitem.update"
+	MessageTypeCommentCreate  = "comment.create"
+	MessageTypeCommentUpdate  = "comment.update"
+	// MessageTypeDeploymentUnhealthy is sent when the deployment of an
+	// application becomes unhealthy, see kubernetes.HealthWatcher
+	MessageTypeDeploymentUnhealthy = "deployment.unhealthy"
+)
+
+// The message types that are only streamed to the subscribers of a space, see
+// NewFilteringChannel
+const (
+	MessageTypeWorkItemDelete  = "workitem.delete"
+	MessageTypeWorkItemReorder = "workitem.reorder"
+	MessageTypeLinkCreate      = "link.create"
+	MessageTypeLinkDelete      = "link.delete"
+	MessageTypeCommentDelete   = "comment.delete"
+)
+
+// MessageTypes lists all the known message types, i.e. the ones sent to the
+// notification service and for which the users set preferences
+var MessageTypes = []string{
+	MessageTypeWorkItemCreate,
+	MessageTypeWorkItemUpdate,
+	MessageTypeCommentCreate,
+	MessageTypeCommentUpdate,
+	MessageTypeDeploymentUnhealthy,
+}
+
+// IsKnownMessageType returns true if the given message type is one of the MessageTypes
+func IsKnownMessageType(messageType string) bool {
+	for _, t := range MessageTypes {
+		if t == messageType {
+			return true
+		}
+	}
+	return false
 }
 
 // NewWorkItemCreated creates a new message instance for the newly created WorkItemID
-func NewWorkItemCreated(workitemID string) Message {
-	return Message{MessageID: uuid.NewV4(), MessageType: "workitem.create", TargetID: workitemID}
+func NewWorkItemCreated(spaceID uuid.UUID, workitemID string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: MessageTypeWorkItemCreate, TargetID: workitemID, SpaceID: spaceID}
 }
 
 // NewWorkItemUpdated creates a new message instance for the updated WorkItemID
-func NewWorkItemUpdated(workitemID string) Message {
-	return Message{MessageID: uuid.NewV4(), MessageType: "workitem.update", TargetID: workitemID}
+func NewWorkItemUpdated(spaceID uuid.UUID, workitemID string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: MessageTypeWorkItemUpdate, TargetID: workitemID, SpaceID: spaceID}
+}
+
+// NewWorkItemDeleted creates a new message instance for the deleted WorkItemID
+func NewWorkItemDeleted(spaceID uuid.UUID, workitemID string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: MessageTypeWorkItemDelete, TargetID: workitemID, SpaceID: spaceID}
+}
+
+// NewWorkItemReordered creates a new message instance for the reordered WorkItemID
+func NewWorkItemReordered(spaceID uuid.UUID, workitemID string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: MessageTypeWorkItemReorder, TargetID: workitemID, SpaceID: spaceID}
+}
+
+// NewLinkCreated creates a new message instance for the newly created work
+// item LinkID
+func NewLinkCreated(spaceID uuid.UUID, linkID string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: MessageTypeLinkCreate, TargetID: linkID, SpaceID: spaceID}
+}
+
+// NewLinkDeleted creates a new message instance for the deleted work item
+// LinkID
+func NewLinkDeleted(spaceID uuid.UUID, linkID string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: MessageTypeLinkDelete, TargetID: linkID, SpaceID: spaceID}
 }
 
 // NewCommentCreated creates a new message instance for the newly created CommentID
-func NewCommentCreated(commentID string) Message {
-	return Message{MessageID: uuid.NewV4(), MessageType: "comment.create", TargetID: commentID}
+func NewCommentCreated(spaceID uuid.UUID, commentID string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: MessageTypeCommentCreate, TargetID: commentID, SpaceID: spaceID}
 }
 
 // NewCommentUpdated creates a new message instance for the updated CommentID
-func NewCommentUpdated(commentID string) Message {
-	return Message{MessageID: uuid.NewV4(), MessageType: "comment.update", TargetID: commentID}
+func NewCommentUpdated(spaceID uuid.UUID, commentID string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: MessageTypeCommentUpdate, TargetID: commentID, SpaceID: spaceID}
+}
+
+// NewCommentDeleted creates a new message instance for the deleted CommentID
+func NewCommentDeleted(spaceID uuid.UUID, commentID string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: MessageTypeCommentDelete, TargetID: commentID, SpaceID: spaceID}
+}
+
+// NewDeploymentUnhealthy creates a new message instance for the deployment of
+// an application in an environment that became unhealthy. The target is
+// "<environment name>/<application name>".
+func NewDeploymentUnhealthy(spaceID uuid.UUID, envName string, appName string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: MessageTypeDeploymentUnhealthy, TargetID: envName + "/" + appName, SpaceID: spaceID}
 }
 
 func setCurrentIdentity(ctx context.Context, msg *Message) {
+	if msg.UserID != nil {
+		return
+	}
 	currentUserIdentityID, err := login.ContextIdentity(ctx)
-	if err != nil {
+	if err == nil && currentUserIdentityID != nil {
 		uID := currentUserIdentityID.String()
 		msg.UserID = &uID
 	}
 }
 
+// MultiChannel sends each message to all the wrapped channels
+type MultiChannel []Channel
+
+// NewMultiChannel creates a channel sending each message to all the given
+// channels, in order
+func NewMultiChannel(channels ...Channel) Channel {
+	return MultiChannel(channels)
+}
+
+// Send sends the message to all the channels
+func (c MultiChannel) Send(ctx context.Context, msg Message) {
+	for _, ch := range c {
+		ch.Send(ctx, msg)
+	}
+}
+
+// FilteringChannel only passes the messages of some types on to the wrapped
+// Channel
+type FilteringChannel struct {
+	types map[string]struct{}
+	next  Channel
+}
+
+// NewFilteringChannel wraps the given channel so that it only receives the
+// messages of the given types
+func NewFilteringChannel(next Channel, types ...string) Channel {
+	c := &FilteringChannel{types: map[string]struct{}{}, next: next}
+	for _, t := range types {
+		c.types[t] = struct{}{}
+	}
+	return c
+}
+
+// Send forwards the message if its type is one of the channel
+func (c *FilteringChannel) Send(ctx context.Context, msg Message) {
+	if _, ok := c.types[msg.MessageType]; ok {
+		c.next.Send(ctx, msg)
+	}
+}
+
 // DevNullChannel is the default configured channel. It does nothing.
 type DevNullChannel struct{}
 
 // Send NO-OP
 func (d *DevNullChannel) Send(context.Context, Message) {}
 
+// RecipientResolver resolves the identities that should receive a message
+type RecipientResolver interface {
+	Recipients(ctx context.Context, msg Message) ([]uuid.UUID, error)
+}
+
+// ResolvingChannel attaches the resolved recipients to each message before
+// passing it on to the wrapped Channel
+type ResolvingChannel struct {
+	resolver RecipientResolver
+	next     Channel
+}
+
+// NewResolvingChannel wraps the given channel so that every message carries
+// the list of recipients computed by the given resolver
+func NewResolvingChannel(resolver RecipientResolver, next Channel) Channel {
+	return &ResolvingChannel{resolver: resolver, next: next}
+}
+
+// Send resolves the recipients of the message and forwards it. Resolution
+// happens synchronously since the request context may not outlive this call.
+// Failing to resolve the recipients doesn't prevent the message from being
+// sent, it will simply not carry any recipients.
+func (c *ResolvingChannel) Send(ctx context.Context, msg Message) {
+	setCurrentIdentity(ctx, &msg)
+	recipients, err := c.resolver.Recipients(ctx, msg)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"message_id": msg.MessageID,
+			"type":       msg.MessageType,
+			"target_id":  msg.TargetID,
+			"err":        err,
+		}, "unable to resolve the notification recipients")
+	} else {
+		msg.Recipients = recipients
+	}
+	c.next.Send(ctx, msg)
+}
+
 // ServiceConfiguration holds configuration options required to interact with the fabric8-notification API
 type ServiceConfiguration interface {
 	GetNotificationServiceURL() string
@@ -115,6 +276,14 @@ func (s *Service) Send(ctx context.Context, msg Message) {
 		cl.SetJWTSigner(goasupport.NewForwardSigner(ctx))
 
 		msgID := goauuid.UUID(msg.MessageID)
+		var custom map[string]interface{}
+		if msg.Recipients != nil {
+			recipients := make([]string, len(msg.Recipients))
+			for i, r := range msg.Recipients {
+				recipients[i] = r.String()
+			}
+			custom = map[string]interface{}{"recipients": recipients}
+		}
 
 		resp, err := cl.SendNotify(
 			goasupport.ForwardContextRequestID(ctx),
@@ -124,8 +293,9 @@ func (s *Service) Send(ctx context.Context, msg Message) {
 					Type: "notifications",
 					ID:   &msgID,
 					Attributes: &client.NotificationAttributes{
-						Type: msg.MessageType,
-						ID:   msg.TargetID,
+						Type:   msg.MessageType,
+						ID:     msg.TargetID,
+						Custom: custom,
 					},
 				},
 			},
@@ -0,0 +1,45 @@
+package notification_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingChannel keeps the messages it's sent
+type recordingChannel struct {
+	messages []notification.Message
+}
+
+func (c *recordingChannel) Send(ctx context.Context, msg notification.Message) {
+	c.messages = append(c.messages, msg)
+}
+
+func TestFilteringChannel(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	spaceID := uuid.NewV4()
+	testData := []struct {
+		msg  notification.Message
+		sent bool
+	}{
+		{notification.NewWorkItemUpdated(spaceID, uuid.NewV4().String()), true},
+		{notification.NewDeploymentUnhealthy(spaceID, "stage", "vertx-hello"), true},
+		{notification.NewLinkCreated(spaceID, uuid.NewV4().String()), false},
+	}
+	for _, td := range testData {
+		t.Run(td.msg.MessageType, func(t *testing.T) {
+			// the notification service only gets the known message types
+			service := &recordingChannel{}
+			notification.NewFilteringChannel(service, notification.MessageTypes...).Send(context.Background(), td.msg)
+			if td.sent {
+				assert.Equal(t, []notification.Message{td.msg}, service.messages)
+			} else {
+				assert.Empty(t, service.messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/watcher"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WatcherResolver resolves the recipients of a message from the watchers of
+// the work item the message is about.
+type WatcherResolver struct {
+	db *gorm.DB
+}
+
+var _ RecipientResolver = &WatcherResolver{}
+
+// NewWatcherResolver creates a new recipient resolver
+func NewWatcherResolver(db *gorm.DB) *WatcherResolver {
+	return &WatcherResolver{db: db}
+}
+
+// Recipients returns the watchers of the work item targeted by the message,
+// excluding the identity that triggered the message. Messages about comments
+// are resolved using the work item the comment belongs to. Messages about
+// deployments go to the owner and the members of the space.
+func (r *WatcherResolver) Recipients(ctx context.Context, msg Message) ([]uuid.UUID, error) {
+	if msg.MessageType == MessageTypeDeploymentUnhealthy {
+		return watcher.NewRepository(r.db).ListSpaceRecipients(ctx, msg.SpaceID, msg.MessageType)
+	}
+	targetID, err := uuid.FromString(msg.TargetID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "invalid target ID: %s", msg.TargetID)
+	}
+	var workItemID uuid.UUID
+	switch msg.MessageType {
+	case MessageTypeWorkItemCreate, MessageTypeWorkItemUpdate:
+		workItemID = targetID
+	case MessageTypeCommentCreate, MessageTypeCommentUpdate:
+		c, err := comment.NewRepository(r.db).Load(ctx, targetID)
+		if err != nil {
+			return nil, errs.Wrapf(err, "failed to load comment %s", targetID)
+		}
+		workItemID = c.ParentID
+	default:
+		return nil, errs.Errorf("unable to resolve recipients of message type '%s'", msg.MessageType)
+	}
+	var excluded []uuid.UUID
+	if msg.UserID != nil {
+		if userID, err := uuid.FromString(*msg.UserID); err == nil {
+			excluded = append(excluded, userID)
+		}
+	}
+	return watcher.NewRepository(r.db).ListRecipients(ctx, workItemID, msg.MessageType, excluded...)
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiterTake(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	now := time.Now()
+	l := NewLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	t.Run("burst", func(t *testing.T) {
+		for i := 2; i >= 0; i-- {
+			res := l.Take("a")
+			require.True(t, res.Allowed)
+			assert.Equal(t, 3, res.Limit)
+			assert.Equal(t, i, res.Remaining)
+		}
+		res := l.Take("a")
+		require.False(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+		assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+		assert.Equal(t, 1500*time.Millisecond, res.Reset)
+	})
+
+	t.Run("keys are independent", func(t *testing.T) {
+		assert.True(t, l.Take("b").Allowed)
+	})
+
+	t.Run("refill", func(t *testing.T) {
+		now = now.Add(500 * time.Millisecond)
+		assert.True(t, l.Take("a").Allowed)
+		assert.False(t, l.Take("a").Allowed)
+		// the bucket never holds more than burst tokens
+		now = now.Add(time.Hour)
+		res := l.Take("a")
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 2, res.Remaining)
+	})
+}
+
+func TestLimiterDropFullBuckets(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	now := time.Now()
+	l := NewLimiter(1, 1)
+	l.now = func() time.Time { return now }
+	l.Take("full")
+	now = now.Add(time.Second)
+	l.Take("empty")
+	l.dropFullBuckets(now)
+	assert.Len(t, l.buckets, 1)
+	assert.Contains(t, l.buckets, "empty")
+}
@@ -0,0 +1,4 @@
+// Package ratelimit contains the middleware that limits the rate of the
+// requests of each identity (or client IP, for the anonymous requests) with a
+// token bucket per route group.
+package ratelimit
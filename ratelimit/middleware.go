@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/metric"
+	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+)
+
+// The route groups, each one having its own limits
+const (
+	// GroupSearch holds the search, autocompletion and GraphQL requests
+	GroupSearch = "search"
+	// GroupWrite holds the requests that are not reads
+	GroupWrite = "write"
+	// GroupDefault holds the other requests
+	GroupDefault = "default"
+)
+
+// The headers describing the limits of the client, see
+// https://tools.ietf.org/html/draft-polli-ratelimit-headers
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+	HeaderRetry     = "Retry-After"
+)
+
+// Config holds the rate limits
+type Config interface {
+	IsRateLimitEnabled() bool
+	GetRateLimit(group string) (rate float64, burst int)
+}
+
+// Group returns the route group of the request
+func Group(req *http.Request) string {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	if strings.HasPrefix(path, "/api/search") || strings.HasSuffix(path, "/suggestions") || path == "/api/graphql" {
+		return GroupSearch
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return GroupDefault
+	}
+	return GroupWrite
+}
+
+// clientKey returns the key of the bucket of the client: the identity if the
+// request carries a valid token, the client IP otherwise
+func clientKey(ctx context.Context, req *http.Request) string {
+	if token := goajwt.ContextJWT(ctx); token != nil && token.Valid {
+		if identityID, err := log.ExtractIdentityID(ctx); err == nil {
+			return "identity:" + identityID
+		}
+	}
+	return "ip:" + clientIP(req)
+}
+
+// clientIP returns the IP of the client. Behind the router, it's the last
+// entry of the X-Forwarded-For header, which the router appends and the client
+// can't forge.
+func clientIP(req *http.Request) string {
+	if forwarded := req.Header.Get("X-Forwarded-For"); forwarded != "" {
+		ips := strings.Split(forwarded, ",")
+		if ip := strings.TrimSpace(ips[len(ips)-1]); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// seconds rounds the given duration up to the second
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// Middleware limits the rate of the requests of each identity, or client IP
+// for the anonymous requests, per route group. It must come after the
+// middleware extracting the token from the request. The requests over the
+// limit are rejected with a 429 status.
+func Middleware(config Config) goa.Middleware {
+	var mu sync.Mutex
+	limiters := map[string]*Limiter{}
+	limiterFor := func(group string) *Limiter {
+		mu.Lock()
+		defer mu.Unlock()
+		l, ok := limiters[group]
+		if !ok {
+			rate, burst := config.GetRateLimit(group)
+			if rate > 0 && burst > 0 {
+				l = NewLimiter(rate, burst)
+			}
+			// a nil limiter means no limit for the group
+			limiters[group] = l
+		}
+		return l
+	}
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if !config.IsRateLimitEnabled() {
+				return h(ctx, rw, req)
+			}
+			group := Group(req)
+			l := limiterFor(group)
+			if l == nil {
+				return h(ctx, rw, req)
+			}
+			key := clientKey(ctx, req)
+			res := l.Take(group + "|" + key)
+			rw.Header().Set(HeaderLimit, strconv.Itoa(res.Limit))
+			rw.Header().Set(HeaderRemaining, strconv.Itoa(res.Remaining))
+			rw.Header().Set(HeaderReset, seconds(res.Reset))
+			metric.ReportRateLimit(group, !res.Allowed)
+			if !res.Allowed {
+				rw.Header().Set(HeaderRetry, seconds(res.RetryAfter))
+				log.Warn(ctx, map[string]interface{}{
+					"group":  group,
+					"client": key,
+				}, "too many requests")
+				return errors.NewTooManyRequestsError(fmt.Sprintf("too many requests, retry in %s seconds", seconds(res.RetryAfter)))
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/ratelimit"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	enabled bool
+	limits  map[string]int
+}
+
+func (c testConfig) IsRateLimitEnabled() bool {
+	return c.enabled
+}
+
+func (c testConfig) GetRateLimit(group string) (float64, int) {
+	burst, ok := c.limits[group]
+	if !ok {
+		burst = c.limits[ratelimit.GroupDefault]
+	}
+	return 0.001, burst
+}
+
+func TestGroup(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	testData := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{http.MethodGet, "/api/search", ratelimit.GroupSearch},
+		{http.MethodGet, "/api/search/codebases", ratelimit.GroupSearch},
+		{http.MethodGet, "/api/spaces/" + uuid.NewV4().String() + "/suggestions", ratelimit.GroupSearch},
+		{http.MethodPost, "/api/graphql", ratelimit.GroupSearch},
+		{http.MethodGet, "/api/spaces", ratelimit.GroupDefault},
+		{http.MethodPatch, "/api/workitems/" + uuid.NewV4().String(), ratelimit.GroupWrite},
+		{http.MethodPost, "/api/spaces", ratelimit.GroupWrite},
+	}
+	for _, d := range testData {
+		t.Run(d.method+" "+d.path, func(t *testing.T) {
+			req := httptest.NewRequest(d.method, d.path, nil)
+			assert.Equal(t, d.expected, ratelimit.Group(req))
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	config := testConfig{
+		enabled: true,
+		limits: map[string]int{
+			ratelimit.GroupDefault: 2,
+			ratelimit.GroupSearch:  1,
+			ratelimit.GroupWrite:   0,
+		},
+	}
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return nil
+	}
+	serve := func(ctx context.Context, middleware func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error, method, path, ip string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		rw := httptest.NewRecorder()
+		return rw, middleware(ctx, rw, req)
+	}
+
+	t.Run("limited per client IP and group", func(t *testing.T) {
+		middleware := ratelimit.Middleware(config)(h)
+		rw, err := serve(context.Background(), middleware, http.MethodGet, "/api/search", "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, "1", rw.Header().Get(ratelimit.HeaderLimit))
+		assert.Equal(t, "0", rw.Header().Get(ratelimit.HeaderRemaining))
+		assert.NotEmpty(t, rw.Header().Get(ratelimit.HeaderReset))
+
+		rw, err = serve(context.Background(), middleware, http.MethodGet, "/api/search", "10.0.0.1")
+		require.Error(t, err)
+		ok, _ := errors.IsTooManyRequestsError(err)
+		assert.True(t, ok)
+		assert.NotEmpty(t, rw.Header().Get(ratelimit.HeaderRetry))
+
+		// other clients and other groups are not limited
+		_, err = serve(context.Background(), middleware, http.MethodGet, "/api/search", "10.0.0.2")
+		require.NoError(t, err)
+		_, err = serve(context.Background(), middleware, http.MethodGet, "/api/spaces", "10.0.0.1")
+		require.NoError(t, err)
+	})
+
+	t.Run("limited per identity", func(t *testing.T) {
+		middleware := ratelimit.Middleware(config)(h)
+		token := &jwt.Token{Claims: jwt.MapClaims{"sub": uuid.NewV4().String()}, Valid: true}
+		ctx := goajwt.WithJWT(context.Background(), token)
+		_, err := serve(ctx, middleware, http.MethodGet, "/api/search", "10.0.0.1")
+		require.NoError(t, err)
+		// same identity from another IP
+		_, err = serve(ctx, middleware, http.MethodGet, "/api/search", "10.0.0.2")
+		require.Error(t, err)
+		// the identities of invalid tokens are ignored
+		token = &jwt.Token{Claims: jwt.MapClaims{"sub": uuid.NewV4().String()}, Valid: false}
+		_, err = serve(goajwt.WithJWT(context.Background(), token), middleware, http.MethodGet, "/api/search", "10.0.0.3")
+		require.NoError(t, err)
+		_, err = serve(context.Background(), middleware, http.MethodGet, "/api/search", "10.0.0.3")
+		require.Error(t, err)
+	})
+
+	t.Run("group without limit", func(t *testing.T) {
+		middleware := ratelimit.Middleware(config)(h)
+		for i := 0; i < 5; i++ {
+			rw, err := serve(context.Background(), middleware, http.MethodPost, "/api/spaces", "10.0.0.1")
+			require.NoError(t, err)
+			assert.Empty(t, rw.Header().Get(ratelimit.HeaderLimit))
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		middleware := ratelimit.Middleware(testConfig{enabled: false})(h)
+		for i := 0; i < 5; i++ {
+			_, err := serve(context.Background(), middleware, http.MethodGet, "/api/search", "10.0.0.1")
+			require.NoError(t, err)
+		}
+	})
+}
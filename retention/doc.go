@@ -0,0 +1,5 @@
+// Package retention applies the retention policies of the spaces: the work
+// items finished for a long time are archived, which hides them from the
+// searches, and the oldest revisions of the work items, comments and links are
+// compacted into a single snapshot revision.
+package retention
@@ -0,0 +1,191 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/models"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/robfig/cron"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AdvisoryLockID is the ID of the advisory lock held by the instance of the
+// service enforcing the retention policies
+const AdvisoryLockID = 4271
+
+// Result counts what the enforcement of the retention policies did
+type Result struct {
+	// Skipped is true if another instance of the service was enforcing the
+	// retention policies at the same time
+	Skipped            bool
+	Archived           int64
+	Restored           int64
+	CompactedRevisions int64
+}
+
+// Enforcer periodically applies the retention policies of the spaces. The
+// work is split into batches of bounded size, each of them running in its own
+// transaction, so that the tables are never locked for long. Only one
+// instance of the service enforces the policies at a time.
+type Enforcer struct {
+	db        *gorm.DB
+	batchSize int
+	cr        *cron.Cron
+}
+
+// NewEnforcer creates a new Enforcer handling at most batchSize rows per
+// transaction
+func NewEnforcer(db *gorm.DB, batchSize int) *Enforcer {
+	return &Enforcer{db: db, batchSize: batchSize, cr: cron.New()}
+}
+
+// Start runs the enforcement according to the given cron schedule (e.g.
+// "@daily"). Nothing is ever archived nor compacted if the schedule is empty.
+func (e *Enforcer) Start(schedule string) error {
+	if schedule == "" {
+		log.Info(nil, map[string]interface{}{}, "retention schedule is not set, the retention policies are not enforced")
+		return nil
+	}
+	if e.batchSize <= 0 {
+		return errs.Errorf("invalid retention batch size %d", e.batchSize)
+	}
+	err := e.cr.AddFunc(schedule, func() {
+		if _, err := e.Enforce(context.Background()); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err": err,
+			}, "failed to enforce the retention policies")
+		}
+	})
+	if err != nil {
+		return errs.Wrapf(err, "invalid retention schedule '%s'", schedule)
+	}
+	e.cr.Start()
+	return nil
+}
+
+// Stop stops the enforcer.
+// This should be called only from main
+func (e *Enforcer) Stop() {
+	e.cr.Stop()
+}
+
+// Enforce applies the retention policies of all the spaces, unless another
+// instance of the service is already doing it. The batches that were
+// committed before an error are kept, so the next run resumes the work.
+func (e *Enforcer) Enforce(ctx context.Context) (*Result, error) {
+	// the session advisory lock is held by a connection of its own for the
+	// whole run, which spans many transactions
+	conn, err := e.db.DB().Conn(ctx)
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to open a database connection")
+	}
+	defer conn.Close()
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", AdvisoryLockID).Scan(&acquired); err != nil {
+		return nil, errs.Wrap(err, "failed to acquire the retention lock")
+	}
+	if !acquired {
+		log.Info(ctx, map[string]interface{}{}, "the retention policies are already enforced by another instance")
+		return &Result{Skipped: true}, nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", AdvisoryLockID); err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"err": err,
+			}, "failed to release the retention lock")
+		}
+	}()
+	return e.enforceAll(ctx)
+}
+
+// enforceAll applies the retention policies of all the spaces
+func (e *Enforcer) enforceAll(ctx context.Context) (*Result, error) {
+	var policies []Policy
+	err := models.Transactional(e.db, func(tx *gorm.DB) error {
+		var err error
+		policies, err = NewPolicyRepository(tx).List(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{}
+	now := time.Now()
+	for _, p := range policies {
+		if err := e.enforce(ctx, p, now, res); err != nil {
+			return res, errs.Wrapf(err, "failed to enforce the retention policy of space %s", p.SpaceID)
+		}
+	}
+	log.Info(ctx, map[string]interface{}{
+		"spaces":              len(policies),
+		"archived":            res.Archived,
+		"restored":            res.Restored,
+		"compacted_revisions": res.CompactedRevisions,
+	}, "enforced the retention policies")
+	return res, nil
+}
+
+// enforce applies the policy of a space and adds what it did to the result
+func (e *Enforcer) enforce(ctx context.Context, p Policy, now time.Time, res *Result) error {
+	// once a policy stops archiving, the zero time restores all the items
+	closedBefore, archive := p.ArchiveBefore(now)
+	n, err := e.batches(ctx, p.SpaceID, "restore", func(r Repository) (int64, error) {
+		return r.Restore(ctx, p.SpaceID, closedBefore, e.batchSize)
+	})
+	res.Restored += n
+	if err != nil {
+		return err
+	}
+	if archive {
+		n, err := e.batches(ctx, p.SpaceID, "archive", func(r Repository) (int64, error) {
+			return r.Archive(ctx, p.SpaceID, closedBefore, e.batchSize)
+		})
+		res.Archived += n
+		if err != nil {
+			return err
+		}
+	}
+	if before, ok := p.CompactBefore(now); ok {
+		n, err := e.batches(ctx, p.SpaceID, "compact revisions", func(r Repository) (int64, error) {
+			return r.CompactRevisions(ctx, p.SpaceID, before, e.batchSize)
+		})
+		res.CompactedRevisions += n
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batches runs the given operation in a new transaction until it handles no
+// more rows, logs the progress after each batch and returns the total number
+// of rows handled
+func (e *Enforcer) batches(ctx context.Context, spaceID uuid.UUID, op string, fn func(r Repository) (int64, error)) (int64, error) {
+	var total int64
+	for batch := 1; ; batch++ {
+		var n int64
+		err := models.Transactional(e.db, func(tx *gorm.DB) error {
+			var err error
+			n, err = fn(NewRepository(tx))
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+		if n == 0 {
+			return total, nil
+		}
+		total += n
+		log.Info(ctx, map[string]interface{}{
+			"space_id":  spaceID,
+			"operation": op,
+			"batch":     batch,
+			"rows":      n,
+			"total":     total,
+		}, "retention batch done")
+	}
+}
@@ -0,0 +1,107 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/space/policy"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Policy tells how long the closed work items and the revisions of a space
+// are kept as they are. A zero value means forever.
+type Policy struct {
+	SpaceID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	// ArchiveClosedAfterMonths is the number of months after which a closed
+	// (or resolved, done or removed) work item is archived
+	ArchiveClosedAfterMonths int
+	// CompactRevisionsAfterMonths is the number of months after which the
+	// revisions of the work items, comments and links are compacted
+	CompactRevisionsAfterMonths int
+	CreatedAt                   time.Time
+	UpdatedAt                   time.Time
+}
+
+// PolicyTableName constant that holds table name of the retention policies
+const PolicyTableName = "space_retention_policies"
+
+// TableName implements gorm.tabler
+func (p Policy) TableName() string {
+	return PolicyTableName
+}
+
+// ArchiveBefore returns the time before which the closed work items must be
+// archived at the given time, and false if they are never archived
+func (p Policy) ArchiveBefore(now time.Time) (time.Time, bool) {
+	if p.ArchiveClosedAfterMonths == 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, -p.ArchiveClosedAfterMonths, 0), true
+}
+
+// CompactBefore returns the time before which the revisions must be
+// compacted at the given time, and false if they are never compacted
+func (p Policy) CompactBefore(now time.Time) (time.Time, bool) {
+	if p.CompactRevisionsAfterMonths == 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, -p.CompactRevisionsAfterMonths, 0), true
+}
+
+// PolicyRepository describes interactions with the retention policies
+type PolicyRepository interface {
+	// Load returns the policy of the space, which keeps everything forever
+	// if none was saved
+	Load(ctx context.Context, spaceID uuid.UUID) (*Policy, error)
+	// Save creates or replaces the policy of the space
+	Save(ctx context.Context, p *Policy) error
+	// List returns the saved policies of all the spaces
+	List(ctx context.Context) ([]Policy, error)
+}
+
+// NewPolicyRepository creates a new storage type.
+func NewPolicyRepository(db *gorm.DB) PolicyRepository {
+	return &GormPolicyRepository{db: db}
+}
+
+// GormPolicyRepository is the implementation of the storage interface for
+// the retention policies.
+type GormPolicyRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the policy of the space
+func (r *GormPolicyRepository) Load(ctx context.Context, spaceID uuid.UUID) (*Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retention_policy", "load"}, time.Now())
+	p := Policy{SpaceID: spaceID}
+	if err := policy.Load(ctx, r.db, PolicyTableName, spaceID, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Save creates or replaces the policy of the space
+func (r *GormPolicyRepository) Save(ctx context.Context, p *Policy) error {
+	defer goa.MeasureSince([]string{"goa", "db", "retention_policy", "save"}, time.Now())
+	return policy.Save(ctx, r.db, PolicyTableName, p.SpaceID, p,
+		policy.Setting{Column: "archive_closed_after_months", Attribute: "archive-closed-after-months", Value: p.ArchiveClosedAfterMonths},
+		policy.Setting{Column: "compact_revisions_after_months", Attribute: "compact-revisions-after-months", Value: p.CompactRevisionsAfterMonths},
+	)
+}
+
+// List returns the saved policies of all the spaces
+func (r *GormPolicyRepository) List(ctx context.Context) ([]Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retention_policy", "list"}, time.Now())
+	var res []Policy
+	if err := r.db.Order("space_id").Find(&res).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to list the retention policies")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,168 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/fabric8-services/fabric8-wit/workitem/schedule"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Archive marks an archived work item. The work item itself stays in the
+// work items table.
+type Archive struct {
+	WorkItemID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	SpaceID    uuid.UUID `sql:"type:uuid"`
+	ArchivedAt time.Time
+}
+
+// ArchiveTableName constant that holds table name of the archived work items
+const ArchiveTableName = "work_item_archive"
+
+// TableName implements gorm.tabler
+func (a Archive) TableName() string {
+	return ArchiveTableName
+}
+
+// finishedStates is the SQL list of the states of the work items that are done
+// with (closed, resolved, done or removed), which is compared to the trimmed
+// lower case state of the work items
+var finishedStates = "'" + strings.Join(schedule.FinishedStates(), "', '") + "'"
+
+// Repository describes the archival and compaction operations of a space.
+// Each operation handles at most the given number of rows so that the locks
+// it takes are short-lived, and returns how many rows it handled; it has to be
+// repeated until it returns 0.
+type Repository interface {
+	// Archive archives the work items of the space that were finished (i.e.
+	// closed, resolved, done or removed) and left unchanged since the given
+	// time.
+	Archive(ctx context.Context, spaceID uuid.UUID, closedBefore time.Time, limit int) (int64, error)
+	// Restore removes from the archive the work items of the space that were
+	// reopened or changed since the given time.
+	Restore(ctx context.Context, spaceID uuid.UUID, closedBefore time.Time, limit int) (int64, error)
+	// CompactRevisions deletes the revisions of the work items, comments and
+	// links of the space older than the given time, except the most recent
+	// of them for each item, which holds a snapshot of the item at that time.
+	// At most the given number of revisions of each kind is deleted.
+	CompactRevisions(ctx context.Context, spaceID uuid.UUID, before time.Time, limit int) (int64, error)
+	// IsArchived returns true if the work item is archived
+	IsArchived(ctx context.Context, workItemID uuid.UUID) (bool, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for the
+// archival and compaction operations.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Archive archives the work items of the space finished before the given time
+func (r *GormRepository) Archive(ctx context.Context, spaceID uuid.UUID, closedBefore time.Time, limit int) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retention", "archive"}, time.Now())
+	stmt := fmt.Sprintf(`INSERT INTO %[1]s (work_item_id, space_id)
+		SELECT w.id, w.space_id FROM %[2]s w
+		WHERE w.space_id = $1 AND w.deleted_at IS NULL
+		AND lower(trim(w.fields->>'%[3]s')) IN (%[4]s) AND w.updated_at < $2
+		AND NOT EXISTS (SELECT 1 FROM %[1]s a WHERE a.work_item_id = w.id)
+		ORDER BY w.updated_at
+		LIMIT $3`,
+		ArchiveTableName, workitem.WorkItemStorage{}.TableName(), workitem.SystemState, finishedStates)
+	return r.exec(ctx, "archive", stmt, spaceID, closedBefore, limit)
+}
+
+// Restore removes from the archive the work items of the space that were
+// reopened or changed since the given time
+func (r *GormRepository) Restore(ctx context.Context, spaceID uuid.UUID, closedBefore time.Time, limit int) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retention", "restore"}, time.Now())
+	stmt := fmt.Sprintf(`DELETE FROM %[1]s WHERE work_item_id IN (
+		SELECT a.work_item_id FROM %[1]s a JOIN %[2]s w ON w.id = a.work_item_id
+		WHERE a.space_id = $1
+		AND (coalesce(lower(trim(w.fields->>'%[3]s')), '') NOT IN (%[4]s) OR w.updated_at >= $2)
+		LIMIT $3)`,
+		ArchiveTableName, workitem.WorkItemStorage{}.TableName(), workitem.SystemState, finishedStates)
+	return r.exec(ctx, "restore", stmt, spaceID, closedBefore, limit)
+}
+
+// compactQuery returns the statement deleting the revisions of the given
+// table older than the time given as second argument, except the most recent
+// of them for each item. The revisions are restricted to the space given as
+// first argument by the given join with the work items table "w".
+func compactQuery(table, itemColumn, join string) string {
+	return fmt.Sprintf(`DELETE FROM %[1]s WHERE id IN (
+		SELECT r.id FROM %[1]s r %[3]s
+		WHERE w.space_id = $1 AND r.revision_time < $2
+		AND EXISTS (SELECT 1 FROM %[1]s n WHERE n.%[2]s = r.%[2]s
+			AND n.revision_time < $2 AND (n.revision_time, n.id) > (r.revision_time, r.id))
+		LIMIT $3)`,
+		table, itemColumn, join)
+}
+
+// CompactRevisions deletes the revisions of the space older than the given
+// time, except the most recent of them for each item
+func (r *GormRepository) CompactRevisions(ctx context.Context, spaceID uuid.UUID, before time.Time, limit int) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retention", "compact_revisions"}, time.Now())
+	wiTable := workitem.WorkItemStorage{}.TableName()
+	stmts := []string{
+		compactQuery(workitem.Revision{}.TableName(), "work_item_id",
+			fmt.Sprintf("JOIN %s w ON w.id = r.work_item_id", wiTable)),
+		compactQuery(comment.Revision{}.TableName(), "comment_id",
+			fmt.Sprintf("JOIN %s c ON c.id = r.comment_id JOIN %s w ON w.id = c.parent_id", comment.Comment{}.TableName(), wiTable)),
+		compactQuery(link.Revision{}.TableName(), "work_item_link_id",
+			fmt.Sprintf("JOIN %s w ON w.id = r.work_item_link_source_id", wiTable)),
+	}
+	var compacted int64
+	for _, stmt := range stmts {
+		n, err := r.exec(ctx, "compact the revisions", stmt, spaceID, before, limit)
+		if err != nil {
+			return 0, err
+		}
+		compacted += n
+	}
+	return compacted, nil
+}
+
+// IsArchived returns true if the work item is archived
+func (r *GormRepository) IsArchived(ctx context.Context, workItemID uuid.UUID) (bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retention", "is_archived"}, time.Now())
+	var count int
+	if err := r.db.Model(&Archive{}).Where("work_item_id = ?", workItemID).Count(&count).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"wi_id": workItemID,
+			"err":   err,
+		}, "unable to check if the work item is archived")
+		return false, errors.NewInternalError(ctx, err)
+	}
+	return count > 0, nil
+}
+
+// exec executes the given statement on a space and returns the number of
+// affected rows
+func (r *GormRepository) exec(ctx context.Context, op string, stmt string, spaceID uuid.UUID, before time.Time, limit int) (int64, error) {
+	if limit <= 0 {
+		return 0, errors.NewBadParameterError("limit", limit)
+	}
+	db := r.db.Exec(stmt, spaceID, before, limit)
+	if db.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"before":   before,
+			"err":      db.Error,
+		}, "unable to %s", op)
+		return 0, errors.NewInternalError(ctx, db.Error)
+	}
+	return db.RowsAffected, nil
+}
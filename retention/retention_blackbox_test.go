@@ -0,0 +1,278 @@
+package retention_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/retention"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestRetentionRepository struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunRetentionRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestRetentionRepository{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+// monthsAgo returns the time the given number of months ago
+func monthsAgo(months int) time.Time {
+	return time.Now().AddDate(0, -months, 0)
+}
+
+// closedWorkItems returns a recipe of work items that are closed except the
+// last one
+func closedWorkItems(n int) tf.RecipeFunction {
+	return tf.WorkItems(n, func(fxt *tf.TestFixture, idx int) error {
+		if idx < n-1 {
+			fxt.WorkItems[idx].Fields[workitem.SystemState] = workitem.SystemStateClosed
+		}
+		return nil
+	})
+}
+
+// setUpdatedAt changes the last update of the given work item
+func (s *TestRetentionRepository) setUpdatedAt(t *testing.T, wiID uuid.UUID, updatedAt time.Time) {
+	require.NoError(t, s.DB.Exec("UPDATE work_items SET updated_at = ? WHERE id = ?", updatedAt, wiID).Error)
+}
+
+// addRevision copies a revision of the given item in the given revision table
+// with the given time
+func (s *TestRetentionRepository) addRevision(t *testing.T, table, itemColumn string, itemID uuid.UUID, at time.Time) {
+	stmt := fmt.Sprintf(`INSERT INTO %[1]s
+		SELECT (jsonb_populate_record(NULL::%[1]s, to_jsonb(r) || jsonb_build_object('id', uuid_generate_v4(), 'revision_time', ?::timestamptz))).*
+		FROM %[1]s r WHERE r.%[2]s = ? LIMIT 1`, table, itemColumn)
+	require.NoError(t, s.DB.Exec(stmt, at, itemID).Error)
+}
+
+// countRevisions returns the number of revisions of the given item
+func (s *TestRetentionRepository) countRevisions(t *testing.T, table, itemColumn string, itemID uuid.UUID) int {
+	var count int
+	require.NoError(t, s.DB.Table(table).Where(itemColumn+" = ?", itemID).Count(&count).Error)
+	return count
+}
+
+func (s *TestRetentionRepository) TestPolicies() {
+	repo := retention.NewPolicyRepository(s.DB)
+
+	s.T().Run("keeps everything by default", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		p, err := repo.Load(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, retention.Policy{SpaceID: fxt.Spaces[0].ID}, *p)
+		_, ok := p.ArchiveBefore(time.Now())
+		assert.False(t, ok)
+		_, ok = p.CompactBefore(time.Now())
+		assert.False(t, ok)
+	})
+
+	s.T().Run("save", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		p := retention.Policy{SpaceID: fxt.Spaces[0].ID, ArchiveClosedAfterMonths: 12, CompactRevisionsAfterMonths: 6}
+		require.NoError(t, repo.Save(context.Background(), &p))
+		assert.False(t, p.UpdatedAt.IsZero())
+		p.ArchiveClosedAfterMonths = 24
+		require.NoError(t, repo.Save(context.Background(), &p))
+		loaded, err := repo.Load(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 24, loaded.ArchiveClosedAfterMonths)
+		assert.Equal(t, 6, loaded.CompactRevisionsAfterMonths)
+		policies, err := repo.List(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, policies, *loaded)
+	})
+
+	s.T().Run("negative", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		err := repo.Save(context.Background(), &retention.Policy{SpaceID: fxt.Spaces[0].ID, CompactRevisionsAfterMonths: -1})
+		assert.IsType(t, errors.BadParameterError{}, err)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		err := repo.Save(context.Background(), &retention.Policy{SpaceID: uuid.NewV4()})
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *TestRetentionRepository) TestArchive() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, closedWorkItems(4))
+	repo := retention.NewRepository(s.DB)
+	ctx := context.Background()
+	spaceID := fxt.Spaces[0].ID
+	// the last work item is not closed and the third one changed recently
+	for _, wi := range []*workitem.WorkItem{fxt.WorkItems[0], fxt.WorkItems[1], fxt.WorkItems[3]} {
+		s.setUpdatedAt(s.T(), wi.ID, monthsAgo(24))
+	}
+
+	s.T().Run("in batches", func(t *testing.T) {
+		for _, expected := range []int64{1, 1, 0} {
+			n, err := repo.Archive(ctx, spaceID, monthsAgo(12), 1)
+			require.NoError(t, err)
+			assert.Equal(t, expected, n)
+		}
+		for i, expected := range []bool{true, true, false, false} {
+			archived, err := repo.IsArchived(ctx, fxt.WorkItems[i].ID)
+			require.NoError(t, err)
+			assert.Equal(t, expected, archived, "work item %d", i)
+		}
+	})
+
+	s.T().Run("restore the changed items", func(t *testing.T) {
+		s.setUpdatedAt(t, fxt.WorkItems[1].ID, time.Now())
+		n, err := repo.Restore(ctx, spaceID, monthsAgo(12), 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		archived, err := repo.IsArchived(ctx, fxt.WorkItems[1].ID)
+		require.NoError(t, err)
+		assert.False(t, archived)
+		archived, err = repo.IsArchived(ctx, fxt.WorkItems[0].ID)
+		require.NoError(t, err)
+		assert.True(t, archived)
+	})
+
+	s.T().Run("invalid limit", func(t *testing.T) {
+		_, err := repo.Archive(ctx, spaceID, monthsAgo(12), 0)
+		assert.IsType(t, errors.BadParameterError{}, err)
+	})
+}
+
+func (s *TestRetentionRepository) TestArchiveFinishedStates() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(4))
+	repo := retention.NewRepository(s.DB)
+	ctx := context.Background()
+	// the states are those of the different space templates
+	for i, state := range []string{"Resolved", " done ", "Removed", "In Progress"} {
+		require.NoError(s.T(), s.DB.Exec(`UPDATE work_items SET fields = jsonb_set(fields, ?::text[], to_jsonb(?::text)) WHERE id = ?`,
+			fmt.Sprintf("{%s}", workitem.SystemState), state, fxt.WorkItems[i].ID).Error)
+		s.setUpdatedAt(s.T(), fxt.WorkItems[i].ID, monthsAgo(24))
+	}
+
+	s.T().Run("archive the finished items", func(t *testing.T) {
+		n, err := repo.Archive(ctx, fxt.Spaces[0].ID, monthsAgo(12), 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		for i, expected := range []bool{true, true, true, false} {
+			archived, err := repo.IsArchived(ctx, fxt.WorkItems[i].ID)
+			require.NoError(t, err)
+			assert.Equal(t, expected, archived, "work item %d", i)
+		}
+	})
+
+	s.T().Run("restore the reopened items", func(t *testing.T) {
+		require.NoError(t, s.DB.Exec(`UPDATE work_items SET fields = fields - ? WHERE id = ?`, workitem.SystemState, fxt.WorkItems[0].ID).Error)
+		require.NoError(t, s.DB.Exec(`UPDATE work_items SET fields = jsonb_set(fields, ?::text[], '"open"') WHERE id = ?`,
+			fmt.Sprintf("{%s}", workitem.SystemState), fxt.WorkItems[1].ID).Error)
+		n, err := repo.Restore(ctx, fxt.Spaces[0].ID, monthsAgo(12), 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		archived, err := repo.IsArchived(ctx, fxt.WorkItems[2].ID)
+		require.NoError(t, err)
+		assert.True(t, archived)
+	})
+}
+
+func (s *TestRetentionRepository) TestCompactRevisions() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(2), tf.Comments(1), tf.WorkItemLinks(1))
+	repo := retention.NewRepository(s.DB)
+	ctx := context.Background()
+	revisions := []struct {
+		table      string
+		itemColumn string
+		itemID     uuid.UUID
+	}{
+		{workitem.Revision{}.TableName(), "work_item_id", fxt.WorkItems[0].ID},
+		{comment.Revision{}.TableName(), "comment_id", fxt.Comments[0].ID},
+		{link.Revision{}.TableName(), "work_item_link_id", fxt.WorkItemLinks[0].ID},
+	}
+	for _, r := range revisions {
+		for _, months := range []int{36, 24, 1} {
+			s.addRevision(s.T(), r.table, r.itemColumn, r.itemID, monthsAgo(months))
+		}
+		require.Equal(s.T(), 4, s.countRevisions(s.T(), r.table, r.itemColumn, r.itemID))
+	}
+
+	s.T().Run("other space", func(t *testing.T) {
+		n, err := repo.CompactRevisions(ctx, uuid.NewV4(), monthsAgo(12), 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), n)
+	})
+
+	s.T().Run("keep a snapshot", func(t *testing.T) {
+		n, err := repo.CompactRevisions(ctx, fxt.Spaces[0].ID, monthsAgo(12), 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		n, err = repo.CompactRevisions(ctx, fxt.Spaces[0].ID, monthsAgo(12), 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), n)
+		for _, r := range revisions {
+			// the revision of 24 months ago remains along with the recent ones
+			assert.Equal(t, 3, s.countRevisions(t, r.table, r.itemColumn, r.itemID), r.table)
+		}
+	})
+}
+
+func (s *TestRetentionRepository) TestEnforce() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, closedWorkItems(2))
+	ctx := context.Background()
+	wiID := fxt.WorkItems[0].ID
+	s.setUpdatedAt(s.T(), wiID, monthsAgo(24))
+	for _, months := range []int{36, 30, 24} {
+		s.addRevision(s.T(), workitem.Revision{}.TableName(), "work_item_id", wiID, monthsAgo(months))
+	}
+	policies := retention.NewPolicyRepository(s.DB)
+	p := retention.Policy{SpaceID: fxt.Spaces[0].ID, ArchiveClosedAfterMonths: 12, CompactRevisionsAfterMonths: 12}
+	require.NoError(s.T(), policies.Save(ctx, &p))
+	enforcer := retention.NewEnforcer(s.DB, 1)
+
+	s.T().Run("archive and compact", func(t *testing.T) {
+		res, err := enforcer.Enforce(ctx)
+		require.NoError(t, err)
+		assert.False(t, res.Skipped)
+		assert.True(t, res.Archived >= 1)
+		assert.True(t, res.CompactedRevisions >= 2)
+		archived, err := retention.NewRepository(s.DB).IsArchived(ctx, wiID)
+		require.NoError(t, err)
+		assert.True(t, archived)
+		assert.Equal(t, 2, s.countRevisions(t, workitem.Revision{}.TableName(), "work_item_id", wiID))
+	})
+
+	s.T().Run("another instance enforcing the policies", func(t *testing.T) {
+		conn, err := s.DB.DB().Conn(ctx)
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", retention.AdvisoryLockID)
+		require.NoError(t, err)
+		defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", retention.AdvisoryLockID)
+		// when
+		res, err := enforcer.Enforce(ctx)
+		// then
+		require.NoError(t, err)
+		assert.True(t, res.Skipped)
+		assert.Equal(t, int64(0), res.Archived+res.Restored+res.CompactedRevisions)
+	})
+
+	s.T().Run("restore when the policy stops archiving", func(t *testing.T) {
+		p.ArchiveClosedAfterMonths = 0
+		require.NoError(t, policies.Save(ctx, &p))
+		res, err := enforcer.Enforce(ctx)
+		require.NoError(t, err)
+		assert.True(t, res.Restored >= 1)
+		archived, err := retention.NewRepository(s.DB).IsArchived(ctx, wiID)
+		require.NoError(t, err)
+		assert.False(t, archived)
+	})
+}
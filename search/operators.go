@@ -0,0 +1,22 @@
+package search
+
+import "github.com/fabric8-services/fabric8-wit/workitem"
+
+// OperatorsForKind returns the operators of the query language that can be
+// applied to a work item field of the given kind. Every field can also be
+// compared to null with $EQ.
+func OperatorsForKind(kind workitem.Kind) []string {
+	switch kind {
+	case workitem.KindString, workitem.KindURL:
+		return []string{EQ, NE, IN, SUBSTR}
+	case workitem.KindMarkup:
+		// markup is stored as an object so only its text can be matched
+		return []string{SUBSTR}
+	case workitem.KindBoolean, workitem.KindInteger, workitem.KindFloat, workitem.KindInstant, workitem.KindDuration:
+		return []string{EQ, NE}
+	default:
+		// enums, lists and references to users, iterations, areas, labels or
+		// codebases
+		return []string{EQ, NE, IN}
+	}
+}
@@ -0,0 +1,25 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/search"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperatorsForKind(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	for kind, operators := range map[workitem.Kind][]string{
+		workitem.KindString: {search.EQ, search.NE, search.IN, search.SUBSTR},
+		workitem.KindMarkup: {search.SUBSTR},
+		workitem.KindFloat:  {search.EQ, search.NE},
+		workitem.KindEnum:   {search.EQ, search.NE, search.IN},
+		workitem.KindUser:   {search.EQ, search.NE, search.IN},
+		workitem.KindList:   {search.EQ, search.NE, search.IN},
+	} {
+		assert.Equal(t, operators, search.OperatorsForKind(kind), kind.String())
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	c "github.com/fabric8-services/fabric8-wit/criteria"
+	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/resource"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/stretchr/testify/assert"
@@ -319,17 +320,34 @@ func TestParseMap(t *testing.T) {
 	t.Run(OPTS, func(t *testing.T) {
 		t.Parallel()
 		// given
-		input := fmt.Sprintf(`{"%s": {"parent-exists": true, "tree-view": true}}`, OPTS)
+		input := fmt.Sprintf(`{"%s": {"parent-exists": true, "tree-view": true, "archived": true}}`, OPTS)
 		// Parsing/Unmarshalling JSON encoding/json
 		fm := map[string]interface{}{}
 		err := json.Unmarshal([]byte(input), &fm)
 		require.NoError(t, err)
 		// when
-		actualOptions := parseOptions(fm)
+		actualOptions, err := parseOptions(fm)
 		// then
-		expectedOptions := &QueryOptions{ParentExists: true, TreeView: true}
+		require.NoError(t, err)
+		expectedOptions := &QueryOptions{ParentExists: true, TreeView: true, Archived: true}
 		assert.Equal(t, expectedOptions, actualOptions)
 	})
+	t.Run(OPTS+" not boolean", func(t *testing.T) {
+		t.Parallel()
+		for _, opt := range []string{"parent-exists", "tree-view", "archived"} {
+			// given
+			input := fmt.Sprintf(`{"%s": {"%s": "true"}}`, OPTS, opt)
+			fm := map[string]interface{}{}
+			err := json.Unmarshal([]byte(input), &fm)
+			require.NoError(t, err)
+			// when
+			actualOptions, err := parseOptions(fm)
+			// then
+			require.Error(t, err, opt)
+			assert.IsType(t, errors.BadParameterError{}, err, opt)
+			assert.Nil(t, actualOptions, opt)
+		}
+	})
 	t.Run(OPTS+" complex query", func(t *testing.T) {
 		t.Parallel()
 		// given
@@ -339,7 +357,8 @@ func TestParseMap(t *testing.T) {
 		err := json.Unmarshal([]byte(input), &fm)
 		require.NoError(t, err)
 		// when
-		options := parseOptions(fm)
+		options, err := parseOptions(fm)
+		require.NoError(t, err)
 		actualQuery := Query{Options: options}
 
 		// then
@@ -382,6 +401,26 @@ func TestParseFilterString(t *testing.T) {
 		expectedOptions := &QueryOptions{ParentExists: true, TreeView: true}
 		assert.Equal(t, expectedOptions, options)
 	})
+	t.Run("option not boolean", func(t *testing.T) {
+		input := fmt.Sprintf(`{"title":"some","%s":{"archived":"true"}}`, OPTS)
+		actualExpr, options, err := ParseFilterString(context.Background(), input)
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, err)
+		assert.Nil(t, actualExpr)
+		assert.Nil(t, options)
+	})
+	t.Run("field name with SQL", func(t *testing.T) {
+		for _, input := range []string{
+			`{"fields.x') OR 1=1 --":null}`,
+			`{"fields.x\" : 1}' OR 1=1 --":"foo"}`,
+			`{"fields.x') OR 1=1 --":{"$SUBSTR":"foo"}}`,
+		} {
+			actualExpr, _, err := ParseFilterString(context.Background(), input)
+			require.Error(t, err, input)
+			require.IsType(t, errors.BadParameterError{}, err, input)
+			require.Nil(t, actualExpr, input)
+		}
+	})
 }
 
 func TestGenerateExpression(t *testing.T) {
@@ -393,7 +432,7 @@ func TestGenerateExpression(t *testing.T) {
 		spaceName := "openshiftio"
 		q := Query{Name: "space", Value: &spaceName}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(nil)
 		// then
 		expectedExpr := c.Equals(
 			c.Field("SpaceID"),
@@ -408,7 +447,7 @@ func TestGenerateExpression(t *testing.T) {
 		spaceName := "openshiftio"
 		q := Query{Name: "space", Value: &spaceName, Negate: true}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(nil)
 		// then
 		expectedExpr := c.Not(
 			c.Field("SpaceID"),
@@ -429,7 +468,7 @@ func TestGenerateExpression(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(nil)
 		// then
 		expectedExpr := c.And(
 			c.Equals(
@@ -457,7 +496,7 @@ func TestGenerateExpression(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(nil)
 		// then
 		expectedExpr := c.Or(
 			c.Equals(
@@ -485,7 +524,7 @@ func TestGenerateExpression(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(nil)
 		// then
 		expectedExpr := c.And(
 			c.Not(
@@ -512,7 +551,7 @@ func TestGenerateExpression(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(nil)
 		// then
 		expectedExpr := c.And(
 			c.Equals(
@@ -532,7 +571,7 @@ func TestGenerateExpression(t *testing.T) {
 			Name: "assignee", Value: nil,
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(nil)
 		// then
 		expectedExpr := c.IsNull("system.assignees")
 
@@ -546,7 +585,7 @@ func TestGenerateExpression(t *testing.T) {
 			Name: "assignee", Value: nil, Negate: true,
 		}
 		// when
-		actualExpr, err := q.generateExpression()
+		actualExpr, err := q.generateExpression(nil)
 		// then
 		require.Error(t, err)
 		require.Nil(t, actualExpr)
@@ -565,7 +604,7 @@ func TestGenerateExpression(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, err := q.generateExpression()
+		actualExpr, err := q.generateExpression(nil)
 		// then
 		require.Error(t, err)
 		require.Nil(t, actualExpr)
@@ -600,7 +639,7 @@ func TestWorkItemNumber(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(nil)
 		// then
 		expectedExpr := c.And(
 			c.Equals(
@@ -614,6 +653,65 @@ func TestWorkItemNumber(t *testing.T) {
 		)
 		expectEqualExpr(t, expectedExpr, actualExpr)
 	})
+
+	t.Run("custom field", func(t *testing.T) {
+		t.Parallel()
+		t.Run("string", func(t *testing.T) {
+			t.Parallel()
+			// given
+			priority := "Critical"
+			q := Query{Name: "fields.priority", Value: &priority}
+			// when
+			actualExpr, err := q.generateExpression(nil)
+			// then
+			require.NoError(t, err)
+			expectEqualExpr(t, c.Equals(c.Field("fields.priority"), c.Literal(priority)), actualExpr)
+		})
+		t.Run("number", func(t *testing.T) {
+			t.Parallel()
+			// given
+			effort := "3.5"
+			q := Query{Name: "fields.effort", Value: &effort, Negate: true}
+			// when
+			actualExpr, err := q.generateExpression(map[string]workitem.Kind{"effort": workitem.KindFloat})
+			// then
+			require.NoError(t, err)
+			expectEqualExpr(t, c.Not(c.Field("fields.effort"), c.Literal(3.5)), actualExpr)
+		})
+		t.Run("number in a string field", func(t *testing.T) {
+			t.Parallel()
+			// given
+			version := "2"
+			q := Query{Name: "fields.version", Value: &version}
+			// when
+			actualExpr, err := q.generateExpression(map[string]workitem.Kind{"version": workitem.KindString})
+			// then
+			require.NoError(t, err)
+			expectEqualExpr(t, c.Equals(c.Field("fields.version"), c.Literal(version)), actualExpr)
+		})
+		t.Run("number in an undefined field", func(t *testing.T) {
+			t.Parallel()
+			// given
+			effort := "3.5"
+			q := Query{Name: "fields.effort", Value: &effort}
+			// when
+			actualExpr, err := q.generateExpression(nil)
+			// then
+			require.NoError(t, err)
+			expectEqualExpr(t, c.Equals(c.Field("fields.effort"), c.Literal(effort)), actualExpr)
+		})
+		t.Run("substring of a number", func(t *testing.T) {
+			t.Parallel()
+			// given
+			effort := "3"
+			q := Query{Name: "fields.effort", Value: &effort, Substring: true}
+			// when
+			actualExpr, err := q.generateExpression(nil)
+			// then
+			require.NoError(t, err)
+			expectEqualExpr(t, c.Substring(c.Field("fields.effort"), c.Literal(effort)), actualExpr)
+		})
+	})
 }
 
 func TestGenerateExpressionWithNonExistingKey(t *testing.T) {
@@ -624,7 +722,7 @@ func TestGenerateExpressionWithNonExistingKey(t *testing.T) {
 		// given
 		q := Query{}
 		// when
-		actualExpr, err := q.generateExpression()
+		actualExpr, err := q.generateExpression(nil)
 		// then
 		require.Error(t, err)
 		require.Nil(t, actualExpr)
@@ -635,7 +733,19 @@ func TestGenerateExpressionWithNonExistingKey(t *testing.T) {
 		spaceName := "openshiftio"
 		q := Query{Name: "", Value: &spaceName}
 		// when
-		actualExpr, err := q.generateExpression()
+		actualExpr, err := q.generateExpression(nil)
+		// then
+		require.Error(t, err)
+		require.Nil(t, actualExpr)
+	})
+
+	t.Run("Prefix without field name", func(t *testing.T) {
+		t.Parallel()
+		// given
+		value := "foo"
+		q := Query{Name: "fields.", Value: &value}
+		// when
+		actualExpr, err := q.generateExpression(nil)
 		// then
 		require.Error(t, err)
 		require.Nil(t, actualExpr)
@@ -647,7 +757,7 @@ func TestGenerateExpressionWithNonExistingKey(t *testing.T) {
 		spaceName := "openshiftio"
 		q := Query{Name: "nonexistingkey", Value: &spaceName}
 		// when
-		actualExpr, err := q.generateExpression()
+		actualExpr, err := q.generateExpression(nil)
 		// then
 		require.Error(t, err)
 		require.Nil(t, actualExpr)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -13,10 +14,13 @@ import (
 
 	"github.com/asaskevich/govalidator"
 	"github.com/davecgh/go-spew/spew"
+	"github.com/fabric8-services/fabric8-wit/comment"
 	"github.com/fabric8-services/fabric8-wit/criteria"
 	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/id"
 	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/retention"
+	"github.com/fabric8-services/fabric8-wit/space"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/link"
 	"github.com/jinzhu/gorm"
@@ -43,6 +47,12 @@ const (
 
 	OptParentExistsKey = "parent-exists"
 	OptTreeViewKey     = "tree-view"
+	// OptArchivedKey includes the archived work items in the results
+	OptArchivedKey = "archived"
+
+	// archivedKeyword includes the archived work items in the results of a
+	// full text search
+	archivedKeyword = "archived:true"
 )
 
 // GormSearchRepository provides a Gorm based repository
@@ -65,6 +75,8 @@ type searchKeyword struct {
 	workItemTypes []uuid.UUID
 	number        []string
 	words         []string
+	// archived is true if the archived work items must be searched too
+	archived bool
 }
 
 // KnownURL has a regex string format URL and compiled regex for the same
@@ -231,7 +243,9 @@ func parseSearchString(ctx context.Context, rawSearchString string) (searchKeywo
 		}
 		// IF part is for search with number:1234
 		// TODO: need to find out the way to use ID fields.
-		if strings.HasPrefix(part, "number:") {
+		if part == archivedKeyword {
+			res.archived = true
+		} else if strings.HasPrefix(part, "number:") {
 			res.number = append(res.number, strings.TrimPrefix(part, "number:")+":*A")
 		} else if strings.HasPrefix(part, "type:") {
 			typeIDStr := strings.TrimPrefix(part, "type:")
@@ -319,22 +333,34 @@ func parseMap(queryMap map[string]interface{}, q *Query) {
 	}
 }
 
-func parseOptions(queryMap map[string]interface{}) *QueryOptions {
+// parseOptions returns the options of the query, if any, or a bad parameter
+// error if an option is not a boolean
+func parseOptions(queryMap map[string]interface{}) (*QueryOptions, error) {
 	for key, val := range queryMap {
 		if ifArr, ok := val.(map[string]interface{}); key == OPTS && ok {
 			options := QueryOptions{}
 			for k, v := range ifArr {
+				var opt *bool
 				switch k {
 				case OptParentExistsKey:
-					options.ParentExists = v.(bool)
+					opt = &options.ParentExists
 				case OptTreeViewKey:
-					options.TreeView = v.(bool)
+					opt = &options.TreeView
+				case OptArchivedKey:
+					opt = &options.Archived
+				default:
+					continue
 				}
+				b, ok := v.(bool)
+				if !ok {
+					return nil, errors.NewBadParameterError(OPTS+"."+k, v).Expected("boolean")
+				}
+				*opt = b
 			}
-			return &options
+			return &options, nil
 		}
 	}
-	return nil
+	return nil, nil
 }
 
 func parseArray(anArray []interface{}, l *[]Query) {
@@ -351,6 +377,9 @@ func parseArray(anArray []interface{}, l *[]Query) {
 type QueryOptions struct {
 	TreeView     bool
 	ParentExists bool
+	// Archived includes the work items archived by the retention policy of
+	// their space, which are excluded by default
+	Archived bool
 }
 
 // Query represents tree structure of the filter query
@@ -396,44 +425,85 @@ var searchKeyMap = map[string]string{
 	"workitemtype": "Type", // same as 'type' - added for compatibility. (Ref. #1564)
 	"space":        "SpaceID",
 	"number":       "Number",
+	"deployed":     workitem.DeployedEnvironment,
 }
 
-func (q Query) determineLiteralType(key string, val string) criteria.Expression {
+// customFieldKeyRegex matches the keys of the work item fields that can be
+// searched for. The field name is pasted into the SQL query, so it must not
+// contain anything besides letters, digits, underscores and dots.
+var customFieldKeyRegex = regexp.MustCompile(`^` + regexp.QuoteMeta(workitem.JSONFieldPrefix) + `[A-Za-z0-9_.]+$`)
+
+// searchKey returns the field to compare against for the given key of a
+// query. Besides the known search keys and the fields handled by a table join,
+// any work item field can be searched for by prefixing its name with
+// workitem.JSONFieldPrefix (e.g. "fields.priority").
+func searchKey(name string) (string, error) {
+	if key, ok := searchKeyMap[name]; ok {
+		return key, nil
+	}
+	// check that none of the default table joins handles this column:
+	for _, j := range workitem.DefaultTableJoins() {
+		if j.HandlesFieldName(name) {
+			return name, nil
+		}
+	}
+	if customFieldKeyRegex.MatchString(name) {
+		return name, nil
+	}
+	return "", errors.NewBadParameterError("key not found", name)
+}
+
+// fieldNames returns the names of the work item fields that the query and its
+// children compare against, without the workitem.JSONFieldPrefix
+func (q Query) fieldNames() []string {
+	var names []string
+	if strings.HasPrefix(q.Name, workitem.JSONFieldPrefix) {
+		names = append(names, strings.TrimPrefix(q.Name, workitem.JSONFieldPrefix))
+	}
+	for _, child := range q.Children {
+		names = append(names, child.fieldNames()...)
+	}
+	return names
+}
+
+// determineLiteralType returns the literal to compare the given key against.
+// The values of the work item fields are compared as strings unless the
+// given kinds, which map the field names to the kind of their definition,
+// tell that the field holds numbers (e.g. "effort").
+func (q Query) determineLiteralType(key string, val string, kinds map[string]workitem.Kind) criteria.Expression {
 	switch key {
 	case workitem.SystemAssignees, workitem.SystemLabels:
 		return criteria.Literal([]string{val})
 	default:
+		if strings.HasPrefix(key, workitem.JSONFieldPrefix) {
+			switch kinds[strings.TrimPrefix(key, workitem.JSONFieldPrefix)] {
+			case workitem.KindInteger, workitem.KindFloat:
+				if f, err := strconv.ParseFloat(val, 64); err == nil {
+					return criteria.Literal(f)
+				}
+			}
+		}
 		return criteria.Literal(val)
 	}
 }
 
-func (q Query) generateExpression() (criteria.Expression, error) {
+func (q Query) generateExpression(kinds map[string]workitem.Kind) (criteria.Expression, error) {
 	var myexpr []criteria.Expression
 	currentOperator := q.Name
 
 	if !isOperator(currentOperator) || currentOperator == OPTS {
-		key, ok := searchKeyMap[q.Name]
-		// check that none of the default table joins handles this column:
-		var handledByJoin bool
-		joins := workitem.DefaultTableJoins()
-		for _, j := range joins {
-			if j.HandlesFieldName(q.Name) {
-				handledByJoin = true
-				key = q.Name
-				break
-			}
-		}
-		if !ok && !handledByJoin {
-			return nil, errors.NewBadParameterError("key not found", q.Name)
+		key, err := searchKey(q.Name)
+		if err != nil {
+			return nil, err
 		}
 		left := criteria.Field(key)
 		if q.Value != nil {
-			right := q.determineLiteralType(key, *q.Value)
+			right := q.determineLiteralType(key, *q.Value, kinds)
 			if q.Negate {
 				myexpr = append(myexpr, criteria.Not(left, right))
 			} else {
 				if q.Substring {
-					myexpr = append(myexpr, criteria.Substring(left, right))
+					myexpr = append(myexpr, criteria.Substring(left, criteria.Literal(*q.Value)))
 				} else {
 					myexpr = append(myexpr, criteria.Equals(left, right))
 				}
@@ -447,34 +517,24 @@ func (q Query) generateExpression() (criteria.Expression, error) {
 	}
 	for _, child := range q.Children {
 		if isOperator(child.Name) || currentOperator == OPTS {
-			exp, err := child.generateExpression()
+			exp, err := child.generateExpression(kinds)
 			if err != nil {
 				return nil, err
 			}
 			myexpr = append(myexpr, exp)
 		} else {
-			key, ok := searchKeyMap[child.Name]
-			// check that none of the default table joins handles this column:
-			var handledByJoin bool
-			joins := workitem.DefaultTableJoins()
-			for _, j := range joins {
-				if j.HandlesFieldName(child.Name) {
-					handledByJoin = true
-					key = child.Name
-					break
-				}
-			}
-			if !ok && !handledByJoin {
-				return nil, errors.NewBadParameterError("key not found", child.Name)
+			key, err := searchKey(child.Name)
+			if err != nil {
+				return nil, err
 			}
 			left := criteria.Field(key)
 			if child.Value != nil {
-				right := q.determineLiteralType(key, *child.Value)
+				right := q.determineLiteralType(key, *child.Value, kinds)
 				if child.Negate {
 					myexpr = append(myexpr, criteria.Not(left, right))
 				} else {
 					if child.Substring {
-						myexpr = append(myexpr, criteria.Substring(left, right))
+						myexpr = append(myexpr, criteria.Substring(left, criteria.Literal(*child.Value)))
 					} else {
 						myexpr = append(myexpr, criteria.Equals(left, right))
 					}
@@ -516,8 +576,8 @@ func (q Query) generateExpression() (criteria.Expression, error) {
 	return res, nil
 }
 
-// ParseFilterString accepts a raw string and generates a criteria expression
-func ParseFilterString(ctx context.Context, rawSearchString string) (criteria.Expression, *QueryOptions, error) {
+// parseQuery parses the raw string into a query and its options
+func parseQuery(ctx context.Context, rawSearchString string) (*Query, error) {
 	fm := map[string]interface{}{}
 	// Parsing/Unmarshalling JSON encoding/json
 	err := json.Unmarshal([]byte(rawSearchString), &fm)
@@ -527,17 +587,90 @@ func ParseFilterString(ctx context.Context, rawSearchString string) (criteria.Ex
 			"err":             err,
 			"rawSearchString": rawSearchString,
 		}, "failed to unmarshal raw search string")
-		return nil, nil, errors.NewBadParameterError("expression", rawSearchString+": "+err.Error())
+		return nil, errors.NewBadParameterError("expression", rawSearchString+": "+err.Error())
 	}
 	q := Query{}
 	parseMap(fm, &q)
 
-	q.Options = parseOptions(fm)
+	q.Options, err = parseOptions(fm)
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
 
-	exp, err := q.generateExpression()
+// ParseFilterString accepts a raw string and generates a criteria expression.
+// The values of the work item fields (e.g. "fields.effort") are compared as
+// strings, use GormSearchRepository.Filter to compare them with the type of
+// their definition.
+func ParseFilterString(ctx context.Context, rawSearchString string) (criteria.Expression, *QueryOptions, error) {
+	q, err := parseQuery(ctx, rawSearchString)
+	if err != nil {
+		return nil, nil, err
+	}
+	exp, err := q.generateExpression(nil)
+	return exp, q.Options, err
+}
+
+// parseFilterString is like ParseFilterString, except that the values of the
+// work item fields are compared with the type of their definition in the work
+// item types (e.g. as numbers for "fields.effort").
+func (r *GormSearchRepository) parseFilterString(ctx context.Context, rawSearchString string) (criteria.Expression, *QueryOptions, error) {
+	q, err := parseQuery(ctx, rawSearchString)
+	if err != nil {
+		return nil, nil, err
+	}
+	kinds, err := r.loadFieldKinds(ctx, q.fieldNames())
+	if err != nil {
+		return nil, nil, err
+	}
+	exp, err := q.generateExpression(kinds)
 	return exp, q.Options, err
 }
 
+// loadFieldKinds returns the kind of the definition of the given work item
+// fields in the work item types. A field defined with different kinds by
+// several work item types is only numeric if all of them are numeric, and is
+// a string otherwise.
+func (r *GormSearchRepository) loadFieldKinds(ctx context.Context, names []string) (map[string]workitem.Kind, error) {
+	kinds := map[string]workitem.Kind{}
+	if len(names) == 0 {
+		return kinds, nil
+	}
+	rows, err := r.db.Raw(`SELECT DISTINCT f.key, coalesce(f.value->'type'->>'kind', f.value->'type'->'simple_type'->>'kind', '')
+		FROM `+workitem.WorkItemType{}.TableName()+` wit, jsonb_each(wit.fields) f
+		WHERE wit.deleted_at IS NULL AND f.key IN (?)`, names).Rows()
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"fields": names,
+			"err":    err,
+		}, "unable to load the kinds of the work item fields")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	defer closeable.Close(ctx, rows)
+	for rows.Next() {
+		var name string
+		var kind workitem.Kind
+		if err := rows.Scan(&name, &kind); err != nil {
+			return nil, errors.NewInternalError(ctx, err)
+		}
+		known, ok := kinds[name]
+		switch {
+		case !ok:
+			kinds[name] = kind
+		case known == kind:
+		case (known == workitem.KindInteger || known == workitem.KindFloat) && (kind == workitem.KindInteger || kind == workitem.KindFloat):
+			kinds[name] = workitem.KindFloat
+		default:
+			kinds[name] = workitem.KindString
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return kinds, nil
+}
+
 // generateSQLSearchInfo accepts searchKeyword and join them in a way that can be used in sql
 func generateSQLSearchInfo(keywords searchKeyword) (sqlParameter string) {
 	numberStr := strings.Join(keywords.number, " & ")
@@ -554,8 +687,11 @@ func generateSQLSearchInfo(keywords searchKeyword) (sqlParameter string) {
 
 // extracted this function from List() in order to close the rows object with "defer" for more readability
 // workaround for https://github.com/lib/pq/issues/81
-func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []uuid.UUID, start *int, limit *int, spaceID *string) ([]workitem.WorkItemStorage, int, error) {
+func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []uuid.UUID, archived bool, start *int, limit *int, spaceID *string) ([]workitem.WorkItemStorage, int, error) {
 	db := r.db.Model(workitem.WorkItemStorage{}).Where("tsv @@ query")
+	if !archived {
+		db = db.Where(notArchived)
+	}
 	if start != nil {
 		if *start < 0 {
 			return nil, 0, errors.NewBadParameterError("start", *start)
@@ -577,10 +713,13 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 		db = db.Where(query, workItemTypes)
 	}
 
-	db = db.Select("count(*) over () as cnt2 , *").Order("execution_order desc")
-	db = db.Joins(", to_tsquery('english', ?) as query, ts_rank(tsv, query) as rank", sqlSearchQueryParameter)
+	// the query is parsed with the text search configuration of the space of
+	// each work item
+	db = db.Select(fmt.Sprintf("count(*) over () as cnt2 , %s.*", workitem.WorkItemStorage{}.TableName())).Order("execution_order desc")
+	db = db.Joins(fmt.Sprintf("JOIN %[1]s ON %[1]s.id = %[2]s.space_id, to_tsquery(%[1]s.search_config, ?) as query, ts_rank(tsv, query) as rank",
+		space.Space{}.TableName(), workitem.WorkItemStorage{}.TableName()), sqlSearchQueryParameter)
 	if spaceID != nil {
-		db = db.Where("space_id=?", *spaceID)
+		db = db.Where(fmt.Sprintf("%s.space_id=?", workitem.WorkItemStorage{}.TableName()), *spaceID)
 	}
 	db = db.Order(fmt.Sprintf("rank desc,%s.updated_at desc", workitem.WorkItemStorage{}.TableName()))
 
@@ -634,7 +773,8 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 	//*/
 }
 
-// SearchFullText Search returns work items for the given query
+// SearchFullText Search returns work items for the given query. The archived
+// work items are searched only if the query contains "archived:true".
 func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchString string, start *int, limit *int, spaceID *string) ([]workitem.WorkItem, int, error) {
 	// parse
 	// generateSearchQuery
@@ -647,7 +787,7 @@ func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchStri
 	sqlSearchQueryParameter := generateSQLSearchInfo(parsedSearchDict)
 	var rows []workitem.WorkItemStorage
 	log.Debug(ctx, map[string]interface{}{"search query": sqlSearchQueryParameter}, "searching for work items")
-	rows, count, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, start, limit, spaceID)
+	rows, count, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, parsedSearchDict.archived, start, limit, spaceID)
 	if err != nil {
 		return nil, 0, errs.WithStack(err)
 	}
@@ -675,14 +815,82 @@ func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchStri
 	return result, count, nil
 }
 
-func (r *GormSearchRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, parentExists *bool, start *int, limit *int) ([]workitem.WorkItemStorage, int, error) {
+// Highlight holds the fragments of a work item that match a full text search,
+// with the matching words highlighted. A fragment is nil if the corresponding
+// text doesn't match.
+type Highlight struct {
+	Title       *string
+	Description *string
+	// Comment is taken from the best matching comment of the work item
+	Comment *string
+}
+
+// Highlights returns the highlighted fragments of the given work items (e.g.
+// the ones returned by SearchFullText) that match the given search string.
+func (r *GormSearchRepository) Highlights(ctx context.Context, rawSearchString string, ids []uuid.UUID) (map[uuid.UUID]Highlight, error) {
+	result := map[uuid.UUID]Highlight{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	parsedSearchDict, err := parseSearchString(ctx, rawSearchString)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	sqlSearchQueryParameter := generateSQLSearchInfo(parsedSearchDict)
+	if sqlSearchQueryParameter == "" {
+		return result, nil
+	}
+	query := fmt.Sprintf(`SELECT w.id,
+			CASE WHEN to_tsvector(s.search_config, coalesce(w.fields->>'system.title', '')) @@ q.query
+				THEN ts_headline(s.search_config, w.fields->>'system.title', q.query) END,
+			CASE WHEN to_tsvector(s.search_config, coalesce(w.fields#>>'{system.description, content}', '')) @@ q.query
+				THEN ts_headline(s.search_config, w.fields#>>'{system.description, content}', q.query) END,
+			(SELECT ts_headline(s.search_config, c.body, q.query) FROM %[3]s c
+				WHERE c.parent_id = w.id AND c.deleted_at IS NULL AND to_tsvector(s.search_config, c.body) @@ q.query
+				ORDER BY ts_rank(to_tsvector(s.search_config, c.body), q.query) DESC, c.created_at
+				LIMIT 1)
+		FROM %[1]s w JOIN %[2]s s ON s.id = w.space_id, to_tsquery(s.search_config, ?) AS q(query)
+		WHERE w.id IN (?)`,
+		workitem.WorkItemStorage{}.TableName(), space.Space{}.TableName(), comment.Comment{}.TableName())
+	rows, err := r.db.Raw(query, sqlSearchQueryParameter, ids).Rows()
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":          err,
+			"search_query": sqlSearchQueryParameter,
+		}, "failed to highlight the search results")
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to highlight the search results"))
+	}
+	defer closeable.Close(ctx, rows)
+	for rows.Next() {
+		var id uuid.UUID
+		var h Highlight
+		if err := rows.Scan(&id, &h.Title, &h.Description, &h.Comment); err != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to scan the highlighted search results"))
+		}
+		result[id] = h
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to read the highlighted search results"))
+	}
+	return result, nil
+}
+
+// notArchived is the condition excluding the work items archived by the
+// retention policy of their space
+var notArchived = fmt.Sprintf(`NOT EXISTS (
+	SELECT 1 FROM %[1]s a WHERE a.work_item_id = %[2]s.id)`,
+	retention.Archive{}.TableName(), workitem.WorkItemStorage{}.TableName())
+
+// filterQuery returns the query selecting the work items matching the given
+// expression, without the archived ones unless requested
+func (r *GormSearchRepository) filterQuery(ctx context.Context, criteria criteria.Expression, parentExists *bool, archived bool) (*gorm.DB, error) {
 	where, parameters, joins, compileError := workitem.Compile(criteria)
 	if compileError != nil {
 		log.Error(ctx, map[string]interface{}{
 			"err":        compileError,
 			"expression": criteria,
 		}, "failed to compile expression")
-		return nil, 0, errors.NewBadParameterError("expression", criteria)
+		return nil, errors.NewBadParameterError("expression", criteria)
 	}
 
 	if parentExists != nil && !*parentExists {
@@ -693,15 +901,26 @@ func (r *GormSearchRepository) listItemsFromDB(ctx context.Context, criteria cri
 				AND wil.target_id = work_items.id
 				AND wil.deleted_at IS NULL)`, link.SystemWorkItemLinkTypeParentChildID)
 	}
+	if !archived {
+		where += " AND " + notArchived
+	}
 
 	db := r.db.Model(&workitem.WorkItemStorage{}).Where(where, parameters...)
 	for _, j := range joins {
 		if err := j.Validate(db); err != nil {
 			log.Error(ctx, map[string]interface{}{"expression": criteria, "err": err}, "table join not valid")
-			return nil, 0, errors.NewBadParameterError("expression", criteria).Expected("valid table join")
+			return nil, errors.NewBadParameterError("expression", criteria).Expected("valid table join")
 		}
 		db = db.Joins(j.GetJoinExpression())
 	}
+	return db, nil
+}
+
+func (r *GormSearchRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, parentExists *bool, archived bool, start *int, limit *int) ([]workitem.WorkItemStorage, int, error) {
+	db, err := r.filterQuery(ctx, criteria, parentExists, archived)
+	if err != nil {
+		return nil, 0, err
+	}
 	orgDB := db
 	if start != nil {
 		if *start < 0 {
@@ -775,16 +994,17 @@ func (r *GormSearchRepository) listItemsFromDB(ctx context.Context, criteria cri
 }
 
 // Filter returns the work items matching the search as well as their count. If
-// the filter did specify the "tree-view" option to be "true", then we will also
+// the filter did specify the "tree-view" option to be true, then we will also
 // create a list of ancestors as well as a list of links. The ancestors exist in
 // order to list the parent of each matching work item up to its root work item.
 // The child links are there in order to know what siblings to load for matching
-// work items.
+// work items. The archived work items are left out unless the "archived" option
+// is true.
 func (r *GormSearchRepository) Filter(ctx context.Context, rawFilterString string, parentExists *bool, start *int, limit *int) (matches []workitem.WorkItem, count int, ancestors link.AncestorList, childLinks link.WorkItemLinkList, err error) {
 	// parse
 	// generateSearchQuery
 	// ....
-	exp, opts, err := ParseFilterString(ctx, rawFilterString)
+	exp, opts, err := r.parseFilterString(ctx, rawFilterString)
 	if err != nil {
 		return nil, 0, nil, nil, errs.Wrap(err, "failed to parse filter string")
 	}
@@ -801,7 +1021,7 @@ func (r *GormSearchRepository) Filter(ctx context.Context, rawFilterString strin
 		return nil, 0, nil, nil, errors.NewBadParameterError("rawFilterString", rawFilterString)
 	}
 
-	result, count, err := r.listItemsFromDB(ctx, exp, parentExists, start, limit)
+	result, count, err := r.listItemsFromDB(ctx, exp, parentExists, opts != nil && opts.Archived, start, limit)
 	if err != nil {
 		return nil, 0, nil, nil, errs.WithStack(err)
 	}
@@ -853,24 +1073,71 @@ func (r *GormSearchRepository) Filter(ctx context.Context, rawFilterString strin
 		}
 	}
 
-	matches = make([]workitem.WorkItem, len(result))
-	for index, value := range result {
+	matches, err = r.convertToModel(ctx, result)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+	return matches, count, ancestors, childLinks, nil
+}
+
+// FilterAfter returns at most limit work items matching the filter that come
+// after the given work item in the order of the work item lists, or the first
+// ones if it is nil. It allows going through all the matching work items
+// without loading them at once.
+func (r *GormSearchRepository) FilterAfter(ctx context.Context, rawFilterString string, after *workitem.WorkItem, limit int) ([]workitem.WorkItem, error) {
+	exp, opts, err := r.parseFilterString(ctx, rawFilterString)
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to parse filter string")
+	}
+	if exp == nil {
+		return nil, errors.NewBadParameterError("rawFilterString", rawFilterString)
+	}
+	if limit <= 0 {
+		return nil, errors.NewBadParameterError("limit", limit)
+	}
+	db, err := r.filterQuery(ctx, exp, nil, opts != nil && opts.Archived)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		order, ok := after.Fields[workitem.SystemOrder].(float64)
+		if !ok {
+			return nil, errors.NewBadParameterError("after", after.ID).Expected("work item with an execution order")
+		}
+		db = db.Where("(work_items.execution_order, work_items.id) < (?, ?)", order, after.ID)
+	}
+	var result []workitem.WorkItemStorage
+	err = db.Select("work_items.*").Order("work_items.execution_order desc, work_items.id desc").Limit(limit).Find(&result).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"expression": exp,
+			"err":        err,
+		}, "failed to filter the work items")
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to filter the work items"))
+	}
+	return r.convertToModel(ctx, result)
+}
+
+// convertToModel converts the stored work items to their model representation
+func (r *GormSearchRepository) convertToModel(ctx context.Context, items []workitem.WorkItemStorage) ([]workitem.WorkItem, error) {
+	res := make([]workitem.WorkItem, len(items))
+	for index, value := range items {
 		wiType, err := r.witr.Load(ctx, value.Type)
 		if err != nil {
 			log.Error(ctx, map[string]interface{}{
 				"err": err,
 				"wit": value.Type,
 			}, "failed to load work item type")
-			return nil, 0, nil, nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to load work item type"))
+			return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to load work item type"))
 		}
 		modelWI, err := workitem.ConvertWorkItemStorageToModel(wiType, &value)
 		if err != nil {
 			log.Error(ctx, map[string]interface{}{
 				"err": err,
 			}, "failed to convert to storage to model")
-			return nil, 0, nil, nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to convert storage to model"))
+			return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to convert storage to model"))
 		}
-		matches[index] = *modelWI
+		res[index] = *modelWI
 	}
-	return matches, count, ancestors, childLinks, nil
+	return res, nil
 }
@@ -7,11 +7,14 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/fabric8-services/fabric8-wit/deployment"
 	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
 	"github.com/fabric8-services/fabric8-wit/id"
 	"github.com/fabric8-services/fabric8-wit/rendering"
 	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/retention"
 	"github.com/fabric8-services/fabric8-wit/search"
 	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
 	"github.com/fabric8-services/fabric8-wit/workitem"
@@ -375,6 +378,77 @@ func containsAllWorkItems(expectedWorkitems []workitem.WorkItem, actualWorkitems
 	}
 }
 
+func (s *searchRepositoryBlackboxTest) TestSearchFullTextConfigurationAndComments() {
+	s.T().Run("search configuration of the space", func(t *testing.T) {
+		// given "which" is an english stop word but not a "simple" one
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.Spaces(2, func(fxt *tf.TestFixture, idx int) error {
+				if idx == 1 {
+					fxt.Spaces[idx].SearchConfig = "simple"
+				}
+				return nil
+			}),
+			tf.WorkItems(2, func(fxt *tf.TestFixture, idx int) error {
+				fxt.WorkItems[idx].SpaceID = fxt.Spaces[idx].ID
+				fxt.WorkItems[idx].Fields[workitem.SystemTitle] = "which one"
+				return nil
+			}),
+		)
+		// when
+		res, count, err := s.searchRepo.SearchFullText(context.Background(), "which", nil, nil, nil)
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		assert.Equal(t, fxt.WorkItems[1].ID, res[0].ID)
+	})
+
+	s.T().Run("comments", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.WorkItems(2, tf.SetWorkItemTitles("about the zebra", "about the lion")),
+			tf.Comments(2, func(fxt *tf.TestFixture, idx int) error {
+				fxt.Comments[idx].ParentID = fxt.WorkItemByTitle("about the lion").ID
+				fxt.Comments[idx].Body = []string{"the lion met a zebra", "nothing to see"}[idx]
+				return nil
+			}),
+		)
+		spaceID := fxt.Spaces[0].ID.String()
+		zebra, lion := fxt.WorkItemByTitle("about the zebra").ID, fxt.WorkItemByTitle("about the lion").ID
+		// when
+		res, count, err := s.searchRepo.SearchFullText(context.Background(), "zebra", nil, nil, &spaceID)
+		// then the title outranks the comment
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		assert.Equal(t, zebra, res[0].ID)
+		assert.Equal(t, lion, res[1].ID)
+
+		t.Run("highlights", func(t *testing.T) {
+			// when
+			highlights, err := s.searchRepo.Highlights(context.Background(), "zebra", []uuid.UUID{zebra, lion})
+			// then
+			require.NoError(t, err)
+			require.Len(t, highlights, 2)
+			require.NotNil(t, highlights[zebra].Title)
+			assert.Equal(t, "about the <b>zebra</b>", *highlights[zebra].Title)
+			assert.Nil(t, highlights[zebra].Comment)
+			assert.Nil(t, highlights[lion].Title)
+			require.NotNil(t, highlights[lion].Comment)
+			assert.Equal(t, "the lion met a <b>zebra</b>", *highlights[lion].Comment)
+		})
+
+		t.Run("deleted comment", func(t *testing.T) {
+			// given
+			require.NoError(t, s.DB.Delete(fxt.Comments[0]).Error)
+			// when
+			res, count, err := s.searchRepo.SearchFullText(context.Background(), "zebra", nil, nil, &spaceID)
+			// then
+			require.NoError(t, err)
+			require.Equal(t, 1, count)
+			assert.Equal(t, zebra, res[0].ID)
+		})
+	})
+}
+
 func (s *searchRepositoryBlackboxTest) TestSearch() {
 
 	var start, limit int = 0, 100
@@ -641,3 +715,172 @@ func verify(t *testing.T, searchQuery string, searchResults []workitem.WorkItem,
 		}
 	}
 }
+
+func (s *searchRepositoryBlackboxTest) TestFilterAfter() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(2), tf.WorkItems(5, func(fxt *tf.TestFixture, idx int) error {
+		if idx == 4 {
+			fxt.WorkItems[idx].SpaceID = fxt.Spaces[1].ID
+		}
+		return nil
+	}))
+	filter := fmt.Sprintf(`{"space": "%s"}`, fxt.Spaces[0].ID)
+
+	s.T().Run("pages through the work items", func(t *testing.T) {
+		var after *workitem.WorkItem
+		var ids []uuid.UUID
+		for i := 0; i < 3; i++ {
+			// when
+			items, err := s.searchRepo.FilterAfter(context.Background(), filter, after, 3)
+			// then
+			require.NoError(t, err)
+			for _, wi := range items {
+				ids = append(ids, wi.ID)
+			}
+			if len(items) < 3 {
+				break
+			}
+			after = &items[len(items)-1]
+		}
+		// the work items are listed like the work item lists, newest first
+		require.Equal(t, []uuid.UUID{fxt.WorkItems[3].ID, fxt.WorkItems[2].ID, fxt.WorkItems[1].ID, fxt.WorkItems[0].ID}, ids)
+	})
+
+	s.T().Run("invalid filter", func(t *testing.T) {
+		// when
+		_, err := s.searchRepo.FilterAfter(context.Background(), `{"space": `, nil, 3)
+		// then
+		require.Error(t, err)
+	})
+}
+
+func (s *searchRepositoryBlackboxTest) TestFilterDeployed() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(4))
+	repo := deployment.NewRepository(s.DB)
+	for _, d := range []deployment.Deployment{
+		{Environment: "stage", WorkItemIDs: []uuid.UUID{fxt.WorkItems[0].ID, fxt.WorkItems[1].ID}},
+		{Environment: "production", WorkItemIDs: []uuid.UUID{fxt.WorkItems[0].ID, fxt.WorkItems[2].ID}},
+		{Environment: "production", WorkItemIDs: []uuid.UUID{fxt.WorkItems[0].ID}},
+	} {
+		d.SpaceID = fxt.Spaces[0].ID
+		d.AppName = "myapp"
+		d.Version = "1.0"
+		require.NoError(s.T(), repo.Create(context.Background(), &d))
+	}
+
+	testData := []struct {
+		name     string
+		filter   string
+		expected id.Slice
+	}{
+		// each work item is only found once even if it was deployed several times
+		{"deployed to", `{"deployed": "production"}`, id.Slice{fxt.WorkItems[0].ID, fxt.WorkItems[2].ID}},
+		{"not deployed to", `{"deployed": {"$NE": "production"}}`, id.Slice{fxt.WorkItems[1].ID, fxt.WorkItems[3].ID}},
+		{"deployed to matching", `{"deployed": {"$SUBSTR": "stag"}}`, id.Slice{fxt.WorkItems[0].ID, fxt.WorkItems[1].ID}},
+		{"never deployed", `{"deployed": null}`, id.Slice{fxt.WorkItems[3].ID}},
+	}
+	for _, d := range testData {
+		s.T().Run(d.name, func(t *testing.T) {
+			// when
+			filter := fmt.Sprintf(`{"$AND": [{"space": "%s"}, %s]}`, fxt.Spaces[0].ID, d.filter)
+			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, len(d.expected), count)
+			ids := make(id.Slice, len(res))
+			for i, wi := range res {
+				ids[i] = wi.ID
+			}
+			assert.ElementsMatch(t, d.expected, ids)
+		})
+	}
+}
+
+func (s *searchRepositoryBlackboxTest) TestArchived() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(2, tf.SetWorkItemTitles("archival one", "archival two")))
+	archive := retention.Archive{WorkItemID: fxt.WorkItems[0].ID, SpaceID: fxt.Spaces[0].ID, ArchivedAt: time.Now()}
+	require.NoError(s.T(), s.DB.Create(&archive).Error)
+	spaceID := fxt.Spaces[0].ID.String()
+
+	testData := []struct {
+		name     string
+		filter   string
+		expected id.Slice
+	}{
+		{"excluded by default", fmt.Sprintf(`{"space": "%s"}`, spaceID), id.Slice{fxt.WorkItems[1].ID}},
+		{"included on demand", fmt.Sprintf(`{"space": "%s", "$OPTS": {"archived": true}}`, spaceID), id.Slice{fxt.WorkItems[0].ID, fxt.WorkItems[1].ID}},
+	}
+	for _, d := range testData {
+		s.T().Run("filter "+d.name, func(t *testing.T) {
+			// when
+			res, count, _, _, err := s.searchRepo.Filter(context.Background(), d.filter, nil, nil, nil)
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, len(d.expected), count)
+			ids := make(id.Slice, len(res))
+			for i, wi := range res {
+				ids[i] = wi.ID
+			}
+			assert.ElementsMatch(t, d.expected, ids)
+		})
+	}
+
+	s.T().Run("full text excluded by default", func(t *testing.T) {
+		// when
+		res, count, err := s.searchRepo.SearchFullText(context.Background(), "archival", nil, nil, &spaceID)
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		assert.Equal(t, fxt.WorkItems[1].ID, res[0].ID)
+	})
+
+	s.T().Run("full text included on demand", func(t *testing.T) {
+		// when
+		_, count, err := s.searchRepo.SearchFullText(context.Background(), "archival archived:true", nil, nil, &spaceID)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+}
+
+func (s *searchRepositoryBlackboxTest) TestFilterCustomFields() {
+	// given
+	suffix := strings.Replace(uuid.NewV4().String(), "-", "_", -1)
+	version := "version_" + suffix
+	effort := "effort_" + suffix
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.WorkItemTypes(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItemTypes[idx].Fields[version] = workitem.FieldDefinition{Label: "Version", Type: workitem.SimpleType{Kind: workitem.KindString}}
+			fxt.WorkItemTypes[idx].Fields[effort] = workitem.FieldDefinition{Label: "Effort", Type: workitem.SimpleType{Kind: workitem.KindFloat}}
+			return nil
+		}),
+		tf.WorkItems(2, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItems[idx].Fields[version] = strconv.Itoa(idx + 1)
+			fxt.WorkItems[idx].Fields[effort] = float64(idx) + 1.5
+			return nil
+		}),
+	)
+	s.T().Run("number in a string field", func(t *testing.T) {
+		// when
+		filter := fmt.Sprintf(`{"$AND":[{"space":"%s"},{"fields.%s":"2"}]}`, fxt.Spaces[0].ID, version)
+		res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		assert.Equal(t, fxt.WorkItems[1].ID, res[0].ID)
+	})
+	s.T().Run("number in a float field", func(t *testing.T) {
+		// when
+		filter := fmt.Sprintf(`{"$AND":[{"space":"%s"},{"fields.%s":"1.5"}]}`, fxt.Spaces[0].ID, effort)
+		res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		assert.Equal(t, fxt.WorkItems[0].ID, res[0].ID)
+	})
+	s.T().Run("field name with SQL", func(t *testing.T) {
+		// when
+		_, _, _, _, err := s.searchRepo.Filter(context.Background(), `{"fields.x') OR 1=1 --":null}`, nil, nil, nil)
+		// then
+		require.Error(t, err)
+	})
+}
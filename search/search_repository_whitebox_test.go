@@ -57,6 +57,18 @@ func TestParseSearchString(t *testing.T) {
 	assert.True(t, assert.ObjectsAreEqualValues(expectedSearchRes, op))
 }
 
+func TestParseSearchStringArchived(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	op, err := parseSearchString(context.Background(), "closed bug archived:true")
+	require.NoError(t, err)
+	expectedSearchRes := searchKeyword{
+		words:    []string{"closed:*", "bug:*"},
+		archived: true,
+	}
+	assert.Equal(t, expectedSearchRes, op)
+}
+
 type searchTestData struct {
 	query    string
 	expected searchKeyword
@@ -0,0 +1,162 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/area"
+	"github.com/fabric8-services/fabric8-wit/closeable"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/label"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// The types of the entities that can be suggested. They match the types of
+// the corresponding JSON API resources.
+const (
+	SuggestionTypeWorkItem  = "workitems"
+	SuggestionTypeUser      = "identities"
+	SuggestionTypeLabel     = "labels"
+	SuggestionTypeIteration = "iterations"
+	SuggestionTypeArea      = "areas"
+
+	// MaxSuggestionsLimit is the maximum number of suggestions returned per
+	// type of entity
+	MaxSuggestionsLimit = 20
+)
+
+// Suggestion is a minimal representation of an entity matching the prefix
+// typed in an autocomplete box.
+type Suggestion struct {
+	Type string
+	ID   uuid.UUID
+	// Name is the title of a work item, the username of a user or the name
+	// of a label, an iteration or an area
+	Name string
+	// Number is only set on work items
+	Number *int
+	// FullName and ImageURL are only set on users
+	FullName *string
+	ImageURL *string
+}
+
+var suggestionWordSeparator = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// suggestionTSQuery converts the given prefix into a text search query that
+// matches the number or the title (the 'A' and 'B' weights of the search
+// vector) of the work items containing words starting with each of the words
+// of the prefix. It returns an empty string if the prefix has no word.
+func suggestionTSQuery(prefix string) string {
+	terms := []string{}
+	for _, w := range suggestionWordSeparator.Split(prefix, -1) {
+		if w != "" {
+			terms = append(terms, w+":*AB")
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Suggest returns at most limit work items, users, labels, iterations and
+// areas (each) matching the given prefix, for an autocomplete box: work items
+// of the space whose number is the prefix or whose title has words starting
+// with the words of the prefix (most recent first), users whose username or
+// full name starts with the prefix, and labels, iterations and areas of the
+// space whose name starts with the prefix. All lookups are index based and
+// run in a single query.
+func (r *GormSearchRepository) Suggest(ctx context.Context, spaceID uuid.UUID, prefix string, limit int) ([]Suggestion, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "search", "suggest"}, time.Now())
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return nil, errors.NewBadParameterError("prefix", prefix).Expected("non empty prefix")
+	}
+	if limit <= 0 || limit > MaxSuggestionsLimit {
+		return nil, errors.NewBadParameterError("limit", limit).Expected(fmt.Sprintf("value between 1 and %d", MaxSuggestionsLimit))
+	}
+	pattern := escapeLike(strings.ToLower(prefix)) + "%"
+
+	branches := []string{}
+	parameters := []interface{}{}
+	// work items
+	var number *int
+	if n, err := strconv.Atoi(prefix); err == nil {
+		number = &n
+	}
+	if tsquery := suggestionTSQuery(prefix); tsquery != "" {
+		branches = append(branches, fmt.Sprintf(`(SELECT '%[3]s' AS type, w.id, w.fields->>'%[4]s' AS name, w.number, NULL AS full_name, NULL AS image_url
+			FROM %[1]s w JOIN %[2]s s ON s.id = w.space_id
+			WHERE w.space_id = ? AND w.deleted_at IS NULL
+			AND (w.number = ? OR w.tsv @@ to_tsquery(s.search_config, ?))
+			ORDER BY w.number = ? DESC, w.number DESC LIMIT ?)`,
+			workitem.WorkItemStorage{}.TableName(), space.Space{}.TableName(), SuggestionTypeWorkItem, workitem.SystemTitle))
+		parameters = append(parameters, spaceID, number, tsquery, number, limit)
+	}
+	// users: the two lookups are limited separately so that each one can use
+	// its own index
+	branches = append(branches, fmt.Sprintf(`(SELECT '%[3]s', i.id, i.username, NULL::integer, u.full_name, u.image_url
+		FROM %[1]s i LEFT JOIN %[2]s u ON u.id = i.user_id AND u.deleted_at IS NULL
+		WHERE i.id IN (
+			(SELECT id FROM %[1]s WHERE lower(username) LIKE ? AND deleted_at IS NULL LIMIT ?)
+			UNION
+			(SELECT i2.id FROM %[1]s i2 JOIN %[2]s u2 ON u2.id = i2.user_id
+				WHERE lower(u2.full_name) LIKE ? AND i2.deleted_at IS NULL AND u2.deleted_at IS NULL LIMIT ?))
+		ORDER BY i.username LIMIT ?)`,
+		account.Identity{}.TableName(), account.User{}.TableName(), SuggestionTypeUser))
+	parameters = append(parameters, pattern, limit, pattern, limit, limit)
+	// labels, iterations and areas of the space
+	for _, named := range []struct {
+		table string
+		typ   string
+	}{
+		{label.Label{}.TableName(), SuggestionTypeLabel},
+		{iteration.Iteration{}.TableName(), SuggestionTypeIteration},
+		{area.Area{}.TableName(), SuggestionTypeArea},
+	} {
+		branches = append(branches, fmt.Sprintf(`(SELECT '%[2]s', id, name, NULL::integer, NULL, NULL
+			FROM %[1]s WHERE space_id = ? AND deleted_at IS NULL AND lower(name) LIKE ?
+			ORDER BY lower(name) LIMIT ?)`, named.table, named.typ))
+		parameters = append(parameters, spaceID, pattern, limit)
+	}
+
+	rows, err := r.db.Raw(strings.Join(branches, " UNION ALL "), parameters...).Rows()
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":      err,
+			"space_id": spaceID,
+			"prefix":   prefix,
+		}, "failed to look up the suggestions")
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to look up the suggestions"))
+	}
+	defer closeable.Close(ctx, rows)
+	result := []Suggestion{}
+	for rows.Next() {
+		var s Suggestion
+		var name *string
+		if err := rows.Scan(&s.Type, &s.ID, &name, &s.Number, &s.FullName, &s.ImageURL); err != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to scan the suggestions"))
+		}
+		if name != nil {
+			s.Name = *name
+		}
+		result = append(result, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to read the suggestions"))
+	}
+	return result, nil
+}
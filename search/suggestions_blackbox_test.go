@@ -0,0 +1,129 @@
+package search_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/search"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunSuggestionsBlackboxTest(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &suggestionsBlackboxTest{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+type suggestionsBlackboxTest struct {
+	gormtestsupport.DBTestSuite
+	searchRepo *search.GormSearchRepository
+}
+
+func (s *suggestionsBlackboxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.searchRepo = search.NewGormSearchRepository(s.DB)
+}
+
+func (s *suggestionsBlackboxTest) TestSuggest() {
+	// given
+	username := "zebra" + uuid.NewV4().String()
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.Identities(2, tf.SetIdentityUsernames(username, "lion"+uuid.NewV4().String())),
+		tf.WorkItems(3, tf.SetWorkItemTitles("the zebra crossing", "zebras everywhere", "a lion")),
+		tf.Labels(2, tf.SetLabelNames("Zebra_label", "ZebraXlabel")),
+		tf.Iterations(2, tf.SetIterationNames("root", "zebra sprint")),
+		tf.Areas(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.Areas[idx].Name = "lion area"
+			return nil
+		}),
+	)
+	suggestionsOfType := func(suggestions []search.Suggestion, typ string) []search.Suggestion {
+		res := []search.Suggestion{}
+		for _, s := range suggestions {
+			if s.Type == typ {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+
+	s.T().Run("prefix", func(t *testing.T) {
+		// when
+		res, err := s.searchRepo.Suggest(context.Background(), fxt.Spaces[0].ID, "Zebr", 5)
+		// then
+		require.NoError(t, err)
+		workItems := suggestionsOfType(res, search.SuggestionTypeWorkItem)
+		require.Len(t, workItems, 2)
+		assert.Equal(t, fxt.WorkItemByTitle("zebras everywhere").ID, workItems[0].ID)
+		assert.Equal(t, fxt.WorkItemByTitle("the zebra crossing").ID, workItems[1].ID)
+		require.NotNil(t, workItems[0].Number)
+		assert.Equal(t, fxt.WorkItemByTitle("zebras everywhere").Number, *workItems[0].Number)
+		users := suggestionsOfType(res, search.SuggestionTypeUser)
+		require.Len(t, users, 1)
+		assert.Equal(t, fxt.Identities[0].ID, users[0].ID)
+		assert.Equal(t, username, users[0].Name)
+		labels := suggestionsOfType(res, search.SuggestionTypeLabel)
+		require.Len(t, labels, 2)
+		assert.Equal(t, "Zebra_label", labels[0].Name)
+		iterations := suggestionsOfType(res, search.SuggestionTypeIteration)
+		require.Len(t, iterations, 1)
+		assert.Equal(t, fxt.Iterations[1].ID, iterations[0].ID)
+		assert.Empty(t, suggestionsOfType(res, search.SuggestionTypeArea))
+	})
+
+	s.T().Run("wildcards are escaped", func(t *testing.T) {
+		// when
+		res, err := s.searchRepo.Suggest(context.Background(), fxt.Spaces[0].ID, "zebra_", 5)
+		// then
+		require.NoError(t, err)
+		labels := suggestionsOfType(res, search.SuggestionTypeLabel)
+		require.Len(t, labels, 1)
+		assert.Equal(t, fxt.Labels[0].ID, labels[0].ID)
+	})
+
+	s.T().Run("number", func(t *testing.T) {
+		// given
+		wi := fxt.WorkItemByTitle("a lion")
+		// when
+		res, err := s.searchRepo.Suggest(context.Background(), fxt.Spaces[0].ID, strconv.Itoa(wi.Number), 5)
+		// then
+		require.NoError(t, err)
+		workItems := suggestionsOfType(res, search.SuggestionTypeWorkItem)
+		require.NotEmpty(t, workItems)
+		assert.Equal(t, wi.ID, workItems[0].ID)
+	})
+
+	s.T().Run("limit", func(t *testing.T) {
+		// when
+		res, err := s.searchRepo.Suggest(context.Background(), fxt.Spaces[0].ID, "zebra", 1)
+		// then
+		require.NoError(t, err)
+		assert.Len(t, suggestionsOfType(res, search.SuggestionTypeWorkItem), 1)
+		assert.Len(t, suggestionsOfType(res, search.SuggestionTypeLabel), 1)
+	})
+
+	s.T().Run("other space", func(t *testing.T) {
+		// given
+		other := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		// when
+		res, err := s.searchRepo.Suggest(context.Background(), other.Spaces[0].ID, "zebra", 5)
+		// then only the users are global
+		require.NoError(t, err)
+		for _, suggestion := range res {
+			assert.Equal(t, search.SuggestionTypeUser, suggestion.Type)
+		}
+	})
+
+	s.T().Run("invalid parameters", func(t *testing.T) {
+		_, err := s.searchRepo.Suggest(context.Background(), fxt.Spaces[0].ID, " ", 5)
+		require.Error(t, err)
+		_, err = s.searchRepo.Suggest(context.Background(), fxt.Spaces[0].ID, "zebra", search.MaxSuggestionsLimit+1)
+		require.Error(t, err)
+	})
+}
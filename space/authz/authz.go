@@ -7,13 +7,16 @@ import (
 
 	"github.com/fabric8-services/fabric8-wit/auth"
 	"github.com/fabric8-services/fabric8-wit/errors"
+	witmiddleware "github.com/fabric8-services/fabric8-wit/goamiddleware"
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/login/tokencontext"
+	"github.com/fabric8-services/fabric8-wit/space/role"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/goadesign/goa"
 	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 // AuthzService represents a space authorization service
@@ -65,8 +68,12 @@ func (s *KeycloakAuthzService) Configuration() AuthzConfiguration {
 	return s.config
 }
 
-// Authorize returns true and the corresponding Requesting Party Token if the current user is among the space collaborators
+// Authorize returns true and the corresponding Requesting Party Token if the current user is among the space collaborators.
+// It returns false without asking Keycloak if the token of the request is not a Keycloak token (e.g. a personal access token).
 func (s *KeycloakAuthzService) Authorize(ctx context.Context, entitlementEndpoint string, spaceID string) (bool, error) {
+	if witmiddleware.IsResolvedToken(ctx) {
+		return false, nil
+	}
 	jwttoken := goajwt.ContextJWT(ctx)
 	if jwttoken == nil {
 		return false, errors.NewUnauthorizedError("missing token")
@@ -127,3 +134,25 @@ func Authorize(ctx context.Context, spaceID string) (bool, error) {
 	manager := srv.(AuthzServiceManager)
 	return manager.AuthzService().Authorize(ctx, manager.EntitlementEndpoint(), spaceID)
 }
+
+// RoleSource gives the role.Contributor role to the collaborators of a space,
+// as given by the Keycloak entitlement of the token of the request: the
+// identity must be the one of the request. It gives no role when no space
+// authz service was set up in the context, nor when the token of the request
+// is not a Keycloak token (e.g. a personal access token), which must never be
+// sent to Keycloak.
+func RoleSource() role.Source {
+	return role.SourceFunc(func(ctx context.Context, spaceID, identityID uuid.UUID) (role.Role, error) {
+		if tokencontext.ReadSpaceAuthzServiceFromContext(ctx) == nil || witmiddleware.IsResolvedToken(ctx) {
+			return role.None, nil
+		}
+		authorized, err := Authorize(ctx, spaceID.String())
+		if err != nil {
+			return role.None, err
+		}
+		if authorized {
+			return role.Contributor, nil
+		}
+		return role.None, nil
+	})
+}
@@ -3,14 +3,18 @@ package authz_test
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/fabric8-services/fabric8-wit/configuration"
 	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/resource"
 	"github.com/fabric8-services/fabric8-wit/space/authz"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
 
 	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -41,3 +45,20 @@ func (s *TestAuthzSuite) TestFailsIfNoTokenInContext() {
 	require.Error(s.T(), err)
 	require.IsType(s.T(), errors.UnauthorizedError{}, err)
 }
+
+func (s *TestAuthzSuite) TestNeverSendsPersonalAccessTokens() {
+	// given
+	var authorizations []string
+	entitlement := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer entitlement.Close()
+	ctx := testsupport.WithResolvedToken(context.Background(), "f8pat_secret", testsupport.TestIdentity)
+	// when
+	authorized, err := s.authzService.Authorize(ctx, entitlement.URL, uuid.NewV4().String())
+	// then
+	require.NoError(s.T(), err)
+	assert.False(s.T(), authorized)
+	assert.Empty(s.T(), authorizations)
+}
@@ -0,0 +1,329 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/models"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// subscriptionBuffer is the number of events a subscriber may lag behind
+	// before it is dropped
+	subscriptionBuffer = 100
+	// pollLimit is the maximum number of events loaded at once by the poller
+	pollLimit = 500
+	// ReplayLimit is the maximum number of events replayed to a subscriber
+	// resuming its stream
+	ReplayLimit = 1000
+	// purgeInterval is how often the events older than the retention period
+	// are purged
+	purgeInterval = time.Minute
+	// InFlightWindow is how many event IDs below the newest event seen are
+	// scanned again: the IDs are taken when the events are created, so an
+	// event can be committed after an event with a higher ID.
+	InFlightWindow = 100
+)
+
+// Broker records the notification messages as events of their space and
+// streams them to the subscribers of the space. The events are polled from
+// the database so that the subscribers also receive the events committed by
+// the other instances of the service.
+type Broker struct {
+	db            *gorm.DB
+	pollInterval  time.Duration
+	retention     time.Duration
+	mu            sync.Mutex
+	subscriptions map[uuid.UUID]map[*Subscription]struct{}
+	// published holds the events published within the in-flight window,
+	// only used by the poller
+	published *Seen
+	wake      chan struct{}
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+var _ notification.Channel = &Broker{}
+
+// NewBroker creates a new Broker
+func NewBroker(db *gorm.DB, pollInterval, retention time.Duration) *Broker {
+	return &Broker{
+		db:            db,
+		pollInterval:  pollInterval,
+		retention:     retention,
+		subscriptions: map[uuid.UUID]map[*Subscription]struct{}{},
+		published:     NewSeen(),
+		wake:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Send stores the message as an event of its space and wakes the poller up
+// so that the local subscribers receive it right away. The messages without a
+// space are ignored.
+func (b *Broker) Send(ctx context.Context, msg notification.Message) {
+	if uuid.Equal(msg.SpaceID, uuid.Nil) {
+		return
+	}
+	e := Event{
+		SpaceID:  msg.SpaceID,
+		Type:     msg.MessageType,
+		TargetID: msg.TargetID,
+	}
+	if identityID, err := login.ContextIdentity(ctx); err == nil {
+		e.IdentityID = identityID
+	}
+	err := models.Transactional(b.db, func(tx *gorm.DB) error {
+		return NewRepository(tx).Create(ctx, &e)
+	})
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"message_id": msg.MessageID,
+			"type":       msg.MessageType,
+			"target_id":  msg.TargetID,
+			"err":        err,
+		}, "unable to record the space event")
+		return
+	}
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start starts polling the events committed after the ones currently stored
+// and purging the events older than the retention period.
+func (b *Broker) Start() error {
+	ctx := context.Background()
+	_, last, err := NewRepository(b.db).Bounds(ctx)
+	if err != nil {
+		return errs.Wrap(err, "failed to load the last space event")
+	}
+	events, err := NewRepository(b.db).ListAll(ctx, last-InFlightWindow, InFlightWindow)
+	if err != nil {
+		return errs.Wrap(err, "failed to load the last space events")
+	}
+	b.published.Add(last)
+	for _, e := range events {
+		b.published.Add(e.ID)
+	}
+	b.stopped = make(chan struct{})
+	go b.run()
+	return nil
+}
+
+// Stop stops the broker.
+// This should be called only from main
+func (b *Broker) Stop() {
+	if b.stopped == nil {
+		return
+	}
+	close(b.stop)
+	<-b.stopped
+}
+
+func (b *Broker) run() {
+	defer close(b.stopped)
+	poll := time.NewTicker(b.pollInterval)
+	defer poll.Stop()
+	purge := time.NewTicker(purgeInterval)
+	defer purge.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-purge.C:
+			if _, err := b.Purge(context.Background()); err != nil {
+				log.Error(nil, map[string]interface{}{
+					"err": err,
+				}, "failed to purge the space events")
+			}
+			continue
+		case <-b.wake:
+		case <-poll.C:
+		}
+		if err := b.poll(context.Background()); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"last_id": b.published.Last(),
+				"err":     err,
+			}, "failed to poll the space events")
+		}
+	}
+}
+
+// poll publishes the events stored since the last poll, including the ones
+// committed late within the in-flight window
+func (b *Broker) poll(ctx context.Context) error {
+	afterID := b.published.Last() - InFlightWindow
+	for {
+		events, err := NewRepository(b.db).ListAll(ctx, afterID, pollLimit)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if b.published.Add(e.ID) {
+				b.publish(e)
+			}
+			afterID = e.ID
+		}
+		if len(events) < pollLimit {
+			return nil
+		}
+	}
+}
+
+// publish sends the event to the subscribers of its space. The subscribers
+// lagging too far behind are dropped: they will resume their stream from the
+// last event they received.
+func (b *Broker) publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subscriptions[e.SpaceID] {
+		select {
+		case s.events <- e:
+		default:
+			log.Warn(nil, map[string]interface{}{
+				"space_id": e.SpaceID,
+				"event_id": e.ID,
+			}, "dropping a space event subscriber lagging behind")
+			b.unsubscribe(s)
+		}
+	}
+}
+
+// Purge deletes the events older than the retention period and returns how
+// many of them were deleted. Nothing is purged if the retention period is not
+// positive.
+func (b *Broker) Purge(ctx context.Context) (int64, error) {
+	if b.retention <= 0 {
+		return 0, nil
+	}
+	var purged int64
+	err := models.Transactional(b.db, func(tx *gorm.DB) error {
+		var err error
+		purged, err = NewRepository(tx).Purge(ctx, time.Now().Add(-b.retention))
+		return err
+	})
+	return purged, err
+}
+
+// Subscription receives the events of a space as they are committed
+type Subscription struct {
+	SpaceID uuid.UUID
+	events  chan Event
+	broker  *Broker
+}
+
+// Subscribe returns a new subscription to the events of the given space. It
+// must be closed once the subscriber is gone.
+func (b *Broker) Subscribe(spaceID uuid.UUID) *Subscription {
+	s := &Subscription{
+		SpaceID: spaceID,
+		events:  make(chan Event, subscriptionBuffer),
+		broker:  b,
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscriptions[spaceID] == nil {
+		b.subscriptions[spaceID] = map[*Subscription]struct{}{}
+	}
+	b.subscriptions[spaceID][s] = struct{}{}
+	return s
+}
+
+// Events returns the channel of the events, which is closed when the
+// subscription is closed or when the subscriber lags too far behind
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close closes the subscription
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.unsubscribe(s)
+}
+
+// unsubscribe removes the subscription, the lock must be held by the caller
+func (b *Broker) unsubscribe(s *Subscription) {
+	subscriptions := b.subscriptions[s.SpaceID]
+	if _, ok := subscriptions[s]; !ok {
+		return
+	}
+	delete(subscriptions, s)
+	close(s.events)
+	if len(subscriptions) == 0 {
+		delete(b.subscriptions, s.SpaceID)
+	}
+}
+
+// Replay returns the retained events of the space that come after the given
+// event ID, starting InFlightWindow IDs below it since the events committed
+// late may have been missed: the subscriber may receive some of them again.
+// It returns false if some of the events that came after it are not retained
+// anymore, in which case the subscriber should reload the whole state of the
+// space instead.
+func (b *Broker) Replay(ctx context.Context, spaceID uuid.UUID, afterID int64) ([]Event, bool, error) {
+	repo := NewRepository(b.db)
+	first, _, err := repo.Bounds(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if first > afterID+1 {
+		return nil, false, nil
+	}
+	events, err := repo.List(ctx, spaceID, afterID-InFlightWindow, ReplayLimit)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(events) == ReplayLimit {
+		return nil, false, nil
+	}
+	return events, true, nil
+}
+
+// Seen records the IDs of the events already handled within the in-flight
+// window below the newest one
+type Seen struct {
+	last int64
+	ids  map[int64]struct{}
+}
+
+// NewSeen creates a new Seen
+func NewSeen() *Seen {
+	return &Seen{ids: map[int64]struct{}{}}
+}
+
+// Add records the given event ID and returns false if it was already
+// recorded, or if it is below the in-flight window and thus cannot be told
+// apart from the events already handled
+func (s *Seen) Add(id int64) bool {
+	if id <= s.last-InFlightWindow {
+		return false
+	}
+	if _, ok := s.ids[id]; ok {
+		return false
+	}
+	s.ids[id] = struct{}{}
+	if id > s.last {
+		s.last = id
+		for seen := range s.ids {
+			if seen <= s.last-InFlightWindow {
+				delete(s.ids, seen)
+			}
+		}
+	}
+	return true
+}
+
+// Last returns the newest event ID recorded
+func (s *Seen) Last() int64 {
+	return s.last
+}
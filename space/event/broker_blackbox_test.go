@@ -0,0 +1,160 @@
+package event_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/event"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunBroker(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &brokerBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+type brokerBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+}
+
+func (s *brokerBlackBoxTest) TestStream() {
+	s.T().Run("subscribers receive the events of their space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1), tf.Spaces(2))
+		broker := event.NewBroker(s.DB, time.Hour, time.Hour)
+		require.NoError(t, broker.Start())
+		defer broker.Stop()
+		sub := broker.Subscribe(fxt.Spaces[0].ID)
+		defer sub.Close()
+		ctx := testsupport.WithIdentity(context.Background(), *fxt.Identities[0])
+		// when
+		broker.Send(ctx, notification.NewWorkItemCreated(fxt.Spaces[1].ID, "other"))
+		broker.Send(ctx, notification.NewWorkItemCreated(fxt.Spaces[0].ID, "created"))
+		// then
+		select {
+		case e := <-sub.Events():
+			assert.Equal(t, notification.MessageTypeWorkItemCreate, e.Type)
+			assert.Equal(t, "created", e.TargetID)
+			require.NotNil(t, e.IdentityID)
+			assert.Equal(t, fxt.Identities[0].ID, *e.IdentityID)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event received")
+		}
+	})
+
+	s.T().Run("events committed out of order", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		broker := event.NewBroker(s.DB, 10*time.Millisecond, time.Hour)
+		require.NoError(t, broker.Start())
+		defer broker.Stop()
+		sub := broker.Subscribe(fxt.Spaces[0].ID)
+		defer sub.Close()
+		// the first event is created before the second one but committed
+		// after it
+		tx := s.DB.Begin()
+		defer tx.Rollback()
+		first := event.Event{SpaceID: fxt.Spaces[0].ID, Type: notification.MessageTypeWorkItemCreate, TargetID: "first"}
+		require.NoError(t, event.NewRepository(tx).Create(context.Background(), &first))
+		broker.Send(context.Background(), notification.NewWorkItemUpdated(fxt.Spaces[0].ID, "second"))
+		receive := func() event.Event {
+			select {
+			case e := <-sub.Events():
+				return e
+			case <-time.After(5 * time.Second):
+				t.Fatal("no event received")
+			}
+			return event.Event{}
+		}
+		second := receive()
+		require.Equal(t, "second", second.TargetID)
+		require.True(t, second.ID > first.ID)
+		// when
+		require.NoError(t, tx.Commit().Error)
+		// then
+		e := receive()
+		assert.Equal(t, first.ID, e.ID)
+		assert.Equal(t, "first", e.TargetID)
+		select {
+		case e := <-sub.Events():
+			t.Fatalf("event %d received twice", e.ID)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	s.T().Run("messages without space are ignored", func(t *testing.T) {
+		broker := event.NewBroker(s.DB, time.Hour, time.Hour)
+		_, before, err := event.NewRepository(s.DB).Bounds(context.Background())
+		require.NoError(t, err)
+		// when
+		broker.Send(context.Background(), notification.NewWorkItemCreated(uuid.Nil, "created"))
+		// then
+		_, after, err := event.NewRepository(s.DB).Bounds(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+}
+
+func (s *brokerBlackBoxTest) TestReplay() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1))
+	broker := event.NewBroker(s.DB, time.Hour, time.Hour)
+	broker.Send(context.Background(), notification.NewWorkItemCreated(fxt.Spaces[0].ID, "a"))
+	broker.Send(context.Background(), notification.NewWorkItemUpdated(fxt.Spaces[0].ID, "a"))
+	events, complete, err := broker.Replay(context.Background(), fxt.Spaces[0].ID, 0)
+	require.NoError(s.T(), err)
+	require.True(s.T(), complete)
+	require.Len(s.T(), events, 2)
+
+	s.T().Run("after an event", func(t *testing.T) {
+		// when
+		replayed, complete, err := broker.Replay(context.Background(), fxt.Spaces[0].ID, events[0].ID)
+		// then the events within the in-flight window are replayed as well
+		require.NoError(t, err)
+		assert.True(t, complete)
+		require.Len(t, replayed, 2)
+		assert.Equal(t, events[0].ID, replayed[0].ID)
+		assert.Equal(t, notification.MessageTypeWorkItemUpdate, replayed[1].Type)
+	})
+
+	s.T().Run("event committed late", func(t *testing.T) {
+		tx := s.DB.Begin()
+		defer tx.Rollback()
+		late := event.Event{SpaceID: fxt.Spaces[0].ID, Type: notification.MessageTypeWorkItemDelete, TargetID: "late"}
+		require.NoError(t, event.NewRepository(tx).Create(context.Background(), &late))
+		broker.Send(context.Background(), notification.NewWorkItemUpdated(fxt.Spaces[0].ID, "b"))
+		replayed, _, err := broker.Replay(context.Background(), fxt.Spaces[0].ID, events[1].ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, replayed)
+		last := replayed[len(replayed)-1]
+		require.True(t, last.ID > late.ID)
+		require.NoError(t, tx.Commit().Error)
+		// when
+		replayed, complete, err := broker.Replay(context.Background(), fxt.Spaces[0].ID, last.ID)
+		// then
+		require.NoError(t, err)
+		assert.True(t, complete)
+		var ids []int64
+		for _, e := range replayed {
+			ids = append(ids, e.ID)
+		}
+		assert.Contains(t, ids, late.ID)
+	})
+
+	s.T().Run("events not retained anymore", func(t *testing.T) {
+		_, err := event.NewRepository(s.DB).Purge(context.Background(), time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		// when
+		replayed, complete, err := broker.Replay(context.Background(), fxt.Spaces[0].ID, events[0].ID-1)
+		// then
+		require.NoError(t, err)
+		assert.False(t, complete)
+		assert.Empty(t, replayed)
+	})
+}
@@ -0,0 +1,3 @@
+// Package event contains the short retained log of the changes made in a
+// space and the broker streaming them to the subscribers of the space.
+package event
@@ -0,0 +1,142 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Event is a change committed in a space, e.g. a work item being created.
+// The IDs of the events are increasing so that the subscribers can resume
+// their stream after the last event they received, but since they are taken
+// when the events are created, the events may be committed out of order.
+type Event struct {
+	ID       int64 `gorm:"primary_key"`
+	SpaceID  uuid.UUID
+	Type     string
+	TargetID string
+	// IdentityID is the identity that made the change, if known
+	IdentityID *uuid.UUID
+	CreatedAt  time.Time
+}
+
+// TableName implements gorm.tabler
+func (e Event) TableName() string {
+	return "space_events"
+}
+
+// Repository describes interactions with the retained events
+type Repository interface {
+	// Create stores the given event and sets its ID
+	Create(ctx context.Context, e *Event) error
+	// List returns at most limit events of the given space that come after
+	// the given event ID, in order
+	List(ctx context.Context, spaceID uuid.UUID, afterID int64, limit int) ([]Event, error)
+	// ListAll returns at most limit events of all the spaces that come after
+	// the given event ID, in order
+	ListAll(ctx context.Context, afterID int64, limit int) ([]Event, error)
+	// Bounds returns the IDs of the oldest and the newest retained events,
+	// zero if there is none
+	Bounds(ctx context.Context) (first int64, last int64, err error)
+	// Purge deletes the events created before the given time, except the
+	// newest one which tells the subscribers resuming their stream whether
+	// they missed some events, and returns how many of them were deleted
+	Purge(ctx context.Context, createdBefore time.Time) (int64, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for the
+// events.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create stores the given event
+func (r *GormRepository) Create(ctx context.Context, e *Event) error {
+	defer goa.MeasureSince([]string{"goa", "db", "space_event", "create"}, time.Now())
+	e.ID = 0
+	if err := r.db.Create(e).Error; err != nil {
+		if gormsupport.IsForeignKeyViolation(err, "space_events_space_id_fkey") {
+			return errors.NewNotFoundError("space", e.SpaceID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"space_id": e.SpaceID,
+			"type":     e.Type,
+			"err":      err,
+		}, "unable to store the space event")
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// List returns the events of the space after the given one
+func (r *GormRepository) List(ctx context.Context, spaceID uuid.UUID, afterID int64, limit int) ([]Event, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "space_event", "list"}, time.Now())
+	var res []Event
+	err := r.db.Where("space_id = ? AND id > ?", spaceID, afterID).Order("id").Limit(limit).Find(&res).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"after_id": afterID,
+			"err":      err,
+		}, "unable to list the space events")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, nil
+}
+
+// ListAll returns the events of all the spaces after the given one
+func (r *GormRepository) ListAll(ctx context.Context, afterID int64, limit int) ([]Event, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "space_event", "list_all"}, time.Now())
+	var res []Event
+	err := r.db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&res).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"after_id": afterID,
+			"err":      err,
+		}, "unable to list the events")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return res, nil
+}
+
+// Bounds returns the IDs of the oldest and newest events
+func (r *GormRepository) Bounds(ctx context.Context) (int64, int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "space_event", "bounds"}, time.Now())
+	var bounds struct {
+		First int64
+		Last  int64
+	}
+	err := r.db.Raw(`SELECT coalesce(min(id), 0) AS first, coalesce(max(id), 0) AS last FROM space_events`).Scan(&bounds).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to load the bounds of the events")
+		return 0, 0, errors.NewInternalError(ctx, err)
+	}
+	return bounds.First, bounds.Last, nil
+}
+
+// Purge deletes the old events
+func (r *GormRepository) Purge(ctx context.Context, createdBefore time.Time) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "space_event", "purge"}, time.Now())
+	db := r.db.Exec(`DELETE FROM space_events WHERE created_at < ? AND id < (SELECT max(id) FROM space_events)`, createdBefore)
+	if db.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"created_before": createdBefore,
+			"err":            db.Error,
+		}, "unable to purge the events")
+		return 0, errors.NewInternalError(ctx, db.Error)
+	}
+	return db.RowsAffected, nil
+}
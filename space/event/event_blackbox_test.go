@@ -0,0 +1,108 @@
+package event_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/event"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunEventRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &eventRepositoryBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+type eventRepositoryBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	repo event.Repository
+}
+
+func (s *eventRepositoryBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.repo = event.NewRepository(s.DB)
+}
+
+func (s *eventRepositoryBlackBoxTest) create(t *testing.T, spaceID uuid.UUID, targetID string) event.Event {
+	e := event.Event{SpaceID: spaceID, Type: "workitem.update", TargetID: targetID}
+	require.NoError(t, s.repo.Create(context.Background(), &e))
+	return e
+}
+
+func (s *eventRepositoryBlackBoxTest) TestCreate() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		// when
+		e1 := s.create(t, fxt.Spaces[0].ID, "a")
+		e2 := s.create(t, fxt.Spaces[0].ID, "b")
+		// then
+		assert.NotZero(t, e1.ID)
+		assert.True(t, e2.ID > e1.ID)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		// when
+		err := s.repo.Create(context.Background(), &event.Event{SpaceID: uuid.NewV4(), Type: "workitem.update", TargetID: "a"})
+		// then
+		require.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *eventRepositoryBlackBoxTest) TestList() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(2))
+	e1 := s.create(s.T(), fxt.Spaces[0].ID, "a")
+	s.create(s.T(), fxt.Spaces[1].ID, "b")
+	e3 := s.create(s.T(), fxt.Spaces[0].ID, "c")
+
+	s.T().Run("events of the space", func(t *testing.T) {
+		// when
+		events, err := s.repo.List(context.Background(), fxt.Spaces[0].ID, 0, 10)
+		// then
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, e1.ID, events[0].ID)
+		assert.Equal(t, e3.ID, events[1].ID)
+	})
+
+	s.T().Run("after an event", func(t *testing.T) {
+		// when
+		events, err := s.repo.List(context.Background(), fxt.Spaces[0].ID, e1.ID, 10)
+		// then
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "c", events[0].TargetID)
+	})
+
+	s.T().Run("all spaces", func(t *testing.T) {
+		// when
+		events, err := s.repo.ListAll(context.Background(), e1.ID, 10)
+		// then
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "b", events[0].TargetID)
+		assert.Equal(t, "c", events[1].TargetID)
+	})
+}
+
+func (s *eventRepositoryBlackBoxTest) TestPurge() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1))
+	e1 := s.create(s.T(), fxt.Spaces[0].ID, "a")
+	e2 := s.create(s.T(), fxt.Spaces[0].ID, "b")
+	// when
+	_, err := s.repo.Purge(context.Background(), time.Now().Add(time.Minute))
+	// then the newest event is kept
+	require.NoError(s.T(), err)
+	first, last, err := s.repo.Bounds(context.Background())
+	require.NoError(s.T(), err)
+	assert.True(s.T(), first > e1.ID)
+	assert.Equal(s.T(), e2.ID, first)
+	assert.Equal(s.T(), e2.ID, last)
+}
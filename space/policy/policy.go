@@ -0,0 +1,88 @@
+// Package policy contains the storage shared by the policies of a space
+// (e.g. the workspace and the retention policies), which are stored in a
+// table of their own with one row per space. The row of a space is only
+// created once its policy is first saved.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Setting is a value of a policy and the column it is stored in
+type Setting struct {
+	// Column is the column of the policy table, which may have a check
+	// constraint named after it
+	Column string
+	// Attribute is the name of the setting reported when its value is
+	// rejected by the check constraint of the column
+	Attribute string
+	Value     interface{}
+}
+
+// Load loads the policy of the space stored in the given table into p, which
+// is left untouched if none was saved
+func Load(ctx context.Context, db *gorm.DB, table string, spaceID uuid.UUID, p interface{}) error {
+	q := db.Where("space_id = ?", spaceID).First(p)
+	if q.RecordNotFound() {
+		return nil
+	}
+	if q.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"table":    table,
+			"err":      q.Error,
+		}, "unable to load the policy of the space")
+		return errors.NewInternalError(ctx, q.Error)
+	}
+	return nil
+}
+
+// Save creates or replaces the policy of the space stored in the given table
+// with the given settings, then loads the saved policy into p
+func Save(ctx context.Context, db *gorm.DB, table string, spaceID uuid.UUID, p interface{}, settings ...Setting) error {
+	columns := []string{"space_id"}
+	placeholders := []string{"?"}
+	updates := []string{}
+	values := []interface{}{spaceID}
+	for _, s := range settings {
+		columns = append(columns, s.Column)
+		placeholders = append(placeholders, "?")
+		updates = append(updates, fmt.Sprintf("%[1]s = EXCLUDED.%[1]s", s.Column))
+		values = append(values, s.Value)
+	}
+	updates = append(updates, "updated_at = now()")
+	upsertStmt := fmt.Sprintf(`INSERT INTO %s (%s)
+		VALUES (%s)
+		ON CONFLICT (space_id) DO UPDATE SET %s`,
+		table, strings.Join(columns, ", "), strings.Join(placeholders, ", "), strings.Join(updates, ", "))
+	err := db.Exec(upsertStmt, values...).Error
+	if err != nil {
+		if gormsupport.IsForeignKeyViolation(err, table+"_space_id_fkey") {
+			return errors.NewNotFoundError("space", spaceID.String())
+		}
+		for _, s := range settings {
+			if gormsupport.IsCheckViolation(err, table+"_"+s.Column+"_check") {
+				return errors.NewBadParameterError(s.Attribute, s.Value)
+			}
+		}
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"table":    table,
+			"err":      err,
+		}, "unable to save the policy of the space")
+		return errors.NewInternalError(ctx, err)
+	}
+	err = db.Where("space_id = ?", spaceID).First(p).Error
+	if err != nil {
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
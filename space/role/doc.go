@@ -0,0 +1,3 @@
+// Package role contains the roles of the identities in a space, the
+// permissions they grant and the sources they are resolved from.
+package role
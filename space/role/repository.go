@@ -0,0 +1,140 @@
+package role
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeSpaceRoles helps to avoid string literal
+const APIStringTypeSpaceRoles = "spaceroles"
+
+// Assignment is the role of an identity in a space, as stored locally.
+type Assignment struct {
+	gormsupport.Lifecycle
+	SpaceID    uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	IdentityID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	Role       Role
+}
+
+// AssignmentTableName constant that holds table name of space roles
+const AssignmentTableName = "space_roles"
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (a Assignment) TableName() string {
+	return AssignmentTableName
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (a Assignment) GetETagData() []interface{} {
+	return []interface{}{a.SpaceID, a.IdentityID, a.Role, a.UpdatedAt.Unix()}
+}
+
+// GetLastModified returns the last modification time
+func (a Assignment) GetLastModified() time.Time {
+	return a.UpdatedAt.Truncate(time.Second)
+}
+
+// Repository describes interactions with the roles stored locally
+type Repository interface {
+	// Assign gives the role to the identity in the space, replacing its
+	// previous role if any.
+	Assign(ctx context.Context, spaceID, identityID uuid.UUID, role Role) (*Assignment, error)
+	// Revoke removes the role of the identity in the space.
+	Revoke(ctx context.Context, spaceID, identityID uuid.UUID) error
+	List(ctx context.Context, spaceID uuid.UUID) ([]Assignment, error)
+	// SpaceRole returns the role of the identity in the space or None.
+	SpaceRole(ctx context.Context, spaceID, identityID uuid.UUID) (Role, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for space
+// roles.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Assign gives the role to the identity in the space
+func (r *GormRepository) Assign(ctx context.Context, spaceID, identityID uuid.UUID, role Role) (*Assignment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "space_role", "assign"}, time.Now())
+	if err := role.Validate(); err != nil {
+		return nil, errors.NewBadParameterError("role", role).Expected("viewer, contributor, maintainer or admin")
+	}
+	upsertStmt := fmt.Sprintf(`INSERT INTO %s (space_id, identity_id, role, created_at, updated_at) VALUES ($1, $2, $3, now(), now())
+		ON CONFLICT (space_id, identity_id) DO UPDATE SET deleted_at = NULL, updated_at = now(), role = EXCLUDED.role`, AssignmentTableName)
+	if err := r.db.Exec(upsertStmt, spaceID, identityID, role).Error; err != nil {
+		if gormsupport.IsForeignKeyViolation(err, "space_roles_space_id_fkey") {
+			return nil, errors.NewNotFoundError("space", spaceID.String())
+		}
+		if gormsupport.IsForeignKeyViolation(err, "space_roles_identity_id_fkey") {
+			return nil, errors.NewNotFoundError("identity", identityID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"space_id":    spaceID,
+			"identity_id": identityID,
+			"role":        role,
+			"err":         err,
+		}, "unable to assign the space role")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	a := Assignment{}
+	if err := r.db.Where("space_id = ? AND identity_id = ?", spaceID, identityID).First(&a).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return &a, nil
+}
+
+// Revoke removes the role of the identity in the space
+func (r *GormRepository) Revoke(ctx context.Context, spaceID, identityID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "space_role", "revoke"}, time.Now())
+	tx := r.db.Where("space_id = ? AND identity_id = ?", spaceID, identityID).Delete(&Assignment{})
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id":    spaceID,
+			"identity_id": identityID,
+			"err":         err,
+		}, "unable to revoke the space role")
+		return errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("space role", identityID.String())
+	}
+	return nil
+}
+
+// List returns the roles stored for the given space
+func (r *GormRepository) List(ctx context.Context, spaceID uuid.UUID) ([]Assignment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "space_role", "list"}, time.Now())
+	var objs []Assignment
+	err := r.db.Where("space_id = ?", spaceID).Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// SpaceRole returns the role of the identity in the space or None
+func (r *GormRepository) SpaceRole(ctx context.Context, spaceID, identityID uuid.UUID) (Role, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "space_role", "load"}, time.Now())
+	a := Assignment{}
+	err := r.db.Where("space_id = ? AND identity_id = ?", spaceID, identityID).First(&a).Error
+	if err == gorm.ErrRecordNotFound {
+		return None, nil
+	}
+	if err != nil {
+		return None, errors.NewInternalError(ctx, err)
+	}
+	return a.Role, nil
+}
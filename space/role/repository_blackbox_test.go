@@ -0,0 +1,133 @@
+package role_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunRoleRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &roleRepositoryBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+type roleRepositoryBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	repo role.Repository
+}
+
+func (s *roleRepositoryBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.repo = role.NewRepository(s.DB)
+}
+
+func (s *roleRepositoryBlackBoxTest) TestAssign() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.Spaces(1))
+		// when
+		a, err := s.repo.Assign(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID, role.Maintainer)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, role.Maintainer, a.Role)
+		r, err := s.repo.SpaceRole(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, role.Maintainer, r)
+	})
+
+	s.T().Run("replaces the previous role", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.Spaces(1))
+		_, err := s.repo.Assign(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID, role.Admin)
+		require.NoError(t, err)
+		// when
+		a, err := s.repo.Assign(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID, role.Viewer)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, role.Viewer, a.Role)
+		assignments, err := s.repo.List(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		require.Len(t, assignments, 1)
+		assert.Equal(t, role.Viewer, assignments[0].Role)
+	})
+
+	s.T().Run("invalid role", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.Spaces(1))
+		// when
+		_, err := s.repo.Assign(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID, role.Role("owner"))
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, err)
+	})
+
+	s.T().Run("unknown identity", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		// when
+		_, err := s.repo.Assign(context.Background(), fxt.Spaces[0].ID, uuid.NewV4(), role.Viewer)
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *roleRepositoryBlackBoxTest) TestRevoke() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.Spaces(1))
+		_, err := s.repo.Assign(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID, role.Contributor)
+		require.NoError(t, err)
+		// when
+		err = s.repo.Revoke(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID)
+		// then
+		require.NoError(t, err)
+		r, err := s.repo.SpaceRole(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, role.None, r)
+		assignments, err := s.repo.List(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, assignments)
+	})
+
+	s.T().Run("not found", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.Spaces(1))
+		// when
+		err := s.repo.Revoke(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID)
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *roleRepositoryBlackBoxTest) TestResolve() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(3), tf.Spaces(1))
+	_, err := s.repo.Assign(context.Background(), fxt.Spaces[0].ID, fxt.Identities[1].ID, role.Maintainer)
+	require.NoError(s.T(), err)
+	sources := []role.Source{
+		role.Everyone(role.Viewer),
+		role.Owner(space.NewRepository(s.DB)),
+		s.repo,
+	}
+	testData := []struct {
+		name     string
+		identity uuid.UUID
+		expected role.Role
+	}{
+		{"owner", fxt.Identities[0].ID, role.Admin},
+		{"stored role", fxt.Identities[1].ID, role.Maintainer},
+		{"anyone", fxt.Identities[2].ID, role.Viewer},
+	}
+	for _, d := range testData {
+		s.T().Run(d.name, func(t *testing.T) {
+			r, err := role.Resolve(context.Background(), fxt.Spaces[0].ID, d.identity, sources...)
+			require.NoError(t, err)
+			assert.Equal(t, d.expected, r)
+		})
+	}
+}
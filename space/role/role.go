@@ -0,0 +1,98 @@
+package role
+
+import (
+	"fmt"
+)
+
+// Role is the role of an identity in a space. Each role grants the permissions
+// of the roles below it.
+type Role string
+
+// The roles of an identity in a space, from the least to the most privileged
+const (
+	// None is the absence of role
+	None        Role = ""
+	Viewer      Role = "viewer"
+	Contributor Role = "contributor"
+	Maintainer  Role = "maintainer"
+	Admin       Role = "admin"
+)
+
+// Roles lists the valid roles, from the least to the most privileged
+var Roles = []Role{Viewer, Contributor, Maintainer, Admin}
+
+func (r Role) level() int {
+	for i, role := range Roles {
+		if r == role {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Validate returns an error if the role is not one of Roles
+func (r Role) Validate() error {
+	if r.level() == 0 {
+		return fmt.Errorf("unknown role: %q", r)
+	}
+	return nil
+}
+
+// Includes returns true if the role grants all the permissions of the other
+// role
+func (r Role) Includes(other Role) bool {
+	return r.level() >= other.level()
+}
+
+// Can returns true if the role grants the given permission
+func (r Role) Can(p Permission) bool {
+	required, ok := requiredRoles[p]
+	return ok && r != None && r.Includes(required)
+}
+
+// Max returns the most privileged of the given roles
+func Max(roles ...Role) Role {
+	res := None
+	for _, r := range roles {
+		if r.level() > res.level() {
+			res = r
+		}
+	}
+	return res
+}
+
+// Permission is an operation in a space that requires a role. Its value
+// completes the sentence "the user is not allowed to ...".
+type Permission string
+
+// The permissions in a space
+const (
+	ReadSpace         Permission = "read the space"
+	SaveQueries       Permission = "save queries"
+	EditWorkItems     Permission = "edit work items"
+	LinkWorkItems     Permission = "link work items"
+	ManageLabels      Permission = "manage labels"
+	PlanIterations    Permission = "plan iterations"
+	ManageIterations  Permission = "manage iterations"
+	ManageAreas       Permission = "manage areas"
+	ManageCodebases   Permission = "manage codebases"
+	RecordDeployments Permission = "record deployments"
+	ManageSpace       Permission = "manage the space"
+	ManageRoles       Permission = "manage the roles"
+)
+
+// requiredRoles holds the least privileged role granting each permission
+var requiredRoles = map[Permission]Role{
+	ReadSpace:         Viewer,
+	SaveQueries:       Viewer,
+	EditWorkItems:     Contributor,
+	LinkWorkItems:     Contributor,
+	ManageLabels:      Contributor,
+	PlanIterations:    Contributor,
+	RecordDeployments: Contributor,
+	ManageIterations:  Maintainer,
+	ManageAreas:       Maintainer,
+	ManageCodebases:   Maintainer,
+	ManageSpace:       Admin,
+	ManageRoles:       Admin,
+}
@@ -0,0 +1,60 @@
+package role_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleCan(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	testData := []struct {
+		role       role.Role
+		permission role.Permission
+		expected   bool
+	}{
+		{role.None, role.ReadSpace, false},
+		{role.Viewer, role.ReadSpace, true},
+		{role.Viewer, role.SaveQueries, true},
+		{role.Viewer, role.EditWorkItems, false},
+		{role.Contributor, role.EditWorkItems, true},
+		{role.Contributor, role.PlanIterations, true},
+		{role.Viewer, role.RecordDeployments, false},
+		{role.Contributor, role.RecordDeployments, true},
+		{role.Contributor, role.ManageIterations, false},
+		{role.Maintainer, role.ManageAreas, true},
+		{role.Maintainer, role.ManageCodebases, true},
+		{role.Maintainer, role.ManageSpace, false},
+		{role.Admin, role.ManageSpace, true},
+		{role.Admin, role.ManageRoles, true},
+		{role.Admin, role.Permission("fly"), false},
+		{role.Role("owner"), role.ReadSpace, false},
+	}
+	for _, d := range testData {
+		t.Run(string(d.role)+" "+string(d.permission), func(t *testing.T) {
+			assert.Equal(t, d.expected, d.role.Can(d.permission))
+		})
+	}
+}
+
+func TestRoleMax(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	assert.Equal(t, role.None, role.Max())
+	assert.Equal(t, role.None, role.Max(role.None, role.Role("owner")))
+	assert.Equal(t, role.Maintainer, role.Max(role.Viewer, role.Maintainer, role.Contributor))
+	assert.Equal(t, role.Admin, role.Max(role.Admin, role.Viewer))
+}
+
+func TestRoleValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	for _, r := range role.Roles {
+		assert.NoError(t, r.Validate())
+	}
+	assert.Error(t, role.None.Validate())
+	assert.Error(t, role.Role("owner").Validate())
+}
@@ -0,0 +1,64 @@
+package role
+
+import (
+	"context"
+
+	"github.com/fabric8-services/fabric8-wit/space"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Source gives the role of an identity in a space, e.g. the roles stored
+// locally (see Repository) or the collaborators known by Keycloak.
+type Source interface {
+	// SpaceRole returns the role of the identity in the space or None if the
+	// source doesn't know the identity.
+	SpaceRole(ctx context.Context, spaceID, identityID uuid.UUID) (Role, error)
+}
+
+// SourceFunc adapts a function to the Source interface
+type SourceFunc func(ctx context.Context, spaceID, identityID uuid.UUID) (Role, error)
+
+// SpaceRole calls f
+func (f SourceFunc) SpaceRole(ctx context.Context, spaceID, identityID uuid.UUID) (Role, error) {
+	return f(ctx, spaceID, identityID)
+}
+
+// Everyone gives the same role to every identity. Spaces are public, hence
+// every authenticated identity is at least a Viewer.
+func Everyone(r Role) Source {
+	return SourceFunc(func(ctx context.Context, spaceID, identityID uuid.UUID) (Role, error) {
+		return r, nil
+	})
+}
+
+// Owner gives the Admin role to the owner of the space.
+func Owner(spaces space.Repository) Source {
+	return SourceFunc(func(ctx context.Context, spaceID, identityID uuid.UUID) (Role, error) {
+		s, err := spaces.Load(ctx, spaceID)
+		if err != nil {
+			return None, err
+		}
+		if uuid.Equal(s.OwnerID, identityID) {
+			return Admin, nil
+		}
+		return None, nil
+	})
+}
+
+// Resolve returns the most privileged role of the identity in the space among
+// the ones given by the sources.
+func Resolve(ctx context.Context, spaceID, identityID uuid.UUID, sources ...Source) (Role, error) {
+	res := None
+	for _, s := range sources {
+		r, err := s.SpaceRole(ctx, spaceID, identityID)
+		if err != nil {
+			return None, errs.Wrapf(err, "failed to resolve the role of identity %s in space %s", identityID, spaceID)
+		}
+		res = Max(res, r)
+		if res == Admin {
+			break
+		}
+	}
+	return res, nil
+}
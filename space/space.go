@@ -26,6 +26,10 @@ var (
 	SpaceType   = "spaces"
 )
 
+// DefaultSearchConfig is the text search configuration used to index and
+// search the work items of a space unless another one is set.
+const DefaultSearchConfig = "english"
+
 // Space represents a Space on the domain and db layer
 type Space struct {
 	gormsupport.Lifecycle
@@ -35,6 +39,10 @@ type Space struct {
 	Description     string
 	OwnerID         uuid.UUID `sql:"type:uuid"` // Belongs To Identity
 	SpaceTemplateID uuid.UUID `sql:"type:uuid"`
+	// SearchConfig is the name of the PostgreSQL text search configuration
+	// (e.g. "german" or "simple") used to index and search the work items of
+	// the space
+	SearchConfig string
 }
 
 // Ensure Fields implements the Equaler interface
@@ -66,6 +74,9 @@ func (p Space) Equal(u convert.Equaler) bool {
 	if !uuid.Equal(p.OwnerID, other.OwnerID) {
 		return false
 	}
+	if p.SearchConfig != other.SearchConfig {
+		return false
+	}
 	return true
 }
 
@@ -231,6 +242,9 @@ func (r *GormRepository) Save(ctx context.Context, p *Space) (*Space, error) {
 		}, "unable to find the space by ID")
 		return nil, errors.NewInternalError(ctx, err)
 	}
+	if err := r.checkSearchConfig(ctx, p); err != nil {
+		return nil, err
+	}
 	tx = tx.Where("Version = ?", oldVersion).Save(p)
 	if err := tx.Error; err != nil {
 		if gormsupport.IsCheckViolation(tx.Error, "spaces_name_check") {
@@ -274,6 +288,9 @@ func (r *GormRepository) Create(ctx context.Context, space *Space) (*Space, erro
 	if !templ.CanConstruct {
 		return nil, errors.NewForbiddenError(fmt.Sprintf("space template \"%s\" (ID: %s) cannot create spaces", templ.Name, templ.ID))
 	}
+	if err := r.checkSearchConfig(ctx, space); err != nil {
+		return nil, err
+	}
 
 	tx := r.db.Create(space)
 	if err := tx.Error; err != nil {
@@ -295,6 +312,26 @@ func (r *GormRepository) Create(ctx context.Context, space *Space) (*Space, erro
 	return space, nil
 }
 
+// checkSearchConfig defaults the search configuration of the given space and
+// returns a BadParameterError if PostgreSQL doesn't know it
+func (r *GormRepository) checkSearchConfig(ctx context.Context, s *Space) error {
+	if s.SearchConfig == "" {
+		s.SearchConfig = DefaultSearchConfig
+	}
+	var count int
+	if err := r.db.Table("pg_ts_config").Where("cfgname = ?", s.SearchConfig).Count(&count).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":           err,
+			"search_config": s.SearchConfig,
+		}, "unable to look up the text search configuration")
+		return errors.NewInternalError(ctx, err)
+	}
+	if count == 0 {
+		return errors.NewBadParameterError("search_config", s.SearchConfig).Expected("a text search configuration of the database")
+	}
+	return nil
+}
+
 // extracted this function from List() in order to close the rows object with "defer" for more readability
 // workaround for https://github.com/lib/pq/issues/81
 func (r *GormRepository) listSpaceFromDB(ctx context.Context, q *string, userID *uuid.UUID, start *int, limit *int) ([]Space, int, error) {
@@ -0,0 +1,131 @@
+package che
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/fabric8-services/fabric8-wit/codebase/che"
+)
+
+// The statuses of the workspaces of the FakeStarter
+const (
+	StatusRunning = "RUNNING"
+	StatusStopped = "STOPPED"
+)
+
+// FakeStarter is a simple che-starter HTTP server keeping the workspaces in
+// memory and recording the requests for later verification
+type FakeStarter struct {
+	*httptest.Server
+	mu         sync.Mutex
+	workspaces map[string]*che.WorkspaceResponse
+	// Requests are the received requests, as "<method> <path>"
+	Requests []string
+	// Tokens are the bearer tokens of the received requests
+	Tokens []string
+}
+
+// NewFakeStarter starts a new FakeStarter, which must be closed by the caller
+func NewFakeStarter() *FakeStarter {
+	s := &FakeStarter{workspaces: map[string]*che.WorkspaceResponse{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// AddWorkspace adds a workspace of the given repository with the given status
+func (s *FakeStarter) AddWorkspace(name, repository, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addWorkspace(name, repository, status)
+}
+
+func (s *FakeStarter) addWorkspace(name, repository, status string) *che.WorkspaceResponse {
+	ws := &che.WorkspaceResponse{
+		ID:     "id-" + name,
+		Status: status,
+		Config: che.WorkspaceConfig{
+			Name:     name,
+			Projects: []che.WorkspaceProject{{Source: che.ProjectSource{Location: repository}}},
+		},
+		Links: []che.WorkspaceLink{
+			{Rel: che.IdeUrlRel, Method: "GET", Href: "https://che.example.com/user/" + name},
+			{Rel: che.SelfLinkRel, Method: "GET", Href: "https://che.example.com/api/workspace/id-" + name},
+			{Rel: "delete", Method: "DELETE", Href: "https://che.example.com/api/workspace/id-" + name},
+		},
+	}
+	s.workspaces[name] = ws
+	return ws
+}
+
+// Status returns the status of the workspace, or an empty string if it
+// doesn't exist
+func (s *FakeStarter) Status(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w, ok := s.workspaces[name]; ok {
+		return w.Status
+	}
+	return ""
+}
+
+func (s *FakeStarter) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Requests = append(s.Requests, r.Method+" "+r.URL.Path)
+	s.Tokens = append(s.Tokens, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("Authorization") == "" {
+		s.fail(w, http.StatusUnauthorized, "missing token")
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && len(path) == 1 && path[0] == "workspace":
+		repository := r.URL.Query().Get("repository")
+		res := []*che.WorkspaceResponse{}
+		for _, ws := range s.workspaces {
+			if ws.Config.Projects[0].Source.Location == repository {
+				res = append(res, ws)
+			}
+		}
+		json.NewEncoder(w).Encode(res)
+	case r.Method == http.MethodPost && len(path) == 1 && path[0] == "workspace":
+		var req che.WorkspaceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.fail(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		name := fmt.Sprintf("wksp-%d", len(s.workspaces)+1)
+		json.NewEncoder(w).Encode(s.addWorkspace(name, req.Repository, StatusRunning))
+	case len(path) >= 2 && path[0] == "workspace":
+		ws, ok := s.workspaces[path[1]]
+		if !ok {
+			s.fail(w, http.StatusNotFound, "workspace not found")
+			return
+		}
+		switch {
+		case r.Method == http.MethodPatch && len(path) == 2:
+			ws.Status = StatusRunning
+			json.NewEncoder(w).Encode(ws)
+		case r.Method == http.MethodDelete && len(path) == 2:
+			delete(s.workspaces, path[1])
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete && len(path) == 3 && path[2] == "runtime":
+			ws.Status = StatusStopped
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			s.fail(w, http.StatusMethodNotAllowed, "unsupported operation")
+		}
+	default:
+		s.fail(w, http.StatusNotFound, "unknown resource")
+	}
+}
+
+func (s *FakeStarter) fail(w http.ResponseWriter, status int, msg string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(che.StarterError{Status: status, ErrorMsg: http.StatusText(status), Message: msg})
+}
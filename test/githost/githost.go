@@ -0,0 +1,28 @@
+package githost
+
+import (
+	"context"
+
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/codebase/githost"
+	"github.com/fabric8-services/fabric8-wit/errors"
+)
+
+// FakeClient is a simple githost.Client impl that returns the metadata set
+// for each repository, keyed by "owner/name", and records the fetched
+// repositories for later verification
+type FakeClient struct {
+	Metadata map[string]codebase.Metadata
+	Fetched  []githost.Repository
+}
+
+// Fetch returns a copy of the metadata of the repository, or a not found
+// error if no metadata is set for it
+func (c *FakeClient) Fetch(ctx context.Context, repo githost.Repository) (*codebase.Metadata, error) {
+	c.Fetched = append(c.Fetched, repo)
+	m, ok := c.Metadata[repo.Owner+"/"+repo.Name]
+	if !ok {
+		return nil, errors.NewNotFoundError("repository", repo.String())
+	}
+	return &m, nil
+}
@@ -2,8 +2,11 @@ package test
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 
 	"github.com/fabric8-services/fabric8-wit/account"
+	witmiddleware "github.com/fabric8-services/fabric8-wit/goamiddleware"
 	tokencontext "github.com/fabric8-services/fabric8-wit/login/tokencontext"
 	"github.com/fabric8-services/fabric8-wit/space/authz"
 	testtoken "github.com/fabric8-services/fabric8-wit/test/token"
@@ -62,6 +65,30 @@ func WithServiceAccountAuthz(ctx context.Context, key interface{}, ident account
 	return goajwt.WithJWT(ctx, token)
 }
 
+// WithResolvedToken fills the context with the given token as the
+// TokenContext middleware does for a token that is not a JWT (e.g. a personal
+// access token), resolving it into the given Identity
+func WithResolvedToken(ctx context.Context, rawToken string, ident account.Identity) context.Context {
+	resolver := func(ctx context.Context, incomingToken string) (jwt.MapClaims, error) {
+		return fillClaimsWithIdentity(ident).Claims.(jwt.MapClaims), nil
+	}
+	var resolvedCtx context.Context
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		resolvedCtx = ctx
+		return nil
+	}
+	middleware := witmiddleware.TokenContext([]byte("secret"), nil, &goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"}, resolver)
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	if err != nil {
+		panic(err.Error())
+	}
+	req.Header.Set("Authorization", "Bearer "+rawToken)
+	if err := middleware(h)(ctx, httptest.NewRecorder(), req); err != nil {
+		panic(err.Error())
+	}
+	return resolvedCtx
+}
+
 func fillClaimsWithIdentity(ident account.Identity) *jwt.Token {
 	token := jwt.New(jwt.SigningMethodRS256)
 	token.Claims.(jwt.MapClaims)["sub"] = ident.ID.String()
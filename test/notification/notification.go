@@ -4,14 +4,20 @@ import (
 	"context"
 
 	"github.com/fabric8-services/fabric8-wit/notification"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 )
 
 // FakeNotificationChannel is a simple Sender impl that records the notifications for later verification
 type FakeNotificationChannel struct {
 	Messages []notification.Message
+	// Tokens are the raw tokens the messages were sent with, if any
+	Tokens []string
 }
 
 // Send records each sent message in Messages
 func (s *FakeNotificationChannel) Send(ctx context.Context, msg notification.Message) {
 	s.Messages = append(s.Messages, msg)
+	if token := goajwt.ContextJWT(ctx); token != nil {
+		s.Tokens = append(s.Tokens, token.Raw)
+	}
 }
@@ -0,0 +1,141 @@
+package accesstoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypePersonalAccessTokens helps to avoid string literal
+const APIStringTypePersonalAccessTokens = "personal-access-tokens"
+
+// Prefix is the prefix of all the personal access tokens, which tells them
+// apart from the JWTs
+const Prefix = "f8pat_"
+
+// MaxLifetime is the maximum duration of a personal access token
+const MaxLifetime = 365 * 24 * time.Hour
+
+// Scope limits what a personal access token can be used for
+type Scope string
+
+// The scopes of the personal access tokens
+const (
+	// ScopeRead only allows to read
+	ScopeRead Scope = "read"
+	// ScopeWriteWorkItems allows to do what a contributor of a space can do,
+	// i.e. to create and edit the work items
+	ScopeWriteWorkItems Scope = "write:workitems"
+	// ScopeAdminSpace allows to do what the user can do
+	ScopeAdminSpace Scope = "admin:space"
+)
+
+// Scopes lists the valid scopes
+var Scopes = []Scope{ScopeRead, ScopeWriteWorkItems, ScopeAdminSpace}
+
+// Validate returns an error if the scope is not one of Scopes
+func (s Scope) Validate() error {
+	for _, scope := range Scopes {
+		if s == scope {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown scope: %q", s)
+}
+
+// AllowsMethod returns true if a request with the given HTTP method can be
+// authenticated with a token having the scope
+func (s Scope) AllowsMethod(method string) bool {
+	if s != ScopeRead {
+		return s.Validate() == nil
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// Restrict returns the role the user has in a space when using a token with
+// the scope, given the role the user has in the space
+func (s Scope) Restrict(r role.Role) role.Role {
+	var max role.Role
+	switch s {
+	case ScopeRead:
+		max = role.Viewer
+	case ScopeWriteWorkItems:
+		max = role.Contributor
+	case ScopeAdminSpace:
+		max = role.Admin
+	default:
+		return role.None
+	}
+	if max.Includes(r) {
+		return r
+	}
+	return max
+}
+
+// PersonalAccessToken is a named, scoped and expiring token that a user mints
+// to authenticate bots and scripts. Only the hash of the token is stored.
+type PersonalAccessToken struct {
+	gormsupport.Lifecycle
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	IdentityID uuid.UUID `sql:"type:uuid"`
+	Name       string
+	Scope      Scope
+	TokenHash  string
+	ExpiresAt  time.Time
+	LastUsedAt *time.Time
+}
+
+// PersonalAccessTokenTableName constant that holds table name of personal
+// access tokens
+const PersonalAccessTokenTableName = "personal_access_tokens"
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (t PersonalAccessToken) TableName() string {
+	return PersonalAccessTokenTableName
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (t PersonalAccessToken) GetETagData() []interface{} {
+	return []interface{}{t.ID, t.UpdatedAt.Unix()}
+}
+
+// GetLastModified returns the last modification time
+func (t PersonalAccessToken) GetLastModified() time.Time {
+	return t.UpdatedAt.Truncate(time.Second)
+}
+
+// IsPersonalAccessToken returns true if the given string has the format of a
+// personal access token
+func IsPersonalAccessToken(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+// generate returns a new random token
+func generate() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return Prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hash returns the hash of the token as stored in the database. The tokens
+// are random enough for a plain SHA-256 to be safe.
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
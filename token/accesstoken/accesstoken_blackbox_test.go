@@ -0,0 +1,103 @@
+package accesstoken_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	witmiddleware "github.com/fabric8-services/fabric8-wit/goamiddleware"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/fabric8-services/fabric8-wit/token/accesstoken"
+	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeRestrict(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	assert.Equal(t, role.Viewer, accesstoken.ScopeRead.Restrict(role.Admin))
+	assert.Equal(t, role.None, accesstoken.ScopeRead.Restrict(role.None))
+	assert.Equal(t, role.Contributor, accesstoken.ScopeWriteWorkItems.Restrict(role.Maintainer))
+	assert.Equal(t, role.Viewer, accesstoken.ScopeWriteWorkItems.Restrict(role.Viewer))
+	assert.Equal(t, role.Admin, accesstoken.ScopeAdminSpace.Restrict(role.Admin))
+	assert.Equal(t, role.None, accesstoken.Scope("all").Restrict(role.Admin))
+}
+
+func TestScopeAllowsMethod(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	assert.True(t, accesstoken.ScopeRead.AllowsMethod(http.MethodGet))
+	assert.False(t, accesstoken.ScopeRead.AllowsMethod(http.MethodPatch))
+	assert.False(t, accesstoken.ScopeRead.AllowsMethod(http.MethodDelete))
+	assert.True(t, accesstoken.ScopeWriteWorkItems.AllowsMethod(http.MethodPost))
+	assert.True(t, accesstoken.ScopeAdminSpace.AllowsMethod(http.MethodDelete))
+	assert.False(t, accesstoken.Scope("all").AllowsMethod(http.MethodGet))
+}
+
+func TestTokenContext(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	identityID := uuid.NewV4()
+	pat := accesstoken.PersonalAccessToken{
+		ID:         uuid.NewV4(),
+		IdentityID: identityID,
+		Scope:      accesstoken.ScopeRead,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	resolver := func(ctx context.Context, incomingToken string) (jwt.MapClaims, error) {
+		if !accesstoken.IsPersonalAccessToken(incomingToken) {
+			return nil, nil
+		}
+		return pat.Claims(), nil
+	}
+	// the JWT middleware of the secured actions rejects all the requests
+	jwtMiddleware := func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return goajwt.ErrJWTError("invalid JWT")
+		}
+	}
+	serve := func(t *testing.T, method, authorization string) (context.Context, error) {
+		var handledCtx context.Context
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			handledCtx = ctx
+			return nil
+		}
+		middleware := witmiddleware.TokenContext([]byte("secret"), nil, &goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"}, resolver)
+		req, err := http.NewRequest(method, "/api/spaces", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", authorization)
+		err = middleware(accesstoken.JWTMiddleware(jwtMiddleware)(h))(context.Background(), httptest.NewRecorder(), req)
+		return handledCtx, err
+	}
+
+	t.Run("personal access token", func(t *testing.T) {
+		ctx, err := serve(t, http.MethodGet, "Bearer "+accesstoken.Prefix+"abc")
+		require.NoError(t, err)
+		require.NotNil(t, ctx)
+		assert.True(t, witmiddleware.IsResolvedToken(ctx))
+		scope, ok := accesstoken.ContextScope(ctx)
+		require.True(t, ok)
+		assert.Equal(t, accesstoken.ScopeRead, scope)
+		assert.Equal(t, identityID.String(), goajwt.ContextJWT(ctx).Claims.(jwt.MapClaims)["sub"])
+	})
+
+	t.Run("scope forbids the method", func(t *testing.T) {
+		_, err := serve(t, http.MethodPatch, "Bearer "+accesstoken.Prefix+"abc")
+		require.Error(t, err)
+	})
+
+	t.Run("forged claims in a JWT", func(t *testing.T) {
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, pat.Claims())
+		raw, err := forged.SignedString([]byte("not the secret"))
+		require.NoError(t, err)
+		ctx, err := serve(t, http.MethodGet, "Bearer "+raw)
+		require.Error(t, err)
+		assert.Nil(t, ctx)
+	})
+}
@@ -0,0 +1,84 @@
+package accesstoken
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	witmiddleware "github.com/fabric8-services/fabric8-wit/goamiddleware"
+	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	"github.com/jinzhu/gorm"
+)
+
+// The claims of the JWT standing for a personal access token in the context,
+// on top of the "sub" claim holding the ID of the owning identity
+const (
+	ClaimTokenID = "pat_id"
+	ClaimScope   = "pat_scope"
+)
+
+// Claims returns the claims of the JWT standing for the token in the context
+func (t PersonalAccessToken) Claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":        t.IdentityID.String(),
+		"exp":        t.ExpiresAt.Unix(),
+		ClaimTokenID: t.ID.String(),
+		ClaimScope:   string(t.Scope),
+	}
+}
+
+// ContextScope returns the scope of the personal access token the request is
+// authenticated with, or false if the request is not authenticated with a
+// personal access token.
+func ContextScope(ctx context.Context) (Scope, bool) {
+	if !witmiddleware.IsResolvedToken(ctx) {
+		return "", false
+	}
+	token := goajwt.ContextJWT(ctx)
+	if token == nil {
+		return "", false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", false
+	}
+	scope, ok := claims[ClaimScope].(string)
+	return Scope(scope), ok
+}
+
+// TokenResolver returns the resolver that authenticates the personal access
+// tokens given in the Authorization header
+func TokenResolver(db *gorm.DB) witmiddleware.TokenResolver {
+	return func(ctx context.Context, incomingToken string) (jwt.MapClaims, error) {
+		if !IsPersonalAccessToken(incomingToken) {
+			return nil, nil
+		}
+		t, err := NewRepository(db).Authenticate(ctx, incomingToken)
+		if err != nil {
+			return nil, err
+		}
+		return t.Claims(), nil
+	}
+}
+
+// JWTMiddleware wraps the middleware that validates the JWT of the secured
+// actions so that the requests authenticated with a personal access token skip
+// it, provided the scope of the token allows the request.
+func JWTMiddleware(jwtMiddleware goa.Middleware) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		secured := jwtMiddleware(h)
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			scope, ok := ContextScope(ctx)
+			if !ok {
+				return secured(ctx, rw, req)
+			}
+			if !scope.AllowsMethod(req.Method) {
+				return errors.NewForbiddenError(fmt.Sprintf("personal access tokens with the %q scope can't be used for %s requests", scope, req.Method))
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+// Package accesstoken contains the personal access tokens that users mint for
+// bots and scripts, and the code that accepts them in place of the JWTs issued
+// by Keycloak.
+package accesstoken
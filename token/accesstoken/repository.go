@@ -0,0 +1,131 @@
+package accesstoken
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Repository describes interactions with the personal access tokens
+type Repository interface {
+	// Create stores the given token, whose ID and hash are generated, and
+	// returns the token to give to the user. It can't be retrieved later.
+	Create(ctx context.Context, t *PersonalAccessToken) (string, error)
+	// List returns the tokens of the identity that are not revoked, including
+	// the expired ones
+	List(ctx context.Context, identityID uuid.UUID) ([]PersonalAccessToken, error)
+	// Revoke revokes the token of the identity
+	Revoke(ctx context.Context, identityID, id uuid.UUID) error
+	// Authenticate returns the valid token matching the given one and records
+	// its usage
+	Authenticate(ctx context.Context, token string) (*PersonalAccessToken, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for personal
+// access tokens.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create stores the given token and returns the token to give to the user
+func (r *GormRepository) Create(ctx context.Context, t *PersonalAccessToken) (string, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "personal_access_token", "create"}, time.Now())
+	if t.IdentityID == uuid.Nil {
+		return "", errors.NewBadParameterError("identity_id", t.IdentityID).Expected("valid identity ID")
+	}
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return "", errors.NewBadParameterError("name", t.Name).Expected("not empty")
+	}
+	if err := t.Scope.Validate(); err != nil {
+		return "", errors.NewBadParameterError("scope", t.Scope).Expected(fmt.Sprintf("one of %v", Scopes))
+	}
+	now := time.Now()
+	if !t.ExpiresAt.After(now) || t.ExpiresAt.After(now.Add(MaxLifetime)) {
+		return "", errors.NewBadParameterError("expires_at", t.ExpiresAt).Expected(fmt.Sprintf("date in the next %d days", MaxLifetime/(24*time.Hour)))
+	}
+	token, err := generate()
+	if err != nil {
+		return "", errors.NewInternalError(ctx, errs.Wrap(err, "failed to generate the personal access token"))
+	}
+	t.ID = uuid.NewV4()
+	t.TokenHash = hash(token)
+	t.LastUsedAt = nil
+	if err := r.db.Create(t).Error; err != nil {
+		if gormsupport.IsForeignKeyViolation(err, "personal_access_tokens_identity_id_fkey") {
+			return "", errors.NewNotFoundError("identity", t.IdentityID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"identity_id": t.IdentityID,
+			"err":         err,
+		}, "unable to create the personal access token")
+		return "", errors.NewInternalError(ctx, err)
+	}
+	return token, nil
+}
+
+// List returns the tokens of the identity that are not revoked
+func (r *GormRepository) List(ctx context.Context, identityID uuid.UUID) ([]PersonalAccessToken, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "personal_access_token", "list"}, time.Now())
+	var objs []PersonalAccessToken
+	err := r.db.Where("identity_id = ?", identityID).Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// Revoke revokes the token of the identity
+func (r *GormRepository) Revoke(ctx context.Context, identityID, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "personal_access_token", "revoke"}, time.Now())
+	tx := r.db.Where("id = ? AND identity_id = ?", id, identityID).Delete(&PersonalAccessToken{})
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"identity_id": identityID,
+			"token_id":    id,
+			"err":         err,
+		}, "unable to revoke the personal access token")
+		return errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("personal access token", id.String())
+	}
+	return nil
+}
+
+// Authenticate returns the valid token matching the given one. The last usage
+// of the token is recorded at most once per minute.
+func (r *GormRepository) Authenticate(ctx context.Context, token string) (*PersonalAccessToken, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "personal_access_token", "authenticate"}, time.Now())
+	t := PersonalAccessToken{}
+	err := r.db.Where("token_hash = ? AND expires_at > now()", hash(token)).First(&t).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewUnauthorizedError("invalid, expired or revoked personal access token")
+	}
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	err = r.db.Exec(fmt.Sprintf(`UPDATE %s SET last_used_at = now()
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')`, PersonalAccessTokenTableName), t.ID).Error
+	if err != nil {
+		log.Warn(ctx, map[string]interface{}{
+			"token_id": t.ID,
+			"err":      err,
+		}, "unable to record the usage of the personal access token")
+	}
+	return &t, nil
+}
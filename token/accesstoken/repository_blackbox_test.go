@@ -0,0 +1,128 @@
+package accesstoken_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/token/accesstoken"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunPersonalAccessTokenRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &personalAccessTokenRepositoryBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+type personalAccessTokenRepositoryBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	repo accesstoken.Repository
+}
+
+func (s *personalAccessTokenRepositoryBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.repo = accesstoken.NewRepository(s.DB)
+}
+
+func (s *personalAccessTokenRepositoryBlackBoxTest) TestCreate() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		pat := accesstoken.PersonalAccessToken{
+			IdentityID: fxt.Identities[0].ID,
+			Name:       "bot",
+			Scope:      accesstoken.ScopeWriteWorkItems,
+			ExpiresAt:  time.Now().Add(24 * time.Hour),
+		}
+		// when
+		token, err := s.repo.Create(context.Background(), &pat)
+		// then
+		require.NoError(t, err)
+		assert.True(t, accesstoken.IsPersonalAccessToken(token))
+		assert.NotEqual(t, uuid.Nil, pat.ID)
+		assert.NotContains(t, pat.TokenHash, token)
+		authenticated, err := s.repo.Authenticate(context.Background(), token)
+		require.NoError(t, err)
+		assert.Equal(t, pat.ID, authenticated.ID)
+		assert.Equal(t, fxt.Identities[0].ID, authenticated.IdentityID)
+		tokens, err := s.repo.List(context.Background(), fxt.Identities[0].ID)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		require.NotNil(t, tokens[0].LastUsedAt)
+	})
+
+	s.T().Run("invalid", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		testData := map[string]accesstoken.PersonalAccessToken{
+			"empty name":     {IdentityID: fxt.Identities[0].ID, Name: " ", Scope: accesstoken.ScopeRead, ExpiresAt: time.Now().Add(time.Hour)},
+			"unknown scope":  {IdentityID: fxt.Identities[0].ID, Name: "bot", Scope: "all", ExpiresAt: time.Now().Add(time.Hour)},
+			"expired":        {IdentityID: fxt.Identities[0].ID, Name: "bot", Scope: accesstoken.ScopeRead, ExpiresAt: time.Now().Add(-time.Hour)},
+			"too long lived": {IdentityID: fxt.Identities[0].ID, Name: "bot", Scope: accesstoken.ScopeRead, ExpiresAt: time.Now().Add(2 * accesstoken.MaxLifetime)},
+		}
+		for name, pat := range testData {
+			t.Run(name, func(t *testing.T) {
+				_, err := s.repo.Create(context.Background(), &pat)
+				require.Error(t, err)
+				assert.IsType(t, errors.BadParameterError{}, err)
+			})
+		}
+	})
+}
+
+func (s *personalAccessTokenRepositoryBlackBoxTest) TestAuthenticate() {
+	s.T().Run("unknown token", func(t *testing.T) {
+		_, err := s.repo.Authenticate(context.Background(), accesstoken.Prefix+"unknown")
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, err)
+	})
+
+	s.T().Run("expired token", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		pat := accesstoken.PersonalAccessToken{
+			IdentityID: fxt.Identities[0].ID,
+			Name:       "bot",
+			Scope:      accesstoken.ScopeRead,
+			ExpiresAt:  time.Now().Add(time.Hour),
+		}
+		token, err := s.repo.Create(context.Background(), &pat)
+		require.NoError(t, err)
+		require.NoError(t, s.DB.Model(&pat).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+		// when
+		_, err = s.repo.Authenticate(context.Background(), token)
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, err)
+	})
+
+	s.T().Run("revoked token", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2))
+		pat := accesstoken.PersonalAccessToken{
+			IdentityID: fxt.Identities[0].ID,
+			Name:       "bot",
+			Scope:      accesstoken.ScopeRead,
+			ExpiresAt:  time.Now().Add(time.Hour),
+		}
+		token, err := s.repo.Create(context.Background(), &pat)
+		require.NoError(t, err)
+		// only the owner can revoke the token
+		err = s.repo.Revoke(context.Background(), fxt.Identities[1].ID, pat.ID)
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, err)
+		// when
+		err = s.repo.Revoke(context.Background(), fxt.Identities[0].ID, pat.ID)
+		// then
+		require.NoError(t, err)
+		_, err = s.repo.Authenticate(context.Background(), token)
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, err)
+		tokens, err := s.repo.List(context.Background(), fxt.Identities[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+}
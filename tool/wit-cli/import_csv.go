@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/client"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/spf13/cobra"
+)
+
+// importCSVCommand imports work items from a CSV file with the work item
+// import action. The file is only validated unless --commit is given.
+type importCSVCommand struct {
+	space           string
+	file            string
+	columns         []string
+	typeID          string
+	referenceColumn string
+	parentColumn    string
+	createMissing   bool
+	commit          bool
+}
+
+// registerImportCSVCommand registers the import-csv command
+func registerImportCSVCommand(app *cobra.Command, c *client.Client) {
+	cmd := &importCSVCommand{}
+	cc := &cobra.Command{
+		Use:   "import-csv",
+		Short: "Import work items from a CSV file",
+		Long: `Import work items from a CSV file into a space. The rows are only validated and their errors
+reported, unless --commit is given in which case all the work items are created at once if no row has errors.`,
+		Example: `wit-cli import-csv --space 9c3a5f4e-... --file backlog.csv --map Title=system.title \
+  --map Assignee=system.assignees --map Kind=type --reference-column ID --parent-column Parent --commit`,
+		RunE: func(cc *cobra.Command, args []string) error {
+			return cmd.run(c)
+		},
+	}
+	cc.Flags().StringVar(&cmd.space, "space", "", "ID of the space to import the work items into")
+	cc.Flags().StringVar(&cmd.file, "file", "", "Path of the CSV file, whose first line is the header")
+	cc.Flags().StringArrayVar(&cmd.columns, "map", nil, `Maps a column to a work item field or to "type", as column=field`)
+	cc.Flags().StringVar(&cmd.typeID, "type", "", `ID of the type of the work items whose type is not given by a column mapped to "type"`)
+	cc.Flags().StringVar(&cmd.referenceColumn, "reference-column", "", "Column holding a reference to the row that is unique in the file")
+	cc.Flags().StringVar(&cmd.parentColumn, "parent-column", "", "Column holding the reference of the row of the parent work item")
+	cc.Flags().BoolVar(&cmd.createMissing, "create-missing", false, "Create the iterations, areas and labels that don't exist")
+	cc.Flags().BoolVar(&cmd.commit, "commit", false, "Create the work items instead of only validating the rows")
+	app.AddCommand(cc)
+}
+
+func (cmd *importCSVCommand) run(c *client.Client) error {
+	spaceID, err := uuid.FromString(cmd.space)
+	if err != nil {
+		return errs.Wrapf(err, "invalid space ID %q", cmd.space)
+	}
+	content, err := ioutil.ReadFile(cmd.file)
+	if err != nil {
+		return errs.Wrapf(err, "failed to read %s", cmd.file)
+	}
+	columns := map[string]string{}
+	for _, m := range cmd.columns {
+		parts := strings.SplitN(m, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return errs.Errorf("invalid column mapping %q, expected column=field", m)
+		}
+		columns[parts[0]] = parts[1]
+	}
+	dryRun := !cmd.commit
+	payload := &client.WorkItemImportSingle{
+		Data: &client.WorkItemImport{
+			Type: "workitemimports",
+			Attributes: &client.WorkItemImportAttributes{
+				Csv:           string(content),
+				Columns:       columns,
+				CreateMissing: &cmd.createMissing,
+				DryRun:        &dryRun,
+			},
+		},
+	}
+	if cmd.referenceColumn != "" {
+		payload.Data.Attributes.ReferenceColumn = &cmd.referenceColumn
+	}
+	if cmd.parentColumn != "" {
+		payload.Data.Attributes.ParentColumn = &cmd.parentColumn
+	}
+	if cmd.typeID != "" {
+		typeID, err := uuid.FromString(cmd.typeID)
+		if err != nil {
+			return errs.Wrapf(err, "invalid type ID %q", cmd.typeID)
+		}
+		payload.Data.Relationships = &client.WorkItemImportRelationships{
+			BaseType: &client.RelationBaseType{
+				Data: &client.BaseTypeData{ID: typeID, Type: "workitemtypes"},
+			},
+		}
+	}
+	resp, err := c.ImportWorkItemImport(context.Background(), client.ImportWorkItemImportPath(spaceID), payload, "")
+	if err != nil {
+		return errs.Wrap(err, "failed to import the work items")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return errs.Errorf("failed to import the work items: %s %s", resp.Status, body)
+	}
+	result, err := c.DecodeWorkItemImportSingle(resp)
+	if err != nil {
+		return errs.Wrap(err, "failed to decode the import report")
+	}
+	return printImportReport(result.Data.Attributes)
+}
+
+// printImportReport prints the errors of the rows and what was or would be
+// created
+func printImportReport(report *client.WorkItemImportAttributes) error {
+	invalid := 0
+	for _, row := range report.Rows {
+		if len(row.Errors) == 0 {
+			continue
+		}
+		invalid++
+		ref := ""
+		if row.Reference != nil {
+			ref = fmt.Sprintf(" (%s)", *row.Reference)
+		}
+		for _, e := range row.Errors {
+			fmt.Printf("row %d%s: %s\n", row.Row, ref, e)
+		}
+	}
+	committed := report.Committed != nil && *report.Committed
+	for _, c := range report.Creates {
+		if committed {
+			fmt.Printf("created %s\n", c)
+		} else {
+			fmt.Printf("would create %s\n", c)
+		}
+	}
+	switch {
+	case invalid > 0:
+		return errs.Errorf("%d of %d rows can't be imported, nothing was created", invalid, len(report.Rows))
+	case committed:
+		fmt.Printf("created %d work items\n", len(report.Rows))
+	default:
+		fmt.Printf("the %d rows are valid, run again with --commit to create the work items\n", len(report.Rows))
+	}
+	return nil
+}
@@ -44,6 +44,7 @@ func main() {
 
 	// Register API commands
 	cli.RegisterCommands(app, c)
+	registerImportCSVCommand(app, c)
 
 	// Execute!
 	if err := app.Execute(); err != nil {
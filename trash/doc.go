@@ -0,0 +1,4 @@
+// Package trash contains the operations to list and restore the work items
+// and comments that were recently deleted in a space and to purge them
+// permanently once their retention period has expired.
+package trash
@@ -0,0 +1,73 @@
+package trash
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/models"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/robfig/cron"
+)
+
+// Purger periodically purges the work items and comments that have been in
+// the trash for longer than the retention period.
+type Purger struct {
+	db        *gorm.DB
+	retention time.Duration
+	cr        *cron.Cron
+}
+
+// NewPurger creates a new Purger
+func NewPurger(db *gorm.DB, retention time.Duration) *Purger {
+	return &Purger{db: db, retention: retention, cr: cron.New()}
+}
+
+// Start runs the purge according to the given cron schedule (e.g. "@hourly").
+// Nothing is ever purged if the retention period is not positive.
+func (p *Purger) Start(schedule string) error {
+	if p.retention <= 0 {
+		log.Info(nil, map[string]interface{}{}, "trash retention period is not set, deleted items are kept forever")
+		return nil
+	}
+	err := p.cr.AddFunc(schedule, func() {
+		if _, err := p.Purge(context.Background()); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err": err,
+			}, "failed to purge the trash")
+		}
+	})
+	if err != nil {
+		return errs.Wrapf(err, "invalid trash purge schedule '%s'", schedule)
+	}
+	p.cr.Start()
+	return nil
+}
+
+// Stop stops the purger.
+// This should be called only from main
+func (p *Purger) Stop() {
+	p.cr.Stop()
+}
+
+// Purge permanently deletes the work items and comments that were deleted
+// before the retention period.
+func (p *Purger) Purge(ctx context.Context) (int64, error) {
+	var purged int64
+	deletedBefore := time.Now().Add(-p.retention)
+	err := models.Transactional(p.db, func(tx *gorm.DB) error {
+		var err error
+		purged, err = NewRepository(tx).Purge(ctx, deletedBefore)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	log.Info(ctx, map[string]interface{}{
+		"deleted_before": deletedBefore,
+		"purged":         purged,
+	}, "purged the trash")
+	return purged, nil
+}
@@ -0,0 +1,287 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeTrashItems helps to avoid string literal
+const APIStringTypeTrashItems = "trashitems"
+
+// Kind tells what has been deleted
+type Kind string
+
+// The kinds of items that can be found in the trash
+const (
+	KindWorkItem Kind = "workitems"
+	KindComment  Kind = "comments"
+)
+
+// Item describes a deleted work item or comment
+type Item struct {
+	ID   uuid.UUID
+	Kind Kind
+	// SpaceID is the space of the work item or of the work item the comment
+	// belongs to
+	SpaceID uuid.UUID
+	// WorkItemID is the ID of the work item itself or of the work item the
+	// comment belongs to
+	WorkItemID uuid.UUID
+	// Number of the work item or of the work item the comment belongs to
+	Number int
+	// Title is the title of the work item or the body of the comment
+	Title     string
+	DeletedAt time.Time
+	// DeletedBy is the identity that deleted the item, if known
+	DeletedBy *uuid.UUID
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (i Item) GetETagData() []interface{} {
+	return []interface{}{i.ID, i.DeletedAt.Unix()}
+}
+
+// GetLastModified returns the last modification time
+func (i Item) GetLastModified() time.Time {
+	return i.DeletedAt.Truncate(time.Second)
+}
+
+// Repository describes interactions with the trash
+type Repository interface {
+	// List returns the deleted work items and comments of the given space,
+	// most recently deleted first.
+	List(ctx context.Context, spaceID uuid.UUID) ([]Item, error)
+	// Load returns the deleted work item or comment with the given ID.
+	Load(ctx context.Context, spaceID uuid.UUID, id uuid.UUID) (*Item, error)
+	// Restore un-deletes the given work item or comment. When restoring a work
+	// item, the links that were deleted together with it are restored too
+	// unless they would now violate the topology of their link type.
+	Restore(ctx context.Context, spaceID uuid.UUID, id uuid.UUID, restorerID uuid.UUID) (*Item, error)
+	// Purge permanently deletes the work items and comments that were deleted
+	// before the given time and returns how many of them were purged.
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for the trash.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// trashQuery returns the query that lists the deleted work items and comments
+// of the space given as first argument, restricted by the given condition on
+// the "trash" subquery.
+func trashQuery(cond string) string {
+	return fmt.Sprintf(`SELECT * FROM (
+		SELECT w.id, '%[1]s' AS kind, w.space_id, w.id AS work_item_id, w.number, w.fields->>'%[3]s' AS title, w.deleted_at,
+			(SELECT r.modifier_id FROM %[4]s r WHERE r.work_item_id = w.id AND r.revision_type = %[6]d ORDER BY r.revision_time DESC LIMIT 1) AS deleted_by
+		FROM %[8]s w
+		WHERE w.space_id = $1 AND w.deleted_at IS NOT NULL
+		UNION ALL
+		SELECT c.id, '%[2]s' AS kind, w.space_id, w.id AS work_item_id, w.number, c.body AS title, c.deleted_at,
+			(SELECT r.modifier_id FROM %[5]s r WHERE r.comment_id = c.id AND r.revision_type = %[7]d ORDER BY r.revision_time DESC LIMIT 1) AS deleted_by
+		FROM %[9]s c JOIN %[8]s w ON w.id = c.parent_id
+		WHERE w.space_id = $1 AND c.deleted_at IS NOT NULL
+	) AS trash WHERE %[10]s ORDER BY deleted_at DESC, id`,
+		KindWorkItem, KindComment, workitem.SystemTitle,
+		workitem.Revision{}.TableName(), comment.Revision{}.TableName(),
+		workitem.RevisionTypeDelete, comment.RevisionTypeDelete,
+		workitem.WorkItemStorage{}.TableName(), comment.Comment{}.TableName(),
+		cond)
+}
+
+// List returns the deleted work items and comments of the given space
+func (r *GormRepository) List(ctx context.Context, spaceID uuid.UUID) ([]Item, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "trash", "list"}, time.Now())
+	var items []Item
+	if err := r.db.Raw(trashQuery("true"), spaceID).Scan(&items).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      err,
+		}, "unable to list the trash of the space")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return items, nil
+}
+
+// Load returns the deleted work item or comment with the given ID
+func (r *GormRepository) Load(ctx context.Context, spaceID uuid.UUID, id uuid.UUID) (*Item, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "trash", "load"}, time.Now())
+	var items []Item
+	if err := r.db.Raw(trashQuery("id = $2"), spaceID, id).Scan(&items).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"id":       id,
+			"err":      err,
+		}, "unable to load the trash item")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	if len(items) == 0 {
+		return nil, errors.NewNotFoundError("trash item", id.String())
+	}
+	return &items[0], nil
+}
+
+// Restore un-deletes the given work item or comment
+func (r *GormRepository) Restore(ctx context.Context, spaceID uuid.UUID, id uuid.UUID, restorerID uuid.UUID) (*Item, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "trash", "restore"}, time.Now())
+	item, err := r.Load(ctx, spaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch item.Kind {
+	case KindWorkItem:
+		err = r.restoreWorkItem(ctx, id, restorerID)
+	case KindComment:
+		err = r.restoreComment(ctx, *item, restorerID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *GormRepository) restoreWorkItem(ctx context.Context, id uuid.UUID, restorerID uuid.UUID) error {
+	wi := workitem.WorkItemStorage{}
+	if err := r.db.Unscoped().Where("id = ?", id).First(&wi).Error; err != nil {
+		return errors.NewInternalError(ctx, err)
+	}
+	wi.DeletedAt = nil
+	if err := r.db.Exec(fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE id = $1", wi.TableName()), id).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"wi_id": id,
+			"err":   err,
+		}, "unable to restore the work item")
+		return errors.NewInternalError(ctx, err)
+	}
+	if err := workitem.NewRevisionRepository(r.db).Create(ctx, restorerID, workitem.RevisionTypeCreate, wi); err != nil {
+		return errs.Wrapf(err, "failed to store a revision of the restored work item %s", id)
+	}
+	return r.restoreLinks(ctx, id, restorerID)
+}
+
+// restoreLinks restores the links of the given work item that were deleted
+// together with one of their ends, provided that both ends exist again.
+func (r *GormRepository) restoreLinks(ctx context.Context, wiID uuid.UUID, restorerID uuid.UUID) error {
+	var links []link.WorkItemLink
+	wiTable := workitem.WorkItemStorage{}.TableName()
+	db := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_with_work_item_id IS NOT NULL AND ? IN (source_id, target_id)", wiID).
+		Where(fmt.Sprintf("EXISTS (SELECT 1 FROM %[1]s s WHERE s.id = source_id AND s.deleted_at IS NULL) AND EXISTS (SELECT 1 FROM %[1]s t WHERE t.id = target_id AND t.deleted_at IS NULL)", wiTable)).
+		Order("deleted_at").
+		Find(&links)
+	if db.Error != nil {
+		return errors.NewInternalError(ctx, db.Error)
+	}
+	linkRepo := link.NewWorkItemLinkRepository(r.db)
+	linkTypeRepo := link.NewWorkItemLinkTypeRepository(r.db)
+	revisionRepo := link.NewRevisionRepository(r.db)
+	for _, l := range links {
+		var count int
+		if err := r.db.Model(&link.WorkItemLink{}).Where("source_id = ? AND target_id = ? AND link_type_id = ?", l.SourceID, l.TargetID, l.LinkTypeID).Count(&count).Error; err != nil {
+			return errors.NewInternalError(ctx, err)
+		}
+		if count > 0 {
+			// the link has been created again in the meantime
+			continue
+		}
+		linkType, err := linkTypeRepo.Load(ctx, l.LinkTypeID)
+		if err != nil {
+			return errs.Wrapf(err, "failed to load the type of work item link %s", l.ID)
+		}
+		if err := linkRepo.ValidateTopology(ctx, l.SourceID, l.TargetID, *linkType); err != nil {
+			log.Info(ctx, map[string]interface{}{
+				"wi_id":  wiID,
+				"wil_id": l.ID,
+				"err":    err,
+			}, "not restoring the work item link")
+			continue
+		}
+		updateStmt := fmt.Sprintf("UPDATE %s SET deleted_at = NULL, deleted_with_work_item_id = NULL WHERE id = $1", l.TableName())
+		if err := r.db.Exec(updateStmt, l.ID).Error; err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"wi_id":  wiID,
+				"wil_id": l.ID,
+				"err":    err,
+			}, "unable to restore the work item link")
+			return errors.NewInternalError(ctx, err)
+		}
+		l.DeletedAt = nil
+		if err := revisionRepo.Create(ctx, restorerID, link.RevisionTypeCreate, l); err != nil {
+			return errs.Wrapf(err, "failed to store a revision of the restored work item link %s", l.ID)
+		}
+	}
+	return nil
+}
+
+func (r *GormRepository) restoreComment(ctx context.Context, item Item, restorerID uuid.UUID) error {
+	var count int
+	if err := r.db.Model(&workitem.WorkItemStorage{}).Where("id = ?", item.WorkItemID).Count(&count).Error; err != nil {
+		return errors.NewInternalError(ctx, err)
+	}
+	if count == 0 {
+		return errors.NewBadParameterError("id", item.ID).Expected(fmt.Sprintf("comment of an existing work item (restore the work item %s first)", item.WorkItemID))
+	}
+	c := comment.Comment{}
+	if err := r.db.Unscoped().Where("id = ?", item.ID).First(&c).Error; err != nil {
+		return errors.NewInternalError(ctx, err)
+	}
+	c.DeletedAt = nil
+	if err := r.db.Exec(fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE id = $1", c.TableName()), c.ID).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"comment_id": item.ID,
+			"err":        err,
+		}, "unable to restore the comment")
+		return errors.NewInternalError(ctx, err)
+	}
+	if err := comment.NewRevisionRepository(r.db).Create(ctx, restorerID, comment.RevisionTypeCreate, c); err != nil {
+		return errs.Wrapf(err, "failed to store a revision of the restored comment %s", item.ID)
+	}
+	return nil
+}
+
+// Purge permanently deletes the work items and comments that were deleted
+// before the given time. Links, revisions and watchers of the purged work
+// items are removed by the database, their comments are removed here.
+// Deleted comments which still have replies are kept.
+func (r *GormRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "trash", "purge"}, time.Now())
+	wiTable := workitem.WorkItemStorage{}.TableName()
+	commentTable := comment.Comment{}.TableName()
+	stmts := []string{
+		fmt.Sprintf(`DELETE FROM %[1]s WHERE parent_id IN (SELECT id FROM %[2]s WHERE deleted_at < $1)`, commentTable, wiTable),
+		fmt.Sprintf(`DELETE FROM %[1]s WHERE deleted_at < $1`, wiTable),
+		fmt.Sprintf(`DELETE FROM %[1]s c WHERE c.deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM %[1]s ch WHERE ch.parent_comment_id = c.id)`, commentTable),
+	}
+	var purged int64
+	for i, stmt := range stmts {
+		db := r.db.Exec(stmt, deletedBefore)
+		if db.Error != nil {
+			log.Error(ctx, map[string]interface{}{
+				"deleted_before": deletedBefore,
+				"err":            db.Error,
+			}, "unable to purge the trash")
+			return 0, errors.NewInternalError(ctx, db.Error)
+		}
+		// the comments of purged work items are not counted
+		if i > 0 {
+			purged += db.RowsAffected
+		}
+	}
+	return purged, nil
+}
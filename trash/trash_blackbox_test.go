@@ -0,0 +1,182 @@
+package trash_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/comment"
+	errs "github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/trash"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestTrashRepository struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunTrashRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestTrashRepository{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+// deleteWorkItem deletes the given work item and its links the same way the
+// work item controller does.
+func (s *TestTrashRepository) deleteWorkItem(t *testing.T, wiID, suppressorID uuid.UUID) {
+	require.NoError(t, workitem.NewWorkItemRepository(s.DB).Delete(context.Background(), wiID, suppressorID))
+	require.NoError(t, link.NewWorkItemLinkRepository(s.DB).DeleteRelatedLinks(context.Background(), wiID, suppressorID))
+}
+
+func (s *TestTrashRepository) TestList() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(3, tf.SetWorkItemTitles("A", "B", "C")), tf.Comments(2))
+		s.deleteWorkItem(t, fxt.WorkItems[1].ID, fxt.Identities[0].ID)
+		require.NoError(t, comment.NewRepository(s.DB).Delete(context.Background(), fxt.Comments[1].ID, fxt.Identities[0].ID))
+		items, err := trash.NewRepository(s.DB).List(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		// most recently deleted first
+		assert.Equal(t, fxt.Comments[1].ID, items[0].ID)
+		assert.Equal(t, trash.KindComment, items[0].Kind)
+		assert.Equal(t, fxt.WorkItems[0].ID, items[0].WorkItemID)
+		assert.Equal(t, fxt.WorkItems[1].ID, items[1].ID)
+		assert.Equal(t, trash.KindWorkItem, items[1].Kind)
+		assert.Equal(t, "B", items[1].Title)
+		assert.Equal(t, fxt.WorkItems[1].Number, items[1].Number)
+		require.NotNil(t, items[1].DeletedBy)
+		assert.Equal(t, fxt.Identities[0].ID, *items[1].DeletedBy)
+	})
+
+	s.T().Run("empty", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		items, err := trash.NewRepository(s.DB).List(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, items)
+	})
+}
+
+func (s *TestTrashRepository) TestRestore() {
+	s.T().Run("work item with its links", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.WorkItems(3, tf.SetWorkItemTitles("parent", "child", "grandchild")),
+			tf.WorkItemLinks(2, func(fxt *tf.TestFixture, idx int) error {
+				l := fxt.WorkItemLinks[idx]
+				l.LinkTypeID = link.SystemWorkItemLinkTypeParentChildID
+				l.SourceID = fxt.WorkItems[idx].ID
+				l.TargetID = fxt.WorkItems[idx+1].ID
+				return nil
+			}),
+		)
+		s.deleteWorkItem(t, fxt.WorkItems[1].ID, fxt.Identities[0].ID)
+		links, err := link.NewWorkItemLinkRepository(s.DB).ListByWorkItem(context.Background(), fxt.WorkItems[0].ID)
+		require.NoError(t, err)
+		require.Empty(t, links)
+		// when
+		item, err := trash.NewRepository(s.DB).Restore(context.Background(), fxt.Spaces[0].ID, fxt.WorkItems[1].ID, fxt.Identities[0].ID)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, trash.KindWorkItem, item.Kind)
+		wi, err := workitem.NewWorkItemRepository(s.DB).LoadByID(context.Background(), fxt.WorkItems[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "child", wi.Fields[workitem.SystemTitle])
+		links, err = link.NewWorkItemLinkRepository(s.DB).ListByWorkItem(context.Background(), fxt.WorkItems[1].ID)
+		require.NoError(t, err)
+		assert.Len(t, links, 2)
+		items, err := trash.NewRepository(s.DB).List(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, items)
+	})
+
+	s.T().Run("work item without the link to its new parent", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.WorkItems(3, tf.SetWorkItemTitles("parent", "child", "other parent")),
+			tf.WorkItemLinks(1, func(fxt *tf.TestFixture, idx int) error {
+				l := fxt.WorkItemLinks[idx]
+				l.LinkTypeID = link.SystemWorkItemLinkTypeParentChildID
+				l.SourceID = fxt.WorkItems[0].ID
+				l.TargetID = fxt.WorkItems[1].ID
+				return nil
+			}),
+		)
+		s.deleteWorkItem(t, fxt.WorkItems[0].ID, fxt.Identities[0].ID)
+		_, err := link.NewWorkItemLinkRepository(s.DB).Create(context.Background(), fxt.WorkItems[2].ID, fxt.WorkItems[1].ID, link.SystemWorkItemLinkTypeParentChildID, fxt.Identities[0].ID)
+		require.NoError(t, err)
+		// when
+		_, err = trash.NewRepository(s.DB).Restore(context.Background(), fxt.Spaces[0].ID, fxt.WorkItems[0].ID, fxt.Identities[0].ID)
+		// then the child keeps its new parent
+		require.NoError(t, err)
+		links, err := link.NewWorkItemLinkRepository(s.DB).ListByWorkItem(context.Background(), fxt.WorkItems[1].ID)
+		require.NoError(t, err)
+		require.Len(t, links, 1)
+		assert.Equal(t, fxt.WorkItems[2].ID, links[0].SourceID)
+	})
+
+	s.T().Run("comment", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Comments(1))
+		require.NoError(t, comment.NewRepository(s.DB).Delete(context.Background(), fxt.Comments[0].ID, fxt.Identities[0].ID))
+		item, err := trash.NewRepository(s.DB).Restore(context.Background(), fxt.Spaces[0].ID, fxt.Comments[0].ID, fxt.Identities[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, trash.KindComment, item.Kind)
+		_, err = comment.NewRepository(s.DB).Load(context.Background(), fxt.Comments[0].ID)
+		require.NoError(t, err)
+	})
+
+	s.T().Run("comment of a deleted work item", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Comments(1))
+		require.NoError(t, comment.NewRepository(s.DB).Delete(context.Background(), fxt.Comments[0].ID, fxt.Identities[0].ID))
+		s.deleteWorkItem(t, fxt.WorkItems[0].ID, fxt.Identities[0].ID)
+		_, err := trash.NewRepository(s.DB).Restore(context.Background(), fxt.Spaces[0].ID, fxt.Comments[0].ID, fxt.Identities[0].ID)
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.BadParameterError)
+		assert.True(t, ok)
+	})
+
+	s.T().Run("not in the trash", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		_, err := trash.NewRepository(s.DB).Restore(context.Background(), fxt.Spaces[0].ID, fxt.WorkItems[0].ID, fxt.Identities[0].ID)
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.NotFoundError)
+		assert.True(t, ok)
+	})
+
+	s.T().Run("other space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(2), tf.WorkItems(1))
+		s.deleteWorkItem(t, fxt.WorkItems[0].ID, fxt.Identities[0].ID)
+		_, err := trash.NewRepository(s.DB).Restore(context.Background(), fxt.Spaces[1].ID, fxt.WorkItems[0].ID, fxt.Identities[0].ID)
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.NotFoundError)
+		assert.True(t, ok)
+	})
+}
+
+func (s *TestTrashRepository) TestPurge() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(2), tf.Comments(2))
+	s.deleteWorkItem(s.T(), fxt.WorkItems[1].ID, fxt.Identities[0].ID)
+	require.NoError(s.T(), comment.NewRepository(s.DB).Delete(context.Background(), fxt.Comments[1].ID, fxt.Identities[0].ID))
+	repo := trash.NewRepository(s.DB)
+	// nothing has been deleted for long enough
+	purged, err := repo.Purge(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(0), purged)
+	// when
+	purged, err = repo.Purge(context.Background(), time.Now().Add(time.Second))
+	// then
+	require.NoError(s.T(), err)
+	assert.True(s.T(), purged >= 2)
+	items, err := repo.List(context.Background(), fxt.Spaces[0].ID)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), items)
+	_, err = workitem.NewWorkItemRepository(s.DB).LoadByID(context.Background(), fxt.WorkItems[0].ID)
+	require.NoError(s.T(), err)
+	_, err = comment.NewRepository(s.DB).Load(context.Background(), fxt.Comments[0].ID)
+	require.NoError(s.T(), err)
+}
@@ -0,0 +1,3 @@
+// Package watcher contains the required operations to manage the identities
+// that watch work items and their notification preferences.
+package watcher
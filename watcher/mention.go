@@ -0,0 +1,25 @@
+package watcher
+
+import (
+	"regexp"
+)
+
+// mentionRegexp matches "@username" when the "@" is not part of a word, so
+// that email addresses aren't mistaken for mentions.
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9][A-Za-z0-9._-]*[A-Za-z0-9]|[A-Za-z0-9])`)
+
+// ParseMentions returns the distinct usernames mentioned in the given text in
+// the order of their first appearance.
+func ParseMentions(text string) []string {
+	res := []string{}
+	seen := map[string]struct{}{}
+	for _, m := range mentionRegexp.FindAllStringSubmatch(text, -1) {
+		username := m[1]
+		if _, ok := seen[username]; ok {
+			continue
+		}
+		seen[username] = struct{}{}
+		res = append(res, username)
+	}
+	return res
+}
@@ -0,0 +1,33 @@
+package watcher_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/watcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	testData := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"empty", "", []string{}},
+		{"no mention", "nothing to see here", []string{}},
+		{"single mention", "@john please have a look", []string{"john"}},
+		{"multiple mentions", "cc @john.doe, @jane_doe and @x", []string{"john.doe", "jane_doe", "x"}},
+		{"duplicate mentions", "@john @john (@john)", []string{"john"}},
+		{"trailing punctuation", "thanks @john.", []string{"john"}},
+		{"email address", "write to john@example.com", []string{}},
+		{"markdown", "**@jane** fixed it", []string{"jane"}},
+	}
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			assert.Equal(t, td.expected, watcher.ParseMentions(td.text))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeNotificationPreferences helps to avoid string literal
+const APIStringTypeNotificationPreferences = "notification-preferences"
+
+// Preference tells whether an identity wants to receive notifications of a
+// given message type (e.g. "workitem.update"). When no preference is stored
+// for a message type, the identity receives it.
+type Preference struct {
+	gormsupport.Lifecycle
+	IdentityID  uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	MessageType string    `gorm:"primary_key"`
+	Enabled     bool
+}
+
+// PreferenceTableName constant that holds table name of notification preferences
+const PreferenceTableName = "notification_preferences"
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (p Preference) TableName() string {
+	return PreferenceTableName
+}
+
+// PreferenceRepository describes interactions with notification preferences
+type PreferenceRepository interface {
+	List(ctx context.Context, identityID uuid.UUID) ([]Preference, error)
+	Save(ctx context.Context, p Preference) (*Preference, error)
+}
+
+// NewPreferenceRepository creates a new storage type.
+func NewPreferenceRepository(db *gorm.DB) PreferenceRepository {
+	return &GormPreferenceRepository{db: db}
+}
+
+// GormPreferenceRepository is the implementation of the storage interface for
+// notification preferences.
+type GormPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// List returns all preferences stored for the given identity
+func (r *GormPreferenceRepository) List(ctx context.Context, identityID uuid.UUID) ([]Preference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notification_preference", "list"}, time.Now())
+	var objs []Preference
+	err := r.db.Where("identity_id = ?", identityID).Order("message_type").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// Save creates or updates the preference of an identity for a message type
+func (r *GormPreferenceRepository) Save(ctx context.Context, p Preference) (*Preference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notification_preference", "save"}, time.Now())
+	if strings.TrimSpace(p.MessageType) == "" {
+		return nil, errors.NewBadParameterError("message type cannot be empty string", p.MessageType).Expected("non empty string")
+	}
+	upsertStmt := fmt.Sprintf(`INSERT INTO %s (identity_id, message_type, enabled, created_at, updated_at) VALUES ($1, $2, $3, now(), now())
+		ON CONFLICT (identity_id, message_type) DO UPDATE SET enabled = EXCLUDED.enabled, deleted_at = NULL, updated_at = now()`, PreferenceTableName)
+	if err := r.db.Exec(upsertStmt, p.IdentityID, p.MessageType, p.Enabled).Error; err != nil {
+		if gormsupport.IsForeignKeyViolation(err, "notification_preferences_identity_id_fkey") {
+			return nil, errors.NewNotFoundError("identity", p.IdentityID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"identity_id":  p.IdentityID,
+			"message_type": p.MessageType,
+			"err":          err,
+		}, "unable to save the notification preference")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	res := Preference{}
+	if err := r.db.Where("identity_id = ? AND message_type = ?", p.IdentityID, p.MessageType).First(&res).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return &res, nil
+}
@@ -0,0 +1,240 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/closeable"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeWatchers helps to avoid string literal
+const APIStringTypeWatchers = "watchers"
+
+// Reason explains why an identity is watching a work item
+type Reason string
+
+// The reasons for which an identity can be subscribed to a work item
+const (
+	ReasonManual    Reason = "manual"
+	ReasonCreator   Reason = "creator"
+	ReasonAssignee  Reason = "assignee"
+	ReasonCommenter Reason = "commenter"
+	ReasonMention   Reason = "mention"
+)
+
+// Watcher describes the subscription of a single identity to a work item.
+//
+// Unwatching a work item soft-deletes the entry so that an explicit opt-out is
+// remembered and the identity doesn't get subscribed again automatically.
+type Watcher struct {
+	gormsupport.Lifecycle
+	WorkItemID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	IdentityID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	Reason     Reason
+}
+
+// WatcherTableName constant that holds table name of work item watchers
+const WatcherTableName = "work_item_watchers"
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (w Watcher) TableName() string {
+	return WatcherTableName
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (w Watcher) GetETagData() []interface{} {
+	return []interface{}{w.WorkItemID, w.IdentityID, w.UpdatedAt.Unix()}
+}
+
+// GetLastModified returns the last modification time
+func (w Watcher) GetLastModified() time.Time {
+	return w.UpdatedAt.Truncate(time.Second)
+}
+
+// Repository describes interactions with work item watchers
+type Repository interface {
+	// Watch explicitly subscribes the given identity to the work item, even if
+	// the identity unwatched it before.
+	Watch(ctx context.Context, workItemID, identityID uuid.UUID) (*Watcher, error)
+	// Unwatch removes the subscription of the given identity.
+	Unwatch(ctx context.Context, workItemID, identityID uuid.UUID) error
+	// AutoWatch subscribes the given identities for the given reason unless
+	// they are already watching or have explicitly unwatched the work item.
+	AutoWatch(ctx context.Context, workItemID uuid.UUID, reason Reason, identityIDs ...uuid.UUID) error
+	List(ctx context.Context, workItemID uuid.UUID) ([]Watcher, error)
+	IsWatching(ctx context.Context, workItemID, identityID uuid.UUID) (bool, error)
+	// ListRecipients returns the watchers of the given work item who did not
+	// opt out of the given message type, minus the excluded identities.
+	ListRecipients(ctx context.Context, workItemID uuid.UUID, messageType string, excludedIDs ...uuid.UUID) ([]uuid.UUID, error)
+	// ListSpaceRecipients returns the owner of the given space and the
+	// identities with a role in it who did not opt out of the given message
+	// type.
+	ListSpaceRecipients(ctx context.Context, spaceID uuid.UUID, messageType string) ([]uuid.UUID, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for work item watchers.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Watch explicitly subscribes the given identity to the work item
+func (r *GormRepository) Watch(ctx context.Context, workItemID, identityID uuid.UUID) (*Watcher, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "watcher", "watch"}, time.Now())
+	upsertStmt := fmt.Sprintf(`INSERT INTO %[1]s (work_item_id, identity_id, reason, created_at, updated_at) VALUES ($1, $2, $3, now(), now())
+		ON CONFLICT (work_item_id, identity_id) DO UPDATE SET deleted_at = NULL, updated_at = now(),
+		reason = CASE WHEN %[1]s.deleted_at IS NULL THEN %[1]s.reason ELSE EXCLUDED.reason END`, WatcherTableName)
+	if err := r.db.Exec(upsertStmt, workItemID, identityID, ReasonManual).Error; err != nil {
+		if gormsupport.IsForeignKeyViolation(err, "work_item_watchers_work_item_id_fkey") {
+			return nil, errors.NewNotFoundError("work item", workItemID.String())
+		}
+		if gormsupport.IsForeignKeyViolation(err, "work_item_watchers_identity_id_fkey") {
+			return nil, errors.NewNotFoundError("identity", identityID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"wi_id":       workItemID,
+			"identity_id": identityID,
+			"err":         err,
+		}, "unable to watch the work item")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	w := Watcher{}
+	if err := r.db.Where("work_item_id = ? AND identity_id = ?", workItemID, identityID).First(&w).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return &w, nil
+}
+
+// Unwatch removes the subscription of the given identity
+func (r *GormRepository) Unwatch(ctx context.Context, workItemID, identityID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "watcher", "unwatch"}, time.Now())
+	tx := r.db.Where("work_item_id = ? AND identity_id = ?", workItemID, identityID).Delete(&Watcher{})
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"wi_id":       workItemID,
+			"identity_id": identityID,
+			"err":         err,
+		}, "unable to unwatch the work item")
+		return errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("watcher", identityID.String())
+	}
+	return nil
+}
+
+// AutoWatch subscribes the given identities unless they are already watching
+// or have explicitly unwatched the work item
+func (r *GormRepository) AutoWatch(ctx context.Context, workItemID uuid.UUID, reason Reason, identityIDs ...uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "watcher", "autowatch"}, time.Now())
+	insertStmt := fmt.Sprintf(`INSERT INTO %s (work_item_id, identity_id, reason, created_at, updated_at) VALUES ($1, $2, $3, now(), now())
+		ON CONFLICT (work_item_id, identity_id) DO NOTHING`, WatcherTableName)
+	for _, identityID := range identityIDs {
+		if uuid.Equal(identityID, uuid.Nil) {
+			continue
+		}
+		if err := r.db.Exec(insertStmt, workItemID, identityID, reason).Error; err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"wi_id":       workItemID,
+				"identity_id": identityID,
+				"reason":      reason,
+				"err":         err,
+			}, "unable to subscribe identity to the work item")
+			return errs.Wrapf(err, "failed to subscribe identity %s to work item %s", identityID, workItemID)
+		}
+	}
+	return nil
+}
+
+// List returns all active watchers of the given work item
+func (r *GormRepository) List(ctx context.Context, workItemID uuid.UUID) ([]Watcher, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "watcher", "list"}, time.Now())
+	var objs []Watcher
+	err := r.db.Where("work_item_id = ?", workItemID).Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// IsWatching returns true if the given identity is watching the work item
+func (r *GormRepository) IsWatching(ctx context.Context, workItemID, identityID uuid.UUID) (bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "watcher", "iswatching"}, time.Now())
+	var count int
+	err := r.db.Model(&Watcher{}).Where("work_item_id = ? AND identity_id = ?", workItemID, identityID).Count(&count).Error
+	if err != nil {
+		return false, errors.NewInternalError(ctx, err)
+	}
+	return count > 0, nil
+}
+
+// ListRecipients returns the watchers of the given work item who did not opt
+// out of the given message type
+func (r *GormRepository) ListRecipients(ctx context.Context, workItemID uuid.UUID, messageType string, excludedIDs ...uuid.UUID) ([]uuid.UUID, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "watcher", "recipients"}, time.Now())
+	q := fmt.Sprintf(`SELECT w.identity_id FROM %[1]s w
+		WHERE w.work_item_id = $1 AND w.deleted_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1 FROM %[2]s p
+			WHERE p.identity_id = w.identity_id AND p.message_type = $2 AND p.enabled = FALSE AND p.deleted_at IS NULL
+		)
+		ORDER BY w.created_at`, WatcherTableName, PreferenceTableName)
+	return r.listRecipients(ctx, q, workItemID, messageType, excludedIDs...)
+}
+
+// ListSpaceRecipients returns the owner and the identities with a role in the
+// space who did not opt out of the message type
+func (r *GormRepository) ListSpaceRecipients(ctx context.Context, spaceID uuid.UUID, messageType string) ([]uuid.UUID, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "watcher", "space_recipients"}, time.Now())
+	q := fmt.Sprintf(`SELECT i.identity_id FROM (
+			SELECT s.owner_id AS identity_id FROM %[1]s s WHERE s.id = $1 AND s.deleted_at IS NULL
+			UNION
+			SELECT r.identity_id FROM %[2]s r WHERE r.space_id = $1 AND r.deleted_at IS NULL
+		) i
+		WHERE NOT EXISTS (
+			SELECT 1 FROM %[3]s p
+			WHERE p.identity_id = i.identity_id AND p.message_type = $2 AND p.enabled = FALSE AND p.deleted_at IS NULL
+		)
+		ORDER BY i.identity_id`, space.Space{}.TableName(), role.AssignmentTableName, PreferenceTableName)
+	return r.listRecipients(ctx, q, spaceID, messageType)
+}
+
+// listRecipients runs the given query on the given target and message type
+// and returns the resulting identities, minus the excluded ones
+func (r *GormRepository) listRecipients(ctx context.Context, q string, targetID uuid.UUID, messageType string, excludedIDs ...uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Raw(q, targetID, messageType).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	defer closeable.Close(ctx, rows)
+	excluded := make(map[uuid.UUID]struct{}, len(excludedIDs))
+	for _, id := range excludedIDs {
+		excluded[id] = struct{}{}
+	}
+	res := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.NewInternalError(ctx, err)
+		}
+		if _, ok := excluded[id]; !ok {
+			res = append(res, id)
+		}
+	}
+	return res, nil
+}
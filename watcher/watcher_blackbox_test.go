@@ -0,0 +1,183 @@
+package watcher_test
+
+import (
+	"context"
+	"testing"
+
+	errs "github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/watcher"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWatcherRepository struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunWatcherRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWatcherRepository{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *TestWatcherRepository) TestWatch() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.WorkItems(1))
+		repo := watcher.NewRepository(s.DB)
+		w, err := repo.Watch(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, watcher.ReasonManual, w.Reason)
+		watching, err := repo.IsWatching(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		assert.True(t, watching)
+		// watching twice is fine
+		_, err = repo.Watch(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		watchers, err := repo.List(context.Background(), fxt.WorkItems[0].ID)
+		require.NoError(t, err)
+		require.Len(t, watchers, 1)
+	})
+
+	s.T().Run("unknown work item", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(1))
+		_, err := watcher.NewRepository(s.DB).Watch(context.Background(), uuid.NewV4(), fxt.Identities[0].ID)
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.NotFoundError)
+		assert.True(t, ok)
+	})
+}
+
+func (s *TestWatcherRepository) TestUnwatch() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.WorkItems(1))
+		repo := watcher.NewRepository(s.DB)
+		_, err := repo.Watch(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		err = repo.Unwatch(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		watching, err := repo.IsWatching(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		assert.False(t, watching)
+		// an explicit opt-out is not overridden by automatic subscriptions
+		err = repo.AutoWatch(context.Background(), fxt.WorkItems[0].ID, watcher.ReasonAssignee, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		watching, err = repo.IsWatching(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		assert.False(t, watching)
+		// but watching explicitly again restores the subscription
+		_, err = repo.Watch(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		watching, err = repo.IsWatching(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[1].ID)
+		require.NoError(t, err)
+		assert.True(t, watching)
+	})
+
+	s.T().Run("not watching", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.WorkItems(1))
+		err := watcher.NewRepository(s.DB).Unwatch(context.Background(), fxt.WorkItems[0].ID, fxt.Identities[1].ID)
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.NotFoundError)
+		assert.True(t, ok)
+	})
+}
+
+func (s *TestWatcherRepository) TestAutoWatch() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(3), tf.WorkItems(1))
+	repo := watcher.NewRepository(s.DB)
+	err := repo.AutoWatch(context.Background(), fxt.WorkItems[0].ID, watcher.ReasonCreator, fxt.Identities[0].ID)
+	require.NoError(s.T(), err)
+	// the original reason is kept when subscribing the same identity again
+	err = repo.AutoWatch(context.Background(), fxt.WorkItems[0].ID, watcher.ReasonAssignee, fxt.Identities[0].ID, fxt.Identities[1].ID, uuid.Nil)
+	require.NoError(s.T(), err)
+	watchers, err := repo.List(context.Background(), fxt.WorkItems[0].ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), watchers, 2)
+	reasons := map[uuid.UUID]watcher.Reason{}
+	for _, w := range watchers {
+		reasons[w.IdentityID] = w.Reason
+	}
+	assert.Equal(s.T(), watcher.ReasonCreator, reasons[fxt.Identities[0].ID])
+	assert.Equal(s.T(), watcher.ReasonAssignee, reasons[fxt.Identities[1].ID])
+}
+
+func (s *TestWatcherRepository) TestListRecipients() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(3), tf.WorkItems(1))
+	repo := watcher.NewRepository(s.DB)
+	prefRepo := watcher.NewPreferenceRepository(s.DB)
+	err := repo.AutoWatch(context.Background(), fxt.WorkItems[0].ID, watcher.ReasonAssignee, fxt.Identities[0].ID, fxt.Identities[1].ID, fxt.Identities[2].ID)
+	require.NoError(s.T(), err)
+	_, err = prefRepo.Save(context.Background(), watcher.Preference{IdentityID: fxt.Identities[1].ID, MessageType: "workitem.update", Enabled: false})
+	require.NoError(s.T(), err)
+
+	s.T().Run("opted out identities are excluded", func(t *testing.T) {
+		recipients, err := repo.ListRecipients(context.Background(), fxt.WorkItems[0].ID, "workitem.update")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{fxt.Identities[0].ID, fxt.Identities[2].ID}, recipients)
+	})
+
+	s.T().Run("preferences only apply to their message type", func(t *testing.T) {
+		recipients, err := repo.ListRecipients(context.Background(), fxt.WorkItems[0].ID, "comment.create")
+		require.NoError(t, err)
+		assert.Len(t, recipients, 3)
+	})
+
+	s.T().Run("excluded identities", func(t *testing.T) {
+		recipients, err := repo.ListRecipients(context.Background(), fxt.WorkItems[0].ID, "comment.create", fxt.Identities[0].ID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{fxt.Identities[1].ID, fxt.Identities[2].ID}, recipients)
+	})
+}
+
+func (s *TestWatcherRepository) TestListSpaceRecipients() {
+	// the space is owned by the first identity
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(4), tf.Spaces(1))
+	ctx := context.Background()
+	for _, identity := range fxt.Identities[1:3] {
+		_, err := role.NewRepository(s.DB).Assign(ctx, fxt.Spaces[0].ID, identity.ID, role.Viewer)
+		require.NoError(s.T(), err)
+	}
+	_, err := watcher.NewPreferenceRepository(s.DB).Save(ctx, watcher.Preference{IdentityID: fxt.Identities[2].ID, MessageType: "deployment.unhealthy", Enabled: false})
+	require.NoError(s.T(), err)
+
+	s.T().Run("owner and members who did not opt out", func(t *testing.T) {
+		recipients, err := watcher.NewRepository(s.DB).ListSpaceRecipients(ctx, fxt.Spaces[0].ID, "deployment.unhealthy")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{fxt.Identities[0].ID, fxt.Identities[1].ID}, recipients)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		recipients, err := watcher.NewRepository(s.DB).ListSpaceRecipients(ctx, uuid.NewV4(), "deployment.unhealthy")
+		require.NoError(t, err)
+		assert.Empty(t, recipients)
+	})
+}
+
+func (s *TestWatcherRepository) TestSavePreference() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(1))
+	repo := watcher.NewPreferenceRepository(s.DB)
+
+	s.T().Run("create and update", func(t *testing.T) {
+		p, err := repo.Save(context.Background(), watcher.Preference{IdentityID: fxt.Identities[0].ID, MessageType: "comment.create", Enabled: false})
+		require.NoError(t, err)
+		assert.False(t, p.Enabled)
+		p, err = repo.Save(context.Background(), watcher.Preference{IdentityID: fxt.Identities[0].ID, MessageType: "comment.create", Enabled: true})
+		require.NoError(t, err)
+		assert.True(t, p.Enabled)
+		prefs, err := repo.List(context.Background(), fxt.Identities[0].ID)
+		require.NoError(t, err)
+		require.Len(t, prefs, 1)
+	})
+
+	s.T().Run("empty message type", func(t *testing.T) {
+		_, err := repo.Save(context.Background(), watcher.Preference{IdentityID: fxt.Identities[0].ID, MessageType: " "})
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.BadParameterError)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,4 @@
+// Package export writes the work items matching a filter as CSV or NDJSON,
+// with the display values of the users, iterations, areas and labels they
+// refer to instead of their IDs.
+package export
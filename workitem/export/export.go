@@ -0,0 +1,391 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Format is the format of an export
+type Format string
+
+// The supported formats
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	if f == FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// The columns that are not work item fields
+const (
+	ColumnID   = "id"
+	ColumnType = "type"
+)
+
+// DefaultColumns are the columns exported when none is given
+var DefaultColumns = []string{
+	workitem.SystemNumber,
+	ColumnType,
+	workitem.SystemTitle,
+	workitem.SystemState,
+	workitem.SystemAssignees,
+	workitem.SystemIteration,
+	workitem.SystemArea,
+	workitem.SystemLabels,
+}
+
+// listSeparator separates the values of a list in a CSV cell
+const listSeparator = ", "
+
+// batchSize is the number of work items loaded at once
+const batchSize = 500
+
+// Exporter writes the work items matching a filter with the display values
+// of the entities they refer to. The display values are cached for the
+// lifetime of the exporter.
+type Exporter struct {
+	db         application.DB
+	columns    []string
+	types      map[uuid.UUID]workitem.WorkItemType
+	users      map[uuid.UUID]string
+	iterations map[uuid.UUID]string
+	areas      map[uuid.UUID]string
+	labels     map[uuid.UUID]string
+}
+
+// NewExporter creates an exporter of the given columns, each of which must be
+// ColumnID, ColumnType or a field of at least one of the given work item
+// types.
+func NewExporter(db application.DB, types []workitem.WorkItemType, columns []string) (*Exporter, error) {
+	e := &Exporter{
+		db:         db,
+		columns:    columns,
+		types:      map[uuid.UUID]workitem.WorkItemType{},
+		users:      map[uuid.UUID]string{},
+		iterations: map[uuid.UUID]string{},
+		areas:      map[uuid.UUID]string{},
+		labels:     map[uuid.UUID]string{},
+	}
+	if len(e.columns) == 0 {
+		e.columns = DefaultColumns
+	}
+	for _, t := range types {
+		e.types[t.ID] = t
+	}
+	for _, c := range e.columns {
+		if !e.isKnownColumn(c) {
+			return nil, errors.NewBadParameterError("columns", c).Expected("id, type or a work item type field")
+		}
+	}
+	return e, nil
+}
+
+func (e *Exporter) isKnownColumn(column string) bool {
+	if column == ColumnID || column == ColumnType {
+		return true
+	}
+	for _, t := range e.types {
+		if _, ok := t.Fields[column]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Export writes the work items matching the given filter to w, in the order
+// of the work item lists. The work items are loaded and written by batches
+// and w is flushed after each batch if it is an http.Flusher, so that the
+// export never holds all the work items in memory.
+func (e *Exporter) Export(ctx context.Context, w io.Writer, format Format, filter string) error {
+	var rw rowWriter
+	switch format {
+	case FormatCSV:
+		rw = &csvRowWriter{w: csv.NewWriter(w)}
+	case FormatNDJSON:
+		rw = &ndjsonRowWriter{enc: json.NewEncoder(w)}
+	default:
+		return errors.NewBadParameterError("format", format).Expected(fmt.Sprintf("%s or %s", FormatCSV, FormatNDJSON))
+	}
+	if err := rw.header(e.columns); err != nil {
+		return errs.Wrap(err, "failed to write the export header")
+	}
+	var after *workitem.WorkItem
+	for {
+		var rows [][]interface{}
+		var items []workitem.WorkItem
+		err := application.Transactional(e.db, func(appl application.Application) error {
+			var err error
+			items, err = appl.SearchItems().FilterAfter(ctx, filter, after, batchSize)
+			if err != nil {
+				return err
+			}
+			rows = make([][]interface{}, len(items))
+			for i, wi := range items {
+				if rows[i], err = e.row(ctx, appl, wi); err != nil {
+					return errs.Wrapf(err, "failed to export the work item %s", wi.ID)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := rw.row(e.columns, row); err != nil {
+				return errs.Wrap(err, "failed to write the export row")
+			}
+		}
+		if err := rw.flush(); err != nil {
+			return errs.Wrap(err, "failed to write the export rows")
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		if len(items) < batchSize {
+			return nil
+		}
+		after = &items[len(items)-1]
+	}
+}
+
+// row returns the display values of the columns of the given work item
+func (e *Exporter) row(ctx context.Context, appl application.Application, wi workitem.WorkItem) ([]interface{}, error) {
+	wit, ok := e.types[wi.Type]
+	if !ok {
+		t, err := appl.WorkItemTypes().Load(ctx, wi.Type)
+		if err != nil {
+			return nil, err
+		}
+		wit = *t
+		e.types[wi.Type] = wit
+	}
+	res := make([]interface{}, len(e.columns))
+	for i, c := range e.columns {
+		switch c {
+		case ColumnID:
+			res[i] = wi.ID.String()
+		case ColumnType:
+			res[i] = wit.Name
+		default:
+			field, ok := wit.Fields[c]
+			if !ok {
+				continue
+			}
+			v, err := e.display(ctx, appl, field.Type, wi.Fields[c])
+			if err != nil {
+				return nil, errs.Wrapf(err, "failed to resolve the value of field %s", c)
+			}
+			res[i] = v
+		}
+	}
+	return res, nil
+}
+
+// display returns the display value of a field value of the given type
+func (e *Exporter) display(ctx context.Context, appl application.Application, fieldType workitem.FieldType, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch t := fieldType.(type) {
+	case workitem.ListType:
+		values, ok := value.([]interface{})
+		if !ok {
+			return value, nil
+		}
+		res := make([]interface{}, len(values))
+		for i, v := range values {
+			var err error
+			if res[i], err = e.displaySimple(ctx, appl, t.ComponentType.Kind, v); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case workitem.EnumType:
+		return e.displaySimple(ctx, appl, t.BaseType.Kind, value)
+	default:
+		return e.displaySimple(ctx, appl, fieldType.GetKind(), value)
+	}
+}
+
+func (e *Exporter) displaySimple(ctx context.Context, appl application.Application, kind workitem.Kind, value interface{}) (interface{}, error) {
+	switch kind {
+	case workitem.KindUser:
+		return e.resolve(value, e.users, func(id uuid.UUID) (string, error) {
+			identity, err := appl.Identities().Load(ctx, id)
+			if err != nil {
+				return "", err
+			}
+			return identity.Username, nil
+		})
+	case workitem.KindIteration:
+		return e.resolve(value, e.iterations, func(id uuid.UUID) (string, error) {
+			return e.iterationPath(ctx, appl, id)
+		})
+	case workitem.KindArea:
+		return e.resolve(value, e.areas, func(id uuid.UUID) (string, error) {
+			a, err := appl.Areas().Load(ctx, id)
+			if err != nil {
+				return "", err
+			}
+			return a.Name, nil
+		})
+	case workitem.KindLabel:
+		return e.resolve(value, e.labels, func(id uuid.UUID) (string, error) {
+			l, err := appl.Labels().Load(ctx, id)
+			if err != nil {
+				return "", err
+			}
+			return l.Name, nil
+		})
+	case workitem.KindInstant:
+		if t, ok := value.(time.Time); ok {
+			return t.UTC().Format(time.RFC3339), nil
+		}
+	case workitem.KindMarkup:
+		if m, ok := value.(rendering.MarkupContent); ok {
+			return m.Content, nil
+		}
+	case workitem.KindCodebase:
+		if c, ok := value.(codebase.Content); ok {
+			return c.Repository, nil
+		}
+	}
+	return value, nil
+}
+
+// iterationPath returns the names of the ancestors of the iteration and its
+// own name, separated like the resolved paths of the iterations API
+func (e *Exporter) iterationPath(ctx context.Context, appl application.Application, id uuid.UUID) (string, error) {
+	itr, err := appl.Iterations().Load(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	ancestors, err := appl.Iterations().LoadMultiple(ctx, itr.Path)
+	if err != nil {
+		return "", err
+	}
+	names := map[uuid.UUID]string{}
+	for _, a := range ancestors {
+		names[a.ID] = a.Name
+	}
+	res := ""
+	for _, ancestorID := range itr.Path {
+		if name, ok := names[ancestorID]; ok {
+			res += iteration.PathSepInService + name
+		}
+	}
+	return res + iteration.PathSepInService + itr.Name, nil
+}
+
+// resolve returns the display value of the entity whose ID is the given
+// value, using the cache. The ID itself is returned if the entity doesn't
+// exist anymore.
+func (e *Exporter) resolve(value interface{}, cache map[uuid.UUID]string, load func(uuid.UUID) (string, error)) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	id, err := uuid.FromString(s)
+	if err != nil {
+		return value, nil
+	}
+	if name, ok := cache[id]; ok {
+		return name, nil
+	}
+	name, err := load(id)
+	if err != nil {
+		if notFound, _ := errors.IsNotFoundError(err); !notFound {
+			return nil, err
+		}
+		name = s
+	}
+	cache[id] = name
+	return name, nil
+}
+
+// rowWriter writes the rows of an export in a given format
+type rowWriter interface {
+	header(columns []string) error
+	row(columns []string, values []interface{}) error
+	flush() error
+}
+
+// csvRowWriter writes a header line and one line per row, the values of the
+// lists being joined in a single cell
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRowWriter) header(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvRowWriter) row(columns []string, values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = csvCell(v)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRowWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func csvCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		cells := make([]string, len(v))
+		for i, e := range v {
+			cells[i] = csvCell(e)
+		}
+		return strings.Join(cells, listSeparator)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ndjsonRowWriter writes one JSON object per row keyed by the columns
+type ndjsonRowWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonRowWriter) header(columns []string) error {
+	return nil
+}
+
+func (n *ndjsonRowWriter) row(columns []string, values []interface{}) error {
+	obj := make(map[string]interface{}, len(columns))
+	for i, c := range columns {
+		obj[c] = values[i]
+	}
+	return n.enc.Encode(obj)
+}
+
+func (n *ndjsonRowWriter) flush() error {
+	return nil
+}
@@ -0,0 +1,168 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/export"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunExporter(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &exporterBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+type exporterBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	db application.DB
+}
+
+func (s *exporterBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+func (s *exporterBlackBoxTest) fixture(t *testing.T) *tf.TestFixture {
+	return tf.NewTestFixture(t, s.DB,
+		tf.Identities(2, tf.SetIdentityUsernames("alice", "bob")),
+		tf.Iterations(2, tf.SetIterationNames("root", "sprint1"), tf.PlaceIterationUnderRootIteration()),
+		tf.Areas(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.Areas[idx].Name = "backend"
+			return nil
+		}),
+		tf.Labels(2, tf.SetLabelNames("important", "ui")),
+		tf.WorkItemTypes(1, tf.SetWorkItemTypeNames("bug")),
+		tf.WorkItems(2, func(fxt *tf.TestFixture, idx int) error {
+			wi := fxt.WorkItems[idx]
+			wi.Fields[workitem.SystemTitle] = fmt.Sprintf("item %d", idx)
+			if idx == 0 {
+				wi.Fields[workitem.SystemIteration] = fxt.IterationByName("sprint1").ID.String()
+				wi.Fields[workitem.SystemArea] = fxt.Areas[0].ID.String()
+				wi.Fields[workitem.SystemLabels] = []string{fxt.LabelByName("important").ID.String(), fxt.LabelByName("ui").ID.String()}
+				wi.Fields[workitem.SystemAssignees] = []string{fxt.IdentityByUsername("alice").ID.String(), fxt.IdentityByUsername("bob").ID.String()}
+			}
+			return nil
+		}),
+	)
+}
+
+func types(fxt *tf.TestFixture) []workitem.WorkItemType {
+	res := make([]workitem.WorkItemType, len(fxt.WorkItemTypes))
+	for i, wit := range fxt.WorkItemTypes {
+		res[i] = *wit
+	}
+	return res
+}
+
+func (s *exporterBlackBoxTest) TestNewExporter() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItemTypes(1))
+
+	s.T().Run("default columns", func(t *testing.T) {
+		// when
+		_, err := export.NewExporter(s.db, types(fxt), nil)
+		// then
+		require.NoError(t, err)
+	})
+
+	s.T().Run("unknown column", func(t *testing.T) {
+		// when
+		_, err := export.NewExporter(s.db, types(fxt), []string{export.ColumnID, "foo"})
+		// then
+		require.IsType(t, errors.BadParameterError{}, err)
+	})
+}
+
+func (s *exporterBlackBoxTest) TestExport() {
+	columns := []string{export.ColumnID, workitem.SystemNumber, export.ColumnType, workitem.SystemTitle, workitem.SystemIteration, workitem.SystemArea, workitem.SystemLabels, workitem.SystemAssignees}
+
+	s.T().Run("csv", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		exporter, err := export.NewExporter(s.db, types(fxt), columns)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		// when
+		err = exporter.Export(context.Background(), &buf, export.FormatCSV, fmt.Sprintf(`{"space": "%s"}`, fxt.Spaces[0].ID))
+		// then
+		require.NoError(t, err)
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, columns, records[0])
+		// the newest work item comes first
+		assert.Equal(t, []string{fxt.WorkItems[1].ID.String(), strconv.Itoa(fxt.WorkItems[1].Number), "bug", "item 1", "", "", "", ""}, records[1])
+		assert.Equal(t, []string{fxt.WorkItems[0].ID.String(), strconv.Itoa(fxt.WorkItems[0].Number), "bug", "item 0", "/root/sprint1", "backend", "important, ui", "alice, bob"}, records[2])
+	})
+
+	s.T().Run("ndjson", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		exporter, err := export.NewExporter(s.db, types(fxt), columns)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		// when
+		err = exporter.Export(context.Background(), &buf, export.FormatNDJSON, fmt.Sprintf(`{"space": "%s"}`, fxt.Spaces[0].ID))
+		// then
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+		var row map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+		assert.Equal(t, fxt.WorkItems[0].ID.String(), row[export.ColumnID])
+		assert.Equal(t, "/root/sprint1", row[workitem.SystemIteration])
+		assert.Equal(t, "backend", row[workitem.SystemArea])
+		assert.Equal(t, []interface{}{"important", "ui"}, row[workitem.SystemLabels])
+		assert.Equal(t, []interface{}{"alice", "bob"}, row[workitem.SystemAssignees])
+	})
+
+	s.T().Run("unknown entity", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		unknownID := uuid.NewV4().String()
+		fxt.WorkItems[1].Fields[workitem.SystemAssignees] = []interface{}{unknownID}
+		err := application.Transactional(s.db, func(appl application.Application) error {
+			_, err := appl.WorkItems().Save(context.Background(), fxt.WorkItems[1].SpaceID, *fxt.WorkItems[1], fxt.Identities[0].ID)
+			return err
+		})
+		require.NoError(t, err)
+		exporter, err := export.NewExporter(s.db, types(fxt), []string{workitem.SystemAssignees})
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		// when
+		err = exporter.Export(context.Background(), &buf, export.FormatCSV, fmt.Sprintf(`{"space": "%s"}`, fxt.Spaces[0].ID))
+		// then
+		require.NoError(t, err)
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, []string{unknownID}, records[1])
+	})
+
+	s.T().Run("invalid filter", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t)
+		exporter, err := export.NewExporter(s.db, types(fxt), columns)
+		require.NoError(t, err)
+		// when
+		err = exporter.Export(context.Background(), &bytes.Buffer{}, export.FormatCSV, `{"space": `)
+		// then
+		require.Error(t, err)
+	})
+}
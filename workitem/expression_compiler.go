@@ -12,6 +12,15 @@ import (
 
 const (
 	jsonAnnotation = "JSON"
+
+	// JSONFieldPrefix can be put in front of the name of a work item field to
+	// look it up in the jsonb "fields" column even if the name doesn't contain
+	// a dot (e.g. "fields.priority" for the "priority" field).
+	JSONFieldPrefix = "fields."
+
+	// DeployedEnvironment is a pseudo field holding the environments a work
+	// item was deployed to, as recorded in the deployments table
+	DeployedEnvironment = "DeployedEnvironment"
 )
 
 // Compile takes an expression and compiles it to a where clause for use with
@@ -111,6 +120,10 @@ func (c *expressionCompiler) getFieldName(fieldName string) (mappedFieldName str
 		return Column(WorkItemStorage{}.TableName(), mappedFieldName), false
 	}
 
+	if strings.HasPrefix(fieldName, JSONFieldPrefix) {
+		return strings.TrimPrefix(fieldName, JSONFieldPrefix), true
+	}
+
 	if strings.Contains(fieldName, ".") {
 		// leave field untouched
 		return fieldName, true
@@ -308,7 +321,37 @@ func (c *expressionCompiler) Or(a *criteria.OrExpression) interface{} {
 	return c.binary(a, "OR")
 }
 
+// refersToDeployedEnvironment returns true if the given expression is the
+// DeployedEnvironment pseudo field
+func refersToDeployedEnvironment(e criteria.Expression) bool {
+	f, ok := e.(*criteria.FieldExpression)
+	return ok && f.FieldName == DeployedEnvironment
+}
+
+// deployedEnvironmentCondition returns the condition matching the work items
+// deployed to an environment satisfying the given condition on "d.environment".
+// A subquery is used instead of a join so that a work item delivered by
+// several deployments is not returned more than once.
+func deployedEnvironmentCondition(cond string) string {
+	return `EXISTS (SELECT 1 FROM work_item_deployments wid JOIN deployments d ON d.id = wid.deployment_id WHERE wid.work_item_id = ` +
+		Column(WorkItemStorage{}.TableName(), "id") + cond + `)`
+}
+
+// deployedEnvironment compiles the comparison of the DeployedEnvironment
+// pseudo field with the given expression
+func (c *expressionCompiler) deployedEnvironment(right criteria.Expression, op string) interface{} {
+	r, ok := right.Accept(c).(string)
+	if !ok {
+		c.err = append(c.err, errs.Errorf("failed to convert right expression to string: %+v", right))
+		return nil
+	}
+	return "(" + deployedEnvironmentCondition(" AND d.environment "+op+" "+r) + ")"
+}
+
 func (c *expressionCompiler) Equals(e *criteria.EqualsExpression) interface{} {
+	if refersToDeployedEnvironment(e.Left()) {
+		return c.deployedEnvironment(e.Right(), "=")
+	}
 	op := "="
 	if isInJSONContext(e.Left()) {
 		op = ":"
@@ -321,6 +364,20 @@ func (c *expressionCompiler) Equals(e *criteria.EqualsExpression) interface{} {
 }
 
 func (c *expressionCompiler) Substring(e *criteria.SubstringExpression) interface{} {
+	if refersToDeployedEnvironment(e.Left()) {
+		litExp, ok := e.Right().(*criteria.LiteralExpression)
+		if !ok {
+			c.err = append(c.err, errs.Errorf("failed to convert right expression to literal expression: %+v", e.Right()))
+			return nil
+		}
+		r, ok := litExp.Value.(string)
+		if !ok {
+			c.err = append(c.err, errs.Errorf("failed to convert value of right literal expression to string: %+v", litExp.Value))
+			return nil
+		}
+		c.parameters = append(c.parameters, "%"+r+"%")
+		return "(" + deployedEnvironmentCondition(" AND d.environment ILIKE ?") + ")"
+	}
 	inJSONContext := isInJSONContext(e.Left())
 	join, isJoinedRef := c.expressionRefersToJoinedData(e.Left())
 	if inJSONContext || isJoinedRef {
@@ -350,7 +407,8 @@ func (c *expressionCompiler) Substring(e *criteria.SubstringExpression) interfac
 		if inJSONContext {
 			r = "%" + r + "%"
 			c.parameters = append(c.parameters, r)
-			return Column(WorkItemStorage{}.TableName(), "fields") + `->>'` + left.FieldName + `' ILIKE ?`
+			fieldName, _ := c.getFieldName(left.FieldName)
+			return Column(WorkItemStorage{}.TableName(), "fields") + `->>'` + fieldName + `' ILIKE ?`
 		}
 		// Handle more complex joined field
 		col, err := join.TranslateFieldName(left.FieldName)
@@ -364,6 +422,17 @@ func (c *expressionCompiler) Substring(e *criteria.SubstringExpression) interfac
 }
 
 func (c *expressionCompiler) IsNull(e *criteria.IsNullExpression) interface{} {
+	if e.FieldName == DeployedEnvironment {
+		return "(NOT " + deployedEnvironmentCondition("") + ")"
+	}
+	if strings.Contains(e.FieldName, `"`) {
+		c.err = append(c.err, errs.Errorf("field name must not contain double quotes: %s", e.FieldName))
+		return nil
+	}
+	if strings.Contains(e.FieldName, `'`) {
+		c.err = append(c.err, errs.Errorf("field name must not contain single quotes: %s", e.FieldName))
+		return nil
+	}
 	mappedFieldName, isJSONField := c.getFieldName(e.FieldName)
 	if isJSONField {
 		return "(" + Column(WorkItemStorage{}.TableName(), "fields") + "->>'" + mappedFieldName + "' IS NULL)"
@@ -372,6 +441,13 @@ func (c *expressionCompiler) IsNull(e *criteria.IsNullExpression) interface{} {
 }
 
 func (c *expressionCompiler) Not(e *criteria.NotExpression) interface{} {
+	if refersToDeployedEnvironment(e.Left()) {
+		condition, ok := c.deployedEnvironment(e.Right(), "=").(string)
+		if !ok {
+			return nil
+		}
+		return "(NOT " + condition + ")"
+	}
 	if isInJSONContext(e.Left()) {
 		condition := c.binary(e, ":")
 		if condition != nil {
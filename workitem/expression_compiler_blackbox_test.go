@@ -18,6 +18,7 @@ func TestField(t *testing.T) {
 	wiTbl := workitem.WorkItemStorage{}.TableName()
 	expect(t, c.Equals(c.Field("foo.bar"), c.Literal(23)), `(`+workitem.Column(wiTbl, "fields")+` @> '{"foo.bar" : 23}')`, []interface{}{}, nil)
 	expect(t, c.Equals(c.Field("foo"), c.Literal(23)), `(`+workitem.Column(wiTbl, "foo")+` = ?)`, []interface{}{23}, nil)
+	expect(t, c.Equals(c.Field(workitem.JSONFieldPrefix+"foo"), c.Literal("abcd")), `(`+workitem.Column(wiTbl, "fields")+` @> '{"foo" : "abcd"}')`, []interface{}{}, nil)
 	expect(t, c.Equals(c.Field("Type"), c.Literal("abcd")), `(`+workitem.Column(wiTbl, "type")+` = ?)`, []interface{}{"abcd"}, nil)
 	expect(t, c.Not(c.Field("Type"), c.Literal("abcd")), `(`+workitem.Column(wiTbl, "type")+` != ?)`, []interface{}{"abcd"}, nil)
 	expect(t, c.Not(c.Field("Version"), c.Literal("abcd")), `(`+workitem.Column(wiTbl, "version")+` != ?)`, []interface{}{"abcd"}, nil)
@@ -145,6 +146,37 @@ func TestIsNull(t *testing.T) {
 	expect(t, c.IsNull("Version"), `(`+workitem.Column(wiTbl, "version")+` IS NULL)`, []interface{}{}, nil)
 	expect(t, c.IsNull("Number"), `(`+workitem.Column(wiTbl, "number")+` IS NULL)`, []interface{}{}, nil)
 	expect(t, c.IsNull("SpaceID"), `(`+workitem.Column(wiTbl, "space_id")+` IS NULL)`, []interface{}{}, nil)
+	t.Run("test illegal field name", func(t *testing.T) {
+		t.Run("double quote", func(t *testing.T) {
+			_, _, _, compileErrors := workitem.Compile(c.IsNull(`foo"bar`))
+			require.NotEmpty(t, compileErrors)
+			require.Contains(t, compileErrors[0].Error(), "field name must not contain double quotes")
+		})
+		t.Run("single quote", func(t *testing.T) {
+			_, _, _, compileErrors := workitem.Compile(c.IsNull(`fields.x') OR 1=1 --`))
+			require.NotEmpty(t, compileErrors)
+			require.Contains(t, compileErrors[0].Error(), "field name must not contain single quotes")
+		})
+	})
+}
+
+func TestDeployedEnvironment(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	deployed := `EXISTS (SELECT 1 FROM work_item_deployments wid JOIN deployments d ON d.id = wid.deployment_id WHERE wid.work_item_id = ` +
+		workitem.Column(workitem.WorkItemStorage{}.TableName(), "id")
+	t.Run("equals", func(t *testing.T) {
+		expect(t, c.Equals(c.Field(workitem.DeployedEnvironment), c.Literal("production")), `(`+deployed+` AND d.environment = ?))`, []interface{}{"production"}, nil)
+	})
+	t.Run("not", func(t *testing.T) {
+		expect(t, c.Not(c.Field(workitem.DeployedEnvironment), c.Literal("production")), `(NOT (`+deployed+` AND d.environment = ?)))`, []interface{}{"production"}, nil)
+	})
+	t.Run("substring", func(t *testing.T) {
+		expect(t, c.Substring(c.Field(workitem.DeployedEnvironment), c.Literal("prod")), `(`+deployed+` AND d.environment ILIKE ?))`, []interface{}{"%prod%"}, nil)
+	})
+	t.Run("null", func(t *testing.T) {
+		expect(t, c.IsNull(workitem.DeployedEnvironment), `(NOT `+deployed+`))`, []interface{}{}, nil)
+	})
 }
 
 func expect(t *testing.T, expr c.Expression, expectedClause string, expectedParameters []interface{}, expectedJoins []*workitem.TableJoin) {
@@ -184,6 +216,14 @@ func TestSubstring(t *testing.T) {
 
 		assert.Equal(t, workitem.Column(wiTbl, "fields")+`->>'system.title' ILIKE ?`, where)
 	})
+	t.Run("custom field", func(t *testing.T) {
+		exp := c.Substring(c.Field(workitem.JSONFieldPrefix+"priority"), c.Literal("crit"))
+		where, parameters, _, compileErrors := workitem.Compile(exp)
+		require.Empty(t, compileErrors)
+
+		assert.Equal(t, workitem.Column(wiTbl, "fields")+`->>'priority' ILIKE ?`, where)
+		assert.Equal(t, []interface{}{"%crit%"}, parameters)
+	})
 	t.Run("system.title with SQL injection text", func(t *testing.T) {
 		title := "some title"
 
@@ -0,0 +1,4 @@
+// Package importer creates work items from the rows of a CSV file, resolving
+// the users, iterations, areas and labels from their display values and
+// linking the rows to their parent rows.
+package importer
@@ -0,0 +1,352 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ColumnType is the target of the column holding the name of the type of the
+// work items
+const ColumnType = "type"
+
+// Options tells how the columns of the CSV file are imported
+type Options struct {
+	// Columns maps the CSV columns to the work item fields or to ColumnType.
+	// The columns that are not mapped are ignored.
+	Columns map[string]string
+	// TypeID is the type of the work items whose type is not given by a
+	// ColumnType column
+	TypeID *uuid.UUID
+	// ReferenceColumn is the column holding a reference to the row that is
+	// unique in the file
+	ReferenceColumn string
+	// ParentColumn is the column holding the reference of the parent row
+	ParentColumn string
+	// CreateMissing tells to create the iterations, areas and labels that
+	// don't exist instead of reporting an error
+	CreateMissing bool
+}
+
+// Report is the outcome of an import
+type Report struct {
+	Rows []RowReport
+	// Creates lists the iterations, areas and labels created by the import
+	Creates []string
+	// Committed is true if the work items were created
+	Committed bool
+}
+
+// Valid returns true if no row has errors
+func (r Report) Valid() bool {
+	for _, row := range r.Rows {
+		if len(row.Errors) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// RowReport is the outcome of the import of a single row
+type RowReport struct {
+	// Row is the position of the row in the file, starting at 1 after the
+	// header
+	Row       int
+	Reference string
+	Errors    []string
+	// WorkItemID and Number are set once the work item is created
+	WorkItemID *uuid.UUID
+	Number     *int
+}
+
+// row is a validated row ready to be created
+type row struct {
+	report *RowReport
+	typeID uuid.UUID
+	fields map[string]interface{}
+	parent string
+}
+
+// Importer validates the rows of a CSV file against the work item types of a
+// space and creates them. An importer is used for a single file.
+type Importer struct {
+	spaceID   uuid.UUID
+	creatorID uuid.UUID
+	options   Options
+	types     map[uuid.UUID]workitem.WorkItemType
+	resolver  *resolver
+	rows      []row
+	report    *Report
+}
+
+// NewImporter creates an importer of the work items of the given space, with
+// the given types and created by the given identity.
+func NewImporter(spaceID, creatorID uuid.UUID, types []workitem.WorkItemType, options Options) (*Importer, error) {
+	if len(options.Columns) == 0 {
+		return nil, errors.NewBadParameterError("columns", options.Columns).Expected("at least one column")
+	}
+	if options.ParentColumn != "" && options.ReferenceColumn == "" {
+		return nil, errors.NewBadParameterError("parent-column", options.ParentColumn).Expected("a reference column")
+	}
+	i := &Importer{
+		spaceID:   spaceID,
+		creatorID: creatorID,
+		options:   options,
+		types:     map[uuid.UUID]workitem.WorkItemType{},
+		resolver:  newResolver(spaceID, options.CreateMissing),
+	}
+	for _, t := range types {
+		i.types[t.ID] = t
+	}
+	if options.TypeID != nil {
+		if _, ok := i.types[*options.TypeID]; !ok {
+			return nil, errors.NewBadParameterError("type", *options.TypeID).Expected("a work item type of the space")
+		}
+	}
+	return i, nil
+}
+
+// Validate reads the CSV file and resolves the values of all the rows without
+// writing anything. The rows that can't be imported are reported with their
+// errors, an error is only returned if the file itself can't be read.
+func (i *Importer) Validate(ctx context.Context, appl application.Application, r io.Reader) (*Report, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, errors.NewBadParameterError("csv", err.Error()).Expected("a valid CSV file")
+	}
+	if len(records) == 0 {
+		return nil, errors.NewBadParameterError("csv", "").Expected("a header line")
+	}
+	header := map[string]int{}
+	for idx, column := range records[0] {
+		header[strings.TrimSpace(column)] = idx
+	}
+	for _, column := range i.columns() {
+		if _, ok := header[column]; !ok {
+			return nil, errors.NewBadParameterError("columns", column).Expected("a column of the CSV header")
+		}
+	}
+	i.report = &Report{Rows: make([]RowReport, len(records)-1)}
+	i.rows = make([]row, len(records)-1)
+	references := map[string]int{}
+	for idx, record := range records[1:] {
+		i.report.Rows[idx].Row = idx + 1
+		i.rows[idx].report = &i.report.Rows[idx]
+		cell := func(column string) string {
+			if pos, ok := header[column]; ok && pos < len(record) {
+				return strings.TrimSpace(record[pos])
+			}
+			return ""
+		}
+		if err := i.validateRow(ctx, appl, &i.rows[idx], cell); err != nil {
+			return nil, errs.Wrapf(err, "failed to validate row %d", idx+1)
+		}
+		if i.options.ReferenceColumn == "" {
+			continue
+		}
+		ref := cell(i.options.ReferenceColumn)
+		i.rows[idx].report.Reference = ref
+		if ref == "" {
+			i.rows[idx].report.addError("the reference is missing")
+		} else if other, ok := references[ref]; ok {
+			i.rows[idx].report.addError(fmt.Sprintf("the reference %q is already used by row %d", ref, other+1))
+		} else {
+			references[ref] = idx
+		}
+		if i.options.ParentColumn != "" {
+			i.rows[idx].parent = cell(i.options.ParentColumn)
+		}
+	}
+	i.validateParents(references)
+	i.report.Creates = i.resolver.creates
+	return i.report, nil
+}
+
+// mappedColumns returns the columns mapped to a field or to the type, sorted
+// so that the errors are always reported in the same order
+func (i *Importer) mappedColumns() []string {
+	var res []string
+	for column := range i.options.Columns {
+		res = append(res, column)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// columns returns all the columns the options refer to
+func (i *Importer) columns() []string {
+	res := i.mappedColumns()
+	for _, column := range []string{i.options.ReferenceColumn, i.options.ParentColumn} {
+		if column != "" {
+			res = append(res, column)
+		}
+	}
+	return res
+}
+
+// validateRow resolves the type and the field values of the row. The
+// invalid values are reported in the row, the error returned is about
+// anything else.
+func (i *Importer) validateRow(ctx context.Context, appl application.Application, r *row, cell func(string) string) error {
+	wit, err := i.rowType(cell)
+	if err != nil {
+		r.report.addError(err.Error())
+		return nil
+	}
+	r.typeID = wit.ID
+	r.fields = map[string]interface{}{}
+	for _, column := range i.mappedColumns() {
+		name := i.options.Columns[column]
+		if name == ColumnType {
+			continue
+		}
+		field, ok := wit.Fields[name]
+		if !ok {
+			r.report.addError(fmt.Sprintf("column %q: %s is not a field of the type %s", column, name, wit.Name))
+			continue
+		}
+		if field.ReadOnly {
+			r.report.addError(fmt.Sprintf("column %q: the field %s is read-only", column, name))
+			continue
+		}
+		value := cell(column)
+		if value == "" {
+			continue
+		}
+		v, err := i.resolver.value(ctx, appl, field.Type, value)
+		if _, ok := errs.Cause(err).(invalidValueError); ok {
+			r.report.addError(fmt.Sprintf("column %q: %s", column, err))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		r.fields[name] = v
+	}
+	r.fields[workitem.SystemCreator] = i.creatorID.String()
+	// validate the fields like the work item repository does on creation
+	var names []string
+	for name := range wit.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := wit.Fields[name]
+		if field.ReadOnly {
+			continue
+		}
+		if _, err := field.ConvertToModel(name, r.fields[name]); err != nil {
+			r.report.addError(err.Error())
+		}
+	}
+	return nil
+}
+
+// rowType returns the type of the work item of the row
+func (i *Importer) rowType(cell func(string) string) (*workitem.WorkItemType, error) {
+	for _, column := range i.mappedColumns() {
+		if i.options.Columns[column] != ColumnType || cell(column) == "" {
+			continue
+		}
+		for _, t := range i.types {
+			if strings.EqualFold(t.Name, cell(column)) && t.CanConstruct {
+				return &t, nil
+			}
+		}
+		return nil, errs.Errorf("column %q: unknown work item type %q", column, cell(column))
+	}
+	if i.options.TypeID == nil {
+		return nil, errs.New("the work item type is missing")
+	}
+	t := i.types[*i.options.TypeID]
+	if !t.CanConstruct {
+		return nil, errs.Errorf("cannot construct work items from %q", t.Name)
+	}
+	return &t, nil
+}
+
+// validateParents checks that the parents refer to other rows without
+// creating cycles
+func (i *Importer) validateParents(references map[string]int) {
+	for idx := range i.rows {
+		parent := i.rows[idx].parent
+		if parent == "" {
+			continue
+		}
+		if _, ok := references[parent]; !ok {
+			i.rows[idx].report.addError(fmt.Sprintf("unknown parent reference %q", parent))
+			i.rows[idx].parent = ""
+		}
+	}
+	for idx := range i.rows {
+		// a chain of parents longer than the number of rows loops
+		current := idx
+		for steps := 0; i.rows[current].parent != ""; steps++ {
+			if steps >= len(i.rows) {
+				i.rows[idx].report.addError("the parent references form a cycle")
+				break
+			}
+			current = references[i.rows[current].parent]
+		}
+	}
+}
+
+// Permissions returns the permissions required in the space to commit the
+// import
+func (i *Importer) Permissions() []role.Permission {
+	res := []role.Permission{role.EditWorkItems}
+	if i.options.ParentColumn != "" {
+		res = append(res, role.LinkWorkItems)
+	}
+	return append(res, i.resolver.permissions()...)
+}
+
+// Commit creates the missing iterations, areas and labels, the work items and
+// the links to their parents. It must be called after Validate returned a
+// valid report, in the same transaction.
+func (i *Importer) Commit(ctx context.Context, appl application.Application) error {
+	if i.report == nil || !i.report.Valid() {
+		return errors.NewBadParameterError("csv", "invalid rows").Expected("a validated file without errors")
+	}
+	if err := i.resolver.createMissing(ctx, appl); err != nil {
+		return err
+	}
+	references := map[string]uuid.UUID{}
+	for _, r := range i.rows {
+		wi, err := appl.WorkItems().Create(ctx, i.spaceID, r.typeID, r.fields, i.creatorID)
+		if err != nil {
+			return errs.Wrapf(err, "failed to create the work item of row %d", r.report.Row)
+		}
+		r.report.WorkItemID = &wi.ID
+		r.report.Number = &wi.Number
+		if r.report.Reference != "" {
+			references[r.report.Reference] = wi.ID
+		}
+	}
+	for _, r := range i.rows {
+		if r.parent == "" {
+			continue
+		}
+		_, err := appl.WorkItemLinks().Create(ctx, references[r.parent], *r.report.WorkItemID, link.SystemWorkItemLinkTypeParentChildID, i.creatorID)
+		if err != nil {
+			return errs.Wrapf(err, "failed to link the work item of row %d to its parent", r.report.Row)
+		}
+	}
+	i.report.Committed = true
+	return nil
+}
+
+func (r *RowReport) addError(msg string) {
+	r.Errors = append(r.Errors, msg)
+}
@@ -0,0 +1,266 @@
+package importer_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/criteria"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/importer"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunImporter(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &importerBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+type importerBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	db application.DB
+}
+
+func (s *importerBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.db = gormapplication.NewGormDB(s.DB)
+}
+
+// fixture creates a space with a root iteration "root" holding "sprint1",
+// the labels "important" and "ui", and two users, the second one having the
+// given email
+func (s *importerBlackBoxTest) fixture(t *testing.T, email string) *tf.TestFixture {
+	user := account.User{Email: email}
+	require.NoError(t, account.NewUserRepository(s.DB).Create(context.Background(), &user))
+	return tf.NewTestFixture(t, s.DB,
+		tf.Identities(2, func(fxt *tf.TestFixture, idx int) error {
+			if idx == 1 {
+				fxt.Identities[idx].UserID = id.NullUUID{UUID: user.ID, Valid: true}
+			}
+			return nil
+		}),
+		tf.Iterations(2, tf.SetIterationNames("root", "sprint1"), tf.PlaceIterationUnderRootIteration()),
+		tf.Labels(2, tf.SetLabelNames("important", "ui")),
+		tf.WorkItemTypes(1, tf.SetWorkItemTypeNames("story")),
+	)
+}
+
+func types(fxt *tf.TestFixture) []workitem.WorkItemType {
+	res := make([]workitem.WorkItemType, len(fxt.WorkItemTypes))
+	for i, wit := range fxt.WorkItemTypes {
+		res[i] = *wit
+	}
+	return res
+}
+
+// importCSV validates the given file and commits it if asked to
+func (s *importerBlackBoxTest) importCSV(t *testing.T, fxt *tf.TestFixture, options importer.Options, content string, commit bool) *importer.Report {
+	var report *importer.Report
+	err := application.Transactional(s.db, func(appl application.Application) error {
+		imp, err := importer.NewImporter(fxt.Spaces[0].ID, fxt.Identities[0].ID, types(fxt), options)
+		require.NoError(t, err)
+		report, err = imp.Validate(context.Background(), appl, strings.NewReader(content))
+		if err != nil || !commit {
+			return err
+		}
+		return imp.Commit(context.Background(), appl)
+	})
+	require.NoError(t, err)
+	return report
+}
+
+func (s *importerBlackBoxTest) options(fxt *tf.TestFixture) importer.Options {
+	return importer.Options{
+		Columns: map[string]string{
+			"Title":     workitem.SystemTitle,
+			"Kind":      importer.ColumnType,
+			"Assignees": workitem.SystemAssignees,
+			"Iteration": workitem.SystemIteration,
+			"Labels":    workitem.SystemLabels,
+		},
+		TypeID:          &fxt.WorkItemTypes[0].ID,
+		ReferenceColumn: "ID",
+		ParentColumn:    "Parent",
+	}
+}
+
+func (s *importerBlackBoxTest) TestNewImporter() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItemTypes(1))
+
+	s.T().Run("no columns", func(t *testing.T) {
+		// when
+		_, err := importer.NewImporter(fxt.Spaces[0].ID, fxt.Identities[0].ID, types(fxt), importer.Options{})
+		// then
+		require.IsType(t, errors.BadParameterError{}, err)
+	})
+
+	s.T().Run("parent without reference", func(t *testing.T) {
+		// when
+		_, err := importer.NewImporter(fxt.Spaces[0].ID, fxt.Identities[0].ID, types(fxt), importer.Options{
+			Columns:      map[string]string{"Title": workitem.SystemTitle},
+			ParentColumn: "Parent",
+		})
+		// then
+		require.IsType(t, errors.BadParameterError{}, err)
+	})
+
+	s.T().Run("unknown type", func(t *testing.T) {
+		// when
+		_, err := importer.NewImporter(fxt.Spaces[0].ID, fxt.Identities[0].ID, types(fxt), importer.Options{
+			Columns: map[string]string{"Title": workitem.SystemTitle},
+			TypeID:  ptrUUID(uuid.NewV4()),
+		})
+		// then
+		require.IsType(t, errors.BadParameterError{}, err)
+	})
+}
+
+func ptrUUID(id uuid.UUID) *uuid.UUID {
+	return &id
+}
+
+func (s *importerBlackBoxTest) TestImport() {
+	s.T().Run("dry run", func(t *testing.T) {
+		// given
+		email := fmt.Sprintf("bob-%s@example.com", uuid.NewV4())
+		fxt := s.fixture(t, email)
+		content := "ID,Title,Kind,Assignees,Iteration,Labels,Parent\n" +
+			"E1,Epic,story," + fxt.Identities[0].Username + ",/root/sprint1,\"important, ui\",\n" +
+			"S1,Story,,\"" + fxt.Identities[0].Username + ", " + email + "\",sprint1,ui,E1\n"
+		// when
+		report := s.importCSV(t, fxt, s.options(fxt), content, false)
+		// then
+		require.Len(t, report.Rows, 2)
+		assert.True(t, report.Valid(), "%+v", report.Rows)
+		assert.False(t, report.Committed)
+		assert.Equal(t, "S1", report.Rows[1].Reference)
+		assert.Nil(t, report.Rows[1].WorkItemID)
+		count, err := workitem.NewWorkItemRepository(s.DB).Count(context.Background(), fxt.Spaces[0].ID, criteria.Literal(true))
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	s.T().Run("commit", func(t *testing.T) {
+		// given
+		email := fmt.Sprintf("bob-%s@example.com", uuid.NewV4())
+		fxt := s.fixture(t, email)
+		content := "ID,Title,Kind,Assignees,Iteration,Labels,Parent\n" +
+			"E1,Epic,story," + fxt.Identities[0].Username + ",/root/sprint1,\"important, ui\",\n" +
+			"S1,Story,,\"" + fxt.Identities[0].Username + ", " + email + "\",sprint1,ui,E1\n"
+		// when
+		report := s.importCSV(t, fxt, s.options(fxt), content, true)
+		// then
+		require.True(t, report.Valid(), "%+v", report.Rows)
+		assert.True(t, report.Committed)
+		require.NotNil(t, report.Rows[0].WorkItemID)
+		require.NotNil(t, report.Rows[1].WorkItemID)
+		repo := workitem.NewWorkItemRepository(s.DB)
+		story, err := repo.LoadByID(context.Background(), *report.Rows[1].WorkItemID)
+		require.NoError(t, err)
+		assert.Equal(t, "Story", story.Fields[workitem.SystemTitle])
+		assert.Equal(t, fxt.IterationByName("sprint1").ID.String(), story.Fields[workitem.SystemIteration])
+		assert.Equal(t, []interface{}{fxt.Identities[0].ID.String(), fxt.Identities[1].ID.String()}, story.Fields[workitem.SystemAssignees])
+		assert.Equal(t, []interface{}{fxt.LabelByName("ui").ID.String()}, story.Fields[workitem.SystemLabels])
+		assert.Equal(t, fxt.Identities[0].ID.String(), story.Fields[workitem.SystemCreator])
+		links, err := link.NewWorkItemLinkRepository(s.DB).ListByWorkItem(context.Background(), *report.Rows[1].WorkItemID)
+		require.NoError(t, err)
+		require.Len(t, links, 1)
+		assert.Equal(t, *report.Rows[0].WorkItemID, links[0].SourceID)
+		assert.Equal(t, link.SystemWorkItemLinkTypeParentChildID, links[0].LinkTypeID)
+	})
+
+	s.T().Run("row errors", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t, fmt.Sprintf("bob-%s@example.com", uuid.NewV4()))
+		content := "ID,Title,Kind,Assignees,Iteration,Labels,Parent\n" +
+			"A,,story,,,,\n" +
+			"B,Unknown user,,carol,,,\n" +
+			"C,Unknown iteration,,,/root/sprint2,,\n" +
+			"D,Unknown label,,,,backend,\n" +
+			"D,Duplicate,,,,,\n" +
+			"E,Unknown parent,,,,,Z\n" +
+			"F,Cycle,,,,,G\n" +
+			"G,Cycle,,,,,F\n" +
+			"H,Unknown type,task,,,,\n"
+		// when
+		report := s.importCSV(t, fxt, s.options(fxt), content, false)
+		// then
+		require.Len(t, report.Rows, 9)
+		assert.False(t, report.Valid())
+		for _, row := range report.Rows {
+			assert.NotEmpty(t, row.Errors, "row %d", row.Row)
+		}
+		assert.Contains(t, report.Rows[1].Errors[0], `unknown user "carol"`)
+		assert.Contains(t, report.Rows[2].Errors[0], `unknown iteration "/root/sprint2"`)
+		assert.Contains(t, report.Rows[3].Errors[0], `unknown label "backend"`)
+		assert.Contains(t, report.Rows[4].Errors, `the reference "D" is already used by row 4`)
+		assert.Contains(t, report.Rows[5].Errors, `unknown parent reference "Z"`)
+		assert.Contains(t, report.Rows[6].Errors, "the parent references form a cycle")
+		assert.Contains(t, report.Rows[8].Errors[0], `unknown work item type "task"`)
+	})
+
+	s.T().Run("commit with row errors", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t, fmt.Sprintf("bob-%s@example.com", uuid.NewV4()))
+		err := application.Transactional(s.db, func(appl application.Application) error {
+			imp, err := importer.NewImporter(fxt.Spaces[0].ID, fxt.Identities[0].ID, types(fxt), s.options(fxt))
+			require.NoError(t, err)
+			_, err = imp.Validate(context.Background(), appl, strings.NewReader("ID,Title,Kind,Assignees,Iteration,Labels,Parent\nA,Title,,carol,,,\n"))
+			require.NoError(t, err)
+			// when
+			return imp.Commit(context.Background(), appl)
+		})
+		// then
+		require.IsType(t, errors.BadParameterError{}, err)
+	})
+
+	s.T().Run("create missing", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t, fmt.Sprintf("bob-%s@example.com", uuid.NewV4()))
+		options := s.options(fxt)
+		options.CreateMissing = true
+		content := "ID,Title,Kind,Assignees,Iteration,Labels,Parent\n" +
+			"A,Title,,,/root/sprint2/week1,\"backend, important\",\n" +
+			"B,Title,,,sprint2,backend,\n"
+		// when
+		report := s.importCSV(t, fxt, options, content, true)
+		// then
+		require.True(t, report.Valid(), "%+v", report.Rows)
+		assert.Equal(t, []string{"iteration /root/sprint2", "iteration /root/sprint2/week1", "label backend"}, report.Creates)
+		wi, err := workitem.NewWorkItemRepository(s.DB).LoadByID(context.Background(), *report.Rows[0].WorkItemID)
+		require.NoError(t, err)
+		week1, err := s.db.Iterations().Load(context.Background(), uuid.FromStringOrNil(wi.Fields[workitem.SystemIteration].(string)))
+		require.NoError(t, err)
+		assert.Equal(t, "week1", week1.Name)
+		labels, err := s.db.Labels().List(context.Background(), fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Len(t, labels, 3)
+	})
+
+	s.T().Run("missing column", func(t *testing.T) {
+		// given
+		fxt := s.fixture(t, fmt.Sprintf("bob-%s@example.com", uuid.NewV4()))
+		err := application.Transactional(s.db, func(appl application.Application) error {
+			imp, err := importer.NewImporter(fxt.Spaces[0].ID, fxt.Identities[0].ID, types(fxt), s.options(fxt))
+			require.NoError(t, err)
+			// when
+			_, err = imp.Validate(context.Background(), appl, strings.NewReader("ID,Title\nA,Title\n"))
+			return err
+		})
+		// then
+		require.IsType(t, errors.BadParameterError{}, err)
+	})
+}
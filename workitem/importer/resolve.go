@@ -0,0 +1,352 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/area"
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/label"
+	"github.com/fabric8-services/fabric8-wit/path"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/space/role"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// listSeparator separates the values of a list in a CSV cell
+const listSeparator = ","
+
+// dateLayout is the layout accepted for the instants besides RFC 3339
+const dateLayout = "2006-01-02"
+
+// invalidValueError is returned for the cells that can't be imported
+type invalidValueError struct {
+	msg string
+}
+
+func (e invalidValueError) Error() string {
+	return e.msg
+}
+
+func invalidValue(format string, args ...interface{}) error {
+	return invalidValueError{msg: fmt.Sprintf(format, args...)}
+}
+
+// node is an iteration or an area
+type node struct {
+	id   uuid.UUID
+	path path.Path
+}
+
+// tree holds the iterations or the areas of the space by path
+type tree struct {
+	root  string
+	nodes map[string]node
+}
+
+// resolver converts the cells to field values, resolving the entities by
+// their display value. The entities that don't exist are given an ID and
+// created on commit if allowed.
+type resolver struct {
+	spaceID            uuid.UUID
+	createMissing      bool
+	users              map[string]string
+	iterations         *tree
+	areas              *tree
+	labels             map[string]uuid.UUID
+	iterationsToCreate []iteration.Iteration
+	areasToCreate      []area.Area
+	labelsToCreate     []label.Label
+	// creates describes the entities to create
+	creates []string
+}
+
+func newResolver(spaceID uuid.UUID, createMissing bool) *resolver {
+	return &resolver{
+		spaceID:       spaceID,
+		createMissing: createMissing,
+		users:         map[string]string{},
+	}
+}
+
+// value converts the cell to a value of the given field type
+func (r *resolver) value(ctx context.Context, appl application.Application, fieldType workitem.FieldType, cell string) (interface{}, error) {
+	switch t := fieldType.(type) {
+	case workitem.ListType:
+		var res []interface{}
+		for _, item := range strings.Split(cell, listSeparator) {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			v, err := r.simpleValue(ctx, appl, t.ComponentType.Kind, item)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+		}
+		return res, nil
+	case workitem.EnumType:
+		return r.simpleValue(ctx, appl, t.BaseType.Kind, cell)
+	default:
+		return r.simpleValue(ctx, appl, fieldType.GetKind(), cell)
+	}
+}
+
+func (r *resolver) simpleValue(ctx context.Context, appl application.Application, kind workitem.Kind, cell string) (interface{}, error) {
+	switch kind {
+	case workitem.KindUser:
+		return r.user(ctx, appl, cell)
+	case workitem.KindIteration:
+		if r.iterations == nil {
+			if err := r.loadIterations(ctx, appl); err != nil {
+				return nil, err
+			}
+		}
+		return r.node(r.iterations, "iteration", cell)
+	case workitem.KindArea:
+		if r.areas == nil {
+			if err := r.loadAreas(ctx, appl); err != nil {
+				return nil, err
+			}
+		}
+		return r.node(r.areas, "area", cell)
+	case workitem.KindLabel:
+		return r.label(ctx, appl, cell)
+	case workitem.KindFloat:
+		f, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			return nil, invalidValue("%q is not a number", cell)
+		}
+		return f, nil
+	case workitem.KindInteger, workitem.KindDuration:
+		i, err := strconv.ParseInt(cell, 10, 64)
+		if err != nil {
+			return nil, invalidValue("%q is not an integer", cell)
+		}
+		return i, nil
+	case workitem.KindBoolean:
+		b, err := strconv.ParseBool(cell)
+		if err != nil {
+			return nil, invalidValue("%q is not a boolean", cell)
+		}
+		return b, nil
+	case workitem.KindInstant:
+		if t, err := time.Parse(time.RFC3339, cell); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse(dateLayout, cell)
+		if err != nil {
+			return nil, invalidValue("%q is not a date", cell)
+		}
+		return t, nil
+	case workitem.KindMarkup:
+		return rendering.NewMarkupContentFromLegacy(cell), nil
+	case workitem.KindCodebase:
+		return codebase.Content{Repository: cell}, nil
+	default:
+		return cell, nil
+	}
+}
+
+// user returns the ID of the identity with the given username or, if the
+// value is an email address, the identity of the user with that email
+func (r *resolver) user(ctx context.Context, appl application.Application, value string) (string, error) {
+	key := strings.ToLower(value)
+	if id, ok := r.users[key]; ok {
+		return id, nil
+	}
+	var identities []account.Identity
+	if strings.Contains(value, "@") {
+		users, err := appl.Users().Query(account.UserFilterByEmail(value))
+		if err != nil {
+			return "", errs.Wrapf(err, "failed to look the user %s up", value)
+		}
+		if len(users) > 0 {
+			identities, err = appl.Identities().Query(account.IdentityFilterByUserID(users[0].ID))
+			if err != nil {
+				return "", errs.Wrapf(err, "failed to look the identity of the user %s up", value)
+			}
+		}
+	} else {
+		var err error
+		identities, err = appl.Identities().Query(account.IdentityFilterByUsername(value))
+		if err != nil {
+			return "", errs.Wrapf(err, "failed to look the identity %s up", value)
+		}
+	}
+	if len(identities) == 0 {
+		return "", invalidValue("unknown user %q", value)
+	}
+	r.users[key] = identities[0].ID.String()
+	return r.users[key], nil
+}
+
+func (r *resolver) loadIterations(ctx context.Context, appl application.Application) error {
+	itrs, err := appl.Iterations().List(ctx, r.spaceID)
+	if err != nil {
+		return errs.Wrap(err, "failed to list the iterations of the space")
+	}
+	names := map[uuid.UUID]string{}
+	for _, itr := range itrs {
+		names[itr.ID] = itr.Name
+	}
+	r.iterations = &tree{nodes: map[string]node{}}
+	for _, itr := range itrs {
+		p := nodePath(names, itr.Path, itr.Name)
+		r.iterations.nodes[p] = node{id: itr.ID, path: itr.Path}
+		if len(itr.Path) == 0 {
+			r.iterations.root = p
+		}
+	}
+	return nil
+}
+
+func (r *resolver) loadAreas(ctx context.Context, appl application.Application) error {
+	areas, err := appl.Areas().List(ctx, r.spaceID)
+	if err != nil {
+		return errs.Wrap(err, "failed to list the areas of the space")
+	}
+	names := map[uuid.UUID]string{}
+	for _, a := range areas {
+		names[a.ID] = a.Name
+	}
+	r.areas = &tree{nodes: map[string]node{}}
+	for _, a := range areas {
+		p := nodePath(names, a.Path, a.Name)
+		r.areas.nodes[p] = node{id: a.ID, path: a.Path}
+		if len(a.Path) == 0 {
+			r.areas.root = p
+		}
+	}
+	return nil
+}
+
+// nodePath returns the path of names of an iteration or an area, like the
+// resolved paths of the iterations API
+func nodePath(names map[uuid.UUID]string, ancestors path.Path, name string) string {
+	res := ""
+	for _, id := range ancestors {
+		res += iteration.PathSepInService + names[id]
+	}
+	return res + iteration.PathSepInService + name
+}
+
+// node returns the ID of the iteration or area with the given path. The path
+// starts at the root, whose name may be omitted.
+func (r *resolver) node(t *tree, kind, value string) (string, error) {
+	var segments []string
+	for _, s := range strings.Split(value, iteration.PathSepInService) {
+		if s = strings.TrimSpace(s); s != "" {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) == 0 {
+		return "", invalidValue("invalid %s path %q", kind, value)
+	}
+	p := iteration.PathSepInService + strings.Join(segments, iteration.PathSepInService)
+	if n, ok := t.nodes[p]; ok {
+		return n.id.String(), nil
+	}
+	if t.root == "" {
+		return "", invalidValue("the space has no root %s", kind)
+	}
+	if !strings.HasPrefix(p+iteration.PathSepInService, t.root+iteration.PathSepInService) {
+		p = t.root + p
+		if n, ok := t.nodes[p]; ok {
+			return n.id.String(), nil
+		}
+	}
+	if !r.createMissing {
+		return "", invalidValue("unknown %s %q", kind, value)
+	}
+	// create the missing ancestors of the path too, under the root
+	parent := t.nodes[t.root]
+	current := t.root
+	for _, name := range strings.Split(strings.TrimPrefix(p, t.root+iteration.PathSepInService), iteration.PathSepInService) {
+		current += iteration.PathSepInService + name
+		n, ok := t.nodes[current]
+		if !ok {
+			n = node{id: uuid.NewV4(), path: append(append(path.Path{}, parent.path...), parent.id)}
+			t.nodes[current] = n
+			r.creates = append(r.creates, kind+" "+current)
+			switch kind {
+			case "iteration":
+				r.iterationsToCreate = append(r.iterationsToCreate, iteration.Iteration{ID: n.id, SpaceID: r.spaceID, Path: n.path, Name: name})
+			case "area":
+				r.areasToCreate = append(r.areasToCreate, area.Area{ID: n.id, SpaceID: r.spaceID, Path: n.path, Name: name})
+			}
+		}
+		parent = n
+	}
+	return parent.id.String(), nil
+}
+
+// label returns the ID of the label with the given name
+func (r *resolver) label(ctx context.Context, appl application.Application, name string) (string, error) {
+	if r.labels == nil {
+		labels, err := appl.Labels().List(ctx, r.spaceID)
+		if err != nil {
+			return "", errs.Wrap(err, "failed to list the labels of the space")
+		}
+		r.labels = map[string]uuid.UUID{}
+		for _, l := range labels {
+			r.labels[strings.ToLower(l.Name)] = l.ID
+		}
+	}
+	if id, ok := r.labels[strings.ToLower(name)]; ok {
+		return id.String(), nil
+	}
+	if !r.createMissing {
+		return "", invalidValue("unknown label %q", name)
+	}
+	l := label.Label{ID: uuid.NewV4(), SpaceID: r.spaceID, Name: name}
+	r.labels[strings.ToLower(name)] = l.ID
+	r.labelsToCreate = append(r.labelsToCreate, l)
+	r.creates = append(r.creates, "label "+name)
+	return l.ID.String(), nil
+}
+
+// permissions returns the permissions required to create the missing entities
+func (r *resolver) permissions() []role.Permission {
+	var res []role.Permission
+	if len(r.iterationsToCreate) > 0 {
+		res = append(res, role.ManageIterations)
+	}
+	if len(r.areasToCreate) > 0 {
+		res = append(res, role.ManageAreas)
+	}
+	if len(r.labelsToCreate) > 0 {
+		res = append(res, role.ManageLabels)
+	}
+	return res
+}
+
+// createMissing creates the missing entities, the parents before their
+// children
+func (r *resolver) createMissing(ctx context.Context, appl application.Application) error {
+	for idx := range r.iterationsToCreate {
+		if err := appl.Iterations().Create(ctx, &r.iterationsToCreate[idx]); err != nil {
+			return errs.Wrapf(err, "failed to create the iteration %s", r.iterationsToCreate[idx].Name)
+		}
+	}
+	for idx := range r.areasToCreate {
+		if err := appl.Areas().Create(ctx, &r.areasToCreate[idx]); err != nil {
+			return errs.Wrapf(err, "failed to create the area %s", r.areasToCreate[idx].Name)
+		}
+	}
+	for idx := range r.labelsToCreate {
+		if err := appl.Labels().Create(ctx, &r.labelsToCreate[idx]); err != nil {
+			return errs.Wrapf(err, "failed to create the label %s", r.labelsToCreate[idx].Name)
+		}
+	}
+	return nil
+}
@@ -38,6 +38,9 @@ type WorkItemLinkRepository interface {
 	Load(ctx context.Context, ID uuid.UUID) (*WorkItemLink, error)
 	List(ctx context.Context) ([]WorkItemLink, error)
 	ListByWorkItem(ctx context.Context, wiID uuid.UUID) ([]WorkItemLink, error)
+	// ListByTopology returns the links between the work items of the given
+	// space whose link type has the given topology.
+	ListByTopology(ctx context.Context, spaceID uuid.UUID, topology Topology) ([]WorkItemLink, error)
 	DeleteRelatedLinks(ctx context.Context, wiID uuid.UUID, suppressorID uuid.UUID) error
 	Delete(ctx context.Context, ID uuid.UUID, suppressorID uuid.UUID) error
 	ListChildLinks(ctx context.Context, linkTypeID uuid.UUID, parentIDs ...uuid.UUID) (WorkItemLinkList, error)
@@ -318,6 +321,29 @@ func (r *GormWorkItemLinkRepository) ListByWorkItem(ctx context.Context, wiID uu
 	return modelLinks, nil
 }
 
+// ListByTopology returns the links between the work items of the given space
+// whose link type has the given topology.
+func (r *GormWorkItemLinkRepository) ListByTopology(ctx context.Context, spaceID uuid.UUID, topology Topology) ([]WorkItemLink, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemlink", "listByTopology"}, time.Now())
+	var modelLinks []WorkItemLink
+	db := r.db.Table(WorkItemLink{}.TableName()+" l").
+		Select("l.*").
+		Joins(fmt.Sprintf("JOIN %s t ON t.id = l.link_type_id AND t.deleted_at IS NULL", WorkItemLinkType{}.TableName())).
+		Joins(fmt.Sprintf("JOIN %s w ON w.id = l.source_id AND w.deleted_at IS NULL", workitem.WorkItemStorage{}.TableName())).
+		Where("l.deleted_at IS NULL AND t.topology = ? AND w.space_id = ?", topology, spaceID).
+		Order("l.created_at").
+		Scan(&modelLinks)
+	if db.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"topology": topology,
+			"err":      db.Error,
+		}, "unable to list the work item links by topology")
+		return nil, errors.NewInternalError(ctx, db.Error)
+	}
+	return modelLinks, nil
+}
+
 // List returns all work item links if wiID is nil; otherwise the work item links are returned
 // that have wiID as source or target.
 // TODO: Handle pagination
@@ -361,12 +387,19 @@ func (r *GormWorkItemLinkRepository) DeleteRelatedLinks(ctx context.Context, wiI
 		"wi_id": wiID,
 	}, "Deleting the links related to work item")
 	var workitemLinks = []WorkItemLink{}
-	r.db.Where("? in (source_id, target_id)", wiID).Find(&workitemLinks)
+	if err := r.db.Where("? in (source_id, target_id)", wiID).Find(&workitemLinks).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"wi_id": wiID,
+			"err":   err,
+		}, "unable to list the links related to the work item")
+		return errors.NewInternalError(ctx, err)
+	}
 	// delete one by one to trigger the creation of a new work item link revision
 	locked := false
 	for _, workitemLink := range workitemLinks {
 		if !locked {
-			wiRepo := workitem.NewWorkItemRepository(r.db)
+			// the source may be the work item that has just been deleted
+			wiRepo := workitem.NewWorkItemRepository(r.db.Unscoped())
 			src, err := wiRepo.LoadByID(ctx, workitemLink.SourceID)
 			if err != nil {
 				return errs.Wrapf(err, "failed to load source work item for: %s", workitemLink.SourceID)
@@ -376,7 +409,20 @@ func (r *GormWorkItemLinkRepository) DeleteRelatedLinks(ctx context.Context, wiI
 			}
 			locked = true
 		}
-		r.deleteLink(ctx, workitemLink, suppressorID)
+		if err := r.deleteLink(ctx, workitemLink, suppressorID); err != nil {
+			return errs.Wrapf(err, "failed to delete the work item link %s", workitemLink.ID)
+		}
+		// remember why the link was deleted so that it can be restored
+		// together with the work item (see the trash package)
+		markStmt := fmt.Sprintf("UPDATE %s SET deleted_with_work_item_id = $1 WHERE id = $2", WorkItemLink{}.TableName())
+		if err := r.db.Exec(markStmt, wiID, workitemLink.ID).Error; err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"wi_id":  wiID,
+				"wil_id": workitemLink.ID,
+				"err":    err,
+			}, "unable to mark the work item link as deleted with the work item")
+			return errors.NewInternalError(ctx, err)
+		}
 	}
 	return nil
 }
@@ -60,6 +60,22 @@ func (s *linkRepoBlackBoxTest) TestList() {
 	})
 }
 
+func (s *linkRepoBlackBoxTest) TestListByTopology() {
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.WorkItemLinkTypes(2,
+			tf.SetTopologies(link.TopologyDependency, link.TopologyNetwork),
+			tf.SetWorkItemLinkTypeNames("depends", "relates"),
+		),
+		tf.WorkItems(4, tf.SetWorkItemTitles("A", "B", "C", "D")),
+		tf.WorkItemLinksCustom(2, tf.BuildLinks(tf.L("A", "B", "depends"), tf.L("C", "D", "relates"))),
+	)
+	links, err := s.workitemLinkRepo.ListByTopology(s.Ctx, fxt.Spaces[0].ID, link.TopologyDependency)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), links, 1)
+	require.Equal(s.T(), fxt.WorkItemByTitle("A").ID, links[0].SourceID)
+	require.Equal(s.T(), fxt.WorkItemByTitle("B").ID, links[0].TargetID)
+}
+
 func (s *linkRepoBlackBoxTest) TestReorder() {
 	// setup creates 1 parent with 3 children
 	setup := func(t *testing.T) *tf.TestFixture {
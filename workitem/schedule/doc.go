@@ -0,0 +1,3 @@
+// Package schedule computes the order in which work items can be worked on
+// given the links of a "dependency" topology between them.
+package schedule
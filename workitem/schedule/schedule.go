@@ -0,0 +1,232 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// EffortField is the name of the work item field whose numeric value is used
+// as the weight of a work item when computing the critical path. Work items
+// without an effort weigh 1.
+const EffortField = "effort"
+
+// finishedStates holds the (lower case) states of the work items that are done
+// with, across the space templates.
+var finishedStates = map[string]struct{}{
+	"closed":   {},
+	"resolved": {},
+	"done":     {},
+	"removed":  {},
+}
+
+// FinishedStates returns the (lower case) states of the work items that are
+// done with, in alphabetical order
+func FinishedStates() []string {
+	res := make([]string, 0, len(finishedStates))
+	for state := range finishedStates {
+		res = append(res, state)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// IsFinishedState returns true if a work item in the given state doesn't
+// block the work items that depend on it.
+func IsFinishedState(state string) bool {
+	_, ok := finishedStates[strings.ToLower(strings.TrimSpace(state))]
+	return ok
+}
+
+// Item is a work item as seen by the scheduler
+type Item struct {
+	ID       uuid.UUID
+	Finished bool
+	// Effort is the weight of the item on the critical path
+	Effort float64
+	// IterationID is the iteration the item is scheduled in, if any
+	IterationID *uuid.UUID
+	// IterationEnd is the end of the iteration the item is scheduled in, if
+	// known (requires IterationID)
+	IterationEnd *time.Time
+}
+
+// Dependency says that the successor can only be finished once the
+// predecessor is. It corresponds to a link of a "dependency" topology from the
+// predecessor (source) to the successor (target).
+type Dependency struct {
+	PredecessorID uuid.UUID
+	SuccessorID   uuid.UUID
+}
+
+// Blocked is an unfinished work item with unfinished predecessors
+type Blocked struct {
+	ID        uuid.UUID
+	BlockedBy []uuid.UUID
+}
+
+// Conflict is a work item scheduled in an iteration that ends before the one
+// of an unfinished predecessor
+type Conflict struct {
+	ID                    uuid.UUID
+	IterationID           uuid.UUID
+	DependencyID          uuid.UUID
+	DependencyIterationID uuid.UUID
+}
+
+// Plan is the result of the scheduling
+type Plan struct {
+	// Order lists the items so that every item comes after its predecessors
+	Order []uuid.UUID
+	// Dependencies between the items of the plan
+	Dependencies []Dependency
+	// CriticalPath is the chain of dependent items with the highest
+	// remaining effort
+	CriticalPath []uuid.UUID
+	// CriticalPathEffort is the remaining effort on the critical path
+	CriticalPathEffort float64
+	Blocked            []Blocked
+	Conflicts          []Conflict
+}
+
+// NewPlan computes the plan of the given items. Dependencies are only part of
+// the plan when both of their ends are in the given items but the known
+// predecessors outside of the plan are still taken into account to find the
+// blocked items and the scheduling conflicts. Items are expected in a stable
+// order (e.g. by number) which is used to break ties.
+// Returns a DataConflictError if the dependencies contain a cycle.
+func NewPlan(items []Item, known map[uuid.UUID]Item, dependencies []Dependency) (*Plan, error) {
+	inPlan := make(map[uuid.UUID]Item, len(items))
+	for _, item := range items {
+		inPlan[item.ID] = item
+	}
+	plan := Plan{
+		Order:        []uuid.UUID{},
+		Dependencies: []Dependency{},
+		CriticalPath: []uuid.UUID{},
+		Blocked:      []Blocked{},
+		Conflicts:    []Conflict{},
+	}
+	predecessors := map[uuid.UUID][]uuid.UUID{}
+	successors := map[uuid.UUID][]uuid.UUID{}
+	inDegree := map[uuid.UUID]int{}
+	for _, dep := range dependencies {
+		if _, ok := inPlan[dep.SuccessorID]; !ok {
+			continue
+		}
+		predecessors[dep.SuccessorID] = append(predecessors[dep.SuccessorID], dep.PredecessorID)
+		if _, ok := inPlan[dep.PredecessorID]; !ok {
+			continue
+		}
+		plan.Dependencies = append(plan.Dependencies, dep)
+		successors[dep.PredecessorID] = append(successors[dep.PredecessorID], dep.SuccessorID)
+		inDegree[dep.SuccessorID]++
+	}
+
+	// topological sort (Kahn), keeping the given order among the ready items
+	ready := []uuid.UUID{}
+	for _, item := range items {
+		if inDegree[item.ID] == 0 {
+			ready = append(ready, item.ID)
+		}
+	}
+	position := make(map[uuid.UUID]int, len(items))
+	for i, item := range items {
+		position[item.ID] = i
+	}
+	for len(ready) > 0 {
+		next := 0
+		for i := range ready {
+			if position[ready[i]] < position[ready[next]] {
+				next = i
+			}
+		}
+		id := ready[next]
+		ready = append(ready[:next], ready[next+1:]...)
+		plan.Order = append(plan.Order, id)
+		for _, succ := range successors[id] {
+			inDegree[succ]--
+			if inDegree[succ] == 0 {
+				ready = append(ready, succ)
+			}
+		}
+	}
+	if len(plan.Order) < len(items) {
+		cyclic := []string{}
+		for _, item := range items {
+			if inDegree[item.ID] > 0 {
+				cyclic = append(cyclic, item.ID.String())
+			}
+		}
+		return nil, errors.NewDataConflictError(fmt.Sprintf("the dependencies between the work items %s contain a cycle", strings.Join(cyclic, ", ")))
+	}
+
+	// critical path: the longest path by remaining effort (none if everything
+	// is finished)
+	effort := map[uuid.UUID]float64{}
+	previous := map[uuid.UUID]uuid.UUID{}
+	var last uuid.UUID
+	for _, id := range plan.Order {
+		item := inPlan[id]
+		var best float64
+		for _, pred := range predecessors[id] {
+			if _, ok := inPlan[pred]; !ok {
+				continue
+			}
+			if _, ok := previous[id]; !ok || effort[pred] > best {
+				best = effort[pred]
+				previous[id] = pred
+			}
+		}
+		if !item.Finished {
+			best += item.Effort
+		}
+		effort[id] = best
+		if last == uuid.Nil || best > effort[last] {
+			last = id
+		}
+	}
+	if last != uuid.Nil && effort[last] > 0 {
+		plan.CriticalPathEffort = effort[last]
+		for id, ok := last, true; ok; id, ok = previous[id] {
+			plan.CriticalPath = append([]uuid.UUID{id}, plan.CriticalPath...)
+		}
+	}
+
+	// blocked items and scheduling conflicts
+	for _, id := range plan.Order {
+		item := inPlan[id]
+		if item.Finished {
+			continue
+		}
+		blockedBy := []uuid.UUID{}
+		for _, predID := range predecessors[id] {
+			pred, ok := inPlan[predID]
+			if !ok {
+				if pred, ok = known[predID]; !ok {
+					continue
+				}
+			}
+			if pred.Finished {
+				continue
+			}
+			blockedBy = append(blockedBy, predID)
+			if item.IterationEnd != nil && pred.IterationEnd != nil && item.IterationEnd.Before(*pred.IterationEnd) {
+				plan.Conflicts = append(plan.Conflicts, Conflict{
+					ID:                    id,
+					IterationID:           *item.IterationID,
+					DependencyID:          predID,
+					DependencyIterationID: *pred.IterationID,
+				})
+			}
+		}
+		if len(blockedBy) > 0 {
+			plan.Blocked = append(plan.Blocked, Blocked{ID: id, BlockedBy: blockedBy})
+		}
+	}
+	return &plan, nil
+}
@@ -0,0 +1,101 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/workitem/schedule"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsFinishedState(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	for state, finished := range map[string]bool{
+		"closed":      true,
+		"Done":        true,
+		"Removed":     true,
+		"resolved":    true,
+		"open":        false,
+		"In Progress": false,
+		"":            false,
+	} {
+		assert.Equal(t, finished, schedule.IsFinishedState(state), state)
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	ids := make([]uuid.UUID, 5)
+	for i := range ids {
+		ids[i] = uuid.NewV4()
+	}
+	dep := func(pred, succ int) schedule.Dependency {
+		return schedule.Dependency{PredecessorID: ids[pred], SuccessorID: ids[succ]}
+	}
+
+	t.Run("order and critical path", func(t *testing.T) {
+		// 0 -> 1 -> 3 and 2 -> 3, where 2 has a big effort
+		items := []schedule.Item{
+			{ID: ids[0], Effort: 1},
+			{ID: ids[1], Effort: 1},
+			{ID: ids[2], Effort: 5},
+			{ID: ids[3], Effort: 1},
+		}
+		plan, err := schedule.NewPlan(items, nil, []schedule.Dependency{dep(1, 3), dep(0, 1), dep(2, 3)})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{ids[0], ids[1], ids[2], ids[3]}, plan.Order)
+		assert.Equal(t, []uuid.UUID{ids[2], ids[3]}, plan.CriticalPath)
+		assert.Equal(t, float64(6), plan.CriticalPathEffort)
+		assert.Len(t, plan.Dependencies, 3)
+		require.Len(t, plan.Blocked, 2)
+		assert.Equal(t, ids[1], plan.Blocked[0].ID)
+		assert.Equal(t, []uuid.UUID{ids[0]}, plan.Blocked[0].BlockedBy)
+		assert.Equal(t, ids[3], plan.Blocked[1].ID)
+		assert.Equal(t, []uuid.UUID{ids[1], ids[2]}, plan.Blocked[1].BlockedBy)
+	})
+
+	t.Run("finished items", func(t *testing.T) {
+		items := []schedule.Item{
+			{ID: ids[0], Effort: 3, Finished: true},
+			{ID: ids[1], Effort: 1},
+		}
+		plan, err := schedule.NewPlan(items, nil, []schedule.Dependency{dep(0, 1)})
+		require.NoError(t, err)
+		assert.Empty(t, plan.Blocked)
+		assert.Equal(t, float64(1), plan.CriticalPathEffort)
+	})
+
+	t.Run("predecessor outside of the plan", func(t *testing.T) {
+		iter1, iter2 := uuid.NewV4(), uuid.NewV4()
+		end1, end2 := time.Now(), time.Now().Add(7*24*time.Hour)
+		items := []schedule.Item{
+			{ID: ids[1], Effort: 1, IterationID: &iter1, IterationEnd: &end1},
+		}
+		known := map[uuid.UUID]schedule.Item{
+			ids[0]: {ID: ids[0], Effort: 1, IterationID: &iter2, IterationEnd: &end2},
+		}
+		plan, err := schedule.NewPlan(items, known, []schedule.Dependency{dep(0, 1)})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{ids[1]}, plan.Order)
+		assert.Empty(t, plan.Dependencies)
+		require.Len(t, plan.Blocked, 1)
+		assert.Equal(t, []uuid.UUID{ids[0]}, plan.Blocked[0].BlockedBy)
+		require.Len(t, plan.Conflicts, 1)
+		assert.Equal(t, schedule.Conflict{ID: ids[1], IterationID: iter1, DependencyID: ids[0], DependencyIterationID: iter2}, plan.Conflicts[0])
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		items := []schedule.Item{{ID: ids[0]}, {ID: ids[1]}, {ID: ids[4]}}
+		_, err := schedule.NewPlan(items, nil, []schedule.Dependency{dep(0, 1), dep(1, 0)})
+		require.Error(t, err)
+		_, ok := errs.Cause(err).(errors.DataConflictError)
+		assert.True(t, ok)
+	})
+}
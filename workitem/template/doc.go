@@ -0,0 +1,3 @@
+// Package template contains the required operations to store reusable trees
+// of work items and to substitute the placeholders in their titles.
+package template
@@ -0,0 +1,124 @@
+package template
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Node is a single work item in a template tree. The fields are kept in the
+// same format as in the work item model, the children are the work items that
+// get linked to this one with the parent/child link type upon instantiation.
+type Node struct {
+	TypeID   uuid.UUID              `json:"type"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+	Children []Node                 `json:"children,omitempty"`
+}
+
+// Ensure Node implements the Scanner and Valuer interfaces
+var _ sql.Scanner = (*Node)(nil)
+var _ driver.Valuer = (*Node)(nil)
+
+// Value implements the https://golang.org/pkg/database/sql/driver/#Valuer interface
+func (n Node) Value() (driver.Value, error) {
+	return json.Marshal(n)
+}
+
+// Scan implements the https://golang.org/pkg/database/sql/#Scanner interface
+func (n *Node) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	s, ok := src.([]byte)
+	if !ok {
+		return errs.Errorf("scan source was not a string")
+	}
+	return json.Unmarshal(s, n)
+}
+
+// placeholderRegexp matches placeholders like "{{ component }}" in titles
+var placeholderRegexp = regexp.MustCompile(`{{\s*([A-Za-z0-9_.\-]+)\s*}}`)
+
+// Title returns the title of the node or an empty string if it has none.
+func (n Node) Title() string {
+	title, _ := n.Fields[workitem.SystemTitle].(string)
+	return title
+}
+
+// Size returns the number of work items in the tree starting with this node.
+func (n Node) Size() int {
+	size := 1
+	for _, c := range n.Children {
+		size += c.Size()
+	}
+	return size
+}
+
+// Placeholders returns the sorted names of all placeholders used in the titles
+// of the tree starting with this node.
+func (n Node) Placeholders() []string {
+	names := map[string]struct{}{}
+	n.walk(func(node Node) {
+		for _, m := range placeholderRegexp.FindAllStringSubmatch(node.Title(), -1) {
+			names[m[1]] = struct{}{}
+		}
+	})
+	res := make([]string, 0, len(names))
+	for name := range names {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Substitute returns a copy of the tree in which the placeholders in the titles
+// are replaced with the given values. A BadParameterError is returned if a
+// value is missing for any of the placeholders.
+func (n Node) Substitute(values map[string]string) (*Node, error) {
+	var missing []string
+	for _, name := range n.Placeholders() {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.NewBadParameterError("placeholders", strings.Join(missing, ", ")).Expected("a value for every placeholder")
+	}
+	res := n.substitute(values)
+	return &res, nil
+}
+
+func (n Node) substitute(values map[string]string) Node {
+	res := Node{
+		TypeID:   n.TypeID,
+		Fields:   make(map[string]interface{}, len(n.Fields)),
+		Children: make([]Node, 0, len(n.Children)),
+	}
+	for k, v := range n.Fields {
+		res.Fields[k] = v
+	}
+	if title := n.Title(); title != "" {
+		res.Fields[workitem.SystemTitle] = placeholderRegexp.ReplaceAllStringFunc(title, func(p string) string {
+			return values[placeholderRegexp.FindStringSubmatch(p)[1]]
+		})
+	}
+	for _, c := range n.Children {
+		res.Children = append(res.Children, c.substitute(values))
+	}
+	return res
+}
+
+func (n Node) walk(fn func(Node)) {
+	fn(n)
+	for _, c := range n.Children {
+		c.walk(fn)
+	}
+}
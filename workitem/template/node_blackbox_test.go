@@ -0,0 +1,58 @@
+package template_test
+
+import (
+	"testing"
+
+	errs "github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/template"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func titled(title string, children ...template.Node) template.Node {
+	return template.Node{
+		TypeID:   uuid.NewV4(),
+		Fields:   map[string]interface{}{workitem.SystemTitle: title},
+		Children: children,
+	}
+}
+
+func TestNode(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	tree := titled("Release {{ component }}",
+		titled("{{component}}: docs"),
+		titled("{{component}}: {{version}} notes", titled("no placeholder")),
+	)
+
+	t.Run("size", func(t *testing.T) {
+		assert.Equal(t, 4, tree.Size())
+	})
+
+	t.Run("placeholders", func(t *testing.T) {
+		assert.Equal(t, []string{"component", "version"}, tree.Placeholders())
+		assert.Empty(t, titled("nothing").Placeholders())
+	})
+
+	t.Run("substitute", func(t *testing.T) {
+		res, err := tree.Substitute(map[string]string{"component": "planner", "version": "1.0"})
+		require.NoError(t, err)
+		assert.Equal(t, "Release planner", res.Title())
+		assert.Equal(t, "planner: docs", res.Children[0].Title())
+		assert.Equal(t, "planner: 1.0 notes", res.Children[1].Title())
+		assert.Equal(t, "no placeholder", res.Children[1].Children[0].Title())
+		// the original tree is left untouched
+		assert.Equal(t, "Release {{ component }}", tree.Title())
+	})
+
+	t.Run("missing value", func(t *testing.T) {
+		_, err := tree.Substitute(map[string]string{"component": "planner"})
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.BadParameterError)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,139 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeWorkItemTemplates helps to avoid string literal
+const APIStringTypeWorkItemTemplates = "workitemtemplates"
+
+// Template is a reusable tree of work items that was saved from an existing
+// work item and its children. It belongs to a space but can be instantiated
+// in every space that uses the same space template.
+type Template struct {
+	gormsupport.Lifecycle
+	ID          uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	SpaceID     uuid.UUID `sql:"type:uuid"`
+	Name        string
+	Description *string
+	CreatorID   uuid.UUID `sql:"type:uuid"`
+	Root        Node      `sql:"type:jsonb"`
+	Version     int
+}
+
+// TemplateTableName constant that holds table name of work item templates
+const TemplateTableName = "work_item_templates"
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (t Template) TableName() string {
+	return TemplateTableName
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (t Template) GetETagData() []interface{} {
+	return []interface{}{t.ID, t.Version}
+}
+
+// GetLastModified returns the last modification time
+func (t Template) GetLastModified() time.Time {
+	return t.UpdatedAt.Truncate(time.Second)
+}
+
+// Repository describes interactions with work item templates
+type Repository interface {
+	Create(ctx context.Context, t *Template) error
+	Load(ctx context.Context, id uuid.UUID) (*Template, error)
+	List(ctx context.Context, spaceID uuid.UUID) ([]Template, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for work item
+// templates.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create stores a new work item template
+func (r *GormRepository) Create(ctx context.Context, t *Template) error {
+	defer goa.MeasureSince([]string{"goa", "db", "work_item_template", "create"}, time.Now())
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.NewBadParameterError("template name cannot be empty string", t.Name).Expected("non empty string")
+	}
+	if t.Root.TypeID == uuid.Nil {
+		return errors.NewBadParameterError("template root work item type", t.Root.TypeID).Expected("non nil UUID")
+	}
+	t.ID = uuid.NewV4()
+	if err := r.db.Create(t).Error; err != nil {
+		if gormsupport.IsUniqueViolation(err, "work_item_templates_name_space_id_unique_idx") {
+			return errors.NewDataConflictError(fmt.Sprintf("work item template already exists with name = %s , space_id = %s", t.Name, t.SpaceID))
+		}
+		if gormsupport.IsForeignKeyViolation(err, "work_item_templates_space_id_fkey") {
+			return errors.NewNotFoundError("space", t.SpaceID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"space_id": t.SpaceID,
+			"name":     t.Name,
+			"err":      err,
+		}, "unable to create the work item template")
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// Load returns the work item template with the given ID
+func (r *GormRepository) Load(ctx context.Context, id uuid.UUID) (*Template, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "work_item_template", "load"}, time.Now())
+	var obj Template
+	tx := r.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("work item template", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(ctx, tx.Error)
+	}
+	return &obj, nil
+}
+
+// List returns the work item templates of the given space ordered by name
+func (r *GormRepository) List(ctx context.Context, spaceID uuid.UUID) ([]Template, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "work_item_template", "list"}, time.Now())
+	var objs []Template
+	err := r.db.Where("space_id = ?", spaceID).Order("name").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// Delete removes the work item template with the given ID
+func (r *GormRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "work_item_template", "delete"}, time.Now())
+	tx := r.db.Delete(Template{ID: id})
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"template_id": id,
+			"err":         err,
+		}, "unable to delete the work item template")
+		return errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("work item template", id.String())
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package template_test
+
+import (
+	"context"
+	"testing"
+
+	errs "github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/template"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestTemplateRepository struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunTemplateRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestTemplateRepository{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+func (s *TestTemplateRepository) newTemplate(fxt *tf.TestFixture, name string) template.Template {
+	return template.Template{
+		SpaceID:   fxt.Spaces[0].ID,
+		Name:      name,
+		CreatorID: fxt.Identities[0].ID,
+		Root: template.Node{
+			TypeID: fxt.WorkItemTypes[0].ID,
+			Fields: map[string]interface{}{workitem.SystemTitle: "{{component}} epic"},
+			Children: []template.Node{
+				{TypeID: fxt.WorkItemTypes[0].ID, Fields: map[string]interface{}{workitem.SystemTitle: "task"}},
+			},
+		},
+	}
+}
+
+func (s *TestTemplateRepository) TestCreateAndLoad() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1), tf.Spaces(1))
+		repo := template.NewRepository(s.DB)
+		tmpl := s.newTemplate(fxt, "release")
+		require.NoError(t, repo.Create(context.Background(), &tmpl))
+		loaded, err := repo.Load(context.Background(), tmpl.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "release", loaded.Name)
+		assert.Equal(t, 2, loaded.Root.Size())
+		assert.Equal(t, "{{component}} epic", loaded.Root.Title())
+		assert.Equal(t, "task", loaded.Root.Children[0].Title())
+	})
+
+	s.T().Run("duplicate name", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1), tf.Spaces(1))
+		repo := template.NewRepository(s.DB)
+		tmpl := s.newTemplate(fxt, "release")
+		require.NoError(t, repo.Create(context.Background(), &tmpl))
+		dup := s.newTemplate(fxt, "release")
+		err := repo.Create(context.Background(), &dup)
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.DataConflictError)
+		assert.True(t, ok)
+	})
+
+	s.T().Run("empty name", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1), tf.Spaces(1))
+		tmpl := s.newTemplate(fxt, " ")
+		err := template.NewRepository(s.DB).Create(context.Background(), &tmpl)
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.BadParameterError)
+		assert.True(t, ok)
+	})
+
+	s.T().Run("not found", func(t *testing.T) {
+		_, err := template.NewRepository(s.DB).Load(context.Background(), uuid.NewV4())
+		require.Error(t, err)
+		_, ok := errors.Cause(err).(errs.NotFoundError)
+		assert.True(t, ok)
+	})
+}
+
+func (s *TestTemplateRepository) TestListAndDelete() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItemTypes(1), tf.Spaces(1))
+	repo := template.NewRepository(s.DB)
+	for _, name := range []string{"b", "a"} {
+		tmpl := s.newTemplate(fxt, name)
+		require.NoError(s.T(), repo.Create(context.Background(), &tmpl))
+	}
+	templates, err := repo.List(context.Background(), fxt.Spaces[0].ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), templates, 2)
+	assert.Equal(s.T(), "a", templates[0].Name)
+
+	require.NoError(s.T(), repo.Delete(context.Background(), templates[0].ID))
+	templates, err = repo.List(context.Background(), fxt.Spaces[0].ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), templates, 1)
+	err = repo.Delete(context.Background(), uuid.NewV4())
+	_, ok := errors.Cause(err).(errs.NotFoundError)
+	assert.True(s.T(), ok)
+}
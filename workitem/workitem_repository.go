@@ -47,6 +47,10 @@ type WorkItemRepository interface {
 	Reorder(ctx context.Context, spaceID uuid.UUID, direction DirectionType, targetID *uuid.UUID, wi WorkItem, modifierID uuid.UUID) (*WorkItem, error)
 	Delete(ctx context.Context, id uuid.UUID, suppressorID uuid.UUID) error
 	Create(ctx context.Context, spaceID uuid.UUID, typeID uuid.UUID, fields map[string]interface{}, creatorID uuid.UUID) (*WorkItem, error)
+	// Move moves the work item into another space, where it gets a new number,
+	// the given type and fields. The old number keeps resolving to the work
+	// item through LookupIDByNamedSpaceAndNumber.
+	Move(ctx context.Context, id uuid.UUID, targetSpaceID uuid.UUID, typeID uuid.UUID, fields map[string]interface{}, modifierID uuid.UUID) (*WorkItem, error)
 	List(ctx context.Context, spaceID uuid.UUID, criteria criteria.Expression, parentExists *bool, start *int, length *int) ([]WorkItem, int, error)
 	Fetch(ctx context.Context, spaceID uuid.UUID, criteria criteria.Expression) (*WorkItem, error)
 	GetCountsPerIteration(ctx context.Context, spaceID uuid.UUID) (map[string]WICountsPerIteration, error)
@@ -192,6 +196,21 @@ func (r *GormWorkItemRepository) LookupIDByNamedSpaceAndNumber(ctx context.Conte
 	}
 	var result Result
 	db := r.db.Raw(query, ownerName, spaceName, wiNumber).Scan(&result)
+	if db.RecordNotFound() {
+		// the work item may have been moved to another space in the meantime
+		redirectQuery := fmt.Sprintf(`select wi.id, wi.space_id from %[1]s r
+			join %[2]s wi on r.work_item_id = wi.id
+			join %[3]s s on r.space_id = s.id
+			join %[4]s i on s.owner_id = i.id
+			where lower(i.username) = lower(?) and
+			lower(s.name) = lower(?) and
+			r.number = ? and
+			wi.deleted_at IS NULL and
+			s.deleted_at IS NULL
+			and i.deleted_at IS NULL`,
+			WorkItemNumberRedirectTableName, WorkItemStorage{}.TableName(), space.Space{}.TableName(), account.Identity{}.TableName())
+		db = r.db.Raw(redirectQuery, ownerName, spaceName, wiNumber).Scan(&result)
+	}
 	if db.RecordNotFound() {
 		log.Error(nil, map[string]interface{}{
 			"wi_number":  wiNumber,
@@ -326,6 +345,100 @@ func (r *GormWorkItemRepository) Delete(ctx context.Context, workitemID uuid.UUI
 	return nil
 }
 
+// WorkItemNumberRedirectTableName constant that holds the name of the table
+// that maps the numbers that work items had in their former spaces to their ID
+const WorkItemNumberRedirectTableName = "work_item_number_redirects"
+
+// Move moves the work item with the given ID into the target space where it
+// gets the next number and is placed at the bottom of the list. The given
+// fields must match the given type and refer to the iterations, areas and
+// labels of the target space. Links, comments and revisions are kept since
+// they refer to the work item by its ID.
+// returns BadParameterError, NotFoundError, ForbiddenError, VersionConflictError or InternalError
+func (r *GormWorkItemRepository) Move(ctx context.Context, id uuid.UUID, targetSpaceID uuid.UUID, typeID uuid.UUID, fields map[string]interface{}, modifierID uuid.UUID) (*WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitem", "move"}, time.Now())
+	wiStorage, err := r.LoadFromDB(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if wiStorage.SpaceID == targetSpaceID {
+		return nil, errors.NewBadParameterError("space", targetSpaceID).Expected("a space other than the current space of the work item")
+	}
+	wiType, err := r.witr.Load(ctx, typeID)
+	if err != nil {
+		return nil, errors.NewBadParameterError("typeID", typeID)
+	}
+	if !wiType.CanConstruct {
+		return nil, errors.NewForbiddenError(fmt.Sprintf("cannot construct work items from \"%s\" (%s)", wiType.Name, wiType.ID))
+	}
+	newFields := Fields{}
+	for fieldName, fieldDef := range wiType.Fields {
+		if fieldDef.ReadOnly {
+			if v, ok := wiStorage.Fields[fieldName]; ok {
+				newFields[fieldName] = v
+			}
+			continue
+		}
+		fieldValue := fields[fieldName]
+		if (fieldName == SystemAssignees || fieldName == SystemLabels) && fieldValue == nil {
+			continue
+		}
+		newFields[fieldName], err = fieldDef.ConvertToModel(fieldName, fieldValue)
+		if err != nil {
+			return nil, errors.NewBadParameterError(fieldName, fieldValue)
+		}
+	}
+	// remember the current number so that old URLs keep working
+	redirectStmt := fmt.Sprintf(`INSERT INTO %s (space_id, number, work_item_id, created_at) VALUES ($1, $2, $3, now())
+		ON CONFLICT (space_id, number) DO UPDATE SET work_item_id = EXCLUDED.work_item_id, created_at = now()`, WorkItemNumberRedirectTableName)
+	if err := r.db.Exec(redirectStmt, wiStorage.SpaceID, wiStorage.Number, wiStorage.ID).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"wi_id":    id,
+			"space_id": wiStorage.SpaceID,
+			"number":   wiStorage.Number,
+			"err":      err,
+		}, "unable to store the number redirect of the moved work item")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	pos, err := r.LoadHighestOrder(ctx, targetSpaceID)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	number, err := r.winr.NextVal(ctx, targetSpaceID)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	oldVersion := wiStorage.Version
+	wiStorage.Version = oldVersion + 1
+	wiStorage.SpaceID = targetSpaceID
+	wiStorage.Type = typeID
+	wiStorage.Number = *number
+	wiStorage.ExecutionOrder = pos + orderValue
+	wiStorage.Fields = newFields
+	tx := r.db.Where("Version = ?", oldVersion).Save(wiStorage)
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"wi_id":           id,
+			"target_space_id": targetSpaceID,
+			"err":             err,
+		}, "unable to move the work item")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	// store a revision of the moved work item
+	if err := r.wirr.Create(context.Background(), modifierID, RevisionTypeUpdate, *wiStorage); err != nil {
+		return nil, errs.Wrapf(err, "error while moving work item")
+	}
+	log.Info(ctx, map[string]interface{}{
+		"wi_id":           id,
+		"target_space_id": targetSpaceID,
+		"number":          wiStorage.Number,
+	}, "Moved work item to another space")
+	return ConvertWorkItemStorageToModel(wiType, wiStorage)
+}
+
 // CalculateOrder calculates the order of the reorder workitem
 func (r *GormWorkItemRepository) CalculateOrder(above, below *float64) float64 {
 	return (*above + *below) / 2
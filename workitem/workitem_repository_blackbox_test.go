@@ -387,6 +387,63 @@ func (s *workItemRepoBlackBoxTest) TestLookupIDByNamedSpaceAndNumber() {
 	})
 }
 
+func (s *workItemRepoBlackBoxTest) TestMove() {
+	s.T().Run("ok", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(2), tf.WorkItems(2))
+		wi := *fxt.WorkItems[1]
+		// when
+		moved, err := s.repo.Move(s.Ctx, wi.ID, fxt.Spaces[1].ID, wi.Type, wi.Fields, fxt.Identities[0].ID)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, wi.ID, moved.ID)
+		assert.Equal(t, fxt.Spaces[1].ID, moved.SpaceID)
+		assert.Equal(t, wi.Version+1, moved.Version)
+		assert.Equal(t, wi.Fields[workitem.SystemTitle], moved.Fields[workitem.SystemTitle])
+		t.Run("new number resolves", func(t *testing.T) {
+			wiID, spaceID, err := s.repo.LookupIDByNamedSpaceAndNumber(s.Ctx, fxt.Identities[0].Username, fxt.Spaces[1].Name, moved.Number)
+			require.NoError(t, err)
+			assert.Equal(t, wi.ID, *wiID)
+			assert.Equal(t, fxt.Spaces[1].ID, *spaceID)
+		})
+		t.Run("old number redirects", func(t *testing.T) {
+			wiID, spaceID, err := s.repo.LookupIDByNamedSpaceAndNumber(s.Ctx, fxt.Identities[0].Username, fxt.Spaces[0].Name, wi.Number)
+			require.NoError(t, err)
+			assert.Equal(t, wi.ID, *wiID)
+			assert.Equal(t, fxt.Spaces[1].ID, *spaceID)
+		})
+		t.Run("old number is not reused", func(t *testing.T) {
+			created, err := s.repo.Create(s.Ctx, fxt.Spaces[0].ID, wi.Type, map[string]interface{}{
+				workitem.SystemTitle: "new",
+				workitem.SystemState: workitem.SystemStateNew,
+			}, fxt.Identities[0].ID)
+			require.NoError(t, err)
+			assert.NotEqual(t, wi.Number, created.Number)
+		})
+	})
+
+	s.T().Run("same space", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		wi := *fxt.WorkItems[0]
+		// when
+		_, err := s.repo.Move(s.Ctx, wi.ID, wi.SpaceID, wi.Type, wi.Fields, fxt.Identities[0].ID)
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("not found", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1), tf.WorkItemTypes(1))
+		// when
+		_, err := s.repo.Move(s.Ctx, uuid.NewV4(), fxt.Spaces[0].ID, fxt.WorkItemTypes[0].ID, map[string]interface{}{}, fxt.Identities[0].ID)
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
+
 // TestLoadBatchByID verifies that repo.LoadBatchByID returns distinct items
 func (s *workItemRepoBlackBoxTest) TestLoadBatchByID() {
 	fixtures := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(5))